@@ -202,6 +202,14 @@ func processEventsSql(schema *proto.Schema, traceId string) (string, []any, erro
 			return "", nil, err
 		}
 
+		// Soft deleting a row is an update which sets deleted_at, rather than a delete
+		model := schema.FindModel(e.GetModelName())
+		if op == Delete && model != nil && model.GetSoftDelete() {
+			conditions = append(conditions, fmt.Sprintf("(%s = ? AND %s = ? AND %s->>'deleted_at' IS NOT NULL)", ColumnTableName, ColumnOp, ColumnData))
+			args = append(args, table, Update)
+			continue
+		}
+
 		conditions = append(conditions, fmt.Sprintf("(%s = ? AND %s = ?)", ColumnTableName, ColumnOp))
 		args = append(args, table, op)
 	}
@@ -177,6 +177,43 @@ func TestProcessEventSqlMultipleEventsManyAttribute(t *testing.T) {
 	require.Equal(t, "delete", args[6])
 }
 
+func TestProcessEventSqlSoftDelete(t *testing.T) {
+	t.Parallel()
+	var keelSchema = `
+		model Person {
+			fields {
+				name Text
+			}
+			@softDelete
+			@on([update, delete], verifyDetails)
+		}`
+
+	builder := &schema.Builder{}
+	schema, err := builder.MakeFromString(keelSchema, config.Empty)
+	require.NoError(t, err)
+
+	sql, args, err := processEventsSql(schema, "0ffe82e8dcfd9f9fbe4c639d5ef4f1ba")
+	require.NoError(t, err)
+
+	expectedSql := `
+		UPDATE keel_audit 
+		SET event_processed_at = now() 
+		WHERE 
+			trace_id = ? AND 
+			event_processed_at IS NULL AND 
+			((table_name = ? AND op = ?) OR 
+			(table_name = ? AND op = ? AND data->>'deleted_at' IS NOT NULL)) 
+		RETURNING *`
+
+	require.Equal(t, clean(expectedSql), clean(sql))
+	require.Len(t, args, 5)
+	require.Equal(t, "0ffe82e8dcfd9f9fbe4c639d5ef4f1ba", args[0])
+	require.Equal(t, "person", args[1])
+	require.Equal(t, "update", args[2])
+	require.Equal(t, "person", args[3])
+	require.Equal(t, "update", args[4])
+}
+
 func TestProcessEventSqlNoEvents(t *testing.T) {
 	t.Parallel()
 	var keelSchema = `
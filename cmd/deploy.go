@@ -23,6 +23,7 @@ import (
 var flagDeployRuntimeBinary string
 var flagDeployLogsSince time.Duration
 var flagDeployLogsStart string
+var flagDeployAllowDestructiveMigrations bool
 
 var deployCommand = &cobra.Command{
 	Use:   "deploy",
@@ -118,10 +119,11 @@ var deployUpCommand = &cobra.Command{
 		}
 
 		err := deploy.Run(context.Background(), &deploy.RunArgs{
-			Action:        deploy.UpAction,
-			ProjectRoot:   validated.ProjectDir,
-			Env:           validated.Env,
-			RuntimeBinary: validated.RuntimeBinary,
+			Action:                     deploy.UpAction,
+			ProjectRoot:                validated.ProjectDir,
+			Env:                        validated.Env,
+			RuntimeBinary:              validated.RuntimeBinary,
+			AllowDestructiveMigrations: flagDeployAllowDestructiveMigrations,
 		})
 		if err != nil {
 			os.Exit(1)
@@ -364,6 +366,7 @@ func init() {
 	deployCommand.AddCommand(deployUpCommand)
 	deployUpCommand.Flags().StringVar(&flagEnvironment, "env", "", "The environment to deploy e.g. staging or production")
 	deployUpCommand.Flags().StringVar(&flagDeployRuntimeBinary, "runtime-binary", "", "")
+	deployUpCommand.Flags().BoolVar(&flagDeployAllowDestructiveMigrations, "allow-destructive-migrations", false, "Apply database migrations which drop tables or columns")
 
 	deployCommand.AddCommand(deployRemoveCommand)
 	deployRemoveCommand.Flags().StringVar(&flagEnvironment, "env", "", "The environment to remove e.g. staging or production")
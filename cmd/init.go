@@ -191,6 +191,7 @@ func initStepTemplate(state *InitState) {
 
 # Keel
 .build/
+.keel/
 			`,
 		})
 
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/teamkeel/keel/cmd/program"
+	"github.com/teamkeel/keel/colors"
+	"github.com/teamkeel/keel/runtime/oauth"
+)
+
+var lockoutsCmd = &cobra.Command{
+	Use:   "lockouts",
+	Short: "Manage the email addresses and IP addresses which are locked out",
+	Long: `The lockouts command allows you to list the email addresses and IP
+addresses which are temporarily locked out of logging in with a password
+or requesting a password reset after too many attempts, and to unlock
+them before the lockout ends.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// list subcommands
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lockoutsCmd)
+	lockoutsCmd.AddCommand(lockoutsListCmd)
+	lockoutsCmd.AddCommand(lockoutsUnlockCmd)
+	lockoutsCmd.PersistentFlags().StringVar(&flagDatabaseURL, "database-url", "", "connection string of the database to use, instead of the local development database")
+}
+
+var lockoutsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the email addresses and IP addresses which are locked out",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAuthDatabase(func(ctx context.Context) error {
+			lockouts, err := oauth.ListLockouts(ctx)
+			if err != nil {
+				return err
+			}
+
+			if len(lockouts) == 0 {
+				fmt.Println(colors.Gray("No lockouts found"))
+			}
+
+			for _, l := range lockouts {
+				details := fmt.Sprintf("%s locked out of %s after %d attempts until %s", l.Kind, l.Action, l.Failures, l.LockedUntil.Format("2006-01-02 15:04:05"))
+				fmt.Printf("  %s %s %s\n", colors.Red("✘"), l.Subject, colors.Gray(details))
+			}
+
+			return nil
+		})
+	},
+}
+
+var lockoutsUnlockCmd = &cobra.Command{
+	Use:   "unlock <email-or-ip>",
+	Short: "Unlock an email address or IP address and clear its failed attempts",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAuthDatabase(func(ctx context.Context) error {
+			unlocked, err := oauth.Unlock(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			program.RenderSuccess(fmt.Sprintf("Lifted %d lockouts for %s", unlocked, args[0]))
+
+			return nil
+		})
+	},
+}
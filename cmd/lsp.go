@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/teamkeel/keel/lsp"
+	"github.com/teamkeel/keel/runtime"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a language server for Keel schema files over stdio",
+	Long: `The lsp command runs a Language Server Protocol server which communicates
+over stdin and stdout, providing diagnostics, completions, formatting, hover
+docs, go-to-definition, find-references and rename for *.keel files.
+
+It is intended to be started by an editor rather than run directly.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server := lsp.NewServer(flagProjectDir, runtime.GetVersion())
+		return server.Serve(os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
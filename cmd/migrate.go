@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/teamkeel/keel/cmd/database"
+	"github.com/teamkeel/keel/cmd/program"
+	"github.com/teamkeel/keel/colors"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/migrations"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/schema"
+)
+
+var (
+	flagDatabaseURL string
+	flagSteps       int
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage your project's database migration files",
+	Long: `The migrate command allows you to generate numbered migration files
+from changes to your schema, which are kept in the migrations directory
+of your project, and apply or revert them in order. Applied migrations
+are recorded in the keel_migrations table.
+
+Once a project has migration files, 'keel run' and 'keel deploy' apply any
+pending ones and will not change models or fields in the database unless
+the change is in a migration file. Until then 'keel run' changes the
+local development database directly, so generate the first migration
+against an empty database using --database-url.
+
+By default the local development database is used.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// list subcommands
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateGenerateCmd)
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.PersistentFlags().StringVar(&flagDatabaseURL, "database-url", "", "connection string of the database to migrate, instead of the local development database")
+	migrateDownCmd.Flags().IntVar(&flagSteps, "steps", 1, "the number of migrations to revert")
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List migrations and whether they have been applied",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrations(func(ctx context.Context, s *proto.Schema, database db.Database, files []*migrations.MigrationFile) error {
+			statuses, err := migrations.GetMigrationStatus(ctx, database, files)
+			if err != nil {
+				return err
+			}
+
+			if len(statuses) == 0 {
+				fmt.Println(colors.Gray("No migrations found"))
+			}
+
+			for _, st := range statuses {
+				name := fmt.Sprintf("%04d_%s", st.Version, st.Name)
+				switch {
+				case st.File == nil:
+					fmt.Printf("  %s %s %s\n", colors.Red("✘"), name, colors.Red("(file missing)"))
+				case st.Modified():
+					fmt.Printf("  %s %s %s\n", colors.Red("✘"), name, colors.Red("(modified since applied)"))
+				case st.Pending():
+					fmt.Printf("  %s %s %s\n", colors.Orange("•"), name, colors.Orange("(pending)"))
+				default:
+					fmt.Printf("  %s %s %s\n", colors.Green("✔"), name, colors.Gray(st.Applied.AppliedAt.Format("2006-01-02 15:04:05")))
+				}
+			}
+
+			if countPending(statuses) > 0 {
+				return nil
+			}
+
+			// Only once everything has been applied can we tell if the schema has changed since the last migration
+			m, err := migrations.New(ctx, s, database)
+			if err != nil {
+				return err
+			}
+
+			if m.HasEntityFieldChanges() {
+				fmt.Printf("\n%s\n", colors.Orange("The schema has changes which are not in a migration. Run 'keel migrate generate <name>' to create one."))
+			}
+
+			for _, rename := range m.StaleRenames {
+				fmt.Printf("%s\n", colors.Orange(fmt.Sprintf("%s has already been applied and can be removed from your schema", rename)))
+			}
+
+			return nil
+		})
+	},
+}
+
+var migrateGenerateCmd = &cobra.Command{
+	Use:   "generate <name>",
+	Short: "Generate a migration from the changes made to your schema",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrations(func(ctx context.Context, s *proto.Schema, database db.Database, files []*migrations.MigrationFile) error {
+			statuses, err := migrations.GetMigrationStatus(ctx, database, files)
+			if err != nil {
+				return err
+			}
+
+			// The database must be up-to-date with the migration files, otherwise the
+			// generated migration would repeat the changes in the pending ones.
+			if countPending(statuses) > 0 {
+				return migrations.ErrPendingMigrations
+			}
+
+			m, err := migrations.New(ctx, s, database)
+			if err != nil {
+				return err
+			}
+
+			f, err := migrations.WriteMigrationFile(migrationsDir(), args[0], m)
+			if err != nil {
+				return err
+			}
+
+			program.RenderSuccess(fmt.Sprintf("Generated migration %s", f.Filename()))
+			for _, ch := range m.Changes {
+				fmt.Printf("  - %s\n", ch.String())
+			}
+
+			for _, rename := range m.StaleRenames {
+				fmt.Printf("%s\n", colors.Orange(fmt.Sprintf("%s has already been applied and can be removed from your schema", rename)))
+			}
+
+			if !f.Reversible() {
+				fmt.Printf("\n%s\n", colors.Orange(fmt.Sprintf("A down migration could not be generated. Edit %s.down.sql if it needs to be reverted.", f.Filename())))
+			}
+
+			return nil
+		})
+	},
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrations(func(ctx context.Context, s *proto.Schema, database db.Database, files []*migrations.MigrationFile) error {
+			applied, err := migrations.Up(ctx, s, database, files)
+			if err != nil {
+				return err
+			}
+
+			if len(applied) == 0 {
+				program.RenderSuccess("No pending migrations")
+				return nil
+			}
+
+			for _, f := range applied {
+				fmt.Printf("  %s %s\n", colors.Green("✔"), f.Filename())
+			}
+			program.RenderSuccess(fmt.Sprintf("Applied %d migrations", len(applied)))
+
+			return nil
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the most recently applied migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrations(func(ctx context.Context, s *proto.Schema, database db.Database, files []*migrations.MigrationFile) error {
+			reverted, err := migrations.Down(ctx, database, files, flagSteps)
+			if err != nil {
+				return err
+			}
+
+			if len(reverted) == 0 {
+				program.RenderSuccess("No migrations to revert")
+				return nil
+			}
+
+			for _, f := range reverted {
+				fmt.Printf("  %s %s\n", colors.Green("✔"), f.Filename())
+			}
+			program.RenderSuccess(fmt.Sprintf("Reverted %d migrations", len(reverted)))
+
+			return nil
+		})
+	},
+}
+
+func migrationsDir() string {
+	return filepath.Join(flagProjectDir, migrations.MigrationsDirectory)
+}
+
+// withMigrations builds the project's schema, reads its migration files and connects to the database
+// being migrated before calling fn. Any error is rendered for the terminal.
+func withMigrations(fn func(ctx context.Context, s *proto.Schema, database db.Database, files []*migrations.MigrationFile) error) error {
+	ctx := context.Background()
+
+	b := schema.Builder{}
+	s, err := b.MakeFromDirectory(flagProjectDir)
+	if err != nil {
+		return program.RenderError(err)
+	}
+
+	files, err := migrations.ReadMigrationFiles(migrationsDir())
+	if err != nil {
+		return program.RenderError(err)
+	}
+
+	conn, closeConn, err := connectDatabase(ctx)
+	if err != nil {
+		return program.RenderError(err)
+	}
+	defer closeConn()
+
+	err = fn(ctx, s, conn, files)
+	if err != nil {
+		return program.RenderError(err)
+	}
+
+	return nil
+}
+
+// connectDatabase connects to the database given by --database-url, otherwise starting and connecting to
+// the local development database. The returned function closes the connection and stops the local database.
+func connectDatabase(ctx context.Context) (db.Database, func(), error) {
+	connString := flagDatabaseURL
+	stop := func() {}
+	if connString == "" {
+		connInfo, err := database.Start(false, flagProjectDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		stop = func() {
+			_ = database.Stop()
+		}
+		connString = connInfo.String()
+	}
+
+	conn, err := db.New(ctx, connString)
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+
+	return conn, func() {
+		_ = conn.Close()
+		stop()
+	}, nil
+}
+
+// countPending returns the number of migrations which have not been applied.
+func countPending(statuses []*migrations.MigrationStatus) int {
+	count := 0
+	for _, s := range statuses {
+		if s.Pending() {
+			count++
+		}
+	}
+	return count
+}
@@ -3,8 +3,10 @@ package program
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -15,6 +17,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	_ "embed"
 
@@ -38,6 +41,7 @@ import (
 	"github.com/teamkeel/keel/runtime/runtimectx"
 	"github.com/teamkeel/keel/schema"
 	"github.com/teamkeel/keel/schema/reader"
+	filestorage "github.com/teamkeel/keel/storage"
 	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
 	p "google.golang.org/protobuf/proto"
 )
@@ -285,6 +289,7 @@ func StartDatabase(reset bool, mode int, projectDirectory string) tea.Cmd {
 
 type StartStorageMsg struct {
 	ConnInfo *storage.ConnectionInfo
+	Storer   filestorage.Storer
 	Err      error
 }
 
@@ -303,6 +308,47 @@ func StartStorage(projectDirectory string) tea.Cmd {
 	}
 }
 
+// StartFilesystemStorage sets up storage for files in the project's .keel directory, which are
+// then served by the runtime at the given URL. This means Docker is not needed for file storage.
+func StartFilesystemStorage(projectDirectory string, runtimeURL string) tea.Cmd {
+	return func() tea.Msg {
+		// Signed URLs only need to be valid for as long as this process is running
+		signingKey := make([]byte, 32)
+		_, err := rand.Read(signingKey)
+		if err != nil {
+			return StartStorageMsg{
+				Err: err,
+			}
+		}
+
+		store, err := filestorage.NewFilesystemStore(filepath.Join(projectDirectory, ".keel"), runtimeURL, signingKey, tracer)
+		if err != nil {
+			return StartStorageMsg{
+				Err: err,
+			}
+		}
+
+		return StartStorageMsg{
+			Storer: store,
+		}
+	}
+}
+
+type DeleteExpiredUploadsMsg struct {
+	Err error
+}
+
+// DeleteExpiredUploads deletes any files which were uploaded directly to storage but were
+// never used in an action. It runs after the given delay so it can be scheduled periodically.
+func DeleteExpiredUploads(storer filestorage.Storer, after time.Duration) tea.Cmd {
+	return tea.Tick(after, func(time.Time) tea.Msg {
+		_, err := storer.DeleteExpiredUploads(context.Background())
+		return DeleteExpiredUploadsMsg{
+			Err: err,
+		}
+	})
+}
+
 type SetupFunctionsMsg struct {
 	Err error
 }
@@ -373,8 +419,11 @@ func UpdateFunctions(schema *proto.Schema, cfg *config.ProjectConfig, dir string
 }
 
 type RunMigrationsMsg struct {
-	Err     error
-	Changes []*migrations.DatabaseChange
+	Err          error
+	Changes      []*migrations.DatabaseChange
+	StaleRenames []string
+	// Migration files which were pending and have been applied
+	AppliedFiles []*migrations.MigrationFile
 }
 
 type ApplyMigrationsError struct {
@@ -389,8 +438,38 @@ func (e *ApplyMigrationsError) Unwrap() error {
 	return e.Err
 }
 
-func RunMigrations(schema *proto.Schema, database db.Database) tea.Cmd {
+// RunMigrations migrates the database to the schema. If the project has migration files then any pending ones are
+// applied and recorded, and changes to the schema which are not in a migration file are not applied.
+func RunMigrations(dir string, schema *proto.Schema, database db.Database) tea.Cmd {
 	return func() tea.Msg {
+		files, err := migrations.ReadMigrationFiles(filepath.Join(dir, migrations.MigrationsDirectory))
+		if err != nil {
+			return RunMigrationsMsg{
+				Err: &ApplyMigrationsError{
+					Err: err,
+				},
+			}
+		}
+
+		if len(files) > 0 {
+			applied, m, err := migrations.UpAndApply(context.Background(), schema, database, files)
+
+			msg := RunMigrationsMsg{
+				AppliedFiles: applied,
+			}
+			if m != nil {
+				msg.Changes = m.Changes
+				msg.StaleRenames = m.StaleRenames
+			}
+			if err != nil {
+				msg.Err = &ApplyMigrationsError{
+					Err: err,
+				}
+			}
+
+			return msg
+		}
+
 		m, err := migrations.New(context.Background(), schema, database)
 		if err != nil {
 			return RunMigrationsMsg{
@@ -401,7 +480,8 @@ func RunMigrations(schema *proto.Schema, database db.Database) tea.Cmd {
 		}
 
 		msg := RunMigrationsMsg{
-			Changes: m.Changes,
+			Changes:      m.Changes,
+			StaleRenames: m.StaleRenames,
 		}
 
 		err = m.Apply(context.Background(), false)
@@ -449,6 +529,12 @@ func StartFunctions(m *Model) tea.Cmd {
 			envVars["KEEL_S3_ENDPOINT"] = fmt.Sprintf("http://%s:%s", m.StorageConnInfo.Host, m.StorageConnInfo.Port)
 		}
 
+		if fsStore, ok := m.Storage.(*filestorage.FilesystemStore); ok {
+			envVars["KEEL_FILES_DIRECTORY"] = fsStore.Directory
+			envVars["KEEL_FILES_URL"] = fsStore.BaseURL
+			envVars["KEEL_FILES_SIGNING_KEY"] = hex.EncodeToString(fsStore.SigningKey)
+		}
+
 		output := &FunctionsOutputWriter{
 			// Initially buffer output inside the writer in case there's an error
 			Buffer: true,
@@ -488,13 +574,18 @@ type StartServerError struct {
 	Err error
 }
 
+// RuntimeURL returns the URL that the runtime server can be reached at.
+func RuntimeURL(port string, customHostname string) string {
+	if customHostname != "" {
+		return customHostname
+	}
+
+	return fmt.Sprintf("http://localhost:%s", port)
+}
+
 func StartRuntimeServer(port string, customHostname string, ch chan tea.Msg) tea.Cmd {
 	return func() tea.Msg {
-		if customHostname != "" {
-			os.Setenv("KEEL_API_URL", customHostname)
-		} else {
-			os.Setenv("KEEL_API_URL", fmt.Sprintf("http://localhost:%s", port))
-		}
+		os.Setenv("KEEL_API_URL", RuntimeURL(port, customHostname))
 
 		runtimeServer := http.Server{
 			Addr: fmt.Sprintf(":%s", port),
@@ -911,6 +1002,20 @@ type CronRunnerMsg struct {
 	Err error
 }
 
+// cronSchedule is the schedule in the format of the cron runner, in the time zone of the schedule.
+func cronSchedule(schedule *proto.Schedule) string {
+	// Our cron expressions for schedules include the year, which is not relevant to our use case.
+	expression := strings.TrimSuffix(schedule.GetExpression(), " *")
+
+	// Without a time zone the runner would use the local time of this machine rather than UTC, as when deployed.
+	timezone := schedule.GetTimezone()
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	return fmt.Sprintf("CRON_TZ=%s %s", timezone, expression)
+}
+
 func SetupCron(schema *proto.Schema, database db.Database, functionsServer *node.DevelopmentServer, cronRunner *cron.Cron, secrets map[string]string) tea.Cmd {
 	return func() tea.Msg {
 		cronRunner.Stop()
@@ -939,10 +1044,7 @@ func SetupCron(schema *proto.Schema, database db.Database, functionsServer *node
 				return fmt.Errorf("wrapping event: %w", err)
 			}
 
-			// Our cron expressions for schedules include the year, which is not relevant to our use case.
-			schedule := strings.TrimSuffix(f.GetSchedule().GetExpression(), " *")
-
-			if _, err := cronRunner.AddFunc(schedule, func() {
+			if _, err := cronRunner.AddFunc(cronSchedule(f.GetSchedule()), func() {
 				o.HandleEvent(ctx, payload) //nolint
 			}); err != nil {
 				return CronRunnerMsg{
@@ -0,0 +1,15 @@
+package program
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/proto"
+)
+
+func TestCronScheduleTimezone(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "CRON_TZ=UTC 0 9 * * 1-5", cronSchedule(&proto.Schedule{Expression: "0 9 * * 1-5 *"}))
+	require.Equal(t, "CRON_TZ=Europe/London 0 9 * * 1-5", cronSchedule(&proto.Schedule{Expression: "0 9 * * 1-5 *", Timezone: "Europe/London"}))
+}
@@ -121,7 +121,9 @@ func Run(model *Model) {
 
 	defer func() {
 		_ = database.Stop()
-		_ = storagecmd.Stop()
+		if !model.FilesystemStorage {
+			_ = storagecmd.Stop()
+		}
 		if model.FunctionsServer != nil {
 			_ = model.FunctionsServer.Kill()
 		}
@@ -137,6 +139,9 @@ func Run(model *Model) {
 	}
 }
 
+// How often files which were uploaded but never used are deleted.
+const uploadsCleanupInterval = time.Hour
+
 type Model struct {
 	// The directory of the Keel project
 	ProjectDir string
@@ -153,6 +158,10 @@ type Model struct {
 	// applies to ModeRun.
 	ResetDatabase bool
 
+	// If true then files will be stored in the project's .keel
+	// directory instead of a storage container in Docker.
+	FilesystemStorage bool
+
 	// If set then @teamkeel/* npm packages will be installed
 	// from this path, rather than NPM.
 	NodePackagesPath string
@@ -182,31 +191,36 @@ type Model struct {
 	CustomHostname string
 
 	// Model state - used in View()
-	Status            int
-	Err               error
-	Schema            *proto.Schema
-	Config            *config.ProjectConfig
-	SchemaFiles       []*reader.SchemaFile
-	Database          db.Database
-	DatabaseConnInfo  *db.ConnectionInfo
-	GeneratedFiles    codegen.GeneratedFiles
-	MigrationChanges  []*migrations.DatabaseChange
-	FunctionsServer   *node.DevelopmentServer
-	RuntimeHandler    http.Handler
-	JobHandler        runtime.JobHandler
-	SubscriberHandler runtime.SubscriberHandler
-	RpcHandler        http.Handler
-	RuntimeRequests   []*RuntimeRequest
-	FunctionsLog      []*FunctionLog
-	StorageConnInfo   *storagecmd.ConnectionInfo
-	Storage           storage.Storer
-	CronRunner        *cron.Cron
-	TestOutput        string
-	Secrets           map[string]string
-	Environment       string
-	SeedData          bool
-	SeededFiles       []string
-	SnapshotDatabase  bool
+	Status           int
+	Err              error
+	Schema           *proto.Schema
+	Config           *config.ProjectConfig
+	SchemaFiles      []*reader.SchemaFile
+	Database         db.Database
+	DatabaseConnInfo *db.ConnectionInfo
+	GeneratedFiles   codegen.GeneratedFiles
+	MigrationChanges []*migrations.DatabaseChange
+	// @renamedFrom attributes which no longer have any effect
+	MigrationStaleRenames []string
+	// Migration files applied when the app was started
+	MigrationAppliedFiles []*migrations.MigrationFile
+	FunctionsServer       *node.DevelopmentServer
+	RuntimeHandler        http.Handler
+	JobHandler            runtime.JobHandler
+	SubscriberHandler     runtime.SubscriberHandler
+	RpcHandler            http.Handler
+	RuntimeRequests       []*RuntimeRequest
+	FunctionsLog          []*FunctionLog
+	StorageConnInfo       *storagecmd.ConnectionInfo
+	Storage               storage.Storer
+	CronRunner            *cron.Cron
+	EventBroker           *events.Broker
+	TestOutput            string
+	Secrets               map[string]string
+	Environment           string
+	SeedData              bool
+	SeededFiles           []string
+	SnapshotDatabase      bool
 
 	// The current latest version of Keel in NPM
 	LatestVersion *semver.Version
@@ -246,6 +260,7 @@ func (m *Model) Init() tea.Cmd {
 	m.RpcPort = "34087"
 	m.TracePort = "4318"
 	m.CronRunner = cron.New()
+	m.EventBroker = events.NewBroker()
 
 	m.Status = StatusCheckingDependencies
 
@@ -286,6 +301,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		m.Status = StatusSetupStorage
+		if m.FilesystemStorage {
+			return m, StartFilesystemStorage(m.ProjectDir, RuntimeURL(m.Port, m.CustomHostname))
+		}
 		return m, StartStorage(m.ProjectDir)
 	case StartStorageMsg:
 		m.StorageConnInfo = msg.ConnInfo
@@ -295,18 +313,29 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
-		// We now create a new S3 storer that's connected to the local minio server (running as a docker container)
-		endpoint := fmt.Sprintf("http://%s:%s", m.StorageConnInfo.Host, m.StorageConnInfo.Port)
-		s3Client := s3.NewFromConfig(aws.Config{
-			BaseEndpoint: &endpoint,
-			Credentials:  credentials.NewStaticCredentialsProvider(m.StorageConnInfo.AccessKey, m.StorageConnInfo.SecretKey, ""),
-			Region:       m.StorageConnInfo.Region,
-		})
+		if msg.Storer != nil {
+			m.Storage = msg.Storer
+		} else {
+			// We now create a new S3 storer that's connected to the local minio server (running as a docker container)
+			endpoint := fmt.Sprintf("http://%s:%s", m.StorageConnInfo.Host, m.StorageConnInfo.Port)
+			s3Client := s3.NewFromConfig(aws.Config{
+				BaseEndpoint: &endpoint,
+				Credentials:  credentials.NewStaticCredentialsProvider(m.StorageConnInfo.AccessKey, m.StorageConnInfo.SecretKey, ""),
+				Region:       m.StorageConnInfo.Region,
+			})
 
-		m.Storage = storage.NewS3BucketStore(m.StorageConnInfo.Bucket, s3Client, tracer)
+			m.Storage = storage.NewS3BucketStore(m.StorageConnInfo.Bucket, s3Client, tracer)
+		}
 
 		m.Status = StatusSetupDatabase
-		return m, StartDatabase(m.ResetDatabase, m.Mode, m.ProjectDir)
+		return m, tea.Batch(
+			StartDatabase(m.ResetDatabase, m.Mode, m.ProjectDir),
+			DeleteExpiredUploads(m.Storage, 0),
+		)
+	case DeleteExpiredUploadsMsg:
+		// Failing to clean up uploads is not a reason to stop
+		// running, so just try again next time.
+		return m, DeleteExpiredUploads(m.Storage, uploadsCleanupInterval)
 	case StartServerError:
 		m.Err = msg.Err
 		// If the servers can't be started we exit
@@ -409,10 +438,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.RuntimeHandler = cors.Handler(runtime.NewHttpHandler(m.Schema))
 		m.Status = StatusRunMigrations
-		return m, RunMigrations(m.Schema, m.Database)
+		return m, RunMigrations(m.ProjectDir, m.Schema, m.Database)
 	case RunMigrationsMsg:
 		m.Err = msg.Err
 		m.MigrationChanges = msg.Changes
+		m.MigrationStaleRenames = msg.StaleRenames
+		m.MigrationAppliedFiles = msg.AppliedFiles
 
 		if m.Err != nil {
 			return m, nil
@@ -581,6 +612,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		ctx = runtimectx.WithSecrets(ctx, m.Secrets)
 
 		ctx = runtimectx.WithOAuthConfig(ctx, &m.Config.Auth)
+		ctx = runtimectx.WithIdempotencyConfig(ctx, &m.Config.Idempotency)
+		ctx = runtimectx.WithRateLimits(ctx, m.Config.RateLimits)
 		if m.Storage != nil {
 			ctx = runtimectx.WithStorage(ctx, m.Storage)
 		}
@@ -614,6 +647,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
+		// Events are also published to GraphQL subscriptions
+		ctx = events.WithBroker(ctx, m.EventBroker)
+
 		// Setting the flows orchestrator
 		ctx = flows.WithOrchestrator(ctx, flows.NewOrchestrator(m.Schema, flows.WithNoQueueEventSender()))
 
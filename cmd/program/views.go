@@ -136,6 +136,15 @@ func renderRun(m *Model) string {
 		b.WriteString("✅ Functions\n")
 	}
 
+	if len(m.MigrationAppliedFiles) > 0 {
+		b.WriteString("\n")
+		b.WriteString(colors.Heading("Migrations applied:").String())
+		b.WriteString("\n")
+		for _, f := range m.MigrationAppliedFiles {
+			b.WriteString(fmt.Sprintf(" - %s\n", f.Filename()))
+		}
+	}
+
 	if len(m.MigrationChanges) > 0 {
 		b.WriteString("\n")
 		b.WriteString(colors.Heading("Schema changes:").String())
@@ -149,16 +158,30 @@ func renderRun(m *Model) string {
 				b.WriteString(colors.Red(ch.Type).String())
 			case migrations.ChangeTypeModified:
 				b.WriteString(colors.Black(ch.Type).String())
+			case migrations.ChangeTypeRenamed:
+				b.WriteString(colors.Yellow(ch.Type).String())
 			}
 			b.WriteString(" ")
 			b.WriteString(ch.Model)
 			if ch.Field != "" {
 				b.WriteString(fmt.Sprintf(".%s", ch.Field))
 			}
+			if ch.RenamedFrom != "" {
+				b.WriteString(colors.Gray(fmt.Sprintf(" (from %s)", ch.RenamedFrom)).String())
+			}
 			b.WriteString("\n")
 		}
 	}
 
+	if len(m.MigrationStaleRenames) > 0 {
+		b.WriteString("\n")
+		b.WriteString(colors.Yellow("These renames have already been applied to the database and can be removed from your schema:").String())
+		b.WriteString("\n")
+		for _, rename := range m.MigrationStaleRenames {
+			b.WriteString(fmt.Sprintf(" - %s\n", rename))
+		}
+	}
+
 	if m.Status == StatusRunning {
 		if len(m.Schema.GetApis()) == 0 {
 			b.WriteString(colors.Yellow("\n - Your schema doesn't have any API's defined in it\n").String())
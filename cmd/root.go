@@ -5,6 +5,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
@@ -29,6 +31,8 @@ var (
 	flagJsonOutput       bool
 	flagSchema           string
 	flagConfig           string
+	flagRunStorage       string
+	flagTestStorage      string
 )
 
 var rootCmd = &cobra.Command{
@@ -120,3 +124,17 @@ func resolvePackageManager(dir string, isInit bool) (string, error) {
 	_, v, err := s.Run()
 	return v, err
 }
+
+// validateStorageFlag returns an error if the --storage flag is not one of the values allowed by the command.
+func validateStorageFlag(value string, allowed ...string) error {
+	if slices.Contains(allowed, value) {
+		return nil
+	}
+
+	quoted := make([]string, len(allowed))
+	for i, a := range allowed {
+		quoted[i] = fmt.Sprintf("'%s'", a)
+	}
+
+	return fmt.Errorf("--storage must be either %s, got '%s'", strings.Join(quoted, " or "), value)
+}
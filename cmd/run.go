@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 	"github.com/teamkeel/keel/cmd/program"
@@ -19,16 +22,22 @@ var runCmd = &cobra.Command{
 			panic(err)
 		}
 
+		if err := validateStorageFlag(flagRunStorage, "docker", "filesystem"); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
 		program.Run(&program.Model{
-			Mode:             program.ModeRun,
-			ProjectDir:       flagProjectDir,
-			ResetDatabase:    flagReset,
-			Port:             flagPort,
-			CustomHostname:   flagHostname,
-			CustomTracing:    flagTracing,
-			NodePackagesPath: flagNodePackagesPath,
-			PackageManager:   packageManager,
-			PrivateKeyPath:   flagPrivateKeyPath,
+			Mode:              program.ModeRun,
+			ProjectDir:        flagProjectDir,
+			ResetDatabase:     flagReset,
+			Port:              flagPort,
+			CustomHostname:    flagHostname,
+			CustomTracing:     flagTracing,
+			NodePackagesPath:  flagNodePackagesPath,
+			PackageManager:    packageManager,
+			PrivateKeyPath:    flagPrivateKeyPath,
+			FilesystemStorage: flagRunStorage == "filesystem",
 		})
 	},
 }
@@ -40,6 +49,7 @@ func init() {
 	runCmd.Flags().StringVar(&flagHostname, "hostname", "", "custom hostname to handle HTTP requests")
 	runCmd.Flags().StringVar(&flagPort, "port", "8000", "the local port to handle Keel HTTP requests")
 	runCmd.Flags().StringVar(&flagPrivateKeyPath, "private-key-path", "", "path to the private key .pem file")
+	runCmd.Flags().StringVar(&flagRunStorage, "storage", "docker", "where files are stored, either 'docker' or 'filesystem' (in the project's .keel directory)")
 
 	if enabledDebugFlags == "true" {
 		runCmd.Flags().StringVar(&flagNodePackagesPath, "node-packages-path", "", "path to local @teamkeel npm packages")
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teamkeel/keel/cmd/program"
+	"github.com/teamkeel/keel/colors"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/runtime/oauth"
+)
+
+var flagScopes []string
+
+var serviceAccountNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]+$`)
+
+var serviceAccountsCmd = &cobra.Command{
+	Use:   "service-accounts",
+	Short: "Manage the service accounts which can access your APIs",
+	Long: `The service-accounts command allows you to create, list and revoke
+service accounts, which authenticate with the client_credentials grant
+at /auth/token rather than as an identity.
+
+Service accounts can also be defined in keelconfig.yaml under
+auth.serviceAccounts, in which case their client ID is their name and
+their client secret is the SERVICE_ACCOUNT_SECRET_<NAME> secret.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// list subcommands
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serviceAccountsCmd)
+	serviceAccountsCmd.AddCommand(serviceAccountsCreateCmd)
+	serviceAccountsCmd.AddCommand(serviceAccountsListCmd)
+	serviceAccountsCmd.AddCommand(serviceAccountsRevokeCmd)
+	serviceAccountsCmd.PersistentFlags().StringVar(&flagDatabaseURL, "database-url", "", "connection string of the database to use, instead of the local development database")
+	serviceAccountsCreateCmd.Flags().StringSliceVar(&flagScopes, "scopes", []string{}, "the scopes granted to the service account")
+}
+
+var serviceAccountsCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a service account and generate its client credentials",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		if !serviceAccountNameRegex.MatchString(name) {
+			return program.RenderError(fmt.Errorf("invalid name %s: must start with a letter and only contain letters, numbers and underscores", name))
+		}
+
+		cfg, err := config.Load(flagProjectDir)
+		if err != nil {
+			return program.RenderError(err)
+		}
+
+		if cfg.Auth.GetServiceAccount(name) != nil {
+			return program.RenderError(fmt.Errorf("service account %s is already defined in keelconfig.yaml", name))
+		}
+
+		for _, scope := range flagScopes {
+			if strings.ContainsAny(scope, " \t\n") {
+				return program.RenderError(fmt.Errorf("invalid scope '%s': scopes cannot contain whitespace", scope))
+			}
+		}
+
+		return withAuthDatabase(func(ctx context.Context) error {
+			account, secret, err := oauth.CreateServiceAccount(ctx, name, flagScopes)
+			if err != nil {
+				return err
+			}
+
+			program.RenderSuccess(fmt.Sprintf("Created service account %s", account.Name))
+			fmt.Printf("  Client ID:     %s\n", account.ClientId)
+			fmt.Printf("  Client secret: %s\n", secret)
+			fmt.Printf("\n%s\n", colors.Orange("The client secret cannot be shown again, so make sure to store it securely."))
+
+			return nil
+		})
+	},
+}
+
+var serviceAccountsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List service accounts and when they were last used",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAuthDatabase(func(ctx context.Context) error {
+			accounts, err := oauth.ListServiceAccounts(ctx)
+			if err != nil {
+				return err
+			}
+
+			if len(accounts) == 0 {
+				fmt.Println(colors.Gray("No service accounts found"))
+			}
+
+			for _, a := range accounts {
+				lastUsed := "never used"
+				if a.LastUsedAt != nil {
+					lastUsed = fmt.Sprintf("last used %s", a.LastUsedAt.Format("2006-01-02 15:04:05"))
+				}
+
+				details := fmt.Sprintf("%s [%s] %s", a.ClientId, strings.Join(a.Scopes, " "), lastUsed)
+
+				if a.RevokedAt != nil {
+					fmt.Printf("  %s %s %s\n", colors.Red("✘"), a.Name, colors.Red(fmt.Sprintf("(revoked %s)", a.RevokedAt.Format("2006-01-02 15:04:05"))))
+				} else {
+					fmt.Printf("  %s %s %s\n", colors.Green("✔"), a.Name, colors.Gray(details))
+				}
+			}
+
+			return nil
+		})
+	},
+}
+
+var serviceAccountsRevokeCmd = &cobra.Command{
+	Use:   "revoke <name>",
+	Short: "Revoke a service account, rejecting any access tokens it has been issued",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAuthDatabase(func(ctx context.Context) error {
+			revoked, err := oauth.RevokeServiceAccount(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			if !revoked {
+				return fmt.Errorf("no active service account named %s", args[0])
+			}
+
+			program.RenderSuccess(fmt.Sprintf("Revoked service account %s", args[0]))
+
+			return nil
+		})
+	},
+}
+
+// withAuthDatabase connects to the database in which service accounts and sessions are stored before calling fn.
+// Any error is rendered for the terminal.
+func withAuthDatabase(fn func(ctx context.Context) error) error {
+	ctx := context.Background()
+
+	conn, closeConn, err := connectDatabase(ctx)
+	if err != nil {
+		return program.RenderError(err)
+	}
+	defer closeConn()
+
+	err = fn(db.WithDatabase(ctx, conn))
+	if err != nil {
+		return program.RenderError(err)
+	}
+
+	return nil
+}
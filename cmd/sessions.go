@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/teamkeel/keel/cmd/program"
+	"github.com/teamkeel/keel/colors"
+	"github.com/teamkeel/keel/runtime/oauth"
+)
+
+var flagSessionId string
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Manage the sessions in which identities are signed in",
+	Long: `The sessions command allows you to list the sessions in which an
+identity is signed in and to revoke them, signing the identity out on
+those devices. The refresh tokens of a revoked session can no longer be
+used and its access tokens are rejected.
+
+Sessions can also be revoked at /auth/sessions/revoke by a service
+account which has been granted the sessions:revoke scope.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// list subcommands
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionsCmd)
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsRevokeCmd)
+	sessionsCmd.PersistentFlags().StringVar(&flagDatabaseURL, "database-url", "", "connection string of the database to use, instead of the local development database")
+	sessionsRevokeCmd.Flags().StringVar(&flagSessionId, "session", "", "only revoke the session with this ID, rather than all of the identity's sessions")
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list <identity-id>",
+	Short: "List the sessions in which an identity is signed in",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAuthDatabase(func(ctx context.Context) error {
+			sessions, err := oauth.ListSessions(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			if len(sessions) == 0 {
+				fmt.Println(colors.Gray("No sessions found"))
+			}
+
+			for _, s := range sessions {
+				details := fmt.Sprintf("%s %s signed in %s, last used %s", s.UserAgent, s.IpAddress, s.CreatedAt.Format("2006-01-02 15:04:05"), s.LastUsedAt.Format("2006-01-02 15:04:05"))
+				fmt.Printf("  %s %s %s\n", colors.Green("✔"), s.Id, colors.Gray(details))
+			}
+
+			return nil
+		})
+	},
+}
+
+var sessionsRevokeCmd = &cobra.Command{
+	Use:   "revoke <identity-id>",
+	Short: "Revoke an identity's sessions, signing it out on all devices",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAuthDatabase(func(ctx context.Context) error {
+			if flagSessionId != "" {
+				revoked, err := oauth.RevokeSession(ctx, args[0], flagSessionId)
+				if err != nil {
+					return err
+				}
+
+				if !revoked {
+					return fmt.Errorf("no active session %s for identity %s", flagSessionId, args[0])
+				}
+
+				program.RenderSuccess(fmt.Sprintf("Revoked session %s", flagSessionId))
+
+				return nil
+			}
+
+			revoked, err := oauth.RevokeAllSessions(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			program.RenderSuccess(fmt.Sprintf("Revoked %d sessions for identity %s", revoked, args[0]))
+
+			return nil
+		})
+	},
+}
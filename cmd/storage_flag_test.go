@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStorageFlag_Default(t *testing.T) {
+	err := runCmd.ParseFlags([]string{})
+	require.NoError(t, err)
+
+	require.Equal(t, "docker", flagRunStorage)
+	require.NoError(t, validateStorageFlag(flagRunStorage, "docker", "filesystem"))
+}
+
+func TestTestStorageFlag_Default(t *testing.T) {
+	err := testCmd.ParseFlags([]string{})
+	require.NoError(t, err)
+
+	require.Equal(t, "s3", flagTestStorage)
+	require.NoError(t, validateStorageFlag(flagTestStorage, "s3", "filesystem"))
+}
+
+func TestStorageFlag_Invalid(t *testing.T) {
+	err := validateStorageFlag("s3", "docker", "filesystem")
+	require.EqualError(t, err, "--storage must be either 'docker' or 'filesystem', got 's3'")
+}
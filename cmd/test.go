@@ -27,6 +27,11 @@ var testCmd = &cobra.Command{
 	Args:         cobra.NoArgs,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateStorageFlag(flagTestStorage, "s3", "filesystem"); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
 		// We still need a tracing provider for auditing and events,
 		// even if the data is not being exported.
 		otel.SetTracerProvider(sdktrace.NewTracerProvider())
@@ -69,11 +74,12 @@ var testCmd = &cobra.Command{
 		}
 
 		err = testing.Run(context.Background(), &testing.RunnerOpts{
-			Dir:            flagProjectDir,
-			Pattern:        flagPattern,
-			DbConnInfo:     connInfo,
-			Secrets:        secrets,
-			GenerateClient: false,
+			Dir:               flagProjectDir,
+			Pattern:           flagPattern,
+			DbConnInfo:        connInfo,
+			Secrets:           secrets,
+			GenerateClient:    false,
+			FilesystemStorage: flagTestStorage == "filesystem",
 		})
 
 		validationErrors := errorhandling.ValidationErrors{}
@@ -99,6 +105,7 @@ func init() {
 
 	testCmd.Flags().StringVarP(&flagPattern, "pattern", "p", "(.*)", "pattern to isolate test")
 	testCmd.Flags().StringVar(&flagPrivateKeyPath, "private-key-path", "", "path to the private key .pem file")
+	testCmd.Flags().StringVar(&flagTestStorage, "storage", "s3", "where files are stored, either 's3' (a mocked bucket) or 'filesystem' (a temporary directory)")
 
 	if enabledDebugFlags == "true" {
 		testCmd.Flags().StringVar(&flagNodePackagesPath, "node-packages-path", "", "path to local @teamkeel npm packages")
@@ -15,10 +15,26 @@ const (
 	DefaultAccessTokenExpiry time.Duration = time.Hour * 24
 	// 3 months is the default refresh token expiry period.
 	DefaultRefreshTokenExpiry time.Duration = time.Hour * 24 * 90
+	// 10 minutes is the default expiry period of passwordless magic links and one-time codes.
+	DefaultPasswordlessCodeExpiry time.Duration = time.Minute * 10
+	// 5 is the default number of attempts at a passwordless one-time code before it is invalidated.
+	DefaultPasswordlessMaxAttempts int = 5
+	// The default issuer shown alongside the account in authenticator apps.
+	DefaultMfaIssuer = "Keel"
+	// 5 is the default number of failed attempts for an identity before it is temporarily locked out.
+	DefaultLockoutMaxAttempts int = 5
+	// 50 is the default number of failed attempts from an IP address before it is temporarily locked out.
+	DefaultLockoutMaxAttemptsPerIp int = 50
+	// 5 minutes is the default duration of a lockout, which doubles with each further failed attempt.
+	DefaultLockoutDuration time.Duration = time.Minute * 5
+	// 24 hours is the default maximum duration of a lockout, after which failed attempts are also forgotten.
+	DefaultLockoutMaxDuration time.Duration = time.Hour * 24
 )
 
 const ProviderSecretPrefix = "AUTH_PROVIDER_SECRET_"
 
+const ServiceAccountSecretPrefix = "SERVICE_ACCOUNT_SECRET_"
+
 const ReservedProviderNamePrefix = "keel_"
 
 const (
@@ -52,6 +68,16 @@ type AuthConfig struct {
 	Providers   []Provider      `yaml:"providers"`
 	Claims      []IdentityClaim `yaml:"claims"`
 	Hooks       []FunctionHook  `yaml:"hooks"`
+	// Service accounts defined in config authenticate with their name as the client ID and
+	// the value of their secret as the client secret.
+	ServiceAccounts []ServiceAccount   `yaml:"serviceAccounts,omitempty"`
+	Passwordless    PasswordlessConfig `yaml:"passwordless,omitempty"`
+	// Email verification applies to identities which sign up with an email and password.
+	EmailVerification EmailVerificationConfig `yaml:"emailVerification,omitempty"`
+	// Multi-factor authentication is optional for identities unless required for one of their roles.
+	Mfa MfaConfig `yaml:"mfa,omitempty"`
+	// Lockouts protect the password grant, password reset requests and MFA codes from brute-force attempts.
+	Lockout LockoutConfig `yaml:"lockout,omitempty"`
 }
 
 type TokensConfig struct {
@@ -60,6 +86,29 @@ type TokensConfig struct {
 	RefreshTokenRotationEnabled *bool `yaml:"refreshTokenRotationEnabled,omitempty"`
 }
 
+type PasswordlessConfig struct {
+	CodeExpiry  *int `yaml:"codeExpiry,omitempty"`
+	MaxAttempts *int `yaml:"maxAttempts,omitempty"`
+}
+
+type EmailVerificationConfig struct {
+	Enabled  *bool `yaml:"enabled,omitempty"`
+	Required *bool `yaml:"required,omitempty"`
+}
+
+type MfaConfig struct {
+	Issuer        *string  `yaml:"issuer,omitempty"`
+	RequiredRoles []string `yaml:"requiredRoles,omitempty"`
+}
+
+type LockoutConfig struct {
+	Enabled          *bool `yaml:"enabled,omitempty"`
+	MaxAttempts      *int  `yaml:"maxAttempts,omitempty"`
+	MaxAttemptsPerIp *int  `yaml:"maxAttemptsPerIp,omitempty"`
+	Duration         *int  `yaml:"duration,omitempty"`
+	MaxDuration      *int  `yaml:"maxDuration,omitempty"`
+}
+
 type Provider struct {
 	Type      string   `yaml:"type"`
 	Name      string   `yaml:"name"`
@@ -68,6 +117,11 @@ type Provider struct {
 	Scopes    []string `yaml:"scopes,omitempty"`
 }
 
+type ServiceAccount struct {
+	Name   string   `yaml:"name"`
+	Scopes []string `yaml:"scopes,omitempty"`
+}
+
 type IdentityClaim struct {
 	Key    string `yaml:"key"`
 	Field  string `yaml:"field"`
@@ -105,6 +159,90 @@ func (c *AuthConfig) RefreshTokenRotationEnabled() bool {
 	}
 }
 
+// PasswordlessCodeExpiry retrieves the configured or default expiry of passwordless magic links and one-time codes.
+func (c *AuthConfig) PasswordlessCodeExpiry() time.Duration {
+	if c.Passwordless.CodeExpiry != nil {
+		return time.Duration(*c.Passwordless.CodeExpiry) * time.Second
+	} else {
+		return DefaultPasswordlessCodeExpiry
+	}
+}
+
+// PasswordlessMaxAttempts retrieves the configured or default number of attempts at a passwordless one-time code.
+func (c *AuthConfig) PasswordlessMaxAttempts() int {
+	if c.Passwordless.MaxAttempts != nil {
+		return *c.Passwordless.MaxAttempts
+	} else {
+		return DefaultPasswordlessMaxAttempts
+	}
+}
+
+// EmailVerificationEnabled retrieves whether a verification email is sent when an identity signs up with a password,
+// which is always the case when verification is required.
+func (c *AuthConfig) EmailVerificationEnabled() bool {
+	return (c.EmailVerification.Enabled != nil && *c.EmailVerification.Enabled) || c.EmailVerificationRequired()
+}
+
+// EmailVerificationRequired retrieves whether tokens are only issued to password identities once their email is verified.
+func (c *AuthConfig) EmailVerificationRequired() bool {
+	return c.EmailVerification.Required != nil && *c.EmailVerification.Required
+}
+
+// MfaIssuer retrieves the configured or default issuer shown alongside the account in authenticator apps.
+func (c *AuthConfig) MfaIssuer() string {
+	if c.Mfa.Issuer != nil {
+		return *c.Mfa.Issuer
+	} else {
+		return DefaultMfaIssuer
+	}
+}
+
+// MfaRequiredRoles retrieves the roles for which identities must authenticate with a second factor.
+func (c *AuthConfig) MfaRequiredRoles() []string {
+	return c.Mfa.RequiredRoles
+}
+
+// LockoutEnabled retrieves whether identities and IP addresses are temporarily locked out after too many failed attempts.
+func (c *AuthConfig) LockoutEnabled() bool {
+	return c.Lockout.Enabled == nil || *c.Lockout.Enabled
+}
+
+// LockoutMaxAttempts retrieves the configured or default number of failed attempts for an identity before it is locked out.
+func (c *AuthConfig) LockoutMaxAttempts() int {
+	if c.Lockout.MaxAttempts != nil {
+		return *c.Lockout.MaxAttempts
+	} else {
+		return DefaultLockoutMaxAttempts
+	}
+}
+
+// LockoutMaxAttemptsPerIp retrieves the configured or default number of failed attempts from an IP address before it is locked out.
+func (c *AuthConfig) LockoutMaxAttemptsPerIp() int {
+	if c.Lockout.MaxAttemptsPerIp != nil {
+		return *c.Lockout.MaxAttemptsPerIp
+	} else {
+		return DefaultLockoutMaxAttemptsPerIp
+	}
+}
+
+// LockoutDuration retrieves the configured or default duration of a lockout.
+func (c *AuthConfig) LockoutDuration() time.Duration {
+	if c.Lockout.Duration != nil {
+		return time.Duration(*c.Lockout.Duration) * time.Second
+	} else {
+		return DefaultLockoutDuration
+	}
+}
+
+// LockoutMaxDuration retrieves the configured or default maximum duration of a lockout.
+func (c *AuthConfig) LockoutMaxDuration() time.Duration {
+	if c.Lockout.MaxDuration != nil {
+		return time.Duration(*c.Lockout.MaxDuration) * time.Second
+	} else {
+		return DefaultLockoutMaxDuration
+	}
+}
+
 // AddOidcProvider adds an OpenID Connect provider to the list of supported authentication providers.
 func (c *AuthConfig) AddOidcProvider(name string, issuerUrl string, clientId string) error {
 	if name == "" {
@@ -213,6 +351,15 @@ func (p *Provider) GetCallbackUrl() (*url.URL, error) {
 	return apiUrl.JoinPath("/auth/callback/" + strings.ToLower(p.Name)), nil
 }
 
+// GetVerifyEmailUrl retrieves the URL of the endpoint which verifies the email of an identity.
+func (c *AuthConfig) GetVerifyEmailUrl() (*url.URL, error) {
+	apiUrl, err := url.ParseRequestURI(os.Getenv("KEEL_API_URL"))
+	if err != nil {
+		return nil, err
+	}
+	return apiUrl.JoinPath("/auth/verify"), nil
+}
+
 // GetProvider retrieves the provider by its name (case insensitive).
 func (c *AuthConfig) GetProvider(name string) *Provider {
 	for _, p := range c.Providers {
@@ -223,6 +370,21 @@ func (c *AuthConfig) GetProvider(name string) *Provider {
 	return nil
 }
 
+// GetServiceAccount retrieves the service account defined in config by its name.
+func (c *AuthConfig) GetServiceAccount(name string) *ServiceAccount {
+	for _, a := range c.ServiceAccounts {
+		if a.Name == name {
+			return &a
+		}
+	}
+	return nil
+}
+
+// GetClientSecretName generates the name of the secret which holds the service account's client secret.
+func (a *ServiceAccount) GetClientSecretName() string {
+	return fmt.Sprintf("%s%s", ServiceAccountSecretPrefix, strings.ToUpper(a.Name))
+}
+
 // GetIssuerUrl retrieves the issuer URL for the provider.
 func (p *Provider) GetIssuerUrl() (string, bool) {
 	switch p.Type {
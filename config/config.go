@@ -36,6 +36,8 @@ type ProjectConfig struct {
 	Secrets       []Secret              `yaml:"secrets"`
 	Auth          AuthConfig            `yaml:"auth"`
 	Console       ConsoleConfig         `yaml:"console"`
+	Idempotency   IdempotencyConfig     `yaml:"idempotency"`
+	RateLimits    []RateLimitConfig     `yaml:"rateLimits,omitempty"`
 	Hardware      *HardwareConfig       `yaml:"hardware"`
 	Deploy        *DeployConfig         `yaml:"deploy,omitempty"`
 }
@@ -355,6 +357,11 @@ func validateUniqueNames(c *ProjectConfig) []*ConfigError {
 	})
 	errors = append(errors, validateUnique(values, "auth.providers.%d.name")...)
 
+	values = lo.Map(c.Auth.ServiceAccounts, func(a ServiceAccount, _ int) string {
+		return a.Name
+	})
+	errors = append(errors, validateUnique(values, "auth.serviceAccounts.%d.name")...)
+
 	return errors
 }
 
@@ -69,6 +69,57 @@ func TestAuthDefaults(t *testing.T) {
 	assert.Equal(t, time.Duration(24)*time.Hour, config.Auth.AccessTokenExpiry())
 	assert.Equal(t, time.Duration(24)*time.Hour*90, config.Auth.RefreshTokenExpiry())
 	assert.Equal(t, true, config.Auth.RefreshTokenRotationEnabled())
+	assert.Equal(t, time.Duration(10)*time.Minute, config.Auth.PasswordlessCodeExpiry())
+	assert.Equal(t, 5, config.Auth.PasswordlessMaxAttempts())
+	assert.Equal(t, false, config.Auth.EmailVerificationEnabled())
+	assert.Equal(t, false, config.Auth.EmailVerificationRequired())
+	assert.Equal(t, "Keel", config.Auth.MfaIssuer())
+	assert.Empty(t, config.Auth.MfaRequiredRoles())
+	assert.Equal(t, true, config.Auth.LockoutEnabled())
+	assert.Equal(t, 5, config.Auth.LockoutMaxAttempts())
+	assert.Equal(t, 50, config.Auth.LockoutMaxAttemptsPerIp())
+	assert.Equal(t, time.Duration(5)*time.Minute, config.Auth.LockoutDuration())
+	assert.Equal(t, time.Duration(24)*time.Hour, config.Auth.LockoutMaxDuration())
+}
+
+func TestAuthPasswordless(t *testing.T) {
+	t.Parallel()
+	config, err := config.Load("fixtures/test_auth_passwordless.yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.Duration(300)*time.Second, config.Auth.PasswordlessCodeExpiry())
+	assert.Equal(t, 3, config.Auth.PasswordlessMaxAttempts())
+}
+
+func TestAuthEmailVerification(t *testing.T) {
+	t.Parallel()
+	config, err := config.Load("fixtures/test_auth_email_verification.yaml")
+	assert.NoError(t, err)
+
+	// Verification emails are always sent when verification is required
+	assert.Equal(t, true, config.Auth.EmailVerificationEnabled())
+	assert.Equal(t, true, config.Auth.EmailVerificationRequired())
+}
+
+func TestAuthMfa(t *testing.T) {
+	t.Parallel()
+	config, err := config.Load("fixtures/test_auth_mfa.yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Acme", config.Auth.MfaIssuer())
+	assert.Equal(t, []string{"Admin", "Support"}, config.Auth.MfaRequiredRoles())
+}
+
+func TestAuthLockout(t *testing.T) {
+	t.Parallel()
+	config, err := config.Load("fixtures/test_auth_lockout.yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, false, config.Auth.LockoutEnabled())
+	assert.Equal(t, 3, config.Auth.LockoutMaxAttempts())
+	assert.Equal(t, 10, config.Auth.LockoutMaxAttemptsPerIp())
+	assert.Equal(t, time.Duration(60)*time.Second, config.Auth.LockoutDuration())
+	assert.Equal(t, time.Duration(3600)*time.Second, config.Auth.LockoutMaxDuration())
 }
 
 func TestGetOidcIssuer(t *testing.T) {
@@ -173,3 +224,12 @@ func TestGetCallbackUrl_NoKeelApiUrl(t *testing.T) {
 	assert.ErrorContains(t, err, "empty url")
 	assert.Nil(t, url)
 }
+
+func TestGetVerifyEmailUrl(t *testing.T) {
+	auth := &config.AuthConfig{}
+	t.Setenv("KEEL_API_URL", "https://myapplication.com/keel/")
+
+	url, err := auth.GetVerifyEmailUrl()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://myapplication.com/keel/auth/verify", url.String())
+}
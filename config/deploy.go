@@ -1,11 +1,12 @@
 package config
 
 type DeployConfig struct {
-	ProjectName string           `yaml:"projectName"`
-	Region      string           `yaml:"region"`
-	Database    *DatabaseConfig  `yaml:"database,omitempty"`
-	Jobs        *JobsConfig      `yaml:"jobs,omitempty"`
-	Telemetry   *TelemetryConfig `yaml:"telemetry,omitempty"`
+	ProjectName string            `yaml:"projectName"`
+	Region      string            `yaml:"region"`
+	Database    *DatabaseConfig   `yaml:"database,omitempty"`
+	Jobs        *JobsConfig       `yaml:"jobs,omitempty"`
+	Telemetry   *TelemetryConfig  `yaml:"telemetry,omitempty"`
+	Migrations  *MigrationsConfig `yaml:"migrations,omitempty"`
 }
 
 type DatabaseConfig struct {
@@ -27,6 +28,16 @@ type TelemetryConfig struct {
 	Collector string `yaml:"collector,omitempty"`
 }
 
+type MigrationsConfig struct {
+	// Allows migrations which drop tables or columns to be applied without confirmation
+	AllowDestructive bool `yaml:"allowDestructive,omitempty"`
+}
+
+// AllowDestructiveMigrations returns true if the config allows destructive migrations to be applied when deploying.
+func (c *DeployConfig) AllowDestructiveMigrations() bool {
+	return c != nil && c.Migrations != nil && c.Migrations.AllowDestructive
+}
+
 func validateDatabase(c *ProjectConfig) []*ConfigError {
 	errors := []*ConfigError{}
 
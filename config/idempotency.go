@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+// 24 hours is the default period in which a request with the same Idempotency-Key is replayed.
+const DefaultIdempotencyWindow time.Duration = time.Hour * 24
+
+type IdempotencyConfig struct {
+	// The number of seconds for which the response to a request with an Idempotency-Key is kept
+	Window *int `yaml:"window,omitempty"`
+}
+
+// IdempotencyWindow retrieves the configured or default period in which idempotent requests are replayed.
+func (c *IdempotencyConfig) IdempotencyWindow() time.Duration {
+	if c.Window != nil {
+		return time.Duration(*c.Window) * time.Second
+	}
+	return DefaultIdempotencyWindow
+}
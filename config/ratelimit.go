@@ -0,0 +1,32 @@
+package config
+
+import "strings"
+
+const (
+	// Requests are counted per authenticated identity or service account, or per IP address for unauthenticated requests.
+	RateLimitByIdentity = "identity"
+	// Requests are counted per IP address.
+	RateLimitByIP = "ip"
+)
+
+// RateLimitConfig is a token bucket rule. Each identity or IP address has a bucket holding up to Limit
+// requests, which refills at a steady rate so that it is full again after Period seconds.
+type RateLimitConfig struct {
+	// The API the rule applies to, otherwise it applies to all APIs
+	Api string `yaml:"api,omitempty"`
+	// The action the rule applies to, otherwise all actions share a single bucket
+	Action string `yaml:"action,omitempty"`
+	By     string `yaml:"by,omitempty"`
+	Limit  int    `yaml:"limit"`
+	Period int    `yaml:"period"`
+}
+
+// Matches returns true if the rule applies to requests for the action in the API.
+func (r *RateLimitConfig) Matches(api string, action string) bool {
+	return (r.Api == "" || strings.EqualFold(r.Api, api)) && (r.Action == "" || r.Action == action)
+}
+
+// ByIP returns true if requests are counted per IP address, even for authenticated identities.
+func (r *RateLimitConfig) ByIP() bool {
+	return r.By == RateLimitByIP
+}
@@ -31,6 +31,9 @@ type DbError struct {
 	Columns []string
 	// the primary human-readable error message. This should be accurate but terse (typically one line). Always present
 	Message string
+	// if a foreign key violation, whether it was caused by deleting or updating a record which is still referenced
+	// by the table, rather than by referencing a record which does not exist
+	StillReferenced bool
 	// the SQLSTATE code for the error - https://www.postgresql.org/docs/current/errcodes-appendix.html. Always present
 	PgErrCode string
 	// the underlying error
@@ -95,7 +95,13 @@ func (db *GormDB) Transaction(ctx context.Context, fn func(context.Context) erro
 	ctx, span := tracer.Start(ctx, "Database Transaction")
 	defer span.End()
 
-	return db.db.Transaction(func(tx *gorm.DB) (err error) {
+	// If already in a transaction then a nested transaction is created using a savepoint
+	conn := db.db
+	if v, ok := ctx.Value(transactionCtxKey).(*gorm.DB); ok {
+		conn = v
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) (err error) {
 		ctx = context.WithValue(ctx, transactionCtxKey, tx)
 		return fn(ctx)
 	})
@@ -130,9 +136,15 @@ func toDbError(err error) error {
 
 	switch pgErr.Code {
 	case PgForeignKeyConstraintViolation:
+		// A restrict or no action foreign key fails with "Key (id)=(2L2ar5NCPvTTEdiDYqgcpF3f5QN1) is still referenced from table \"post\"."
+		// when deleting the referenced record, in which case the table of the error is the referencing table
+		dbErr.StillReferenced = strings.Contains(pgErr.Detail, "is still referenced from table")
+
 		// Extract column and value from "Key (author_id)=(2L2ar5NCPvTTEdiDYqgcpF3f5QN1) is not present in table \"author\"."
 		out := regexp.MustCompile(`\(([^)]+)\)`).FindAllStringSubmatch(pgErr.Detail, -1)
-		dbErr.Columns = []string{out[0][1]}
+		if len(out) > 0 {
+			dbErr.Columns = []string{out[0][1]}
+		}
 	case PgUniqueConstraintViolation:
 		// Extract column and value from "Key (code)=(1234) already exists."
 		out := regexp.MustCompile(`\(([^)]+)\)`).FindAllStringSubmatch(pgErr.Detail, -1)
@@ -20,11 +20,12 @@ func main() {
 		SecretNames:    strings.Split(os.Getenv("KEEL_SECRETS"), ":"),
 
 		// AWS resources
-		EventsQueueURL:   os.Getenv("KEEL_EVENTS_QUEUE_URL"),
-		FlowsQueueURL:    os.Getenv("KEEL_FLOWS_QUEUE_URL"),
-		SchedulerRoleARN: os.Getenv("KEEL_SCHEDULER_ROLE_ARN"),
-		FunctionsARN:     os.Getenv("KEEL_FUNCTIONS_ARN"),
-		BucketName:       os.Getenv("KEEL_FILES_BUCKET_NAME"),
+		EventsQueueURL:    os.Getenv("KEEL_EVENTS_QUEUE_URL"),
+		FlowsQueueURL:     os.Getenv("KEEL_FLOWS_QUEUE_URL"),
+		SchedulerRoleARN:  os.Getenv("KEEL_SCHEDULER_ROLE_ARN"),
+		FunctionsARN:      os.Getenv("KEEL_FUNCTIONS_ARN"),
+		BucketName:        os.Getenv("KEEL_FILES_BUCKET_NAME"),
+		WebSocketEndpoint: os.Getenv("KEEL_WEBSOCKET_ENDPOINT"),
 
 		// RDS
 		DBEndpoint:  os.Getenv("KEEL_DATABASE_ENDPOINT"),
@@ -50,5 +51,7 @@ func main() {
 		lambda.Start(h.JobHandler)
 	case runtime.RuntimeModeFlow:
 		lambda.Start(h.FlowHandler)
+	case runtime.RuntimeModeWebSocket:
+		lambda.Start(h.WebSocketHandler)
 	}
 }
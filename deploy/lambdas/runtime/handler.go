@@ -18,6 +18,7 @@ import (
 	"github.com/teamkeel/keel/events"
 	"github.com/teamkeel/keel/functions"
 	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/apis/graphql"
 	"github.com/teamkeel/keel/runtime/flows"
 	"github.com/teamkeel/keel/runtime/runtimectx"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -37,6 +38,9 @@ const (
 
 	// Handles flows.
 	RuntimeModeFlow = "flow"
+
+	// Handles WebSocket connections for GraphQL subscriptions.
+	RuntimeModeWebSocket = "websocket"
 )
 
 type Handler struct {
@@ -53,6 +57,7 @@ type Handler struct {
 	tracer             trace.Tracer
 	tracerProvider     *sdktrace.TracerProvider
 	flowOrchestrator   *flows.Orchestrator
+	connections        *ConnectionSender
 }
 
 type HandlerArgs struct {
@@ -73,10 +78,15 @@ type HandlerArgs struct {
 	FlowsQueueURL string
 	// ARN fo the iam role for scheduling
 	SchedulerRoleARN string
+	// Management endpoint of the API Gateway WebSocket API used for GraphQL subscriptions
+	WebSocketEndpoint string
 	// Full ARN of functions Lambda.
 	FunctionsARN string
 	// Bucket name used for files and job inputs
 	BucketName string
+	// If set files are stored with this storer rather than in the bucket. This is used by keel test to
+	// store files on the filesystem.
+	FilesStorage storage.Storer
 	// List of secret names to looad from SSM
 	SecretNames []string
 	// Webhook URL to use for sending job run updates
@@ -150,6 +160,11 @@ func New(ctx context.Context, args *HandlerArgs) (*Handler, error) {
 		return nil, err
 	}
 
+	connections, err := initConnections(ctx, args.WebSocketEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
 	h := &Handler{
 		args:               args,
 		log:                log,
@@ -164,6 +179,7 @@ func New(ctx context.Context, args *HandlerArgs) (*Handler, error) {
 		tracer:             tracer,
 		tracerProvider:     tracerProvider,
 		flowOrchestrator:   flowOrchestrator,
+		connections:        connections,
 	}
 
 	return h, nil
@@ -242,9 +258,15 @@ func initLogger(level string) (*logrus.Logger, error) {
 
 func (h *Handler) buildContext(ctx context.Context) (context.Context, error) {
 	ctx = runtimectx.WithOAuthConfig(ctx, &h.config.Auth)
+	ctx = runtimectx.WithIdempotencyConfig(ctx, &h.config.Idempotency)
+	ctx = runtimectx.WithRateLimits(ctx, h.config.RateLimits)
 	ctx = runtimectx.WithPrivateKey(ctx, h.privateKey)
 	ctx = runtimectx.WithSecrets(ctx, h.secrets)
-	ctx = runtimectx.WithStorage(ctx, h.filesStorage)
+	if h.args.FilesStorage != nil {
+		ctx = runtimectx.WithStorage(ctx, h.args.FilesStorage)
+	} else {
+		ctx = runtimectx.WithStorage(ctx, h.filesStorage)
+	}
 	ctx = db.WithDatabase(ctx, h.db)
 	ctx = functions.WithFunctionsTransport(ctx, h.functionsTransport)
 
@@ -255,6 +277,11 @@ func (h *Handler) buildContext(ctx context.Context) (context.Context, error) {
 
 	ctx = flows.WithOrchestrator(ctx, h.flowOrchestrator)
 
+	// Events are delivered to GraphQL subscriptions on connections held by API Gateway
+	if h.connections != nil {
+		ctx = events.WithEventPublisher(ctx, graphql.NewConnectionPublisher(h.schema, h.connections))
+	}
+
 	return ctx, nil
 }
 
@@ -0,0 +1,199 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	lambdaevents "github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+	"github.com/teamkeel/keel/runtime/apis/graphql"
+	"github.com/teamkeel/keel/runtime/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ConnectionSender sends messages to WebSocket connections held by API Gateway using
+// its management API, see https://docs.aws.amazon.com/apigateway/latest/developerguide/apigateway-how-to-call-websocket-api-connections.html
+type ConnectionSender struct {
+	endpoint    string
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+var _ graphql.ConnectionSender = &ConnectionSender{}
+
+func initConnections(ctx context.Context, endpoint string) (*ConnectionSender, error) {
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConnectionSender{
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		region:      cfg.Region,
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		client:      http.DefaultClient,
+	}, nil
+}
+
+// Send posts the data to the connection.
+func (s *ConnectionSender) Send(ctx context.Context, connectionId string, data []byte) error {
+	return s.do(ctx, http.MethodPost, connectionId, data)
+}
+
+// Close disconnects the client.
+func (s *ConnectionSender) Close(ctx context.Context, connectionId string) error {
+	return s.do(ctx, http.MethodDelete, connectionId, nil)
+}
+
+func (s *ConnectionSender) do(ctx context.Context, method string, connectionId string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/@connections/%s", s.endpoint, url.PathEscape(connectionId)), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(body)
+	err = s.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "execute-api", s.region, time.Now())
+	if err != nil {
+		return err
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusGone:
+		return graphql.ErrConnectionGone
+	case res.StatusCode >= 300:
+		return fmt.Errorf("unexpected status code %d from API Gateway management API", res.StatusCode)
+	}
+
+	return nil
+}
+
+// WebSocketHandler handles the $connect, $disconnect and $default routes of the API Gateway WebSocket API
+// which serves GraphQL subscriptions. The API is chosen with the "api" query string parameter, e.g.
+// wss://example.execute-api.eu-west-2.amazonaws.com/default?api=web, and defaults to the "api" API.
+func (h *Handler) WebSocketHandler(ctx context.Context, request lambdaevents.APIGatewayWebsocketProxyRequest) (lambdaevents.APIGatewayProxyResponse, error) {
+	defer func() {
+		if h.tracerProvider != nil {
+			h.tracerProvider.ForceFlush(ctx)
+		}
+	}()
+
+	ctx, span := h.tracer.Start(ctx, fmt.Sprintf("WebSocket %s", request.RequestContext.RouteKey))
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("type", "websocket"),
+		attribute.String("websocket.route", request.RequestContext.RouteKey),
+		attribute.String("websocket.connectionId", request.RequestContext.ConnectionID),
+		attribute.String("aws.requestID", request.RequestContext.RequestID),
+	)
+
+	ctx, err := h.buildContext(ctx)
+	if err != nil {
+		span.RecordError(err, trace.WithStackTrace(true))
+		span.SetStatus(codes.Error, err.Error())
+		return lambdaevents.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, nil
+	}
+
+	if h.connections == nil {
+		err := errors.New("websocket endpoint has not been configured")
+		span.SetStatus(codes.Error, err.Error())
+		return lambdaevents.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, nil
+	}
+
+	connectionId := request.RequestContext.ConnectionID
+
+	switch request.RequestContext.EventType {
+	case "CONNECT":
+		headers := http.Header{}
+		for k, v := range request.MultiValueHeaders {
+			for _, value := range v {
+				headers.Add(k, value)
+			}
+		}
+
+		if !lo.Contains(strings.Split(strings.ReplaceAll(headers.Get("Sec-WebSocket-Protocol"), " ", ""), ","), graphql.WebSocketSubprotocol) {
+			return lambdaevents.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest}, nil
+		}
+
+		apiName := request.QueryStringParameters["api"]
+		if apiName == "" {
+			apiName = "api"
+		}
+
+		err := graphql.OpenConnection(ctx, h.schema, connectionId, apiName, headers)
+		var runtimeErr common.RuntimeError
+		switch {
+		case errors.Is(err, graphql.ErrConnectionApiNotFound):
+			return lambdaevents.APIGatewayProxyResponse{StatusCode: http.StatusNotFound}, nil
+		case errors.As(err, &runtimeErr):
+			return lambdaevents.APIGatewayProxyResponse{StatusCode: http.StatusUnauthorized}, nil
+		case err != nil:
+			span.RecordError(err, trace.WithStackTrace(true))
+			span.SetStatus(codes.Error, err.Error())
+			return lambdaevents.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, nil
+		}
+
+		return lambdaevents.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers: map[string]string{
+				"Sec-WebSocket-Protocol": graphql.WebSocketSubprotocol,
+			},
+		}, nil
+	case "DISCONNECT":
+		err = graphql.CloseConnection(ctx, connectionId)
+	default:
+		body := []byte(request.Body)
+		if request.IsBase64Encoded {
+			body, err = base64.StdEncoding.DecodeString(request.Body)
+			if err != nil {
+				return lambdaevents.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest}, nil
+			}
+		}
+
+		err = graphql.HandleConnectionMessage(ctx, h.schema, h.connections, connectionId, body)
+	}
+
+	if err != nil {
+		h.log.WithError(err).WithFields(logrus.Fields{
+			"connectionId": connectionId,
+		}).Error("error handling websocket request")
+		span.RecordError(err, trace.WithStackTrace(true))
+		span.SetStatus(codes.Error, err.Error())
+		return lambdaevents.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, nil
+	}
+
+	return lambdaevents.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+}
@@ -3,6 +3,7 @@ package deploy
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -16,12 +17,15 @@ import (
 )
 
 type RunMigrationsArgs struct {
-	AwsConfig aws.Config
-	Stack     *auto.Stack
-	Env       string
-	Schema    *proto.Schema
-	Config    *config.ProjectConfig
-	DryRun    bool
+	AwsConfig   aws.Config
+	Stack       *auto.Stack
+	Env         string
+	ProjectRoot string
+	Schema      *proto.Schema
+	Config      *config.ProjectConfig
+	DryRun      bool
+	// Whether changes which delete data can be applied
+	AllowDestructive bool
 }
 
 func runMigrations(ctx context.Context, args *RunMigrationsArgs) error {
@@ -76,12 +80,27 @@ func runMigrations(ctx context.Context, args *RunMigrationsArgs) error {
 		return err
 	}
 
+	files, err := migrations.ReadMigrationFiles(filepath.Join(args.ProjectRoot, migrations.MigrationsDirectory))
+	if err != nil {
+		log(ctx, "%s Error reading migration files: %s", IconCross, gray("%s", err.Error()))
+		return err
+	}
+
+	if len(files) > 0 {
+		return runMigrationFiles(ctx, args, dbConn, files, t)
+	}
+
 	m, err := migrations.New(ctx, args.Schema, dbConn)
 	if err != nil {
 		log(ctx, "%s Error generating database migrations: %s", IconCross, gray("%s", err.Error()))
 		return err
 	}
 
+	if !args.DryRun && m.HasDestructiveChanges() && !args.AllowDestructive {
+		log(ctx, "%s %s", IconCross, migrations.ErrDestructiveChanges.Error())
+		return migrations.ErrDestructiveChanges
+	}
+
 	err = m.Apply(ctx, args.DryRun)
 	if err != nil {
 		message := "Error applying database migrations"
@@ -98,6 +117,10 @@ func runMigrations(ctx context.Context, args *RunMigrationsArgs) error {
 		return err
 	}
 
+	for _, rename := range m.StaleRenames {
+		log(ctx, "%s %s has already been applied and can be removed from your schema", IconPipe, orange("%s", rename))
+	}
+
 	if args.DryRun {
 		switch {
 		case len(m.Changes) == 0:
@@ -118,11 +141,19 @@ func runMigrations(ctx context.Context, args *RunMigrationsArgs) error {
 					action = red("(removed)")
 				case migrations.ChangeTypeModified:
 					action = gray("(modified)")
+				case migrations.ChangeTypeRenamed:
+					action = gray("(renamed from %s)", ch.RenamedFrom)
 				}
 
-				log(ctx, "    - %s %s", orange("%s", message), action)
+				log(ctx, "    - %s %s%s", orange("%s", message), action, safetySummary(ctx, m, ch))
 			}
 		}
+
+		if m.HasDestructiveChanges() && !args.AllowDestructive {
+			log(ctx, "%s Some changes will permanently delete data. To apply them run again with %s or set %s in your config file", IconCross, orange("--allow-destructive-migrations"), orange("deploy.migrations.allowDestructive"))
+			return migrations.ErrDestructiveChanges
+		}
+
 		return nil
 	}
 
@@ -135,3 +166,81 @@ func runMigrations(ctx context.Context, args *RunMigrationsArgs) error {
 
 	return nil
 }
+
+// runMigrationFiles applies and records the project's pending migration files, after which the database must match
+// the schema, as changes to models and fields which are not in a migration file are not applied.
+func runMigrationFiles(ctx context.Context, args *RunMigrationsArgs, dbConn db.Database, files []*migrations.MigrationFile, t *Timing) error {
+	if args.DryRun {
+		pending, err := migrations.PendingMigrations(ctx, dbConn, files)
+		if err != nil {
+			log(ctx, "%s Database migrations can not be applied: %s", IconCross, err.Error())
+			return err
+		}
+
+		if len(pending) > 0 {
+			log(ctx, "%s The following migrations will be applied %s", IconTick, t.Since())
+			for _, f := range pending {
+				log(ctx, "    - %s", orange("%s", f.Filename()))
+			}
+			return nil
+		}
+
+		// Once every migration file has been applied any difference from the schema can be checked for
+		m, err := migrations.New(ctx, args.Schema, dbConn)
+		if err != nil {
+			log(ctx, "%s Error generating database migrations: %s", IconCross, gray("%s", err.Error()))
+			return err
+		}
+
+		if m.HasEntityFieldChanges() {
+			log(ctx, "%s %s", IconCross, migrations.ErrUnmigratedChanges.Error())
+			return migrations.ErrUnmigratedChanges
+		}
+
+		log(ctx, "%s No pending migrations %s", IconTick, t.Since())
+		return nil
+	}
+
+	applied, m, err := migrations.UpAndApply(ctx, args.Schema, dbConn, files)
+	if err != nil {
+		log(ctx, "%s Error applying database migrations: %s", IconCross, err.Error())
+		return err
+	}
+
+	for _, rename := range m.StaleRenames {
+		log(ctx, "%s %s has already been applied and can be removed from your schema", IconPipe, orange("%s", rename))
+	}
+
+	switch {
+	case len(applied) == 0:
+		log(ctx, "%s Database schema is up-to-date %s", IconTick, t.Since())
+	default:
+		log(ctx, "%s %s migrations applied %s", IconTick, orange("%d", len(applied)), t.Since())
+		for _, f := range applied {
+			log(ctx, "    - %s", orange("%s", f.Filename()))
+		}
+	}
+
+	return nil
+}
+
+// safetySummary describes the risk of applying a change which is not safe, including
+// an estimate of the number of rows in the affected table.
+func safetySummary(ctx context.Context, m *migrations.Migrations, ch *migrations.DatabaseChange) string {
+	if ch.Safety == migrations.SafetySafe {
+		return ""
+	}
+
+	rows := "unknown number of rows"
+	estimate, err := m.EstimateRows(ctx, ch)
+	if err == nil {
+		rows = fmt.Sprintf("~%d rows", estimate)
+	}
+
+	label := orange("[locking]")
+	if ch.Safety == migrations.SafetyDestructive {
+		label = red("[destructive]")
+	}
+
+	return fmt.Sprintf(" %s %s", label, gray("%s, %s", ch.Reason, rows))
+}
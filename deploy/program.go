@@ -8,6 +8,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	pulumiaws "github.com/pulumi/pulumi-aws/sdk/v6/go/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/apigatewayv2"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/lambda"
@@ -21,8 +22,12 @@ import (
 	"github.com/teamkeel/keel/deploy/lambdas/runtime"
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/flows"
+	"github.com/teamkeel/keel/storage"
 )
 
+// The stage of the WebSocket API used for GraphQL subscriptions.
+const webSocketStageName = "default"
+
 // https://github.com/open-telemetry/opentelemetry-lambda/releases/tag/layer-collector%2F0.12.0
 const otelCollectorLayer = "arn:aws:lambda:%s:184161586896:layer:opentelemetry-collector-amd64-0_12_0:1"
 
@@ -74,6 +79,27 @@ func createProgram(args *NewProgramArgs) pulumi.RunFunc {
 			return err
 		}
 
+		// Files uploaded directly to the bucket are moved out of the uploads
+		// prefix when used in an action, so anything left there is not needed.
+		_, err = s3.NewBucketLifecycleConfigurationV2(ctx, "file-storage-uploads", &s3.BucketLifecycleConfigurationV2Args{
+			Bucket: filesBucket.ID(),
+			Rules: s3.BucketLifecycleConfigurationV2RuleArray{
+				&s3.BucketLifecycleConfigurationV2RuleArgs{
+					Id:     pulumi.String("expire-uploads"),
+					Status: pulumi.String("Enabled"),
+					Filter: &s3.BucketLifecycleConfigurationV2RuleFilterArgs{
+						Prefix: pulumi.String(storage.UploadObjectPrefix),
+					},
+					Expiration: &s3.BucketLifecycleConfigurationV2RuleExpirationArgs{
+						Days: pulumi.Int(int(storage.UploadExpiryDuration.Hours() / 24)),
+					},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
 		// All events go to this eventQueue
 		eventQueue, err := sqs.NewQueue(ctx, "events", &sqs.QueueArgs{
 			Tags: baseTags,
@@ -178,6 +204,20 @@ func createProgram(args *NewProgramArgs) pulumi.RunFunc {
 			return fmt.Errorf("error creating runtime lambda: %v", err)
 		}
 
+		// We avoid creating resources we don't need by only creating the WebSocket API
+		// if there are GraphQL subscriptions to serve
+		var webSocketApi *apigatewayv2.Api
+		if hasSubscriptions(args.Schema) {
+			webSocketApi, err = apigatewayv2.NewApi(ctx, "websocket", &apigatewayv2.ApiArgs{
+				ProtocolType:             pulumi.String("WEBSOCKET"),
+				RouteSelectionExpression: pulumi.String("$request.body.type"),
+				Tags:                     baseTags,
+			})
+			if err != nil {
+				return fmt.Errorf("error creating websocket api: %v", err)
+			}
+		}
+
 		// Permissions for runtime Lambdas
 		runtimePolicyStatements := iam.GetPolicyDocumentStatementArray{
 			// Read any parameter from SSM for the project/env
@@ -252,6 +292,18 @@ func createProgram(args *NewProgramArgs) pulumi.RunFunc {
 			}))
 		}
 
+		if webSocketApi != nil {
+			// Send messages to and close WebSocket connections
+			runtimePolicyStatements = append(runtimePolicyStatements, iam.GetPolicyDocumentStatementInput(iam.GetPolicyDocumentStatementArgs{
+				Actions: pulumi.ToStringArray([]string{
+					"execute-api:ManageConnections",
+				}),
+				Resources: pulumi.ToStringArrayOutput([]pulumi.StringOutput{
+					pulumi.Sprintf("%s/*", webSocketApi.ExecutionArn),
+				}),
+			}))
+		}
+
 		runtimeRole, err := createLambdaRole(ctx, "runtime", runtimePolicyStatements, baseTags)
 		if err != nil {
 			return err
@@ -287,6 +339,11 @@ func createProgram(args *NewProgramArgs) pulumi.RunFunc {
 			})
 		}
 
+		// The management endpoint for WebSocket connections
+		if webSocketApi != nil {
+			baseRuntimeEnvVars["KEEL_WEBSOCKET_ENDPOINT"] = pulumi.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s", webSocketApi.ID(), region, webSocketStageName)
+		}
+
 		// OTEL config
 		if tracingEnabled {
 			baseRuntimeEnvVars["OPENTELEMETRY_COLLECTOR_CONFIG_URI"] = pulumi.String("/var/task/collector.yaml")
@@ -326,6 +383,32 @@ func createProgram(args *NewProgramArgs) pulumi.RunFunc {
 			return fmt.Errorf("error creating api lambda: %v", err)
 		}
 
+		if webSocketApi != nil {
+			err = createWebSocketResources(ctx, webSocketApi, &lambda.FunctionArgs{
+				Runtime:    lambda.RuntimeCustomAL2023,
+				MemorySize: pulumi.IntPtr(2048),
+				Handler:    pulumi.String("main"),
+				LoggingConfig: lambda.FunctionLoggingConfigArgs{
+					LogFormat: pulumi.String("JSON"),
+				},
+
+				Code:   pulumi.NewFileArchive(args.RuntimeLambdaPath),
+				Role:   runtimeRole.Arn,
+				Layers: otelLayer,
+				Tags:   baseTags,
+
+				Environment: lambda.FunctionEnvironmentArgs{
+					Variables: extendStringMap(baseRuntimeEnvVars, pulumi.StringMap{
+						"KEEL_RUNTIME_MODE": pulumi.String(runtime.RuntimeModeWebSocket),
+						"OTEL_SERVICE_NAME": pulumi.String("websocket"),
+					}),
+				},
+			}, baseTags)
+			if err != nil {
+				return err
+			}
+		}
+
 		// We avoid creating resources we don't need by only creating the subscribers Lambda
 		// if there are event subscriptions defined in the schema
 		var subscriber *lambda.Function
@@ -502,6 +585,74 @@ func createProgram(args *NewProgramArgs) pulumi.RunFunc {
 	}
 }
 
+// hasSubscriptions returns true if any API has a model with events, which are served as GraphQL subscriptions.
+func hasSubscriptions(schema *proto.Schema) bool {
+	for _, api := range schema.GetApis() {
+		for _, apiModel := range api.GetApiModels() {
+			for _, event := range schema.GetEvents() {
+				if event.GetModelName() == apiModel.GetModelName() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// createWebSocketResources creates the Lambda which serves GraphQL subscriptions over the WebSocket API.
+func createWebSocketResources(ctx *pulumi.Context, webSocketApi *apigatewayv2.Api, functionArgs *lambda.FunctionArgs, baseTags pulumi.StringMap) error {
+	websocket, err := lambda.NewFunction(ctx, "websocket", functionArgs)
+	if err != nil {
+		return fmt.Errorf("error creating websocket lambda: %v", err)
+	}
+
+	_, err = lambda.NewPermission(ctx, "websocket-invoke", &lambda.PermissionArgs{
+		Action:    pulumi.String("lambda:InvokeFunction"),
+		Function:  websocket.Name,
+		Principal: pulumi.String("apigateway.amazonaws.com"),
+		SourceArn: pulumi.Sprintf("%s/*", webSocketApi.ExecutionArn),
+	})
+	if err != nil {
+		return err
+	}
+
+	integration, err := apigatewayv2.NewIntegration(ctx, "websocket-integration", &apigatewayv2.IntegrationArgs{
+		ApiId:           webSocketApi.ID(),
+		IntegrationType: pulumi.String("AWS_PROXY"),
+		IntegrationUri:  websocket.InvokeArn,
+	})
+	if err != nil {
+		return err
+	}
+
+	routes := []pulumi.Resource{}
+	for name, routeKey := range map[string]string{"connect": "$connect", "disconnect": "$disconnect", "default": "$default"} {
+		route, err := apigatewayv2.NewRoute(ctx, fmt.Sprintf("websocket-route-%s", name), &apigatewayv2.RouteArgs{
+			ApiId:    webSocketApi.ID(),
+			RouteKey: pulumi.String(routeKey),
+			Target:   pulumi.Sprintf("integrations/%s", integration.ID()),
+		})
+		if err != nil {
+			return err
+		}
+		routes = append(routes, route)
+	}
+
+	_, err = apigatewayv2.NewStage(ctx, "websocket-stage", &apigatewayv2.StageArgs{
+		ApiId:      webSocketApi.ID(),
+		Name:       pulumi.String(webSocketStageName),
+		AutoDeploy: pulumi.BoolPtr(true),
+		Tags:       baseTags,
+	}, pulumi.DependsOn(routes))
+	if err != nil {
+		return err
+	}
+
+	ctx.Export(StackOutputWebSocketURL, pulumi.Sprintf("%s/%s", webSocketApi.ApiEndpoint, webSocketStageName))
+
+	return nil
+}
+
 type CreateRDSResourcesArgs struct {
 	Config   *config.ProjectConfig
 	Env      string
@@ -809,7 +960,8 @@ func createEventBridgeSchedules(ctx *pulumi.Context, jobsLambda *lambda.Function
 		}
 
 		_, err = scheduler.NewSchedule(ctx, fmt.Sprintf("scheduled-job-%s", job.GetName()), &scheduler.ScheduleArgs{
-			ScheduleExpression: pulumi.String(expression),
+			ScheduleExpression:         pulumi.String(expression),
+			ScheduleExpressionTimezone: pulumi.StringPtrFromPtr(scheduleTimezone(job.GetSchedule())),
 			FlexibleTimeWindow: scheduler.ScheduleFlexibleTimeWindowArgs{
 				Mode: pulumi.String("OFF"),
 			},
@@ -884,7 +1036,8 @@ func createEventBridgeSchedulesForFlows(ctx *pulumi.Context, flowsQueue *sqs.Que
 		}
 
 		_, err = scheduler.NewSchedule(ctx, fmt.Sprintf("scheduled-flow-%s", flow.GetName()), &scheduler.ScheduleArgs{
-			ScheduleExpression: pulumi.String(expression),
+			ScheduleExpression:         pulumi.String(expression),
+			ScheduleExpressionTimezone: pulumi.StringPtrFromPtr(scheduleTimezone(flow.GetSchedule())),
 			FlexibleTimeWindow: scheduler.ScheduleFlexibleTimeWindowArgs{
 				Mode: pulumi.String("OFF"),
 			},
@@ -905,6 +1058,15 @@ func createEventBridgeSchedulesForFlows(ctx *pulumi.Context, flowsQueue *sqs.Que
 	return nil
 }
 
+// scheduleTimezone is the time zone in which EventBridge evaluates the schedule, which is UTC if not set.
+func scheduleTimezone(schedule *proto.Schedule) *string {
+	if schedule.GetTimezone() == "" {
+		return nil
+	}
+
+	return lo.ToPtr(schedule.GetTimezone())
+}
+
 // extendStringMap creates a _new_ StringMap by combining `a` and `b`.
 func extendStringMap(a, b pulumi.StringMap) pulumi.StringMap {
 	r := pulumi.StringMap{}
@@ -0,0 +1,19 @@
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/proto"
+)
+
+func TestScheduleTimezone(t *testing.T) {
+	t.Parallel()
+
+	// EventBridge evaluates schedules without a time zone in UTC
+	require.Nil(t, scheduleTimezone(&proto.Schedule{Expression: "0 9 ? * MON-FRI *"}))
+
+	timezone := scheduleTimezone(&proto.Schedule{Expression: "0 9 ? * MON-FRI *", Timezone: "America/New_York"})
+	require.NotNil(t, timezone)
+	require.Equal(t, "America/New_York", *timezone)
+}
@@ -24,6 +24,8 @@ type RunArgs struct {
 	ProjectRoot   string
 	Env           string
 	RuntimeBinary string
+	// Apply migrations which delete data, even if not allowed in the config
+	AllowDestructiveMigrations bool
 }
 
 func Run(ctx context.Context, args *RunArgs) error {
@@ -101,12 +103,14 @@ func Run(ctx context.Context, args *RunArgs) error {
 
 	if args.Action == UpAction {
 		err = runMigrations(ctx, &RunMigrationsArgs{
-			AwsConfig: cfg,
-			Stack:     stack,
-			Env:       args.Env,
-			Schema:    protoSchema,
-			Config:    projectConfig,
-			DryRun:    true,
+			AwsConfig:        cfg,
+			Stack:            stack,
+			Env:              args.Env,
+			ProjectRoot:      args.ProjectRoot,
+			Schema:           protoSchema,
+			Config:           projectConfig,
+			DryRun:           true,
+			AllowDestructive: args.AllowDestructiveMigrations || deploy.AllowDestructiveMigrations(),
 		})
 		if err != nil {
 			return err
@@ -164,12 +168,14 @@ func Run(ctx context.Context, args *RunArgs) error {
 	log(ctx, "%s App successfully deployed %s", IconTick, t.Since())
 
 	err = runMigrations(ctx, &RunMigrationsArgs{
-		AwsConfig: cfg,
-		Stack:     stack,
-		Env:       args.Env,
-		Schema:    protoSchema,
-		Config:    projectConfig,
-		DryRun:    false,
+		AwsConfig:        cfg,
+		Stack:            stack,
+		Env:              args.Env,
+		ProjectRoot:      args.ProjectRoot,
+		Schema:           protoSchema,
+		Config:           projectConfig,
+		DryRun:           false,
+		AllowDestructive: args.AllowDestructiveMigrations || deploy.AllowDestructiveMigrations(),
 	})
 	if err != nil {
 		return err
@@ -80,6 +80,7 @@ func selectStack(ctx context.Context, args *SelectStackArgs) (*auto.Stack, error
 
 const (
 	StackOutputApiURL               = "apiUrl"
+	StackOutputWebSocketURL         = "webSocketUrl"
 	StackOutputDatabaseEndpoint     = "databaseEndpoint"
 	StackOutputDatabaseDbName       = "databaseDbName"
 	StackOutputDatabaseSecretArn    = "databaseSecretArn"
@@ -91,6 +92,7 @@ const (
 
 type StackOutputs struct {
 	ApiURL               string
+	WebSocketURL         string
 	DatabaseEndpoint     string
 	DatabaseDbName       string
 	DatabaseSecretArn    string
@@ -137,6 +139,8 @@ func parseStackOutputs(outputs auto.OutputMap) *StackOutputs {
 		switch key {
 		case StackOutputApiURL:
 			result.ApiURL = v
+		case StackOutputWebSocketURL:
+			result.WebSocketURL = v
 		case StackOutputDatabaseDbName:
 			result.DatabaseDbName = v
 		case StackOutputDatabaseEndpoint:
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// brokerBufferSize is the number of events which can be queued for a single listener
+// before further events are dropped for that listener.
+const brokerBufferSize = 64
+
+// Broker fans out the events generated by SendEvents to any in-process listeners,
+// such as GraphQL subscriptions. It is only useful where the runtime is a long-lived
+// process, i.e. when using `keel run`.
+type Broker struct {
+	mu        sync.RWMutex
+	listeners map[int]chan *Event
+	nextId    int
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		listeners: map[int]chan *Event{},
+	}
+}
+
+// Subscribe registers a new listener and returns a channel on which events will be
+// received, as well as a function which must be called to unsubscribe.
+func (b *Broker) Subscribe() (<-chan *Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextId
+	b.nextId++
+
+	ch := make(chan *Event, brokerBufferSize)
+	b.listeners[id] = ch
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.listeners, id)
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish sends the event to all current listeners. Publishing never blocks; if a
+// listener is not keeping up then the event is dropped for that listener.
+func (b *Broker) Publish(event *Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.listeners {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+var brokerContextKey handlerContextKey = "eventBroker"
+
+func WithBroker(ctx context.Context, broker *Broker) context.Context {
+	return context.WithValue(ctx, brokerContextKey, broker)
+}
+
+func HasBroker(ctx context.Context) bool {
+	return ctx.Value(brokerContextKey) != nil
+}
+
+func GetBroker(ctx context.Context) (*Broker, error) {
+	v, ok := ctx.Value(brokerContextKey).(*Broker)
+	if !ok {
+		return nil, fmt.Errorf("context does not have key or is not Broker: %s", brokerContextKey)
+	}
+	return v, nil
+}
+
+// EventPublisher delivers an event to subscription listeners which are not held in this process,
+// such as WebSocket connections managed by API Gateway in deployed environments.
+type EventPublisher func(ctx context.Context, event *Event) error
+
+var publisherContextKey handlerContextKey = "eventPublisher"
+
+func WithEventPublisher(ctx context.Context, publisher EventPublisher) context.Context {
+	return context.WithValue(ctx, publisherContextKey, publisher)
+}
+
+func HasEventPublisher(ctx context.Context) bool {
+	return ctx.Value(publisherContextKey) != nil
+}
+
+func GetEventPublisher(ctx context.Context) (EventPublisher, error) {
+	v, ok := ctx.Value(publisherContextKey).(EventPublisher)
+	if !ok {
+		return nil, fmt.Errorf("context does not have key or is not EventPublisher: %s", publisherContextKey)
+	}
+	return v, nil
+}
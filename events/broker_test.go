@@ -0,0 +1,49 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerPublishesToAllListeners(t *testing.T) {
+	broker := NewBroker()
+
+	first, unsubscribeFirst := broker.Subscribe()
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := broker.Subscribe()
+	defer unsubscribeSecond()
+
+	event := &Event{EventName: "member.created"}
+	broker.Publish(event)
+
+	require.Equal(t, event, <-first)
+	require.Equal(t, event, <-second)
+}
+
+func TestBrokerUnsubscribeClosesChannel(t *testing.T) {
+	broker := NewBroker()
+
+	listener, unsubscribe := broker.Subscribe()
+	unsubscribe()
+	unsubscribe()
+
+	_, ok := <-listener
+	require.False(t, ok)
+
+	// Publishing with no listeners must not block or panic.
+	broker.Publish(&Event{EventName: "member.created"})
+}
+
+func TestBrokerDropsEventsForSlowListeners(t *testing.T) {
+	broker := NewBroker()
+
+	listener, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < brokerBufferSize+10; i++ {
+		broker.Publish(&Event{EventName: "member.created"})
+	}
+
+	require.Len(t, listener, brokerBufferSize)
+}
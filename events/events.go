@@ -77,12 +77,32 @@ func GetEventHandler(ctx context.Context) (EventHandler, error) {
 	return v, nil
 }
 
+var deferredContextKey handlerContextKey = "eventsDeferred"
+
+// WithDeferredEvents prevents SendEvents from sending any events for this context. This is used
+// when running in a database transaction, as events must only be sent once the transaction has
+// been committed, by calling SendEvents with the parent context.
+func WithDeferredEvents(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deferredContextKey, true)
+}
+
+func isDeferred(ctx context.Context) bool {
+	deferred, _ := ctx.Value(deferredContextKey).(bool)
+	return deferred
+}
+
 // SendEvents will gather, create and send events which have occurred within the scope of this context.
 // It achieves this by inspecting the keel_audit table for rows which must be generated into events,
 // updates the event_processed_at field on these rows, and then calls the event handler for each event.
+// Each event is also published to the broker and publisher, if they have been configured.
 func SendEvents(ctx context.Context, schema *proto.Schema) error {
-	// If no event handler has been configured, then no events can be sent.
-	if !HasEventHandler(ctx) {
+	// If no event handler, broker or publisher has been configured, then no events can be sent.
+	if !HasEventHandler(ctx) && !HasBroker(ctx) && !HasEventPublisher(ctx) {
+		return nil
+	}
+
+	// Events will be sent later with the parent context.
+	if isDeferred(ctx) {
 		return nil
 	}
 
@@ -101,9 +121,31 @@ func SendEvents(ctx context.Context, schema *proto.Schema) error {
 		return errors.New("valid spanContext expected")
 	}
 
-	handler, err := GetEventHandler(ctx)
-	if err != nil {
-		return err
+	var handler EventHandler
+	if HasEventHandler(ctx) {
+		h, err := GetEventHandler(ctx)
+		if err != nil {
+			return err
+		}
+		handler = h
+	}
+
+	var broker *Broker
+	if HasBroker(ctx) {
+		b, err := GetBroker(ctx)
+		if err != nil {
+			return err
+		}
+		broker = b
+	}
+
+	var publisher EventPublisher
+	if HasEventPublisher(ctx) {
+		p, err := GetEventPublisher(ctx)
+		if err != nil {
+			return err
+		}
+		publisher = p
 	}
 
 	traceparent := util.GetTraceparent(spanContext)
@@ -145,12 +187,26 @@ func SendEvents(ctx context.Context, schema *proto.Schema) error {
 
 	var handlerErrors error
 	for _, log := range auditLogs {
-		eventName, err := eventNameFromAudit(log.TableName, log.Op)
+		op := log.Op
+
+		// Updates to soft deleted rows are not permitted, so an update which leaves
+		// deleted_at set is the row being soft deleted.
+		model := schema.FindModel(strcase.ToCamel(log.TableName))
+		softDeleted := model != nil && model.GetSoftDelete() && op == auditing.Update && log.Data["deleted_at"] != nil
+		if softDeleted {
+			op = auditing.Delete
+		}
+
+		eventName, err := eventNameFromAudit(log.TableName, op)
 		if err != nil {
 			return err
 		}
 
 		protoEvent := proto.FindEvent(schema.GetEvents(), eventName)
+		if protoEvent == nil && softDeleted {
+			// The model has update events but no delete events
+			continue
+		}
 		if protoEvent == nil {
 			return fmt.Errorf("event '%s' does not exist", eventName)
 		}
@@ -171,8 +227,8 @@ func SendEvents(ctx context.Context, schema *proto.Schema) error {
 			}
 		}
 
-		for _, subscriber := range subscribers {
-			event := &Event{
+		newEvent := func() *Event {
+			return &Event{
 				EventName:  eventName,
 				OccurredAt: time.Now().UTC(),
 				IdentityId: identityId,
@@ -183,6 +239,27 @@ func SendEvents(ctx context.Context, schema *proto.Schema) error {
 					PreviousData: toLowerCamelMap(previous),
 				},
 			}
+		}
+
+		// Publish to in-process listeners, such as GraphQL subscriptions.
+		if broker != nil {
+			broker.Publish(newEvent())
+		}
+
+		// Publish to listeners outside of this process, such as WebSocket connections held by API Gateway.
+		if publisher != nil {
+			err = publisher(ctx, newEvent())
+			if err != nil {
+				handlerErrors = errors.Join(handlerErrors, err)
+			}
+		}
+
+		if handler == nil {
+			continue
+		}
+
+		for _, subscriber := range subscribers {
+			event := newEvent()
 
 			err = handler(ctx, subscriber.GetName(), event, traceparent)
 			if err != nil {
@@ -262,12 +262,14 @@ func WithConstant(identifier string, typeName string) expressions.Option {
 func WithCtx() expressions.Option {
 	return func(p *expressions.Parser) error {
 		p.Provider.Objects[typing.TypeNameContext] = map[string]*types.Type{
-			"identity":        types.NewObjectType(parser.IdentityModelName),
-			"isAuthenticated": types.BoolType,
-			"now":             typing.TypeTimestamp,
-			"secrets":         typing.TypeSecrets,
-			"env":             typing.TypeEnvvars,
-			"headers":         typing.TypeHeaders,
+			"identity":         types.NewObjectType(parser.IdentityModelName),
+			"isAuthenticated":  types.BoolType,
+			"isServiceAccount": types.BoolType,
+			"scopes":           typing.TypeTextArray,
+			"now":              typing.TypeTimestamp,
+			"secrets":          typing.TypeSecrets,
+			"env":              typing.TypeEnvvars,
+			"headers":          typing.TypeHeaders,
 		}
 
 		if p.Provider.Objects[typing.TypeNameSecrets] == nil {
@@ -17,6 +17,8 @@ type TypeProvider struct {
 	// For example, ctx would look like this:
 	//  _Context ->
 	//      isAuthenticated -> Bool
+	//      isServiceAccount -> Bool
+	//      scopes			-> Text[]
 	//      now				-> Timestamp
 	//		identity 		-> Identity
 	Objects map[string]map[string]*types.Type
@@ -144,6 +144,14 @@ func CallFunction(ctx context.Context, actionName string, body any, permissionSt
 		}
 	}
 
+	var serviceAccount *auth.ServiceAccount
+	if auth.IsServiceAccount(ctx) {
+		serviceAccount, err = auth.GetServiceAccount(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	secrets := runtimectx.GetSecrets(ctx)
 
 	tracingContext := propagation.MapCarrier{}
@@ -152,6 +160,7 @@ func CallFunction(ctx context.Context, actionName string, body any, permissionSt
 	meta := map[string]any{
 		"headers":         requestHeaders,
 		"identity":        identity,
+		"serviceAccount":  serviceAccount,
 		"secrets":         secrets,
 		"tracing":         tracingContext,
 		"permissionState": permissionState,
@@ -498,6 +507,11 @@ func toRuntimeError(errorResponse *FunctionsRuntimeError) error {
 	case PermissionError:
 		return common.NewPermissionError()
 	case ForeignKeyConstraintError:
+		// Deleting a record which is still referenced by a restrict or no action relationship
+		if detail, ok := data["detail"].(string); ok && strings.Contains(detail, "is still referenced from table") {
+			table, _ := data["table"].(string)
+			return common.NewRestrictedDeleteError(table)
+		}
 		return common.NewForeignKeyConstraintError(data["column"].(string))
 	case NoResultError:
 		return common.RuntimeError{
@@ -733,3 +733,15 @@ test("list function - database check, correct identity, with rows - rows returne
     actions.withIdentity(identity).listDbPermissionFn()
   ).not.toHaveAuthorizationError();
 });
+
+test("delete action - record still referenced by restrict relationship - ERR_CONFLICT", async () => {
+  const author = await models.author.create({ name: "Jane Austen" });
+  await models.novel.create({ title: "Emma", authorId: author.id });
+
+  await expect(actions.deleteAuthor({ id: author.id })).toHaveError({
+    code: "ERR_CONFLICT",
+    message: "the record cannot be deleted as it is still referenced by Novel",
+  });
+
+  await expect(await models.author.findMany()).toHaveLength(1);
+});
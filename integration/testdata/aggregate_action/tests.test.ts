@@ -0,0 +1,150 @@
+import { actions, models, resetDatabase } from "@teamkeel/testing";
+import { Status } from "@teamkeel/sdk";
+import { test, expect, beforeEach } from "vitest";
+
+beforeEach(resetDatabase);
+
+async function seed() {
+  const alice = await models.customer.create({ name: "Alice" });
+  const bob = await models.customer.create({ name: "Bob" });
+
+  await models.order.create({
+    status: Status.Pending,
+    total: 10.5,
+    quantity: 1,
+    placedOn: new Date("2024-01-01"),
+    customerId: alice.id,
+  });
+  await models.order.create({
+    status: Status.Shipped,
+    total: 20,
+    quantity: 2,
+    placedOn: new Date("2024-01-01"),
+    customerId: alice.id,
+  });
+  await models.order.create({
+    status: Status.Shipped,
+    total: 30,
+    quantity: 3,
+    placedOn: new Date("2024-01-15"),
+    customerId: bob.id,
+  });
+  await models.order.create({
+    status: Status.Shipped,
+    total: 100,
+    quantity: 10,
+    placedOn: new Date("2024-02-01"),
+    customerId: bob.id,
+    cancelled: true,
+  });
+}
+
+test("aggregate - groups records and applies functions", async () => {
+  await seed();
+
+  const { results } = await actions.orderTotals();
+
+  expect(results).toEqual([
+    {
+      status: Status.Pending,
+      count: 1,
+      sum: { total: 10.5, quantity: 1 },
+      avg: { total: 10.5 },
+      min: { placedOn: new Date("2024-01-01") },
+      max: { total: 10.5 },
+    },
+    {
+      status: Status.Shipped,
+      count: 2,
+      sum: { total: 50, quantity: 5 },
+      avg: { total: 25 },
+      min: { placedOn: new Date("2024-01-01") },
+      max: { total: 30 },
+    },
+  ]);
+});
+
+test("aggregate - filters by inputs", async () => {
+  await seed();
+
+  const { results } = await actions.orderTotals({
+    where: { status: { equals: Status.Shipped } },
+  });
+
+  expect(results).toHaveLength(1);
+  expect(results[0].status).toEqual(Status.Shipped);
+  expect(results[0].count).toEqual(2);
+});
+
+test("aggregate - filters by relationship inputs", async () => {
+  await seed();
+
+  const { results } = await actions.orderTotals({
+    where: { customer: { name: { equals: "Alice" } } },
+  });
+
+  expect(results.map((r) => [r.status, r.count, r.sum.total])).toEqual([
+    [Status.Pending, 1, 10.5],
+    [Status.Shipped, 1, 20],
+  ]);
+});
+
+test("aggregate - groups dates by interval", async () => {
+  await seed();
+
+  const byDay = await actions.ordersByDay();
+  expect(
+    byDay.results.map((r) => [r.placedOn, r.count, r.sum.quantity])
+  ).toEqual([
+    [new Date("2024-01-01"), 2, 3],
+    [new Date("2024-01-15"), 1, 3],
+    [new Date("2024-02-01"), 1, 10],
+  ]);
+
+  const byMonth = await actions.ordersByDay({ interval: "month" });
+  expect(
+    byMonth.results.map((r) => [r.placedOn, r.count, r.sum.quantity])
+  ).toEqual([
+    [new Date("2024-01-01"), 3, 6],
+    [new Date("2024-02-01"), 1, 10],
+  ]);
+});
+
+test("aggregate - invalid interval", async () => {
+  await expect(actions.ordersByDay({ interval: "fortnight" })).toHaveError({
+    code: "ERR_INPUT_MALFORMED",
+    message: "interval must be one of day, week, month, quarter, year",
+  });
+});
+
+test("aggregate - without @groupBy returns a single result", async () => {
+  await seed();
+
+  const { results } = await actions.orderCount();
+  expect(results).toEqual([{ count: 4, sum: { total: 160.5 } }]);
+});
+
+test("aggregate - with no records", async () => {
+  const { results } = await actions.orderCount();
+  expect(results).toEqual([{ count: 0, sum: { total: null } }]);
+});
+
+test("aggregate - not authorised if any record is not permitted", async () => {
+  await seed();
+
+  await expect(actions.shippedOrderCount()).toHaveAuthorizationError();
+});
+
+test("aggregate - authorised if every record is permitted", async () => {
+  const customer = await models.customer.create({ name: "Alice" });
+  await models.order.create({
+    status: Status.Shipped,
+    total: 20,
+    quantity: 2,
+    placedOn: new Date("2024-01-01"),
+    customerId: customer.id,
+  });
+
+  const { results } = await actions.shippedOrderCount();
+  expect(results).toEqual([{ count: 1 }]);
+});
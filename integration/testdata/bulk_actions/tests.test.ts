@@ -0,0 +1,233 @@
+import { actions, models, resetDatabase } from "@teamkeel/testing";
+import { useDatabase } from "@teamkeel/sdk";
+import { test, expect, beforeEach } from "vitest";
+import { sql } from "kysely";
+
+beforeEach(resetDatabase);
+
+test("createMany - creates a record for each item in order", async () => {
+  const author = await actions.createAuthor({ name: "Keelson" });
+
+  const posts = await actions.createPosts({
+    items: [
+      { title: "First", author: { id: author.id } },
+      { title: "Second", author: { id: author.id } },
+      { title: "Third", author: { id: author.id } },
+    ],
+  });
+
+  expect(posts.map((p) => p.title)).toEqual(["First", "Second", "Third"]);
+  expect(posts.every((p) => p.authorId === author.id)).toBeTruthy();
+  expect(posts.every((p) => p.published === false)).toBeTruthy();
+
+  const rows = await models.post.findMany();
+  expect(rows).toHaveLength(3);
+});
+
+test("createMany - no items", async () => {
+  const posts = await actions.createPosts({ items: [] });
+  expect(posts).toEqual([]);
+});
+
+test("createMany - no records are created if any item fails", async () => {
+  const author = await actions.createAuthor({ name: "Keelson" });
+
+  await expect(
+    actions.createPosts({
+      items: [
+        { title: "First", author: { id: author.id } },
+        { title: "Second", author: { id: "does-not-exist" } },
+      ],
+    })
+  ).rejects.toThrow();
+
+  const rows = await models.post.findMany();
+  expect(rows).toHaveLength(0);
+});
+
+test("updateMany - updates the record of each item", async () => {
+  const author = await actions.createAuthor({ name: "Keelson" });
+  const [first, second] = await actions.createPosts({
+    items: [
+      { title: "First", author: { id: author.id } },
+      { title: "Second", author: { id: author.id } },
+    ],
+  });
+
+  const posts = await actions.updatePosts({
+    items: [
+      { where: { id: second.id }, values: { published: true } },
+      { where: { id: first.id }, values: { title: "Updated" } },
+    ],
+  });
+
+  expect(posts.map((p) => p.id)).toEqual([second.id, first.id]);
+  expect(posts[0].title).toEqual("Second");
+  expect(posts[0].published).toEqual(true);
+  expect(posts[1].title).toEqual("Updated");
+  expect(posts[1].published).toEqual(false);
+});
+
+test("updateMany - no records are updated if any item is not found", async () => {
+  const author = await actions.createAuthor({ name: "Keelson" });
+  const [first] = await actions.createPosts({
+    items: [{ title: "First", author: { id: author.id } }],
+  });
+
+  await expect(
+    actions.updatePosts({
+      items: [
+        { where: { id: first.id }, values: { title: "Updated" } },
+        { where: { id: "123" }, values: { title: "Updated" } },
+      ],
+    })
+  ).toHaveError({
+    code: "ERR_RECORD_NOT_FOUND",
+    message: "items[1]: record not found",
+  });
+
+  const post = await models.post.findOne({ id: first.id });
+  expect(post!.title).toEqual("First");
+});
+
+test("updateMany - items which update the same record are rejected", async () => {
+  const author = await actions.createAuthor({ name: "Keelson" });
+  const [first] = await actions.createPosts({
+    items: [{ title: "First", author: { id: author.id } }],
+  });
+
+  await expect(
+    actions.updatePosts({
+      items: [
+        { where: { id: first.id }, values: { title: "Updated" } },
+        { where: { id: first.id }, values: { published: true } },
+      ],
+    })
+  ).toHaveError({
+    code: "ERR_INPUT_MALFORMED",
+    message: "items[1] writes the same record as items[0]",
+  });
+});
+
+test("updateMany - permission is checked against each record", async () => {
+  const author = await actions.createAuthor({ name: "Keelson" });
+  const [unlocked, locked] = await actions.createPosts({
+    items: [
+      { title: "Unlocked", author: { id: author.id } },
+      { title: "Locked", author: { id: author.id }, locked: true },
+    ],
+  });
+
+  await expect(
+    actions.updateUnlockedPosts({
+      items: [
+        { where: { id: unlocked.id }, values: { title: "Updated" } },
+        { where: { id: locked.id }, values: { title: "Updated" } },
+      ],
+    })
+  ).toHaveError({
+    code: "ERR_PERMISSION_DENIED",
+    message: "items[1]: not authorized to access",
+  });
+
+  const post = await models.post.findOne({ id: unlocked.id });
+  expect(post!.title).toEqual("Unlocked");
+
+  const posts = await actions.updateUnlockedPosts({
+    items: [{ where: { id: unlocked.id }, values: { title: "Updated" } }],
+  });
+  expect(posts[0].title).toEqual("Updated");
+});
+
+test("deleteMany - deletes the record of each item", async () => {
+  const author = await actions.createAuthor({ name: "Keelson" });
+  const [first, second, third] = await actions.createPosts({
+    items: [
+      { title: "First", author: { id: author.id } },
+      { title: "Second", author: { id: author.id } },
+      { title: "Third", author: { id: author.id } },
+    ],
+  });
+
+  const ids = await actions.deletePosts({
+    items: [{ id: third.id }, { id: first.id }],
+  });
+  expect(ids).toEqual([third.id, first.id]);
+
+  const rows = await models.post.findMany();
+  expect(rows.map((r) => r.id)).toEqual([second.id]);
+});
+
+test("deleteMany - no records are deleted if any item is not found", async () => {
+  const author = await actions.createAuthor({ name: "Keelson" });
+  const [first] = await actions.createPosts({
+    items: [{ title: "First", author: { id: author.id } }],
+  });
+
+  await expect(
+    actions.deletePosts({
+      items: [{ id: first.id }, { id: "123" }],
+    })
+  ).toHaveError({
+    code: "ERR_RECORD_NOT_FOUND",
+    message: "items[1]: record not found",
+  });
+
+  const rows = await models.post.findMany();
+  expect(rows).toHaveLength(1);
+});
+
+test("deleteMany - permission is checked against each record", async () => {
+  const author = await actions.createAuthor({ name: "Keelson" });
+  const [unlocked, locked] = await actions.createPosts({
+    items: [
+      { title: "Unlocked", author: { id: author.id } },
+      { title: "Locked", author: { id: author.id }, locked: true },
+    ],
+  });
+
+  await expect(
+    actions.deleteUnlockedPosts({
+      items: [{ id: unlocked.id }, { id: locked.id }],
+    })
+  ).toHaveAuthorizationError();
+
+  const rows = await models.post.findMany();
+  expect(rows).toHaveLength(2);
+});
+
+test("bulk actions - audit log records an event for each record", async () => {
+  const author = await actions.createAuthor({ name: "Keelson" });
+  const [first, second] = await actions.createPosts({
+    items: [
+      { title: "First", author: { id: author.id } },
+      { title: "Second", author: { id: author.id } },
+    ],
+  });
+
+  await actions.updatePosts({
+    items: [
+      { where: { id: first.id }, values: { published: true } },
+      { where: { id: second.id }, values: { published: true } },
+    ],
+  });
+
+  await actions.deletePosts({
+    items: [{ id: first.id }, { id: second.id }],
+  });
+
+  const logs = await sql<{
+    op: string;
+  }>`SELECT op FROM keel_audit WHERE table_name = 'post' ORDER BY created_at`.execute(
+    useDatabase()
+  );
+
+  expect(logs.rows.map((r) => r.op)).toEqual([
+    "insert",
+    "insert",
+    "update",
+    "update",
+    "delete",
+    "delete",
+  ]);
+});
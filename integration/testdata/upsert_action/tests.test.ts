@@ -0,0 +1,114 @@
+import { actions, models, resetDatabase } from "@teamkeel/testing";
+import { useDatabase } from "@teamkeel/sdk";
+import { test, expect, beforeEach } from "vitest";
+import { sql } from "kysely";
+
+beforeEach(resetDatabase);
+
+test("upsert - creates the record when it does not exist", async () => {
+  const product = await actions.upsertProduct({
+    where: { sku: "ABC-123" },
+    values: { name: "Widget", price: 9.99 },
+  });
+
+  expect(product.sku).toEqual("ABC-123");
+  expect(product.name).toEqual("Widget");
+  expect(product.price).toEqual(9.99);
+
+  const products = await models.product.findMany();
+  expect(products).toHaveLength(1);
+});
+
+test("upsert - updates the existing record", async () => {
+  const created = await actions.upsertProduct({
+    where: { sku: "ABC-123" },
+    values: { name: "Widget", price: 9.99 },
+  });
+
+  const updated = await actions.upsertProduct({
+    where: { sku: "ABC-123" },
+    values: { name: "Better Widget", price: 12.5 },
+  });
+
+  expect(updated.id).toEqual(created.id);
+  expect(updated.name).toEqual("Better Widget");
+  expect(updated.price).toEqual(12.5);
+  expect(updated.createdAt).toEqual(created.createdAt);
+
+  const products = await models.product.findMany();
+  expect(products).toHaveLength(1);
+});
+
+test("upsert - composite unique relationships", async () => {
+  const warehouse = await actions.createWarehouse({ name: "North" });
+  const product = await actions.createProduct({
+    sku: "ABC-123",
+    name: "Widget",
+    price: 9.99,
+    locked: false,
+  });
+
+  const created = await actions.upsertStock({
+    where: { warehouseId: warehouse.id, productId: product.id },
+    values: { quantity: 10 },
+  });
+  expect(created.quantity).toEqual(10);
+
+  const updated = await actions.upsertStock({
+    where: { warehouseId: warehouse.id, productId: product.id },
+    values: { quantity: 25 },
+  });
+  expect(updated.id).toEqual(created.id);
+  expect(updated.quantity).toEqual(25);
+
+  const stock = await models.stock.findMany();
+  expect(stock).toHaveLength(1);
+});
+
+test("upsert - permission is checked against the existing record", async () => {
+  await actions.createProduct({
+    sku: "ABC-123",
+    name: "Widget",
+    price: 9.99,
+    locked: true,
+  });
+
+  await expect(
+    actions.upsertProductUnlocked({
+      where: { sku: "ABC-123" },
+      values: { name: "Better Widget", price: 12.5 },
+    })
+  ).toHaveAuthorizationError();
+
+  const product = await models.product.findOne({ sku: "ABC-123" });
+  expect(product!.name).toEqual("Widget");
+});
+
+test("upsert - permission is checked against the created record", async () => {
+  const product = await actions.upsertProductUnlocked({
+    where: { sku: "ABC-123" },
+    values: { name: "Widget", price: 9.99 },
+  });
+
+  expect(product.locked).toEqual(false);
+});
+
+test("upsert - audit log records a create and then an update", async () => {
+  await actions.upsertProduct({
+    where: { sku: "ABC-123" },
+    values: { name: "Widget", price: 9.99 },
+  });
+
+  await actions.upsertProduct({
+    where: { sku: "ABC-123" },
+    values: { name: "Better Widget", price: 12.5 },
+  });
+
+  const logs = await sql<{
+    op: string;
+  }>`SELECT op FROM keel_audit WHERE table_name = 'product' ORDER BY created_at`.execute(
+    useDatabase()
+  );
+
+  expect(logs.rows.map((r) => r.op)).toEqual(["insert", "update"]);
+});
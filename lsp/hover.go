@@ -0,0 +1,70 @@
+package lsp
+
+import (
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+// attributeDocs describes each attribute, shown when hovering over its name.
+var attributeDocs = map[string]string{
+	parser.AttributeUnique:      "Ensures the field's value is unique across all records of the model. On a model, `@unique([a, b])` makes a combination of fields unique.",
+	parser.AttributePermission:  "Grants permission to run actions or jobs when the `expression` is true or the identity has one of the `roles`, e.g. `@permission(expression: ctx.isAuthenticated, actions: [get, list])`.",
+	parser.AttributeWhere:       "Filters the records an action can read or write to those matching the expression, e.g. `@where(post.published == true)`.",
+	parser.AttributeSet:         "Sets a field when an action writes a record, e.g. `@set(post.author = ctx.identity)`.",
+	parser.AttributePrimaryKey:  "Marks the field as the primary key of the model.",
+	parser.AttributeDefault:     "Gives the field a default value when a record is created without one, e.g. `@default(false)`. Without an argument the default for the field's type is used.",
+	parser.AttributeValidate:    "Validates the inputs to an action with an expression, e.g. `@validate(name != \"\")`.",
+	parser.AttributeRelation:    "Names the field on the related model which is the other side of this relationship, and what happens when the related record is deleted with `onDelete` (restrict, cascade, setNull or noAction), e.g. `@relation(author, onDelete: restrict)`.",
+	parser.AttributeOrderBy:     "Sets the order of the results of a list action, e.g. `@orderBy(createdAt: desc)`.",
+	parser.AttributeSortable:    "Lets the caller of a list action sort the results by the given fields, e.g. `@sortable(title, createdAt)`.",
+	parser.AttributeSchedule:    "Runs a job or flow on a schedule, in cron syntax or plain English, with an optional time zone, e.g. `@schedule(\"every weekday at 9am\", timezone: \"Europe/London\")`.",
+	parser.AttributeFunction:    "Marks the action as implemented by a function.",
+	parser.AttributeOn:          "Runs a subscriber when records of the model are created, updated or deleted, e.g. `@on([create], sendWelcomeEmail)`.",
+	parser.AttributeEmbed:       "Includes related models in the response of a get or list action, e.g. `@embed(author)`.",
+	parser.AttributeComputed:    "Computes the field's value from an expression whenever the record changes, e.g. `@computed(item.price * item.quantity)`.",
+	parser.AttributeFacet:       "Returns facets for the given fields from a list action, for building filters.",
+	parser.AttributeGroupBy:     "Groups the results of an aggregate action by the given fields. Date and Timestamp fields are grouped by an `interval` input of day, week, month, quarter or year, e.g. `@groupBy(status, createdAt)`.",
+	parser.AttributeAggregate:   "Applies the sum, avg, min and max functions to the given fields for each group of an aggregate action, e.g. `@aggregate(sum: [total], avg: [total])`.",
+	parser.AttributeSequence:    "Generates a sequential identifier with a prefix, e.g. `@sequence(\"INV\")`.",
+	parser.AttributeRenamedFrom: "Renames the field or model without losing data, e.g. `@renamedFrom(\"oldName\")`.",
+	parser.AttributeSoftDelete:  "Marks records of the model as deleted instead of removing them, so that they can be restored.",
+	parser.AttributeSearchable:  "Lets list actions search the given text fields with a `search` input, ranking results by relevance. Earlier fields are weighted higher and words are matched using English stemming, e.g. `@searchable(title, body)`.",
+	parser.AttributeIndex:       "Creates a database index on the fields or expression given. Indexes can be partial with `where`, unique with `unique` and have fields in `descending` order, e.g. `@index([status, createdAt], where: post.published == true, descending: [createdAt])`.",
+	parser.AttributeVectorIndex: "Builds an hnsw or ivfflat index for nearest neighbour search on a Vector field with a fixed number of dimensions, e.g. `@vectorIndex(hnsw, dimensions: 1536, metric: cosine)`. Use hnsw unless you have a reason not to, as an ivfflat index is clustered from the rows in the table when it is created, so one created on an empty table gives poor results.",
+}
+
+// typeDocs describes each built-in type, shown when hovering over a field type.
+var typeDocs = map[string]string{
+	parser.FieldTypeID:         "A unique identifier, generated as a KSUID.",
+	parser.FieldTypeText:       "A string of text.",
+	parser.FieldTypeNumber:     "An integer.",
+	parser.FieldTypeDecimal:    "A decimal number.",
+	parser.FieldTypeDate:       "A calendar date, without a time.",
+	parser.FieldTypeTimestamp:  "A point in time, stored in UTC.",
+	parser.FieldTypeBoolean:    "Either true or false.",
+	parser.FieldTypeSecret:     "A string which is encrypted at rest and never returned by the API.",
+	parser.FieldTypePassword:   "A string which is hashed before being stored and never returned by the API.",
+	parser.FieldTypeMarkdown:   "Rich text in markdown.",
+	parser.FieldTypeVector:     "A vector of numbers, e.g. an embedding.",
+	parser.FieldTypeFile:       "A file, uploaded as a data URL and kept in storage.",
+	parser.FieldTypeDuration:   "A length of time, in ISO 8601 duration format.",
+	parser.MessageFieldTypeAny: "Any JSON value. Only allowed in messages.",
+}
+
+// hoverDocs returns the documentation for the attribute or built-in type named by word, which includes
+// the leading "@" of an attribute.
+func hoverDocs(word string) (string, bool) {
+	if len(word) > 1 && word[0] == '@' {
+		doc, ok := attributeDocs[word[1:]]
+		if !ok {
+			return "", false
+		}
+		return "**" + word + "**\n\n" + doc, true
+	}
+
+	doc, ok := typeDocs[word]
+	if !ok {
+		return "", false
+	}
+
+	return "**" + word + "**\n\n" + doc, true
+}
@@ -0,0 +1,211 @@
+package lsp
+
+import "encoding/json"
+
+// The subset of the Language Server Protocol which is supported. See
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/
+//
+// Line and character offsets are zero-based, whereas the schema parser's positions are one-based.
+
+const (
+	MethodInitialize              = "initialize"
+	MethodInitialized             = "initialized"
+	MethodShutdown                = "shutdown"
+	MethodExit                    = "exit"
+	MethodDidOpen                 = "textDocument/didOpen"
+	MethodDidChange               = "textDocument/didChange"
+	MethodDidClose                = "textDocument/didClose"
+	MethodDidSave                 = "textDocument/didSave"
+	MethodCompletion              = "textDocument/completion"
+	MethodFormatting              = "textDocument/formatting"
+	MethodHover                   = "textDocument/hover"
+	MethodDefinition              = "textDocument/definition"
+	MethodReferences              = "textDocument/references"
+	MethodRename                  = "textDocument/rename"
+	MethodPublishDiagnostics      = "textDocument/publishDiagnostics"
+	MethodDidChangeWatchedFiles   = "workspace/didChangeWatchedFiles"
+	MethodCancelRequest           = "$/cancelRequest"
+	MethodSetTrace                = "$/setTrace"
+	jsonRpcVersion                = "2.0"
+	textDocumentSyncKindFull      = 1
+	diagnosticSeverityError       = 1
+	diagnosticSeverityWarning     = 2
+	markupKindMarkdown            = "markdown"
+	errorCodeMethodNotFound       = -32601
+	errorCodeInvalidParams        = -32602
+	errorCodeInternalError        = -32603
+	errorCodeServerNotInitialized = -32002
+)
+
+const (
+	completionItemKindKeyword  = 14
+	completionItemKindField    = 5
+	completionItemKindVariable = 6
+	completionItemKindClass    = 7
+	completionItemKindProperty = 10
+	completionItemKindText     = 1
+	completionItemKindFunction = 3
+)
+
+type message struct {
+	JsonRpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  any              `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+type InitializeParams struct {
+	RootUri          string            `json:"rootUri"`
+	RootPath         string            `json:"rootPath"`
+	WorkspaceFolders []WorkspaceFolder `json:"workspaceFolders"`
+}
+
+type WorkspaceFolder struct {
+	Uri  string `json:"uri"`
+	Name string `json:"name"`
+}
+
+type InitializeResult struct {
+	Capabilities ServerCapabilities `json:"capabilities"`
+	ServerInfo   ServerInfo         `json:"serverInfo"`
+}
+
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type ServerCapabilities struct {
+	TextDocumentSync           TextDocumentSyncOptions `json:"textDocumentSync"`
+	CompletionProvider         *CompletionOptions      `json:"completionProvider,omitempty"`
+	DocumentFormattingProvider bool                    `json:"documentFormattingProvider"`
+	HoverProvider              bool                    `json:"hoverProvider"`
+	DefinitionProvider         bool                    `json:"definitionProvider"`
+	ReferencesProvider         bool                    `json:"referencesProvider"`
+	RenameProvider             bool                    `json:"renameProvider"`
+}
+
+type TextDocumentSyncOptions struct {
+	OpenClose bool `json:"openClose"`
+	Change    int  `json:"change"`
+}
+
+type CompletionOptions struct {
+	TriggerCharacters []string `json:"triggerCharacters,omitempty"`
+}
+
+type TextDocumentIdentifier struct {
+	Uri string `json:"uri"`
+}
+
+type TextDocumentItem struct {
+	Uri        string `json:"uri"`
+	LanguageId string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+type DidOpenTextDocumentParams struct {
+	TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+type DidChangeTextDocumentParams struct {
+	TextDocument   TextDocumentIdentifier           `json:"textDocument"`
+	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+type TextDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type Location struct {
+	Uri   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type ReferenceParams struct {
+	TextDocumentPositionParams
+	Context ReferenceContext `json:"context"`
+}
+
+type ReferenceContext struct {
+	IncludeDeclaration bool `json:"includeDeclaration"`
+}
+
+type RenameParams struct {
+	TextDocumentPositionParams
+	NewName string `json:"newName"`
+}
+
+type DocumentFormattingParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type PublishDiagnosticsParams struct {
+	Uri         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+type CompletionItem struct {
+	Label      string `json:"label"`
+	Kind       int    `json:"kind,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	InsertText string `json:"insertText,omitempty"`
+}
+
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+type WorkspaceEdit struct {
+	Changes map[string][]TextEdit `json:"changes"`
+}
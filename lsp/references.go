@@ -0,0 +1,106 @@
+package lsp
+
+import (
+	"regexp"
+	"strings"
+	"text/scanner"
+	"unicode/utf8"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/reader"
+)
+
+var typeNameRegex = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+
+// token is an identifier in a schema file.
+type token struct {
+	value    string
+	filename string
+	line     int
+	column   int
+}
+
+func (t token) contains(filename string, line int, column int) bool {
+	return t.filename == filename && t.line == line && t.column <= column && column <= t.column+utf8.RuneCountInString(t.value)
+}
+
+// symbols indexes the declarations of models, enums and messages across the schema files,
+// and every reference to them.
+type symbols struct {
+	declarations map[string]token
+	kinds        map[string]string
+	references   map[string][]token
+}
+
+func newSymbols(files []*reader.SchemaFile, asts []*parser.AST) *symbols {
+	s := &symbols{
+		declarations: map[string]token{},
+		kinds:        map[string]string{},
+		references:   map[string][]token{},
+	}
+
+	// Enum values can share a name with a model, enum or message so
+	// their declarations are excluded from the references
+	enumValues := map[lexer.Position]bool{}
+
+	declare := func(kind string, name parser.NameNode) {
+		s.declarations[name.Value] = token{value: name.Value, filename: name.Pos.Filename, line: name.Pos.Line, column: name.Pos.Column}
+		s.kinds[name.Value] = kind
+	}
+
+	for _, model := range query.Models(asts) {
+		declare(parser.KeywordModel, model.Name)
+	}
+	for _, enum := range query.Enums(asts) {
+		declare(parser.KeywordEnum, enum.Name)
+		for _, v := range enum.Values {
+			enumValues[lexer.Position{Filename: v.Name.Pos.Filename, Line: v.Name.Pos.Line, Column: v.Name.Pos.Column}] = true
+		}
+	}
+	for _, message := range query.Messages(asts) {
+		declare(parser.KeywordMessage, message.Name)
+	}
+
+	for _, f := range files {
+		var sc scanner.Scanner
+		sc.Init(strings.NewReader(f.Contents))
+		sc.Error = func(*scanner.Scanner, string) {}
+
+		previous := ""
+		for tok := sc.Scan(); tok != scanner.EOF; tok = sc.Scan() {
+			value := sc.TokenText()
+			pos := sc.Position
+
+			// A name following a dot is a field or enum value, e.g. Status.Draft
+			isReference := tok == scanner.Ident && previous != "." && !enumValues[lexer.Position{Filename: f.FileName, Line: pos.Line, Column: pos.Column}]
+			previous = value
+
+			if !isReference {
+				continue
+			}
+
+			if _, ok := s.declarations[value]; !ok {
+				continue
+			}
+
+			s.references[value] = append(s.references[value], token{value: value, filename: f.FileName, line: pos.Line, column: pos.Column})
+		}
+	}
+
+	return s
+}
+
+// at returns the name of the model, enum or message declared or referenced at the position.
+func (s *symbols) at(filename string, line int, column int) (string, bool) {
+	for name, refs := range s.references {
+		for _, ref := range refs {
+			if ref.contains(filename, line, column) {
+				return name, true
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,472 @@
+// Package lsp implements a Language Server Protocol server for Keel schema files, which is
+// run over stdio by the `keel lsp` command.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/teamkeel/keel/schema/completions"
+	"github.com/teamkeel/keel/schema/definitions"
+	"github.com/teamkeel/keel/schema/format"
+	"github.com/teamkeel/keel/schema/node"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/reader"
+)
+
+// ErrExitWithoutShutdown is returned by Serve if the client sends the exit notification
+// without first requesting a shutdown.
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown request")
+
+type Server struct {
+	// The project directory, used if the client does not send a root URI when initializing.
+	dir     string
+	version string
+
+	workspace   *workspace
+	initialized bool
+	shutdown    bool
+
+	writer io.Writer
+}
+
+func NewServer(dir string, version string) *Server {
+	return &Server{
+		dir:     dir,
+		version: version,
+	}
+}
+
+// Serve reads requests from r and writes responses and notifications to w until the client
+// sends the exit notification or r is closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.writer = w
+	reader := bufio.NewReader(r)
+
+	for {
+		msg, err := readMessage(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == MethodExit {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+
+		result, rerr := s.handle(msg)
+
+		// Notifications have no id and must not be responded to
+		if msg.Id == nil {
+			continue
+		}
+
+		response := &message{JsonRpc: jsonRpcVersion, Id: msg.Id}
+		if rerr != nil {
+			response.Error = rerr
+		} else {
+			response.Result = result
+			if result == nil {
+				response.Result = json.RawMessage("null")
+			}
+		}
+
+		if err := s.write(response); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg *message) (any, *responseError) {
+	if !s.initialized && msg.Method != MethodInitialize {
+		return nil, &responseError{Code: errorCodeServerNotInitialized, Message: "server not initialized"}
+	}
+
+	switch msg.Method {
+	case MethodInitialize:
+		var params InitializeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.initialize(&params), nil
+	case MethodInitialized, MethodCancelRequest, MethodSetTrace, MethodDidSave:
+		return nil, nil
+	case MethodShutdown:
+		s.shutdown = true
+		return nil, nil
+	case MethodDidOpen:
+		var params DidOpenTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.workspace.open[uriToFilename(params.TextDocument.Uri)] = params.TextDocument.Text
+		return nil, s.publishDiagnostics()
+	case MethodDidChange:
+		var params DidChangeTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		// Documents are synced in full, so the last change is the whole document
+		if len(params.ContentChanges) > 0 {
+			s.workspace.open[uriToFilename(params.TextDocument.Uri)] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		}
+		return nil, s.publishDiagnostics()
+	case MethodDidClose:
+		var params DidCloseTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.workspace.open, uriToFilename(params.TextDocument.Uri))
+		return nil, s.publishDiagnostics()
+	case MethodDidChangeWatchedFiles:
+		s.workspace.load()
+		return nil, s.publishDiagnostics()
+	case MethodCompletion:
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.completion(&params), nil
+	case MethodFormatting:
+		var params DocumentFormattingParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.formatting(&params), nil
+	case MethodHover:
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.hover(&params), nil
+	case MethodDefinition:
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.definition(&params), nil
+	case MethodReferences:
+		var params ReferenceParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.references(&params), nil
+	case MethodRename:
+		var params RenameParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.rename(&params)
+	default:
+		return nil, &responseError{Code: errorCodeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", msg.Method)}
+	}
+}
+
+func (s *Server) initialize(params *InitializeParams) *InitializeResult {
+	dir := s.dir
+	switch {
+	case len(params.WorkspaceFolders) > 0:
+		dir = uriToFilename(params.WorkspaceFolders[0].Uri)
+	case params.RootUri != "":
+		dir = uriToFilename(params.RootUri)
+	case params.RootPath != "":
+		dir = params.RootPath
+	}
+
+	s.workspace = newWorkspace(dir)
+	s.initialized = true
+
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync: TextDocumentSyncOptions{
+				OpenClose: true,
+				Change:    textDocumentSyncKindFull,
+			},
+			CompletionProvider: &CompletionOptions{
+				TriggerCharacters: []string{"@", ".", "(", "[", ","},
+			},
+			DocumentFormattingProvider: true,
+			HoverProvider:              true,
+			DefinitionProvider:         true,
+			ReferencesProvider:         true,
+			RenameProvider:             true,
+		},
+		ServerInfo: ServerInfo{
+			Name:    "keel",
+			Version: s.version,
+		},
+	}
+}
+
+func (s *Server) publishDiagnostics() *responseError {
+	for filename, diagnostics := range s.workspace.diagnostics() {
+		err := s.notify(MethodPublishDiagnostics, &PublishDiagnosticsParams{
+			Uri:         filenameToUri(filename),
+			Diagnostics: diagnostics,
+		})
+		if err != nil {
+			return &responseError{Code: errorCodeInternalError, Message: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) completion(params *TextDocumentPositionParams) []CompletionItem {
+	filename := uriToFilename(params.TextDocument.Uri)
+
+	line, column := s.workspace.schemaPosition(filename, params.Position)
+	pos := &node.Position{
+		Filename: filename,
+		Line:     line,
+		Column:   column,
+	}
+
+	items := []CompletionItem{}
+	for _, c := range completions.Completions(s.workspace.files(), pos, s.workspace.config()) {
+		items = append(items, CompletionItem{
+			Label:      c.Label,
+			Kind:       completionItemKind(c.Kind),
+			Detail:     c.Description,
+			InsertText: c.InsertText,
+		})
+	}
+
+	return items
+}
+
+func (s *Server) formatting(params *DocumentFormattingParams) []TextEdit {
+	filename := uriToFilename(params.TextDocument.Uri)
+
+	contents, ok := s.workspace.contents(filename)
+	if !ok {
+		return nil
+	}
+
+	// A schema which can't be parsed is left as it is
+	ast, err := parser.Parse(&reader.SchemaFile{FileName: filename, Contents: contents})
+	if err != nil {
+		return nil
+	}
+
+	formatted := format.Format(ast)
+	if formatted == contents {
+		return []TextEdit{}
+	}
+
+	return []TextEdit{
+		{
+			Range:   Range{Start: Position{}, End: endOfDocument(contents)},
+			NewText: formatted,
+		},
+	}
+}
+
+func (s *Server) hover(params *TextDocumentPositionParams) *Hover {
+	contents, ok := s.workspace.contents(uriToFilename(params.TextDocument.Uri))
+	if !ok {
+		return nil
+	}
+
+	word, r := wordAt(contents, params.Position)
+	docs, ok := hoverDocs(word)
+	if !ok {
+		return nil
+	}
+
+	return &Hover{
+		Contents: MarkupContent{Kind: markupKindMarkdown, Value: docs},
+		Range:    &r,
+	}
+}
+
+func (s *Server) definition(params *TextDocumentPositionParams) []Location {
+	filename := uriToFilename(params.TextDocument.Uri)
+	line, column := s.workspace.schemaPosition(filename, params.Position)
+
+	syms := newSymbols(s.workspace.files(), s.workspace.asts())
+	if name, ok := syms.at(filename, line, column); ok {
+		return []Location{s.workspace.location(syms.declarations[name])}
+	}
+
+	// Otherwise the position may be a field in an action's inputs
+	def := definitions.GetDefinition(s.workspace.files(), definitions.Position{Filename: filename, Line: line, Column: column})
+	if def == nil || def.Schema == nil {
+		return []Location{}
+	}
+
+	pos := s.workspace.lspPosition(def.Schema.Filename, def.Schema.Line, def.Schema.Column)
+
+	return []Location{
+		{
+			Uri:   filenameToUri(def.Schema.Filename),
+			Range: Range{Start: pos, End: pos},
+		},
+	}
+}
+
+func (s *Server) references(params *ReferenceParams) []Location {
+	filename := uriToFilename(params.TextDocument.Uri)
+
+	line, column := s.workspace.schemaPosition(filename, params.Position)
+
+	syms := newSymbols(s.workspace.files(), s.workspace.asts())
+	name, ok := syms.at(filename, line, column)
+	if !ok {
+		return []Location{}
+	}
+
+	locations := []Location{}
+	for _, ref := range syms.references[name] {
+		if ref == syms.declarations[name] && !params.Context.IncludeDeclaration {
+			continue
+		}
+		locations = append(locations, s.workspace.location(ref))
+	}
+
+	return locations
+}
+
+func (s *Server) rename(params *RenameParams) (*WorkspaceEdit, *responseError) {
+	filename := uriToFilename(params.TextDocument.Uri)
+
+	line, column := s.workspace.schemaPosition(filename, params.Position)
+
+	syms := newSymbols(s.workspace.files(), s.workspace.asts())
+	name, ok := syms.at(filename, line, column)
+	if !ok {
+		return nil, &responseError{Code: errorCodeInvalidParams, Message: "only models, enums and messages can be renamed"}
+	}
+
+	if !typeNameRegex.MatchString(params.NewName) {
+		return nil, &responseError{Code: errorCodeInvalidParams, Message: fmt.Sprintf("'%s' is not a valid %s name - it must be in UpperCamelCase", params.NewName, syms.kinds[name])}
+	}
+
+	if _, exists := syms.declarations[params.NewName]; exists || parser.IsBuiltInFieldType(params.NewName) {
+		return nil, &responseError{Code: errorCodeInvalidParams, Message: fmt.Sprintf("the name '%s' is already in use", params.NewName)}
+	}
+
+	edit := &WorkspaceEdit{Changes: map[string][]TextEdit{}}
+	for _, ref := range syms.references[name] {
+		loc := s.workspace.location(ref)
+		edit.Changes[loc.Uri] = append(edit.Changes[loc.Uri], TextEdit{Range: loc.Range, NewText: params.NewName})
+	}
+
+	return edit, nil
+}
+
+func (s *Server) notify(method string, params any) error {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return s.write(&message{JsonRpc: jsonRpcVersion, Method: method, Params: b})
+}
+
+func (s *Server) write(msg *message) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	return err
+}
+
+// readMessage reads a message, which is a header part followed by JSON content. The only
+// header which is used is Content-Length.
+func readMessage(r *bufio.Reader) (*message, error) {
+	headers, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length header: %w", err)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: errorCodeInvalidParams, Message: err.Error()}
+}
+
+func completionItemKind(kind string) int {
+	switch kind {
+	case completions.KindModel, completions.KindType:
+		return completionItemKindClass
+	case completions.KindAction:
+		return completionItemKindFunction
+	case completions.KindField:
+		return completionItemKindField
+	case completions.KindVariable, completions.KindInput:
+		return completionItemKindVariable
+	case completions.KindKeyword, completions.KindAttribute:
+		return completionItemKindKeyword
+	case completions.KindLabel:
+		return completionItemKindProperty
+	default:
+		return completionItemKindText
+	}
+}
+
+// wordAt returns the identifier at the position, including a leading "@", and its range.
+func wordAt(text string, pos Position) (string, Range) {
+	if pos.Line < 0 || pos.Line > strings.Count(text, "\n") {
+		return "", Range{}
+	}
+
+	text = lineAt(text, pos.Line)
+	line := []rune(text)
+	character := runeOffset(text, pos.Character)
+	if pos.Character < 0 || character > len(line) {
+		return "", Range{}
+	}
+
+	isWordChar := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	start, end := character, character
+	for start > 0 && isWordChar(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWordChar(line[end]) {
+		end++
+	}
+	if start > 0 && line[start-1] == '@' && end > start {
+		start--
+	}
+
+	word := string(line[start:end])
+
+	return word, Range{
+		Start: Position{Line: pos.Line, Character: utf16Offset(text, start)},
+		End:   Position{Line: pos.Line, Character: utf16Offset(text, end)},
+	}
+}
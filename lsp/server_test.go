@@ -0,0 +1,379 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/lsp"
+)
+
+const postSchema = `model Post {
+    fields {
+        title Text
+        status Status
+        author Author
+    }
+    actions {
+        get getPost(id)
+        list listPosts(status)
+    }
+}
+
+enum Status {
+    Draft
+    Published
+}
+`
+
+const authorSchema = `model Author {
+    fields {
+        name Text
+        posts Post[]
+    }
+}
+`
+
+type session struct {
+	t   *testing.T
+	dir string
+	// The directory the server is started in, if different to dir.
+	serverDir string
+	input     bytes.Buffer
+	nextId    int
+	results   map[int]json.RawMessage
+	errors    map[int]string
+	notes     []map[string]any
+}
+
+func newSession(t *testing.T) *session {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "post.keel"), []byte(postSchema), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "author.keel"), []byte(authorSchema), 0644))
+
+	s := &session{t: t, dir: dir, results: map[int]json.RawMessage{}, errors: map[int]string{}}
+	s.request("initialize", map[string]any{"rootUri": s.uri("")})
+	s.notify("initialized", map[string]any{})
+	return s
+}
+
+func (s *session) uri(filename string) string {
+	return "file://" + filepath.Join(s.dir, filename)
+}
+
+func (s *session) write(msg map[string]any) {
+	msg["jsonrpc"] = "2.0"
+	b, err := json.Marshal(msg)
+	require.NoError(s.t, err)
+	fmt.Fprintf(&s.input, "Content-Length: %d\r\n\r\n%s", len(b), b)
+}
+
+func (s *session) request(method string, params any) int {
+	s.nextId++
+	s.write(map[string]any{"id": s.nextId, "method": method, "params": params})
+	return s.nextId
+}
+
+func (s *session) notify(method string, params any) {
+	s.write(map[string]any{"method": method, "params": params})
+}
+
+func (s *session) position(filename string, line int, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": s.uri(filename)},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+// run sends every message to the server, followed by a shutdown, and collects its output.
+func (s *session) run() {
+	s.request("shutdown", nil)
+	s.notify("exit", nil)
+
+	var output bytes.Buffer
+	serverDir := s.dir
+	if s.serverDir != "" {
+		serverDir = s.serverDir
+	}
+
+	server := lsp.NewServer(serverDir, "test")
+	require.NoError(s.t, server.Serve(&s.input, &output))
+
+	reader := bufio.NewReader(&output)
+	for {
+		headers, err := textproto.NewReader(reader).ReadMIMEHeader()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(s.t, err)
+
+		length, err := strconv.Atoi(headers.Get("Content-Length"))
+		require.NoError(s.t, err)
+
+		body := make([]byte, length)
+		_, err = io.ReadFull(reader, body)
+		require.NoError(s.t, err)
+
+		var msg struct {
+			Id     *int            `json:"id"`
+			Method string          `json:"method"`
+			Params map[string]any  `json:"params"`
+			Result json.RawMessage `json:"result"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		require.NoError(s.t, json.Unmarshal(body, &msg))
+
+		switch {
+		case msg.Id == nil:
+			s.notes = append(s.notes, map[string]any{"method": msg.Method, "params": msg.Params})
+		case msg.Error != nil:
+			s.errors[*msg.Id] = msg.Error.Message
+		default:
+			s.results[*msg.Id] = msg.Result
+		}
+	}
+}
+
+func (s *session) result(id int, v any) {
+	raw, ok := s.results[id]
+	require.True(s.t, ok, "no result for request %d: %s", id, s.errors[id])
+	require.NoError(s.t, json.Unmarshal(raw, v))
+}
+
+// diagnostics returns the messages of the last diagnostics published for the file.
+func (s *session) diagnostics(filename string) []string {
+	var messages []string
+	for _, n := range s.notes {
+		params := n["params"].(map[string]any)
+		if n["method"] != "textDocument/publishDiagnostics" || params["uri"] != s.uri(filename) {
+			continue
+		}
+		messages = []string{}
+		for _, d := range params["diagnostics"].([]any) {
+			messages = append(messages, d.(map[string]any)["message"].(string))
+		}
+	}
+	return messages
+}
+
+func TestDiagnostics(t *testing.T) {
+	s := newSession(t)
+
+	s.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("author.keel"), "languageId": "keel", "version": 1, "text": authorSchema},
+	})
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": s.uri("author.keel"), "version": 2},
+		"contentChanges": []any{map[string]any{"text": "model Author {\n    fields {\n        name Txt\n    }\n}\n"}},
+	})
+	s.run()
+
+	messages := s.diagnostics("author.keel")
+	require.Len(t, messages, 1)
+	require.Contains(t, messages[0], "Txt")
+
+	// Diagnostics are published for every file in the project
+	require.NotNil(t, s.diagnostics("post.keel"))
+	require.Empty(t, s.diagnostics("post.keel"))
+}
+
+func TestCompletions(t *testing.T) {
+	s := newSession(t)
+	s.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("author.keel"), "languageId": "keel", "version": 1, "text": "model Author {\n    fields {\n        name Te\n    }\n}\n"},
+	})
+	id := s.request("textDocument/completion", s.position("author.keel", 2, 15))
+	s.run()
+
+	var items []lsp.CompletionItem
+	s.result(id, &items)
+
+	labels := []string{}
+	for _, i := range items {
+		labels = append(labels, i.Label)
+	}
+	require.Contains(t, labels, "Text")
+	require.Contains(t, labels, "Post")
+	require.Contains(t, labels, "Status")
+}
+
+func TestFormatting(t *testing.T) {
+	s := newSession(t)
+	s.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("author.keel"), "languageId": "keel", "version": 1, "text": "model Author {\nfields {\nname Text\nposts Post[]\n}\n}"},
+	})
+	id := s.request("textDocument/formatting", map[string]any{"textDocument": map[string]any{"uri": s.uri("author.keel")}})
+	s.run()
+
+	var edits []lsp.TextEdit
+	s.result(id, &edits)
+	require.Len(t, edits, 1)
+	require.Equal(t, authorSchema, edits[0].NewText)
+	require.Equal(t, lsp.Range{End: lsp.Position{Line: 5, Character: 1}}, edits[0].Range)
+}
+
+func TestHover(t *testing.T) {
+	s := newSession(t)
+	typeHover := s.request("textDocument/hover", s.position("post.keel", 2, 15))
+	fieldHover := s.request("textDocument/hover", s.position("post.keel", 2, 10))
+	s.run()
+
+	var hover lsp.Hover
+	s.result(typeHover, &hover)
+	require.Contains(t, hover.Contents.Value, "**Text**")
+	require.Equal(t, lsp.Range{Start: lsp.Position{Line: 2, Character: 14}, End: lsp.Position{Line: 2, Character: 18}}, *hover.Range)
+
+	require.Equal(t, "null", string(s.results[fieldHover]))
+}
+
+func TestHoverAttribute(t *testing.T) {
+	s := newSession(t)
+	s.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("author.keel"), "languageId": "keel", "version": 1, "text": "model Author {\n    fields {\n        name Text @unique\n    }\n}\n"},
+	})
+	id := s.request("textDocument/hover", s.position("author.keel", 2, 21))
+	s.run()
+
+	var hover lsp.Hover
+	s.result(id, &hover)
+	require.Contains(t, hover.Contents.Value, "**@unique**")
+}
+
+func TestHoverUtf16Position(t *testing.T) {
+	s := newSession(t)
+	s.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("author.keel"), "languageId": "keel", "version": 1, "text": "model Author {\n    fields {\n        name Text @default(\"😀\") @unique\n    }\n}\n"},
+	})
+	// The emoji is two UTF-16 code units, so @unique starts at character 33 rather than 32
+	id := s.request("textDocument/hover", s.position("author.keel", 2, 35))
+	s.run()
+
+	var hover lsp.Hover
+	s.result(id, &hover)
+	require.Contains(t, hover.Contents.Value, "**@unique**")
+	require.Equal(t, lsp.Range{Start: lsp.Position{Line: 2, Character: 33}, End: lsp.Position{Line: 2, Character: 40}}, *hover.Range)
+}
+
+func TestRelativeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "post.keel"), []byte(postSchema), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "author.keel"), []byte(authorSchema), 0644))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	rel, err := filepath.Rel(wd, dir)
+	require.NoError(t, err)
+
+	// Without a root URI the files on disk are found relative to the directory the server was started in,
+	// but must still be the same files as the documents opened in the editor
+	s := &session{t: t, dir: dir, serverDir: rel, results: map[int]json.RawMessage{}, errors: map[int]string{}}
+	s.request("initialize", map[string]any{})
+	s.notify("initialized", map[string]any{})
+	s.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("author.keel"), "languageId": "keel", "version": 1, "text": authorSchema},
+	})
+	s.run()
+
+	require.NotNil(t, s.diagnostics("author.keel"))
+	require.Empty(t, s.diagnostics("author.keel"))
+	require.Empty(t, s.diagnostics("post.keel"))
+}
+
+func TestDefinition(t *testing.T) {
+	s := newSession(t)
+	model := s.request("textDocument/definition", s.position("post.keel", 4, 16))
+	enum := s.request("textDocument/definition", s.position("post.keel", 3, 16))
+	input := s.request("textDocument/definition", s.position("post.keel", 8, 25))
+	s.run()
+
+	var locations []lsp.Location
+	s.result(model, &locations)
+	require.Equal(t, []lsp.Location{{
+		Uri:   s.uri("author.keel"),
+		Range: lsp.Range{Start: lsp.Position{Line: 0, Character: 6}, End: lsp.Position{Line: 0, Character: 12}},
+	}}, locations)
+
+	s.result(enum, &locations)
+	require.Equal(t, s.uri("post.keel"), locations[0].Uri)
+	require.Equal(t, lsp.Position{Line: 12, Character: 5}, locations[0].Range.Start)
+
+	s.result(input, &locations)
+	require.Equal(t, s.uri("post.keel"), locations[0].Uri)
+	require.Equal(t, lsp.Position{Line: 3, Character: 8}, locations[0].Range.Start)
+}
+
+func TestReferences(t *testing.T) {
+	s := newSession(t)
+	withDeclaration := s.request("textDocument/references", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("post.keel")},
+		"position":     map[string]any{"line": 0, "character": 7},
+		"context":      map[string]any{"includeDeclaration": true},
+	})
+	withoutDeclaration := s.request("textDocument/references", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("post.keel")},
+		"position":     map[string]any{"line": 0, "character": 7},
+		"context":      map[string]any{"includeDeclaration": false},
+	})
+	s.run()
+
+	var locations []lsp.Location
+	s.result(withDeclaration, &locations)
+	require.Len(t, locations, 2)
+
+	s.result(withoutDeclaration, &locations)
+	require.Equal(t, []lsp.Location{{
+		Uri:   s.uri("author.keel"),
+		Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 14}, End: lsp.Position{Line: 3, Character: 18}},
+	}}, locations)
+}
+
+func TestRename(t *testing.T) {
+	s := newSession(t)
+	rename := s.request("textDocument/rename", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("author.keel")},
+		"position":     map[string]any{"line": 0, "character": 8},
+		"newName":      "Writer",
+	})
+	invalid := s.request("textDocument/rename", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("author.keel")},
+		"position":     map[string]any{"line": 0, "character": 8},
+		"newName":      "writer",
+	})
+	taken := s.request("textDocument/rename", map[string]any{
+		"textDocument": map[string]any{"uri": s.uri("author.keel")},
+		"position":     map[string]any{"line": 0, "character": 8},
+		"newName":      "Status",
+	})
+	s.run()
+
+	var edit lsp.WorkspaceEdit
+	s.result(rename, &edit)
+	require.Len(t, edit.Changes, 2)
+	require.Equal(t, []lsp.TextEdit{{
+		Range:   lsp.Range{Start: lsp.Position{Line: 0, Character: 6}, End: lsp.Position{Line: 0, Character: 12}},
+		NewText: "Writer",
+	}}, edit.Changes[s.uri("author.keel")])
+	require.Equal(t, []lsp.TextEdit{{
+		Range:   lsp.Range{Start: lsp.Position{Line: 4, Character: 15}, End: lsp.Position{Line: 4, Character: 21}},
+		NewText: "Writer",
+	}}, edit.Changes[s.uri("post.keel")])
+
+	require.Contains(t, s.errors[invalid], "UpperCamelCase")
+	require.Contains(t, s.errors[taken], "already in use")
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	input := "Content-Length: 33\r\n\r\n{\"jsonrpc\":\"2.0\",\"method\":\"exit\"}"
+	err := lsp.NewServer("", "test").Serve(bytes.NewBufferString(input), io.Discard)
+	require.ErrorIs(t, err, lsp.ErrExitWithoutShutdown)
+}
@@ -0,0 +1,273 @@
+package lsp
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/schema"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/reader"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+// workspace holds the schema files of the project. Files open in the editor take
+// precedence over their contents on disk, as they may have unsaved changes.
+type workspace struct {
+	dir   string
+	disk  map[string]string
+	open  map[string]string
+	known map[string]bool
+}
+
+func newWorkspace(dir string) *workspace {
+	// Files on disk are named relative to the directory, so it's made absolute for
+	// their names to match those of documents opened in the editor, which are URIs
+	if dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+
+	w := &workspace{
+		dir:   dir,
+		disk:  map[string]string{},
+		open:  map[string]string{},
+		known: map[string]bool{},
+	}
+	w.load()
+	return w
+}
+
+// load reads the schema files in the project directory from disk.
+func (w *workspace) load() {
+	w.disk = map[string]string{}
+
+	if w.dir == "" {
+		return
+	}
+
+	inputs, err := reader.FromDir(w.dir)
+	if err != nil {
+		return
+	}
+
+	for _, f := range inputs.SchemaFiles {
+		w.disk[f.FileName] = f.Contents
+	}
+}
+
+// files returns every schema file in the workspace, ordered by filename so that results are deterministic.
+func (w *workspace) files() []*reader.SchemaFile {
+	names := []string{}
+	for name := range w.disk {
+		if _, ok := w.open[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for name := range w.open {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	files := []*reader.SchemaFile{}
+	for _, name := range names {
+		contents, _ := w.contents(name)
+		files = append(files, &reader.SchemaFile{FileName: name, Contents: contents})
+	}
+
+	return files
+}
+
+func (w *workspace) contents(filename string) (string, bool) {
+	if contents, ok := w.open[filename]; ok {
+		return contents, true
+	}
+
+	contents, ok := w.disk[filename]
+	return contents, ok
+}
+
+// config loads the project config, returning nil if there isn't a valid one.
+func (w *workspace) config() *config.ProjectConfig {
+	if w.dir == "" {
+		return nil
+	}
+
+	cfg, err := config.Load(w.dir)
+	if err != nil {
+		return nil
+	}
+
+	return cfg
+}
+
+// diagnostics validates the schema files and returns the errors and warnings for each file. Every file in the
+// workspace, and every file which previously had diagnostics, is included so that fixed problems are cleared.
+func (w *workspace) diagnostics() map[string][]Diagnostic {
+	result := map[string][]Diagnostic{}
+	for name := range w.known {
+		result[name] = []Diagnostic{}
+	}
+
+	files := w.files()
+	for _, f := range files {
+		result[f.FileName] = []Diagnostic{}
+	}
+
+	builder := schema.Builder{}
+	if cfg := w.config(); cfg != nil {
+		builder.Config = cfg
+	}
+
+	inputs := &reader.Inputs{Directory: w.dir, SchemaFiles: files}
+	if w.dir != "" {
+		// Migrations are only used to warn about stale @renamedFrom attributes, so if they can't be read they're left out
+		inputs.MigrationFiles, _ = reader.MigrationsFromDir(w.dir)
+	}
+
+	err := builder.ValidateFromInputs(inputs, true)
+
+	var errs *errorhandling.ValidationErrors
+	if errors.As(err, &errs) && errs != nil {
+		for _, e := range errs.Errors {
+			w.appendDiagnostic(result, e, diagnosticSeverityError)
+		}
+		for _, e := range errs.Warnings {
+			w.appendDiagnostic(result, e, diagnosticSeverityWarning)
+		}
+	}
+
+	w.known = map[string]bool{}
+	for name, diagnostics := range result {
+		if len(diagnostics) > 0 {
+			w.known[name] = true
+		}
+	}
+
+	return result
+}
+
+func (w *workspace) appendDiagnostic(result map[string][]Diagnostic, e *errorhandling.ValidationError, severity int) {
+	// Errors which aren't positioned in a file can't be shown
+	if e.Pos.Filename == "" || e.ErrorDetails == nil {
+		return
+	}
+
+	message := e.Message
+	if e.Hint != "" {
+		message += "\n" + e.Hint
+	}
+
+	start := w.lspPosition(e.Pos.Filename, e.Pos.Line, e.Pos.Column)
+	end := w.lspPosition(e.Pos.Filename, e.EndPos.Line, e.EndPos.Column)
+	if e.EndPos.Line == 0 {
+		end = start
+	}
+
+	result[e.Pos.Filename] = append(result[e.Pos.Filename], Diagnostic{
+		Range:    Range{Start: start, End: end},
+		Severity: severity,
+		Code:     e.Code,
+		Source:   "keel",
+		Message:  message,
+	})
+}
+
+// asts parses each schema file. Files which cannot be parsed are skipped.
+func (w *workspace) asts() []*parser.AST {
+	asts := []*parser.AST{}
+	for _, f := range w.files() {
+		ast, _ := parser.Parse(f)
+		if ast != nil {
+			asts = append(asts, ast)
+		}
+	}
+	return asts
+}
+
+// lspPosition converts a line and column in a schema file, which are numbered from 1 and count
+// characters, to a position in the document, which is numbered from 0 and counts UTF-16 code units.
+func (w *workspace) lspPosition(filename string, line int, column int) Position {
+	contents, _ := w.contents(filename)
+	return Position{Line: line - 1, Character: utf16Offset(lineAt(contents, line-1), column-1)}
+}
+
+// schemaPosition converts a position in the document to a line and column in the schema file.
+func (w *workspace) schemaPosition(filename string, pos Position) (int, int) {
+	contents, _ := w.contents(filename)
+	return pos.Line + 1, runeOffset(lineAt(contents, pos.Line), pos.Character) + 1
+}
+
+// location is the range of the token in its document.
+func (w *workspace) location(t token) Location {
+	return Location{
+		Uri: filenameToUri(t.filename),
+		Range: Range{
+			Start: w.lspPosition(t.filename, t.line, t.column),
+			End:   w.lspPosition(t.filename, t.line, t.column+utf8.RuneCountInString(t.value)),
+		},
+	}
+}
+
+func uriToFilename(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+
+	return filepath.Clean(filepath.FromSlash(u.Path))
+}
+
+func filenameToUri(filename string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(filename)}
+	return u.String()
+}
+
+// endOfDocument is the position after the last character of the text.
+func endOfDocument(text string) Position {
+	line := strings.Count(text, "\n")
+	last := text[strings.LastIndex(text, "\n")+1:]
+	return Position{Line: line, Character: utf16Offset(last, utf8.RuneCountInString(last))}
+}
+
+// lineAt returns the text of the line, numbered from 0.
+func lineAt(text string, line int) string {
+	lines := strings.Split(text, "\n")
+	if line < 0 || line >= len(lines) {
+		return ""
+	}
+	return strings.TrimSuffix(lines[line], "\r")
+}
+
+// utf16Offset converts an offset into the line in characters to an offset in UTF-16 code units.
+func utf16Offset(line string, runes int) int {
+	units := 0
+	for i, r := range []rune(line) {
+		if i >= runes {
+			return units
+		}
+		units += utf16.RuneLen(r)
+	}
+
+	// Offsets past the end of the line are kept, as positions may be at the end of a line
+	return units + runes - utf8.RuneCountInString(line)
+}
+
+// runeOffset converts an offset into the line in UTF-16 code units to an offset in characters.
+func runeOffset(line string, units int) int {
+	offset := 0
+	for i, r := range []rune(line) {
+		if offset >= units {
+			return i
+		}
+		offset += utf16.RuneLen(r)
+	}
+
+	return utf8.RuneCountInString(line) + units - offset
+}
@@ -16,7 +16,7 @@ LEFT JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
 LEFT JOIN pg_catalog.pg_index i on i.indexrelid = a.attrelid
 WHERE
 	n.nspname = 'public'
-	AND c.relname not in ('keel_schema', 'keel_refresh_token', 'flow_run', 'flow_step', 'keel_auth_code', 'pg_stat_statements_info', 'pg_stat_statements')
+	AND c.relname not in ('keel_schema', 'keel_migrations', 'keel_refresh_token', 'flow_run', 'flow_step', 'keel_auth_code', 'keel_idempotency_key', 'keel_rate_limit', 'keel_service_account', 'keel_passwordless_code', 'keel_mfa', 'keel_mfa_recovery_code', 'keel_session', 'keel_lockout', 'keel_subscription_connection', 'keel_subscription', 'pg_stat_statements_info', 'pg_stat_statements')
 	AND c.relname not like '%__sequence_seq'
 	AND a.attnum > 0
 	AND NOT a.attisdropped
@@ -5,10 +5,31 @@ SELECT
 	contype constraint_type,
 	confdeltype on_delete ,
 	conkey constrained_columns,
-	confkey references_columns
+	confkey references_columns,
+	false partial
 FROM pg_catalog.pg_constraint r
 LEFT JOIN pg_catalog.pg_class c on c.oid = r.conrelid
 LEFT JOIN pg_catalog.pg_class c2 on c2.oid = r.confrelid
 LEFT JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
 WHERE 
-	n.nspname = 'public'
\ No newline at end of file
+	n.nspname = 'public'
+UNION ALL
+-- Unique fields of models with @softDelete are unique indexes which only apply to rows which have not been deleted
+SELECT
+	c.relname::text table_name,
+	NULL::text on_table,
+	i.relname::text constraint_name,
+	'u' constraint_type,
+	NULL on_delete,
+	ix.indkey::smallint[] constrained_columns,
+	NULL::smallint[] references_columns,
+	true partial
+FROM pg_catalog.pg_index ix
+JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+JOIN pg_catalog.pg_class c ON c.oid = ix.indrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE
+	n.nspname = 'public' AND
+	ix.indisunique AND
+	ix.indpred IS NOT NULL AND
+	i.relname LIKE '%\_udx'
@@ -0,0 +1,554 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MigrationsDirectory is the directory in a project in which migration files are kept.
+const MigrationsDirectory = "migrations"
+
+// irreversibleMarker is written at the top of a down migration which could not be generated. The
+// migration cannot be reverted until the SQL has been written by hand and the marker removed.
+const irreversibleMarker = "-- keel:irreversible"
+
+// concurrentStmtsComment is written above the statements in an up migration which are run outside of its transaction.
+const concurrentStmtsComment = "-- Run one at a time once the rest of the migration has been committed, so that writes are not blocked"
+
+var (
+	ErrNoChanges             = errors.New("no database schema changes to generate a migration for")
+	ErrIrreversibleMigration = errors.New("migration cannot be reverted")
+	ErrPendingMigrations     = errors.New("there are migrations which have not been applied")
+	ErrMigrationFileModified = errors.New("migration file has been modified since it was applied")
+	ErrMigrationFileMissing  = errors.New("migration has been applied but its file is missing")
+	ErrMigrationOutOfOrder   = errors.New("migration is older than the latest applied migration")
+	ErrInvalidMigrationName  = errors.New("migration names can only contain letters, numbers and underscores")
+	ErrUnmigratedChanges     = errors.New("the schema has changes which are not in a migration file, run 'keel migrate generate <name>' to create one")
+)
+
+var (
+	migrationFileRegex = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	migrationNameRegex = regexp.MustCompile(`^\w+$`)
+)
+
+const createMigrationsTableStmt = "CREATE TABLE IF NOT EXISTS keel_migrations (version INTEGER NOT NULL PRIMARY KEY, name TEXT NOT NULL, checksum TEXT NOT NULL, applied_at TIMESTAMPTZ NOT NULL DEFAULT now());"
+
+// MigrationFile is a numbered migration kept in the project's migrations directory. Each
+// migration is a pair of files, e.g. 0001_add_posts.up.sql and 0001_add_posts.down.sql.
+type MigrationFile struct {
+	Version int
+	Name    string
+
+	// The SQL to apply the migration
+	Up string
+
+	// The SQL to revert the migration, which may be marked as irreversible
+	Down string
+}
+
+// Filename returns the name of the migration's files without the up or down suffix.
+func (f *MigrationFile) Filename() string {
+	return fmt.Sprintf("%04d_%s", f.Version, f.Name)
+}
+
+// Checksum is recorded when a migration is applied so that changes to
+// the file afterwards can be detected.
+func (f *MigrationFile) Checksum() string {
+	sum := sha256.Sum256([]byte(f.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// Reversible returns true if the migration has down SQL which can be run.
+func (f *MigrationFile) Reversible() bool {
+	down := strings.TrimSpace(f.Down)
+	return down != "" && !strings.HasPrefix(down, irreversibleMarker)
+}
+
+// AppliedMigration is a row in the keel_migrations table.
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// MigrationStatus describes a migration which is either in the migrations directory,
+// the keel_migrations table, or both.
+type MigrationStatus struct {
+	Version int
+	Name    string
+
+	// Nil if the migration has been applied but its file no longer exists
+	File *MigrationFile
+
+	// Nil if the migration has not been applied
+	Applied *AppliedMigration
+}
+
+// Pending returns true if the migration has not yet been applied.
+func (s *MigrationStatus) Pending() bool {
+	return s.Applied == nil
+}
+
+// Modified returns true if the migration file has changed since it was applied.
+func (s *MigrationStatus) Modified() bool {
+	return s.File != nil && s.Applied != nil && s.File.Checksum() != s.Applied.Checksum
+}
+
+// ReadMigrationFiles reads all the migrations in dir, ordered by version. A missing directory
+// is treated as there being no migrations.
+func ReadMigrationFiles(dir string) ([]*MigrationFile, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*MigrationFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[int]*MigrationFile{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		f, ok := files[version]
+		if !ok {
+			f = &MigrationFile{
+				Version: version,
+				Name:    matches[2],
+			}
+			files[version] = f
+		}
+
+		if f.Name != matches[2] {
+			return nil, fmt.Errorf("there is more than one migration with version %d", version)
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if matches[3] == "up" {
+			f.Up = string(b)
+		} else {
+			f.Down = string(b)
+		}
+	}
+
+	result := lo.Values(files)
+	slices.SortFunc(result, func(a, b *MigrationFile) int {
+		return a.Version - b.Version
+	})
+
+	return result, nil
+}
+
+// WriteMigrationFile generates the next numbered migration in dir from the changes in m,
+// along with a down migration if one can be generated.
+func WriteMigrationFile(dir string, name string, m *Migrations) (*MigrationFile, error) {
+	if !m.HasEntityFieldChanges() {
+		return nil, ErrNoChanges
+	}
+
+	name = casing.ToSnake(name)
+	if !migrationNameRegex.MatchString(name) {
+		return nil, ErrInvalidMigrationName
+	}
+
+	existing, err := ReadMigrationFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	version := 1
+	if len(existing) > 0 {
+		version = existing[len(existing)-1].Version + 1
+	}
+
+	header := strings.Builder{}
+	for _, change := range m.Changes {
+		header.WriteString(fmt.Sprintf("-- %s\n", changeDescription(change)))
+	}
+
+	down, err := m.DownSQL()
+	if errors.Is(err, ErrIrreversibleMigration) {
+		down = fmt.Sprintf("%s\n-- %s\n-- Write the SQL to revert this migration and remove the line above.\n", irreversibleMarker, err.Error())
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Statements which build indexes concurrently or validate constraints are run after the rest of the migration
+	// has been committed, so they are kept apart at the end of the file to match
+	up, concurrentStmts := splitConcurrentStmts(m.SQL)
+	up = header.String() + up + "\n"
+	if len(concurrentStmts) > 0 {
+		up += fmt.Sprintf("\n%s\n%s\n", concurrentStmtsComment, strings.Join(concurrentStmts, "\n"))
+	}
+
+	f := &MigrationFile{
+		Version: version,
+		Name:    name,
+		Up:      up,
+		Down:    down,
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, f.Filename()+".up.sql"), []byte(f.Up), 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, f.Filename()+".down.sql"), []byte(f.Down), 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// DownSQL generates the SQL to revert the changes in m. Only added tables and columns can
+// be reverted, as the data or definitions needed to undo anything else are no longer known.
+func (m *Migrations) DownSQL() (string, error) {
+	if len(m.Changes) == 0 {
+		return "", fmt.Errorf("%w: changes to indexes and triggers cannot be reverted", ErrIrreversibleMigration)
+	}
+
+	statements := []string{}
+
+	hasComputedFields := lo.ContainsBy(m.Schema.Entities(), func(e proto.Entity) bool {
+		return len(e.GetComputedFields()) > 0
+	})
+
+	for _, change := range lo.Reverse(slices.Clone(m.Changes)) {
+		if change.Type == ChangeTypeRenamed {
+			// Computed field functions are recreated using the new names, so renaming back would break them
+			if hasComputedFields {
+				return "", fmt.Errorf("%w: %s", ErrIrreversibleMigration, changeDescription(change))
+			}
+
+			statements = append(statements, m.revertRenameStmts(change)...)
+			continue
+		}
+
+		if change.Type != ChangeTypeAdded {
+			return "", fmt.Errorf("%w: %s", ErrIrreversibleMigration, changeDescription(change))
+		}
+
+		if change.Field == "" {
+			statements = append(statements, dropTableStmt(change.Model))
+			continue
+		}
+
+		entity := m.Schema.FindEntity(change.Model)
+		if entity == nil {
+			return "", fmt.Errorf("%w: %s", ErrIrreversibleMigration, changeDescription(change))
+		}
+
+		field := entity.FindField(change.Field)
+		if field == nil || field.GetComputedExpression() != nil {
+			// Computed fields also have functions and triggers which dropping the column would leave behind.
+			return "", fmt.Errorf("%w: %s", ErrIrreversibleMigration, changeDescription(change))
+		}
+
+		statements = append(statements, dropColumnStmt(change.Model, change.Field))
+		if field.GetSequence() != nil {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s CASCADE;", Identifier(change.Model), sequenceColumnName(field)))
+		}
+	}
+
+	return strings.Join(statements, "\n") + "\n", nil
+}
+
+// GetAppliedMigrations returns the rows in the keel_migrations table ordered by version.
+func GetAppliedMigrations(ctx context.Context, database db.Database) ([]*AppliedMigration, error) {
+	result, err := database.ExecuteQuery(ctx, "SELECT to_regclass('keel_migrations') AS name")
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []*AppliedMigration{}
+	if result.Rows[0]["name"] == nil {
+		return applied, nil
+	}
+
+	err = database.GetDB().WithContext(ctx).Raw("SELECT version, name, checksum, applied_at FROM keel_migrations ORDER BY version").Scan(&applied).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// GetMigrationStatus matches the migration files against the migrations applied to the database.
+func GetMigrationStatus(ctx context.Context, database db.Database, files []*MigrationFile) ([]*MigrationStatus, error) {
+	applied, err := GetAppliedMigrations(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := map[int]*MigrationStatus{}
+	for _, f := range files {
+		statuses[f.Version] = &MigrationStatus{
+			Version: f.Version,
+			Name:    f.Name,
+			File:    f,
+		}
+	}
+
+	for _, a := range applied {
+		s, ok := statuses[a.Version]
+		if !ok {
+			s = &MigrationStatus{
+				Version: a.Version,
+				Name:    a.Name,
+			}
+			statuses[a.Version] = s
+		}
+		s.Applied = a
+	}
+
+	result := lo.Values(statuses)
+	slices.SortFunc(result, func(a, b *MigrationStatus) int {
+		return a.Version - b.Version
+	})
+
+	return result, nil
+}
+
+// PendingMigrations returns the migration files which have not been applied, in order. Applied migrations are
+// checked to make sure their files have not been modified or removed, and pending migrations must be newer than
+// any applied ones.
+func PendingMigrations(ctx context.Context, database db.Database, files []*MigrationFile) ([]*MigrationFile, error) {
+	statuses, err := GetMigrationStatus(ctx, database, files)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []*MigrationFile{}
+	latest := 0
+	for _, s := range statuses {
+		switch {
+		case s.File == nil:
+			return nil, fmt.Errorf("%w: %04d_%s", ErrMigrationFileMissing, s.Version, s.Name)
+		case s.Modified():
+			return nil, fmt.Errorf("%w: %s", ErrMigrationFileModified, s.File.Filename())
+		case s.Pending():
+			pending = append(pending, s.File)
+		default:
+			latest = s.Version
+		}
+	}
+
+	for _, f := range pending {
+		if f.Version < latest {
+			return nil, fmt.Errorf("%w: %s", ErrMigrationOutOfOrder, f.Filename())
+		}
+	}
+
+	return pending, nil
+}
+
+// Up applies all pending migration files, in order, in a single transaction. Each migration is
+// recorded in the keel_migrations table.
+func Up(ctx context.Context, schema *proto.Schema, database db.Database, files []*MigrationFile) ([]*MigrationFile, error) {
+	ctx, span := tracer.Start(ctx, "Apply Migration Files")
+	defer span.End()
+
+	pending, err := PendingMigrations(ctx, database, files)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("pending", len(pending)))
+
+	if len(pending) == 0 {
+		return pending, nil
+	}
+
+	sql := strings.Builder{}
+	sql.WriteString(createMigrationsTableStmt)
+	sql.WriteString("\n")
+	concurrentStmts := []string{}
+	for _, f := range pending {
+		up, stmts := splitConcurrentStmts(f.Up)
+		concurrentStmts = append(concurrentStmts, stmts...)
+
+		sql.WriteString(up)
+		sql.WriteString("\n")
+		sql.WriteString(fmt.Sprintf("INSERT INTO keel_migrations (version, name, checksum) VALUES (%d, %s, %s);\n", f.Version, db.QuoteLiteral(f.Name), db.QuoteLiteral(f.Checksum())))
+	}
+
+	// The migration files replace the generated SQL, but everything else Keel needs is still set up by Apply
+	m := &Migrations{
+		database: database,
+		Schema:   schema,
+		SQL:      sql.String(),
+	}
+
+	err = database.Transaction(ctx, func(ctx context.Context) error {
+		return m.Apply(ctx, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Indexes can't be built concurrently in a transaction, so they are built one at a time once the migrations
+	// have been committed, along with validating any constraints. If an index fails then it is left invalid, and is
+	// dropped and built again by the next migration.
+	for _, stmt := range concurrentStmts {
+		_, err = database.ExecuteStatement(ctx, stmt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pending, nil
+}
+
+// UpAndApply is used in place of Apply for projects which have migration files. Pending migration files are
+// applied and recorded first, then the migration generated from the schema is applied to set up everything
+// else Keel needs. Changes to models and fields must be made by migration files, otherwise they would not be
+// recorded, so ErrUnmigratedChanges is returned if the generated migration has any.
+func UpAndApply(ctx context.Context, schema *proto.Schema, database db.Database, files []*MigrationFile) ([]*MigrationFile, *Migrations, error) {
+	applied, err := Up(ctx, schema, database, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := New(ctx, schema, database)
+	if err != nil {
+		return applied, nil, err
+	}
+
+	if m.HasEntityFieldChanges() {
+		return applied, m, ErrUnmigratedChanges
+	}
+
+	err = m.Apply(ctx, false)
+	if err != nil {
+		return applied, m, err
+	}
+
+	return applied, m, nil
+}
+
+// Down reverts the latest applied migrations, up to the number of steps given, in a single
+// transaction. The stored Keel schema is not reverted, so the project's schema should be
+// changed to match before more migrations are generated.
+func Down(ctx context.Context, database db.Database, files []*MigrationFile, steps int) ([]*MigrationFile, error) {
+	ctx, span := tracer.Start(ctx, "Revert Migration Files")
+	defer span.End()
+
+	statuses, err := GetMigrationStatus(ctx, database, files)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := lo.Filter(statuses, func(s *MigrationStatus, _ int) bool {
+		return !s.Pending()
+	})
+
+	reverted := []*MigrationFile{}
+	for i := len(applied) - 1; i >= 0 && len(reverted) < steps; i-- {
+		s := applied[i]
+		switch {
+		case s.File == nil:
+			return nil, fmt.Errorf("%w: %04d_%s", ErrMigrationFileMissing, s.Version, s.Name)
+		case s.Modified():
+			return nil, fmt.Errorf("%w: %s", ErrMigrationFileModified, s.File.Filename())
+		case !s.File.Reversible():
+			return nil, fmt.Errorf("%w: %s", ErrIrreversibleMigration, s.File.Filename())
+		}
+		reverted = append(reverted, s.File)
+	}
+
+	span.SetAttributes(attribute.Int("reverted", len(reverted)))
+
+	if len(reverted) == 0 {
+		return reverted, nil
+	}
+
+	err = database.Transaction(ctx, func(ctx context.Context) error {
+		for _, f := range reverted {
+			_, err := database.ExecuteStatement(ctx, f.Down)
+			if err != nil {
+				return fmt.Errorf("reverting %s: %w", f.Filename(), err)
+			}
+
+			_, err = database.ExecuteStatement(ctx, "DELETE FROM keel_migrations WHERE version = ?", f.Version)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reverted, nil
+}
+
+// revertRenameStmts renames a table or column back to its previous name. Constraints, indexes
+// and triggers keep their new names, which is harmless as they are found by table.
+func (m *Migrations) revertRenameStmts(change *DatabaseChange) []string {
+	if change.Field == "" {
+		return []string{fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", Identifier(change.Model), Identifier(change.RenamedFrom))}
+	}
+
+	table := change.Model
+	if entity := m.Schema.FindEntity(change.Model); entity != nil {
+		field := entity.FindField(change.Field)
+		if field != nil && field.GetSequence() != nil {
+			return []string{
+				fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", Identifier(table), Identifier(change.Field), Identifier(change.RenamedFrom)),
+				fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", Identifier(table), sequenceColumnName(field), db.QuoteIdentifier(casing.ToSnake(change.RenamedFrom)+sequenceSuffix)),
+			}
+		}
+	}
+
+	return []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", Identifier(table), Identifier(change.Field), Identifier(change.RenamedFrom))}
+}
+
+func changeDescription(change *DatabaseChange) string {
+	name := change.Model
+	if change.Field != "" {
+		name = fmt.Sprintf("%s.%s", change.Model, change.Field)
+	}
+	if change.RenamedFrom != "" {
+		return fmt.Sprintf("%s %s from %s", name, strings.ToLower(change.Type), change.RenamedFrom)
+	}
+	return fmt.Sprintf("%s %s", name, strings.ToLower(change.Type))
+}
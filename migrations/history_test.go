@@ -0,0 +1,304 @@
+package migrations_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/migrations"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/schema"
+)
+
+const historyTestSchema = `
+model Post {
+	fields {
+		title Text
+		number Text @sequence("P")
+		author Author
+	}
+}
+
+model Author {
+	fields {
+		name Text
+	}
+}`
+
+func historyTestProto(t *testing.T) *proto.Schema {
+	builder := &schema.Builder{}
+	s, err := builder.MakeFromString(historyTestSchema, config.Empty)
+	require.NoError(t, err)
+	return s
+}
+
+func TestDownSQL(t *testing.T) {
+	m := &migrations.Migrations{
+		Schema: historyTestProto(t),
+		Changes: []*migrations.DatabaseChange{
+			{Model: "Author", Type: migrations.ChangeTypeAdded},
+			{Model: "Post", Field: "title", Type: migrations.ChangeTypeAdded},
+			{Model: "Post", Field: "number", Type: migrations.ChangeTypeAdded},
+			{Model: "Post", Field: "authorId", Type: migrations.ChangeTypeAdded},
+		},
+		SQL: "-- changes",
+	}
+
+	down, err := m.DownSQL()
+	require.NoError(t, err)
+	require.Equal(t, `ALTER TABLE "post" DROP COLUMN "author_id" CASCADE;
+ALTER TABLE "post" DROP COLUMN "number" CASCADE;
+ALTER TABLE "post" DROP COLUMN "number__sequence" CASCADE;
+ALTER TABLE "post" DROP COLUMN "title" CASCADE;
+DROP TABLE "author" CASCADE;
+`, down)
+}
+
+func TestDownSQLRenamed(t *testing.T) {
+	m := &migrations.Migrations{
+		Schema: historyTestProto(t),
+		Changes: []*migrations.DatabaseChange{
+			{Model: "Author", Type: migrations.ChangeTypeRenamed, RenamedFrom: "Writer"},
+			{Model: "Post", Field: "title", Type: migrations.ChangeTypeRenamed, RenamedFrom: "heading"},
+			{Model: "Post", Field: "number", Type: migrations.ChangeTypeRenamed, RenamedFrom: "reference"},
+		},
+		SQL: "-- changes",
+	}
+
+	down, err := m.DownSQL()
+	require.NoError(t, err)
+	require.Equal(t, `ALTER TABLE "post" RENAME COLUMN "number" TO "reference";
+ALTER TABLE "post" RENAME COLUMN "number__sequence" TO "reference__sequence";
+ALTER TABLE "post" RENAME COLUMN "title" TO "heading";
+ALTER TABLE "author" RENAME TO "writer";
+`, down)
+}
+
+func TestDownSQLIrreversible(t *testing.T) {
+	for _, change := range []*migrations.DatabaseChange{
+		{Model: "Post", Field: "title", Type: migrations.ChangeTypeModified},
+		{Model: "Post", Field: "body", Type: migrations.ChangeTypeRemoved},
+		{Model: "Comment", Type: migrations.ChangeTypeRemoved},
+	} {
+		m := &migrations.Migrations{
+			Schema: historyTestProto(t),
+			Changes: []*migrations.DatabaseChange{
+				{Model: "Author", Type: migrations.ChangeTypeAdded},
+				change,
+			},
+			SQL: "-- changes",
+		}
+
+		_, err := m.DownSQL()
+		require.ErrorIs(t, err, migrations.ErrIrreversibleMigration, change.String())
+	}
+}
+
+func TestWriteAndReadMigrationFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+
+	files, err := migrations.ReadMigrationFiles(dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	first, err := migrations.WriteMigrationFile(dir, "addAuthors", &migrations.Migrations{
+		Schema:  historyTestProto(t),
+		Changes: []*migrations.DatabaseChange{{Model: "Author", Type: migrations.ChangeTypeAdded}},
+		SQL:     `CREATE TABLE "author" ();`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0001_add_authors", first.Filename())
+	require.True(t, first.Reversible())
+
+	second, err := migrations.WriteMigrationFile(dir, "remove_comments", &migrations.Migrations{
+		Schema:  historyTestProto(t),
+		Changes: []*migrations.DatabaseChange{{Model: "Comment", Type: migrations.ChangeTypeRemoved}},
+		SQL:     `DROP TABLE "comment" CASCADE;`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0002_remove_comments", second.Filename())
+	require.False(t, second.Reversible())
+
+	// Other files in the directory are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Migrations"), 0o644))
+
+	files, err = migrations.ReadMigrationFiles(dir)
+	require.NoError(t, err)
+	require.Equal(t, []*migrations.MigrationFile{first, second}, files)
+	require.Equal(t, "-- Author added\nCREATE TABLE \"author\" ();\n", files[0].Up)
+	require.Equal(t, "DROP TABLE \"author\" CASCADE;\n", files[0].Down)
+
+	// The checksum changes if the file is edited
+	checksum := files[0].Checksum()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0001_add_authors.up.sql"), []byte("CREATE TABLE \"author\" (id TEXT);\n"), 0o644))
+
+	files, err = migrations.ReadMigrationFiles(dir)
+	require.NoError(t, err)
+	require.NotEqual(t, checksum, files[0].Checksum())
+}
+
+func TestWriteMigrationFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := migrations.WriteMigrationFile(dir, "nothing", &migrations.Migrations{Schema: historyTestProto(t)})
+	require.ErrorIs(t, err, migrations.ErrNoChanges)
+
+	_, err = migrations.WriteMigrationFile(dir, "../escape", &migrations.Migrations{
+		Schema:  historyTestProto(t),
+		Changes: []*migrations.DatabaseChange{{Model: "Author", Type: migrations.ChangeTypeAdded}},
+		SQL:     `CREATE TABLE "author" ();`,
+	})
+	require.ErrorIs(t, err, migrations.ErrInvalidMigrationName)
+}
+
+func TestReadMigrationFilesDuplicateVersion(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0001_add_posts.up.sql"), []byte(""), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0001_add_authors.up.sql"), []byte(""), 0o644))
+
+	_, err := migrations.ReadMigrationFiles(dir)
+	require.Error(t, err)
+}
+
+func TestWriteMigrationFileConcurrentIndex(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := migrations.WriteMigrationFile(dir, "index_titles", &migrations.Migrations{
+		Schema:  historyTestProto(t),
+		Changes: []*migrations.DatabaseChange{{Model: "Post", Field: "subtitle", Type: migrations.ChangeTypeAdded}},
+		SQL: `CREATE INDEX CONCURRENTLY "post__title__idx" ON "post" ("title");
+ALTER TABLE "post" ADD COLUMN "subtitle" TEXT;`,
+	})
+	require.NoError(t, err)
+
+	// The concurrent index is kept apart at the end, as it is built after the rest of the migration is committed
+	require.Equal(t, `-- Post.subtitle added
+ALTER TABLE "post" ADD COLUMN "subtitle" TEXT;
+
+-- Run one at a time once the rest of the migration has been committed, so that writes are not blocked
+CREATE INDEX CONCURRENTLY "post__title__idx" ON "post" ("title");
+`, f.Up)
+}
+
+func TestUpConcurrentIndex(t *testing.T) {
+	dbConnInfo := &db.ConnectionInfo{
+		Host:     "localhost",
+		Port:     "8001",
+		Username: "postgres",
+		Password: "postgres",
+		Database: "keel",
+	}
+
+	mainDB, err := sql.Open("pgx/v5", dbConnInfo.String())
+	require.NoError(t, err)
+	defer mainDB.Close()
+
+	dbName := "testupconcurrentindex"
+	_, err = mainDB.Exec("DROP DATABASE if exists " + dbName)
+	require.NoError(t, err)
+	_, err = mainDB.Exec("CREATE DATABASE " + dbName)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+
+	database, err := db.New(ctx, dbConnInfo.WithDatabase(dbName).String())
+	require.NoError(t, err)
+	defer database.Close()
+
+	files := []*migrations.MigrationFile{
+		{
+			Version: 1,
+			Name:    "add_posts",
+			Up:      `CREATE TABLE "post" ("id" TEXT NOT NULL PRIMARY KEY, "title" TEXT NOT NULL);`,
+		},
+		{
+			Version: 2,
+			Name:    "index_titles",
+			Up: `ALTER TABLE "post" ADD COLUMN "subtitle" TEXT;
+CREATE INDEX CONCURRENTLY "post__title__idx" ON "post" ("title");`,
+		},
+	}
+
+	// Building an index concurrently errors if it is run in a transaction
+	applied, err := migrations.Up(ctx, historyTestProto(t), database, files)
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+
+	result, err := database.ExecuteQuery(ctx, `SELECT i.indisvalid FROM pg_index i JOIN pg_class c ON c.oid = i.indexrelid WHERE c.relname = 'post__title__idx'`)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	require.Equal(t, true, result.Rows[0]["indisvalid"])
+
+	statuses, err := migrations.GetMigrationStatus(ctx, database, files)
+	require.NoError(t, err)
+	for _, s := range statuses {
+		require.False(t, s.Pending())
+	}
+}
+
+func TestUpAndApply(t *testing.T) {
+	dbConnInfo := &db.ConnectionInfo{
+		Host:     "localhost",
+		Port:     "8001",
+		Username: "postgres",
+		Password: "postgres",
+		Database: "keel",
+	}
+
+	mainDB, err := sql.Open("pgx/v5", dbConnInfo.String())
+	require.NoError(t, err)
+	defer mainDB.Close()
+
+	dbName := "testupandapply"
+	_, err = mainDB.Exec("DROP DATABASE if exists " + dbName)
+	require.NoError(t, err)
+	_, err = mainDB.Exec("CREATE DATABASE " + dbName)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+
+	database, err := db.New(ctx, dbConnInfo.WithDatabase(dbName).String())
+	require.NoError(t, err)
+	defer database.Close()
+
+	s := historyTestProto(t)
+	dir := t.TempDir()
+
+	m, err := migrations.New(ctx, s, database)
+	require.NoError(t, err)
+	_, err = migrations.WriteMigrationFile(dir, "init", m)
+	require.NoError(t, err)
+
+	files, err := migrations.ReadMigrationFiles(dir)
+	require.NoError(t, err)
+
+	applied, _, err := migrations.UpAndApply(ctx, s, database, files)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+
+	pending, err := migrations.PendingMigrations(ctx, database, files)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	// A model added to the schema without a migration file is not applied
+	builder := &schema.Builder{}
+	changed, err := builder.MakeFromString(historyTestSchema+`
+model Comment {
+	fields {
+		body Text
+	}
+}`, config.Empty)
+	require.NoError(t, err)
+
+	applied, _, err = migrations.UpAndApply(ctx, changed, database, files)
+	require.ErrorIs(t, err, migrations.ErrUnmigratedChanges)
+	require.Empty(t, applied)
+
+	result, err := database.ExecuteQuery(ctx, "SELECT to_regclass('comment') AS name")
+	require.NoError(t, err)
+	require.Nil(t, result.Rows[0]["name"])
+}
@@ -82,6 +82,10 @@ type ConstraintRow struct {
 	// n = set null
 	// d = set default
 	OnDelete string
+
+	// True for the unique index of a model with @softDelete, which only
+	// applies to rows which have not been soft deleted
+	Partial bool
 }
 
 type TriggerRow struct {
@@ -112,4 +116,6 @@ type IndexRow struct {
 	IsUnique bool `json:"is_unique"`
 	// e.g. false
 	IsPrimary bool `json:"is_primary"`
+	// e.g. true, or false if building the index concurrently failed
+	IsValid bool `json:"is_valid"`
 }
@@ -28,6 +28,7 @@ const (
 	ChangeTypeAdded    = "ADDED"
 	ChangeTypeRemoved  = "REMOVED"
 	ChangeTypeModified = "MODIFIED"
+	ChangeTypeRenamed  = "RENAMED"
 )
 
 var ErrNoStoredSchema = errors.New("no schema stored in keel_schema table")
@@ -65,6 +66,13 @@ type DatabaseChange struct {
 
 	// The type of change
 	Type string
+
+	// The previous name of the model or field if it has been renamed
+	RenamedFrom string `json:",omitempty"`
+
+	// How safe the change is to apply to a database with existing data, and why
+	Safety string `json:"-"`
+	Reason string `json:"-"`
 }
 
 func (c DatabaseChange) String() string {
@@ -82,6 +90,10 @@ type Migrations struct {
 
 	// The SQL to run to execute the database schema changes
 	SQL string
+
+	// Any @renamedFrom attributes which have already been applied, or refer to a
+	// table or column which doesn't exist, and so should be removed from the schema
+	StaleRenames []string
 }
 
 // HasEntityFieldChanges returns true if the migrations contain entity field changes to be applied.
@@ -132,14 +144,52 @@ func (m *Migrations) Apply(ctx context.Context, dryRun bool) error {
 	sql.WriteString("\n")
 
 	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_refresh_token (token TEXT NOT NULL PRIMARY KEY, identity_id TEXT NOT NULL, created_at TIMESTAMP, expires_at TIMESTAMP);\n")
+	sql.WriteString("ALTER TABLE keel_refresh_token ADD COLUMN IF NOT EXISTS session_id TEXT;\n")
+	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_refresh_token_session_id ON keel_refresh_token (session_id);\n")
+	sql.WriteString("\n")
+
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_session (id TEXT NOT NULL PRIMARY KEY, identity_id TEXT NOT NULL, user_agent TEXT, ip_address TEXT, created_at TIMESTAMPTZ NOT NULL, last_used_at TIMESTAMPTZ NOT NULL, revoked_at TIMESTAMPTZ);\n")
+	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_session_identity_id ON keel_session (identity_id);\n")
+	sql.WriteString("\n")
+
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_lockout (action TEXT NOT NULL, kind TEXT NOT NULL, subject TEXT NOT NULL, failures INTEGER NOT NULL, locked_until TIMESTAMPTZ, updated_at TIMESTAMPTZ NOT NULL, PRIMARY KEY (action, kind, subject));\n")
 	sql.WriteString("\n")
 
 	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_auth_code (code TEXT NOT NULL PRIMARY KEY, identity_id TEXT NOT NULL, created_at TIMESTAMP, expires_at TIMESTAMP);\n")
 	sql.WriteString("\n")
 
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_passwordless_code (email TEXT NOT NULL PRIMARY KEY, code TEXT NOT NULL, attempts INTEGER NOT NULL, created_at TIMESTAMPTZ NOT NULL, expires_at TIMESTAMPTZ NOT NULL);\n")
+	sql.WriteString("\n")
+
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_mfa (identity_id TEXT NOT NULL PRIMARY KEY, secret TEXT NOT NULL, enabled BOOLEAN NOT NULL, last_used_step BIGINT NOT NULL, created_at TIMESTAMPTZ NOT NULL);\n")
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_mfa_recovery_code (identity_id TEXT NOT NULL, code TEXT NOT NULL, PRIMARY KEY (identity_id, code));\n")
+	sql.WriteString("\n")
+
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_idempotency_key (key TEXT NOT NULL PRIMARY KEY, request_hash TEXT NOT NULL, response_status INTEGER, response_headers TEXT, response_body TEXT, created_at TIMESTAMP NOT NULL);\n")
+	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_idempotency_key_created_at ON keel_idempotency_key (created_at);\n")
+	sql.WriteString("\n")
+
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_rate_limit (key TEXT NOT NULL PRIMARY KEY, capacity DOUBLE PRECISION NOT NULL, rate DOUBLE PRECISION NOT NULL, tokens DOUBLE PRECISION NOT NULL, allowed BOOLEAN NOT NULL, updated_at TIMESTAMPTZ NOT NULL);\n")
+	sql.WriteString("\n")
+
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_service_account (id TEXT NOT NULL PRIMARY KEY, name TEXT NOT NULL UNIQUE, client_id TEXT NOT NULL UNIQUE, secret_hash TEXT, scopes TEXT NOT NULL, created_at TIMESTAMPTZ NOT NULL, last_used_at TIMESTAMPTZ, revoked_at TIMESTAMPTZ);\n")
+	sql.WriteString("\n")
+
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_subscription_connection (id TEXT NOT NULL PRIMARY KEY, api TEXT NOT NULL, identity_id TEXT, service_account TEXT, initialised_at TIMESTAMPTZ, created_at TIMESTAMPTZ NOT NULL);\n")
+	sql.WriteString("CREATE TABLE IF NOT EXISTS keel_subscription (connection_id TEXT NOT NULL, id TEXT NOT NULL, event_name TEXT NOT NULL, query TEXT NOT NULL, variables TEXT, operation_name TEXT, created_at TIMESTAMPTZ NOT NULL, PRIMARY KEY (connection_id, id));\n")
+	sql.WriteString("CREATE INDEX IF NOT EXISTS idx_keel_subscription_event_name ON keel_subscription (event_name);\n")
+	sql.WriteString("\n")
+
 	sql.WriteString(fmt.Sprintf("SELECT set_trace_id('%s');\n", span.SpanContext().TraceID().String()))
 
-	sql.WriteString(m.SQL)
+	// Concurrent index statements can't be run in a transaction, so unless this is a dry run they are
+	// run one at a time once the rest of the migration has been applied
+	migrationSQL, concurrentStmts := splitConcurrentStmts(m.SQL)
+	if dryRun {
+		migrationSQL, concurrentStmts = withoutConcurrently(m.SQL), nil
+	}
+
+	sql.WriteString(migrationSQL)
 	sql.WriteString("\n")
 
 	// For now, we do this here but this could belong in our proto once we start on the database indexing work.
@@ -177,6 +227,13 @@ func (m *Migrations) Apply(ctx context.Context, dryRun bool) error {
 		return err
 	}
 
+	for _, stmt := range concurrentStmts {
+		_, err = m.database.ExecuteStatement(ctx, stmt)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -202,8 +259,28 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 		return nil, err
 	}
 
-	statements := []string{}
-	changes := []*DatabaseChange{}
+	existingIndexes, err := getIndexes(database)
+	if err != nil {
+		return nil, err
+	}
+
+	existingComputedFns, err := getComputedFunctions(database)
+	if err != nil {
+		return nil, err
+	}
+
+	// Renames are done first, after which the database is treated as though the
+	// models and fields have always had their new names
+	state := &databaseState{
+		columns:     columns,
+		constraints: constraints,
+		triggers:    existingTriggers,
+		indexes:     existingIndexes,
+		computedFns: existingComputedFns,
+	}
+	statements, changes, staleRenames := renameStmts(schema, state)
+	existingTriggers = state.triggers
+	existingComputedFns = state.computedFns
 	entitiesAdded := []proto.Entity{}
 	existingEntities := []proto.Entity{}
 
@@ -292,6 +369,7 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 			return c.TableName == tableName
 		})
 
+		partialUniqueStmts := []string{}
 		for _, field := range entity.GetFields() {
 			if field.GetType().GetType() == proto.Type_TYPE_ENTITY {
 				continue
@@ -313,6 +391,14 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 					Type:  ChangeTypeAdded,
 				})
 
+				if field.GetUnique() && !field.GetPrimaryKey() && isSoftDeleteEntity(schema, entity.GetName()) {
+					uniqueStmt, err := addUniqueConstraintStmt(schema, entity.GetName(), []string{field.GetName()})
+					if err != nil {
+						return nil, err
+					}
+					partialUniqueStmts = append(partialUniqueStmts, uniqueStmt)
+				}
+
 				// When the field added is a foreign key field, we add a corresponding foreign key constraint.
 				if field.GetForeignKeyInfo() != nil {
 					statements = append(statements, fkConstraint(field, entity))
@@ -322,42 +408,93 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 
 			// Column already exists - see if any changes need to be applied
 			hasChanged := false
+			safety, reason := "", ""
 
 			alterSQL, err := alterColumnStmt(entity.GetName(), field, column)
 			if err != nil {
 				return nil, err
 			}
 			if alterSQL != "" {
+				// Indexes are rebuilt when the type of a column changes, but a vector index cannot be built on a
+				// vector without dimensions, so any index on the column is dropped first and then recreated
+				if strings.Contains(alterSQL, " TYPE ") {
+					for _, index := range existingIndexes {
+						if index.TableName == tableName && index.ColumnName == column.ColumnName && !index.IsPrimary && !index.IsUnique {
+							statements = append(statements, fmt.Sprintf("DROP INDEX IF EXISTS \"%s\";", index.IndexName))
+						}
+					}
+					existingIndexes = lo.Reject(existingIndexes, func(index *IndexRow, _ int) bool {
+						return index.TableName == tableName && index.ColumnName == column.ColumnName && !index.IsPrimary && !index.IsUnique
+					})
+				}
+
 				statements = append(statements, alterSQL)
 				hasChanged = true
+
+				if strings.Contains(alterSQL, "SET NOT NULL") {
+					safety, reason = SafetyLocking, "checks every existing row is not null"
+				} else if strings.Contains(alterSQL, " TYPE ") {
+					safety, reason = SafetyLocking, "checks every existing vector has the indexed number of dimensions"
+				}
 			}
 
 			uniqueConstraint, hasUniqueConstraint := lo.Find(constraints, func(c *ConstraintRow) bool {
 				return c.TableName == tableName && c.ConstraintType == "u" && len(c.ConstrainedColumns) == 1 && c.ConstrainedColumns[0] == int64(column.ColumnNum)
 			})
 
+			// The unique constraint is recreated when @softDelete is added or removed, as it then does or doesn't apply to deleted rows
+			if hasUniqueConstraint && (!field.GetUnique() || uniqueConstraint.Partial != isSoftDeleteEntity(schema, entity.GetName())) {
+				statements = append(statements, dropUniqueConstraintStmt(uniqueConstraint))
+				hasChanged = true
+				hasUniqueConstraint = false
+			}
 			if field.GetUnique() && !field.GetPrimaryKey() && !hasUniqueConstraint {
 				uniqueStmt, err := addUniqueConstraintStmt(schema, entity.GetName(), []string{field.GetName()})
 				if err != nil {
 					return nil, err
 				}
 
-				statements = append(statements, uniqueStmt)
+				// The partial unique index of a model with @softDelete references the deleted_at column, which may only
+				// be added later in this migration
+				if isSoftDeleteEntity(schema, entity.GetName()) {
+					partialUniqueStmts = append(partialUniqueStmts, uniqueStmt)
+				} else {
+					statements = append(statements, uniqueStmt)
+				}
 				hasChanged = true
+				safety, reason = SafetyLocking, "builds a unique index over every existing row"
 			}
-			if !field.GetUnique() && hasUniqueConstraint {
-				statements = append(statements, dropConstraintStmt(uniqueConstraint.TableName, uniqueConstraint.ConstraintName))
-				hasChanged = true
+
+			// Recreate the foreign key constraint when its referential action has changed
+			if field.GetForeignKeyInfo() != nil {
+				fk, hasFk := lo.Find(constraints, func(c *ConstraintRow) bool {
+					return c.TableName == tableName && c.ConstraintType == "f" && len(c.ConstrainedColumns) == 1 && c.ConstrainedColumns[0] == int64(column.ColumnNum)
+				})
+				if hasFk && onDeleteChanged(field, fk) {
+					statements = append(statements, dropConstraintStmt(fk.TableName, fk.ConstraintName))
+					statements = append(statements, addNotValidForeignKeyConstraintStmts(
+						Identifier(entity.GetName()),
+						fk.ConstraintName,
+						Identifier(field.GetName()),
+						Identifier(field.GetForeignKeyInfo().GetRelatedEntityName()),
+						Identifier(field.GetForeignKeyInfo().GetRelatedEntityField()),
+						onDeleteAction(field))...)
+					hasChanged = true
+					safety, reason = SafetyLocking, "validates every existing row references an existing record"
+				}
 			}
 
 			if hasChanged {
 				changes = append(changes, &DatabaseChange{
-					Model: entity.GetName(),
-					Field: field.GetName(),
-					Type:  ChangeTypeModified,
+					Model:  entity.GetName(),
+					Field:  field.GetName(),
+					Type:   ChangeTypeModified,
+					Safety: safety,
+					Reason: reason,
 				})
 			}
 		}
+		statements = append(statements, partialUniqueStmts...)
 
 		// Drop columns if fields removed from models or tasks
 		for _, column := range tableColumns {
@@ -398,26 +535,55 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 
 		if len(stmts) > 0 {
 			statements = append(statements, stmts...)
-			changes = append(changes, &DatabaseChange{
+			change := &DatabaseChange{
 				Model: entity.GetName(),
 				Type:  ChangeTypeModified,
-			})
+			}
+			if lo.ContainsBy(stmts, func(stmt string) bool {
+				return strings.Contains(stmt, "ADD CONSTRAINT") || strings.Contains(stmt, "CREATE UNIQUE INDEX")
+			}) {
+				change.Safety, change.Reason = SafetyLocking, "builds a unique index over every existing row"
+			}
+			changes = append(changes, change)
 		}
 	}
 
 	// Amend indexes for fields which are used as required action inputs or as facets
-	existingIndexes, err := getIndexes(database)
-	if err != nil {
-		return nil, err
-	}
 	indexStmts := createIndexStmts(schema, existingIndexes)
 	statements = append(statements, indexStmts...)
 
-	// Fetch all computed functions in the database
-	existingComputedFns, err := getComputedFunctions(database)
+	// Indexes defined with @index
+	modelIndexes, err := modelIndexStmts(schema, columns, existingIndexes)
 	if err != nil {
 		return nil, err
 	}
+	statements = append(statements, modelIndexes...)
+
+	// Full-text search columns and indexes for models with @searchable
+	for _, model := range schema.GetModels() {
+		stmts := searchStmts(model, columns, existingIndexes)
+		if len(stmts) == 0 {
+			continue
+		}
+
+		statements = append(statements, stmts...)
+
+		// Dont add the db change if the model is new or was already modified elsewhere
+		if lo.ContainsBy(changes, func(c *DatabaseChange) bool {
+			return c.Model == model.GetName() && c.Field == ""
+		}) {
+			continue
+		}
+
+		change := &DatabaseChange{
+			Model: model.GetName(),
+			Type:  ChangeTypeModified,
+		}
+		if model.IsSearchable() {
+			change.Safety, change.Reason = SafetyLocking, "computes the search column for every existing row"
+		}
+		changes = append(changes, change)
+	}
 
 	// Computed fields functions and triggers
 	computedChanges, stmts, err := computedFieldsStmts(ctx, schema, existingComputedFns)
@@ -485,14 +651,17 @@ func New(ctx context.Context, schema *proto.Schema, database db.Database) (*Migr
 		}
 	}
 
+	classifyChanges(schema, changes)
+
 	stringChanges := lo.Map(changes, func(c *DatabaseChange, _ int) string { return c.String() })
 	span.SetAttributes(attribute.StringSlice("migration", stringChanges))
 
 	return &Migrations{
-		database: database,
-		Schema:   schema,
-		Changes:  changes,
-		SQL:      strings.TrimSpace(strings.Join(statements, "\n")),
+		database:     database,
+		Schema:       schema,
+		Changes:      changes,
+		SQL:          strings.TrimSpace(strings.Join(statements, "\n")),
+		StaleRenames: staleRenames,
 	}, nil
 }
 
@@ -521,13 +690,12 @@ func compositeUniqueConstraints(schema *proto.Schema, entity proto.Entity, const
 			continue
 		}
 
-		if _, ok := uniqueConstraints[c.ConstraintName]; ok {
+		if _, ok := uniqueConstraints[c.ConstraintName]; ok && c.Partial == isSoftDeleteEntity(schema, entity.GetName()) {
 			delete(uniqueConstraints, c.ConstraintName)
 			continue
 		}
 
-		stmt := dropConstraintStmt(c.TableName, c.ConstraintName)
-		statements = append(statements, stmt)
+		statements = append(statements, dropUniqueConstraintStmt(c))
 	}
 
 	for _, fieldNames := range uniqueConstraints {
@@ -924,13 +1092,47 @@ func fkConstraintsForEntity(entity proto.Entity) (fkStatements []string) {
 // fkConstraint generates a foreign key constraint statement for the given foreign key field.
 func fkConstraint(field *proto.Field, thisEntity proto.Entity) (fkStatement string) {
 	fki := field.GetForeignKeyInfo()
-	onDelete := lo.Ternary(field.GetOptional(), "SET NULL", "CASCADE")
 	stmt := addForeignKeyConstraintStmt(
 		Identifier(thisEntity.GetName()),
 		Identifier(field.GetName()),
 		Identifier(fki.GetRelatedEntityName()),
 		Identifier(fki.GetRelatedEntityField()),
-		onDelete,
+		onDeleteAction(field),
 	)
 	return stmt
 }
+
+// onDeleteAction returns the referential action of a foreign key field. Unless defined with
+// @relation(onDelete: ...), optional relationships are set to null and required ones are cascaded.
+func onDeleteAction(field *proto.Field) string {
+	switch field.GetForeignKeyInfo().GetOnDelete() {
+	case proto.OnDelete_ON_DELETE_CASCADE:
+		return "CASCADE"
+	case proto.OnDelete_ON_DELETE_SET_NULL:
+		return "SET NULL"
+	case proto.OnDelete_ON_DELETE_RESTRICT:
+		return "RESTRICT"
+	case proto.OnDelete_ON_DELETE_NO_ACTION:
+		return "NO ACTION"
+	default:
+		return lo.Ternary(field.GetOptional(), "SET NULL", "CASCADE")
+	}
+}
+
+// onDeleteChanged determines if the referential action of an existing foreign key constraint differs from
+// that of the field. Constraints created before the action could be defined are left as they are, unless
+// one has since been defined.
+func onDeleteChanged(field *proto.Field, constraint *ConstraintRow) bool {
+	codes := map[string]string{
+		"NO ACTION": "a",
+		"RESTRICT":  "r",
+		"CASCADE":   "c",
+		"SET NULL":  "n",
+	}
+
+	if field.GetForeignKeyInfo().GetOnDelete() == proto.OnDelete_ON_DELETE_UNKNOWN {
+		return constraint.OnDelete != codes["CASCADE"] && constraint.OnDelete != codes["SET NULL"]
+	}
+
+	return constraint.OnDelete != codes[onDeleteAction(field)]
+}
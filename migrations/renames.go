@@ -0,0 +1,189 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+)
+
+// databaseState is the introspected state of the database which migrations are generated from.
+type databaseState struct {
+	columns     []*ColumnRow
+	constraints []*ConstraintRow
+	triggers    []*TriggerRow
+	indexes     []*IndexRow
+	computedFns []*FunctionRow
+}
+
+func (s *databaseState) hasTable(table string) bool {
+	return lo.ContainsBy(s.columns, func(c *ColumnRow) bool {
+		return c.TableName == table
+	})
+}
+
+func (s *databaseState) findColumn(table string, column string) *ColumnRow {
+	c, _ := lo.Find(s.columns, func(c *ColumnRow) bool {
+		return c.TableName == table && c.ColumnName == column
+	})
+	return c
+}
+
+// renameStmts generates the statements to rename the tables and columns of models and fields which
+// have been marked with @renamedFrom. The database state is updated to reflect the renames so that
+// the rest of the migration is generated as though they had already happened.
+//
+// Audit and updated at triggers are named after their table, so for renamed tables they are dropped
+// and then recreated with the rest of the migration. Computed field functions refer to tables and columns
+// by name, so if anything is renamed they are all dropped and recreated.
+//
+// Renames which have already been applied to the database, or for which the previous table or column
+// does not exist, are returned as stale so that the user can be told to remove the attribute.
+func renameStmts(schema *proto.Schema, state *databaseState) (statements []string, changes []*DatabaseChange, stale []string) {
+	for _, model := range schema.GetModels() {
+		if model.GetRenamedFrom() == nil {
+			continue
+		}
+
+		previous := model.GetRenamedFrom().GetValue()
+		newTable := casing.ToSnake(model.GetName())
+		oldTable := casing.ToSnake(previous)
+
+		if state.hasTable(newTable) || !state.hasTable(oldTable) {
+			stale = append(stale, fmt.Sprintf("%s @renamedFrom(\"%s\")", model.GetName(), previous))
+			continue
+		}
+
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", Identifier(previous), Identifier(model.GetName())))
+
+		for _, c := range state.columns {
+			if c.TableName == oldTable {
+				c.TableName = newTable
+			}
+		}
+
+		for _, c := range state.constraints {
+			if c.OnTable != nil && *c.OnTable == oldTable {
+				c.OnTable = &newTable
+			}
+
+			if c.TableName != oldTable {
+				continue
+			}
+
+			c.TableName = newTable
+			if name, ok := strings.CutPrefix(c.ConstraintName, oldTable+"_"); ok {
+				statements = append(statements, renameConstraintStmt(model.GetName(), c, newTable+"_"+name))
+				c.ConstraintName = newTable + "_" + name
+			}
+		}
+
+		for _, i := range state.indexes {
+			if i.TableName != oldTable {
+				continue
+			}
+
+			i.TableName = newTable
+			if name, ok := strings.CutPrefix(i.IndexName, oldTable+"__"); ok {
+				statements = append(statements, fmt.Sprintf("ALTER INDEX %s RENAME TO %s;", db.QuoteIdentifier(i.IndexName), db.QuoteIdentifier(newTable+"__"+name)))
+				i.IndexName = newTable + "__" + name
+			}
+		}
+
+		state.triggers = lo.Filter(state.triggers, func(t *TriggerRow, _ int) bool {
+			if t.TableName != oldTable {
+				return true
+			}
+
+			// A trigger with more than one event has a row for each, but only needs dropping once
+			stmt := fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s;", db.QuoteIdentifier(t.TriggerName), Identifier(model.GetName()))
+			if !lo.Contains(statements, stmt) {
+				statements = append(statements, stmt)
+			}
+			return false
+		})
+
+		changes = append(changes, &DatabaseChange{
+			Model:       model.GetName(),
+			Type:        ChangeTypeRenamed,
+			RenamedFrom: previous,
+		})
+	}
+
+	for _, entity := range schema.Entities() {
+		table := casing.ToSnake(entity.GetName())
+
+		for _, field := range entity.GetFields() {
+			// Relationship fields have no column, it's their foreign key field which is renamed
+			if field.GetRenamedFrom() == nil || field.GetType().GetType() == proto.Type_TYPE_ENTITY {
+				continue
+			}
+
+			previous := field.GetRenamedFrom().GetValue()
+			column := state.findColumn(table, casing.ToSnake(previous))
+
+			if column == nil || state.findColumn(table, casing.ToSnake(field.GetName())) != nil {
+				stale = append(stale, fmt.Sprintf("%s.%s @renamedFrom(\"%s\")", entity.GetName(), field.GetName(), previous))
+				continue
+			}
+
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", Identifier(entity.GetName()), Identifier(previous), Identifier(field.GetName())))
+			column.ColumnName = casing.ToSnake(field.GetName())
+
+			if field.GetSequence() != nil {
+				sequence := state.findColumn(table, casing.ToSnake(previous)+sequenceSuffix)
+				if sequence != nil {
+					statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", Identifier(entity.GetName()), db.QuoteIdentifier(sequence.ColumnName), sequenceColumnName(field)))
+					sequence.ColumnName = casing.ToSnake(field.GetName()) + sequenceSuffix
+				}
+			}
+
+			oldUnique := UniqueConstraintName(entity.GetName(), []string{previous})
+			for _, c := range state.constraints {
+				if c.TableName == table && c.ConstraintName == oldUnique {
+					newUnique := UniqueConstraintName(entity.GetName(), []string{field.GetName()})
+					statements = append(statements, renameConstraintStmt(entity.GetName(), c, newUnique))
+					c.ConstraintName = newUnique
+				}
+			}
+
+			oldIndex := indexName(entity.GetName(), previous)
+			for _, i := range state.indexes {
+				if i.TableName == table && i.IndexName == oldIndex {
+					newIndex := indexName(entity.GetName(), field.GetName())
+					statements = append(statements, fmt.Sprintf("ALTER INDEX %s RENAME TO %s;", db.QuoteIdentifier(oldIndex), db.QuoteIdentifier(newIndex)))
+					i.IndexName = newIndex
+					i.ColumnName = casing.ToSnake(field.GetName())
+				}
+			}
+
+			changes = append(changes, &DatabaseChange{
+				Model:       entity.GetName(),
+				Field:       field.GetName(),
+				Type:        ChangeTypeRenamed,
+				RenamedFrom: previous,
+			})
+		}
+	}
+
+	if len(changes) > 0 {
+		for _, fn := range state.computedFns {
+			statements = append(statements, fmt.Sprintf("DROP FUNCTION IF EXISTS %s CASCADE;", db.QuoteIdentifier(fn.RoutineName)))
+		}
+		state.computedFns = []*FunctionRow{}
+	}
+
+	return statements, changes, stale
+}
+
+// renameConstraintStmt renames a constraint, or the partial unique index of a model with @softDelete.
+func renameConstraintStmt(entityName string, c *ConstraintRow, newName string) string {
+	if c.Partial {
+		return fmt.Sprintf("ALTER INDEX %s RENAME TO %s;", db.QuoteIdentifier(c.ConstraintName), db.QuoteIdentifier(newName))
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s RENAME CONSTRAINT %s TO %s;", Identifier(entityName), db.QuoteIdentifier(c.ConstraintName), db.QuoteIdentifier(newName))
+}
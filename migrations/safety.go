@@ -0,0 +1,113 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/proto"
+)
+
+// The safety of applying a change to a database which already contains data
+const (
+	// SafetySafe changes can be applied without any risk to existing data
+	SafetySafe = "SAFE"
+	// SafetyLocking changes scan or rewrite every row of a table while holding a lock on it,
+	// which can block reads and writes for some time on large tables
+	SafetyLocking = "LOCKING"
+	// SafetyDestructive changes permanently delete data
+	SafetyDestructive = "DESTRUCTIVE"
+)
+
+var ErrDestructiveChanges = errors.New("migrations contain destructive changes which have not been confirmed")
+
+// classifyChanges sets the safety of any changes which have not already been classified
+// where they were generated, based on the type of change and the field it applies to.
+func classifyChanges(schema *proto.Schema, changes []*DatabaseChange) {
+	for _, change := range changes {
+		if change.Safety != "" {
+			continue
+		}
+
+		change.Safety = SafetySafe
+
+		switch change.Type {
+		case ChangeTypeRemoved:
+			change.Safety = SafetyDestructive
+			if change.Field == "" {
+				change.Reason = "drops the table and all of its rows"
+			} else {
+				change.Reason = "drops the column and all of its values"
+			}
+		case ChangeTypeAdded, ChangeTypeModified:
+			if change.Field == "" {
+				continue
+			}
+
+			entity := schema.FindEntity(change.Model)
+			if entity == nil {
+				continue
+			}
+
+			field := entity.FindField(change.Field)
+			if field == nil {
+				continue
+			}
+
+			// New tables are empty, so only fields on existing tables need their values populating
+			tableAdded := lo.ContainsBy(changes, func(c *DatabaseChange) bool {
+				return c.Model == change.Model && c.Field == "" && c.Type == ChangeTypeAdded
+			})
+			if tableAdded {
+				continue
+			}
+
+			switch {
+			case field.GetComputedExpression() != nil:
+				change.Safety = SafetyLocking
+				change.Reason = "computes the value for every existing row"
+			case change.Type == ChangeTypeAdded && field.GetSequence() != nil:
+				change.Safety = SafetyLocking
+				change.Reason = "generates a value for every existing row"
+			}
+		}
+	}
+}
+
+// HasDestructiveChanges returns true if applying the migrations would permanently delete data.
+func (m *Migrations) HasDestructiveChanges() bool {
+	return lo.ContainsBy(m.Changes, func(c *DatabaseChange) bool {
+		return c.Safety == SafetyDestructive
+	})
+}
+
+// EstimateRows returns an estimate of the number of rows affected by the change, which is the number
+// of rows in its table. The planner's statistics are used so that large tables don't need to be scanned,
+// falling back to counting the rows if the table has never been analysed.
+func (m *Migrations) EstimateRows(ctx context.Context, change *DatabaseChange) (int64, error) {
+	table := casing.ToSnake(change.Model)
+
+	rows, err := m.database.ExecuteQuery(ctx, "SELECT reltuples::bigint AS estimate FROM pg_class WHERE oid = to_regclass($1)", fmt.Sprintf("public.%s", Identifier(change.Model)))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(rows.Rows) == 0 {
+		return 0, nil
+	}
+
+	estimate, ok := rows.Rows[0]["estimate"].(int64)
+	if ok && estimate >= 0 {
+		return estimate, nil
+	}
+
+	rows, err = m.database.ExecuteQuery(ctx, fmt.Sprintf("SELECT count(*) AS count FROM %s", Identifier(change.Model)))
+	if err != nil {
+		return 0, fmt.Errorf("counting rows in %s: %w", table, err)
+	}
+
+	count, _ := rows.Rows[0]["count"].(int64)
+	return count, nil
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/schema"
+)
+
+func TestClassifyChanges(t *testing.T) {
+	builder := &schema.Builder{}
+	s, err := builder.MakeFromString(`
+		model Post {
+			fields {
+				title Text
+				number Text @sequence("P")
+				wordCount Number @computed(1)
+			}
+		}
+		model Author {
+			fields {
+				name Text
+				number Text @sequence("A")
+			}
+		}`, config.Empty)
+	require.NoError(t, err)
+
+	changes := []*DatabaseChange{
+		{Model: "Author", Type: ChangeTypeAdded},
+		{Model: "Author", Field: "number", Type: ChangeTypeAdded},
+		{Model: "Post", Field: "title", Type: ChangeTypeAdded},
+		{Model: "Post", Field: "number", Type: ChangeTypeAdded},
+		{Model: "Post", Field: "wordCount", Type: ChangeTypeModified},
+		{Model: "Post", Field: "title", Type: ChangeTypeModified, Safety: SafetyLocking, Reason: "checks every existing row is not null"},
+		{Model: "Post", Field: "body", Type: ChangeTypeRemoved},
+		{Model: "Comment", Type: ChangeTypeRemoved},
+		{Model: "Post", Field: "title", Type: ChangeTypeRenamed, RenamedFrom: "heading"},
+	}
+
+	classifyChanges(s, changes)
+
+	expected := []string{
+		SafetySafe,
+		SafetySafe,
+		SafetySafe,
+		SafetyLocking,
+		SafetyLocking,
+		SafetyLocking,
+		SafetyDestructive,
+		SafetyDestructive,
+		SafetySafe,
+	}
+	for i, change := range changes {
+		require.Equal(t, expected[i], change.Safety, change.String())
+	}
+
+	m := &Migrations{Schema: s, Changes: changes}
+	require.True(t, m.HasDestructiveChanges())
+
+	m.Changes = changes[:6]
+	require.False(t, m.HasDestructiveChanges())
+}
@@ -37,6 +37,18 @@ var PostgresFieldTypes map[proto.Type]string = map[proto.Type]string{
 	proto.Type_TYPE_DURATION:  "INTERVAL",
 }
 
+var vectorIndexMethods = map[proto.VectorIndexMethod]string{
+	proto.VectorIndexMethod_VECTOR_INDEX_METHOD_HNSW:    "hnsw",
+	proto.VectorIndexMethod_VECTOR_INDEX_METHOD_IVFFLAT: "ivfflat",
+}
+
+// The pgvector operator class of an index determines which distance metric it can be used for.
+var vectorOperatorClasses = map[proto.VectorDistanceMetric]string{
+	proto.VectorDistanceMetric_VECTOR_DISTANCE_METRIC_COSINE:        "vector_cosine_ops",
+	proto.VectorDistanceMetric_VECTOR_DISTANCE_METRIC_L2:            "vector_l2_ops",
+	proto.VectorDistanceMetric_VECTOR_DISTANCE_METRIC_INNER_PRODUCT: "vector_ip_ops",
+}
+
 // Matches the type cast on a Postgrs value eg. on "'foo'::text" matches "::text".
 var typeCastRegex = regexp.MustCompile(`::([\w\s]+)(?:\[\])?$`)
 
@@ -144,6 +156,16 @@ func addUniqueConstraintStmt(schema *proto.Schema, entityName string, fieldNames
 		columnNames = append(columnNames, Identifier(name))
 	}
 
+	// Soft deleted rows are kept in the table, so they must not stop the same values being used again
+	if isSoftDeleteEntity(schema, entityName) {
+		return fmt.Sprintf(
+			"CREATE UNIQUE INDEX %s ON %s (%s) WHERE %s IS NULL;",
+			UniqueConstraintName(entityName, fieldNames),
+			Identifier(entityName),
+			strings.Join(columnNames, ", "),
+			Identifier(parser.FieldNameDeletedAt)), nil
+	}
+
 	return fmt.Sprintf(
 		"ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);",
 		Identifier(entityName),
@@ -151,10 +173,25 @@ func addUniqueConstraintStmt(schema *proto.Schema, entityName string, fieldNames
 		strings.Join(columnNames, ", ")), nil
 }
 
+// isSoftDeleteEntity returns true if the entity is a model with @softDelete.
+func isSoftDeleteEntity(schema *proto.Schema, entityName string) bool {
+	model, ok := schema.FindEntity(entityName).(*proto.Model)
+	return ok && model.GetSoftDelete()
+}
+
 func dropConstraintStmt(tableName string, constraintName string) string {
 	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", Identifier(tableName), constraintName)
 }
 
+// dropUniqueConstraintStmt drops a unique constraint, or the partial unique index of a model with @softDelete.
+func dropUniqueConstraintStmt(c *ConstraintRow) string {
+	if c.Partial {
+		return fmt.Sprintf("DROP INDEX IF EXISTS %s;", db.QuoteIdentifier(c.ConstraintName))
+	}
+
+	return dropConstraintStmt(c.TableName, c.ConstraintName)
+}
+
 func addColumnStmt(schema *proto.Schema, entityName string, field *proto.Field) (string, error) {
 	statements := []string{}
 
@@ -173,7 +210,8 @@ func addColumnStmt(schema *proto.Schema, entityName string, field *proto.Field)
 		fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", Identifier(entityName), stmt),
 	)
 
-	if field.GetUnique() && !field.GetPrimaryKey() {
+	// The partial unique index of a model with @softDelete is added by the caller once the deleted_at column exists
+	if field.GetUnique() && !field.GetPrimaryKey() && !isSoftDeleteEntity(schema, entityName) {
 		stmt, err := addUniqueConstraintStmt(schema, entityName, []string{field.GetName()})
 		if err != nil {
 			return "", err
@@ -198,6 +236,22 @@ func sequenceColumnDefinition(field *proto.Field) string {
 
 // addForeignKeyConstraintStmt generates a string of this form:
 // ALTER TABLE "thisTable" ADD FOREIGN KEY ("thisColumn") REFERENCES "otherTable"("otherColumn") ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE;.
+// addNotValidForeignKeyConstraintStmts adds a foreign key constraint without checking the existing rows while the
+// table is locked, and then validates them separately.
+func addNotValidForeignKeyConstraintStmts(thisTable string, constraintName string, thisColumn string, otherTable string, otherColumn string, onDelete string) []string {
+	return []string{
+		fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(%s) ON DELETE %s DEFERRABLE INITIALLY IMMEDIATE NOT VALID;",
+			thisTable,
+			constraintName,
+			thisColumn,
+			otherTable,
+			otherColumn,
+			onDelete,
+		),
+		fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s;", thisTable, constraintName),
+	}
+}
+
 func addForeignKeyConstraintStmt(thisTable string, thisColumn string, otherTable string, otherColumn string, onDelete string) string {
 	return fmt.Sprintf("ALTER TABLE %s ADD FOREIGN KEY (%s) REFERENCES %s(%s) ON DELETE %s DEFERRABLE INITIALLY IMMEDIATE;",
 		thisTable,
@@ -261,9 +315,27 @@ func alterColumnStmt(entityName string, field *proto.Field, column *ColumnRow) (
 		}
 	}
 
+	// Vector columns with an index have a fixed number of dimensions
+	if field.GetType().GetType() == proto.Type_TYPE_VECTOR && !field.GetType().GetRepeated() {
+		columnType := vectorColumnType(field)
+		if !strings.EqualFold(strings.TrimPrefix(column.DataType, "public."), columnType) {
+			stmts = append(stmts, fmt.Sprintf("%s TYPE %s;", alterColumnStmtPrefix, columnType))
+		}
+	}
+
 	return strings.Join(stmts, "\n"), nil
 }
 
+// vectorColumnType is the column type of a vector field. An index can only be built on vectors with a
+// known number of dimensions, so when the field has an index the dimensions are part of the type.
+func vectorColumnType(field *proto.Field) string {
+	if field.GetVectorIndex() == nil {
+		return PostgresFieldTypes[proto.Type_TYPE_VECTOR]
+	}
+
+	return fmt.Sprintf("%s(%d)", PostgresFieldTypes[proto.Type_TYPE_VECTOR], field.GetVectorIndex().GetDimensions())
+}
+
 func hashOfExpression(expression string) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(expression)))[:8]
 }
@@ -361,6 +433,8 @@ func fieldDefinition(field *proto.Field) (string, error) {
 
 	if field.GetType().GetRepeated() {
 		fieldType = fmt.Sprintf("%s[]", fieldType)
+	} else if field.GetType().GetType() == proto.Type_TYPE_VECTOR {
+		fieldType = vectorColumnType(field)
 	}
 
 	output := fmt.Sprintf("%s %s", columnName, fieldType)
@@ -616,6 +690,11 @@ func createIndexStmts(schema *proto.Schema, existingIndexes []*IndexRow) []strin
 			// Find fields used as required inputs
 			fieldsToIndex := findIndexableInputFields(schema, model, message)
 			for _, field := range fieldsToIndex {
+				// Vector inputs are searched by distance, which only a vector index can be used for
+				if field.GetType().GetType() == proto.Type_TYPE_VECTOR {
+					continue
+				}
+
 				// They could have been added already if used as another action's input
 				if !lo.Contains(indexedFields, field) {
 					indexedFields = append(indexedFields, field)
@@ -653,6 +732,35 @@ func createIndexStmts(schema *proto.Schema, existingIndexes []*IndexRow) []strin
 		statements = append(statements, stmt)
 	}
 
+	// Add vector indexes which don't exist yet
+	vectorIndexes := []string{}
+	for _, model := range schema.GetModels() {
+		for _, field := range model.GetFields() {
+			if field.GetVectorIndex() == nil {
+				continue
+			}
+
+			name := vectorIndexName(field)
+			vectorIndexes = append(vectorIndexes, name)
+
+			if lo.ContainsBy(existingIndexes, func(i *IndexRow) bool {
+				return name == i.IndexName
+			}) {
+				continue
+			}
+
+			// An ivfflat index is clustered from the rows which exist when it is built, with pgvector's default of 100
+			// lists, so its recall is poor if it is created before the table has data. This is why hnsw is recommended.
+			stmt := fmt.Sprintf("CREATE INDEX \"%s\" ON %s USING %s (%s %s);",
+				name,
+				Identifier(field.GetEntityName()),
+				vectorIndexMethods[field.GetVectorIndex().GetMethod()],
+				Identifier(field.GetName()),
+				vectorOperatorClasses[field.GetVectorIndex().GetMetric()])
+			statements = append(statements, stmt)
+		}
+	}
+
 	// Drop existing indexes which don't exist anymore
 	for _, index := range existingIndexes {
 		if lo.ContainsBy(indexedFields, func(f *proto.Field) bool {
@@ -661,11 +769,25 @@ func createIndexStmts(schema *proto.Schema, existingIndexes []*IndexRow) []strin
 			continue
 		}
 
+		if lo.Contains(vectorIndexes, index.IndexName) {
+			continue
+		}
+
 		// Skip dropping primary key and unique indexes as we are not concerned with these here
 		if index.IsPrimary || index.IsUnique {
 			continue
 		}
 
+		// Skip full-text search indexes as these are managed with the search column
+		if index.ColumnName == proto.SearchColumnName {
+			continue
+		}
+
+		// Skip indexes defined with @index as these are managed separately
+		if modelIndexNameRegex.MatchString(index.IndexName) {
+			continue
+		}
+
 		stmt := fmt.Sprintf("DROP INDEX IF EXISTS \"%s\";", index.IndexName)
 		statements = append(statements, stmt)
 	}
@@ -673,6 +795,204 @@ func createIndexStmts(schema *proto.Schema, existingIndexes []*IndexRow) []strin
 	return statements
 }
 
+// modelIndexStmts generates the statements to create and drop the indexes defined with @index. Indexes on tables which
+// already exist are created and dropped concurrently, so that writes to the table are not blocked while the index is
+// built. The name of each index includes a hash of its definition, so that if the definition changes it is rebuilt.
+func modelIndexStmts(schema *proto.Schema, columns []*ColumnRow, existingIndexes []*IndexRow) ([]string, error) {
+	statements := []string{}
+	names := []string{}
+
+	for _, model := range schema.GetModels() {
+		tableName := casing.ToSnake(model.GetName())
+		concurrently := lo.Ternary(lo.ContainsBy(columns, func(c *ColumnRow) bool {
+			return c.TableName == tableName
+		}), " CONCURRENTLY", "")
+
+		for _, index := range model.GetIndexes() {
+			definition, err := modelIndexDefinition(schema, model, index)
+			if err != nil {
+				return nil, err
+			}
+
+			unique := lo.Ternary(index.GetUnique(), "UNIQUE ", "")
+			name := modelIndexName(model.GetName(), unique+definition)
+			names = append(names, name)
+
+			existing, exists := lo.Find(existingIndexes, func(i *IndexRow) bool {
+				return i.IndexName == name
+			})
+			if exists && existing.IsValid {
+				continue
+			}
+
+			// If building an index concurrently fails then it is left invalid, so it is dropped and built again
+			if exists {
+				statements = append(statements, fmt.Sprintf("DROP INDEX%s IF EXISTS \"%s\";", concurrently, name))
+			}
+
+			statements = append(statements, fmt.Sprintf("CREATE %sINDEX%s \"%s\" ON %s %s;", unique, concurrently, name, Identifier(model.GetName()), definition))
+		}
+	}
+
+	// Drop existing indexes which have been removed from the schema
+	dropped := []string{}
+	for _, index := range existingIndexes {
+		if !modelIndexNameRegex.MatchString(index.IndexName) || lo.Contains(names, index.IndexName) || lo.Contains(dropped, index.IndexName) {
+			continue
+		}
+
+		dropped = append(dropped, index.IndexName)
+		statements = append(statements, fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS \"%s\";", index.IndexName))
+	}
+
+	return statements, nil
+}
+
+// modelIndexDefinition generates the columns or expression of an index, along with its where clause
+// if it is a partial index, e.g. ("status", "created_at" DESC) WHERE "published" = true.
+func modelIndexDefinition(schema *proto.Schema, model *proto.Model, index *proto.Index) (string, error) {
+	keys := []string{}
+	for _, field := range index.GetFieldNames() {
+		key := Identifier(field)
+		if lo.Contains(index.GetDescendingFieldNames(), field) {
+			key += " DESC"
+		}
+		keys = append(keys, key)
+	}
+
+	if index.GetExpression() != nil {
+		sql, err := indexExpressionSql(schema, model, index.GetExpression())
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, fmt.Sprintf("(%s)", sql))
+	}
+
+	definition := fmt.Sprintf("(%s)", strings.Join(keys, ", "))
+
+	if index.GetWhere() != nil {
+		sql, err := indexExpressionSql(schema, model, index.GetWhere())
+		if err != nil {
+			return "", err
+		}
+		definition += " WHERE " + sql
+	}
+
+	return definition, nil
+}
+
+func indexExpressionSql(schema *proto.Schema, model *proto.Model, expression *proto.Expression) (string, error) {
+	parsed, err := parser.ParseExpression(expression.GetSource())
+	if err != nil {
+		return "", err
+	}
+
+	return resolve.RunCelVisitor(parsed, actions.GenerateIndexExpression(schema, model))
+}
+
+// Matches the statements which create or drop an index concurrently, which must each be run on their own outside of a transaction.
+var concurrentIndexStmtRegex = regexp.MustCompile(`(?m)^(CREATE (?:UNIQUE )?INDEX|DROP INDEX) CONCURRENTLY (.*)$`)
+
+// Matches the statements which validate a constraint added as NOT VALID. Outside of the transaction which added the
+// constraint, validating it doesn't block writes to the table.
+var validateConstraintStmtRegex = regexp.MustCompile(`^ALTER TABLE .* VALIDATE CONSTRAINT .*$`)
+
+// splitConcurrentStmts separates the statements which create or drop an index concurrently, or validate a constraint,
+// from the rest of the SQL so that they can be run on their own while writes to the table continue.
+func splitConcurrentStmts(sql string) (string, []string) {
+	lines := []string{}
+	stmts := []string{}
+	for _, line := range strings.Split(sql, "\n") {
+		if concurrentIndexStmtRegex.MatchString(line) || validateConstraintStmtRegex.MatchString(line) {
+			stmts = append(stmts, line)
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n"), stmts
+}
+
+// withoutConcurrently changes any statements which create or drop an index concurrently so that they can be run in a transaction.
+func withoutConcurrently(sql string) string {
+	return concurrentIndexStmtRegex.ReplaceAllString(sql, "$1 $2")
+}
+
+// Matches the names of indexes defined with @index, e.g. post__index_1a2b3c4d.
+var modelIndexNameRegex = regexp.MustCompile(`__index_[0-9a-f]{8}$`)
+
+func modelIndexName(modelName string, definition string) string {
+	return fmt.Sprintf("%s__index_%s", casing.ToSnake(modelName), hashOfExpression(definition))
+}
+
+// searchStmts generates the statements to add, regenerate or drop the full-text search column of a model, along
+// with its GIN index. The name of the index includes a hash of the column's expression, so that if the searchable
+// fields change then the column is regenerated.
+func searchStmts(model *proto.Model, columns []*ColumnRow, existingIndexes []*IndexRow) []string {
+	tableName := casing.ToSnake(model.GetName())
+
+	hasColumn := lo.ContainsBy(columns, func(c *ColumnRow) bool {
+		return c.TableName == tableName && c.ColumnName == proto.SearchColumnName
+	})
+
+	// The column may already have been dropped along with a searchable field which has been removed
+	dropColumn := fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s;", Identifier(model.GetName()), Identifier(proto.SearchColumnName))
+
+	if !model.IsSearchable() {
+		if hasColumn {
+			return []string{dropColumn}
+		}
+		return nil
+	}
+
+	expression := searchDocumentExpression(model)
+	index := searchIndexName(model.GetName(), expression)
+
+	if lo.ContainsBy(existingIndexes, func(i *IndexRow) bool {
+		return i.TableName == tableName && i.IndexName == index
+	}) {
+		return nil
+	}
+
+	statements := []string{}
+	if hasColumn {
+		statements = append(statements, dropColumn)
+	}
+
+	statements = append(statements,
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TSVECTOR GENERATED ALWAYS AS (%s) STORED;", Identifier(model.GetName()), Identifier(proto.SearchColumnName), expression),
+		fmt.Sprintf("CREATE INDEX \"%s\" ON %s USING GIN (%s);", index, Identifier(model.GetName()), Identifier(proto.SearchColumnName)))
+
+	return statements
+}
+
+// searchDocumentExpression generates the tsvector expression for the searchable fields of a model. Fields are
+// weighted in the order they are listed, with any after the fourth sharing the lowest weight.
+func searchDocumentExpression(model *proto.Model) string {
+	weights := []string{"A", "B", "C", "D"}
+
+	documents := []string{}
+	for i, field := range model.GetSearchableFields() {
+		weight := weights[min(i, len(weights)-1)]
+		documents = append(documents, fmt.Sprintf("setweight(to_tsvector('%s', coalesce(%s, '')), '%s')", proto.SearchTextConfig, Identifier(field), weight))
+	}
+
+	return strings.Join(documents, " || ")
+}
+
+func searchIndexName(modelName string, expression string) string {
+	return fmt.Sprintf("%s__%s__%s__idx", casing.ToSnake(modelName), proto.SearchColumnName, hashOfExpression(expression))
+}
+
+// vectorIndexName includes the index method and distance metric, so that if either changes the index is rebuilt.
+func vectorIndexName(field *proto.Field) string {
+	return fmt.Sprintf("%s__%s__%s_%s__idx",
+		casing.ToSnake(field.GetEntityName()),
+		casing.ToSnake(field.GetName()),
+		vectorIndexMethods[field.GetVectorIndex().GetMethod()],
+		strings.TrimPrefix(vectorOperatorClasses[field.GetVectorIndex().GetMetric()], "vector_"))
+}
+
 func indexName(modelName string, fieldName string) string {
 	return fmt.Sprintf("%s__%s__idx", casing.ToSnake(modelName), casing.ToSnake(fieldName))
 }
@@ -158,7 +158,7 @@ func writeClientApiInterface(w *codegen.Writer, schema *proto.Schema, api *proto
 
 	for _, a := range proto.GetActionNamesForApi(schema, api) {
 		action := schema.FindAction(a)
-		if action.GetType() == proto.ActionType_ACTION_TYPE_GET || action.GetType() == proto.ActionType_ACTION_TYPE_LIST || action.GetType() == proto.ActionType_ACTION_TYPE_READ {
+		if action.GetType() == proto.ActionType_ACTION_TYPE_GET || action.GetType() == proto.ActionType_ACTION_TYPE_LIST || action.GetType() == proto.ActionType_ACTION_TYPE_READ || action.GetType() == proto.ActionType_ACTION_TYPE_AGGREGATE {
 			queries = append(queries, action)
 		} else {
 			mutations = append(mutations, action)
@@ -261,7 +261,7 @@ func toClientActionReturnType(model *proto.Model, action *proto.Action) string {
 	switch action.GetType() {
 	case proto.ActionType_ACTION_TYPE_CREATE:
 		return model.GetName()
-	case proto.ActionType_ACTION_TYPE_UPDATE:
+	case proto.ActionType_ACTION_TYPE_UPDATE, proto.ActionType_ACTION_TYPE_RESTORE, proto.ActionType_ACTION_TYPE_UPSERT:
 		return model.GetName()
 	case proto.ActionType_ACTION_TYPE_GET:
 		if len(action.GetResponseEmbeds()) > 0 {
@@ -282,6 +282,12 @@ func toClientActionReturnType(model *proto.Model, action *proto.Action) string {
 		}
 	case proto.ActionType_ACTION_TYPE_DELETE:
 		return "string"
+	case proto.ActionType_ACTION_TYPE_CREATE_MANY, proto.ActionType_ACTION_TYPE_UPDATE_MANY:
+		return model.GetName() + "[]"
+	case proto.ActionType_ACTION_TYPE_DELETE_MANY:
+		return "string[]"
+	case proto.ActionType_ACTION_TYPE_AGGREGATE:
+		return action.GetResponseMessageName()
 	case proto.ActionType_ACTION_TYPE_READ, proto.ActionType_ACTION_TYPE_WRITE:
 		if action.GetResponseMessageName() == parser.MessageFieldTypeAny {
 			return "any"
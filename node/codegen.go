@@ -1142,8 +1142,10 @@ func writeAPIFactory(w *codegen.Writer, schema *proto.Schema) {
 	w.Writeln("const headers = new Headers(meta.headers);")
 	w.Writeln("const response = { headers: responseHeaders }")
 	w.Writeln("const now = () => { return new Date(); };")
-	w.Writeln("const { identity } = meta;")
+	w.Writeln("const { identity, serviceAccount } = meta;")
 	w.Writeln("const isAuthenticated = identity != null;")
+	w.Writeln("const isServiceAccount = serviceAccount != null;")
+	w.Writeln("const scopes = serviceAccount ? serviceAccount.scopes : [];")
 	w.Writeln("const env = {")
 	w.Indent()
 
@@ -1164,7 +1166,7 @@ func writeAPIFactory(w *codegen.Writer, schema *proto.Schema) {
 
 	w.Dedent()
 	w.Writeln("};")
-	w.Writeln("return { headers, response, identity, env, now, secrets, isAuthenticated };")
+	w.Writeln("return { headers, response, identity, env, now, secrets, isAuthenticated, isServiceAccount, scopes };")
 	w.Dedent()
 	w.Writeln("};")
 
@@ -1264,7 +1266,11 @@ func writeAPIFactory(w *codegen.Writer, schema *proto.Schema) {
 		// default values are now set in the database so this is no longer needed.
 		// Passing a no-op function here for backwards compatibility with older versions of the
 		// functions-runtime package.
-		w.Writef(`new runtime.ModelAPI("%s", () => ({}), tableConfigMap)`, casing.ToSnake(model.GetName()))
+		if model.GetSoftDelete() {
+			w.Writef(`new runtime.ModelAPI("%s", () => ({}), tableConfigMap, { softDelete: true })`, casing.ToSnake(model.GetName()))
+		} else {
+			w.Writef(`new runtime.ModelAPI("%s", () => ({}), tableConfigMap)`, casing.ToSnake(model.GetName()))
+		}
 
 		w.Writeln(",")
 	}
@@ -1593,7 +1599,7 @@ func toActionReturnType(model *proto.Model, action *proto.Action) string {
 	switch action.GetType() {
 	case proto.ActionType_ACTION_TYPE_CREATE:
 		returnType += sdkPrefix + model.GetName()
-	case proto.ActionType_ACTION_TYPE_UPDATE:
+	case proto.ActionType_ACTION_TYPE_UPDATE, proto.ActionType_ACTION_TYPE_RESTORE, proto.ActionType_ACTION_TYPE_UPSERT:
 		returnType += sdkPrefix + model.GetName()
 	case proto.ActionType_ACTION_TYPE_GET:
 		className := model.GetName()
@@ -1615,6 +1621,12 @@ func toActionReturnType(model *proto.Model, action *proto.Action) string {
 	case proto.ActionType_ACTION_TYPE_DELETE:
 		// todo: create ID type
 		returnType += "string"
+	case proto.ActionType_ACTION_TYPE_CREATE_MANY, proto.ActionType_ACTION_TYPE_UPDATE_MANY:
+		returnType += sdkPrefix + model.GetName() + "[]"
+	case proto.ActionType_ACTION_TYPE_DELETE_MANY:
+		returnType += "string[]"
+	case proto.ActionType_ACTION_TYPE_AGGREGATE:
+		returnType += action.GetResponseMessageName()
 	case proto.ActionType_ACTION_TYPE_READ, proto.ActionType_ACTION_TYPE_WRITE:
 		if action.GetResponseMessageName() == parser.MessageFieldTypeAny {
 			returnType += "any"
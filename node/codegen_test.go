@@ -415,15 +415,17 @@ function createContextAPI({ responseHeaders, meta }) {
 	const headers = new Headers(meta.headers);
 	const response = { headers: responseHeaders }
 	const now = () => { return new Date(); };
-	const { identity } = meta;
+	const { identity, serviceAccount } = meta;
 	const isAuthenticated = identity != null;
+	const isServiceAccount = serviceAccount != null;
+	const scopes = serviceAccount ? serviceAccount.scopes : [];
 	const env = {
 		TEST: process.env["TEST"] || "",
 	};
 	const secrets = {
 		SECRET_KEY: meta.secrets.SECRET_KEY || "",
 	};
-	return { headers, response, identity, env, now, secrets, isAuthenticated };
+	return { headers, response, identity, env, now, secrets, isAuthenticated, isServiceAccount, scopes };
 };
 function createJobContextAPI({ meta }) {
 	const now = () => { return new Date(); };
@@ -484,6 +486,29 @@ export { createContextAPI, createJobContextAPI, createSubscriberContextAPI, crea
 	})
 }
 
+func TestWriteAPIFactorySoftDelete(t *testing.T) {
+	t.Parallel()
+	schema := `
+model Post {
+	fields {
+		title Text
+	}
+	@softDelete
+}`
+
+	expected := `
+function createModelAPI() {
+	return {
+		post: new runtime.ModelAPI("post", () => ({}), tableConfigMap, { softDelete: true }),
+		identity: new runtime.ModelAPI("identity", () => ({}), tableConfigMap),
+	};
+};`
+
+	runWriterTest(t, schema, expected, func(s *proto.Schema, w *codegen.Writer) {
+		writeAPIFactory(w, s)
+	})
+}
+
 func TestWriteAPIDeclarations(t *testing.T) {
 	t.Parallel()
 	expected := `
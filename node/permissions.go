@@ -59,6 +59,10 @@ func writePermissions(w *codegen.Writer, schema *proto.Schema) {
 					return "${ctx.now()}"
 				case permissions.ValueIsAuthenticated:
 					return "${ctx.isAuthenticated}"
+				case permissions.ValueIsServiceAccount:
+					return "${ctx.isServiceAccount}"
+				case permissions.ValueScopes:
+					return "${ctx.scopes}"
 				case permissions.ValueRecordIDs:
 					// Need to use sql.join() here:
 					// Docs: https://kysely-org.github.io/kysely/interfaces/Sql.html#join
@@ -108,6 +108,15 @@ export class Core {
                 type: "not_found",
               },
             };
+          case 429:
+            return {
+              error: {
+                ...errorCommon,
+                type: "too_many_requests",
+                retryAfter:
+                  Number(result.headers.get("Retry-After")) || undefined,
+              },
+            };
           case 500:
             return {
               error: {
@@ -311,6 +320,166 @@ export class Core {
       return await this.auth.requestToken(req);
     },
 
+    /**
+     * Requests a magic link or a one-time code to be emailed to the identity, which can then be used with the passwordless flow.
+     * Returns error field if an error occurred.
+     */
+    requestPasswordless: async (
+      input: PasswordlessRequestInput
+    ): Promise<APIResult<boolean>> => {
+      return await this.auth.postAuthEndpoint("/auth/passwordless", {
+        email: input.email,
+        method: input.method,
+        redirect_url: input.redirectUrl,
+      });
+    },
+
+    /**
+     * Authenticates with the magic link or one-time code sent by requestPasswordless and, if successful, returns data field with result of the authentication.
+     * Returns error field if an error occurred.
+     */
+    authenticateWithPasswordless: async (
+      input: PasswordlessFlowInput
+    ): Promise<APIResult<AuthenticationResponse>> => {
+      const req: PasswordlessGrant = {
+        grant_type: "passwordless",
+        username: input.email,
+        code: input.code,
+        create_if_not_exists: input.createIfNotExists,
+      };
+
+      return await this.auth.requestToken(req);
+    },
+
+    /**
+     * Completes authentication with a code from the authenticator app or a recovery code, using the mfaToken from the mfa_required error.
+     * Returns error field if an error occurred.
+     */
+    authenticateWithMfa: async (
+      input: MfaFlowInput
+    ): Promise<APIResult<AuthenticationResponse>> => {
+      const req: MfaGrant = {
+        grant_type: "mfa",
+        mfa_token: input.mfaToken,
+        code: input.code,
+      };
+
+      return await this.auth.requestToken(req);
+    },
+
+    /**
+     * Enrols the authenticated identity in multi-factor authentication, or the identity which must enrol to sign in if an mfaToken is provided.
+     * Returns data field with the secret for the authenticator app, which must then be confirmed with confirmMfa.
+     */
+    enrolMfa: async (
+      input: MfaEnrolInput = {}
+    ): Promise<APIResult<MfaEnrolment>> => {
+      return await this.auth.postAuthEndpoint(
+        "/auth/mfa/enrol",
+        { mfa_token: input.mfaToken },
+        (data) => ({
+          secret: data.secret,
+          otpauthUri: data.otpauth_uri,
+          recoveryCodes: data.recovery_codes,
+        })
+      );
+    },
+
+    /**
+     * Confirms the enrolment in multi-factor authentication with a code from the authenticator app.
+     * Returns error field if an error occurred.
+     */
+    confirmMfa: async (input: MfaConfirmInput): Promise<APIResult<boolean>> => {
+      return await this.auth.postAuthEndpoint("/auth/mfa/confirm", {
+        code: input.code,
+        mfa_token: input.mfaToken,
+      });
+    },
+
+    /**
+     * Disables multi-factor authentication for the authenticated identity, with a code from the authenticator app or a recovery code.
+     * Returns error field if an error occurred.
+     */
+    disableMfa: async (input: MfaDisableInput): Promise<APIResult<boolean>> => {
+      return await this.auth.postAuthEndpoint("/auth/mfa/disable", {
+        code: input.code,
+      });
+    },
+
+    /**
+     * Returns data field set to the sessions in which the authenticated identity is signed in, such as on other devices.
+     * Returns error field if an error occurred.
+     */
+    listSessions: async (): Promise<APIResult<Session[]>> => {
+      return await this.auth.requestAuthEndpoint(
+        "GET",
+        "/auth/sessions",
+        undefined,
+        (data) =>
+          data.sessions.map((s: any) => ({
+            id: s.id,
+            userAgent: s.user_agent,
+            ipAddress: s.ip_address,
+            createdAt: new Date(s.created_at),
+            lastUsedAt: new Date(s.last_used_at),
+            current: s.current,
+          }))
+      );
+    },
+
+    /**
+     * Revokes one of the authenticated identity's sessions, signing it out on that device.
+     * Returns error field if an error occurred.
+     */
+    revokeSession: async (
+      input: RevokeSessionInput
+    ): Promise<APIResult<boolean>> => {
+      return await this.auth.postAuthEndpoint("/auth/sessions/revoke", {
+        session_id: input.sessionId,
+      });
+    },
+
+    /**
+     * Revokes all of the authenticated identity's sessions, signing it out on every device including this one.
+     * Returns error field if an error occurred.
+     */
+    revokeAllSessions: async (): Promise<APIResult<boolean>> => {
+      const result = await this.auth.postAuthEndpoint("/auth/sessions/revoke", {
+        all: true,
+      });
+
+      if (result.data) {
+        this.auth.accessToken.set(null);
+        this.auth.refreshToken.set(null);
+      }
+
+      return result;
+    },
+
+    /**
+     * Verifies the identity's email using the token from the link sent in the verification email.
+     * Returns error field if an error occurred.
+     */
+    verifyEmail: async (
+      input: VerifyEmailInput
+    ): Promise<APIResult<boolean>> => {
+      return await this.auth.postAuthEndpoint("/auth/verify", {
+        token: input.token,
+      });
+    },
+
+    /**
+     * Sends another verification email to the identity, if its email has not already been verified.
+     * Returns error field if an error occurred.
+     */
+    resendVerification: async (
+      input: ResendVerificationInput
+    ): Promise<APIResult<boolean>> => {
+      return await this.auth.postAuthEndpoint("/auth/verify/resend", {
+        email: input.email,
+      });
+    },
+
     /**
      * Forcefully refreshes the session with the authentication server, and returns data field set to true if the identity is still authenticated.
      * Return true if successfully authenticated.
@@ -406,9 +575,10 @@ export class Core {
           const requestId = result.headers.get("X-Amzn-Requestid") || undefined;
 
           let errorMessage = "unknown error";
+          let resp: any = {};
 
           try {
-            const resp = await result.json();
+            resp = await result.json();
             errorMessage = resp.error_description;
           } catch (error) {}
 
@@ -417,6 +587,17 @@ export class Core {
             requestId,
           };
 
+          if (resp.error === "mfa_required") {
+            return {
+              error: {
+                ...errorCommon,
+                type: "mfa_required",
+                mfaToken: resp.mfa_token,
+                mfaEnrolled: resp.mfa_enrolled,
+              },
+            };
+          }
+
           switch (result.status) {
             case 400:
               return {
@@ -446,6 +627,15 @@ export class Core {
                   type: "not_found",
                 },
               };
+            case 429:
+              return {
+                error: {
+                  ...errorCommon,
+                  type: "too_many_requests",
+                  retryAfter:
+                    Number(result.headers.get("Retry-After")) || undefined,
+                },
+              };
             case 500:
               return {
                 error: {
@@ -473,6 +663,112 @@ export class Core {
         };
       }
     },
+
+    /**
+     * Posts a request to an authentication server endpoint which does not issue tokens, and returns data field set to true if accepted
+     * or, if a parse function is given, to the parsed response.
+     */
+    postAuthEndpoint: async <T = boolean>(
+      path: string,
+      body: Record<string, unknown>,
+      parse: (data: any) => T = (): any => true
+    ): Promise<APIResult<T>> => {
+      return await this.auth.requestAuthEndpoint("POST", path, body, parse);
+    },
+
+    /**
+     * Requests an authentication server endpoint which does not issue tokens, with the access token if authenticated.
+     */
+    requestAuthEndpoint: async <T>(
+      method: "GET" | "POST",
+      path: string,
+      body: Record<string, unknown> | undefined,
+      parse: (data: any) => T
+    ): Promise<APIResult<T>> => {
+      try {
+        const url = new URL(this.config.baseUrl);
+        const token = this.auth.accessToken.get();
+        const result = await globalThis.fetch(url.origin + path, {
+          method,
+          cache: "no-cache",
+          headers: {
+            accept: "application/json",
+            ...(body !== undefined
+              ? {
+                  "content-type": "application/json",
+                }
+              : {}),
+            ...(token != null
+              ? {
+                  Authorization: "Bearer " + token,
+                }
+              : {}),
+          },
+          body: body !== undefined ? JSON.stringify(body) : undefined,
+        });
+
+        if (result.ok) {
+          return {
+            data: parse(await result.json()),
+          };
+        }
+
+        const requestId = result.headers.get("X-Amzn-Requestid") || undefined;
+
+        let errorMessage = "unknown error";
+
+        try {
+          const resp = await result.json();
+          errorMessage = resp.error_description;
+        } catch (error) {}
+
+        if (result.status === 400) {
+          return {
+            error: {
+              type: "bad_request",
+              message: errorMessage,
+              requestId,
+            },
+          };
+        }
+
+        if (result.status === 401) {
+          return {
+            error: {
+              type: "unauthorized",
+              message: errorMessage,
+              requestId,
+            },
+          };
+        }
+
+        if (result.status === 403) {
+          return {
+            error: {
+              type: "forbidden",
+              message: errorMessage,
+              requestId,
+            },
+          };
+        }
+
+        return {
+          error: {
+            type: "internal_server_error",
+            message: errorMessage,
+            requestId,
+          },
+        };
+      } catch (error) {
+        return {
+          error: {
+            type: "unknown",
+            message: "unknown error",
+            error,
+          },
+        };
+      }
+    },
   };
 }
 
@@ -49,6 +49,15 @@ type ForbiddenError = {
   requestId?: string;
 };
 
+/* 403 when a second factor is required to complete authentication */
+type MfaRequiredError = {
+  type: "mfa_required";
+  message: string;
+  mfaToken: string;
+  mfaEnrolled: boolean;
+  requestId?: string;
+};
+
 /* 404 */
 type NotFoundError = {
   type: "not_found";
@@ -56,6 +65,14 @@ type NotFoundError = {
   requestId?: string;
 };
 
+/* 429 when rate limited or locked out after too many failed attempts */
+type TooManyRequestsError = {
+  type: "too_many_requests";
+  message: string;
+  retryAfter?: number;
+  requestId?: string;
+};
+
 /* 500 */
 type InternalServerError = {
   type: "internal_server_error";
@@ -74,8 +91,10 @@ type UnknownError = {
 export type APIError =
   | UnauthorizedError
   | ForbiddenError
+  | MfaRequiredError
   | NotFoundError
   | BadRequestError
+  | TooManyRequestsError
   | InternalServerError
   | UnknownError;
 
@@ -111,6 +130,63 @@ export interface SingleSignOnFlowInput {
   code: string;
 }
 
+export interface PasswordlessRequestInput {
+  email: string;
+  method?: "code" | "link";
+  redirectUrl?: string;
+}
+
+export interface PasswordlessFlowInput {
+  email: string;
+  code: string;
+  createIfNotExists?: boolean;
+}
+
+export interface VerifyEmailInput {
+  token: string;
+}
+
+export interface ResendVerificationInput {
+  email: string;
+}
+
+export interface MfaFlowInput {
+  mfaToken: string;
+  code: string;
+}
+
+export interface MfaEnrolInput {
+  mfaToken?: string;
+}
+
+export interface MfaConfirmInput {
+  code: string;
+  mfaToken?: string;
+}
+
+export interface MfaDisableInput {
+  code: string;
+}
+
+export type MfaEnrolment = {
+  secret: string;
+  otpauthUri: string;
+  recoveryCodes: string[];
+};
+
+export interface RevokeSessionInput {
+  sessionId: string;
+}
+
+export type Session = {
+  id: string;
+  userAgent: string;
+  ipAddress: string;
+  createdAt: Date;
+  lastUsedAt: Date;
+  current: boolean;
+};
+
 type PasswordGrant = {
   grant_type: "password";
   username: string;
@@ -129,6 +205,19 @@ type AuthorizationCodeGrant = {
   code: string;
 };
 
+type PasswordlessGrant = {
+  grant_type: "passwordless";
+  username: string;
+  code: string;
+  create_if_not_exists?: boolean;
+};
+
+type MfaGrant = {
+  grant_type: "mfa";
+  mfa_token: string;
+  code: string;
+};
+
 type RefreshGrant = {
   grant_type: "refresh_token";
   refresh_token: string;
@@ -138,6 +227,8 @@ export type TokenRequest =
   | PasswordGrant
   | TokenExchangeGrant
   | AuthorizationCodeGrant
+  | PasswordlessGrant
+  | MfaGrant
   | RefreshGrant;
 
 export type SortDirection = "asc" | "desc" | "ASC" | "DESC";
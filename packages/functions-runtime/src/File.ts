@@ -9,6 +9,9 @@ import { getSignedUrl } from "@aws-sdk/s3-request-presigner";
 import { useDatabase } from "./database";
 import { DatabaseError } from "./errors";
 import KSUID from "ksuid";
+import { createHmac } from "node:crypto";
+import { mkdir, readFile, writeFile } from "node:fs/promises";
+import { join } from "node:path";
 
 type MimeType =
   | "application/json"
@@ -89,6 +92,12 @@ const s3Client: S3Client | null = (() => {
   });
 })();
 
+// When running locally, files can be stored in a directory instead of S3.
+// Download URLs are then signed with the same key as the runtime which serves them.
+const filesDirectory: string | null = process.env.KEEL_FILES_DIRECTORY
+  ? join(process.env.KEEL_FILES_DIRECTORY, "files")
+  : null;
+
 export class InlineFile {
   protected _filename: string;
   protected _contentType: MimeType;
@@ -209,6 +218,10 @@ export class File extends InlineFile {
       return Buffer.from(arrayBuffer);
     }
 
+    if (filesDirectory) {
+      return await readFile(join(filesDirectory, this.key));
+    }
+
     if (!s3Client) {
       throw new Error("S3 client is required");
     }
@@ -240,6 +253,24 @@ export class File extends InlineFile {
 
   // Generates a presigned download URL
   async getPresignedUrl(): Promise<URL> {
+    if (filesDirectory) {
+      const expires = Math.floor(Date.now() / 1000) + 60 * 60;
+      const signature = createHmac(
+        "sha256",
+        Buffer.from(process.env.KEEL_FILES_SIGNING_KEY || "", "hex")
+      )
+        .update(`${this.key}:${expires}`)
+        .digest("hex");
+
+      const url = new URL(
+        `${process.env.KEEL_FILES_URL}/_keel/files/${encodeURIComponent(this.key)}`
+      );
+      url.searchParams.set("expires", expires.toString());
+      url.searchParams.set("signature", signature);
+
+      return url;
+    }
+
     if (!s3Client) {
       throw new Error("S3 client is required");
     }
@@ -257,6 +288,12 @@ export class File extends InlineFile {
 
   // Generates a presigned upload URL. If the file doesn't have a key, a new one will be generated
   async getPresignedUploadUrl(): Promise<URL> {
+    if (filesDirectory) {
+      throw new Error(
+        "presigned upload URLs are not supported when files are stored on the filesystem"
+      );
+    }
+
     if (!s3Client) {
       throw new Error("S3 client is required");
     }
@@ -298,6 +335,17 @@ async function storeFile(
   size: number,
   expires: Date | null
 ): Promise<void> {
+  if (filesDirectory) {
+    // The metadata is written first so the file is never visible without it.
+    await mkdir(filesDirectory, { recursive: true });
+    await writeFile(
+      join(filesDirectory, key + ".json"),
+      JSON.stringify({ filename, contentType })
+    );
+    await writeFile(join(filesDirectory, key), contents);
+    return;
+  }
+
   if (!s3Client) {
     throw new Error("S3 client is required");
   }
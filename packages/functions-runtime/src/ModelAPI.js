@@ -35,6 +35,13 @@ import { DatabaseError } from "./errors";
  *
  * TableConfigMap is mapping of database table names to TableConfig objects
  * @typedef {Object.<string, TableConfig>} TableConfigMap
+ *
+ * ModelAPIOptions describes the model the API is for. If softDelete is true
+ * then the model has @softDelete, so deleting a row sets its deleted_at column
+ * rather than removing it, and soft deleted rows are never found or updated.
+ * @typedef {{
+ *  softDelete?: boolean,
+ * }} ModelAPIOptions
  */
 
 class ModelAPI {
@@ -42,11 +49,13 @@ class ModelAPI {
    * @param {string} tableName The name of the table this API is for
    * @param {Function} _ Used to be a function that returns the default values for a row in this table. No longer used.
    * @param {TableConfigMap} tableConfigMap
+   * @param {ModelAPIOptions} options
    */
-  constructor(tableName, _, tableConfigMap = {}) {
+  constructor(tableName, _, tableConfigMap = {}, options = {}) {
     this._tableName = tableName;
     this._tableConfigMap = tableConfigMap;
     this._modelName = upperCamelCase(this._tableName);
+    this._softDelete = options.softDelete === true;
   }
 
   async create(values) {
@@ -77,6 +86,7 @@ class ModelAPI {
 
       builder = applyJoins(context, builder, where);
       builder = applyWhereConditions(context, builder, where);
+      builder = excludeSoftDeleted(builder, this._tableName, this._softDelete);
 
       span.setAttribute("sql", builder.compile().sql);
       const row = await builder.executeTakeFirst();
@@ -106,6 +116,11 @@ class ModelAPI {
 
           builder = applyJoins(context, builder, where);
           builder = applyWhereConditions(context, builder, where);
+          builder = excludeSoftDeleted(
+            builder,
+            this._tableName,
+            this._softDelete
+          );
 
           builder = builder.as(this._tableName);
 
@@ -191,6 +206,7 @@ class ModelAPI {
 
       // TODO: support joins for update
       builder = applyWhereConditions(context, builder, where);
+      builder = excludeSoftDeleted(builder, this._tableName, this._softDelete);
 
       span.setAttribute("sql", builder.compile().sql);
 
@@ -208,12 +224,18 @@ class ModelAPI {
     const db = useDatabase();
 
     return tracing.withSpan(name, async (span) => {
-      let builder = db.deleteFrom(this._tableName).returning(["id"]);
+      let builder = this._softDelete
+        ? db
+            .updateTable(this._tableName)
+            .set({ deleted_at: sql`now()` })
+            .returning(["id"])
+        : db.deleteFrom(this._tableName).returning(["id"]);
 
       const context = new QueryContext([this._tableName], this._tableConfigMap);
 
       // TODO: support joins for delete
       builder = applyWhereConditions(context, builder, where);
+      builder = excludeSoftDeleted(builder, this._tableName, this._softDelete);
 
       span.setAttribute("sql", builder.compile().sql);
       try {
@@ -237,9 +259,29 @@ class ModelAPI {
 
     builder = applyJoins(context, builder, where);
     builder = applyWhereConditions(context, builder, where);
+    builder = excludeSoftDeleted(builder, this._tableName, this._softDelete);
+
+    return new QueryBuilder(
+      this._tableName,
+      context,
+      builder,
+      this._softDelete
+    );
+  }
+}
 
-    return new QueryBuilder(this._tableName, context, builder);
+/**
+ * Excludes rows which have been soft deleted if the model has @softDelete.
+ * @param {import("kysely").WhereInterface} builder
+ * @param {string} tableName
+ * @param {boolean} softDelete
+ */
+function excludeSoftDeleted(builder, tableName, softDelete) {
+  if (!softDelete) {
+    return builder;
   }
+
+  return builder.where(`${tableName}.deleted_at`, "is", null);
 }
 
 async function create(conn, tableName, tableConfigs, values) {
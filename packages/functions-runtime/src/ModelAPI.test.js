@@ -7,6 +7,7 @@ import KSUID from "ksuid";
 let personAPI;
 let postAPI;
 let authorAPI;
+let productAPI;
 
 beforeEach(async () => {
   const db = useDatabase();
@@ -15,6 +16,7 @@ beforeEach(async () => {
   DROP TABLE IF EXISTS post;
   DROP TABLE IF EXISTS person;
   DROP TABLE IF EXISTS author;
+  DROP TABLE IF EXISTS product;
 
   CREATE TABLE person(
       id               text PRIMARY KEY,
@@ -34,7 +36,13 @@ beforeEach(async () => {
   CREATE TABLE author(
     id               text PRIMARY KEY,
     name             text NOT NULL
-  );`.execute(db);
+  );
+  CREATE TABLE product(
+    id               text PRIMARY KEY,
+    sku              text NOT NULL,
+    deleted_at       timestamptz
+  );
+  CREATE UNIQUE INDEX product_sku_udx ON product (sku) WHERE deleted_at IS NULL;`.execute(db);
 
   const tableConfigMap = {
     person: {
@@ -58,6 +66,10 @@ beforeEach(async () => {
   postAPI = new ModelAPI("post", undefined, tableConfigMap);
 
   authorAPI = new ModelAPI("author", undefined, tableConfigMap);
+
+  productAPI = new ModelAPI("product", undefined, tableConfigMap, {
+    softDelete: true,
+  });
 });
 
 test("ModelAPI.create", async () => {
@@ -930,6 +942,57 @@ test("ModelAPI.delete", async () => {
   await expect(personAPI.findOne({ id })).resolves.toEqual(null);
 });
 
+test("ModelAPI.delete - soft deletes", async () => {
+  const product = await productAPI.create({
+    id: KSUID.randomSync().string,
+    sku: "ABC",
+  });
+
+  const deletedId = await productAPI.delete({ id: product.id });
+  expect(deletedId).toEqual(product.id);
+
+  const db = useDatabase();
+  const row = await db
+    .selectFrom("product")
+    .selectAll()
+    .where("id", "=", product.id)
+    .executeTakeFirst();
+  expect(row.deleted_at).not.toBeNull();
+
+  await expect(productAPI.findOne({ id: product.id })).resolves.toEqual(null);
+  await expect(productAPI.findMany()).resolves.toEqual([]);
+  await expect(
+    productAPI.where({ sku: "ABC" }).findMany()
+  ).resolves.toEqual([]);
+  await expect(
+    productAPI.update({ id: product.id }, { sku: "DEF" })
+  ).rejects.toThrow("no result");
+  await expect(productAPI.delete({ id: product.id })).rejects.toThrow(
+    "no result"
+  );
+});
+
+test("ModelAPI.create - unique values can be reused once soft deleted", async () => {
+  const product = await productAPI.create({
+    id: KSUID.randomSync().string,
+    sku: "ABC",
+  });
+
+  await expect(
+    productAPI.create({ id: KSUID.randomSync().string, sku: "ABC" })
+  ).rejects.toThrow();
+
+  await productAPI.where({ id: product.id }).delete();
+
+  const recreated = await productAPI.create({
+    id: KSUID.randomSync().string,
+    sku: "ABC",
+  });
+
+  const rows = await productAPI.findMany();
+  expect(rows.map((r) => r.id)).toEqual([recreated.id]);
+});
+
 describe("QueryBuilder", () => {
   test("ModelAPI chained findMany with offset/limit/order by", async () => {
     await postAPI.create({
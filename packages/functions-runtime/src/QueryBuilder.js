@@ -1,3 +1,4 @@
+import { sql } from "kysely";
 import { applyWhereConditions } from "./applyWhereConditions";
 import {
   applyLimit,
@@ -17,12 +18,14 @@ class QueryBuilder {
    * @param {string} tableName
    * @param {import("./QueryContext").QueryContext} context
    * @param {import("kysely").Kysely} db
+   * @param {boolean} softDelete If the model has @softDelete, in which case soft deleted rows have already been excluded from db
    */
-  constructor(tableName, context, db) {
+  constructor(tableName, context, db, softDelete = false) {
     this._tableName = tableName;
     this._context = context;
     this._db = db;
     this._modelName = upperCamelCase(this._tableName);
+    this._softDelete = softDelete;
   }
 
   where(where) {
@@ -31,7 +34,12 @@ class QueryBuilder {
     let builder = applyJoins(context, this._db, where);
     builder = applyWhereConditions(context, builder, where);
 
-    return new QueryBuilder(this._tableName, context, builder);
+    return new QueryBuilder(
+      this._tableName,
+      context,
+      builder,
+      this._softDelete
+    );
   }
 
   sql() {
@@ -84,7 +92,12 @@ class QueryBuilder {
     return tracing.withSpan(name, async (span) => {
       // the original query selects the distinct id + the model.* so we need to clear
       const sub = this._db.clearSelect().select("id");
-      let builder = db.deleteFrom(this._tableName).where("id", "in", sub);
+      let builder = this._softDelete
+        ? db
+            .updateTable(this._tableName)
+            .set({ deleted_at: sql`now()` })
+            .where("id", "in", sub)
+        : db.deleteFrom(this._tableName).where("id", "in", sub);
 
       const query = builder.returning(["id"]);
 
@@ -29,6 +29,10 @@ class KeelCamelCasePlugin {
       if (key.endsWith("__sequence")) {
         return obj;
       }
+      // Models using @searchable have a keel_search column holding the search document, which is also internal
+      if (key === "keel_search") {
+        return obj;
+      }
       let value = row[key];
       if (Array.isArray(value)) {
         value = value.map((it) =>
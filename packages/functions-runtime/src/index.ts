@@ -172,6 +172,8 @@ export type ContextAPI = {
   headers: RequestHeaders;
   response: Response;
   isAuthenticated: boolean;
+  isServiceAccount: boolean;
+  scopes: string[];
   now(): Date;
 };
 
@@ -49,11 +49,13 @@ function parseInputs(inputs) {
   return inputs;
 }
 
-// parseComplexInputType will parse out complex types such as InlineFile and Duration
+// parseComplexInputType will parse out complex types such as InlineFile, File and Duration
 function parseComplexInputType(value) {
   switch (value.__typename) {
     case "InlineFile":
       return InlineFile.fromDataURL(value.dataURL);
+    case "File":
+      return File.fromDbRecord(value);
     case "Duration":
       return Duration.fromISOString(value.interval);
     default:
@@ -1,5 +1,5 @@
 import { parseInputs } from "./parsing";
-import { InlineFile } from "./File";
+import { InlineFile, File } from "./File";
 import { test, expect } from "vitest";
 
 test("simple test", async () => {
@@ -55,6 +55,28 @@ test("dataURL image test", async () => {
   });
 });
 
+test("uploaded file test", async () => {
+  const params = {
+    file: {
+      __typename: "File",
+      key: "2ZPqVyJmMnvbVjm3bTdWxvAqAgb",
+      filename: "cover.png",
+      contentType: "image/png",
+      size: 2024,
+    },
+  };
+
+  const parsedParams = parseInputs(params);
+  expect(parsedParams).toEqual({
+    file: File.fromDbRecord({
+      key: "2ZPqVyJmMnvbVjm3bTdWxvAqAgb",
+      filename: "cover.png",
+      contentType: "image/png",
+      size: 2024,
+    }),
+  });
+});
+
 test("nested image test", async () => {
   const params = {
     post: {
@@ -16,15 +16,17 @@ import (
 type ValueType int
 
 const (
-	ValueIdentityID      ValueType = iota // Identity ID of caller
-	ValueIdentityEmail                    // Identity email of caller
-	ValueIsAuthenticated                  // Is authenticated flag
-	ValueNow                              // Current timestamp
-	ValueHeader                           // Header value
-	ValueSecret                           // Secret value
-	ValueString                           // A string literal
-	ValueNumber                           // A number literal
-	ValueRecordIDs                        // The ID's of the records to check permission for
+	ValueIdentityID       ValueType = iota // Identity ID of caller
+	ValueIdentityEmail                     // Identity email of caller
+	ValueIsAuthenticated                   // Is authenticated flag
+	ValueIsServiceAccount                  // Is service account flag
+	ValueScopes                            // Scopes granted to a service account caller
+	ValueNow                               // Current timestamp
+	ValueHeader                            // Header value
+	ValueSecret                            // Secret value
+	ValueString                            // A string literal
+	ValueNumber                            // A number literal
+	ValueRecordIDs                         // The ID's of the records to check permission for
 )
 
 type Value struct {
@@ -268,6 +270,19 @@ func handleContext(s *proto.Schema, ident *parser.ExpressionIdent, stmt *stateme
 		stmt.expression += "?::boolean"
 		stmt.values = append(stmt.values, &Value{Type: ValueIsAuthenticated})
 		return nil
+	case "isServiceAccount":
+		stmt.expression += "?::boolean"
+		stmt.values = append(stmt.values, &Value{Type: ValueIsServiceAccount})
+		return nil
+	case "scopes":
+		// The scopes are an array so membership is tested with ANY rather than IS NOT DISTINCT FROM
+		if strings.HasSuffix(stmt.expression, " IS NOT DISTINCT FROM ") {
+			stmt.expression = strings.TrimSuffix(stmt.expression, " IS NOT DISTINCT FROM ") + " = ANY(?::text[])"
+		} else {
+			stmt.expression += "?::text[]"
+		}
+		stmt.values = append(stmt.values, &Value{Type: ValueScopes})
+		return nil
 	case "now":
 		stmt.expression += "?"
 		stmt.values = append(stmt.values, &Value{Type: ValueNow})
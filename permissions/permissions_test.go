@@ -481,6 +481,44 @@ func TestToSQL(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "ctx_service_account_scopes",
+			schema: `
+				model Invoice {
+					fields {
+						total Number
+					}
+					actions {
+						get getInvoice(id)
+					}
+					@permission(
+						expression: ctx.isServiceAccount && "invoices:read" in ctx.scopes,
+						actions: [get]
+					)
+				}
+			`,
+			action: "getInvoice",
+			sql: `
+				SELECT DISTINCT "invoice"."id" FROM "invoice"
+				WHERE
+					?::boolean and ? = ANY(?::text[]) AND "invoice"."id" IN (?)
+			`,
+			values: []permissions.Value{
+				{
+					Type: permissions.ValueIsServiceAccount,
+				},
+				{
+					Type:        permissions.ValueString,
+					StringValue: "\"invoices:read\"",
+				},
+				{
+					Type: permissions.ValueScopes,
+				},
+				{
+					Type: permissions.ValueRecordIDs,
+				},
+			},
+		},
 		{
 			name: "ctx_now",
 			schema: `
@@ -10,7 +10,8 @@ func (a *Action) IsArbitraryFunction() bool {
 
 func (a *Action) IsWriteAction() bool {
 	switch a.GetType() {
-	case ActionType_ACTION_TYPE_CREATE, ActionType_ACTION_TYPE_DELETE, ActionType_ACTION_TYPE_WRITE, ActionType_ACTION_TYPE_UPDATE:
+	case ActionType_ACTION_TYPE_CREATE, ActionType_ACTION_TYPE_DELETE, ActionType_ACTION_TYPE_WRITE, ActionType_ACTION_TYPE_UPDATE, ActionType_ACTION_TYPE_RESTORE, ActionType_ACTION_TYPE_UPSERT,
+		ActionType_ACTION_TYPE_CREATE_MANY, ActionType_ACTION_TYPE_UPDATE_MANY, ActionType_ACTION_TYPE_DELETE_MANY:
 		return true
 	default:
 		return false
@@ -19,7 +20,7 @@ func (a *Action) IsWriteAction() bool {
 
 func (a *Action) IsReadAction() bool {
 	switch a.GetType() {
-	case ActionType_ACTION_TYPE_GET, ActionType_ACTION_TYPE_LIST, ActionType_ACTION_TYPE_READ:
+	case ActionType_ACTION_TYPE_GET, ActionType_ACTION_TYPE_LIST, ActionType_ACTION_TYPE_READ, ActionType_ACTION_TYPE_AGGREGATE:
 		return true
 	default:
 		return false
@@ -38,6 +39,10 @@ func (a *Action) IsList() bool {
 	return a.GetType() == ActionType_ACTION_TYPE_LIST
 }
 
+func (a *Action) IsAggregate() bool {
+	return a.GetType() == ActionType_ACTION_TYPE_AGGREGATE
+}
+
 func (a *Action) IsGet() bool {
 	return a.GetType() == ActionType_ACTION_TYPE_GET
 }
@@ -46,6 +51,24 @@ func (a *Action) IsDelete() bool {
 	return a.GetType() == ActionType_ACTION_TYPE_DELETE
 }
 
+func (a *Action) IsRestore() bool {
+	return a.GetType() == ActionType_ACTION_TYPE_RESTORE
+}
+
+func (a *Action) IsUpsert() bool {
+	return a.GetType() == ActionType_ACTION_TYPE_UPSERT
+}
+
+// IsBulk returns true if the action performs its single record action for each of many items.
+func (a *Action) IsBulk() bool {
+	switch a.GetType() {
+	case ActionType_ACTION_TYPE_CREATE_MANY, ActionType_ACTION_TYPE_UPDATE_MANY, ActionType_ACTION_TYPE_DELETE_MANY:
+		return true
+	default:
+		return false
+	}
+}
+
 // FacetFields returns the fields that are used for faceting for this action.
 func FacetFields(schema *Schema, action *Action) []*Field {
 	model := schema.FindModel(action.GetModelName())
@@ -9,6 +9,13 @@ import (
 var _ Entity = &Model{}
 var _ Entity = &Task{}
 
+// SearchColumnName is the column which holds the full-text search document of a model with @searchable.
+const SearchColumnName = "keel_search"
+
+// SearchTextConfig is the Postgres text search configuration used to build the search document and to parse
+// search text. Words are stemmed and stop words dropped as in English, whatever the language of the text.
+const SearchTextConfig = "english"
+
 type Entity interface {
 	GetName() string
 	GetFields() []*Field
@@ -32,6 +39,11 @@ func (m *Model) HasFiles() bool {
 	return len(m.FileFields()) > 0
 }
 
+// IsSearchable returns true if the model has @searchable.
+func (m *Model) IsSearchable() bool {
+	return len(m.GetSearchableFields()) > 0
+}
+
 // FieldNames provides a (sorted) list of the fields in the model of the given name.
 func (m *Model) FieldNames() []string {
 	names := lo.Map(m.GetFields(), func(x *Field, _ int) string {
@@ -238,7 +238,8 @@ func ActionIsArbitraryFunction(action *Action) bool {
 // Deprecated: Use Action.IsWriteAction() instead.
 func IsWriteAction(action *Action) bool {
 	switch action.GetType() {
-	case ActionType_ACTION_TYPE_CREATE, ActionType_ACTION_TYPE_DELETE, ActionType_ACTION_TYPE_WRITE, ActionType_ACTION_TYPE_UPDATE:
+	case ActionType_ACTION_TYPE_CREATE, ActionType_ACTION_TYPE_DELETE, ActionType_ACTION_TYPE_WRITE, ActionType_ACTION_TYPE_UPDATE, ActionType_ACTION_TYPE_RESTORE, ActionType_ACTION_TYPE_UPSERT,
+		ActionType_ACTION_TYPE_CREATE_MANY, ActionType_ACTION_TYPE_UPDATE_MANY, ActionType_ACTION_TYPE_DELETE_MANY:
 		return true
 	default:
 		return false
@@ -248,7 +249,7 @@ func IsWriteAction(action *Action) bool {
 // Deprecated: Use Action.IsReadAction() instead.
 func IsReadAction(action *Action) bool {
 	switch action.GetType() {
-	case ActionType_ACTION_TYPE_GET, ActionType_ACTION_TYPE_LIST, ActionType_ACTION_TYPE_READ:
+	case ActionType_ACTION_TYPE_GET, ActionType_ACTION_TYPE_LIST, ActionType_ACTION_TYPE_READ, ActionType_ACTION_TYPE_AGGREGATE:
 		return true
 	default:
 		return false
@@ -412,11 +413,17 @@ func FindMessageField(message *Message, fieldName string) *MessageField {
 func FindValuesInputMessage(schema *Schema, actionName string) *Message {
 	action := schema.FindAction(actionName)
 	message := schema.FindMessage(action.GetInputMessageName())
+	if action.IsBulk() {
+		message = FindItemInputMessage(schema, actionName)
+	}
 
 	switch action.GetType() {
-	case ActionType_ACTION_TYPE_CREATE:
+	case ActionType_ACTION_TYPE_CREATE,
+		ActionType_ACTION_TYPE_CREATE_MANY:
 		return message
-	case ActionType_ACTION_TYPE_UPDATE:
+	case ActionType_ACTION_TYPE_UPDATE,
+		ActionType_ACTION_TYPE_UPSERT,
+		ActionType_ACTION_TYPE_UPDATE_MANY:
 		for _, v := range message.GetFields() {
 			if v.GetName() == "values" && v.GetType().GetType() == Type_TYPE_MESSAGE {
 				return schema.FindMessage(v.GetType().GetMessageName().GetValue())
@@ -431,13 +438,21 @@ func FindValuesInputMessage(schema *Schema, actionName string) *Message {
 func FindWhereInputMessage(schema *Schema, actionName string) *Message {
 	action := schema.FindAction(actionName)
 	message := schema.FindMessage(action.GetInputMessageName())
+	if action.IsBulk() {
+		message = FindItemInputMessage(schema, actionName)
+	}
 
 	switch action.GetType() {
 	case ActionType_ACTION_TYPE_GET,
-		ActionType_ACTION_TYPE_DELETE:
+		ActionType_ACTION_TYPE_DELETE,
+		ActionType_ACTION_TYPE_RESTORE,
+		ActionType_ACTION_TYPE_DELETE_MANY:
 		return message
 	case ActionType_ACTION_TYPE_LIST,
-		ActionType_ACTION_TYPE_UPDATE:
+		ActionType_ACTION_TYPE_AGGREGATE,
+		ActionType_ACTION_TYPE_UPDATE,
+		ActionType_ACTION_TYPE_UPSERT,
+		ActionType_ACTION_TYPE_UPDATE_MANY:
 		for _, v := range message.GetFields() {
 			if v.GetName() == "where" && v.GetType().GetType() == Type_TYPE_MESSAGE {
 				return schema.FindMessage(v.GetType().GetMessageName().GetValue())
@@ -447,6 +462,23 @@ func FindWhereInputMessage(schema *Schema, actionName string) *Message {
 	return nil
 }
 
+// For bulk action types, returns the message of each of the "items" in the root message, which
+// is the input message of the single record action, or returns nil if not found.
+func FindItemInputMessage(schema *Schema, actionName string) *Message {
+	action := schema.FindAction(actionName)
+	if !action.IsBulk() {
+		return nil
+	}
+
+	message := schema.FindMessage(action.GetInputMessageName())
+	for _, v := range message.GetFields() {
+		if v.GetName() == "items" && v.GetType().GetType() == Type_TYPE_MESSAGE {
+			return schema.FindMessage(v.GetType().GetMessageName().GetValue())
+		}
+	}
+	return nil
+}
+
 func MessageUsedAsResponse(schema *Schema, msgName string) bool {
 	for _, model := range schema.GetModels() {
 		for _, action := range model.GetActions() {
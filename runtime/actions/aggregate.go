@@ -0,0 +1,273 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/locale"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+// The interval which Date and Timestamp fields are grouped by when none is given.
+const defaultAggregateInterval = "day"
+
+var aggregateFunctions = map[proto.AggregateFunction]string{
+	proto.AggregateFunction_AGGREGATE_FUNCTION_SUM: parser.AggregateSum,
+	proto.AggregateFunction_AGGREGATE_FUNCTION_AVG: parser.AggregateAvg,
+	proto.AggregateFunction_AGGREGATE_FUNCTION_MIN: parser.AggregateMin,
+	proto.AggregateFunction_AGGREGATE_FUNCTION_MAX: parser.AggregateMax,
+}
+
+// Aggregate counts the records matching the filters of the action, grouped by the fields of @groupBy, and applies
+// the functions of @aggregate to each group. Date and Timestamp fields are grouped into buckets of an interval.
+func Aggregate(scope *Scope, input map[string]any) (map[string]any, error) {
+	permissions := proto.PermissionsForAction(scope.Schema, scope.Action)
+
+	// Attempt to resolve permissions early; i.e. before row-based database querying.
+	canResolveEarly, authorised, err := TryResolveAuthorisationEarly(scope, input, permissions)
+	if err != nil {
+		return nil, err
+	}
+	if canResolveEarly && !authorised {
+		return nil, common.NewPermissionError()
+	}
+
+	opts := []QueryBuilderOption{}
+	if location, err := locale.GetTimeLocation(scope.Context); err == nil {
+		opts = append(opts, WithTimezone(location.String()))
+	}
+
+	if !canResolveEarly {
+		// Every record which is aggregated must be permitted
+		isAuthorised, err := authoriseAggregate(scope, input, permissions, opts)
+		if err != nil {
+			return nil, err
+		}
+		if !isAuthorised {
+			return nil, common.NewPermissionError()
+		}
+	}
+
+	// Generate the SQL statement.
+	query := NewQuery(scope.Model, opts...)
+	statement, err := GenerateAggregateStatement(query, scope, input)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.GetDatabase(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := database.ExecuteQuery(scope.Context, statement.template, statement.args...)
+	if err != nil {
+		return nil, toRuntimeError(err)
+	}
+
+	results := make([]map[string]any, len(result.Rows))
+	for i, row := range result.Rows {
+		results[i] = aggregateResult(scope.Action, row)
+	}
+
+	return map[string]any{
+		"results": results,
+	}, nil
+}
+
+func GenerateAggregateStatement(query *QueryBuilder, scope *Scope, input map[string]any) (*Statement, error) {
+	interval, err := aggregateInterval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := NewQuery(query.Entity, query.options()...)
+	err = filters.applyAggregateFilters(scope, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(filters.joins) > 0 {
+		// Joining to the records of a to-many relationship would aggregate a record more than once,
+		// and so the records are filtered by an inline query instead.
+		filters.Select(IdField())
+		filters.DistinctOn(IdField())
+		err = query.Where(IdField(), OneOf, InlineQuery(filters, IdField()))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		query.filters = filters.filters
+		query.args = filters.args
+	}
+
+	for _, name := range scope.Action.GetGroupBy() {
+		field := scope.Model.FindField(name)
+		if field == nil {
+			return nil, fmt.Errorf("cannot group by unknown field %s", name)
+		}
+
+		column := Field(name).toSqlOperandString(query)
+		switch field.GetType().GetType() {
+		case proto.Type_TYPE_DATE:
+			column = fmt.Sprintf("DATE_TRUNC('%s', %s)::DATE", interval, column)
+		case proto.Type_TYPE_TIMESTAMP, proto.Type_TYPE_DATETIME:
+			if query.timezone != "" {
+				column = fmt.Sprintf("DATE_TRUNC('%s', %s, '%s')", interval, column, query.timezone)
+			} else {
+				column = fmt.Sprintf("DATE_TRUNC('%s', %s)", interval, column)
+			}
+		}
+
+		query.SelectClause(fmt.Sprintf("%s AS %s", column, sqlQuote(name)))
+		query.GroupBy(Raw(column))
+		query.orderBy = append(query.orderBy, &orderClause{field: Raw(column), direction: "ASC"})
+	}
+
+	query.SelectClause("COUNT(*) AS \"count\"")
+
+	for _, aggregate := range scope.Action.GetAggregates() {
+		function, ok := aggregateFunctions[aggregate.GetFunction()]
+		if !ok {
+			return nil, fmt.Errorf("unhandled aggregate function %s", aggregate.GetFunction())
+		}
+
+		column := Field(aggregate.GetFieldName()).toSqlOperandString(query)
+		alias := fmt.Sprintf("%s.%s", function, aggregate.GetFieldName())
+		query.SelectClause(fmt.Sprintf("%s(%s) AS %s", strings.ToUpper(function), column, sqlQuote(alias)))
+	}
+
+	return query.SelectStatement(), nil
+}
+
+// authoriseAggregate checks that every record matching the filters of an aggregate action is permitted. Rather than
+// fetching the ids of the records to authorise, the permission expressions are checked against the records found by
+// an inline query of the filters.
+func authoriseAggregate(scope *Scope, input map[string]any, permissions []*proto.PermissionRule, opts []QueryBuilderOption) (bool, error) {
+	if len(proto.PermissionsWithExpression(permissions)) == 0 {
+		return false, nil
+	}
+
+	query := NewQuery(scope.Model, opts...)
+	err := query.applyAggregateFilters(scope, input)
+	if err != nil {
+		return false, err
+	}
+
+	query.SelectClause(fmt.Sprintf("COUNT(DISTINCT %s) AS count", IdField().toSqlOperandString(query)))
+	row, err := query.SelectStatement().ExecuteToSingle(scope.Context)
+	if err != nil {
+		return false, err
+	}
+
+	count, ok := row["count"].(int64)
+	if !ok {
+		return false, errors.New("could not parse the number of records to authorise")
+	}
+
+	if count == 0 {
+		return true, nil
+	}
+
+	statement, err := GenerateAggregatePermissionStatement(NewQuery(scope.Model, opts...), scope, input, int(count))
+	if err != nil {
+		return false, err
+	}
+
+	row, err = statement.ExecuteToSingle(scope.Context)
+	if err != nil {
+		return false, err
+	}
+
+	authorised, ok := row["authorised"].(bool)
+	if !ok {
+		return false, errors.New("could not parse authorised result")
+	}
+
+	return authorised, nil
+}
+
+// GenerateAggregatePermissionStatement generates the SQL which checks that each of the count records matching the
+// filters of an aggregate action is permitted.
+func GenerateAggregatePermissionStatement(query *QueryBuilder, scope *Scope, input map[string]any, count int) (*Statement, error) {
+	where, ok := input["where"].(map[string]any)
+	if !ok {
+		where = map[string]any{}
+	}
+
+	err := query.applyAggregateFilters(scope, input)
+	if err != nil {
+		return nil, err
+	}
+	query.Select(IdField())
+
+	permissions := proto.PermissionsForAction(scope.Schema, scope.Action)
+	return generatePermissionStatement(scope, permissions, where, InlineQuery(query, IdField()), count)
+}
+
+// applyAggregateFilters applies the implicit and @where filters of an aggregate action, just as for a list action.
+func (query *QueryBuilder) applyAggregateFilters(scope *Scope, input map[string]any) error {
+	where, ok := input["where"].(map[string]any)
+	if !ok {
+		where = map[string]any{}
+	}
+
+	err := query.applyImplicitFiltersForList(scope, where)
+	if err != nil {
+		return err
+	}
+
+	err = query.applyExpressionFilters(scope, where)
+	if err != nil {
+		return err
+	}
+
+	query.ExcludeSoftDeleted()
+
+	return nil
+}
+
+// aggregateInterval returns the interval to group Date and Timestamp fields by.
+func aggregateInterval(input map[string]any) (string, error) {
+	interval, ok := input["interval"].(string)
+	if !ok || interval == "" {
+		return defaultAggregateInterval, nil
+	}
+
+	if !lo.Contains(parser.AggregateIntervals, interval) {
+		return "", common.NewInputMalformedError(fmt.Sprintf("interval must be one of %s", strings.Join(parser.AggregateIntervals, ", ")))
+	}
+
+	return interval, nil
+}
+
+// aggregateResult maps a row of the aggregate statement to a result, in which the value of each function
+// is nested by the name of the function, e.g. { status: "Shipped", count: 2, sum: { total: 20.5 } }.
+func aggregateResult(action *proto.Action, row map[string]any) map[string]any {
+	result := map[string]any{
+		"count": row["count"],
+	}
+
+	for _, name := range action.GetGroupBy() {
+		result[name] = row[name]
+	}
+
+	for _, aggregate := range action.GetAggregates() {
+		function := aggregateFunctions[aggregate.GetFunction()]
+
+		values, ok := result[function].(map[string]any)
+		if !ok {
+			values = map[string]any{}
+			result[function] = values
+		}
+
+		values[aggregate.GetFieldName()] = row[fmt.Sprintf("%s.%s", function, aggregate.GetFieldName())]
+	}
+
+	return result
+}
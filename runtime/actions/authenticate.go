@@ -8,6 +8,7 @@ import (
 	email "net/mail"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/karlseguin/typed"
 	"go.opentelemetry.io/otel/attribute"
@@ -45,6 +46,16 @@ func ResetRequestPassword(scope *Scope, input map[string]any) error {
 		return common.RuntimeError{Code: common.ErrInvalidInput, Message: "invalid redirect URL"}
 	}
 
+	// Each request counts as an attempt, so that emails cannot be flooded with reset links
+	clientIp := runtimectx.GetClientIP(scope.Context)
+	allowed, lockedUntil, err := oauth.ReserveAttempt(scope.Context, oauth.LockoutActionPasswordReset, emailString, clientIp)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return common.NewRateLimitError(time.Until(*lockedUntil))
+	}
+
 	var identity auth.Identity
 	identity, err = FindIdentityByEmail(scope.Context, scope.Schema, emailString, oauth.KeelIssuer)
 	if err != nil {
@@ -78,6 +89,45 @@ func ResetRequestPassword(scope *Scope, input map[string]any) error {
 	return err
 }
 
+// SendVerificationEmail emails the identity a link to the verify endpoint, which verifies the identity's email when followed.
+func SendVerificationEmail(ctx context.Context, identity auth.Identity) error {
+	emailString, ok := identity[parser.IdentityFieldNameEmail].(string)
+	if !ok || emailString == "" {
+		return errors.New("cannot send a verification email to an identity without an email")
+	}
+
+	token, err := oauth.GenerateEmailVerificationToken(ctx, identity[parser.FieldNameId].(string), emailString)
+	if err != nil {
+		return err
+	}
+
+	config, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	verifyUrl, err := config.GetVerifyEmailUrl()
+	if err != nil {
+		return err
+	}
+
+	q := verifyUrl.Query()
+	q.Add("token", token)
+	verifyUrl.RawQuery = q.Encode()
+
+	client, err := runtimectx.GetMailClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.Send(ctx, &mail.SendEmailRequest{
+		To:        emailString,
+		From:      "hi@keel.xyz",
+		Subject:   "[Keel] Verify your email",
+		PlainText: fmt.Sprintf("Please follow this link to verify your email: %s", verifyUrl),
+	})
+}
+
 // Deprecated: we will be deprecating the authenticate action and password flow in favour of the new auth endpoints.
 func ResetPassword(scope *Scope, input map[string]any) error {
 	typedInput := typed.New(input)
@@ -120,18 +170,11 @@ func ResetPassword(scope *Scope, input map[string]any) error {
 }
 
 func HandleAuthorizationHeader(ctx context.Context, schema *proto.Schema, headers http.Header) (auth.Identity, error) {
-	header := headers.Get("Authorization")
-	if header == "" {
-		return nil, nil
-	}
-
-	headerSplit := strings.Split(header, "Bearer ")
-	if len(headerSplit) != 2 {
-		return nil, common.NewAuthenticationFailedMessageErr("no 'Bearer' prefix in the Authorization header")
+	token, err := bearerToken(headers)
+	if err != nil {
+		return nil, err
 	}
 
-	token := headerSplit[1]
-
 	if token != "" {
 		identity, err := HandleBearerToken(ctx, schema, token)
 		if err != nil {
@@ -143,6 +186,63 @@ func HandleAuthorizationHeader(ctx context.Context, schema *proto.Schema, header
 	return nil, nil
 }
 
+// HandleAuthorization authenticates the bearer token in the Authorization header, if there is one, and returns
+// the context with either the identity or the service account which the token was issued to.
+func HandleAuthorization(ctx context.Context, schema *proto.Schema, headers http.Header) (context.Context, error) {
+	token, err := bearerToken(headers)
+	if err != nil {
+		return ctx, err
+	}
+
+	if token == "" {
+		return ctx, nil
+	}
+
+	if oauth.IsServiceAccountToken(token) {
+		account, err := HandleServiceAccountToken(ctx, token)
+		if err != nil {
+			return ctx, err
+		}
+		return auth.WithServiceAccount(ctx, account), nil
+	}
+
+	identity, err := HandleBearerToken(ctx, schema, token)
+	if err != nil {
+		return ctx, err
+	}
+
+	return auth.WithIdentity(ctx, identity), nil
+}
+
+func bearerToken(headers http.Header) (string, error) {
+	header := headers.Get("Authorization")
+	if header == "" {
+		return "", nil
+	}
+
+	headerSplit := strings.Split(header, "Bearer ")
+	if len(headerSplit) != 2 {
+		return "", common.NewAuthenticationFailedMessageErr("no 'Bearer' prefix in the Authorization header")
+	}
+
+	return headerSplit[1], nil
+}
+
+func HandleServiceAccountToken(ctx context.Context, token string) (*auth.ServiceAccount, error) {
+	ctx, span := tracer.Start(ctx, "Authorization")
+	defer span.End()
+
+	account, err := oauth.ValidateServiceAccountToken(ctx, token)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("service_account.id", account.Id))
+
+	return account, nil
+}
+
 func HandleBearerToken(ctx context.Context, schema *proto.Schema, token string) (auth.Identity, error) {
 	ctx, span := tracer.Start(ctx, "Authorization")
 	defer span.End()
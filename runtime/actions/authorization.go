@@ -24,7 +24,7 @@ func AuthoriseAction(scope *Scope, input map[string]any, rowsToAuthorise []map[s
 		return false, errors.New("cannot authorise with AuthoriseAction if no operation is provided in scope")
 	}
 
-	if scope.Action.GetType() == proto.ActionType_ACTION_TYPE_UPDATE || scope.Action.GetType() == proto.ActionType_ACTION_TYPE_LIST {
+	if scope.Action.GetType() == proto.ActionType_ACTION_TYPE_UPDATE || scope.Action.GetType() == proto.ActionType_ACTION_TYPE_UPDATE_MANY || scope.Action.GetType() == proto.ActionType_ACTION_TYPE_LIST || scope.Action.GetType() == proto.ActionType_ACTION_TYPE_AGGREGATE {
 		var ok bool
 		input, ok = input["where"].(map[string]any)
 		if !ok {
@@ -222,7 +222,33 @@ func ResolveRolePermissionRule(ctx context.Context, schema *proto.Schema, permis
 	return authorised, nil
 }
 
+// IdentityHasAnyRole returns true if the identity's email or its domain is listed in any of the given roles.
+// Unlike role-based permissions, the email does not need to be verified.
+func IdentityHasAnyRole(schema *proto.Schema, identity auth.Identity, roleNames []string) bool {
+	identityEmail, _ := identity[parser.IdentityFieldNameEmail].(string)
+	if identityEmail == "" {
+		return false
+	}
+
+	_, identityDomain, _ := strings.Cut(identityEmail, "@")
+
+	for _, roleName := range roleNames {
+		role := proto.FindRole(roleName, schema)
+		if lo.Contains(role.GetEmails(), identityEmail) || lo.Contains(role.GetDomains(), identityDomain) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func GeneratePermissionStatement(scope *Scope, permissions []*proto.PermissionRule, input map[string]any, idsToAuthorise []string) (*Statement, error) {
+	return generatePermissionStatement(scope, permissions, input, Value(idsToAuthorise), len(idsToAuthorise))
+}
+
+// generatePermissionStatement generates the SQL which checks that each of the count rows with the ids given by the
+// operand, which is either a list of ids or an inline query selecting them, is permitted.
+func generatePermissionStatement(scope *Scope, permissions []*proto.PermissionRule, input map[string]any, ids *QueryOperand, count int) (*Statement, error) {
 	permissions = proto.PermissionsWithExpression(permissions)
 	query := NewQuery(scope.Model, WithJoinType(JoinTypeLeft))
 
@@ -253,13 +279,13 @@ func GeneratePermissionStatement(scope *Scope, permissions []*proto.PermissionRu
 	query.And()
 
 	// Filter by the ids we want to authorise
-	err := query.Where(IdField(), OneOf, Value(idsToAuthorise))
+	err := query.Where(IdField(), OneOf, ids)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check that the number of authorised rows matches
-	query.SelectClause(fmt.Sprintf("COUNT(DISTINCT %s) = %v AS authorised", IdField().toSqlOperandString(query), len(idsToAuthorise)))
+	query.SelectClause(fmt.Sprintf("COUNT(DISTINCT %s) = %v AS authorised", IdField().toSqlOperandString(query), count))
 
 	return query.SelectStatement(), nil
 }
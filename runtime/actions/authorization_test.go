@@ -27,8 +27,9 @@ type authorisationTestCase struct {
 	expectedArgs []any
 	// If resolved early, what was the authorisation result?
 	// nil if early authorisation cannot be determined.
-	earlyAuth *earlyAuthorisationResult
-	identity  auth.Identity
+	earlyAuth      *earlyAuthorisationResult
+	identity       auth.Identity
+	serviceAccount *auth.ServiceAccount
 }
 
 type earlyAuthorisationResult struct {
@@ -52,6 +53,12 @@ var unverifiedIdentity = auth.Identity{
 	"email": "weaveton@weave.xyz",
 }
 
+var billingServiceAccount = &auth.ServiceAccount{
+	Id:     "serviceAccountId",
+	Name:   "billing",
+	Scopes: []string{"things:read", "things:write"},
+}
+
 var verifiedIdentity = auth.Identity{
 	"id":            "identityId",
 	"email":         "keelson@keel.xyz",
@@ -456,6 +463,62 @@ var authorisationTestCases = []authorisationTestCase{
 		earlyAuth:  AuthorisationDeniedEarly(),
 		identity:   unverifiedIdentity,
 	},
+	{
+		name: "early_evaluate_is_service_account",
+		keelSchema: `
+			model Thing {
+				actions {
+					create createThing() {
+						@permission(expression: ctx.isServiceAccount)
+					}
+				}
+			}`,
+		actionName:     "createThing",
+		earlyAuth:      AuthorisationGrantedEarly(),
+		serviceAccount: billingServiceAccount,
+	},
+	{
+		name: "early_evaluate_is_service_account_identity",
+		keelSchema: `
+			model Thing {
+				actions {
+					create createThing() {
+						@permission(expression: ctx.isServiceAccount)
+					}
+				}
+			}`,
+		actionName: "createThing",
+		earlyAuth:  AuthorisationDeniedEarly(),
+		identity:   unverifiedIdentity,
+	},
+	{
+		name: "early_evaluate_service_account_scope",
+		keelSchema: `
+			model Thing {
+				actions {
+					create createThing() {
+						@permission(expression: ctx.isServiceAccount && "things:write" in ctx.scopes)
+					}
+				}
+			}`,
+		actionName:     "createThing",
+		earlyAuth:      AuthorisationGrantedEarly(),
+		serviceAccount: billingServiceAccount,
+	},
+	{
+		name: "early_evaluate_service_account_missing_scope",
+		keelSchema: `
+			model Thing {
+				actions {
+					create createThing() {
+						@permission(expression: "things:delete" in ctx.scopes)
+					}
+				}
+			}`,
+		actionName:     "createThing",
+		earlyAuth:      AuthorisationDeniedEarly(),
+		serviceAccount: billingServiceAccount,
+	},
 	{
 		name: "cannot_early_evaluate_multiple_conditions_and_with_database",
 		keelSchema: `
@@ -1457,6 +1520,8 @@ func TestPermissionQueryBuilder(t *testing.T) {
 				ctx = auth.WithIdentity(ctx, testCase.identity)
 			}
 
+			ctx = auth.WithServiceAccount(ctx, testCase.serviceAccount)
+
 			ctx = runtimectx.WithSecrets(ctx, map[string]string{"MY_SECRET": "1234"})
 
 			scope, _, _, err := generateQueryScope(ctx, testCase.keelSchema, testCase.actionName)
@@ -1512,3 +1577,50 @@ func TestPermissionQueryBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregatePermissionStatement(t *testing.T) {
+	t.Parallel()
+
+	keelSchema := `
+		enum Status {
+			Pending
+			Shipped
+		}
+		model Order {
+			fields {
+				status Status
+				total Decimal
+				shared Boolean
+			}
+			actions {
+				aggregate orderTotals(status?) {
+					@groupBy(status)
+					@aggregate(sum: [total])
+					@where(order.total > 0)
+				}
+			}
+			@permission(expression: order.shared, actions: [aggregate])
+		}`
+
+	scope, query, _, err := generateQueryScope(t.Context(), keelSchema, "orderTotals")
+	require.NoError(t, err)
+
+	input := map[string]any{
+		"where": map[string]any{
+			"status": map[string]any{
+				"equals": "Shipped",
+			},
+		},
+	}
+
+	statement, err := actions.GenerateAggregatePermissionStatement(query, scope, input, 3)
+	require.NoError(t, err)
+
+	require.Equal(t, clean(`
+		SELECT COUNT(DISTINCT "order"."id") = 3 AS authorised
+		FROM "order"
+		WHERE
+			( "order"."shared" IS NOT DISTINCT FROM ? ) AND
+			"order"."id" IN (SELECT "order"."id" FROM "order" WHERE "order"."status" IS NOT DISTINCT FROM ? AND "order"."total" > ?)`), clean(statement.SqlTemplate()))
+	require.Equal(t, []any{true, "Shipped", int64(0)}, statement.SqlArgs())
+}
@@ -0,0 +1,612 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/expressions/resolve"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/locale"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+// CreateMany creates a record for each of the items. All the records are inserted by a single statement within
+// a transaction, and so either every item is created or none are. Each item is authorised as its own create,
+// and the created records are returned in the order of the items.
+func CreateMany(scope *Scope, input map[string]any) (res []map[string]any, err error) {
+	items, err := bulkItems(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return []map[string]any{}, nil
+	}
+
+	if scope.Model.HasFiles() {
+		// handle file uploads for each item
+		for i, item := range items {
+			items[i], err = handleFileUploads(scope, item)
+			if err != nil {
+				return nil, bulkItemError(i, fmt.Errorf("handling file uploads: %w", err))
+			}
+		}
+	}
+
+	// Attempt to resolve permissions early for each item; i.e. before row-based database querying.
+	unresolved, err := resolveBulkAuthorisationEarly(scope, items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate the SQL statement
+	opts := bulkQueryOptions(scope)
+	query := NewQuery(scope.Model, opts...)
+
+	statements, err := GenerateCreateManyStatements(query, scope, input)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.GetDatabase(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(scope.Context, func(ctx context.Context) error {
+		scope := scope.WithContext(ctx)
+
+		res = make([]map[string]any, len(items))
+		for _, statement := range statements {
+			rows, err := statement.ExecuteToItems(scope.Context, len(items))
+			if err != nil {
+				return err
+			}
+
+			for i, row := range rows {
+				if row != nil {
+					res[i] = row
+				}
+			}
+		}
+
+		// The items which could not be authorised early are authorised against the records they created
+		for _, i := range unresolved {
+			isAuthorised, err := AuthoriseAction(scope, items[i], []map[string]any{res[i]})
+			if err != nil {
+				return bulkItemError(i, err)
+			}
+
+			if !isAuthorised {
+				return bulkItemError(i, common.NewPermissionError())
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Because of computed fields and nested creates, we need to fetch the rows again to get the computed fields
+	ids := make([]any, len(res))
+	for i, row := range res {
+		ids[i] = row["id"]
+	}
+
+	query = NewQuery(scope.Model, opts...)
+	err = query.Where(IdField(), OneOf, Value(ids))
+	if err != nil {
+		return nil, err
+	}
+	query.Select(AllFields())
+	rows, _, _, err := query.SelectStatement().ExecuteToMany(scope.Context, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := map[any]map[string]any{}
+	for _, row := range rows {
+		byId[row["id"]] = row
+	}
+
+	for i, row := range res {
+		res[i] = byId[row["id"]]
+	}
+
+	return transformBulkFileResponses(scope, res)
+}
+
+// UpdateMany updates a record for each of the items, which each have their own where and values inputs. All the
+// records are updated by a single statement within a transaction, and so if any item's record cannot be found
+// then none are updated. Each item is authorised as its own update, and the updated records are returned in
+// the order of the items.
+func UpdateMany(scope *Scope, input map[string]any) (res []map[string]any, err error) {
+	items, err := bulkItems(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return []map[string]any{}, nil
+	}
+
+	if scope.Model.HasFiles() {
+		// handle file uploads and change input values to file data if applicable
+		for i, item := range items {
+			if values, ok := item["values"].(map[string]any); ok {
+				in, err := handleFileUploads(scope, values)
+				if err != nil {
+					return nil, bulkItemError(i, fmt.Errorf("handling file uploads: %w", err))
+				}
+				item["values"] = in
+			}
+		}
+	}
+
+	wheres := make([]map[string]any, len(items))
+	for i, item := range items {
+		where, ok := item["where"].(map[string]any)
+		if !ok {
+			where = map[string]any{}
+		}
+		wheres[i] = where
+	}
+
+	err = rejectDuplicateBulkItems(wheres)
+	if err != nil {
+		return nil, err
+	}
+
+	// Attempt to resolve permissions early for each item; i.e. before row-based database querying.
+	unresolved, err := resolveBulkAuthorisationEarly(scope, items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate the SQL statement
+	opts := bulkQueryOptions(scope)
+	query := NewQuery(scope.Model, opts...)
+
+	statement, err := GenerateUpdateManyStatement(query, scope, input)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.GetDatabase(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(scope.Context, func(ctx context.Context) error {
+		scope := scope.WithContext(ctx)
+
+		// The items which could not be authorised early are authorised against their records before the update
+		err := authoriseBulkItemRows(scope, items, wheres, unresolved, opts)
+		if err != nil {
+			return err
+		}
+
+		res, err = statement.ExecuteToItems(scope.Context, len(items))
+		if err != nil {
+			return err
+		}
+
+		return checkBulkItemRows(res)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transformBulkFileResponses(scope, res)
+}
+
+// DeleteMany deletes a record for each of the items. All the records are deleted by a single statement within
+// a transaction, and so if any item's record cannot be found then none are deleted. Each item is authorised as
+// its own delete, and the IDs of the deleted records are returned in the order of the items.
+func DeleteMany(scope *Scope, input map[string]any) (res []string, err error) {
+	items, err := bulkItems(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return []string{}, nil
+	}
+
+	err = rejectDuplicateBulkItems(items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Attempt to resolve permissions early for each item; i.e. before row-based database querying.
+	unresolved, err := resolveBulkAuthorisationEarly(scope, items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate the SQL statement
+	opts := bulkQueryOptions(scope)
+	query := NewQuery(scope.Model, opts...)
+
+	statement, err := GenerateDeleteManyStatement(query, scope, input)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.GetDatabase(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(scope.Context, func(ctx context.Context) error {
+		scope := scope.WithContext(ctx)
+
+		// The items which could not be authorised early are authorised against their records before the delete
+		err := authoriseBulkItemRows(scope, items, items, unresolved, opts)
+		if err != nil {
+			return err
+		}
+
+		rows, err := statement.ExecuteToItems(scope.Context, len(items))
+		if err != nil {
+			return err
+		}
+
+		err = checkBulkItemRows(rows)
+		if err != nil {
+			return err
+		}
+
+		res = make([]string, len(rows))
+		for i, row := range rows {
+			id, ok := row["id"].(string)
+			if !ok {
+				return errors.New("could not parse id key")
+			}
+			res[i] = id
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GenerateCreateManyStatements generates the statements which create the records of a bulk create. Items are
+// inserted by multi-row INSERT statements, unless any item creates nested records, in which case each item is
+// inserted by its own common table expression of a single statement.
+func GenerateCreateManyStatements(query *QueryBuilder, scope *Scope, input map[string]any) ([]*Statement, error) {
+	queries, err := bulkItemQueries(query, input, func(q *QueryBuilder, item map[string]any) error {
+		return q.applyCreate(scope, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if CanInsertRows(queries) {
+		return InsertRowsStatements(scope.Context, queries), nil
+	}
+
+	return []*Statement{InsertManyStatement(scope.Context, queries)}, nil
+}
+
+func GenerateUpdateManyStatement(query *QueryBuilder, scope *Scope, input map[string]any) (*Statement, error) {
+	queries, err := bulkItemQueries(query, input, func(q *QueryBuilder, item map[string]any) error {
+		return q.applyUpdate(scope, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return UpdateManyStatement(scope.Context, queries), nil
+}
+
+func GenerateDeleteManyStatement(query *QueryBuilder, scope *Scope, input map[string]any) (*Statement, error) {
+	queries, err := bulkItemQueries(query, input, func(q *QueryBuilder, item map[string]any) error {
+		return q.applyDelete(scope, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if scope.Model.GetSoftDelete() {
+		return UpdateManyStatement(scope.Context, queries), nil
+	}
+
+	return DeleteManyStatement(scope.Context, queries), nil
+}
+
+// bulkItems returns the items input of a bulk action.
+func bulkItems(input map[string]any) ([]map[string]any, error) {
+	values, ok := input["items"].([]any)
+	if !ok {
+		return nil, common.NewInputMalformedError("items must be a list")
+	}
+
+	if len(values) > parser.MaxBulkItems {
+		return nil, common.NewInputMalformedError(fmt.Sprintf("bulk actions cannot write more than %d items in one request, but there are %d items", parser.MaxBulkItems, len(values)))
+	}
+
+	items := make([]map[string]any, len(values))
+	for i, v := range values {
+		item, ok := v.(map[string]any)
+		if !ok {
+			return nil, common.NewInputMalformedError(fmt.Sprintf("items[%d] must be an object", i))
+		}
+		items[i] = item
+	}
+
+	return items, nil
+}
+
+// bulkItemQueries creates a query builder for each of the items of a bulk action, with the same options as the
+// given query, and applies the single record action for the item to it.
+func bulkItemQueries(query *QueryBuilder, input map[string]any, apply func(q *QueryBuilder, item map[string]any) error) ([]*QueryBuilder, error) {
+	items, err := bulkItems(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, common.NewInputMalformedError("items must have at least one item")
+	}
+
+	queries := make([]*QueryBuilder, len(items))
+	for i, item := range items {
+		queries[i] = NewQuery(query.Entity, query.options()...)
+
+		err := apply(queries[i], item)
+		if err != nil {
+			return nil, bulkItemError(i, err)
+		}
+	}
+
+	return queries, nil
+}
+
+func bulkQueryOptions(scope *Scope) []QueryBuilderOption {
+	opts := []QueryBuilderOption{}
+	if location, err := locale.GetTimeLocation(scope.Context); err == nil {
+		opts = append(opts, WithTimezone(location.String()))
+	}
+	return opts
+}
+
+// resolveBulkAuthorisationEarly attempts to authorise each of the items of a bulk action without any database
+// querying, and returns the indexes of the items which require row-based authorisation. If any item can be
+// resolved early as not authorised, then the whole action is not permitted.
+func resolveBulkAuthorisationEarly(scope *Scope, items []map[string]any) ([]int, error) {
+	permissions := proto.PermissionsForAction(scope.Schema, scope.Action)
+
+	unresolved := []int{}
+	for i, item := range items {
+		canResolveEarly, authorised, err := TryResolveAuthorisationEarly(scope, item, permissions)
+		if err != nil {
+			return nil, bulkItemError(i, err)
+		}
+
+		if canResolveEarly && !authorised {
+			return nil, bulkItemError(i, common.NewPermissionError())
+		}
+
+		if !canResolveEarly {
+			unresolved = append(unresolved, i)
+		}
+	}
+
+	return unresolved, nil
+}
+
+// rejectDuplicateBulkItems returns an error if more than one item of a bulk update or delete has the same where
+// inputs, as they would write the same record.
+func rejectDuplicateBulkItems(wheres []map[string]any) error {
+	seen := map[string]int{}
+	for i, where := range wheres {
+		key, err := json.Marshal(where)
+		if err != nil {
+			return err
+		}
+
+		if j, ok := seen[string(key)]; ok {
+			return common.NewInputMalformedError(fmt.Sprintf("items[%d] writes the same record as items[%d]", i, j))
+		}
+		seen[string(key)] = i
+	}
+
+	return nil
+}
+
+// checkBulkItemRows checks that each item of a bulk update or delete wrote a record, and that no two items wrote
+// the same record, which can happen if they find it by different unique fields.
+func checkBulkItemRows(rows []map[string]any) error {
+	ids := map[any]int{}
+	for i, row := range rows {
+		if row == nil {
+			return bulkItemError(i, common.NewNotFoundError(""))
+		}
+
+		if j, ok := ids[row["id"]]; ok {
+			return common.NewInputMalformedError(fmt.Sprintf("items[%d] writes the same record as items[%d]", i, j))
+		}
+		ids[row["id"]] = i
+	}
+
+	return nil
+}
+
+// authoriseBulkItemRows authorises the unresolved items of a bulk update or delete against the records they would
+// write, which are found by the items' where inputs. Unless the permissions use the inputs, in which case each item
+// is authorised with its own, the records of all the items are found and authorised together.
+func authoriseBulkItemRows(scope *Scope, items []map[string]any, wheres []map[string]any, unresolved []int, opts []QueryBuilderOption) error {
+	if len(unresolved) == 0 {
+		return nil
+	}
+
+	usesInputs, err := permissionsUseInputs(scope, lo.Map(unresolved, func(i int, _ int) map[string]any {
+		return wheres[i]
+	}))
+	if err != nil {
+		return err
+	}
+
+	if usesInputs {
+		for _, i := range unresolved {
+			err := authoriseBulkItemRow(scope, items[i], wheres[i], opts)
+			if err != nil {
+				return bulkItemError(i, err)
+			}
+		}
+
+		return nil
+	}
+
+	query := NewQuery(scope.Model, opts...)
+	query.OpenParenthesis()
+	for _, i := range unresolved {
+		query.OpenParenthesis()
+
+		err := query.ApplyImplicitFilters(scope, wheres[i])
+		if err != nil {
+			return err
+		}
+
+		err = query.applyExpressionFilters(scope, wheres[i])
+		if err != nil {
+			return err
+		}
+
+		query.CloseParenthesis()
+		query.Or()
+	}
+	query.CloseParenthesis()
+
+	query.ExcludeSoftDeleted()
+	query.Select(IdField())
+	query.DistinctOn(IdField())
+	rows, _, _, err := query.SelectStatement().ExecuteToMany(scope.Context, nil)
+	if err != nil {
+		return err
+	}
+
+	isAuthorised, err := AuthoriseAction(scope, items[unresolved[0]], rows)
+	if err != nil {
+		return err
+	}
+
+	if !isAuthorised {
+		// Authorise the items one at a time to find which of them is not permitted, so that the client knows
+		for _, i := range unresolved {
+			err := authoriseBulkItemRow(scope, items[i], wheres[i], opts)
+			if err != nil {
+				return bulkItemError(i, err)
+			}
+		}
+
+		return common.NewPermissionError()
+	}
+
+	return nil
+}
+
+// permissionsUseInputs determines if any of the permission expressions of the action use any of the inputs.
+func permissionsUseInputs(scope *Scope, inputs []map[string]any) (bool, error) {
+	permissions := proto.PermissionsWithExpression(proto.PermissionsForAction(scope.Schema, scope.Action))
+	for _, permission := range permissions {
+		expression, err := parser.ParseExpression(permission.GetExpression().GetSource())
+		if err != nil {
+			return false, err
+		}
+
+		operands, err := resolve.IdentOperands(expression)
+		if err != nil {
+			return false, err
+		}
+
+		for _, operand := range operands {
+			for _, input := range inputs {
+				if _, ok := input[operand.Fragments[0]]; ok {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// authoriseBulkItemRow authorises an item of a bulk update or delete against the record it would write, which is
+// found by the item's where inputs.
+func authoriseBulkItemRow(scope *Scope, item map[string]any, where map[string]any, opts []QueryBuilderOption) error {
+	query := NewQuery(scope.Model, opts...)
+	err := query.ApplyImplicitFilters(scope, where)
+	if err != nil {
+		return err
+	}
+
+	err = query.applyExpressionFilters(scope, where)
+	if err != nil {
+		return err
+	}
+
+	query.ExcludeSoftDeleted()
+	query.Select(IdField())
+	query.DistinctOn(IdField())
+	row, err := query.SelectStatement().ExecuteToSingle(scope.Context)
+	if err != nil {
+		return err
+	}
+
+	rowsToAuthorise := []map[string]any{}
+	if row != nil {
+		rowsToAuthorise = append(rowsToAuthorise, row)
+	}
+
+	isAuthorised, err := AuthoriseAction(scope, item, rowsToAuthorise)
+	if err != nil {
+		return err
+	}
+
+	if !isAuthorised {
+		return common.NewPermissionError()
+	}
+
+	return nil
+}
+
+// if we have any files in our results we need to transform them to the object structure required
+func transformBulkFileResponses(scope *Scope, rows []map[string]any) ([]map[string]any, error) {
+	if !scope.Model.HasFiles() {
+		return rows, nil
+	}
+
+	var err error
+	for i, row := range rows {
+		rows[i], err = transformModelFileResponses(scope.Context, scope.Model, row)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rows, nil
+}
+
+// bulkItemError prefixes the message of an error caused by one of the items of a bulk action with the item's index,
+// so that the client knows which item failed.
+func bulkItemError(i int, err error) error {
+	var runtimeErr common.RuntimeError
+	if errors.As(err, &runtimeErr) {
+		runtimeErr.Message = fmt.Sprintf("items[%d]: %s", i, runtimeErr.Message)
+		return runtimeErr
+	}
+
+	return fmt.Errorf("items[%d]: %w", i, err)
+}
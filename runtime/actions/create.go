@@ -98,18 +98,28 @@ func Create(scope *Scope, input map[string]any) (res map[string]any, err error)
 }
 
 func GenerateCreateStatement(query *QueryBuilder, scope *Scope, input map[string]any) (*Statement, error) {
-	err := query.captureWriteValues(scope, input)
+	err := query.applyCreate(scope, input)
 	if err != nil {
 		return nil, err
 	}
 
+	return query.InsertStatement(scope.Context), nil
+}
+
+// applyCreate captures the values to insert for the inputs of a create.
+func (query *QueryBuilder) applyCreate(scope *Scope, input map[string]any) error {
+	err := query.captureWriteValues(scope, input)
+	if err != nil {
+		return err
+	}
+
 	err = query.captureSetValues(scope, input)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Return the inserted row
 	query.AppendReturning(AllFields())
 
-	return query.InsertStatement(scope.Context), nil
+	return nil
 }
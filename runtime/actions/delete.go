@@ -6,6 +6,7 @@ import (
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/common"
 	"github.com/teamkeel/keel/runtime/locale"
+	"github.com/teamkeel/keel/schema/parser"
 )
 
 func Delete(scope *Scope, input map[string]any) (res *string, err error) {
@@ -43,6 +44,7 @@ func Delete(scope *Scope, input map[string]any) (res *string, err error) {
 		if err != nil {
 			return nil, err
 		}
+		authQuery.ExcludeSoftDeleted()
 		authQuery.Select(IdField())
 		authQuery.DistinctOn(IdField())
 		rows, err := authQuery.SelectStatement().ExecuteToSingle(scope.Context)
@@ -84,17 +86,38 @@ func Delete(scope *Scope, input map[string]any) (res *string, err error) {
 }
 
 func GenerateDeleteStatement(query *QueryBuilder, scope *Scope, input map[string]any) (*Statement, error) {
-	err := query.ApplyImplicitFilters(scope, input)
+	err := query.applyDelete(scope, input)
 	if err != nil {
 		return nil, err
 	}
 
+	if scope.Model.GetSoftDelete() {
+		return query.UpdateStatement(scope.Context), nil
+	}
+
+	return query.DeleteStatement(scope.Context), nil
+}
+
+// applyDelete captures the filters to find the row for the inputs of a delete. Models with @softDelete are
+// deleted by setting deletedAt, which excludes them from any further queries, and so must then be written
+// with an UPDATE rather than a DELETE.
+func (query *QueryBuilder) applyDelete(scope *Scope, input map[string]any) error {
+	err := query.ApplyImplicitFilters(scope, input)
+	if err != nil {
+		return err
+	}
+
 	err = query.applyExpressionFilters(scope, input)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	query.AppendReturning(IdField())
 
-	return query.DeleteStatement(scope.Context), nil
+	if scope.Model.GetSoftDelete() {
+		query.ExcludeSoftDeleted()
+		query.AddWriteValue(Field(parser.FieldNameDeletedAt), Raw("NOW()"))
+	}
+
+	return nil
 }
@@ -41,6 +41,8 @@ func resolveEmbeddedData(ctx context.Context, schema *proto.Schema, sourceModel
 		return nil, fmt.Errorf("applying sql where: %w", err)
 	}
 
+	dbQuery.ExcludeSoftDeleted()
+
 	switch {
 	case field.IsBelongsTo():
 		dbQuery.Join(
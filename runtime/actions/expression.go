@@ -63,9 +63,11 @@ func (query *QueryBuilder) AddJoinFromFragments(schema *proto.Schema, fragments
 			rightOperand = ExpressionField(fragments[:i], primaryKey, false)
 		}
 
-		query.Join(relatedEntityName, leftOperand, rightOperand)
+		// Rows which have been soft deleted are no longer related to anything, so cannot be matched through the join
+		entity = schema.FindEntity(relatedEntityName)
+		model, isModel := entity.(*proto.Model)
 
-		entity = schema.FindEntity(relatedEntityField.GetType().GetEntityName().GetValue())
+		query.join(relatedEntityName, leftOperand, rightOperand, isModel && model.GetSoftDelete())
 	}
 
 	return nil
@@ -168,6 +170,11 @@ func generateOperandForCtx(ctx context.Context, schema *proto.Schema, fragments
 	case expressions.IsContextIsAuthenticatedField(ident):
 		isAuthenticated := auth.IsAuthenticated(ctx)
 		return Value(isAuthenticated), nil
+	case expressions.IsContextIsServiceAccountField(ident):
+		isServiceAccount := auth.IsServiceAccount(ctx)
+		return Value(isServiceAccount), nil
+	case expressions.IsContextScopesField(ident):
+		return Value(auth.GetScopes(ctx)), nil
 	case expressions.IsContextNowField(ident):
 		return Value(runtimectx.GetNow()), nil
 	case expressions.IsContextEnvField(ident):
@@ -2,20 +2,26 @@ package actions
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/oauth"
 	"github.com/teamkeel/keel/runtime/runtimectx"
 	"github.com/teamkeel/keel/storage"
 )
 
 // handleFileUploads will check the inputs for any file uploads for the scope's action and upload them
 //
-// Currently inline files will be provided as input in a data-url format, we will store these files and change the inputs
-// to a structure that will be then saved in the db.
+// Inline files will be provided as input in a data-url format, we will store these files and change the inputs
+// to a structure that will be then saved in the db. Alternatively, the input can be the key of a file which was
+// uploaded directly to storage, in which case the upload is completed instead.
 func handleFileUploads(scope *Scope, inputs map[string]any) (map[string]any, error) {
-	// we handle file uploads for UPDATE and CREATE actions
-	if scope.Action.GetType() != proto.ActionType_ACTION_TYPE_UPDATE && scope.Action.GetType() != proto.ActionType_ACTION_TYPE_CREATE {
+	// we handle file uploads for UPDATE, CREATE and UPSERT actions, and for each item of their bulk actions
+	if scope.Action.GetType() != proto.ActionType_ACTION_TYPE_UPDATE && scope.Action.GetType() != proto.ActionType_ACTION_TYPE_CREATE && scope.Action.GetType() != proto.ActionType_ACTION_TYPE_UPSERT &&
+		scope.Action.GetType() != proto.ActionType_ACTION_TYPE_CREATE_MANY && scope.Action.GetType() != proto.ActionType_ACTION_TYPE_UPDATE_MANY {
 		return inputs, nil
 	}
 	// check if the values input message for the action has any files
@@ -46,7 +52,7 @@ func handleFileUploads(scope *Scope, inputs map[string]any) (map[string]any, err
 
 					fileInfos := []any{}
 					for _, d := range data {
-						fi, err := storer.Store(scope.Context, d)
+						fi, err := storeFileInput(scope, storer, field.GetName(), d)
 						if err != nil {
 							return inputs, fmt.Errorf("storing file: %w", err)
 						}
@@ -60,7 +66,7 @@ func handleFileUploads(scope *Scope, inputs map[string]any) (map[string]any, err
 						return inputs, fmt.Errorf("invalid input for field: %s", field.GetName())
 					}
 
-					fi, err := storer.Store(scope.Context, data)
+					fi, err := storeFileInput(scope, storer, field.GetName(), data)
 					if err != nil {
 						return inputs, fmt.Errorf("storing file: %w", err)
 					}
@@ -74,6 +80,76 @@ func handleFileUploads(scope *Scope, inputs map[string]any) (map[string]any, err
 	return inputs, nil
 }
 
+// completeFunctionFileUploads completes the uploads given as File inputs to a function, replacing each upload key with
+// the stored file's storage.FileInfo. Files given as data URLs are left for the function to store itself.
+func completeFunctionFileUploads(scope *Scope, message *proto.Message, inputs map[string]any) (map[string]any, error) {
+	var storer storage.Storer
+	for _, field := range message.GetFields() {
+		if field.GetType().GetType() != proto.Type_TYPE_FILE {
+			continue
+		}
+
+		in, ok := inputs[field.GetName()]
+		if !ok || in == nil {
+			continue
+		}
+
+		values, ok := in.([]any)
+		if !field.GetType().GetRepeated() {
+			values = []any{in}
+		} else if !ok {
+			return inputs, fmt.Errorf("invalid input for field: %s", field.GetName())
+		}
+
+		for i, v := range values {
+			key, ok := v.(string)
+			if !ok || strings.HasPrefix(key, "data:") {
+				continue
+			}
+
+			if storer == nil {
+				var err error
+				storer, err = runtimectx.GetStorage(scope.Context)
+				if err != nil {
+					return inputs, fmt.Errorf("invalid file storage: %w", err)
+				}
+			}
+
+			fi, err := storeFileInput(scope, storer, field.GetName(), key)
+			if err != nil {
+				return inputs, err
+			}
+			values[i] = fi
+		}
+
+		if !field.GetType().GetRepeated() {
+			inputs[field.GetName()] = values[0]
+		}
+	}
+
+	return inputs, nil
+}
+
+// storeFileInput stores the file given as an input, which is either a data URL or the key of an upload. Upload keys
+// are only accepted from the caller who requested the upload, for the action and input it was requested for.
+func storeFileInput(scope *Scope, storer storage.Storer, fieldName string, input string) (storage.FileInfo, error) {
+	if strings.HasPrefix(input, "data:") {
+		return storer.Store(scope.Context, input)
+	}
+
+	key, err := oauth.ValidateUploadToken(scope.Context, input, scope.Action.GetName(), fieldName)
+	if err != nil {
+		return storage.FileInfo{}, err
+	}
+
+	fi, err := storer.CompleteUpload(scope.Context, key)
+	if errors.Is(err, storage.ErrFileNotFound) {
+		return storage.FileInfo{}, common.NewInputMalformedError(fmt.Sprintf("no file has been uploaded for the input '%s'", fieldName))
+	}
+
+	return fi, err
+}
+
 // transformModelFileResponses will take the results for the given scope's action execution and parse and transform the file responses.
 func transformModelFileResponses(ctx context.Context, model *proto.Model, results map[string]any) (map[string]any, error) {
 	if model == nil {
@@ -217,6 +217,11 @@ func generateOperandForCtxQuery(ctx context.Context, schema *proto.Schema, fragm
 	case expressions.IsContextIsAuthenticatedField(ident):
 		isAuthenticated := auth.IsAuthenticated(ctx)
 		return Value(isAuthenticated), nil
+	case expressions.IsContextIsServiceAccountField(ident):
+		isServiceAccount := auth.IsServiceAccount(ctx)
+		return Value(isServiceAccount), nil
+	case expressions.IsContextScopesField(ident):
+		return Value(auth.GetScopes(ctx)), nil
 	case expressions.IsContextNowField(ident):
 		return Value(runtimectx.GetNow()), nil
 	case expressions.IsContextEnvField(ident):
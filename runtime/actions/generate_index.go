@@ -0,0 +1,197 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/iancoleman/strcase"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/expressions/resolve"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+// GenerateIndexExpression visits an @index expression and generates the SQL expression for the index definition.
+// Index definitions cannot have parameters, so values are embedded as literals.
+func GenerateIndexExpression(schema *proto.Schema, model *proto.Model) resolve.Visitor[string] {
+	return &indexExpressionGen{
+		schema: schema,
+		model:  model,
+	}
+}
+
+var _ resolve.Visitor[string] = new(indexExpressionGen)
+
+type indexExpressionGen struct {
+	schema *proto.Schema
+	model  *proto.Model
+	sql    strings.Builder
+	// Whether the last operand was text, in which case + is concatenation
+	isText bool
+}
+
+func (v *indexExpressionGen) StartTerm(nested bool) error {
+	if nested {
+		v.sql.WriteString("(")
+	}
+	return nil
+}
+
+func (v *indexExpressionGen) EndTerm(nested bool) error {
+	if nested {
+		v.sql.WriteString(")")
+	}
+	return nil
+}
+
+func (v *indexExpressionGen) StartFunction(name string) error {
+	return fmt.Errorf("functions are not supported in index expressions: %s", name)
+}
+
+func (v *indexExpressionGen) EndFunction() error {
+	return nil
+}
+
+func (v *indexExpressionGen) StartArgument(num int) error {
+	return nil
+}
+
+func (v *indexExpressionGen) EndArgument() error {
+	return nil
+}
+
+func (v *indexExpressionGen) VisitAnd() error {
+	v.sql.WriteString(" AND ")
+	return nil
+}
+
+func (v *indexExpressionGen) VisitOr() error {
+	v.sql.WriteString(" OR ")
+	return nil
+}
+
+func (v *indexExpressionGen) VisitNot() error {
+	v.sql.WriteString("NOT ")
+	return nil
+}
+
+func (v *indexExpressionGen) VisitOperator(op string) error {
+	// Equality uses = rather than IS NOT DISTINCT FROM so that the planner can match
+	// the conditions of a query to those of a partial index
+	sqlOp := map[string]string{
+		operators.Add:           "+",
+		operators.Subtract:      "-",
+		operators.Multiply:      "*",
+		operators.Divide:        "/",
+		operators.Equals:        "=",
+		operators.NotEquals:     "<>",
+		operators.Greater:       ">",
+		operators.GreaterEquals: ">=",
+		operators.Less:          "<",
+		operators.LessEquals:    "<=",
+		operators.In:            "IN",
+	}[op]
+
+	if op == operators.Add && v.isText {
+		sqlOp = "||"
+	}
+
+	if sqlOp == "" {
+		return fmt.Errorf("unsupported operator: %s", op)
+	}
+
+	v.sql.WriteString(fmt.Sprintf(" %s ", sqlOp))
+	return nil
+}
+
+func (v *indexExpressionGen) VisitLiteral(value any) error {
+	// Comparisons with null must use IS and IS NOT
+	if value == nil {
+		sql := v.sql.String()
+		switch {
+		case strings.HasSuffix(sql, " = "):
+			sql = strings.TrimSuffix(sql, " = ") + " IS "
+		case strings.HasSuffix(sql, " <> "):
+			sql = strings.TrimSuffix(sql, " <> ") + " IS NOT "
+		}
+		v.sql.Reset()
+		v.sql.WriteString(sql)
+	}
+
+	if values, ok := value.([]any); ok {
+		literals := []string{}
+		for _, value := range values {
+			literal, err := indexLiteral(value)
+			if err != nil {
+				return err
+			}
+			literals = append(literals, literal)
+		}
+		v.sql.WriteString(fmt.Sprintf("(%s)", strings.Join(literals, ", ")))
+		return nil
+	}
+
+	literal, err := indexLiteral(value)
+	if err != nil {
+		return err
+	}
+
+	_, v.isText = value.(string)
+	v.sql.WriteString(literal)
+	return nil
+}
+
+func (v *indexExpressionGen) VisitIdent(ident *parser.ExpressionIdent) error {
+	if enum := v.schema.FindEnum(ident.Fragments[0]); enum != nil && len(ident.Fragments) == 2 {
+		v.isText = false
+		v.sql.WriteString(db.QuoteLiteral(ident.Fragments[1]))
+		return nil
+	}
+
+	if len(ident.Fragments) != 2 || ident.Fragments[0] != strcase.ToLowerCamel(v.model.GetName()) {
+		return fmt.Errorf("index expressions can only use the fields of %s: %s", v.model.GetName(), ident.String())
+	}
+
+	field := v.model.FindField(ident.Fragments[1])
+	if field == nil || field.GetType().GetType() == proto.Type_TYPE_ENTITY {
+		return fmt.Errorf("field not found on %s: %s", v.model.GetName(), ident.Fragments[1])
+	}
+
+	v.isText = field.GetType().GetType() == proto.Type_TYPE_STRING || field.GetType().GetType() == proto.Type_TYPE_MARKDOWN
+	v.sql.WriteString(sqlQuote(strcase.ToSnake(field.GetName())))
+	return nil
+}
+
+func (v *indexExpressionGen) VisitIdentArray(idents []*parser.ExpressionIdent) error {
+	values := []string{}
+	for _, ident := range idents {
+		if v.schema.FindEnum(ident.Fragments[0]) == nil || len(ident.Fragments) != 2 {
+			return errors.New("only enum values are supported in index expression arrays")
+		}
+		values = append(values, db.QuoteLiteral(ident.Fragments[1]))
+	}
+
+	v.sql.WriteString(fmt.Sprintf("(%s)", strings.Join(values, ", ")))
+	return nil
+}
+
+func (v *indexExpressionGen) Result() (string, error) {
+	return v.sql.String(), nil
+}
+
+func indexLiteral(value any) (string, error) {
+	switch val := value.(type) {
+	case int64, float64:
+		return fmt.Sprintf("%v", val), nil
+	case string:
+		return db.QuoteLiteral(val), nil
+	case bool:
+		return fmt.Sprintf("%t", val), nil
+	case nil:
+		return "NULL", nil
+	default:
+		return "", fmt.Errorf("unsupported literal type: %T", value)
+	}
+}
@@ -0,0 +1,95 @@
+package actions_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/teamkeel/keel/expressions/resolve"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/schema"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/reader"
+	"github.com/test-go/testify/assert"
+)
+
+const indexTestSchema = `
+model Item {
+	fields {
+		name Text
+		price Decimal
+		quantity Number
+		isActive Boolean
+		orderStatus OrderStatus?
+	}
+	@index(expression: %s)
+}
+enum OrderStatus {
+	Pending
+	Delivered
+}`
+
+var indexTestCases = []struct {
+	name        string
+	expression  string
+	expectedSql string
+}{
+	{
+		name:        "arithmetic",
+		expression:  "item.price * item.quantity",
+		expectedSql: `"price" * "quantity"`,
+	},
+	{
+		name:        "text concatenation",
+		expression:  `item.name + " it's"`,
+		expectedSql: `"name" || ' it''s'`,
+	},
+	{
+		name:        "equals enum",
+		expression:  "item.orderStatus == OrderStatus.Pending",
+		expectedSql: `"order_status" = 'Pending'`,
+	},
+	{
+		name:        "in enums",
+		expression:  "item.orderStatus in [OrderStatus.Pending, OrderStatus.Delivered]",
+		expectedSql: `"order_status" IN ('Pending', 'Delivered')`,
+	},
+	{
+		name:        "not equals null",
+		expression:  "item.orderStatus != null",
+		expectedSql: `"order_status" IS NOT NULL`,
+	},
+	{
+		name:        "logical operators",
+		expression:  "item.isActive && (item.quantity > 10 || item.price <= 2.5)",
+		expectedSql: `"is_active" AND ("quantity" > 10 OR "price" <= 2.5)`,
+	},
+}
+
+func TestGenerateIndexExpression(t *testing.T) {
+	t.Parallel()
+	for _, testCase := range indexTestCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			builder := &schema.Builder{}
+			schema, err := builder.MakeFromInputs(&reader.Inputs{
+				SchemaFiles: []*reader.SchemaFile{
+					{
+						Contents: fmt.Sprintf(indexTestSchema, testCase.expression),
+						FileName: "schema.keel",
+					},
+				},
+			})
+			assert.NoError(t, err)
+
+			model := schema.FindModel("Item")
+			expression, err := parser.ParseExpression(model.GetIndexes()[0].GetExpression().GetSource())
+			assert.NoError(t, err)
+
+			sql, err := resolve.RunCelVisitor(expression, actions.GenerateIndexExpression(schema, model))
+			assert.NoError(t, err)
+
+			assert.Equal(t, testCase.expectedSql, sql)
+		})
+	}
+}
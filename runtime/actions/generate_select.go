@@ -93,7 +93,7 @@ func (v *setQueryGen) VisitIdent(ident *parser.ExpressionIdent) error {
 
 	if model != nil && expressions.IsEntityDbColumn(model, normalised) && len(normalised) > 2 {
 		switch v.action.GetType() {
-		case proto.ActionType_ACTION_TYPE_CREATE:
+		case proto.ActionType_ACTION_TYPE_CREATE, proto.ActionType_ACTION_TYPE_UPSERT, proto.ActionType_ACTION_TYPE_CREATE_MANY:
 			// This section performs a field lookup on a to-be related model as an inline query
 
 			field := model.FindField(ident.Fragments[1])
@@ -128,7 +128,7 @@ func (v *setQueryGen) VisitIdent(ident *parser.ExpressionIdent) error {
 			}
 
 			v.operand = InlineQuery(query, selectField)
-		case proto.ActionType_ACTION_TYPE_UPDATE:
+		case proto.ActionType_ACTION_TYPE_UPDATE, proto.ActionType_ACTION_TYPE_UPDATE_MANY:
 			// This section performs a field lookup on a related model as an inline query
 
 			query := NewQuery(v.model)
@@ -159,7 +159,7 @@ func (v *setQueryGen) VisitIdent(ident *parser.ExpressionIdent) error {
 			v.operand = InlineQuery(query, selectField)
 		}
 	} else {
-		if v.action.GetType() == proto.ActionType_ACTION_TYPE_UPDATE && v.inputs["values"] != nil {
+		if (v.action.GetType() == proto.ActionType_ACTION_TYPE_UPDATE || v.action.GetType() == proto.ActionType_ACTION_TYPE_UPDATE_MANY) && v.inputs["values"] != nil {
 			v.inputs = v.inputs["values"].(map[string]any)
 		}
 
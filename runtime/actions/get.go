@@ -102,6 +102,8 @@ func GenerateGetStatement(query *QueryBuilder, scope *Scope, input map[string]an
 		return nil, err
 	}
 
+	query.ExcludeSoftDeleted()
+
 	// Select all columns and distinct on id
 	query.Select(AllFields())
 	query.DistinctOn(IdField())
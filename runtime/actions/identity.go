@@ -2,6 +2,7 @@ package actions
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/auth"
@@ -33,10 +34,13 @@ func FindIdentityById(ctx context.Context, schema *proto.Schema, id string) (aut
 	return result, nil
 }
 
+// FindIdentityByEmail finds the identity with the email address, whatever its case. Identities created before emails
+// were lower-cased may have the same address in different cases, in which case the one with the exact email is found.
 func FindIdentityByEmail(ctx context.Context, schema *proto.Schema, email string, issuer string) (auth.Identity, error) {
 	identityModel := schema.FindModel(parser.IdentityModelName)
 	query := NewQuery(identityModel)
-	err := query.Where(Field("email"), Equals, Value(email))
+	emailColumn := Raw(fmt.Sprintf("LOWER(%s)", Field(parser.IdentityFieldNameEmail).toSqlOperandString(query)))
+	err := query.Where(emailColumn, Equals, Value(oauth.NormaliseEmail(email)))
 	if err != nil {
 		return nil, err
 	}
@@ -47,16 +51,21 @@ func FindIdentityByEmail(ctx context.Context, schema *proto.Schema, email string
 	}
 
 	query.Select(AllFields())
-	result, err := query.SelectStatement().ExecuteToSingle(ctx)
-
+	results, _, _, err := query.SelectStatement().ExecuteToMany(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	if result == nil {
+	if len(results) == 0 {
 		return nil, nil
 	}
 
-	return result, nil
+	for _, result := range results {
+		if result[parser.IdentityFieldNameEmail] == email {
+			return result, nil
+		}
+	}
+
+	return results[0], nil
 }
 
 func FindIdentityByExternalId(ctx context.Context, schema *proto.Schema, externalId string, issuer string) (auth.Identity, error) {
@@ -90,7 +99,7 @@ func CreateIdentity(ctx context.Context, schema *proto.Schema, email string, pas
 
 	query := NewQuery(identityModel)
 	query.AddWriteValues(map[string]*QueryOperand{
-		"email":    Value(email),
+		"email":    Value(oauth.NormaliseEmail(email)),
 		"password": Value(password),
 		"issuer":   Value(issuer),
 	})
@@ -105,6 +114,54 @@ func CreateIdentity(ctx context.Context, schema *proto.Schema, email string, pas
 	return result, nil
 }
 
+// CreateIdentityWithVerifiedEmail creates an identity without a password for an email address which has been
+// proven to belong to the identity, such as by a passwordless login.
+func CreateIdentityWithVerifiedEmail(ctx context.Context, schema *proto.Schema, email string, issuer string) (auth.Identity, error) {
+	identityModel := schema.FindModel(parser.IdentityModelName)
+
+	query := NewQuery(identityModel)
+	query.AddWriteValues(map[string]*QueryOperand{
+		parser.IdentityFieldNameEmail:         Value(oauth.NormaliseEmail(email)),
+		parser.IdentityFieldNameEmailVerified: Value(true),
+		parser.IdentityFieldNameIssuer:        Value(issuer),
+	})
+	query.Select(AllFields())
+	query.AppendReturning(IdField())
+
+	result, err := query.InsertStatement(ctx).ExecuteToSingle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// VerifyIdentityEmail marks the email of the identity as verified, as long as it is still the given email.
+func VerifyIdentityEmail(ctx context.Context, schema *proto.Schema, identityId string, email string) (auth.Identity, error) {
+	identityModel := schema.FindModel(parser.IdentityModelName)
+
+	query := NewQuery(identityModel)
+	err := query.Where(IdField(), Equals, Value(identityId))
+	if err != nil {
+		return nil, err
+	}
+	query.And()
+	err = query.Where(Field(parser.IdentityFieldNameEmail), Equals, Value(email))
+	if err != nil {
+		return nil, err
+	}
+
+	query.AddWriteValue(Field(parser.IdentityFieldNameEmailVerified), Value(true))
+	query.Select(AllFields())
+
+	result, err := query.UpdateStatement(ctx).ExecuteToSingle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func CreateIdentityWithClaims(ctx context.Context, schema *proto.Schema, externalId string, issuer string, standardClaims *oauth.IdTokenClaims, customClaims map[string]any) (auth.Identity, error) {
 	ctx, span := tracer.Start(ctx, "Create Identity")
 	defer span.End()
@@ -63,6 +63,15 @@ func (query *QueryBuilder) applyImplicitFiltersFromMessage(scope *Scope, message
 			return fmt.Errorf("'%s' input value %v is not in correct format", input.GetName(), value)
 		}
 
+		// Vector inputs are not compared using operators, but by their distance from the nearest input
+		if field.GetType().GetType() == proto.Type_TYPE_VECTOR {
+			err := query.whereVectorDistance(field, valueMap)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
 		for operatorStr, operand := range valueMap {
 			var operator ActionOperator
 			var err error
@@ -141,6 +150,12 @@ func (query *QueryBuilder) applyRequestOrdering(orderBy []any) {
 	}
 }
 
+// Returns the search text of a list action, or an empty string if the results shouldn't be searched.
+func searchInput(input map[string]any) string {
+	search, _ := input["search"].(string)
+	return strings.TrimSpace(search)
+}
+
 func List(scope *Scope, input map[string]any) (map[string]any, error) {
 	permissions := proto.PermissionsForAction(scope.Schema, scope.Action)
 
@@ -249,6 +264,13 @@ func GenerateListStatement(query *QueryBuilder, scope *Scope, input map[string]a
 		return nil, nil, err
 	}
 
+	search := searchInput(input)
+	if search != "" {
+		query.whereSearch(search)
+	}
+
+	query.ExcludeSoftDeleted()
+
 	err = query.applySchemaOrdering(scope)
 	if err != nil {
 		return nil, nil, err
@@ -256,11 +278,31 @@ func GenerateListStatement(query *QueryBuilder, scope *Scope, input map[string]a
 
 	query.applyRequestOrdering(orderBy)
 
+	// Results are ordered nearest first for vector inputs, unless the action or request specifies an order
+	nearest := len(query.orderBy) == 0 && search == ""
+	err = query.applyNearestOrdering(scope, where)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Search results are ranked by relevance, unless the action or request specifies an order
+	if search != "" {
+		query.orderBySearchRank(search)
+	}
+
 	page, err := ParsePage(input)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	// The first page of the nearest rows is kept simple so that a vector index can be used
+	if nearest && query.isNearestOnly(page) {
+		query.Select(AllFields())
+		query.applyNearestPaging(page)
+
+		return query.SelectStatement(), &page, nil
+	}
+
 	// Select all columns from this table and distinct on id
 	query.DistinctOn(IdField())
 	query.Select(AllFields())
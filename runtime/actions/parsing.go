@@ -8,6 +8,7 @@ import (
 	"github.com/relvacode/iso8601"
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/types"
+	"github.com/teamkeel/keel/storage"
 )
 
 // TransformInputs will traverse through the input data structure and will ensure that values are correctly typed.
@@ -209,6 +210,15 @@ var toInlineFileForFunctions = func(value any) (map[string]any, error) {
 			"__typename": "InlineFile",
 			"dataURL":    t,
 		}, nil
+	case storage.FileInfo:
+		// A file which was uploaded directly to storage, and has already been stored
+		return map[string]any{
+			"__typename":  "File",
+			"key":         t.Key,
+			"filename":    t.Filename,
+			"contentType": t.ContentType,
+			"size":        t.Size,
+		}, nil
 	default:
 		return nil, fmt.Errorf("incompatible type %T parsing to inline file for functions", t)
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/actions"
 	"github.com/teamkeel/keel/runtime/types"
+	"github.com/teamkeel/keel/storage"
 )
 
 func TestParsing(t *testing.T) {
@@ -316,3 +317,51 @@ message FileResponse {
 	assert.Equal(t, "InlineFile", file["__typename"])
 	assert.Equal(t, dataUrl, file["dataURL"])
 }
+
+func TestParsingCustomFunctionUploadedFileInputs(t *testing.T) {
+	t.Parallel()
+	schema := `
+model Person {
+	fields {
+		avatar File
+	}
+	actions {
+		write setAvatar(FileInput) returns (FileResponse)
+	}
+}
+message FileInput {
+    file File
+}
+
+message FileResponse {
+    filename Text
+}
+`
+
+	scope, _, action, err := generateQueryScope(t.Context(), schema, "setAvatar")
+	assert.NoError(t, err)
+
+	// An uploaded file has already been stored by the time the inputs are transformed
+	data := map[string]any{
+		"file": storage.FileInfo{
+			Key:         "2ZPqVyJmMnvbVjm3bTdWxvAqAgb",
+			Filename:    "my-avatar.png",
+			ContentType: "image/png",
+			Size:        2024,
+		},
+	}
+
+	message := scope.Schema.FindMessage(action.GetInputMessageName())
+	isFunction := action.GetImplementation() == proto.ActionImplementation_ACTION_IMPLEMENTATION_CUSTOM
+
+	parsed, err := actions.TransformInputs(scope.Schema, message, data, isFunction)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"__typename":  "File",
+		"key":         "2ZPqVyJmMnvbVjm3bTdWxvAqAgb",
+		"filename":    "my-avatar.png",
+		"contentType": "image/png",
+		"size":        2024,
+	}, parsed["file"])
+}
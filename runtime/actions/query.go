@@ -3,6 +3,7 @@ package actions
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -220,6 +221,15 @@ func (o *QueryOperand) toSqlOperandString(query *QueryBuilder) string {
 		if table == "" {
 			table = query.table
 		}
+		// The search document of a model with @searchable is internal, so its columns are listed rather than
+		// selecting all of them
+		if o.column == "*" && table == query.table {
+			if model, ok := query.Entity.(*proto.Model); ok && model.IsSearchable() {
+				return strings.Join(lo.Map(modelColumns(model), func(c string, _ int) string {
+					return sqlQuote(table, c)
+				}), ", ")
+			}
+		}
 		return sqlQuote(table, o.column)
 	case o.IsNull():
 		return "NULL"
@@ -302,6 +312,12 @@ type QueryBuilder struct {
 	args []any
 	// The graph of rows to be written during an INSERT or UPDATE.
 	writeValues *Row
+	// The unique fields in ON CONFLICT, which update the existing row rather than failing an INSERT.
+	conflictFields []string
+	// Whether the selected rows are locked until the end of the transaction.
+	forUpdate bool
+	// The prefix of the aliases of common table expressions, so that the statements of many queries can be combined.
+	aliasPrefix string
 	// The type of SQL join to use.
 	joinType JoinType
 	// The timezone to be used if we're dealing with relative dates (e.g. DATE_TRUNC("day", NOW()))
@@ -408,6 +424,15 @@ func NewQuery(entity proto.Entity, opts ...QueryBuilderOption) *QueryBuilder {
 	return qb
 }
 
+// Returns the options of the query builder, for creating other queries with the same options.
+func (query *QueryBuilder) options() []QueryBuilderOption {
+	opts := []QueryBuilderOption{WithJoinType(query.joinType), WithTimezone(query.timezone)}
+	if query.embedLiterals {
+		opts = append(opts, EmbedLiterals())
+	}
+	return opts
+}
+
 // Creates a copy of the query builder.
 func (query *QueryBuilder) Copy() *QueryBuilder {
 	return &QueryBuilder{
@@ -424,6 +449,16 @@ func (query *QueryBuilder) Copy() *QueryBuilder {
 	}
 }
 
+// Updates the existing row, rather than failing, when an INSERT conflicts on these unique fields.
+func (query *QueryBuilder) OnConflictUpdate(fields ...string) {
+	query.conflictFields = append(query.conflictFields, fields...)
+}
+
+// Locks the selected rows with FOR UPDATE, so that they cannot be changed by others until the transaction ends.
+func (query *QueryBuilder) ForUpdate() {
+	query.forUpdate = true
+}
+
 // Includes a value to be written during an INSERT or UPDATE.
 func (query *QueryBuilder) AddWriteValue(operand *QueryOperand, value *QueryOperand) {
 	query.writeValues.model = query.Entity
@@ -522,12 +557,84 @@ func trimRhsOperators(filters []string) []string {
 	return lo.DropRightWhile(filters, func(s string) bool { return s == "OR" || s == "AND" })
 }
 
+// Excludes rows which have been soft deleted, if the base model has @softDelete.
+func (query *QueryBuilder) ExcludeSoftDeleted() {
+	query.whereSoftDeleted(false)
+}
+
+// Includes a condition on whether rows have been soft deleted, if the base model has @softDelete.
+// The condition is ANDed with all existing filters, which are parenthesised so that any ORs
+// within them cannot include rows which do not match the condition.
+func (query *QueryBuilder) whereSoftDeleted(deleted bool) {
+	model, ok := query.Entity.(*proto.Model)
+	if !ok || !model.GetSoftDelete() {
+		return
+	}
+
+	operator := "IS NULL"
+	if deleted {
+		operator = "IS NOT NULL"
+	}
+	condition := fmt.Sprintf("%s %s", Field(parser.FieldNameDeletedAt).toSqlOperandString(query), operator)
+
+	filters := trimRhsOperators(query.filters)
+	if len(filters) == 0 {
+		query.filters = []string{condition}
+		return
+	}
+
+	query.filters = append([]string{condition, "AND", "("}, append(filters, ")")...)
+}
+
+// Filters rows to those matching the search text, if the base model has @searchable.
+func (query *QueryBuilder) whereSearch(text string) {
+	model, ok := query.Entity.(*proto.Model)
+	if !ok || !model.IsSearchable() {
+		return
+	}
+
+	query.And()
+	query.filters = append(query.filters, fmt.Sprintf("%s @@ %s", Field(proto.SearchColumnName).toSqlOperandString(query), searchQuery(text)))
+	query.And()
+}
+
+// Orders rows by how relevant they are to the search text, if the base model has @searchable.
+// This is appended to any existing ordering, so that it only breaks ties.
+func (query *QueryBuilder) orderBySearchRank(text string) {
+	model, ok := query.Entity.(*proto.Model)
+	if !ok || !model.IsSearchable() {
+		return
+	}
+
+	rank := fmt.Sprintf("ts_rank(%s, %s)", Field(proto.SearchColumnName).toSqlOperandString(query), searchQuery(text))
+	query.AppendOrderBy(Raw(rank), "DESC")
+}
+
+// Generates the tsquery for some search text. The rank is used in the ordering, the paging window and
+// the cursor filter, so the text is embedded in the SQL rather than passed as an argument each time.
+// It is base64 encoded so that it can't contain quotes or placeholders. The text search configuration must
+// be the same as the one the search document was built with.
+func searchQuery(text string) string {
+	return fmt.Sprintf("websearch_to_tsquery('%s', convert_from(decode('%s', 'base64'), 'UTF8'))", proto.SearchTextConfig, base64.StdEncoding.EncodeToString([]byte(text)))
+}
+
 // Include an JOIN clause.
 func (query *QueryBuilder) Join(joinModel string, joinField *QueryOperand, modelField *QueryOperand) {
+	query.join(joinModel, joinField, modelField, false)
+}
+
+// Include a JOIN clause, which only joins rows of the model which have not been soft deleted if excludeSoftDeleted is set.
+func (query *QueryBuilder) join(joinModel string, joinField *QueryOperand, modelField *QueryOperand, excludeSoftDeleted bool) {
+	condition := fmt.Sprintf("%s = %s", joinField.toSqlOperandString(query), modelField.toSqlOperandString(query))
+	if excludeSoftDeleted {
+		deletedAt := &QueryOperand{table: joinField.table, column: casing.ToSnake(parser.FieldNameDeletedAt)}
+		condition = fmt.Sprintf("%s AND %s IS NULL", condition, deletedAt.toSqlOperandString(query))
+	}
+
 	join := joinClause{
 		table:     sqlQuote(casing.ToSnake(joinModel)),
 		alias:     sqlQuote(joinField.table),
-		condition: fmt.Sprintf("%s = %s", joinField.toSqlOperandString(query), modelField.toSqlOperandString(query)),
+		condition: condition,
 		joinType:  query.joinType,
 	}
 
@@ -542,7 +649,7 @@ func (query *QueryBuilder) AppendOrderBy(operand *QueryOperand, direction string
 	order := &orderClause{field: operand, direction: strings.ToUpper(direction)}
 
 	existing, found := lo.Find(query.orderBy, func(o *orderClause) bool {
-		return o.field.column == order.field.column
+		return o.field.column == order.field.column && o.field.raw == order.field.raw
 	})
 
 	if found {
@@ -591,6 +698,8 @@ func (query *QueryBuilder) SelectFacets(scope *Scope, input map[string]any) erro
 		return nil
 	}
 
+	search := searchInput(input)
+
 	var sql string
 	selects := []string{}
 	ctes := []string{}
@@ -618,6 +727,11 @@ func (query *QueryBuilder) SelectFacets(scope *Scope, input map[string]any) erro
 			return err
 		}
 
+		if search != "" {
+			facetQuery.whereSearch(search)
+		}
+		facetQuery.ExcludeSoftDeleted()
+
 		var statement *Statement
 		var sel string
 		switch field.GetType().GetType() {
@@ -695,23 +809,7 @@ func (query *QueryBuilder) ApplyPaging(page Page) error {
 	// that helps to determine "hasNextPage"
 	query.AppendOrderBy(IdField(), "ASC")
 
-	// Select hasNext clause
-	orderByClausesAsSql := []string{}
-	for _, o := range query.orderBy {
-		orderByClausesAsSql = append(orderByClausesAsSql, fmt.Sprintf("%s %s", o.field.toSqlOperandString(query), o.direction))
-	}
-	hasNext := fmt.Sprintf("CASE WHEN LEAD(%s) OVER (ORDER BY %s) IS NOT NULL THEN true ELSE false END AS hasNext", IdField().toSqlOperandString(query), strings.Join(orderByClausesAsSql, ", "))
-	query.SelectClause(hasNext)
-
-	// We add a subquery to the select list that fetches the total count of records
-	// matching the constraints specified by the main query without the offset/limit applied
-	// This is actually more performant than COUNT(*) OVER() [window function]
-	countQuery, args := query.countQuery()
-	totalResults := fmt.Sprintf("(%s) AS totalCount", countQuery)
-	query.SelectClause(totalResults)
-
-	// Because we are essentially performing the same query again within the subquery, we need to duplicate the query parameters again as they will be used twice in the course of the whole query
-	query.args = append(query.args, args...)
+	query.selectPageInfo()
 
 	// if we have offset pagination..
 	if page.OffsetPagination() {
@@ -741,6 +839,27 @@ func (query *QueryBuilder) ApplyPaging(page Page) error {
 	return nil
 }
 
+// Selects the hasNext and totalCount columns from which the page info is determined.
+func (query *QueryBuilder) selectPageInfo() {
+	// Select hasNext clause
+	orderByClausesAsSql := []string{}
+	for _, o := range query.orderBy {
+		orderByClausesAsSql = append(orderByClausesAsSql, fmt.Sprintf("%s %s", o.field.toSqlOperandString(query), o.direction))
+	}
+	hasNext := fmt.Sprintf("CASE WHEN LEAD(%s) OVER (ORDER BY %s) IS NOT NULL THEN true ELSE false END AS hasNext", IdField().toSqlOperandString(query), strings.Join(orderByClausesAsSql, ", "))
+	query.SelectClause(hasNext)
+
+	// We add a subquery to the select list that fetches the total count of records
+	// matching the constraints specified by the main query without the offset/limit applied
+	// This is actually more performant than COUNT(*) OVER() [window function]
+	countQuery, args := query.countQuery()
+	totalResults := fmt.Sprintf("(%s) AS totalCount", countQuery)
+	query.SelectClause(totalResults)
+
+	// Because we are essentially performing the same query again within the subquery, we need to duplicate the query parameters again as they will be used twice in the course of the whole query
+	query.args = append(query.args, args...)
+}
+
 // Apply forward pagination 'after' cursor filter to the query, or backwards `before` cursor.
 func (query *QueryBuilder) applyCursorFilter(cursor string, isBackwards bool) error {
 	query.And()
@@ -905,7 +1024,12 @@ func (query *QueryBuilder) SelectStatement() *Statement {
 		query.args = append(query.args, *query.offset)
 	}
 
-	sql := fmt.Sprintf("SELECT %s %s FROM %s %s %s %s %s %s %s",
+	forUpdate := ""
+	if query.forUpdate {
+		forUpdate = fmt.Sprintf("FOR UPDATE OF %s", sqlQuote(query.table))
+	}
+
+	sql := fmt.Sprintf("SELECT %s %s FROM %s %s %s %s %s %s %s %s",
 		distinctOn,
 		selection,
 		sqlQuote(query.table),
@@ -914,7 +1038,8 @@ func (query *QueryBuilder) SelectStatement() *Statement {
 		groupBy,
 		orderBy,
 		limit,
-		offset)
+		offset,
+		forUpdate)
 
 	return &Statement{
 		template: cleanSql(sql),
@@ -929,18 +1054,7 @@ func (query *QueryBuilder) InsertStatement(ctx context.Context) *Statement {
 	args := []any{}
 	ctes, args, alias := query.generateInsertCte(ctes, args, query.writeValues, nil, "")
 
-	selection := []string{"*"}
-	if auth.IsAuthenticated(ctx) {
-		identity, _ := auth.GetIdentity(ctx)
-		selection = append(selection, setIdentityIdClause())
-		args = append(args, identity[parser.FieldNameId].(string))
-	}
-
-	spanContext := trace.SpanContextFromContext(ctx)
-	if spanContext.IsValid() {
-		selection = append(selection, setTraceIdClause())
-		args = append(args, spanContext.TraceID().String())
-	}
+	selection, args := auditSelection(ctx, []string{"*"}, args)
 
 	statement := fmt.Sprintf("WITH %s SELECT %s FROM %s",
 		strings.Join(ctes, ", "),
@@ -956,7 +1070,7 @@ func (query *QueryBuilder) InsertStatement(ctx context.Context) *Statement {
 
 // Recursively generates in common table expression insert query for the write values graph.
 func (query *QueryBuilder) generateInsertCte(ctes []string, args []any, row *Row, foreignKey *proto.Field, primaryKeyTableAlias string) ([]string, []any, string) {
-	alias := fmt.Sprintf("new_%v_%s", makeAlias(query.writeValues, row), casing.ToSnake(row.model.GetName()))
+	alias := fmt.Sprintf("%snew_%v_%s", query.aliasPrefix, makeAlias(query.writeValues, row), casing.ToSnake(row.model.GetName()))
 	columnNames := []string{}
 
 	// Rows which this row references need to created first, and the primary needs to be extracted (as a SELECT statement from them to insert into this row.
@@ -996,7 +1110,7 @@ func (query *QueryBuilder) generateInsertCte(ctes []string, args []any, row *Row
 			continue
 		}
 
-		cteAlias := fmt.Sprintf("select_%s_%v", operand.query.table, i)
+		cteAlias := fmt.Sprintf("%sselect_%s_%v", query.aliasPrefix, operand.query.table, i)
 		cteExists := false
 		for _, c := range ctes {
 			if strings.HasPrefix(c, sqlQuote(cteAlias)) {
@@ -1034,7 +1148,7 @@ func (query *QueryBuilder) generateInsertCte(ctes []string, args []any, row *Row
 			columnValues = append(columnValues, sql)
 			args = append(args, opArgs...)
 		case operand.IsInlineQuery():
-			cteAlias := fmt.Sprintf("select_%s_%v", operand.query.table, i)
+			cteAlias := fmt.Sprintf("%sselect_%s_%v", query.aliasPrefix, operand.query.table, i)
 			columnAlias := ""
 
 			for i, s := range operand.query.selection {
@@ -1061,10 +1175,19 @@ func (query *QueryBuilder) generateInsertCte(ctes []string, args []any, row *Row
 			strings.Join(columnValues, ", "))
 	}
 
-	cte := fmt.Sprintf("%s AS (INSERT INTO %s %s RETURNING *)",
+	// Only the root row can be upserted. Whether it was inserted or updated is returned, as xmax is only set for
+	// a row which has been updated.
+	returning := "*"
+	if row == query.writeValues && len(query.conflictFields) > 0 {
+		values = fmt.Sprintf("%s %s", values, query.onConflictClause(orderedKeys))
+		returning = fmt.Sprintf("*, (xmax = 0) AS %s", upsertInsertedAlias)
+	}
+
+	cte := fmt.Sprintf("%s AS (INSERT INTO %s %s RETURNING %s)",
 		sqlQuote(alias),
 		sqlQuote(casing.ToSnake(row.model.GetName())),
-		values)
+		values,
+		returning)
 
 	ctes = append(ctes, cte)
 
@@ -1076,6 +1199,39 @@ func (query *QueryBuilder) generateInsertCte(ctes []string, args []any, row *Row
 	return ctes, args, alias
 }
 
+// Generates the ON CONFLICT clause which updates the existing row with the values which would have been inserted.
+func (query *QueryBuilder) onConflictClause(columns []string) string {
+	conflicts := []string{}
+	for _, f := range query.conflictFields {
+		conflicts = append(conflicts, sqlQuote(casing.ToSnake(f)))
+	}
+
+	sets := []string{}
+	for _, col := range columns {
+		c := sqlQuote(casing.ToSnake(col))
+		if lo.Contains(conflicts, c) {
+			continue
+		}
+		sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", c, c))
+	}
+
+	// DO NOTHING would not return the existing row, so there must always be something to update
+	if len(sets) == 0 {
+		for _, c := range conflicts {
+			sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", c, c))
+		}
+	}
+
+	// The unique indexes of models with @softDelete only apply to rows which have not been soft deleted,
+	// so the index is inferred with its predicate and a soft deleted row never conflicts with the insert
+	arbiter := ""
+	if model, ok := query.Entity.(*proto.Model); ok && model.GetSoftDelete() {
+		arbiter = fmt.Sprintf(" WHERE %s IS NULL", sqlQuote(casing.ToSnake(parser.FieldNameDeletedAt)))
+	}
+
+	return fmt.Sprintf("ON CONFLICT (%s)%s DO UPDATE SET %s", strings.Join(conflicts, ", "), arbiter, strings.Join(sets, ", "))
+}
+
 // Generates a unique alias for this row in the graph.
 func makeAlias(graph *Row, row *Row) int {
 	rows := orderGraphNodes(graph)
@@ -1114,14 +1270,38 @@ func orderGraphNodes(graph *Row) []*Row {
 
 // Generates an executable UPDATE statement with the list of arguments.
 func (query *QueryBuilder) UpdateStatement(ctx context.Context) *Statement {
-	queryFilters := query.filters
+	ctes, args, update := query.generateUpdate([]string{}, []any{})
+
+	returning := ""
+
+	query.returning, args = auditSelection(ctx, query.returning, args)
+
+	if len(query.returning) > 0 {
+		returning = fmt.Sprintf("RETURNING %s", strings.Join(query.returning, ", "))
+	}
+
+	commonTableExpressions := ""
+	if len(ctes) > 0 {
+		commonTableExpressions = fmt.Sprintf("WITH %s", strings.Join(ctes, ", "))
+	}
+
+	template := fmt.Sprintf("%s %s %s",
+		commonTableExpressions,
+		update,
+		returning)
+
+	return &Statement{
+		template: cleanSql(template),
+		args:     args,
+		entity:   query.Entity,
+	}
+}
 
+// Generates the UPDATE, without RETURNING, and appends any common table expressions it needs.
+func (query *QueryBuilder) generateUpdate(ctes []string, args []any) ([]string, []any, string) {
 	joins := ""
 	filters := ""
-	returning := ""
 	sets := []string{}
-	args := []any{}
-	ctes := []string{}
 
 	// Make iteratng through the writeValues map deterministically ordered
 	orderedKeys := make([]string, 0, len(query.writeValues.values))
@@ -1136,7 +1316,7 @@ func (query *QueryBuilder) UpdateStatement(ctx context.Context) *Statement {
 			continue
 		}
 
-		cteAlias := fmt.Sprintf("select_%s_%v", operand.query.table, i)
+		cteAlias := fmt.Sprintf("%sselect_%s_%v", query.aliasPrefix, operand.query.table, i)
 		cteExists := false
 		for _, c := range ctes {
 			if strings.HasPrefix(c, sqlQuote(cteAlias)) {
@@ -1165,7 +1345,7 @@ func (query *QueryBuilder) UpdateStatement(ctx context.Context) *Statement {
 		operand := query.writeValues.values[v]
 
 		if operand.IsInlineQuery() {
-			cteAlias := fmt.Sprintf("select_%s_%v", operand.query.table, i)
+			cteAlias := fmt.Sprintf("%sselect_%s_%v", query.aliasPrefix, operand.query.table, i)
 			columnAlias := ""
 
 			for i, s := range operand.query.selection {
@@ -1194,51 +1374,40 @@ func (query *QueryBuilder) UpdateStatement(ctx context.Context) *Statement {
 		}
 	}
 
-	conditions := trimRhsOperators(queryFilters)
+	conditions := trimRhsOperators(query.filters)
 	if len(conditions) > 0 {
 		filters = fmt.Sprintf("WHERE %s", strings.Join(conditions, " "))
 	}
 
-	if auth.IsAuthenticated(ctx) {
-		identity, _ := auth.GetIdentity(ctx)
-		query.returning = append(query.returning, setIdentityIdClause())
-		args = append(args, identity[parser.FieldNameId].(string))
+	if len(query.joins) == 0 {
+		return ctes, args, fmt.Sprintf("UPDATE %s SET %s %s",
+			sqlQuote(query.table),
+			strings.Join(sets, ", "),
+			filters)
 	}
 
-	spanContext := trace.SpanContextFromContext(ctx)
-	if spanContext.IsValid() {
-		query.returning = append(query.returning, setTraceIdClause())
-		args = append(args, spanContext.TraceID().String())
-	}
+	return ctes, args, fmt.Sprintf("UPDATE %s SET %s WHERE \"id\" = (SELECT %s.\"id\" FROM %s %s %s)",
+		sqlQuote(query.table),
+		strings.Join(sets, ", "),
+		sqlQuote(query.table),
+		sqlQuote(query.table),
+		joins,
+		filters)
+}
+
+// Generates an executable DELETE statement with the list of arguments.
+func (query *QueryBuilder) DeleteStatement(ctx context.Context) *Statement {
+	args, del := query.generateDelete([]any{})
+
+	returning := ""
+
+	query.returning, args = auditSelection(ctx, query.returning, args)
 
 	if len(query.returning) > 0 {
 		returning = fmt.Sprintf("RETURNING %s", strings.Join(query.returning, ", "))
 	}
 
-	commonTableExpressions := ""
-	if len(ctes) > 0 {
-		commonTableExpressions = fmt.Sprintf("WITH %s", strings.Join(ctes, ", "))
-	}
-
-	var template string
-	if len(query.joins) == 0 {
-		template = fmt.Sprintf("%s UPDATE %s SET %s %s %s",
-			commonTableExpressions,
-			sqlQuote(query.table),
-			strings.Join(sets, ", "),
-			filters,
-			returning)
-	} else {
-		template = fmt.Sprintf("%s UPDATE %s SET %s WHERE \"id\" = (SELECT %s.\"id\" FROM %s %s %s) %s",
-			commonTableExpressions,
-			sqlQuote(query.table),
-			strings.Join(sets, ", "),
-			sqlQuote(query.table),
-			sqlQuote(query.table),
-			joins,
-			filters,
-			returning)
-	}
+	template := fmt.Sprintf("%s %s", del, returning)
 
 	return &Statement{
 		template: cleanSql(template),
@@ -1247,11 +1416,10 @@ func (query *QueryBuilder) UpdateStatement(ctx context.Context) *Statement {
 	}
 }
 
-// Generates an executable DELETE statement with the list of arguments.
-func (query *QueryBuilder) DeleteStatement(ctx context.Context) *Statement {
+// Generates the DELETE, without RETURNING.
+func (query *QueryBuilder) generateDelete(args []any) ([]any, string) {
 	usings := ""
 	filters := ""
-	returning := ""
 
 	if len(query.joins) > 0 {
 		usingTables := lo.Map(query.joins, func(j joinClause, _ int) string {
@@ -1271,32 +1439,164 @@ func (query *QueryBuilder) DeleteStatement(ctx context.Context) *Statement {
 		filters = fmt.Sprintf("WHERE %s", strings.Join(conditions, " "))
 	}
 
-	if auth.IsAuthenticated(ctx) {
-		identity, _ := auth.GetIdentity(ctx)
-		query.returning = append(query.returning, setIdentityIdClause())
-		query.args = append(query.args, identity[parser.FieldNameId].(string))
+	args = append(args, query.args...)
+
+	return args, fmt.Sprintf("DELETE FROM %s %s %s",
+		sqlQuote(query.table),
+		usings,
+		filters)
+}
+
+// Generates a single executable statement which performs the INSERT of each query, so that the items of a
+// bulk action are written in one round trip to the database.
+func InsertManyStatement(ctx context.Context, queries []*QueryBuilder) *Statement {
+	return writeManyStatement(ctx, queries, func(query *QueryBuilder, ctes []string, args []any) ([]string, []any, string) {
+		return query.generateInsertCte(ctes, args, query.writeValues, nil, "")
+	})
+}
+
+// The most arguments a statement can have, as Postgres numbers them with a 16-bit integer.
+const maxStatementArgs = 65535
+
+// CanInsertRows determines if the rows of the queries can be inserted by InsertRowsStatements, which is when none
+// of them create nested records or insert values selected by other queries.
+func CanInsertRows(queries []*QueryBuilder) bool {
+	columns := 0
+	for _, query := range queries {
+		row := query.writeValues
+		if len(row.references) > 0 || len(row.referencedBy) > 0 {
+			return false
+		}
+
+		for _, operand := range row.values {
+			if operand.IsInlineQuery() {
+				return false
+			}
+		}
+
+		columns += len(row.values)
 	}
 
-	spanContext := trace.SpanContextFromContext(ctx)
-	if spanContext.IsValid() {
-		query.returning = append(query.returning, setTraceIdClause())
-		query.args = append(query.args, spanContext.TraceID().String())
+	// Rows without any values are inserted with DEFAULT VALUES, which can only insert one row
+	return columns > 0
+}
+
+// Generates executable INSERT statements which each insert many of the query's rows with a multi-row VALUES
+// list. A row which doesn't have a value for one of the columns has it set to its DEFAULT. The rows are split
+// across statements so that none has more arguments than Postgres allows. Postgres returns the rows of a
+// multi-row INSERT in the order of its VALUES, and so each returned row has the index of its query.
+func InsertRowsStatements(ctx context.Context, queries []*QueryBuilder) []*Statement {
+	columns := []string{}
+	for _, query := range queries {
+		for col := range query.writeValues.values {
+			if !lo.Contains(columns, col) {
+				columns = append(columns, col)
+			}
+		}
 	}
+	sort.Strings(columns)
 
-	if len(query.returning) > 0 {
-		returning = fmt.Sprintf("RETURNING %s", strings.Join(query.returning, ", "))
+	columnNames := lo.Map(columns, func(col string, _ int) string {
+		return sqlQuote(casing.ToSnake(col))
+	})
+
+	chunkSize := max(1, (maxStatementArgs-2)/len(columns))
+
+	statements := []*Statement{}
+	for offset, chunk := range lo.Chunk(queries, chunkSize) {
+		offset *= chunkSize
+		args := []any{}
+		rows := []string{}
+
+		for _, query := range chunk {
+			values := []string{}
+			for _, col := range columns {
+				operand, ok := query.writeValues.values[col]
+				if !ok {
+					values = append(values, "DEFAULT")
+					continue
+				}
+
+				values = append(values, operand.toSqlOperandString(query))
+				args = append(args, operand.toSqlArgs()...)
+			}
+			rows = append(rows, fmt.Sprintf("(%s)", strings.Join(values, ", ")))
+		}
+
+		index := "ROW_NUMBER() OVER () - 1"
+		if offset > 0 {
+			index = fmt.Sprintf("ROW_NUMBER() OVER () + %d", offset-1)
+		}
+
+		selection, args := auditSelection(ctx, []string{fmt.Sprintf("%s AS %s", index, bulkItemAlias), "*"}, args)
+
+		statement := fmt.Sprintf("WITH %s AS (INSERT INTO %s (%s) VALUES %s RETURNING *) SELECT %s FROM %s",
+			sqlQuote("new_rows"),
+			sqlQuote(queries[0].table),
+			strings.Join(columnNames, ", "),
+			strings.Join(rows, ", "),
+			strings.Join(selection, ", "),
+			sqlQuote("new_rows"))
+
+		statements = append(statements, &Statement{
+			entity:   queries[0].Entity,
+			template: cleanSql(statement),
+			args:     args,
+		})
 	}
 
-	template := fmt.Sprintf("DELETE FROM %s %s %s %s",
-		sqlQuote(query.table),
-		usings,
-		filters,
-		returning)
+	return statements
+}
+
+// Generates a single executable statement which performs the UPDATE of each query, so that the items of a
+// bulk action are written in one round trip to the database.
+func UpdateManyStatement(ctx context.Context, queries []*QueryBuilder) *Statement {
+	return writeManyStatement(ctx, queries, func(query *QueryBuilder, ctes []string, args []any) ([]string, []any, string) {
+		ctes, args, update := query.generateUpdate(ctes, args)
+		alias := fmt.Sprintf("%supdated", query.aliasPrefix)
+		ctes = append(ctes, fmt.Sprintf("%s AS (%s RETURNING %s)", sqlQuote(alias), update, strings.Join(query.returning, ", ")))
+		return ctes, args, alias
+	})
+}
+
+// Generates a single executable statement which performs the DELETE of each query, so that the items of a
+// bulk action are written in one round trip to the database.
+func DeleteManyStatement(ctx context.Context, queries []*QueryBuilder) *Statement {
+	return writeManyStatement(ctx, queries, func(query *QueryBuilder, ctes []string, args []any) ([]string, []any, string) {
+		args, del := query.generateDelete(args)
+		alias := fmt.Sprintf("%sdeleted", query.aliasPrefix)
+		ctes = append(ctes, fmt.Sprintf("%s AS (%s RETURNING %s)", sqlQuote(alias), del, strings.Join(query.returning, ", ")))
+		return ctes, args, alias
+	})
+}
+
+// Combines the writes of many queries as data-modifying common table expressions of one statement. Each
+// returned row has the index of the query which wrote it, as a query may not write a row at all.
+func writeManyStatement(ctx context.Context, queries []*QueryBuilder, write func(query *QueryBuilder, ctes []string, args []any) ([]string, []any, string)) *Statement {
+	ctes := []string{}
+	args := []any{}
+	items := []string{}
+
+	for i, query := range queries {
+		var alias string
+		query.aliasPrefix = fmt.Sprintf("item_%v_", i)
+		ctes, args, alias = write(query, ctes, args)
+		items = append(items, fmt.Sprintf("SELECT %v::BIGINT AS %s, * FROM %s", i, bulkItemAlias, sqlQuote(alias)))
+	}
+
+	selection, args := auditSelection(ctx, []string{"*"}, args)
+
+	statement := fmt.Sprintf("WITH %s SELECT %s FROM (%s) AS %s ORDER BY %s",
+		strings.Join(ctes, ", "),
+		strings.Join(selection, ", "),
+		strings.Join(items, " UNION ALL "),
+		sqlQuote("items"),
+		bulkItemAlias)
 
 	return &Statement{
-		template: cleanSql(template),
-		args:     query.args,
-		entity:   query.Entity,
+		entity:   queries[0].Entity,
+		template: cleanSql(statement),
+		args:     args,
 	}
 }
 
@@ -1431,6 +1731,8 @@ func (statement *Statement) ExecuteToMany(ctx context.Context, page *Page) (Rows
 			delete(row, "_facets")
 			delete(row, setIdentityIdAlias)
 			delete(row, setTraceIdAlias)
+			// Write statements return all columns, including the search document
+			delete(row, proto.SearchColumnName)
 		}
 	}
 
@@ -1535,6 +1837,29 @@ func (statement *Statement) ExecuteToMany(ctx context.Context, page *Page) (Rows
 	return toLowerCamelMaps(rows), ri, pageInfo, nil
 }
 
+// Execute a statement generated for the items of a bulk action, returning the row written for each of the
+// items in order, or nil for an item which did not write a row.
+func (statement *Statement) ExecuteToItems(ctx context.Context, count int) ([]map[string]any, error) {
+	rows, _, _, err := statement.ExecuteToMany(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]any, count)
+	for _, row := range rows {
+		key := casing.ToLowerCamel(bulkItemAlias)
+		i, err := toInt(row[key])
+		if err != nil || i < 0 || i >= count {
+			return nil, fmt.Errorf("could not parse the item of a bulk action row: %v", row[key])
+		}
+
+		delete(row, key)
+		results[i] = row
+	}
+
+	return results, nil
+}
+
 // Execute the SQL statement against the database and expects a single row, returns the single row or nil if no data is found.
 func (statement *Statement) ExecuteToSingle(ctx context.Context) (map[string]any, error) {
 	results, _, pageInfo, err := statement.ExecuteToMany(ctx, nil)
@@ -1780,6 +2105,18 @@ func toLowerCamelMaps(maps []map[string]any) []map[string]any {
 	return res
 }
 
+// modelColumns are the names of the columns of the model's fields.
+func modelColumns(model *proto.Model) []string {
+	columns := []string{}
+	for _, field := range model.GetFields() {
+		if field.GetType().GetType() == proto.Type_TYPE_ENTITY {
+			continue
+		}
+		columns = append(columns, casing.ToSnake(field.GetName()))
+	}
+	return columns
+}
+
 // given a variadic list of tokens (e.g sqlQuote("person", "id")),
 // returns sql friendly quoted tokens: "person"."id".
 func sqlQuote(tokens ...string) string {
@@ -1806,6 +2143,9 @@ func toRuntimeError(err error) error {
 		case db.PgUniqueConstraintViolation:
 			return common.NewUniquenessError(value.Columns)
 		case db.PgForeignKeyConstraintViolation:
+			if value.StillReferenced {
+				return common.NewRestrictedDeleteError(value.Table)
+			}
 			return common.NewForeignKeyConstraintError(value.Columns[0])
 		default:
 			return common.RuntimeError{
@@ -1819,10 +2159,29 @@ func toRuntimeError(err error) error {
 }
 
 const (
-	setIdentityIdAlias = "__keel_identity_id"
-	setTraceIdAlias    = "__keel_trace_id"
+	setIdentityIdAlias  = "__keel_identity_id"
+	setTraceIdAlias     = "__keel_trace_id"
+	bulkItemAlias       = "__keel_item"
+	upsertInsertedAlias = "__keel_inserted"
 )
 
+// Appends the columns to a selection which set the identity and trace of the transaction for the audit log.
+func auditSelection(ctx context.Context, selection []string, args []any) ([]string, []any) {
+	if auth.IsAuthenticated(ctx) {
+		identity, _ := auth.GetIdentity(ctx)
+		selection = append(selection, setIdentityIdClause())
+		args = append(args, identity[parser.FieldNameId].(string))
+	}
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		selection = append(selection, setTraceIdClause())
+		args = append(args, spanContext.TraceID().String())
+	}
+
+	return selection, args
+}
+
 func setIdentityIdClause() string {
 	return fmt.Sprintf("set_identity_id(?) AS %s", setIdentityIdAlias)
 }
@@ -4029,6 +4029,816 @@ var testCases = []testCase{
 			RETURNING "item".*`,
 		expectedArgs: []any{"123", 4, "123"},
 	},
+	{
+		name: "soft_delete_get",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+				}
+				actions {
+					get getPost(id)
+				}
+				@softDelete
+				@permission(expression: true, actions: [get, list, delete, restore])
+			}`,
+		actionName: "getPost",
+		input:      map[string]any{"id": "123"},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON("post"."id") "post".*
+			FROM
+				"post"
+			WHERE
+				"post"."deleted_at" IS NULL AND
+				("post"."id" IS NOT DISTINCT FROM ?)`,
+		expectedArgs: []any{"123"},
+	},
+	{
+		name: "soft_delete_list",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+				}
+				actions {
+					list listPosts(title)
+				}
+				@softDelete
+				@permission(expression: true, actions: [get, list, delete, restore])
+			}`,
+		actionName: "listPosts",
+		input:      map[string]any{"where": map[string]any{"title": map[string]any{"equals": "foo"}}},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON("post"."id") "post".*,
+				CASE WHEN LEAD("post"."id") OVER (ORDER BY "post"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT "post"."id") FROM "post" WHERE "post"."deleted_at" IS NULL AND ("post"."title" IS NOT DISTINCT FROM ?)) AS totalCount
+			FROM
+				"post"
+			WHERE
+				"post"."deleted_at" IS NULL AND
+				("post"."title" IS NOT DISTINCT FROM ?)
+			ORDER BY
+				"post"."id" ASC
+			LIMIT ?`,
+		expectedArgs: []any{"foo", "foo", 50},
+	},
+	{
+		name: "soft_delete_relationship_filter",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+					author Author
+				}
+				actions {
+					list listPosts(author.name)
+				}
+				@permission(expression: true, actions: [list])
+			}
+			model Author {
+				fields {
+					name Text
+				}
+				@softDelete
+			}`,
+		actionName: "listPosts",
+		input:      map[string]any{"where": map[string]any{"author": map[string]any{"name": map[string]any{"equals": "foo"}}}},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON("post"."id") "post".*,
+				CASE WHEN LEAD("post"."id") OVER (ORDER BY "post"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT "post"."id") FROM "post" LEFT JOIN "author" AS "post$author" ON "post$author"."id" = "post"."author_id" AND "post$author"."deleted_at" IS NULL WHERE "post$author"."name" IS NOT DISTINCT FROM ?) AS totalCount
+			FROM
+				"post"
+			LEFT JOIN "author" AS "post$author" ON "post$author"."id" = "post"."author_id" AND "post$author"."deleted_at" IS NULL
+			WHERE
+				"post$author"."name" IS NOT DISTINCT FROM ?
+			ORDER BY
+				"post"."id" ASC
+			LIMIT ?`,
+		expectedArgs: []any{"foo", "foo", 50},
+	},
+	{
+		name: "soft_delete_delete",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+				}
+				actions {
+					delete deletePost(id)
+				}
+				@softDelete
+				@permission(expression: true, actions: [get, list, delete, restore])
+			}`,
+		actionName: "deletePost",
+		input:      map[string]any{"id": "123"},
+		expectedTemplate: `
+			UPDATE "post" SET
+				"deleted_at" = NOW()
+			WHERE
+				"post"."deleted_at" IS NULL AND
+				("post"."id" IS NOT DISTINCT FROM ?)
+			RETURNING "post"."id"`,
+		expectedArgs: []any{"123"},
+	},
+	{
+		name: "soft_delete_restore",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+				}
+				actions {
+					restore restorePost(id)
+				}
+				@softDelete
+				@permission(expression: true, actions: [get, list, delete, restore])
+			}`,
+		actionName: "restorePost",
+		input:      map[string]any{"id": "123"},
+		expectedTemplate: `
+			UPDATE "post" SET
+				"deleted_at" = NULL
+			WHERE
+				"post"."deleted_at" IS NOT NULL AND
+				("post"."id" IS NOT DISTINCT FROM ?)
+			RETURNING "post".*`,
+		expectedArgs: []any{"123"},
+	},
+	{
+		name: "upsert_op_unique_field",
+		keelSchema: `
+			model Product {
+				fields {
+					sku Text @unique
+					name Text
+					price Decimal
+				}
+				actions {
+					upsert upsertProduct(sku) with (name, price)
+				}
+				@permission(expression: true, actions: [upsert])
+			}`,
+		actionName: "upsertProduct",
+		input: map[string]any{
+			"where":  map[string]any{"sku": "ABC-123"},
+			"values": map[string]any{"name": "Widget", "price": 9.99},
+		},
+		expectedTemplate: `
+			WITH
+				"new_1_product" AS
+					(INSERT INTO "product"
+						("name", "price", "sku")
+					VALUES
+						(?, ?, ?)
+					ON CONFLICT ("sku") DO UPDATE SET
+						"name" = EXCLUDED."name",
+						"price" = EXCLUDED."price"
+					RETURNING *, (xmax = 0) AS __keel_inserted)
+			SELECT * FROM "new_1_product"`,
+		expectedArgs: []any{"Widget", 9.99, "ABC-123"},
+	},
+	{
+		name: "upsert_op_composite_unique_relationships",
+		keelSchema: `
+			model Warehouse {
+				fields {
+					name Text
+				}
+			}
+			model Product {
+				fields {
+					name Text
+				}
+			}
+			model Stock {
+				fields {
+					warehouse Warehouse
+					product Product
+					quantity Number
+				}
+				actions {
+					upsert upsertStock(warehouse.id, product.id) with (quantity)
+				}
+				@unique([warehouse, product])
+				@permission(expression: true, actions: [upsert])
+			}`,
+		actionName: "upsertStock",
+		input: map[string]any{
+			"where": map[string]any{
+				"warehouseId": "w1",
+				"productId":   "p1",
+			},
+			"values": map[string]any{"quantity": int64(10)},
+		},
+		expectedTemplate: `
+			WITH
+				"new_1_stock" AS
+					(INSERT INTO "stock"
+						("product_id", "quantity", "warehouse_id")
+					VALUES
+						(?, ?, ?)
+					ON CONFLICT ("warehouse_id", "product_id") DO UPDATE SET
+						"quantity" = EXCLUDED."quantity"
+					RETURNING *, (xmax = 0) AS __keel_inserted)
+			SELECT * FROM "new_1_stock"`,
+		expectedArgs: []any{"p1", int64(10), "w1"},
+	},
+	{
+		name: "upsert_op_set_attribute",
+		keelSchema: `
+			model Product {
+				fields {
+					sku Text @unique
+					name Text
+					updatedBy Identity?
+				}
+				actions {
+					upsert upsertProduct(sku) {
+						@set(product.name = sku)
+						@set(product.updatedBy = ctx.identity)
+					}
+				}
+				@permission(expression: true, actions: [upsert])
+			}`,
+		actionName: "upsertProduct",
+		identity:   identity,
+		input: map[string]any{
+			"where": map[string]any{"sku": "ABC-123"},
+		},
+		expectedTemplate: `
+			WITH
+				"new_1_product" AS
+					(INSERT INTO "product"
+						("name", "sku", "updated_by_id")
+					VALUES
+						(?, ?, ?)
+					ON CONFLICT ("sku") DO UPDATE SET
+						"name" = EXCLUDED."name",
+						"updated_by_id" = EXCLUDED."updated_by_id"
+					RETURNING *, (xmax = 0) AS __keel_inserted)
+			SELECT *, set_identity_id(?) AS __keel_identity_id FROM "new_1_product"`,
+		expectedArgs: []any{"ABC-123", "ABC-123", identity[parser.FieldNameId].(string), identity[parser.FieldNameId].(string)},
+	},
+	{
+		name: "upsert_op_only_unique_inputs",
+		keelSchema: `
+			model Product {
+				fields {
+					sku Text @unique
+				}
+				actions {
+					upsert upsertProduct(sku)
+				}
+				@softDelete
+				@permission(expression: true, actions: [upsert])
+			}`,
+		actionName: "upsertProduct",
+		input: map[string]any{
+			"where": map[string]any{"sku": "ABC-123"},
+		},
+		expectedTemplate: `
+			WITH
+				"new_1_product" AS
+					(INSERT INTO "product"
+						("sku")
+					VALUES
+						(?)
+					ON CONFLICT ("sku") WHERE "deleted_at" IS NULL DO UPDATE SET
+						"sku" = EXCLUDED."sku"
+					RETURNING *, (xmax = 0) AS __keel_inserted)
+			SELECT * FROM "new_1_product"`,
+		expectedArgs: []any{"ABC-123"},
+	},
+	{
+		name: "create_many_op",
+		keelSchema: `
+			model Author {
+				fields {
+					name Text
+				}
+			}
+			model Post {
+				fields {
+					title Text
+					published Boolean @default(false)
+					author Author
+				}
+				actions {
+					createMany createPosts() with (title, author.id) @set(post.published = true)
+				}
+				@permission(expression: true, actions: [createMany])
+			}`,
+		actionName: "createPosts",
+		input: map[string]any{
+			"items": []any{
+				map[string]any{"title": "First", "author": map[string]any{"id": "author-1"}},
+				map[string]any{"title": "Second", "author": map[string]any{"id": "author-2"}},
+			},
+		},
+		expectedTemplate: `
+			WITH
+				"new_rows" AS
+					(INSERT INTO "post"
+						("author_id", "published", "title")
+					VALUES
+						(?, ?, ?),
+						(?, ?, ?)
+					RETURNING *)
+			SELECT ROW_NUMBER() OVER () - 1 AS __keel_item, * FROM "new_rows"`,
+		expectedArgs: []any{"author-1", true, "First", "author-2", true, "Second"},
+	},
+	{
+		name: "create_many_op_nested_create",
+		keelSchema: `
+			model Author {
+				fields {
+					name Text
+				}
+			}
+			model Post {
+				fields {
+					title Text
+					author Author
+				}
+				actions {
+					createMany createPosts() with (title, author.name)
+				}
+				@permission(expression: true, actions: [createMany])
+			}`,
+		actionName: "createPosts",
+		input: map[string]any{
+			"items": []any{
+				map[string]any{"title": "First", "author": map[string]any{"name": "Keelson"}},
+				map[string]any{"title": "Second", "author": map[string]any{"name": "Weaveton"}},
+			},
+		},
+		expectedTemplate: `
+			WITH
+				"item_0_new_1_author" AS
+					(INSERT INTO "author"
+						("name")
+					VALUES
+						(?)
+					RETURNING *),
+				"item_0_new_1_post" AS
+					(INSERT INTO "post"
+						("author_id", "title")
+					VALUES
+						((SELECT "id" FROM "item_0_new_1_author"), ?)
+					RETURNING *),
+				"item_1_new_1_author" AS
+					(INSERT INTO "author"
+						("name")
+					VALUES
+						(?)
+					RETURNING *),
+				"item_1_new_1_post" AS
+					(INSERT INTO "post"
+						("author_id", "title")
+					VALUES
+						((SELECT "id" FROM "item_1_new_1_author"), ?)
+					RETURNING *)
+			SELECT * FROM
+				(SELECT 0::BIGINT AS __keel_item, * FROM "item_0_new_1_post"
+				UNION ALL
+				SELECT 1::BIGINT AS __keel_item, * FROM "item_1_new_1_post") AS "items"
+			ORDER BY __keel_item`,
+		expectedArgs: []any{"Keelson", "First", "Weaveton", "Second"},
+	},
+	{
+		name: "create_many_op_missing_column",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+					subtitle Text?
+				}
+				actions {
+					createMany createPosts() with (title, subtitle?)
+				}
+				@permission(expression: true, actions: [createMany])
+			}`,
+		actionName: "createPosts",
+		input: map[string]any{
+			"items": []any{
+				map[string]any{"title": "First", "subtitle": "Sub"},
+				map[string]any{"title": "Second"},
+			},
+		},
+		expectedTemplate: `
+			WITH
+				"new_rows" AS
+					(INSERT INTO "post"
+						("subtitle", "title")
+					VALUES
+						(?, ?),
+						(DEFAULT, ?)
+					RETURNING *)
+			SELECT ROW_NUMBER() OVER () - 1 AS __keel_item, * FROM "new_rows"`,
+		expectedArgs: []any{"Sub", "First", "Second"},
+	},
+	{
+		name: "update_many_op",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+					slug Text @unique
+				}
+				actions {
+					updateMany updatePosts(slug) with (title)
+				}
+				@permission(expression: true, actions: [updateMany])
+			}`,
+		actionName: "updatePosts",
+		identity:   identity,
+		input: map[string]any{
+			"items": []any{
+				map[string]any{"where": map[string]any{"slug": "first"}, "values": map[string]any{"title": "First"}},
+				map[string]any{"where": map[string]any{"slug": "second"}, "values": map[string]any{"title": "Second"}},
+			},
+		},
+		expectedTemplate: `
+			WITH
+				"item_0_updated" AS
+					(UPDATE "post" SET
+						"title" = ?
+					WHERE
+						"post"."slug" IS NOT DISTINCT FROM ?
+					RETURNING "post".*),
+				"item_1_updated" AS
+					(UPDATE "post" SET
+						"title" = ?
+					WHERE
+						"post"."slug" IS NOT DISTINCT FROM ?
+					RETURNING "post".*)
+			SELECT *, set_identity_id(?) AS __keel_identity_id FROM
+				(SELECT 0::BIGINT AS __keel_item, * FROM "item_0_updated"
+				UNION ALL
+				SELECT 1::BIGINT AS __keel_item, * FROM "item_1_updated") AS "items"
+			ORDER BY __keel_item`,
+		expectedArgs: []any{"First", "first", "Second", "second", identity[parser.FieldNameId].(string)},
+	},
+	{
+		name: "delete_many_op",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+				}
+				actions {
+					deleteMany deletePosts(id)
+				}
+				@permission(expression: true, actions: [deleteMany])
+			}`,
+		actionName: "deletePosts",
+		input: map[string]any{
+			"items": []any{
+				map[string]any{"id": "post-1"},
+				map[string]any{"id": "post-2"},
+			},
+		},
+		expectedTemplate: `
+			WITH
+				"item_0_deleted" AS
+					(DELETE FROM "post"
+					WHERE
+						"post"."id" IS NOT DISTINCT FROM ?
+					RETURNING "post"."id"),
+				"item_1_deleted" AS
+					(DELETE FROM "post"
+					WHERE
+						"post"."id" IS NOT DISTINCT FROM ?
+					RETURNING "post"."id")
+			SELECT * FROM
+				(SELECT 0::BIGINT AS __keel_item, * FROM "item_0_deleted"
+				UNION ALL
+				SELECT 1::BIGINT AS __keel_item, * FROM "item_1_deleted") AS "items"
+			ORDER BY __keel_item`,
+		expectedArgs: []any{"post-1", "post-2"},
+	},
+	{
+		name: "delete_many_op_soft_delete",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+				}
+				actions {
+					deleteMany deletePosts(id)
+				}
+				@softDelete
+				@permission(expression: true, actions: [deleteMany])
+			}`,
+		actionName: "deletePosts",
+		input: map[string]any{
+			"items": []any{
+				map[string]any{"id": "post-1"},
+				map[string]any{"id": "post-2"},
+			},
+		},
+		expectedTemplate: `
+			WITH
+				"item_0_updated" AS
+					(UPDATE "post" SET
+						"deleted_at" = NOW()
+					WHERE
+						"post"."deleted_at" IS NULL AND
+						("post"."id" IS NOT DISTINCT FROM ?)
+					RETURNING "post"."id"),
+				"item_1_updated" AS
+					(UPDATE "post" SET
+						"deleted_at" = NOW()
+					WHERE
+						"post"."deleted_at" IS NULL AND
+						("post"."id" IS NOT DISTINCT FROM ?)
+					RETURNING "post"."id")
+			SELECT * FROM
+				(SELECT 0::BIGINT AS __keel_item, * FROM "item_0_updated"
+				UNION ALL
+				SELECT 1::BIGINT AS __keel_item, * FROM "item_1_updated") AS "items"
+			ORDER BY __keel_item`,
+		expectedArgs: []any{"post-1", "post-2"},
+	},
+	{
+		name: "aggregate_op",
+		keelSchema: `
+			enum Status {
+				Pending
+				Shipped
+			}
+			model Order {
+				fields {
+					status Status
+					total Decimal
+					quantity Number
+				}
+				actions {
+					aggregate orderTotals(status?) {
+						@groupBy(status)
+						@aggregate(sum: [total, quantity], avg: [total], max: [total])
+						@where(order.total > 0)
+					}
+				}
+				@permission(expression: true, actions: [aggregate])
+			}`,
+		actionName: "orderTotals",
+		input: map[string]any{
+			"where": map[string]any{
+				"status": map[string]any{
+					"equals": "Shipped",
+				},
+			},
+		},
+		expectedTemplate: `
+			SELECT
+				"order"."status" AS "status",
+				COUNT(*) AS "count",
+				SUM("order"."total") AS "sum.total",
+				SUM("order"."quantity") AS "sum.quantity",
+				AVG("order"."total") AS "avg.total",
+				MAX("order"."total") AS "max.total"
+			FROM "order"
+			WHERE
+				"order"."status" IS NOT DISTINCT FROM ? AND
+				"order"."total" > ?
+			GROUP BY "order"."status"
+			ORDER BY "order"."status" ASC`,
+		expectedArgs: []any{"Shipped", int64(0)},
+	},
+	{
+		name: "aggregate_op_interval",
+		keelSchema: `
+			model Order {
+				fields {
+					total Decimal
+					placedOn Date
+				}
+				actions {
+					aggregate orderTotals() {
+						@groupBy(placedOn, createdAt)
+						@aggregate(sum: [total], min: [placedOn])
+					}
+				}
+				@softDelete
+				@permission(expression: true, actions: [aggregate])
+			}`,
+		actionName: "orderTotals",
+		input: map[string]any{
+			"interval": "month",
+		},
+		expectedTemplate: `
+			SELECT
+				DATE_TRUNC('month', "order"."placed_on")::DATE AS "placedOn",
+				DATE_TRUNC('month', "order"."created_at") AS "createdAt",
+				COUNT(*) AS "count",
+				SUM("order"."total") AS "sum.total",
+				MIN("order"."placed_on") AS "min.placedOn"
+			FROM "order"
+			WHERE "order"."deleted_at" IS NULL
+			GROUP BY
+				DATE_TRUNC('month', "order"."placed_on")::DATE,
+				DATE_TRUNC('month', "order"."created_at")
+			ORDER BY
+				DATE_TRUNC('month', "order"."placed_on")::DATE ASC,
+				DATE_TRUNC('month', "order"."created_at") ASC`,
+		expectedArgs: []any{},
+	},
+	{
+		name: "aggregate_op_to_many_filter",
+		keelSchema: `
+			model Customer {
+				fields {
+					name Text
+					orders Order[]
+				}
+				actions {
+					aggregate customerCount(orders.total)
+				}
+				@permission(expression: true, actions: [aggregate])
+			}
+			model Order {
+				fields {
+					total Decimal
+					customer Customer
+				}
+			}`,
+		actionName: "customerCount",
+		input: map[string]any{
+			"where": map[string]any{
+				"orders": map[string]any{
+					"total": map[string]any{
+						"greaterThan": 10.0,
+					},
+				},
+			},
+		},
+		expectedTemplate: `
+			SELECT COUNT(*) AS "count"
+			FROM "customer"
+			WHERE "customer"."id" IN
+				(SELECT DISTINCT ON("customer"."id") "customer"."id"
+				FROM "customer"
+				LEFT JOIN "order" AS "customer$orders" ON "customer$orders"."customer_id" = "customer"."id"
+				WHERE "customer$orders"."total" > ?)`,
+		expectedArgs: []any{10.0},
+	},
+	{
+		name: "search_list",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+					body Markdown
+					published Boolean
+				}
+				actions {
+					list listPosts(published)
+				}
+				@searchable(title, body)
+				@permission(expression: true, actions: [list])
+			}`,
+		actionName: "listPosts",
+		input:      map[string]any{"where": map[string]any{"published": map[string]any{"equals": true}}, "search": "keel tips"},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON(ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbCB0aXBz', 'base64'), 'UTF8'))), "post"."id") "post"."title", "post"."body", "post"."published", "post"."id", "post"."created_at", "post"."updated_at",
+				CASE WHEN LEAD("post"."id") OVER (ORDER BY ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbCB0aXBz', 'base64'), 'UTF8'))) DESC, "post"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT (ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbCB0aXBz', 'base64'), 'UTF8'))), "post"."id")) FROM "post" WHERE "post"."published" IS NOT DISTINCT FROM ? AND "post"."keel_search" @@ websearch_to_tsquery('english', convert_from(decode('a2VlbCB0aXBz', 'base64'), 'UTF8'))) AS totalCount
+			FROM
+				"post"
+			WHERE
+				"post"."published" IS NOT DISTINCT FROM ? AND
+				"post"."keel_search" @@ websearch_to_tsquery('english', convert_from(decode('a2VlbCB0aXBz', 'base64'), 'UTF8'))
+			ORDER BY
+				ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbCB0aXBz', 'base64'), 'UTF8'))) DESC,
+				"post"."id" ASC
+			LIMIT ?`,
+		expectedArgs: []any{true, true, 50},
+	},
+	{
+		name: "search_list_after_cursor",
+		keelSchema: `
+			model Post {
+				fields {
+					title Text
+				}
+				actions {
+					list listPosts()
+				}
+				@searchable(title)
+				@permission(expression: true, actions: [list])
+			}`,
+		actionName: "listPosts",
+		input:      map[string]any{"search": "keel", "after": "123"},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON(ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbA==', 'base64'), 'UTF8'))), "post"."id") "post"."title", "post"."id", "post"."created_at", "post"."updated_at",
+				CASE WHEN LEAD("post"."id") OVER (ORDER BY ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbA==', 'base64'), 'UTF8'))) DESC, "post"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT (ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbA==', 'base64'), 'UTF8'))), "post"."id")) FROM "post" WHERE "post"."keel_search" @@ websearch_to_tsquery('english', convert_from(decode('a2VlbA==', 'base64'), 'UTF8'))) AS totalCount
+			FROM
+				"post"
+			WHERE
+				"post"."keel_search" @@ websearch_to_tsquery('english', convert_from(decode('a2VlbA==', 'base64'), 'UTF8')) AND
+				(ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbA==', 'base64'), 'UTF8'))) < (SELECT ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbA==', 'base64'), 'UTF8'))) FROM "post" WHERE "post"."id" IS NOT DISTINCT FROM ?) OR
+				(ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbA==', 'base64'), 'UTF8'))) IS NOT DISTINCT FROM (SELECT ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbA==', 'base64'), 'UTF8'))) FROM "post" WHERE "post"."id" IS NOT DISTINCT FROM ?) AND "post"."id" > (SELECT "post"."id" FROM "post" WHERE "post"."id" IS NOT DISTINCT FROM ?)))
+			ORDER BY
+				ts_rank("post"."keel_search", websearch_to_tsquery('english', convert_from(decode('a2VlbA==', 'base64'), 'UTF8'))) DESC,
+				"post"."id" ASC
+			LIMIT ?`,
+		expectedArgs: []any{"123", "123", "123", 50},
+	},
+	{
+		name: "nearest_list",
+		keelSchema: `
+			model Document {
+				fields {
+					title Text
+					embedding Vector @vectorIndex(hnsw, dimensions: 3)
+				}
+				actions {
+					list listDocuments(embedding)
+				}
+				@permission(expression: true, actions: [list])
+			}`,
+		actionName: "listDocuments",
+		input:      map[string]any{"where": map[string]any{"embedding": map[string]any{"nearest": []any{0.1, 0.2, 0.3}, "maxDistance": 0.5}}},
+		expectedTemplate: `
+			SELECT
+				"document".*,
+				CASE WHEN LEAD("document"."id") OVER (ORDER BY ("document"."embedding" <=> '[0.1,0.2,0.3]'::vector) ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(*) FROM "document" WHERE ("document"."embedding" <=> '[0.1,0.2,0.3]'::vector) <= ?) AS totalCount
+			FROM
+				"document"
+			WHERE
+				("document"."embedding" <=> '[0.1,0.2,0.3]'::vector) <= ?
+			ORDER BY
+				("document"."embedding" <=> '[0.1,0.2,0.3]'::vector) ASC
+			LIMIT ?`,
+		expectedArgs: []any{0.5, 0.5, 50},
+	},
+	{
+		name: "nearest_list_after_cursor",
+		keelSchema: `
+			model Document {
+				fields {
+					title Text
+					embedding Vector @vectorIndex(hnsw, dimensions: 3)
+				}
+				actions {
+					list listDocuments(embedding)
+				}
+				@permission(expression: true, actions: [list])
+			}`,
+		actionName: "listDocuments",
+		input:      map[string]any{"where": map[string]any{"embedding": map[string]any{"nearest": []any{0.1, 0.2, 0.3}}}, "after": "123"},
+		expectedTemplate: `
+			SELECT
+				DISTINCT ON(("document"."embedding" <=> '[0.1,0.2,0.3]'::vector), "document"."id") "document".*,
+				CASE WHEN LEAD("document"."id") OVER (ORDER BY ("document"."embedding" <=> '[0.1,0.2,0.3]'::vector) ASC, "document"."id" ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(DISTINCT (("document"."embedding" <=> '[0.1,0.2,0.3]'::vector), "document"."id")) FROM "document") AS totalCount
+			FROM
+				"document"
+			WHERE
+				(("document"."embedding" <=> '[0.1,0.2,0.3]'::vector) > (SELECT ("document"."embedding" <=> '[0.1,0.2,0.3]'::vector) FROM "document" WHERE "document"."id" IS NOT DISTINCT FROM ?) OR
+				(("document"."embedding" <=> '[0.1,0.2,0.3]'::vector) IS NOT DISTINCT FROM (SELECT ("document"."embedding" <=> '[0.1,0.2,0.3]'::vector) FROM "document" WHERE "document"."id" IS NOT DISTINCT FROM ?) AND "document"."id" > (SELECT "document"."id" FROM "document" WHERE "document"."id" IS NOT DISTINCT FROM ?)))
+			ORDER BY
+				("document"."embedding" <=> '[0.1,0.2,0.3]'::vector) ASC,
+				"document"."id" ASC
+			LIMIT ?`,
+		expectedArgs: []any{"123", "123", "123", 50},
+	},
+	{
+		name: "nearest_list_inner_product",
+		keelSchema: `
+			model Document {
+				fields {
+					title Text
+					embedding Vector
+				}
+				actions {
+					list listDocuments(embedding)
+				}
+				@permission(expression: true, actions: [list])
+			}`,
+		actionName: "listDocuments",
+		input:      map[string]any{"where": map[string]any{"embedding": map[string]any{"nearest": []any{1, 0.5}, "metric": "innerProduct"}}, "first": 5},
+		expectedTemplate: `
+			SELECT
+				"document".*,
+				CASE WHEN LEAD("document"."id") OVER (ORDER BY ("document"."embedding" <#> '[1,0.5]'::vector) ASC) IS NOT NULL THEN true ELSE false END AS hasNext,
+				(SELECT COUNT(*) FROM "document") AS totalCount
+			FROM
+				"document"
+			ORDER BY
+				("document"."embedding" <#> '[1,0.5]'::vector) ASC
+			LIMIT ?`,
+		expectedArgs: []any{5},
+	},
 }
 
 func TestQueryBuilder(t *testing.T) {
@@ -4059,6 +4869,23 @@ func TestQueryBuilder(t *testing.T) {
 				statement, err = actions.GenerateUpdateStatement(query, scope, testCase.input)
 			case proto.ActionType_ACTION_TYPE_DELETE:
 				statement, err = actions.GenerateDeleteStatement(query, scope, testCase.input)
+			case proto.ActionType_ACTION_TYPE_RESTORE:
+				statement, err = actions.GenerateRestoreStatement(query, scope, testCase.input)
+			case proto.ActionType_ACTION_TYPE_UPSERT:
+				statement, err = actions.GenerateUpsertStatement(query, scope, testCase.input)
+			case proto.ActionType_ACTION_TYPE_CREATE_MANY:
+				var statements []*actions.Statement
+				statements, err = actions.GenerateCreateManyStatements(query, scope, testCase.input)
+				if err == nil {
+					require.Len(t, statements, 1)
+					statement = statements[0]
+				}
+			case proto.ActionType_ACTION_TYPE_UPDATE_MANY:
+				statement, err = actions.GenerateUpdateManyStatement(query, scope, testCase.input)
+			case proto.ActionType_ACTION_TYPE_DELETE_MANY:
+				statement, err = actions.GenerateDeleteManyStatement(query, scope, testCase.input)
+			case proto.ActionType_ACTION_TYPE_AGGREGATE:
+				statement, err = actions.GenerateAggregateStatement(query, scope, testCase.input)
 			default:
 				require.NoError(t, fmt.Errorf("unhandled action type %s in sql generation", action.GetType().String()))
 			}
@@ -4161,6 +4988,36 @@ func TestDeleteStatement(t *testing.T) {
 	require.Equal(t, clean(expected), clean(stmt.SqlTemplate()))
 }
 
+func TestSelectStatementWithRawOrderings(t *testing.T) {
+	model := &proto.Model{Name: "Person"}
+	query := actions.NewQuery(model)
+	query.Select(actions.AllFields())
+	query.AppendOrderBy(actions.Raw("length(\"person\".\"name\")"), "desc")
+	query.AppendOrderBy(actions.Raw("random()"), "asc")
+	stmt := query.SelectStatement()
+
+	expected := `
+		SELECT DISTINCT ON(length("person"."name"), random()) "person".* FROM "person"
+		ORDER BY length("person"."name") DESC, random() ASC`
+
+	require.Equal(t, clean(expected), clean(stmt.SqlTemplate()))
+}
+
+func TestSelectStatementForUpdate(t *testing.T) {
+	model := &proto.Model{Name: "Person"}
+	query := actions.NewQuery(model)
+	err := query.Where(actions.IdField(), actions.Equals, actions.Value("1234"))
+	require.NoError(t, err)
+	query.Select(actions.AllFields())
+	query.ForUpdate()
+	stmt := query.SelectStatement()
+
+	expected := `
+		SELECT "person".* FROM "person" WHERE "person"."id" IS NOT DISTINCT FROM ? FOR UPDATE OF "person"`
+
+	require.Equal(t, clean(expected), clean(stmt.SqlTemplate()))
+}
+
 func TestInsertStatementWithAuditing(t *testing.T) {
 	ctx := t.Context()
 	ctx = withIdentity(ctx)
@@ -0,0 +1,94 @@
+package actions
+
+import (
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/locale"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+// Restore un-deletes a record from a model with @softDelete and returns it.
+func Restore(scope *Scope, input map[string]any) (res map[string]any, err error) {
+	// Attempt to resolve permissions early; i.e. before row-based database querying.
+	permissions := proto.PermissionsForAction(scope.Schema, scope.Action)
+	canResolveEarly, authorised, err := TryResolveAuthorisationEarly(scope, input, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate the SQL statement
+	opts := []QueryBuilderOption{}
+	if location, err := locale.GetTimeLocation(scope.Context); err == nil {
+		opts = append(opts, WithTimezone(location.String()))
+	}
+	query := NewQuery(scope.Model, opts...)
+
+	statement, err := GenerateRestoreStatement(query, scope, input)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case canResolveEarly && !authorised:
+		return nil, common.NewPermissionError()
+	case !canResolveEarly:
+		query.Select(IdField())
+		query.DistinctOn(IdField())
+		rowToAuthorise, err := query.SelectStatement().ExecuteToSingle(scope.Context)
+		if err != nil {
+			return nil, err
+		}
+
+		rowsToAuthorise := []map[string]any{}
+		if rowToAuthorise != nil {
+			rowsToAuthorise = append(rowsToAuthorise, rowToAuthorise)
+		}
+
+		isAuthorised, err := AuthoriseAction(scope, input, rowsToAuthorise)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isAuthorised {
+			return nil, common.NewPermissionError()
+		}
+	}
+
+	// Execute database request, expecting a single result
+	res, err = statement.ExecuteToSingle(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		return nil, common.NewNotFoundError("")
+	}
+
+	// if we have any files in our results we need to transform them to the object structure required
+	if scope.Model.HasFiles() {
+		res, err = transformModelFileResponses(scope.Context, scope.Model, res)
+	}
+
+	return res, err
+}
+
+func GenerateRestoreStatement(query *QueryBuilder, scope *Scope, input map[string]any) (*Statement, error) {
+	err := query.ApplyImplicitFilters(scope, input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.applyExpressionFilters(scope, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only deleted rows can be restored
+	query.whereSoftDeleted(true)
+	query.AddWriteValue(Field(parser.FieldNameDeletedAt), Null())
+
+	// Return the restored row
+	query.AppendReturning(AllFields())
+
+	return query.UpdateStatement(scope.Context), nil
+}
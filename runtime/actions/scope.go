@@ -98,6 +98,13 @@ func Execute(scope *Scope, input any) (result any, meta *common.ResponseMetadata
 		message := scope.Schema.FindMessage(scope.Action.GetInputMessageName())
 		isFunction := scope.Action.GetImplementation() == proto.ActionImplementation_ACTION_IMPLEMENTATION_CUSTOM
 
+		if isFunction {
+			inputsAsMap, err = completeFunctionFileUploads(scope, message, inputsAsMap)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
 		inputsAsMap, err = TransformInputs(scope.Schema, message, inputsAsMap, isFunction)
 		if err != nil {
 			return nil, nil, err
@@ -242,9 +249,27 @@ func executeAutoAction(scope *Scope, inputs map[string]any) (any, error) {
 	case proto.ActionType_ACTION_TYPE_DELETE:
 		result, err := Delete(scope, inputs)
 		return result, err
+	case proto.ActionType_ACTION_TYPE_RESTORE:
+		result, err := Restore(scope, inputs)
+		return result, err
+	case proto.ActionType_ACTION_TYPE_UPSERT:
+		result, err := Upsert(scope, inputs)
+		return result, err
+	case proto.ActionType_ACTION_TYPE_CREATE_MANY:
+		result, err := CreateMany(scope, inputs)
+		return result, err
+	case proto.ActionType_ACTION_TYPE_UPDATE_MANY:
+		result, err := UpdateMany(scope, inputs)
+		return result, err
+	case proto.ActionType_ACTION_TYPE_DELETE_MANY:
+		result, err := DeleteMany(scope, inputs)
+		return result, err
 	case proto.ActionType_ACTION_TYPE_LIST:
 		result, err := List(scope, inputs)
 		return result, err
+	case proto.ActionType_ACTION_TYPE_AGGREGATE:
+		result, err := Aggregate(scope, inputs)
+		return result, err
 	default:
 		return nil, fmt.Errorf("unhandled auto action type: %s", scope.Action.GetType().String())
 	}
@@ -84,6 +84,16 @@ func Update(scope *Scope, input map[string]any) (res map[string]any, err error)
 }
 
 func GenerateUpdateStatement(query *QueryBuilder, scope *Scope, input map[string]any) (*Statement, error) {
+	err := query.applyUpdate(scope, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.UpdateStatement(scope.Context), nil
+}
+
+// applyUpdate captures the values to write and the filters to find the row for the inputs of an update.
+func (query *QueryBuilder) applyUpdate(scope *Scope, input map[string]any) error {
 	values, ok := input["values"].(map[string]any)
 	if !ok {
 		values = map[string]any{}
@@ -91,12 +101,12 @@ func GenerateUpdateStatement(query *QueryBuilder, scope *Scope, input map[string
 
 	err := query.captureWriteValues(scope, values)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	err = query.captureSetValues(scope, input)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	where, ok := input["where"].(map[string]any)
@@ -106,16 +116,19 @@ func GenerateUpdateStatement(query *QueryBuilder, scope *Scope, input map[string
 
 	err = query.ApplyImplicitFilters(scope, where)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	err = query.applyExpressionFilters(scope, where)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
+	// Soft deleted rows cannot be updated until they are restored
+	query.ExcludeSoftDeleted()
+
 	// Return the updated row
 	query.AppendReturning(AllFields())
 
-	return query.UpdateStatement(scope.Context), nil
+	return nil
 }
@@ -0,0 +1,250 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/locale"
+)
+
+// Upsert creates a record, or updates the existing record when one already has the same values for the unique
+// fields in the where inputs. Both the create and the update are performed by a single INSERT ... ON CONFLICT
+// statement, and so the audit log records either a create or an update event depending on which took place.
+func Upsert(scope *Scope, input map[string]any) (res map[string]any, err error) {
+	if scope.Model.HasFiles() {
+		// handle file uploads and change input values to file data if applicable
+		if values, ok := input["values"].(map[string]any); ok {
+			in, err := handleFileUploads(scope, values)
+			if err != nil {
+				return nil, fmt.Errorf("handling file uploads: %w", err)
+			}
+			input["values"] = in
+		}
+	}
+
+	// Permissions and @set expressions can use both the where and values inputs
+	inputs := upsertInputs(input)
+
+	// Attempt to resolve permissions early; i.e. before row-based database querying.
+	permissions := proto.PermissionsForAction(scope.Schema, scope.Action)
+	canResolveEarly, authorised, err := TryResolveAuthorisationEarly(scope, inputs, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate the SQL statement
+	opts := []QueryBuilderOption{}
+	if location, err := locale.GetTimeLocation(scope.Context); err == nil {
+		opts = append(opts, WithTimezone(location.String()))
+	}
+	query := NewQuery(scope.Model, opts...)
+
+	statement, err := GenerateUpsertStatement(query, scope, input)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case canResolveEarly && !authorised:
+		err = common.NewPermissionError()
+	case canResolveEarly && authorised:
+		// Execute database request without starting a transaction or performing any row-based authorization
+		res, err = statement.ExecuteToSingle(scope.Context)
+	case !canResolveEarly:
+		// If the record is created by someone else after finding that it doesn't exist, then it would be updated
+		// without being authorised, so the upsert is tried again to find and authorise it
+		for attempt := 0; attempt < 2; attempt++ {
+			res, err = executeAuthorisedUpsert(scope, statement, inputs, input, opts)
+			if !errors.Is(err, errUpsertCreatedConcurrently) {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Because of computed fields, we need to fetch the row again to get the computed fields
+	query = NewQuery(scope.Model, opts...)
+	err = query.Where(IdField(), Equals, Value(res["id"]))
+	if err != nil {
+		return nil, err
+	}
+	query.Select(AllFields())
+	statement = query.SelectStatement()
+	res, err = statement.ExecuteToSingle(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	// if we have any files in our results we need to transform them to the object structure required
+	if scope.Model.HasFiles() {
+		res, err = transformModelFileResponses(scope.Context, scope.Model, res)
+	}
+
+	return res, err
+}
+
+func GenerateUpsertStatement(query *QueryBuilder, scope *Scope, input map[string]any) (*Statement, error) {
+	values, ok := input["values"].(map[string]any)
+	if !ok {
+		values = map[string]any{}
+	}
+
+	where, ok := input["where"].(map[string]any)
+	if !ok {
+		where = map[string]any{}
+	}
+
+	err := query.captureWriteValues(scope, values)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nested records would be created again whenever the existing record is updated
+	if len(query.writeValues.references) > 0 || len(query.writeValues.referencedBy) > 0 {
+		return nil, common.NewInputMalformedError("nested records cannot be created by an upsert action")
+	}
+
+	// The unique fields used to find an existing record are also written when the record is created.
+	// Relationships are found by their id, such as author.id, which is the foreign key on this model.
+	conflictFields := []string{}
+	message := proto.FindWhereInputMessage(scope.Schema, scope.Action.GetName())
+	for _, input := range message.GetFields() {
+		field := scope.Model.FindField(input.GetTarget()[0])
+		if field == nil {
+			return nil, fmt.Errorf("field not found on %s: %s", scope.Model.GetName(), input.GetTarget()[0])
+		}
+
+		fieldName := field.GetName()
+		if field.GetType().GetType() == proto.Type_TYPE_ENTITY {
+			fieldName = field.GetForeignKeyFieldName().GetValue()
+		}
+
+		value, ok := where[input.GetName()]
+		if !ok {
+			return nil, common.NewInputMalformedError(fmt.Sprintf("%s is required", input.GetName()))
+		}
+
+		query.AddWriteValue(Field(fieldName), Value(value))
+		conflictFields = append(conflictFields, fieldName)
+	}
+
+	err = query.captureSetValues(scope, upsertInputs(input))
+	if err != nil {
+		return nil, err
+	}
+
+	query.OnConflictUpdate(conflictFields...)
+
+	// Return the inserted or updated row
+	query.AppendReturning(AllFields())
+
+	return query.InsertStatement(scope.Context), nil
+}
+
+// errUpsertCreatedConcurrently is returned when the record an upsert was expected to create has been created by
+// someone else in the meantime, and so has been updated instead.
+var errUpsertCreatedConcurrently = errors.New("the record to upsert was created concurrently")
+
+// executeAuthorisedUpsert performs an upsert in a transaction, authorising the existing record, if there is one,
+// as well as the record which results from the upsert.
+func executeAuthorisedUpsert(scope *Scope, statement *Statement, inputs map[string]any, input map[string]any, opts []QueryBuilderOption) (res map[string]any, err error) {
+	database, err := db.GetDatabase(scope.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(scope.Context, func(ctx context.Context) error {
+		scope := scope.WithContext(ctx)
+
+		// If the record already exists then it must be permitted to be updated as it was before the update
+		existing, err := findUpsertExisting(scope, input, opts)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			isAuthorised, err := AuthoriseAction(scope, inputs, []map[string]any{existing})
+			if err != nil {
+				return err
+			}
+
+			if !isAuthorised {
+				return common.NewPermissionError()
+			}
+		}
+
+		// Execute database request, expecting a single result
+		res, err = statement.ExecuteToSingle(scope.Context)
+		if err != nil {
+			return err
+		}
+
+		inserted := res[casing.ToLowerCamel(upsertInsertedAlias)] == true
+		delete(res, casing.ToLowerCamel(upsertInsertedAlias))
+
+		if existing == nil && !inserted {
+			return errUpsertCreatedConcurrently
+		}
+
+		// Whether created or updated, the resulting record must also be permitted
+		isAuthorised, err := AuthoriseAction(scope, inputs, []map[string]any{res})
+		if err != nil {
+			return err
+		}
+
+		if !isAuthorised {
+			return common.NewPermissionError()
+		}
+
+		return nil
+	})
+
+	return res, err
+}
+
+// findUpsertExisting fetches the record which an upsert would update, or nil if it would create a new record.
+// The record is locked so that it can't be changed by others between being authorised and updated.
+func findUpsertExisting(scope *Scope, input map[string]any, opts []QueryBuilderOption) (map[string]any, error) {
+	where, ok := input["where"].(map[string]any)
+	if !ok {
+		where = map[string]any{}
+	}
+
+	query := NewQuery(scope.Model, opts...)
+	err := query.ApplyImplicitFilters(scope, where)
+	if err != nil {
+		return nil, err
+	}
+
+	query.ExcludeSoftDeleted()
+	query.Select(AllFields())
+	query.ForUpdate()
+
+	return query.SelectStatement().ExecuteToSingle(scope.Context)
+}
+
+// upsertInputs flattens the where and values inputs of an upsert action into a single map of inputs.
+func upsertInputs(input map[string]any) map[string]any {
+	inputs := map[string]any{}
+
+	if where, ok := input["where"].(map[string]any); ok {
+		for k, v := range where {
+			inputs[k] = v
+		}
+	}
+
+	if values, ok := input["values"].(map[string]any); ok {
+		for k, v := range values {
+			inputs[k] = v
+		}
+	}
+
+	return inputs
+}
@@ -0,0 +1,168 @@
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+// The pgvector distance operators for each metric. The inner product operator returns the negative
+// inner product, so that for every metric a smaller distance means a nearer vector.
+var vectorDistanceOperators = map[string]string{
+	parser.VectorMetricCosine:       "<=>",
+	parser.VectorMetricL2:           "<->",
+	parser.VectorMetricInnerProduct: "<#>",
+}
+
+// Filters rows to those within the maximum distance of the nearest input of a vector field, if one is given.
+func (query *QueryBuilder) whereVectorDistance(field *proto.Field, input map[string]any) error {
+	maxDistance, ok := input["maxDistance"]
+	if !ok || maxDistance == nil {
+		return nil
+	}
+
+	distance, err := query.vectorDistance(field, input)
+	if err != nil {
+		return err
+	}
+
+	err = query.Where(Raw(distance), LessThanEquals, Value(maxDistance))
+	if err != nil {
+		return err
+	}
+
+	query.And()
+
+	return nil
+}
+
+// Orders rows by their distance from the nearest input of each vector field in the where inputs, nearest first.
+// This is appended to any existing ordering, so that it only breaks ties.
+func (query *QueryBuilder) applyNearestOrdering(scope *Scope, where map[string]any) error {
+	message := proto.FindWhereInputMessage(scope.Schema, scope.Action.GetName())
+	if message == nil {
+		return nil
+	}
+
+	for _, input := range message.GetFields() {
+		field := scope.Model.FindField(input.GetName())
+		if field == nil || field.GetType().GetType() != proto.Type_TYPE_VECTOR {
+			continue
+		}
+
+		value, ok := where[input.GetName()].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		distance, err := query.vectorDistance(field, value)
+		if err != nil {
+			return err
+		}
+
+		query.AppendOrderBy(Raw(distance), "ASC")
+	}
+
+	return nil
+}
+
+// Whether the first page of a list can be ordered by the distance from a vector alone, which is the only ordering
+// a pgvector index can be used for. The rows must also not be made distinct, so there can't be any joins.
+func (query *QueryBuilder) isNearestOnly(page Page) bool {
+	return len(query.orderBy) == 1 &&
+		query.orderBy[0].field.IsRaw() &&
+		len(query.joins) == 0 &&
+		page.Cursor() == "" &&
+		!page.IsBackwards()
+}
+
+// Applies paging to a list which is only ordered by the distance from a vector, so that the query is a plain
+// ORDER BY distance LIMIT n and can use an index on the vector column. Without a tie-break on the id, rows which
+// are equally near may come in any order, and later pages fall back to ApplyPaging so that their cursor is stable.
+func (query *QueryBuilder) applyNearestPaging(page Page) {
+	// Without any joins each row is already distinct
+	query.distinctOn = nil
+
+	query.And()
+
+	if page.GetLimit() >= 0 {
+		query.Limit(page.GetLimit())
+	}
+
+	query.selectPageInfo()
+
+	if page.OffsetPagination() {
+		query.Offset(page.Offset)
+	}
+}
+
+// Generates the SQL for the distance between a vector field and the nearest input. The distance is used
+// in the filters, the ordering, the paging window and the cursor filter, so the vector is embedded in
+// the SQL rather than passed as an argument each time.
+func (query *QueryBuilder) vectorDistance(field *proto.Field, input map[string]any) (string, error) {
+	index := field.GetVectorIndex()
+
+	metric := parser.VectorMetricCosine
+	switch index.GetMetric() {
+	case proto.VectorDistanceMetric_VECTOR_DISTANCE_METRIC_L2:
+		metric = parser.VectorMetricL2
+	case proto.VectorDistanceMetric_VECTOR_DISTANCE_METRIC_INNER_PRODUCT:
+		metric = parser.VectorMetricInnerProduct
+	}
+
+	if m, ok := input["metric"].(string); ok {
+		metric = m
+	}
+
+	operator, ok := vectorDistanceOperators[metric]
+	if !ok {
+		return "", common.NewInputMalformedError(fmt.Sprintf("'%s' is not a valid metric, it must be one of cosine, l2 or innerProduct", metric))
+	}
+
+	nearest, err := toVector(input["nearest"])
+	if err != nil {
+		return "", err
+	}
+
+	column := Field(field.GetName()).toSqlOperandString(query)
+
+	// Indexed vector columns have a fixed number of dimensions, and pgvector errors on vectors of different sizes
+	if index != nil && len(nearest) != int(index.GetDimensions()) {
+		return "", common.NewInputMalformedError(fmt.Sprintf("nearest must have %d dimensions", index.GetDimensions()))
+	}
+
+	elements := make([]string, len(nearest))
+	for i, v := range nearest {
+		elements[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	return fmt.Sprintf("(%s %s '[%s]'::vector)", column, operator, strings.Join(elements, ",")), nil
+}
+
+func toVector(value any) ([]float64, error) {
+	var vector []float64
+	switch v := value.(type) {
+	case []float64:
+		vector = v
+	case []any:
+		for _, e := range v {
+			f, err := toFloat(e)
+			if err != nil {
+				return nil, common.NewInputMalformedError("nearest must be a list of numbers")
+			}
+			vector = append(vector, f)
+		}
+	default:
+		return nil, common.NewInputMalformedError("nearest must be a list of numbers")
+	}
+
+	if len(vector) == 0 {
+		return nil, common.NewInputMalformedError("nearest must have at least one dimension")
+	}
+
+	return vector, nil
+}
@@ -338,6 +338,9 @@ func targetAssociating(scope *Scope, target []string) bool {
 	}
 
 	message := scope.Schema.FindMessage(scope.Action.GetInputMessageName())
+	if scope.Action.IsBulk() {
+		message = proto.FindItemInputMessage(scope.Schema, scope.Action.GetName())
+	}
 	model := scope.Schema.FindModel(strcase.ToCamel(target[0]))
 	for _, t := range target[1 : len(target)-1] {
 		found := false
@@ -0,0 +1,220 @@
+package authapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+var serviceAccountTestSchema = `
+model Invoice {
+	fields {
+		reference Text
+	}
+	actions {
+		list listInvoices() {
+			@permission(expression: ctx.isServiceAccount && "invoices:read" in ctx.scopes)
+		}
+	}
+}`
+
+func TestClientCredentialsGrant_Valid(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), serviceAccountTestSchema, true)
+	defer database.Close()
+
+	account, secret, err := oauth.CreateServiceAccount(ctx, "billing", []string{"invoices:read", "invoices:write"})
+	require.NoError(t, err)
+
+	request := makeClientCredentialsFormRequest(ctx, account.ClientId, secret, "")
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, request)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, response.AccessToken)
+	require.Equal(t, "bearer", response.TokenType)
+	require.NotEmpty(t, response.ExpiresIn)
+	require.Empty(t, response.RefreshToken)
+	require.Equal(t, "invoices:read invoices:write", response.Scope)
+
+	accessTokenSub, err := oauth.ExtractClaimFromJwt(response.AccessToken, "sub")
+	require.NoError(t, err)
+	require.Equal(t, account.Id, accessTokenSub)
+
+	// The token is accepted by the API and satisfies the permission rule
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeServiceAccountApiRequest(ctx, response.AccessToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	accounts, err := oauth.ListServiceAccounts(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.NotNil(t, accounts[0].LastUsedAt)
+}
+
+func TestClientCredentialsGrant_BasicAuth(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), serviceAccountTestSchema, true)
+	defer database.Close()
+
+	account, secret, err := oauth.CreateServiceAccount(ctx, "billing", []string{"invoices:read"})
+	require.NoError(t, err)
+
+	request := makeClientCredentialsFormRequest(ctx, "", "", "")
+	request.SetBasicAuth(account.ClientId, secret)
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, response.AccessToken)
+}
+
+func TestClientCredentialsGrant_IncorrectSecret(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), serviceAccountTestSchema, true)
+	defer database.Close()
+
+	account, _, err := oauth.CreateServiceAccount(ctx, "billing", []string{"invoices:read"})
+	require.NoError(t, err)
+
+	request := makeClientCredentialsFormRequest(ctx, account.ClientId, "whoops!", "")
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+}
+
+func TestClientCredentialsGrant_MissingCredentials(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), serviceAccountTestSchema, true)
+	defer database.Close()
+
+	request := makeClientCredentialsFormRequest(ctx, "", "", "")
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "invalid_request", errorResponse.Error)
+}
+
+func TestClientCredentialsGrant_RequestedScope(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), serviceAccountTestSchema, true)
+	defer database.Close()
+
+	account, secret, err := oauth.CreateServiceAccount(ctx, "billing", []string{"invoices:read", "invoices:write"})
+	require.NoError(t, err)
+
+	// A token with only the write scope cannot list invoices
+	request := makeClientCredentialsFormRequest(ctx, account.ClientId, secret, "invoices:write")
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Equal(t, "invoices:write", response.Scope)
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeServiceAccountApiRequest(ctx, response.AccessToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+
+	// Scopes which the service account has not been granted cannot be requested
+	request = makeClientCredentialsFormRequest(ctx, account.ClientId, secret, "invoices:read invoices:delete")
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "invalid_scope", errorResponse.Error)
+}
+
+func TestClientCredentialsGrant_Revoked(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), serviceAccountTestSchema, true)
+	defer database.Close()
+
+	account, secret, err := oauth.CreateServiceAccount(ctx, "billing", []string{"invoices:read"})
+	require.NoError(t, err)
+
+	request := makeClientCredentialsFormRequest(ctx, account.ClientId, secret, "")
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	revoked, err := oauth.RevokeServiceAccount(ctx, "billing")
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	// Tokens already issued are rejected
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeServiceAccountApiRequest(ctx, response.AccessToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	// And no more can be issued
+	request = makeClientCredentialsFormRequest(ctx, account.ClientId, secret, "")
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+}
+
+func TestClientCredentialsGrant_ConfigServiceAccount(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), serviceAccountTestSchema, true)
+	defer database.Close()
+
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		ServiceAccounts: []config.ServiceAccount{
+			{Name: "reporting", Scopes: []string{"invoices:read"}},
+		},
+	})
+	ctx = runtimectx.WithSecrets(ctx, map[string]string{
+		"SERVICE_ACCOUNT_SECRET_REPORTING": "my-secret",
+	})
+
+	request := makeClientCredentialsFormRequest(ctx, "reporting", "my-secret", "")
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Equal(t, "invoices:read", response.Scope)
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeServiceAccountApiRequest(ctx, response.AccessToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	request = makeClientCredentialsFormRequest(ctx, "reporting", "wrong-secret", "")
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+}
+
+func makeClientCredentialsFormRequest(ctx context.Context, clientId string, clientSecret string, scope string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so/auth/token", nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("grant_type", "client_credentials")
+	if clientId != "" {
+		form.Add("client_id", clientId)
+	}
+	if clientSecret != "" {
+		form.Add("client_secret", clientSecret)
+	}
+	if scope != "" {
+		form.Add("scope", scope)
+	}
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
+
+func makeServiceAccountApiRequest(ctx context.Context, token string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so/api/json/listInvoices", nil)
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Authorization", "Bearer "+token)
+	request = request.WithContext(ctx)
+
+	return request
+}
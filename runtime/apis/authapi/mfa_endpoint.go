@@ -0,0 +1,324 @@
+package authapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/auth"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/ratelimit"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/schema/parser"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ArgMfaToken = "mfa_token"
+)
+
+const (
+	// The number of codes an identity can attempt in mfaAttemptPeriod, which is as long as an MFA token is valid for.
+	mfaMaxAttempts   = 5
+	mfaAttemptPeriod = oauth.MfaTokenExpiry
+)
+
+type MfaEnrolResponse struct {
+	Secret        string   `json:"secret"`
+	OtpAuthUri    string   `json:"otpauth_uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MfaRequiredResponse struct {
+	ErrorResponse
+	MfaToken string `json:"mfa_token"`
+	Enrolled bool   `json:"mfa_enrolled"`
+}
+
+// MfaEnrolHandler issues a new TOTP secret, along with its otpauth URI for authenticator apps and a set of recovery codes.
+// The identity is authenticated with its access token or, when it must enrol to sign in, with the MFA token from the
+// mfa_required error. The enrolment only takes effect once confirmed at the confirm endpoint.
+func MfaEnrolHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "MFA Enrol Endpoint")
+		defer span.End()
+
+		inputs, errResponse := parseMfaRequest(ctx, r)
+		if errResponse != nil {
+			return *errResponse
+		}
+
+		identity, errResponse := mfaIdentity(ctx, schema, r, inputs, true)
+		if errResponse != nil {
+			return *errResponse
+		}
+
+		accountName, _ := identity[parser.IdentityFieldNameEmail].(string)
+		if accountName == "" {
+			accountName = identity[parser.FieldNameId].(string)
+		}
+
+		enrolment, err := oauth.EnrolMfa(ctx, identity[parser.FieldNameId].(string), accountName)
+		if errors.Is(err, oauth.ErrMfaAlreadyEnabled) {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "multi-factor authentication is already enabled for this identity", nil)
+		}
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, &MfaEnrolResponse{
+			Secret:        enrolment.Secret,
+			OtpAuthUri:    enrolment.Uri,
+			RecoveryCodes: enrolment.RecoveryCodes,
+		}, nil)
+	}
+}
+
+// MfaConfirmHandler enables multi-factor authentication for the identity once it provides a code from its authenticator
+// app for the pending enrolment. An identity which enrolled to sign in can then complete the challenge with the mfa grant.
+func MfaConfirmHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "MFA Confirm Endpoint")
+		defer span.End()
+
+		inputs, errResponse := parseMfaRequest(ctx, r)
+		if errResponse != nil {
+			return *errResponse
+		}
+
+		identity, errResponse := mfaIdentity(ctx, schema, r, inputs, true)
+		if errResponse != nil {
+			return *errResponse
+		}
+
+		code, hasCode := inputs[ArgCode].(string)
+		if !hasCode || code == "" {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the code from the authenticator app in the 'code' field is required", nil)
+		}
+
+		isValid, err := oauth.ConfirmMfa(ctx, identity[parser.FieldNameId].(string), code)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if !isValid {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "possible causes may be that the code is incorrect or there is no enrolment to confirm", nil)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, nil, nil)
+	}
+}
+
+// MfaDisableHandler removes multi-factor authentication from the identity, which must be authenticated with its access token
+// and provide a code from its authenticator app or a recovery code. It cannot be removed if it is required for the identity's role.
+func MfaDisableHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "MFA Disable Endpoint")
+		defer span.End()
+
+		cfg, err := runtimectx.GetOAuthConfig(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		inputs, errResponse := parseMfaRequest(ctx, r)
+		if errResponse != nil {
+			return *errResponse
+		}
+
+		identity, errResponse := mfaIdentity(ctx, schema, r, inputs, false)
+		if errResponse != nil {
+			return *errResponse
+		}
+
+		code, hasCode := inputs[ArgCode].(string)
+		if !hasCode || code == "" {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "a code from the authenticator app or a recovery code in the 'code' field is required", nil)
+		}
+
+		if actions.IdentityHasAnyRole(schema, identity, cfg.MfaRequiredRoles()) {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "multi-factor authentication is required for this identity's role", nil)
+		}
+
+		if errResponse := verifyMfaAttempt(ctx, identity, code); errResponse != nil {
+			return *errResponse
+		}
+
+		err = oauth.DisableMfa(ctx, identity[parser.FieldNameId].(string))
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, nil, nil)
+	}
+}
+
+// verifyMfaAttempt checks the code from the identity's authenticator app or recovery code. The attempt is counted
+// against the identity before the code is checked, so that no more than mfaMaxAttempts can be made within
+// mfaAttemptPeriod however many MFA tokens are issued or requests are made at once. The attempt is also reserved
+// towards locking out the identity's email and the client's IP address, as for the password grant.
+func verifyMfaAttempt(ctx context.Context, identity auth.Identity, code string) *common.Response {
+	identityId := identity[parser.FieldNameId].(string)
+	emailAddress, _ := identity[parser.IdentityFieldNameEmail].(string)
+	clientIp := runtimectx.GetClientIP(ctx)
+
+	allowed, lockedUntil, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionMfa, emailAddress, clientIp)
+	if err != nil {
+		resp := common.InternalServerErrorResponse(ctx, err)
+		return &resp
+	}
+
+	if !allowed {
+		resp := lockedOutResponse(ctx, *lockedUntil)
+		return &resp
+	}
+
+	err = ratelimit.Take(ctx, "auth/mfa/identity:"+identityId, mfaMaxAttempts, mfaAttemptPeriod)
+	var rateLimitErr common.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		resp := jsonErrResponse(ctx, http.StatusTooManyRequests, TokenErrTooManyAttempts, "too many attempts at a multi-factor authentication code have been made", nil)
+		resp.Headers = common.RetryAfterHeaders(rateLimitErr)
+		return &resp
+	}
+	if err != nil {
+		resp := common.InternalServerErrorResponse(ctx, err)
+		return &resp
+	}
+
+	isValid, err := oauth.VerifyMfa(ctx, identityId, code)
+	if err != nil {
+		resp := common.InternalServerErrorResponse(ctx, err)
+		return &resp
+	}
+
+	if !isValid {
+		if lockedUntil != nil {
+			resp := lockedOutResponse(ctx, *lockedUntil)
+			return &resp
+		}
+
+		resp := jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the code is incorrect, has already been used or multi-factor authentication is not enabled", nil)
+		return &resp
+	}
+
+	err = oauth.ResetFailedAttempts(ctx, oauth.LockoutActionMfa, emailAddress)
+	if err != nil {
+		resp := common.InternalServerErrorResponse(ctx, err)
+		return &resp
+	}
+
+	return nil
+}
+
+func parseMfaRequest(ctx context.Context, r *http.Request) (map[string]any, *common.Response) {
+	if r.Method != http.MethodPost {
+		resp := jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the mfa endpoints only accept POST", nil)
+		return nil, &resp
+	}
+
+	if !common.HasContentType(r.Header, "application/x-www-form-urlencoded") && !common.HasContentType(r.Header, "application/json") {
+		resp := jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the request body must either be an encoded form (Content-Type: application/x-www-form-urlencoded) or JSON (Content-Type: application/json)", nil)
+		return nil, &resp
+	}
+
+	data, err := common.ParseRequestData(r)
+	if err != nil {
+		resp := jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+		return nil, &resp
+	}
+
+	inputs, ok := data.(map[string]any)
+	if !ok {
+		resp := jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+		return nil, &resp
+	}
+
+	return inputs, nil
+}
+
+// mfaIdentity authenticates the identity with the access token in the Authorization header or, if allowed, the
+// MFA token issued with an mfa_required error.
+func mfaIdentity(ctx context.Context, schema *proto.Schema, r *http.Request, inputs map[string]any, allowMfaToken bool) (auth.Identity, *common.Response) {
+	var identity auth.Identity
+	var err error
+
+	if mfaToken, hasMfaToken := inputs[ArgMfaToken].(string); hasMfaToken && mfaToken != "" && allowMfaToken {
+		identityId, err := oauth.ValidateMfaToken(ctx, mfaToken)
+		if err != nil {
+			resp := jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the mfa token is invalid or has expired", err)
+			return nil, &resp
+		}
+
+		identity, err = actions.FindIdentityById(ctx, schema, identityId)
+		if err != nil {
+			resp := common.InternalServerErrorResponse(ctx, err)
+			return nil, &resp
+		}
+	} else {
+		identity, err = actions.HandleAuthorizationHeader(ctx, schema, r.Header)
+		if err != nil {
+			resp := jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the access token is invalid or has expired", err)
+			return nil, &resp
+		}
+	}
+
+	if identity == nil {
+		resp := jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity must be authenticated", nil)
+		return nil, &resp
+	}
+
+	return identity, nil
+}
+
+// mfaChallenge generates an MFA token if the identity must authenticate with a second factor before tokens are issued,
+// which is the case if it has enabled multi-factor authentication or has a role which requires it.
+func mfaChallenge(ctx context.Context, schema *proto.Schema, cfg *config.AuthConfig, identity auth.Identity) (mfaToken string, enrolled bool, err error) {
+	identityId := identity[parser.FieldNameId].(string)
+
+	enrolled, err = oauth.MfaEnabled(ctx, identityId)
+	if err != nil {
+		return "", false, err
+	}
+
+	if !enrolled && !actions.IdentityHasAnyRole(schema, identity, cfg.MfaRequiredRoles()) {
+		return "", false, nil
+	}
+
+	mfaToken, err = oauth.GenerateMfaToken(ctx, identityId)
+	if err != nil {
+		return "", false, err
+	}
+
+	return mfaToken, enrolled, nil
+}
+
+// mfaRequiredResponse is returned instead of tokens when the identity must authenticate with a second factor,
+// along with the MFA token with which to enrol (if not yet enrolled) and complete the mfa grant.
+func mfaRequiredResponse(ctx context.Context, mfaToken string, enrolled bool) common.Response {
+	description := "a code from the authenticator app or a recovery code must be provided with the mfa grant"
+	if !enrolled {
+		description = "multi-factor authentication is required for this identity and must be enrolled in"
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetStatus(codes.Error, TokenErrMfaRequired)
+	span.SetAttributes(
+		attribute.String("auth.error", TokenErrMfaRequired),
+		attribute.String("auth.error_description", description),
+	)
+
+	return common.NewJsonResponse(http.StatusForbidden, &MfaRequiredResponse{
+		ErrorResponse: ErrorResponse{
+			Error:            TokenErrMfaRequired,
+			ErrorDescription: description,
+		},
+		MfaToken: mfaToken,
+		Enrolled: enrolled,
+	}, nil)
+}
@@ -0,0 +1,430 @@
+package authapi_test
+
+import (
+	"context"
+	"encoding/base32"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/oauth/oauthtest"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+var mfaRoleTestSchema = `
+model Post{}
+
+role Admin {
+	domains {
+		"keel.so"
+	}
+}`
+
+func TestMfa_EnrolAndSignIn(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	enrolment, httpResponse, err := handleRuntimeRequest[authapi.MfaEnrolResponse](schema, makeMfaRequest(ctx, "/auth/mfa/enrol", response.AccessToken, url.Values{}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, enrolment.Secret)
+	require.Len(t, enrolment.RecoveryCodes, 10)
+
+	uri, err := url.Parse(enrolment.OtpAuthUri)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Keel:keelson@keel.so", uri.Path)
+	require.Equal(t, enrolment.Secret, uri.Query().Get("secret"))
+	require.Equal(t, "Keel", uri.Query().Get("issuer"))
+
+	// Until confirmed, tokens are issued without a second factor
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	code := totpCode(t, enrolment.Secret, time.Now())
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeMfaRequest(ctx, "/auth/mfa/confirm", response.AccessToken, url.Values{"code": {code}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	var refreshTokens []map[string]any
+	database.GetDB().Raw("SELECT * FROM keel_refresh_token").Scan(&refreshTokens)
+	require.Len(t, refreshTokens, 2)
+
+	mfaResponse, httpResponse, err := handleRuntimeRequest[authapi.MfaRequiredResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+	require.Equal(t, "mfa_required", mfaResponse.Error)
+	require.True(t, mfaResponse.Enrolled)
+	require.NotEmpty(t, mfaResponse.MfaToken)
+
+	// No refresh token is issued until the challenge is completed
+	database.GetDB().Raw("SELECT * FROM keel_refresh_token").Scan(&refreshTokens)
+	require.Len(t, refreshTokens, 2)
+
+	// The MFA token cannot be used as an access token
+	_, err = oauth.ValidateAccessToken(ctx, mfaResponse.MfaToken)
+	require.ErrorIs(t, err, oauth.ErrInvalidToken)
+
+	tokens, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makeMfaGrantRequest(ctx, mfaResponse.MfaToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, tokens.AccessToken)
+	require.NotEmpty(t, tokens.RefreshToken)
+
+	sub, err := oauth.ValidateAccessToken(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Equal(t, identities[0]["id"], sub)
+
+	// A code cannot be used twice
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, mfaResponse.MfaToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+
+	// A recovery code can be used once instead
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makeMfaGrantRequest(ctx, mfaResponse.MfaToken, enrolment.RecoveryCodes[0]))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, mfaResponse.MfaToken, enrolment.RecoveryCodes[0]))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	// It cannot be enrolled in again while enabled
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/mfa/enrol", tokens.AccessToken, url.Values{}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "multi-factor authentication is already enabled for this identity", errorResponse.ErrorDescription)
+}
+
+func TestMfa_ConfirmIncorrectCode(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	enrolment, httpResponse, err := handleRuntimeRequest[authapi.MfaEnrolResponse](schema, makeMfaRequest(ctx, "/auth/mfa/enrol", response.AccessToken, url.Values{}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	code := totpCode(t, enrolment.Secret, time.Now().Add(-5*time.Minute))
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/mfa/confirm", response.AccessToken, url.Values{"code": {code}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "invalid_request", errorResponse.Error)
+
+	// Recovery codes cannot confirm an enrolment
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/mfa/confirm", response.AccessToken, url.Values{"code": {enrolment.RecoveryCodes[0]}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "invalid_request", errorResponse.Error)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestMfa_RequiredForRole(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), mfaRoleTestSchema, true)
+	defer database.Close()
+
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Mfa: config.MfaConfig{RequiredRoles: []string{"Admin"}},
+	})
+
+	mfaResponse, httpResponse, err := handleRuntimeRequest[authapi.MfaRequiredResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+	require.Equal(t, "mfa_required", mfaResponse.Error)
+	require.Equal(t, "multi-factor authentication is required for this identity and must be enrolled in", mfaResponse.ErrorDescription)
+	require.False(t, mfaResponse.Enrolled)
+
+	// The identity is not authenticated, so can only enrol using the MFA token
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/mfa/enrol", "", url.Values{}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+
+	enrolment, httpResponse, err := handleRuntimeRequest[authapi.MfaEnrolResponse](schema, makeMfaRequest(ctx, "/auth/mfa/enrol", "", url.Values{"mfa_token": {mfaResponse.MfaToken}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	code := totpCode(t, enrolment.Secret, time.Now())
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeMfaRequest(ctx, "/auth/mfa/confirm", "", url.Values{"mfa_token": {mfaResponse.MfaToken}, "code": {code}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	tokens, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makeMfaGrantRequest(ctx, mfaResponse.MfaToken, code))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, tokens.AccessToken)
+
+	// It cannot be disabled while required for the role
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/mfa/disable", tokens.AccessToken, url.Values{"code": {enrolment.RecoveryCodes[0]}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "multi-factor authentication is required for this identity's role", errorResponse.ErrorDescription)
+
+	// Identities without the role are not challenged
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "weaveton@gmail.com", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestMfa_RequiredForRole_TokenExchange(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), mfaRoleTestSchema, true)
+	defer database.Close()
+
+	server, err := oauthtest.NewServer()
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Mfa: config.MfaConfig{RequiredRoles: []string{"Admin"}},
+		Providers: []config.Provider{
+			{
+				Type:      config.OpenIdConnectProvider,
+				Name:      "my-oidc",
+				ClientId:  "oidc-client-id",
+				IssuerUrl: server.Issuer,
+			},
+		},
+	})
+
+	server.SetUser("id|285620", &oauth.UserClaims{Email: "keelson@keel.so", EmailVerified: true})
+	server.SetUser("id|285621", &oauth.UserClaims{Email: "weaveton@gmail.com", EmailVerified: true})
+
+	// Exchanging an ID token does not skip the second factor required for the identity's role
+	idToken, err := server.FetchIdToken("id|285620", []string{"oidc-client-id"})
+	require.NoError(t, err)
+
+	mfaResponse, httpResponse, err := handleRuntimeRequest[authapi.MfaRequiredResponse](schema, makeTokenExchangeFormRequest(ctx, idToken, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+	require.Equal(t, "mfa_required", mfaResponse.Error)
+	require.NotEmpty(t, mfaResponse.MfaToken)
+	require.False(t, mfaResponse.Enrolled)
+
+	var sessions []map[string]any
+	database.GetDB().Raw("SELECT * FROM keel_session").Scan(&sessions)
+	require.Empty(t, sessions)
+
+	// Identities without the role are not challenged
+	idToken, err = server.FetchIdToken("id|285621", []string{"oidc-client-id"})
+	require.NoError(t, err)
+
+	tokens, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makeTokenExchangeFormRequest(ctx, idToken, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, tokens.AccessToken)
+	require.NotEmpty(t, tokens.RefreshToken)
+}
+
+func TestMfa_Disable(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	enrolment, _, err := handleRuntimeRequest[authapi.MfaEnrolResponse](schema, makeMfaRequest(ctx, "/auth/mfa/enrol", response.AccessToken, url.Values{}))
+	require.NoError(t, err)
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeMfaRequest(ctx, "/auth/mfa/confirm", response.AccessToken, url.Values{"code": {totpCode(t, enrolment.Secret, time.Now())}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	// An MFA token cannot be used to disable
+	mfaResponse, _, err := handleRuntimeRequest[authapi.MfaRequiredResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/mfa/disable", "", url.Values{"mfa_token": {mfaResponse.MfaToken}, "code": {enrolment.RecoveryCodes[0]}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/mfa/disable", response.AccessToken, url.Values{"code": {"wrong"}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeMfaRequest(ctx, "/auth/mfa/disable", response.AccessToken, url.Values{"code": {strings.ToUpper(enrolment.RecoveryCodes[0])}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	var codes []map[string]any
+	database.GetDB().Raw("SELECT * FROM keel_mfa_recovery_code").Scan(&codes)
+	require.Empty(t, codes)
+}
+
+func TestMfa_LockedOut(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	mfaToken, secret := enrolMfaAndSignIn(t, ctx, schema)
+
+	for range config.DefaultLockoutMaxAttempts - 1 {
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, mfaToken, "not-a-code"))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, mfaToken, "not-a-code"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, "too_many_attempts", errorResponse.Error)
+
+	// The correct code is rejected while locked out
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, mfaToken, totpCode(t, secret, time.Now())))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+
+	lockouts, err := oauth.ListLockouts(ctx)
+	require.NoError(t, err)
+	require.Len(t, lockouts, 1)
+	require.Equal(t, oauth.LockoutActionMfa, lockouts[0].Action)
+	require.Equal(t, "keelson@keel.so", lockouts[0].Subject)
+}
+
+func TestMfa_MaxAttempts(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	disabled := false
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{Enabled: &disabled},
+	})
+
+	mfaToken, secret := enrolMfaAndSignIn(t, ctx, schema)
+
+	for range 5 {
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, mfaToken, "not-a-code"))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	// Attempts are still limited without lockouts, and getting another MFA token does not allow more
+	mfaResponse, httpResponse, err := handleRuntimeRequest[authapi.MfaRequiredResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, mfaResponse.MfaToken, totpCode(t, secret, time.Now())))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, "too_many_attempts", errorResponse.Error)
+	require.NotEmpty(t, httpResponse.Header.Get("Retry-After"))
+}
+
+func TestMfa_InvalidGrant(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, "", "123456"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "the mfa token from the mfa_required error in the 'mfa_token' field is required", errorResponse.ErrorDescription)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, "invalid", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "a code from the authenticator app or a recovery code in the 'code' field is required", errorResponse.ErrorDescription)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, "invalid", "123456"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "possible causes may be that the mfa token is invalid or has expired", errorResponse.ErrorDescription)
+
+	// An access token is not an MFA token
+	response, _, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaGrantRequest(ctx, response.AccessToken, "123456"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+}
+
+// enrolMfaAndSignIn creates an identity enrolled in multi-factor authentication and signs in with its password,
+// returning the MFA token from the mfa_required error and the TOTP secret.
+func enrolMfaAndSignIn(t *testing.T, ctx context.Context, schema *proto.Schema) (string, string) {
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	enrolment, httpResponse, err := handleRuntimeRequest[authapi.MfaEnrolResponse](schema, makeMfaRequest(ctx, "/auth/mfa/enrol", response.AccessToken, url.Values{}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeMfaRequest(ctx, "/auth/mfa/confirm", response.AccessToken, url.Values{"code": {totpCode(t, enrolment.Secret, time.Now())}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	mfaResponse, httpResponse, err := handleRuntimeRequest[authapi.MfaRequiredResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+
+	return mfaResponse.MfaToken, enrolment.Secret
+}
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	require.NoError(t, err)
+
+	return oauth.TotpCode(key, oauth.TotpStep(at))
+}
+
+func makeMfaRequest(ctx context.Context, path string, accessToken string, form url.Values) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so"+path, nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	if accessToken != "" {
+		request.Header.Add("Authorization", "Bearer "+accessToken)
+	}
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
+
+func makeMfaGrantRequest(ctx context.Context, mfaToken string, code string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so/auth/token", nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("grant_type", "mfa")
+	form.Add("mfa_token", mfaToken)
+	form.Add("code", code)
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
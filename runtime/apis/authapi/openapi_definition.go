@@ -18,6 +18,8 @@ func OAuthOpenApiSchema() common.HandlerFunc {
 
 		boolTrue := true
 		boolFalse := false
+		passwordlessMethodCode := PasswordlessMethodCode
+		passwordlessMethodLink := PasswordlessMethodLink
 
 		definition := openapi.OpenAPI{
 			OpenAPI: openapi.OpenApiSpecificationVersion,
@@ -103,6 +105,26 @@ func OAuthOpenApiSchema() common.HandlerFunc {
 							},
 						},
 					},
+					"403": {
+						Description: "Token Request Requires Email Verification or Multi-Factor Authentication",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+					"429": {
+						Description: "Token Request Locked Out After Too Many Failed Attempts",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
 				},
 			},
 		}
@@ -180,6 +202,376 @@ func OAuthOpenApiSchema() common.HandlerFunc {
 			},
 		}
 
+		definition.Paths["/auth/passwordless"] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				RequestBody: &openapi.RequestBodyObject{
+					Description: "Passwordless Request",
+					Content: map[string]openapi.MediaTypeObject{
+						"application/json": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/PasswordlessRequest",
+							},
+						},
+						"application/x-www-form-urlencoded": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/PasswordlessRequest",
+							},
+						},
+					},
+					Required: &boolTrue,
+				},
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "Magic Link or One-Time Code Sent",
+					},
+					"400": {
+						Description: "Passwordless Request Badly Formed",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+					"429": {
+						Description: "Code Sent to the Email Address Too Recently",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		definition.Paths["/auth/verify"] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				RequestBody: &openapi.RequestBodyObject{
+					Description: "Verify Email Request",
+					Content: map[string]openapi.MediaTypeObject{
+						"application/json": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/VerifyEmailRequest",
+							},
+						},
+						"application/x-www-form-urlencoded": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/VerifyEmailRequest",
+							},
+						},
+					},
+					Required: &boolTrue,
+				},
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "Email Verified",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/VerifyEmailResponse",
+								},
+							},
+						},
+					},
+					"400": {
+						Description: "Verify Email Request Badly Formed",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+					"401": {
+						Description: "Verification Token Invalid or Expired",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		definition.Paths["/auth/verify/resend"] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				RequestBody: &openapi.RequestBodyObject{
+					Description: "Resend Verification Request",
+					Content: map[string]openapi.MediaTypeObject{
+						"application/json": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/ResendVerificationRequest",
+							},
+						},
+						"application/x-www-form-urlencoded": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/ResendVerificationRequest",
+							},
+						},
+					},
+					Required: &boolTrue,
+				},
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "Verification Email Sent",
+					},
+					"400": {
+						Description: "Resend Verification Request Badly Formed",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		definition.Paths["/auth/mfa/enrol"] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				RequestBody: &openapi.RequestBodyObject{
+					Description: "MFA Enrol Request",
+					Content: map[string]openapi.MediaTypeObject{
+						"application/json": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/MfaEnrolRequest",
+							},
+						},
+						"application/x-www-form-urlencoded": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/MfaEnrolRequest",
+							},
+						},
+					},
+					Required: &boolTrue,
+				},
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "MFA Enrolment Pending Confirmation",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/MfaEnrolResponse",
+								},
+							},
+						},
+					},
+					"400": {
+						Description: "MFA Enrol Request Badly Formed",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+					"401": {
+						Description: "Identity Not Authenticated",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		definition.Paths["/auth/mfa/confirm"] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				RequestBody: &openapi.RequestBodyObject{
+					Description: "MFA Confirm Request",
+					Content: map[string]openapi.MediaTypeObject{
+						"application/json": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/MfaConfirmRequest",
+							},
+						},
+						"application/x-www-form-urlencoded": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/MfaConfirmRequest",
+							},
+						},
+					},
+					Required: &boolTrue,
+				},
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "MFA Enabled",
+					},
+					"400": {
+						Description: "MFA Confirm Request Badly Formed or Code Incorrect",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+					"401": {
+						Description: "Identity Not Authenticated",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		definition.Paths["/auth/mfa/disable"] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				RequestBody: &openapi.RequestBodyObject{
+					Description: "MFA Disable Request",
+					Content: map[string]openapi.MediaTypeObject{
+						"application/json": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/MfaDisableRequest",
+							},
+						},
+						"application/x-www-form-urlencoded": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/MfaDisableRequest",
+							},
+						},
+					},
+					Required: &boolTrue,
+				},
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "MFA Disabled",
+					},
+					"400": {
+						Description: "MFA Disable Request Badly Formed",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+					"401": {
+						Description: "Identity Not Authenticated or Code Incorrect",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		definition.Paths["/auth/sessions"] = openapi.PathItemObject{
+			Get: &openapi.OperationObject{
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "Sessions",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/SessionsResponse",
+								},
+							},
+						},
+					},
+					"401": {
+						Description: "Identity Not Authenticated",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		definition.Paths["/auth/sessions/revoke"] = openapi.PathItemObject{
+			Post: &openapi.OperationObject{
+				RequestBody: &openapi.RequestBodyObject{
+					Description: "Revoke Sessions Request",
+					Content: map[string]openapi.MediaTypeObject{
+						"application/json": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/RevokeSessionsRequest",
+							},
+						},
+						"application/x-www-form-urlencoded": {
+							Schema: jsonschema.JSONSchema{
+								Ref: "#/components/schemas/RevokeSessionsRequest",
+							},
+						},
+					},
+					Required: &boolTrue,
+				},
+				Responses: map[string]openapi.ResponseObject{
+					"200": {
+						Description: "Sessions Revoked",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/RevokeSessionsResponse",
+								},
+							},
+						},
+					},
+					"400": {
+						Description: "Revoke Sessions Request Badly Formed",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+					"401": {
+						Description: "Identity or Service Account Not Authenticated",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+					"403": {
+						Description: "Service Account Not Granted Scope",
+						Content: map[string]openapi.MediaTypeObject{
+							"application/json": {
+								Schema: jsonschema.JSONSchema{
+									Ref: "#/components/schemas/TokenErrorResponse",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
 		definition.Components.Schemas["ProvidersResponse"] = jsonschema.JSONSchema{
 			Type: "object",
 			Properties: map[string]jsonschema.JSONSchema{
@@ -222,6 +614,43 @@ func OAuthOpenApiSchema() common.HandlerFunc {
 					Required: []string{"grant_type", "username", "password"},
 					Title:    "Password",
 				},
+				{
+					Type: "object",
+					Properties: map[string]jsonschema.JSONSchema{
+						"grant_type": {
+							Const:   "passwordless",
+							Default: "passwordless",
+						},
+						"username": {
+							Type: "string",
+						},
+						"code": {
+							Type: "string",
+						},
+						"create_if_not_exists": {
+							Type: "boolean",
+						},
+					},
+					Required: []string{"grant_type", "username", "code"},
+					Title:    "Passwordless",
+				},
+				{
+					Type: "object",
+					Properties: map[string]jsonschema.JSONSchema{
+						"grant_type": {
+							Const:   "mfa",
+							Default: "mfa",
+						},
+						"mfa_token": {
+							Type: "string",
+						},
+						"code": {
+							Type: "string",
+						},
+					},
+					Required: []string{"grant_type", "mfa_token", "code"},
+					Title:    "Multi-Factor Authentication",
+				},
 				{
 					Type: "object",
 					Properties: map[string]jsonschema.JSONSchema{
@@ -270,6 +699,163 @@ func OAuthOpenApiSchema() common.HandlerFunc {
 			},
 		}
 
+		definition.Components.Schemas["PasswordlessRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"email": {
+					Type: "string",
+				},
+				"method": {
+					Type: "string",
+					Enum: []*string{&passwordlessMethodCode, &passwordlessMethodLink},
+				},
+				"redirect_url": {
+					Type: "string",
+				},
+			},
+			Required: []string{"email"},
+		}
+
+		definition.Components.Schemas["VerifyEmailRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"token": {
+					Type: "string",
+				},
+			},
+			Required: []string{"token"},
+		}
+
+		definition.Components.Schemas["VerifyEmailResponse"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"email": {
+					Type: "string",
+				},
+				"email_verified": {
+					Type: "boolean",
+				},
+			},
+		}
+
+		definition.Components.Schemas["ResendVerificationRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"email": {
+					Type: "string",
+				},
+			},
+			Required: []string{"email"},
+		}
+
+		definition.Components.Schemas["MfaEnrolRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"mfa_token": {
+					Type: "string",
+				},
+			},
+		}
+
+		definition.Components.Schemas["MfaEnrolResponse"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"secret": {
+					Type: "string",
+				},
+				"otpauth_uri": {
+					Type: "string",
+				},
+				"recovery_codes": {
+					Type: "array",
+					Items: &jsonschema.JSONSchema{
+						Type: "string",
+					},
+				},
+			},
+		}
+
+		definition.Components.Schemas["MfaConfirmRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"code": {
+					Type: "string",
+				},
+				"mfa_token": {
+					Type: "string",
+				},
+			},
+			Required: []string{"code"},
+		}
+
+		definition.Components.Schemas["MfaDisableRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"code": {
+					Type: "string",
+				},
+			},
+			Required: []string{"code"},
+		}
+
+		definition.Components.Schemas["SessionsResponse"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"sessions": {
+					Type: "array",
+					Items: &jsonschema.JSONSchema{
+						Type: "object",
+						Properties: map[string]jsonschema.JSONSchema{
+							"id": {
+								Type: "string",
+							},
+							"user_agent": {
+								Type: "string",
+							},
+							"ip_address": {
+								Type: "string",
+							},
+							"created_at": {
+								Type:   "string",
+								Format: "date-time",
+							},
+							"last_used_at": {
+								Type:   "string",
+								Format: "date-time",
+							},
+							"current": {
+								Type: "boolean",
+							},
+						},
+					},
+				},
+			},
+		}
+
+		definition.Components.Schemas["RevokeSessionsRequest"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"session_id": {
+					Type: "string",
+				},
+				"all": {
+					Type: "boolean",
+				},
+				"identity_id": {
+					Type: "string",
+				},
+			},
+		}
+
+		definition.Components.Schemas["RevokeSessionsResponse"] = jsonschema.JSONSchema{
+			Type: "object",
+			Properties: map[string]jsonschema.JSONSchema{
+				"revoked": {
+					Type: "integer",
+				},
+			},
+		}
+
 		definition.Components.Schemas["RevokeRequest"] = jsonschema.JSONSchema{
 			Type: "object",
 			Properties: map[string]jsonschema.JSONSchema{
@@ -312,6 +898,12 @@ func OAuthOpenApiSchema() common.HandlerFunc {
 				"error_description": {
 					Type: "string",
 				},
+				"mfa_token": {
+					Type: "string",
+				},
+				"mfa_enrolled": {
+					Type: "boolean",
+				},
 			},
 		}
 
@@ -0,0 +1,163 @@
+package authapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	email "net/mail"
+	"net/url"
+
+	"github.com/teamkeel/keel/mail"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	ArgEmail       = "email"
+	ArgMethod      = "method"
+	ArgRedirectUrl = "redirect_url"
+)
+
+const (
+	PasswordlessMethodCode = "code"
+	PasswordlessMethodLink = "link"
+)
+
+// PasswordlessHandler handles requests for a magic link or a one-time code to be emailed to an identity,
+// which can then be exchanged for tokens at the token endpoint with the passwordless grant. A request is
+// always accepted regardless of whether an identity exists for the email, so as not to reveal which do.
+func PasswordlessHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Passwordless Endpoint")
+		defer span.End()
+
+		cfg, err := runtimectx.GetOAuthConfig(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if r.Method != http.MethodPost {
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the passwordless endpoint only accepts POST", nil)
+		}
+
+		if !common.HasContentType(r.Header, "application/x-www-form-urlencoded") && !common.HasContentType(r.Header, "application/json") {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the request body must either be an encoded form (Content-Type: application/x-www-form-urlencoded) or JSON (Content-Type: application/json)", nil)
+		}
+
+		data, err := common.ParseRequestData(r)
+		if err != nil {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+		}
+
+		inputs, ok := data.(map[string]any)
+		if !ok {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+		}
+
+		emailAddress, hasEmail := inputs[ArgEmail].(string)
+		if !hasEmail || emailAddress == "" {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the identity's email in the 'email' field is required", nil)
+		}
+
+		if _, err := email.ParseAddress(emailAddress); err != nil {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "invalid email address", nil)
+		}
+
+		emailAddress = oauth.NormaliseEmail(emailAddress)
+
+		method, hasMethod := inputs[ArgMethod].(string)
+		if !hasMethod || method == "" {
+			method = PasswordlessMethodCode
+		}
+
+		span.SetAttributes(attribute.String(ArgMethod, method))
+
+		expiryMinutes := int(math.Ceil(cfg.PasswordlessCodeExpiry().Minutes()))
+
+		var req *mail.SendEmailRequest
+		switch method {
+		case PasswordlessMethodCode:
+			code, err := oauth.NewOneTimeCode(ctx, emailAddress)
+			if errors.Is(err, oauth.ErrPasswordlessCodeTooSoon) {
+				return tooSoonResponse(ctx)
+			}
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			req = &mail.SendEmailRequest{
+				To:        emailAddress,
+				From:      "hi@keel.xyz",
+				Subject:   "[Keel] Your sign in code",
+				PlainText: fmt.Sprintf("Your sign in code is %s. It expires in %d minutes.", code, expiryMinutes),
+			}
+
+		case PasswordlessMethodLink:
+			if cfg.RedirectUrl == nil {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "redirectUrl must be specified in keelconfig.yaml to send magic links", nil)
+			}
+
+			redirectUrl, err := url.Parse(*cfg.RedirectUrl)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			// A magic link can be sent to another page of the app, but never to another origin where the code could be intercepted
+			if requested, hasRequested := inputs[ArgRedirectUrl].(string); hasRequested && requested != "" {
+				requestedUrl, err := url.ParseRequestURI(requested)
+				if err != nil || requestedUrl.Scheme != redirectUrl.Scheme || requestedUrl.Host != redirectUrl.Host {
+					return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the redirect_url must have the same origin as the redirectUrl in keelconfig.yaml", nil)
+				}
+				redirectUrl = requestedUrl
+			}
+
+			code, err := oauth.NewMagicLinkCode(ctx, emailAddress)
+			if errors.Is(err, oauth.ErrPasswordlessCodeTooSoon) {
+				return tooSoonResponse(ctx)
+			}
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			q := redirectUrl.Query()
+			q.Set(ArgEmail, emailAddress)
+			q.Set(ArgCode, code)
+			redirectUrl.RawQuery = q.Encode()
+
+			req = &mail.SendEmailRequest{
+				To:        emailAddress,
+				From:      "hi@keel.xyz",
+				Subject:   "[Keel] Your sign in link",
+				PlainText: fmt.Sprintf("Please follow this link to sign in, which expires in %d minutes: %s", expiryMinutes, redirectUrl),
+			}
+
+		default:
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the method field must be either 'code' or 'link'", nil)
+		}
+
+		client, err := runtimectx.GetMailClient(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		err = client.Send(ctx, req)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, nil, nil)
+	}
+}
+
+// tooSoonResponse responds with 429 Too Many Requests when a code has been issued to the email address too recently,
+// with the Retry-After header set to the interval between codes.
+func tooSoonResponse(ctx context.Context) common.Response {
+	response := jsonErrResponse(ctx, http.StatusTooManyRequests, TokenErrTooManyAttempts, "a code has been sent to this email address recently, please wait before requesting another", nil)
+	response.Headers = common.RetryAfterHeaders(common.NewRateLimitError(oauth.PasswordlessCodeInterval))
+	return response
+}
@@ -0,0 +1,576 @@
+package authapi_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/mail"
+	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+// mailbox is an email client which keeps the emails sent rather than sending them.
+type mailbox struct {
+	sent []*mail.SendEmailRequest
+}
+
+func (m *mailbox) Send(_ context.Context, req *mail.SendEmailRequest) error {
+	m.sent = append(m.sent, req)
+	return nil
+}
+
+func (m *mailbox) last() *mail.SendEmailRequest {
+	return m.sent[len(m.sent)-1]
+}
+
+var oneTimeCodeRegex = regexp.MustCompile(`code is (\d{6})`)
+var magicLinkRegex = regexp.MustCompile(`(https?://\S+)`)
+
+func TestPasswordless_OneTimeCode_NewIdentity(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, httpResponse, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	require.Len(t, inbox.sent, 1)
+	require.Equal(t, "keelson@keel.so", inbox.last().To)
+	require.Equal(t, "[Keel] Your sign in code", inbox.last().Subject)
+
+	matches := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)
+	require.Len(t, matches, 2)
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", matches[1], nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, response.AccessToken)
+	require.NotEmpty(t, response.RefreshToken)
+	require.True(t, response.Created)
+
+	sub, err := oauth.ValidateAccessToken(ctx, response.AccessToken)
+	require.NoError(t, err)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Len(t, identities, 1)
+	require.Equal(t, sub, identities[0]["id"])
+	require.Equal(t, "keelson@keel.so", identities[0]["email"])
+	require.Equal(t, oauth.KeelIssuer, identities[0]["issuer"])
+	require.Equal(t, true, identities[0]["email_verified"])
+	require.Nil(t, identities[0]["password"])
+}
+
+func TestPasswordless_OneTimeCode_ExistingIdentity(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	// Create an identity with the password grant
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.True(t, response.Created)
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	code := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	response, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.False(t, response.Created)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Len(t, identities, 1)
+}
+
+func TestPasswordless_PasswordGrantWithoutPassword(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, _, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+
+	code := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	// An identity created by a passwordless login cannot authenticate with a password
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+}
+
+func TestPasswordless_MagicLink(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	redirectUrl := "https://myapp.com/signedin"
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RedirectUrl: &redirectUrl,
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, httpResponse, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "link", "https://myapp.com/magic"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	require.Len(t, inbox.sent, 1)
+	require.Equal(t, "[Keel] Your sign in link", inbox.last().Subject)
+
+	matches := magicLinkRegex.FindStringSubmatch(inbox.last().PlainText)
+	require.Len(t, matches, 2)
+
+	link, err := url.Parse(matches[1])
+	require.NoError(t, err)
+	require.Equal(t, "myapp.com", link.Host)
+	require.Equal(t, "/magic", link.Path)
+	require.Equal(t, "keelson@keel.so", link.Query().Get("email"))
+	require.Len(t, link.Query().Get("code"), 32)
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessGrantRequest(ctx, link.Query().Get("email"), link.Query().Get("code"), nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, response.AccessToken)
+	require.True(t, response.Created)
+}
+
+func TestPasswordless_MagicLink_DefaultRedirectUrl(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	redirectUrl := "https://myapp.com/signedin"
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RedirectUrl: &redirectUrl,
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, httpResponse, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "link", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	link, err := url.Parse(magicLinkRegex.FindStringSubmatch(inbox.last().PlainText)[1])
+	require.NoError(t, err)
+	require.Equal(t, "/signedin", link.Path)
+}
+
+func TestPasswordless_MagicLink_OtherOrigin(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	redirectUrl := "https://myapp.com/signedin"
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RedirectUrl: &redirectUrl,
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "link", "https://evil.com/magic"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "invalid_request", errorResponse.Error)
+	require.Equal(t, "the redirect_url must have the same origin as the redirectUrl in keelconfig.yaml", errorResponse.ErrorDescription)
+	require.Empty(t, inbox.sent)
+}
+
+func TestPasswordless_MagicLink_NoRedirectUrl(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "link", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "redirectUrl must be specified in keelconfig.yaml to send magic links", errorResponse.ErrorDescription)
+	require.Empty(t, inbox.sent)
+}
+
+func TestPasswordless_InvalidRequest(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	ctx = runtimectx.WithMailClient(ctx, &mailbox{})
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "", "code", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "the identity's email in the 'email' field is required", errorResponse.ErrorDescription)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "keelson", "code", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "invalid email address", errorResponse.ErrorDescription)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "sms", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "the method field must be either 'code' or 'link'", errorResponse.ErrorDescription)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", "", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "the magic link or one-time code in the 'code' field is required", errorResponse.ErrorDescription)
+}
+
+func TestPasswordless_SingleUse(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, _, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	code := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+	require.Equal(t, "possible causes may be that the code is incorrect, has been consumed, has expired or has been attempted too many times", errorResponse.ErrorDescription)
+}
+
+func TestPasswordless_NewCodeReplacesPrevious(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, _, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	first := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_passwordless_code SET created_at = now() - INTERVAL '1 minute'")
+	require.NoError(t, err)
+
+	_, _, err = handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	second := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	if first != second {
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", first, nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", second, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestPasswordless_Expired(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, _, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	code := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_passwordless_code SET expires_at = now() - INTERVAL '1 second'")
+	require.NoError(t, err)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	var codes []map[string]any
+	database.GetDB().Raw("SELECT * FROM keel_passwordless_code").Scan(&codes)
+	require.Empty(t, codes)
+}
+
+func TestPasswordless_MaxAttempts(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttempts := 3
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Passwordless: config.PasswordlessConfig{
+			MaxAttempts: &maxAttempts,
+		},
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, _, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	code := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	wrong, err := strconv.Atoi(code)
+	require.NoError(t, err)
+	wrongCode := fmt.Sprintf("%06d", (wrong+1)%1_000_000)
+
+	for range maxAttempts {
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", wrongCode, nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	// The correct code can no longer be used
+	_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Empty(t, identities)
+}
+
+func TestPasswordless_NewCodeTooSoon(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, httpResponse, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	code := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessRequest(ctx, "Keelson@Keel.so", "code", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, "too_many_attempts", errorResponse.Error)
+	require.Equal(t, "60", httpResponse.Header.Get("Retry-After"))
+	require.Len(t, inbox.sent, 1)
+
+	// The code which was sent can still be used
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestPasswordless_NewCodeKeepsAttempts(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttempts := 3
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Passwordless: config.PasswordlessConfig{
+			MaxAttempts: &maxAttempts,
+		},
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, _, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+
+	for range maxAttempts {
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", "not-the-code", nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_passwordless_code SET created_at = now() - INTERVAL '1 minute'")
+	require.NoError(t, err)
+
+	_, httpResponse, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	code := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	// The attempts at the previous code count until it would have expired
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_passwordless_code SET created_at = now() - INTERVAL '1 minute', expires_at = now() - INTERVAL '1 second'")
+	require.NoError(t, err)
+
+	_, _, err = handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	code = oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestPasswordless_EmailCase(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, _, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "Keelson@Keel.so", "code", ""))
+	require.NoError(t, err)
+	require.Equal(t, "keelson@keel.so", inbox.last().To)
+	code := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessGrantRequest(ctx, "KEELSON@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Len(t, identities, 1)
+	require.Equal(t, "keelson@keel.so", identities[0]["email"])
+}
+
+func TestPasswordless_ExistingMixedCaseIdentity(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	identityId, err := oauth.ValidateAccessToken(ctx, response.AccessToken)
+	require.NoError(t, err)
+
+	// Identities created before emails were lower-cased may have a mixed-case email
+	err = database.GetDB().Exec("UPDATE identity SET email = ?", "Keelson@Keel.so").Error
+	require.NoError(t, err)
+
+	_, _, err = handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	code := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	response, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.False(t, response.Created)
+
+	sub, err := oauth.ValidateAccessToken(ctx, response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, identityId, sub)
+
+	response, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "KEELSON@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.False(t, response.Created)
+
+	sub, err = oauth.ValidateAccessToken(ctx, response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, identityId, sub)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Len(t, identities, 1)
+}
+
+func TestPasswordGrant_EmailCase(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "Keelson@Keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.True(t, response.Created)
+
+	response, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.False(t, response.Created)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Len(t, identities, 1)
+	require.Equal(t, "keelson@keel.so", identities[0]["email"])
+}
+
+func TestPasswordless_CreateIfNotExistsFalse(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, _, err := handleRuntimeRequest[map[string]any](schema, makePasswordlessRequest(ctx, "keelson@keel.so", "code", ""))
+	require.NoError(t, err)
+	code := oneTimeCodeRegex.FindStringSubmatch(inbox.last().PlainText)[1]
+
+	createIfNotExists := false
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordlessGrantRequest(ctx, "keelson@keel.so", code, &createIfNotExists))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "the identity does not exist", errorResponse.ErrorDescription)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Empty(t, identities)
+}
+
+func makePasswordlessRequest(ctx context.Context, email string, method string, redirectUrl string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so/auth/passwordless", nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("email", email)
+	if method != "" {
+		form.Add("method", method)
+	}
+	if redirectUrl != "" {
+		form.Add("redirect_url", redirectUrl)
+	}
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
+
+func makePasswordlessGrantRequest(ctx context.Context, email string, code string, createIfNotExists *bool) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so/auth/token", nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("grant_type", "passwordless")
+	form.Add("username", email)
+	form.Add("code", code)
+
+	if createIfNotExists != nil {
+		form.Add("create_if_not_exists", strconv.FormatBool(*createIfNotExists))
+	}
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
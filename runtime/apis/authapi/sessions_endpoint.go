@@ -0,0 +1,221 @@
+package authapi
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+)
+
+const (
+	ArgSessionId  = "session_id"
+	ArgIdentityId = "identity_id"
+	ArgAll        = "all"
+)
+
+const (
+	// The scope a service account must be granted to revoke the sessions of any identity.
+	ScopeRevokeSessions = "sessions:revoke"
+)
+
+type SessionResponse struct {
+	Id         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IpAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+type SessionsResponse struct {
+	Sessions []*SessionResponse `json:"sessions"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// SessionsHandler lists the sessions in which the identity is signed in, marking the session in which its access token was issued.
+func SessionsHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Sessions Endpoint")
+		defer span.End()
+
+		if r.Method != http.MethodGet {
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the sessions endpoint only accepts GET", nil)
+		}
+
+		token := sessionsBearerToken(r)
+		if token == "" || oauth.IsServiceAccountToken(token) {
+			return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity must be authenticated", nil)
+		}
+
+		identityId, sessionId, err := oauth.ValidateSessionAccessToken(ctx, token)
+		if err != nil {
+			return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the access token is invalid, has expired or its session has been revoked", err)
+		}
+
+		sessions, err := oauth.ListSessions(ctx, identityId)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		response := &SessionsResponse{
+			Sessions: []*SessionResponse{},
+		}
+
+		for _, s := range sessions {
+			response.Sessions = append(response.Sessions, &SessionResponse{
+				Id:         s.Id,
+				UserAgent:  s.UserAgent,
+				IpAddress:  s.IpAddress,
+				CreatedAt:  s.CreatedAt,
+				LastUsedAt: s.LastUsedAt,
+				Current:    s.Id == sessionId,
+			})
+		}
+
+		return common.NewJsonResponse(http.StatusOK, response, nil)
+	}
+}
+
+// RevokeSessionsHandler revokes one or all of the identity's sessions, signing it out on those devices. The refresh tokens
+// of a revoked session can no longer be used and its access tokens are rejected. A service account which has been granted
+// the sessions:revoke scope can revoke the sessions of any identity.
+func RevokeSessionsHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Revoke Sessions Endpoint")
+		defer span.End()
+
+		if r.Method != http.MethodPost {
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the revoke sessions endpoint only accepts POST", nil)
+		}
+
+		if !common.HasContentType(r.Header, "application/x-www-form-urlencoded") && !common.HasContentType(r.Header, "application/json") {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the request body must either be an encoded form (Content-Type: application/x-www-form-urlencoded) or JSON (Content-Type: application/json)", nil)
+		}
+
+		data, err := common.ParseRequestData(r)
+		if err != nil {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+		}
+
+		inputs, ok := data.(map[string]any)
+		if !ok {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+		}
+
+		all := false
+		if argAll, hasAll := inputs[ArgAll]; hasAll {
+			if b, ok := argAll.(bool); ok {
+				all = b
+			} else if s, ok := argAll.(string); ok {
+				if all, err = strconv.ParseBool(s); err != nil {
+					return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the all field is invalid and must be either 'true' or 'false'", nil)
+				}
+			}
+		}
+
+		sessionId, _ := inputs[ArgSessionId].(string)
+		if sessionId == "" && !all {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "either the session to revoke in the 'session_id' field or 'all' is required", nil)
+		}
+
+		identityId, errResponse := sessionsIdentity(ctx, r, inputs)
+		if errResponse != nil {
+			return *errResponse
+		}
+
+		var revoked int64
+		if sessionId != "" {
+			isRevoked, err := oauth.RevokeSession(ctx, identityId, sessionId)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if !isRevoked {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the session does not exist or has already been revoked", nil)
+			}
+
+			revoked = 1
+		} else {
+			revoked, err = oauth.RevokeAllSessions(ctx, identityId)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+		}
+
+		return common.NewJsonResponse(http.StatusOK, &RevokeSessionsResponse{Revoked: revoked}, nil)
+	}
+}
+
+// sessionsIdentity returns the identity whose sessions are to be revoked, which is the identity authenticated with the
+// access token or, for a service account with the sessions:revoke scope, the identity in the identity_id field.
+func sessionsIdentity(ctx context.Context, r *http.Request, inputs map[string]any) (string, *common.Response) {
+	token := sessionsBearerToken(r)
+	if token == "" {
+		resp := jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity or service account must be authenticated", nil)
+		return "", &resp
+	}
+
+	if !oauth.IsServiceAccountToken(token) {
+		identityId, _, err := oauth.ValidateSessionAccessToken(ctx, token)
+		if err != nil {
+			resp := jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the access token is invalid, has expired or its session has been revoked", err)
+			return "", &resp
+		}
+
+		return identityId, nil
+	}
+
+	account, err := actions.HandleServiceAccountToken(ctx, token)
+	if err != nil {
+		resp := jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the access token is invalid, has expired or the service account has been revoked", err)
+		return "", &resp
+	}
+
+	if !slices.Contains(account.Scopes, ScopeRevokeSessions) {
+		resp := jsonErrResponse(ctx, http.StatusForbidden, TokenErrInvalidScope, "the service account must be granted the 'sessions:revoke' scope", nil)
+		return "", &resp
+	}
+
+	identityId, _ := inputs[ArgIdentityId].(string)
+	if identityId == "" {
+		resp := jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the identity whose sessions to revoke in the 'identity_id' field is required", nil)
+		return "", &resp
+	}
+
+	return identityId, nil
+}
+
+func sessionsBearerToken(r *http.Request) string {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+// newSession starts a session for the identity on the device which made the request and issues its first refresh token.
+func newSession(ctx context.Context, r *http.Request, identityId string) (refreshToken string, sessionId string, err error) {
+	sessionId, err = oauth.NewSession(ctx, identityId, r.UserAgent(), runtimectx.GetClientIP(ctx))
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err = oauth.NewRefreshToken(ctx, identityId, sessionId)
+	if err != nil {
+		return "", "", err
+	}
+
+	return refreshToken, sessionId, nil
+}
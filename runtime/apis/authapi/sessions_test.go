@@ -0,0 +1,207 @@
+package authapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/oauth"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	laptopRequest := makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil)
+	laptopRequest.Header.Set("User-Agent", "Laptop")
+	laptop, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, laptopRequest)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	phoneRequest := makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil)
+	phoneRequest.Header.Set("User-Agent", "Phone")
+	phoneRequest.Header.Set("X-Forwarded-For", "203.0.113.1")
+	phone, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, phoneRequest)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	sessions, httpResponse, err := handleRuntimeRequest[authapi.SessionsResponse](schema, makeSessionsRequest(ctx, laptop.AccessToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, sessions.Sessions, 2)
+
+	var laptopSession, phoneSession *authapi.SessionResponse
+	for _, s := range sessions.Sessions {
+		switch s.UserAgent {
+		case "Laptop":
+			laptopSession = s
+		case "Phone":
+			phoneSession = s
+		}
+	}
+	require.NotNil(t, laptopSession)
+	require.NotNil(t, phoneSession)
+	require.True(t, laptopSession.Current)
+	require.False(t, phoneSession.Current)
+	require.Equal(t, "203.0.113.1", phoneSession.IpAddress)
+
+	// Revoke the phone's session from the laptop
+	revoked, httpResponse, err := handleRuntimeRequest[authapi.RevokeSessionsResponse](schema, makeMfaRequest(ctx, "/auth/sessions/revoke", laptop.AccessToken, url.Values{"session_id": {phoneSession.Id}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Equal(t, int64(1), revoked.Revoked)
+
+	// The phone's access token and refresh token are no longer valid
+	_, err = oauth.ValidateAccessToken(ctx, phone.AccessToken)
+	require.ErrorIs(t, err, oauth.ErrSessionRevoked)
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeSessionsRequest(ctx, phone.AccessToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeRefreshTokenFormRequest(ctx, phone.RefreshToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	// The laptop is still signed in, and a refreshed access token stays in the same session
+	refreshed, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makeRefreshTokenFormRequest(ctx, laptop.RefreshToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	sessions, httpResponse, err = handleRuntimeRequest[authapi.SessionsResponse](schema, makeSessionsRequest(ctx, refreshed.AccessToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, sessions.Sessions, 1)
+	require.Equal(t, laptopSession.Id, sessions.Sessions[0].Id)
+	require.True(t, sessions.Sessions[0].Current)
+
+	// A session cannot be revoked twice
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/sessions/revoke", laptop.AccessToken, url.Values{"session_id": {phoneSession.Id}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "the session does not exist or has already been revoked", errorResponse.ErrorDescription)
+}
+
+func TestSessions_RevokeAll(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	first, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	second, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	revoked, httpResponse, err := handleRuntimeRequest[authapi.RevokeSessionsResponse](schema, makeMfaRequest(ctx, "/auth/sessions/revoke", first.AccessToken, url.Values{"all": {"true"}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Equal(t, int64(2), revoked.Revoked)
+
+	for _, tokens := range []authapi.TokenResponse{first, second} {
+		_, err = oauth.ValidateAccessToken(ctx, tokens.AccessToken)
+		require.ErrorIs(t, err, oauth.ErrSessionRevoked)
+
+		_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeRefreshTokenFormRequest(ctx, tokens.RefreshToken))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+}
+
+func TestSessions_RevokeRequiresSessionOrAll(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	tokens, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/sessions/revoke", tokens.AccessToken, url.Values{}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "invalid_request", errorResponse.Error)
+	require.Equal(t, "either the session to revoke in the 'session_id' field or 'all' is required", errorResponse.ErrorDescription)
+}
+
+func TestSessions_Unauthenticated(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeSessionsRequest(ctx, ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/sessions/revoke", "", url.Values{"all": {"true"}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+}
+
+func TestSessions_ServiceAccountRevokesIdentitySessions(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	tokens, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	identityId, err := oauth.ValidateAccessToken(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+
+	account, secret, err := oauth.CreateServiceAccount(ctx, "support", []string{"sessions:revoke"})
+	require.NoError(t, err)
+	support, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makeClientCredentialsFormRequest(ctx, account.ClientId, secret, ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	account, secret, err = oauth.CreateServiceAccount(ctx, "billing", []string{"invoices:read"})
+	require.NoError(t, err)
+	billing, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makeClientCredentialsFormRequest(ctx, account.ClientId, secret, ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	// A service account without the scope cannot revoke sessions
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/sessions/revoke", billing.AccessToken, url.Values{"identity_id": {identityId}, "all": {"true"}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+	require.Equal(t, "invalid_scope", errorResponse.Error)
+
+	// The identity must be given
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeMfaRequest(ctx, "/auth/sessions/revoke", support.AccessToken, url.Values{"all": {"true"}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "invalid_request", errorResponse.Error)
+
+	revoked, httpResponse, err := handleRuntimeRequest[authapi.RevokeSessionsResponse](schema, makeMfaRequest(ctx, "/auth/sessions/revoke", support.AccessToken, url.Values{"identity_id": {identityId}, "all": {"true"}}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Equal(t, int64(1), revoked.Revoked)
+
+	_, err = oauth.ValidateAccessToken(ctx, tokens.AccessToken)
+	require.ErrorIs(t, err, oauth.ErrSessionRevoked)
+
+	// Service accounts have no sessions to list
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeSessionsRequest(ctx, support.AccessToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+}
+
+func makeSessionsRequest(ctx context.Context, accessToken string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "http://mykeelapp.keel.so/auth/sessions", nil)
+
+	if accessToken != "" {
+		request.Header.Add("Authorization", "Bearer "+accessToken)
+	}
+
+	request = request.WithContext(ctx)
+
+	return request
+}
@@ -1,11 +1,15 @@
 package authapi
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	email "net/mail"
 
+	"github.com/samber/lo"
 	"github.com/teamkeel/keel/config"
 	"github.com/teamkeel/keel/functions"
 	"github.com/teamkeel/keel/proto"
@@ -35,6 +39,9 @@ const (
 	ArgUsername           = "username"
 	ArgPassword           = "password"
 	ArgCreateIfNotExists  = "create_if_not_exists"
+	ArgClientId           = "client_id"
+	ArgClientSecret       = "client_secret"
+	ArgScope              = "scope"
 )
 
 const (
@@ -48,6 +55,7 @@ type TokenResponse struct {
 	ExpiresIn    int    `json:"expires_in"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 	Created      bool   `json:"identity_created"`
+	Scope        string `json:"scope,omitempty"`
 }
 
 // https://datatracker.ietf.org/doc/html/rfc6749#section-5.2
@@ -56,6 +64,13 @@ const (
 	TokenErrUnsupportedGrantType = "unsupported_grant_type"
 	TokenErrInvalidClient        = "invalid_client"
 	TokenErrInvalidRequest       = "invalid_request"
+	TokenErrInvalidScope         = "invalid_scope"
+)
+
+const (
+	TokenErrEmailNotVerified = "email_not_verified"
+	TokenErrMfaRequired      = "mfa_required"
+	TokenErrTooManyAttempts  = "too_many_attempts"
 )
 
 const (
@@ -65,6 +80,8 @@ const (
 	GrantTypeAuthCode          = "authorization_code"
 	GrantTypeRefreshToken      = "refresh_token"
 	GrantTypeTokenExchange     = "token_exchange"
+	GrantTypePasswordless      = "passwordless"
+	GrantTypeMfa               = "mfa"
 )
 
 // TokenEndpointHandler handles requests to the token endpoint for the various grant types we support.
@@ -78,6 +95,7 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 		var err error
 		var identity auth.Identity
 		var refreshToken string
+		var sessionId string
 		createIfNotExists := true
 		identityCreated := false
 
@@ -106,7 +124,7 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 
 		grantType, hasGrantType := inputs[ArgGrantType].(string)
 		if !hasGrantType || grantType == "" {
-			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code' or 'password'", nil)
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code', 'password', 'passwordless', 'mfa' or 'client_credentials'", nil)
 		}
 
 		span.SetAttributes(
@@ -123,8 +141,16 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 			}
 		}
 
+		// Service accounts are not identities, so they are issued a token without the identity hooks being called
+		if grantType == GrantTypeClientCredentials {
+			return clientCredentialsGrant(ctx, r, inputs)
+		}
+
+		// Authentication is not complete while the identity must still provide a second factor
+		mfaRequired := false
+
 		defer func(grant string) {
-			if grant != GrantTypeRefreshToken {
+			if grant != GrantTypeRefreshToken && !mfaRequired {
 				err = functions.CallPredefinedHook(ctx, config.HookAfterAuthentication)
 				if err != nil {
 					resp = common.InternalServerErrorResponse(ctx, err)
@@ -143,7 +169,7 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 			var isValid bool
 			if cfg.RefreshTokenRotationEnabled() {
 				// Rotate and revoke this refresh token, and mint a new one.
-				isValid, refreshToken, identityId, err = oauth.RotateRefreshToken(ctx, refreshTokenRaw)
+				isValid, refreshToken, identityId, sessionId, err = oauth.RotateRefreshToken(ctx, refreshTokenRaw)
 				if err != nil {
 					return common.InternalServerErrorResponse(ctx, err)
 				}
@@ -152,7 +178,7 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 				refreshToken = refreshTokenRaw
 
 				// Check that the refresh token exists and has not expired.
-				isValid, identityId, err = oauth.ValidateRefreshToken(ctx, refreshToken)
+				isValid, identityId, sessionId, err = oauth.ValidateRefreshToken(ctx, refreshToken)
 				if err != nil {
 					return common.InternalServerErrorResponse(ctx, err)
 				}
@@ -177,11 +203,25 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "invalid email address", nil)
 			}
 
+			username = oauth.NormaliseEmail(username)
+
 			password, hasPassword := inputs[ArgPassword].(string)
 			if !hasPassword || password == "" {
 				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the identity's password in the 'password' field is required", nil)
 			}
 
+			// The attempt is counted before the password is checked, so that concurrent requests cannot make more
+			// attempts than are allowed before the lockout
+			clientIp := runtimectx.GetClientIP(ctx)
+			allowed, lockedUntil, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, username, clientIp)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if !allowed {
+				return lockedOutResponse(ctx, *lockedUntil)
+			}
+
 			ident, err := actions.FindIdentityByEmail(ctx, schema, username, oauth.KeelIssuer)
 			if err != nil {
 				return common.InternalServerErrorResponse(ctx, err)
@@ -189,7 +229,7 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 
 			if ident == nil {
 				if !createIfNotExists {
-					return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity does not exist or the credentials are incorrect", nil)
+					return failedPasswordAttemptResponse(ctx, lockedUntil)
 				}
 
 				hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -203,19 +243,80 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 				}
 
 				identityCreated = true
+
+				if cfg.EmailVerificationEnabled() {
+					err = actions.SendVerificationEmail(ctx, ident)
+					if err != nil {
+						return common.InternalServerErrorResponse(ctx, err)
+					}
+				}
 			} else {
-				correct := bcrypt.CompareHashAndPassword([]byte(ident[parser.IdentityFieldNamePassword].(string)), []byte(password)) == nil
+				// Identities created by a passwordless login do not have a password
+				hash, _ := ident[parser.IdentityFieldNamePassword].(string)
+				correct := hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
 				if !correct {
-					return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity does not exist or the credentials are incorrect", nil)
+					return failedPasswordAttemptResponse(ctx, lockedUntil)
+				}
+			}
+
+			err = oauth.ResetFailedAttempts(ctx, oauth.LockoutActionPassword, username)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if cfg.EmailVerificationRequired() {
+				if verified, _ := ident[parser.IdentityFieldNameEmailVerified].(bool); !verified {
+					return jsonErrResponse(ctx, http.StatusForbidden, TokenErrEmailNotVerified, "the identity's email must be verified before tokens can be issued", nil)
 				}
 			}
 
-			// Generate a refresh token.
-			refreshToken, err = oauth.NewRefreshToken(ctx, ident[parser.FieldNameId].(string))
+			identity = ident
+
+		case GrantTypePasswordless:
+			username, hasUsername := inputs[ArgUsername].(string)
+			if !hasUsername || username == "" {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the identity's email in the 'username' field is required", nil)
+			}
+
+			if _, err := email.ParseAddress(username); err != nil {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "invalid email address", nil)
+			}
+
+			username = oauth.NormaliseEmail(username)
+
+			code, hasCode := inputs[ArgCode].(string)
+			if !hasCode || code == "" {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the magic link or one-time code in the 'code' field is required", nil)
+			}
+
+			// Consume the passwordless code
+			isValid, err := oauth.ConsumePasswordlessCode(ctx, username, code)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if !isValid {
+				return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the code is incorrect, has been consumed, has expired or has been attempted too many times", nil)
+			}
+
+			ident, err := actions.FindIdentityByEmail(ctx, schema, username, oauth.KeelIssuer)
 			if err != nil {
 				return common.InternalServerErrorResponse(ctx, err)
 			}
 
+			if ident == nil {
+				if !createIfNotExists {
+					return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity does not exist", nil)
+				}
+
+				ident, err = actions.CreateIdentityWithVerifiedEmail(ctx, schema, username, oauth.KeelIssuer)
+				if err != nil {
+					return common.InternalServerErrorResponse(ctx, err)
+				}
+
+				identityCreated = true
+			}
+
 			identity = ident
 
 		case GrantTypeAuthCode:
@@ -235,17 +336,50 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 				return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the auth code has been consumed or has expired", nil)
 			}
 
-			// Generate a refresh token.
-			refreshToken, err = oauth.NewRefreshToken(ctx, identityId)
+			identity, err = actions.FindIdentityById(ctx, schema, identityId)
 			if err != nil {
 				return common.InternalServerErrorResponse(ctx, err)
 			}
 
+			if identity == nil {
+				return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity does not exist", nil)
+			}
+
+		case GrantTypeMfa:
+			mfaToken, hasMfaToken := inputs[ArgMfaToken].(string)
+			if !hasMfaToken || mfaToken == "" {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the mfa token from the mfa_required error in the 'mfa_token' field is required", nil)
+			}
+
+			code, hasCode := inputs[ArgCode].(string)
+			if !hasCode || code == "" {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "a code from the authenticator app or a recovery code in the 'code' field is required", nil)
+			}
+
+			identityId, err := oauth.ValidateMfaToken(ctx, mfaToken)
+			if err != nil {
+				return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "possible causes may be that the mfa token is invalid or has expired", err)
+			}
+
 			identity, err = actions.FindIdentityById(ctx, schema, identityId)
 			if err != nil {
 				return common.InternalServerErrorResponse(ctx, err)
 			}
 
+			if identity == nil {
+				return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity does not exist", nil)
+			}
+
+			if errResponse := verifyMfaAttempt(ctx, identity, code); errResponse != nil {
+				return *errResponse
+			}
+
+			// Start a session with a new refresh token.
+			refreshToken, sessionId, err = newSession(ctx, r, identityId)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
 		case GrantTypeTokenExchange:
 			idTokenRaw, hasIdTokenRaw := inputs[ArgSubjectToken].(string)
 			if !hasIdTokenRaw || idTokenRaw == "" {
@@ -311,16 +445,10 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 				}
 			}
 
-			// Generate a refresh token.
-			refreshToken, err = oauth.NewRefreshToken(ctx, ident[parser.FieldNameId].(string))
-			if err != nil {
-				return common.InternalServerErrorResponse(ctx, err)
-			}
-
 			identity = ident
 
 		default:
-			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrUnsupportedGrantType, "the only supported grants are 'refresh_token', 'token_exchange', 'authorization_code' or 'password'", nil)
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrUnsupportedGrantType, "the only supported grants are 'refresh_token', 'token_exchange', 'authorization_code', 'password', 'passwordless', 'mfa' or 'client_credentials'", nil)
 		}
 
 		ctx = auth.WithIdentity(ctx, identity)
@@ -332,8 +460,28 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 			}
 		}
 
-		// Generate a new access token for this identity.
-		accessTokenRaw, expiresIn, err := oauth.GenerateAccessToken(ctx, identity["id"].(string))
+		// Signing in with a password, passwordless code, single sign-on or an ID token may require a second factor
+		switch grantType {
+		case GrantTypePassword, GrantTypePasswordless, GrantTypeAuthCode, GrantTypeTokenExchange:
+			mfaToken, enrolled, err := mfaChallenge(ctx, schema, cfg, identity)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			if mfaToken != "" {
+				mfaRequired = true
+				return mfaRequiredResponse(ctx, mfaToken, enrolled)
+			}
+
+			// Start a session with a new refresh token.
+			refreshToken, sessionId, err = newSession(ctx, r, identity[parser.FieldNameId].(string))
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+		}
+
+		// Generate a new access token for this identity, which is valid until the session is revoked.
+		accessTokenRaw, expiresIn, err := oauth.GenerateSessionAccessToken(ctx, identity["id"].(string), sessionId)
 		if err != nil {
 			return common.InternalServerErrorResponse(ctx, err)
 		}
@@ -349,3 +497,66 @@ func TokenEndpointHandler(schema *proto.Schema) common.HandlerFunc {
 		return common.NewJsonResponse(http.StatusOK, response, nil)
 	}
 }
+
+// clientCredentialsGrant issues an access token to a service account. The client credentials can be provided in
+// the request body or with HTTP Basic authentication, https://datatracker.ietf.org/doc/html/rfc6749#section-4.4
+func clientCredentialsGrant(ctx context.Context, r *http.Request, inputs map[string]any) common.Response {
+	clientId, _ := inputs[ArgClientId].(string)
+	clientSecret, _ := inputs[ArgClientSecret].(string)
+	if basicId, basicSecret, ok := r.BasicAuth(); ok {
+		clientId, clientSecret = basicId, basicSecret
+	}
+
+	if clientId == "" || clientSecret == "" {
+		return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the client credentials are required in the 'client_id' and 'client_secret' fields or the Authorization header", nil)
+	}
+
+	account, err := oauth.AuthenticateServiceAccount(ctx, clientId, clientSecret)
+	if err != nil {
+		return common.InternalServerErrorResponse(ctx, err)
+	}
+
+	if account == nil {
+		return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the service account does not exist, has been revoked or the credentials are incorrect", nil)
+	}
+
+	// A token can be requested with fewer scopes than the service account has, but not more
+	if scope, hasScope := inputs[ArgScope].(string); hasScope && scope != "" {
+		requested := strings.Fields(scope)
+		if !lo.Every(account.Scopes, requested) {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidScope, "the requested scope exceeds the scopes granted to the service account", nil)
+		}
+		account.Scopes = requested
+	}
+
+	accessTokenRaw, expiresIn, err := oauth.GenerateServiceAccountToken(ctx, account)
+	if err != nil {
+		return common.InternalServerErrorResponse(ctx, err)
+	}
+
+	response := &TokenResponse{
+		AccessToken: accessTokenRaw,
+		TokenType:   TokenType,
+		ExpiresIn:   int(expiresIn.Seconds()),
+		Scope:       strings.Join(account.Scopes, " "),
+	}
+
+	return common.NewJsonResponse(http.StatusOK, response, nil)
+}
+
+// failedPasswordAttemptResponse responds to a failed password attempt, which was counted when it was reserved,
+// as locked out if the attempt caused a lockout.
+func failedPasswordAttemptResponse(ctx context.Context, lockedUntil *time.Time) common.Response {
+	if lockedUntil != nil {
+		return lockedOutResponse(ctx, *lockedUntil)
+	}
+
+	return jsonErrResponse(ctx, http.StatusUnauthorized, TokenErrInvalidClient, "the identity does not exist or the credentials are incorrect", nil)
+}
+
+// lockedOutResponse responds with 429 Too Many Requests and the Retry-After header set to when the lockout ends.
+func lockedOutResponse(ctx context.Context, lockedUntil time.Time) common.Response {
+	response := jsonErrResponse(ctx, http.StatusTooManyRequests, TokenErrTooManyAttempts, "too many failed attempts have been made and further attempts are temporarily locked out", nil)
+	response.Headers = common.RetryAfterHeaders(common.NewRateLimitError(time.Until(lockedUntil)))
+	return response
+}
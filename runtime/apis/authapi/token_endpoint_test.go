@@ -912,7 +912,7 @@ func TestTokenEndpointJson_MissingGrantType(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
 	require.Equal(t, "invalid_request", errorResponse.Error)
-	require.Equal(t, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code' or 'password'", errorResponse.ErrorDescription)
+	require.Equal(t, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code', 'password', 'passwordless', 'mfa' or 'client_credentials'", errorResponse.ErrorDescription)
 	require.True(t, common.HasContentType(httpResponse.Header, "application/json"))
 }
 
@@ -932,7 +932,7 @@ func TestTokenEndpoint_MissingGrantType(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
 	require.Equal(t, "invalid_request", errorResponse.Error)
-	require.Equal(t, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code' or 'password'", errorResponse.ErrorDescription)
+	require.Equal(t, "the grant_type field is required with either 'refresh_token', 'token_exchange', 'authorization_code', 'password', 'passwordless', 'mfa' or 'client_credentials'", errorResponse.ErrorDescription)
 	require.True(t, common.HasContentType(httpResponse.Header, "application/json"))
 }
 
@@ -953,7 +953,7 @@ func TestTokenEndpoint_WrongGrantType(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
 	require.Equal(t, "unsupported_grant_type", errorResponse.Error)
-	require.Equal(t, "the only supported grants are 'refresh_token', 'token_exchange', 'authorization_code' or 'password'", errorResponse.ErrorDescription)
+	require.Equal(t, "the only supported grants are 'refresh_token', 'token_exchange', 'authorization_code', 'password', 'passwordless', 'mfa' or 'client_credentials'", errorResponse.ErrorDescription)
 	require.True(t, common.HasContentType(httpResponse.Header, "application/json"))
 }
 
@@ -1825,6 +1825,155 @@ func TestPasswordGrant_InvalidCreateIfNotExists(t *testing.T) {
 	require.True(t, common.HasContentType(httpResponse.Header, "application/json"))
 }
 
+func TestPasswordGrant_LockedOutAfterFailedAttempts(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	for range config.DefaultLockoutMaxAttempts - 1 {
+		errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "whoops!", nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+		require.Equal(t, "invalid_client", errorResponse.Error)
+	}
+
+	// The last failed attempt locks out the identity
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "whoops!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, "too_many_attempts", errorResponse.Error)
+	require.Equal(t, "too many failed attempts have been made and further attempts are temporarily locked out", errorResponse.ErrorDescription)
+	require.Equal(t, "300", httpResponse.Header.Get("Retry-After"))
+
+	// Even the correct credentials are rejected during the lockout
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, "too_many_attempts", errorResponse.Error)
+	require.NotEmpty(t, httpResponse.Header.Get("Retry-After"))
+
+	unlocked, err := oauth.Unlock(ctx, "user@example.com")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), unlocked)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+}
+
+func TestPasswordGrant_SuccessResetsFailedAttempts(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	for range 2 {
+		for range config.DefaultLockoutMaxAttempts - 1 {
+			_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "whoops!", nil))
+			require.NoError(t, err)
+			require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+		}
+
+		_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	}
+}
+
+func TestPasswordGrant_LockoutDisabled(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	disabled := false
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{Enabled: &disabled},
+	})
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	for range config.DefaultLockoutMaxAttempts + 1 {
+		_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "user@example.com", "whoops!", nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	}
+}
+
+func TestPasswordGrant_IpLockoutInterleavedWithSuccessfulLogins(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttemptsPerIp := 4
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{MaxAttemptsPerIp: &maxAttemptsPerIp},
+	})
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "attacker@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "victim@example.com", "guess1", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+
+	// Signing into their own account does not give back the attempt at the victim's
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "attacker@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "victim@example.com", "guess2", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "attacker@example.com", "myP@ssword1234!", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+}
+
+func TestPasswordGrant_IpLockoutUsesLastForwardedAddress(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttemptsPerIp := 2
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{MaxAttemptsPerIp: &maxAttemptsPerIp},
+	})
+
+	// The addresses set by the client are ignored, so changing them does not avoid the lockout
+	for i, email := range []string{"keelson@keel.so", "weaveton@keel.so"} {
+		request := makePasswordFormRequest(ctx, email, "whoops!", nil)
+		request.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d, 198.51.100.4", i))
+
+		_, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+		require.NoError(t, err)
+		if i < maxAttemptsPerIp-1 {
+			require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+		} else {
+			require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+		}
+	}
+
+	request := makePasswordFormRequest(ctx, "dave@keel.so", "whoops!", nil)
+	request.Header.Set("X-Forwarded-For", "192.0.2.99, 198.51.100.4")
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, "too_many_attempts", errorResponse.Error)
+
+	lockouts, err := oauth.ListLockouts(ctx)
+	require.NoError(t, err)
+	require.Len(t, lockouts, 1)
+	require.Equal(t, oauth.LockoutKindIp, lockouts[0].Kind)
+	require.Equal(t, "198.51.100.4", lockouts[0].Subject)
+}
+
 func handleRuntimeRequest[T any](schema *proto.Schema, req *http.Request) (T, *http.Response, error) {
 	var response T
 	handler := runtime.NewHttpHandler(schema)
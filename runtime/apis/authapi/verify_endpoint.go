@@ -0,0 +1,202 @@
+package authapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	email "net/mail"
+	"net/url"
+	"time"
+
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/ratelimit"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/schema/parser"
+)
+
+const (
+	VerifyErrInvalidToken = "invalid_token"
+)
+
+const (
+	// The number of verification emails which can be resent to an email address, or to an identity, in resendVerificationPeriod.
+	resendVerificationLimit  = 3
+	resendVerificationPeriod = time.Hour
+)
+
+// VerifyEmailHandler verifies the email of an identity using the token sent to it in a verification email.
+// When following the link in the email, the user is redirected to the redirectUrl in keelconfig.yaml if one is configured.
+func VerifyEmailHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Verify Email Endpoint")
+		defer span.End()
+
+		cfg, err := runtimectx.GetOAuthConfig(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		var token string
+		var redirectUrl *url.URL
+
+		switch r.Method {
+		case http.MethodGet:
+			token = r.URL.Query().Get(ArgToken)
+
+			if cfg.RedirectUrl != nil {
+				redirectUrl, err = url.Parse(*cfg.RedirectUrl)
+				if err != nil {
+					return common.InternalServerErrorResponse(ctx, err)
+				}
+			}
+		case http.MethodPost:
+			if !common.HasContentType(r.Header, "application/x-www-form-urlencoded") && !common.HasContentType(r.Header, "application/json") {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the request body must either be an encoded form (Content-Type: application/x-www-form-urlencoded) or JSON (Content-Type: application/json)", nil)
+			}
+
+			data, err := common.ParseRequestData(r)
+			if err != nil {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+			}
+
+			inputs, ok := data.(map[string]any)
+			if !ok {
+				return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+			}
+
+			token, _ = inputs[ArgToken].(string)
+		default:
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the verify endpoint only accepts GET or POST", nil)
+		}
+
+		errResponse := func(status int, errorType string, errorDescription string, err error) common.Response {
+			if redirectUrl != nil {
+				return redirectErrResponse(ctx, redirectUrl, errorType, errorDescription, err)
+			}
+			return jsonErrResponse(ctx, status, errorType, errorDescription, err)
+		}
+
+		if token == "" {
+			return errResponse(http.StatusBadRequest, TokenErrInvalidRequest, "the verification token in the 'token' field is required", nil)
+		}
+
+		identityId, emailAddress, err := oauth.ValidateEmailVerificationToken(ctx, token)
+		switch {
+		case errors.Is(err, oauth.ErrTokenExpired):
+			return errResponse(http.StatusUnauthorized, VerifyErrInvalidToken, "the verification token has expired", err)
+		case errors.Is(err, oauth.ErrInvalidToken):
+			return errResponse(http.StatusUnauthorized, VerifyErrInvalidToken, "the verification token is invalid", err)
+		case err != nil:
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		identity, err := actions.VerifyIdentityEmail(ctx, schema, identityId, emailAddress)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if identity == nil {
+			return errResponse(http.StatusUnauthorized, VerifyErrInvalidToken, "the identity does not exist or its email has changed", nil)
+		}
+
+		if redirectUrl != nil {
+			q := url.Values{}
+			q.Add("email_verified", "true")
+			redirectUrl.RawQuery = q.Encode()
+			return common.NewRedirectResponse(redirectUrl)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, map[string]any{
+			"email":          identity[parser.IdentityFieldNameEmail],
+			"email_verified": true,
+		}, nil)
+	}
+}
+
+// ResendVerificationHandler sends another verification email to an identity with an unverified email. A request
+// is always accepted regardless of whether such an identity exists for the email, so as not to reveal which do.
+func ResendVerificationHandler(schema *proto.Schema) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Resend Verification Endpoint")
+		defer span.End()
+
+		cfg, err := runtimectx.GetOAuthConfig(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if r.Method != http.MethodPost {
+			return jsonErrResponse(ctx, http.StatusMethodNotAllowed, TokenErrInvalidRequest, "the resend verification endpoint only accepts POST", nil)
+		}
+
+		if !cfg.EmailVerificationEnabled() {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "email verification is not enabled in keelconfig.yaml", nil)
+		}
+
+		if !common.HasContentType(r.Header, "application/x-www-form-urlencoded") && !common.HasContentType(r.Header, "application/json") {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the request body must either be an encoded form (Content-Type: application/x-www-form-urlencoded) or JSON (Content-Type: application/json)", nil)
+		}
+
+		data, err := common.ParseRequestData(r)
+		if err != nil {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+		}
+
+		inputs, ok := data.(map[string]any)
+		if !ok {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "request payload is malformed", err)
+		}
+
+		emailAddress, hasEmail := inputs[ArgEmail].(string)
+		if !hasEmail || emailAddress == "" {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "the identity's email in the 'email' field is required", nil)
+		}
+
+		if _, err := email.ParseAddress(emailAddress); err != nil {
+			return jsonErrResponse(ctx, http.StatusBadRequest, TokenErrInvalidRequest, "invalid email address", nil)
+		}
+
+		emailAddress = oauth.NormaliseEmail(emailAddress)
+
+		// Requests are limited for every email, whether or not it has an identity, so as not to reveal which do
+		err = ratelimit.Take(ctx, "auth/verify/resend/email:"+emailAddress, resendVerificationLimit, resendVerificationPeriod)
+		var rateLimitErr common.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			response := jsonErrResponse(ctx, http.StatusTooManyRequests, TokenErrTooManyAttempts, "too many verification emails have been requested for this email address", nil)
+			response.Headers = common.RetryAfterHeaders(rateLimitErr)
+			return response
+		}
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		identity, err := actions.FindIdentityByEmail(ctx, schema, emailAddress, oauth.KeelIssuer)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if identity != nil {
+			if verified, _ := identity[parser.IdentityFieldNameEmailVerified].(bool); !verified {
+				// The emails sent to an identity are also limited, so that changing its email does not allow more to be
+				// sent. As responding otherwise would reveal that the identity exists, the request is accepted and nothing is sent.
+				err = ratelimit.Take(ctx, fmt.Sprintf("auth/verify/resend/identity:%s", identity[parser.FieldNameId]), resendVerificationLimit, resendVerificationPeriod)
+				if errors.As(err, &rateLimitErr) {
+					return common.NewJsonResponse(http.StatusOK, nil, nil)
+				}
+				if err != nil {
+					return common.InternalServerErrorResponse(ctx, err)
+				}
+
+				err = actions.SendVerificationEmail(ctx, identity)
+				if err != nil {
+					return common.InternalServerErrorResponse(ctx, err)
+				}
+			}
+		}
+
+		return common.NewJsonResponse(http.StatusOK, nil, nil)
+	}
+}
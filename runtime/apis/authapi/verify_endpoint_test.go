@@ -0,0 +1,422 @@
+package authapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/runtime"
+	"github.com/teamkeel/keel/runtime/apis/authapi"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+var verifyLinkRegex = regexp.MustCompile(`(https?://\S+/auth/verify\?token=\S+)`)
+
+func TestVerifyEmail_SentOnIdentityCreated(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	t.Setenv("KEEL_API_URL", "http://mykeelapp.keel.so")
+
+	enabled := true
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		EmailVerification: config.EmailVerificationConfig{Enabled: &enabled},
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.True(t, response.Created)
+
+	require.Len(t, inbox.sent, 1)
+	require.Equal(t, "keelson@keel.so", inbox.last().To)
+	require.Equal(t, "[Keel] Verify your email", inbox.last().Subject)
+
+	link := verifyLinkRegex.FindString(inbox.last().PlainText)
+	require.NotEmpty(t, link)
+
+	verifyUrl, err := url.Parse(link)
+	require.NoError(t, err)
+	require.Equal(t, "mykeelapp.keel.so", verifyUrl.Host)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Len(t, identities, 1)
+	require.Equal(t, false, identities[0]["email_verified"])
+
+	verifyResponse, httpResponse, err := handleRuntimeRequest[map[string]any](schema, makeVerifyEmailRequest(ctx, verifyUrl.Query().Get("token")))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Equal(t, "keelson@keel.so", verifyResponse["email"])
+	require.Equal(t, true, verifyResponse["email_verified"])
+
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Equal(t, true, identities[0]["email_verified"])
+
+	// Signing in again does not send another verification email
+	_, httpResponse, err = handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, inbox.sent, 1)
+}
+
+func TestVerifyEmail_NotSentWhenDisabled(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Empty(t, inbox.sent)
+}
+
+func TestVerifyEmail_RedirectsFromLink(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	t.Setenv("KEEL_API_URL", "http://mykeelapp.keel.so")
+
+	enabled := true
+	redirectUrl := "https://myapp.com/verified"
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		RedirectUrl:       &redirectUrl,
+		EmailVerification: config.EmailVerificationConfig{Enabled: &enabled},
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	link := verifyLinkRegex.FindString(inbox.last().PlainText)
+	request := httptest.NewRequest(http.MethodGet, link, nil).WithContext(ctx)
+
+	w := httptest.NewRecorder()
+	runtime.NewHttpHandler(schema).ServeHTTP(w, request)
+	require.Equal(t, http.StatusFound, w.Result().StatusCode)
+
+	location, err := w.Result().Location()
+	require.NoError(t, err)
+	require.Equal(t, "myapp.com", location.Host)
+	require.Equal(t, "/verified", location.Path)
+	require.Equal(t, "true", location.Query().Get("email_verified"))
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Equal(t, true, identities[0]["email_verified"])
+
+	// An invalid token is redirected with the error
+	request = httptest.NewRequest(http.MethodGet, "http://mykeelapp.keel.so/auth/verify?token=invalid", nil).WithContext(ctx)
+
+	w = httptest.NewRecorder()
+	runtime.NewHttpHandler(schema).ServeHTTP(w, request)
+	require.Equal(t, http.StatusFound, w.Result().StatusCode)
+
+	location, err = w.Result().Location()
+	require.NoError(t, err)
+	require.Equal(t, "invalid_token", location.Query().Get("error"))
+	require.Equal(t, "the verification token is invalid", location.Query().Get("error_description"))
+}
+
+func TestVerifyEmail_InvalidTokens(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	identityId := identities[0]["id"].(string)
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeVerifyEmailRequest(ctx, ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "invalid_request", errorResponse.Error)
+
+	// A password reset token cannot be used to verify an email
+	resetToken, err := oauth.GenerateResetToken(ctx, identityId)
+	require.NoError(t, err)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeVerifyEmailRequest(ctx, resetToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_token", errorResponse.Error)
+	require.Equal(t, "the verification token is invalid", errorResponse.ErrorDescription)
+
+	// Neither can an access token
+	accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, identityId, "")
+	require.NoError(t, err)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeVerifyEmailRequest(ctx, accessToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_token", errorResponse.Error)
+
+	expiredToken := makeExpiredEmailVerificationToken(t, ctx, identityId)
+
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeVerifyEmailRequest(ctx, expiredToken))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_token", errorResponse.Error)
+	require.Equal(t, "the verification token has expired", errorResponse.ErrorDescription)
+
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Equal(t, false, identities[0]["email_verified"])
+}
+
+func TestVerifyEmail_EmailChanged(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	t.Setenv("KEEL_API_URL", "http://mykeelapp.keel.so")
+
+	enabled := true
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		EmailVerification: config.EmailVerificationConfig{Enabled: &enabled},
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	link, err := url.Parse(verifyLinkRegex.FindString(inbox.last().PlainText))
+	require.NoError(t, err)
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE identity SET email = 'weaveton@keel.so'")
+	require.NoError(t, err)
+
+	// The token was sent to the previous email, and so cannot verify the new one
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeVerifyEmailRequest(ctx, link.Query().Get("token")))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_token", errorResponse.Error)
+	require.Equal(t, "the identity does not exist or its email has changed", errorResponse.ErrorDescription)
+
+	var identities []map[string]any
+	database.GetDB().Raw("SELECT * FROM identity").Scan(&identities)
+	require.Equal(t, false, identities[0]["email_verified"])
+}
+
+func TestVerifyEmail_Required(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	t.Setenv("KEEL_API_URL", "http://mykeelapp.keel.so")
+
+	required := true
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		EmailVerification: config.EmailVerificationConfig{Required: &required},
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	// The identity is created and sent a verification email, but no tokens are issued
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, httpResponse.StatusCode)
+	require.Equal(t, "email_not_verified", errorResponse.Error)
+	require.Equal(t, "the identity's email must be verified before tokens can be issued", errorResponse.ErrorDescription)
+	require.Len(t, inbox.sent, 1)
+
+	// Incorrect credentials are still rejected as such
+	errorResponse, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "wrong", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, httpResponse.StatusCode)
+	require.Equal(t, "invalid_client", errorResponse.Error)
+
+	link, err := url.Parse(verifyLinkRegex.FindString(inbox.last().PlainText))
+	require.NoError(t, err)
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeVerifyEmailRequest(ctx, link.Query().Get("token")))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	response, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.NotEmpty(t, response.AccessToken)
+	require.False(t, response.Created)
+}
+
+func TestResendVerification(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	t.Setenv("KEEL_API_URL", "http://mykeelapp.keel.so")
+
+	enabled := true
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		EmailVerification: config.EmailVerificationConfig{Enabled: &enabled},
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, inbox.sent, 1)
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeResendVerificationRequest(ctx, "keelson@keel.so"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, inbox.sent, 2)
+	require.Equal(t, "[Keel] Verify your email", inbox.last().Subject)
+
+	// An email which has no identity is accepted, but nothing is sent
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeResendVerificationRequest(ctx, "nobody@keel.so"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, inbox.sent, 2)
+
+	link, err := url.Parse(verifyLinkRegex.FindString(inbox.last().PlainText))
+	require.NoError(t, err)
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeVerifyEmailRequest(ctx, link.Query().Get("token")))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	// Nothing is sent once the email is verified
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeResendVerificationRequest(ctx, "keelson@keel.so"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, inbox.sent, 2)
+}
+
+func TestResendVerification_RateLimited(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	t.Setenv("KEEL_API_URL", "http://mykeelapp.keel.so")
+
+	enabled := true
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		EmailVerification: config.EmailVerificationConfig{Enabled: &enabled},
+	})
+
+	inbox := &mailbox{}
+	ctx = runtimectx.WithMailClient(ctx, inbox)
+
+	_, httpResponse, err := handleRuntimeRequest[authapi.TokenResponse](schema, makePasswordFormRequest(ctx, "keelson@keel.so", "1234", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, inbox.sent, 1)
+
+	for range 3 {
+		_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeResendVerificationRequest(ctx, "keelson@keel.so"))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	}
+	require.Len(t, inbox.sent, 4)
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeResendVerificationRequest(ctx, "Keelson@keel.so"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+	require.Equal(t, "too_many_attempts", errorResponse.Error)
+	require.NotEmpty(t, httpResponse.Header.Get("Retry-After"))
+	require.Len(t, inbox.sent, 4)
+
+	// An email without an identity is limited just the same
+	for range 3 {
+		_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeResendVerificationRequest(ctx, "nobody@keel.so"))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	}
+
+	_, httpResponse, err = handleRuntimeRequest[authapi.ErrorResponse](schema, makeResendVerificationRequest(ctx, "nobody@keel.so"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, httpResponse.StatusCode)
+
+	// The identity's limit still applies once its email has changed, but without revealing it
+	_, err = database.ExecuteStatement(ctx, "UPDATE identity SET email = 'weaveton@keel.so'")
+	require.NoError(t, err)
+
+	_, httpResponse, err = handleRuntimeRequest[map[string]any](schema, makeResendVerificationRequest(ctx, "weaveton@keel.so"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	require.Len(t, inbox.sent, 4)
+}
+
+func TestResendVerification_Disabled(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	errorResponse, httpResponse, err := handleRuntimeRequest[authapi.ErrorResponse](schema, makeResendVerificationRequest(ctx, "keelson@keel.so"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+	require.Equal(t, "invalid_request", errorResponse.Error)
+	require.Equal(t, "email verification is not enabled in keelconfig.yaml", errorResponse.ErrorDescription)
+}
+
+func makeExpiredEmailVerificationToken(t *testing.T, ctx context.Context, identityId string) string {
+	privateKey, err := runtimectx.GetPrivateKey(ctx)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, oauth.AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   identityId,
+			Audience:  []string{"email-verification"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(-time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now.Add(-time.Hour)),
+			Issuer:    oauth.KeelIssuer,
+		},
+	})
+
+	tokenString, err := token.SignedString(privateKey)
+	require.NoError(t, err)
+
+	return tokenString
+}
+
+func makeVerifyEmailRequest(ctx context.Context, token string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so/auth/verify", nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("token", token)
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
+
+func makeResendVerificationRequest(ctx context.Context, email string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://mykeelapp.keel.so/auth/verify/resend", nil)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Add("email", email)
+
+	request.URL.RawQuery = form.Encode()
+	request = request.WithContext(ctx)
+
+	return request
+}
@@ -70,6 +70,9 @@ func ListFlowsHandler(p *proto.Schema) common.HandlerFunc {
 
 			if f.GetSchedule() != nil {
 				flowData["schedule"] = f.GetSchedule().GetExpression()
+				if tz := f.GetSchedule().GetTimezone(); tz != "" {
+					flowData["timezone"] = tz
+				}
 			}
 
 			flowsData = append(flowsData, flowData)
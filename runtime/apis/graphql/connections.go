@@ -0,0 +1,512 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/teamkeel/graphql"
+	"github.com/teamkeel/graphql/gqlerrors"
+	"github.com/teamkeel/graphql/language/ast"
+	"github.com/teamkeel/graphql/language/parser"
+	"github.com/teamkeel/graphql/language/source"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/events"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/auth"
+)
+
+// ConnectionSender sends messages to WebSocket connections which are held outside of the runtime,
+// such as by API Gateway in deployed environments, where each message is handled by a separate
+// invocation and so the state of each connection is stored in the database.
+type ConnectionSender interface {
+	// Send writes the message to the connection, returning ErrConnectionGone if it has been closed.
+	Send(ctx context.Context, connectionId string, data []byte) error
+	// Close disconnects the client.
+	Close(ctx context.Context, connectionId string) error
+}
+
+// ErrConnectionGone is returned by a ConnectionSender when the client has already disconnected.
+var ErrConnectionGone = errors.New("connection no longer exists")
+
+// ErrConnectionApiNotFound is returned when opening a connection to an API which does not exist.
+var ErrConnectionApiNotFound = errors.New("api not found")
+
+// connection is a WebSocket connection as stored in the keel_subscription_connection table.
+type connection struct {
+	id             string
+	api            *proto.Api
+	identityId     string
+	serviceAccount *auth.ServiceAccount
+	initialised    bool
+}
+
+// OpenConnection records a new WebSocket connection to the named API. If the upgrade request has an
+// Authorization header then it is authenticated now, as headers are not available to later messages.
+func OpenConnection(ctx context.Context, s *proto.Schema, connectionId string, apiName string, headers http.Header) error {
+	ctx, span := tracer.Start(ctx, "Open Connection")
+	defer span.End()
+
+	api, ok := findConnectionApi(s, apiName)
+	if !ok {
+		return ErrConnectionApiNotFound
+	}
+
+	ctx, err := actions.HandleAuthorization(ctx, s, headers)
+	if err != nil {
+		return err
+	}
+
+	identityId, serviceAccount, err := connectionPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	sql := `
+		INSERT INTO
+			keel_subscription_connection (id, api, identity_id, service_account, created_at)
+		VALUES
+			(?, ?, ?, ?, now())`
+
+	_, err = database.ExecuteStatement(ctx, sql, connectionId, api.GetName(), identityId, serviceAccount)
+	return err
+}
+
+// CloseConnection forgets the WebSocket connection and all of its subscriptions.
+func CloseConnection(ctx context.Context, connectionId string) error {
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(ctx, func(ctx context.Context) error {
+		_, err := database.ExecuteStatement(ctx, "DELETE FROM keel_subscription WHERE connection_id = ?", connectionId)
+		if err != nil {
+			return err
+		}
+
+		_, err = database.ExecuteStatement(ctx, "DELETE FROM keel_subscription_connection WHERE id = ?", connectionId)
+		return err
+	})
+}
+
+// HandleConnectionMessage handles a graphql-ws message received on a WebSocket connection which was
+// recorded with OpenConnection. Subscriptions are stored so that events can later be delivered to them
+// by the publisher returned from NewConnectionPublisher.
+func HandleConnectionMessage(ctx context.Context, s *proto.Schema, sender ConnectionSender, connectionId string, data []byte) error {
+	ctx, span := tracer.Start(ctx, "Connection Message")
+	defer span.End()
+
+	conn, err := findConnection(ctx, s, connectionId)
+	if err != nil {
+		return err
+	}
+
+	// The connection has either been closed or was never opened with OpenConnection.
+	if conn == nil {
+		return sender.Close(ctx, connectionId)
+	}
+
+	var msg wsMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return closeConnection(ctx, sender, connectionId)
+	}
+
+	span.SetAttributes(attribute.String("message.type", msg.Type))
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch msg.Type {
+	case messageConnectionInit:
+		if conn.initialised {
+			return closeConnection(ctx, sender, connectionId)
+		}
+
+		identityId, serviceAccount := conn.identityId, ""
+		if conn.serviceAccount != nil {
+			b, err := json.Marshal(conn.serviceAccount)
+			if err != nil {
+				return err
+			}
+			serviceAccount = string(b)
+		}
+
+		headers, err := connectionHeaders(http.Header{}, msg.Payload)
+		if err != nil {
+			return closeConnection(ctx, sender, connectionId)
+		}
+
+		if headers.Get("Authorization") != "" {
+			authCtx, err := actions.HandleAuthorization(ctx, s, headers)
+			if err != nil {
+				return closeConnection(ctx, sender, connectionId)
+			}
+
+			identityId, serviceAccount, err = connectionPrincipal(authCtx)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = database.ExecuteStatement(ctx, "UPDATE keel_subscription_connection SET identity_id = ?, service_account = ?, initialised_at = now() WHERE id = ?", identityId, serviceAccount, connectionId)
+		if err != nil {
+			return err
+		}
+
+		return sendMessage(ctx, sender, connectionId, wsMessage{Type: messageConnectionAck})
+	case messagePing:
+		return sendMessage(ctx, sender, connectionId, wsMessage{Type: messagePong})
+	case messagePong:
+		return nil
+	case messageSubscribe:
+		if !conn.initialised || msg.ID == "" {
+			return closeConnection(ctx, sender, connectionId)
+		}
+
+		var params GraphQLRequest
+		if err := json.Unmarshal(msg.Payload, &params); err != nil {
+			return closeConnection(ctx, sender, connectionId)
+		}
+
+		gqlSchema, err := connectionSchema(s, conn.api)
+		if err != nil {
+			return err
+		}
+
+		event, errs := subscriptionEvent(s, gqlSchema, params)
+		if len(errs) > 0 {
+			payload, _ := json.Marshal(errs)
+			return sendMessage(ctx, sender, connectionId, wsMessage{ID: msg.ID, Type: messageError, Payload: payload})
+		}
+
+		variables, err := json.Marshal(params.Variables)
+		if err != nil {
+			return err
+		}
+
+		sql := `
+			INSERT INTO
+				keel_subscription (connection_id, id, event_name, query, variables, operation_name, created_at)
+			VALUES
+				(?, ?, ?, ?, ?, ?, now())
+			ON CONFLICT DO NOTHING`
+
+		result, err := database.ExecuteStatement(ctx, sql, connectionId, msg.ID, event.GetName(), params.Query, string(variables), params.OperationName)
+		if err != nil {
+			return err
+		}
+
+		// There is already a subscription with this id.
+		if result.RowsAffected == 0 {
+			return closeConnection(ctx, sender, connectionId)
+		}
+
+		return nil
+	case messageComplete:
+		_, err = database.ExecuteStatement(ctx, "DELETE FROM keel_subscription WHERE connection_id = ? AND id = ?", connectionId, msg.ID)
+		return err
+	default:
+		return closeConnection(ctx, sender, connectionId)
+	}
+}
+
+// NewConnectionPublisher returns an events.EventPublisher which delivers each event to the subscriptions
+// of the WebSocket connections recorded with OpenConnection.
+func NewConnectionPublisher(s *proto.Schema, sender ConnectionSender) events.EventPublisher {
+	return func(ctx context.Context, e *events.Event) error {
+		ctx, span := tracer.Start(ctx, "Publish Event")
+		defer span.End()
+
+		span.SetAttributes(attribute.String("event.name", e.EventName))
+
+		if e.Target == nil {
+			return nil
+		}
+
+		database, err := db.GetDatabase(ctx)
+		if err != nil {
+			return err
+		}
+
+		sql := `
+			SELECT
+				s.connection_id, s.id, s.query, s.variables, s.operation_name
+			FROM
+				keel_subscription s
+			WHERE
+				s.event_name = ?`
+
+		result, err := database.ExecuteQuery(ctx, sql, e.EventName)
+		if err != nil {
+			return err
+		}
+
+		model := s.FindModel(e.Target.Type)
+		if model == nil {
+			return fmt.Errorf("model does not exist for event: %s", e.EventName)
+		}
+
+		var publishErrors error
+		for _, row := range result.Rows {
+			connectionId := row["connection_id"].(string)
+
+			err := publishToSubscription(ctx, s, sender, model, e, connectionId, row)
+			if errors.Is(err, ErrConnectionGone) {
+				err = CloseConnection(ctx, connectionId)
+			}
+			if err != nil {
+				publishErrors = errors.Join(publishErrors, err)
+			}
+		}
+
+		return publishErrors
+	}
+}
+
+// publishToSubscription delivers the event to a single subscription if the connection is authorised to get it.
+func publishToSubscription(ctx context.Context, s *proto.Schema, sender ConnectionSender, model *proto.Model, e *events.Event, connectionId string, row map[string]any) error {
+	conn, err := findConnection(ctx, s, connectionId)
+	if err != nil || conn == nil {
+		return err
+	}
+
+	if conn.identityId != "" {
+		identity, err := actions.FindIdentityById(ctx, s, conn.identityId)
+		if err != nil {
+			return err
+		}
+
+		// The identity has since been deleted.
+		if identity == nil {
+			return closeConnection(ctx, sender, connectionId)
+		}
+
+		ctx = auth.WithIdentity(ctx, identity)
+	}
+	ctx = auth.WithServiceAccount(ctx, conn.serviceAccount)
+
+	authorised, err := authoriseEvent(ctx, s, model, e)
+	if err != nil || !authorised {
+		return err
+	}
+
+	params := GraphQLRequest{
+		Query: row["query"].(string),
+	}
+	if v, ok := row["operation_name"].(string); ok {
+		params.OperationName = v
+	}
+	if v, ok := row["variables"].(string); ok {
+		if err := json.Unmarshal([]byte(v), &params.Variables); err != nil {
+			return err
+		}
+	}
+
+	gqlSchema, err := connectionSchema(s, conn.api)
+	if err != nil {
+		return err
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         *gqlSchema,
+		Context:        ctx,
+		RootObject:     eventResponse(e),
+		RequestString:  params.Query,
+		VariableValues: params.Variables,
+		OperationName:  params.OperationName,
+	})
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	return sendMessage(ctx, sender, connectionId, wsMessage{ID: row["id"].(string), Type: messageNext, Payload: payload})
+}
+
+// findConnection loads the connection, returning nil if it does not exist.
+func findConnection(ctx context.Context, s *proto.Schema, connectionId string) (*connection, error) {
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := database.ExecuteQuery(ctx, "SELECT id, api, identity_id, service_account, initialised_at FROM keel_subscription_connection WHERE id = ?", connectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Rows) == 0 {
+		return nil, nil
+	}
+
+	row := result.Rows[0]
+
+	api, ok := findConnectionApi(s, row["api"].(string))
+	if !ok {
+		return nil, nil
+	}
+
+	conn := &connection{
+		id:          connectionId,
+		api:         api,
+		initialised: row["initialised_at"] != nil,
+	}
+
+	if v, ok := row["identity_id"].(string); ok {
+		conn.identityId = v
+	}
+
+	if v, ok := row["service_account"].(string); ok && v != "" {
+		var account auth.ServiceAccount
+		if err := json.Unmarshal([]byte(v), &account); err != nil {
+			return nil, err
+		}
+		conn.serviceAccount = &account
+	}
+
+	return conn, nil
+}
+
+// connectionPrincipal returns the identity id and the serialised service account which have been authenticated in the context.
+func connectionPrincipal(ctx context.Context) (string, string, error) {
+	identityId := ""
+	if auth.IsAuthenticated(ctx) {
+		identity, err := auth.GetIdentity(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		identityId = identity["id"].(string)
+	}
+
+	serviceAccount := ""
+	if auth.IsServiceAccount(ctx) {
+		account, err := auth.GetServiceAccount(ctx)
+		if err != nil {
+			return "", "", err
+		}
+
+		b, err := json.Marshal(account)
+		if err != nil {
+			return "", "", err
+		}
+		serviceAccount = string(b)
+	}
+
+	return identityId, serviceAccount, nil
+}
+
+func findConnectionApi(s *proto.Schema, apiName string) (*proto.Api, bool) {
+	for _, api := range s.GetApis() {
+		if strings.EqualFold(api.GetName(), apiName) {
+			return api, true
+		}
+	}
+	return nil, false
+}
+
+var connectionSchemas sync.Map
+
+// connectionSchema returns the GraphQL schema for the API, which is built once per process.
+func connectionSchema(s *proto.Schema, api *proto.Api) (*graphql.Schema, error) {
+	if cached, ok := connectionSchemas.Load(api); ok {
+		return cached.(*graphql.Schema), nil
+	}
+
+	gqlSchema, err := NewGraphQLSchema(s, api)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionSchemas.Store(api, gqlSchema)
+	return gqlSchema, nil
+}
+
+// subscriptionEvent validates the subscription operation and returns the event which it subscribes to.
+func subscriptionEvent(s *proto.Schema, gqlSchema *graphql.Schema, params GraphQLRequest) (*proto.Event, []gqlerrors.FormattedError) {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(params.Query),
+			Name: "GraphQL request",
+		}),
+	})
+	if err != nil {
+		return nil, gqlerrors.FormatErrors(err)
+	}
+
+	validation := graphql.ValidateDocument(gqlSchema, document, nil)
+	if !validation.IsValid {
+		return nil, validation.Errors
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		op, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		if params.OperationName == "" || (op.Name != nil && op.Name.Value == params.OperationName) {
+			operation = op
+			break
+		}
+	}
+
+	if operation == nil || operation.Operation != ast.OperationTypeSubscription {
+		return nil, gqlerrors.FormatErrors(errors.New("operation must be a subscription"))
+	}
+
+	selections := operation.GetSelectionSet().Selections
+	if len(selections) != 1 {
+		return nil, gqlerrors.FormatErrors(errors.New("subscription must select exactly one field"))
+	}
+
+	field, ok := selections[0].(*ast.Field)
+	if !ok {
+		return nil, gqlerrors.FormatErrors(errors.New("subscription must select exactly one field"))
+	}
+
+	for _, event := range s.GetEvents() {
+		if subscriptionFieldName(event) == field.Name.Value {
+			return event, nil
+		}
+	}
+
+	return nil, gqlerrors.FormatErrors(fmt.Errorf("the subscription field %q is not defined", field.Name.Value))
+}
+
+func sendMessage(ctx context.Context, sender ConnectionSender, connectionId string, msg wsMessage) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return sender.Send(ctx, connectionId, b)
+}
+
+// closeConnection disconnects the client, which is how protocol errors are handled since API Gateway does not
+// support closing a connection with a status code.
+func closeConnection(ctx context.Context, sender ConnectionSender, connectionId string) error {
+	err := sender.Close(ctx, connectionId)
+	if err != nil && !errors.Is(err, ErrConnectionGone) {
+		logrus.WithError(err).Debug("error closing subscription connection")
+	}
+
+	return CloseConnection(ctx, connectionId)
+}
@@ -0,0 +1,162 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/events"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/apis/graphql"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+// connectionSender records the messages sent to connections held outside of the runtime.
+type connectionSender struct {
+	sent   map[string][]map[string]any
+	closed []string
+}
+
+func (s *connectionSender) Send(ctx context.Context, connectionId string, data []byte) error {
+	var msg map[string]any
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	s.sent[connectionId] = append(s.sent[connectionId], msg)
+	return nil
+}
+
+func (s *connectionSender) Close(ctx context.Context, connectionId string) error {
+	s.closed = append(s.closed, connectionId)
+	return nil
+}
+
+func sendConnectionMessage(t *testing.T, ctx context.Context, schema *proto.Schema, sender *connectionSender, connectionId string, msg map[string]any) {
+	b, err := json.Marshal(msg)
+	require.NoError(t, err)
+	require.NoError(t, graphql.HandleConnectionMessage(ctx, schema, sender, connectionId, b))
+}
+
+func TestConnectionReceivesPublishedEvents(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), subscriptionsSchema, true)
+	defer database.Close()
+
+	sender := &connectionSender{sent: map[string][]map[string]any{}}
+
+	err := graphql.OpenConnection(ctx, schema, "conn1", "test", http.Header{})
+	require.NoError(t, err)
+
+	sendConnectionMessage(t, ctx, schema, sender, "conn1", map[string]any{"type": "connection_init"})
+	require.Len(t, sender.sent["conn1"], 1)
+	require.Equal(t, "connection_ack", sender.sent["conn1"][0]["type"])
+
+	sendConnectionMessage(t, ctx, schema, sender, "conn1", map[string]any{
+		"id":   "1",
+		"type": "subscribe",
+		"payload": map[string]any{
+			"query": "subscription { orderCreated { eventName data { id reference } } }",
+		},
+	})
+	require.Len(t, sender.sent["conn1"], 1)
+
+	publish := graphql.NewConnectionPublisher(schema, sender)
+	err = publish(ctx, &events.Event{
+		EventName:  "order.created",
+		OccurredAt: time.Now(),
+		Target: &events.EventTarget{
+			Id:   "123",
+			Type: "Order",
+			Data: map[string]any{"id": "123", "reference": "ABC"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sender.sent["conn1"], 2)
+	msg := sender.sent["conn1"][1]
+	require.Equal(t, "next", msg["type"])
+	require.Equal(t, "1", msg["id"])
+	require.Equal(t, map[string]any{
+		"data": map[string]any{
+			"orderCreated": map[string]any{
+				"eventName": "order.created",
+				"data": map[string]any{
+					"id":        "123",
+					"reference": "ABC",
+				},
+			},
+		},
+	}, msg["payload"])
+
+	// Once completed, no more events are sent
+	sendConnectionMessage(t, ctx, schema, sender, "conn1", map[string]any{"id": "1", "type": "complete"})
+
+	err = publish(ctx, &events.Event{
+		EventName:  "order.created",
+		OccurredAt: time.Now(),
+		Target: &events.EventTarget{
+			Id:   "456",
+			Type: "Order",
+			Data: map[string]any{"id": "456", "reference": "DEF"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, sender.sent["conn1"], 2)
+}
+
+func TestConnectionRequiresConnectionInit(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), subscriptionsSchema, true)
+	defer database.Close()
+
+	sender := &connectionSender{sent: map[string][]map[string]any{}}
+
+	err := graphql.OpenConnection(ctx, schema, "conn1", "test", http.Header{})
+	require.NoError(t, err)
+
+	sendConnectionMessage(t, ctx, schema, sender, "conn1", map[string]any{
+		"id":   "1",
+		"type": "subscribe",
+		"payload": map[string]any{
+			"query": "subscription { orderCreated { eventName } }",
+		},
+	})
+
+	require.Equal(t, []string{"conn1"}, sender.closed)
+
+	var count int
+	database.GetDB().Raw("SELECT count(*) FROM keel_subscription_connection").Scan(&count)
+	require.Equal(t, 0, count)
+}
+
+func TestConnectionInvalidQuery(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), subscriptionsSchema, true)
+	defer database.Close()
+
+	sender := &connectionSender{sent: map[string][]map[string]any{}}
+
+	err := graphql.OpenConnection(ctx, schema, "conn1", "test", http.Header{})
+	require.NoError(t, err)
+
+	sendConnectionMessage(t, ctx, schema, sender, "conn1", map[string]any{"type": "connection_init"})
+	sendConnectionMessage(t, ctx, schema, sender, "conn1", map[string]any{
+		"id":   "1",
+		"type": "subscribe",
+		"payload": map[string]any{
+			"query": "subscription { orderDeleted { eventName } }",
+		},
+	})
+
+	require.Len(t, sender.sent["conn1"], 2)
+	require.Equal(t, "error", sender.sent["conn1"][1]["type"])
+	require.Equal(t, "1", sender.sent["conn1"][1]["id"])
+}
+
+func TestConnectionUnknownApi(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), subscriptionsSchema, true)
+	defer database.Close()
+
+	err := graphql.OpenConnection(ctx, schema, "conn1", "other", http.Header{})
+	require.ErrorIs(t, err, graphql.ErrConnectionApiNotFound)
+}
@@ -19,9 +19,11 @@ import (
 	"github.com/samber/lo"
 	"github.com/teamkeel/graphql"
 	"github.com/teamkeel/graphql/gqlerrors"
+	"github.com/teamkeel/graphql/language/ast"
+	gqlparser "github.com/teamkeel/graphql/language/parser"
+	"github.com/teamkeel/graphql/language/source"
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/actions"
-	"github.com/teamkeel/keel/runtime/auth"
 	"github.com/teamkeel/keel/runtime/common"
 	"github.com/teamkeel/keel/runtime/locale"
 	"github.com/teamkeel/keel/schema/parser"
@@ -43,7 +45,7 @@ func NewHandler(s *proto.Schema, api *proto.Api) common.HandlerFunc {
 		ctx, span := tracer.Start(r.Context(), "GraphQL")
 		defer span.End()
 
-		identity, err := actions.HandleAuthorizationHeader(ctx, s, r.Header)
+		ctx, err := actions.HandleAuthorization(ctx, s, r.Header)
 		if err != nil {
 			var extensions map[string]interface{}
 
@@ -61,9 +63,6 @@ func NewHandler(s *proto.Schema, api *proto.Api) common.HandlerFunc {
 				},
 			}, nil)
 		}
-		if identity != nil {
-			ctx = auth.WithIdentity(ctx, identity)
-		}
 
 		// handle any Time-Zone headers
 		location, err := locale.HandleTimezoneHeader(ctx, r.Header)
@@ -154,23 +153,66 @@ func NewHandler(s *proto.Schema, api *proto.Api) common.HandlerFunc {
 			},
 		})
 
+		status := http.StatusOK
+
 		if result.HasErrors() {
 			messages := []string{}
 			attr := []attribute.KeyValue{}
 			for i, err := range result.Errors {
 				messages = append(messages, err.Message)
 				attr = append(attr, attribute.String(fmt.Sprintf("error.%d", i), err.Message))
+
+				if err.Extensions["code"] == common.ErrRateLimited {
+					status = http.StatusTooManyRequests
+				}
 			}
 			span.AddEvent("errors", trace.WithAttributes(attr...))
 			span.SetStatus(codes.Error, strings.Join(messages, ", "))
 		}
 
-		return common.NewJsonResponse(http.StatusOK, result, &common.ResponseMetadata{
+		return common.NewJsonResponse(status, result, &common.ResponseMetadata{
 			Headers: headers,
 		})
 	}
 }
 
+// IsReadOnlyRequest returns true if the operation in the request body is a query, which only reads data.
+func IsReadOnlyRequest(body []byte) bool {
+	var params GraphQLRequest
+	if err := json.Unmarshal(body, &params); err != nil {
+		return false
+	}
+
+	document, err := gqlparser.Parse(gqlparser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(params.Query),
+			Name: "GraphQL request",
+		}),
+	})
+	if err != nil {
+		return false
+	}
+
+	operations := 0
+	for _, definition := range document.Definitions {
+		op, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		if params.OperationName != "" && (op.Name == nil || op.Name.Value != params.OperationName) {
+			continue
+		}
+
+		if op.Operation != ast.OperationTypeQuery {
+			return false
+		}
+		operations++
+	}
+
+	return operations > 0
+}
+
 // NewGraphQLSchema creates a map of graphql.Schema objects where the keys
 // are the API names from the provided proto.Schema.
 func NewGraphQLSchema(proto *proto.Schema, api *proto.Api) (*graphql.Schema, error) {
@@ -184,6 +226,10 @@ func NewGraphQLSchema(proto *proto.Schema, api *proto.Api) (*graphql.Schema, err
 			Name:   "Mutation",
 			Fields: graphql.Fields{},
 		}),
+		subscription: graphql.NewObject(graphql.ObjectConfig{
+			Name:   "Subscription",
+			Fields: graphql.Fields{},
+		}),
 		inputs: map[string]*graphql.InputObject{},
 		types:  make(map[string]graphql.Type),
 		enums:  map[string]*graphql.Enum{},
@@ -195,20 +241,34 @@ func NewGraphQLSchema(proto *proto.Schema, api *proto.Api) (*graphql.Schema, err
 // A graphqlSchemaBuilder exposes a Make method, that makes a set of graphql.Schema objects - one for each
 // of the APIs defined in the keel schema provided at construction time.
 type graphqlSchemaBuilder struct {
-	schema   *proto.Schema
-	query    *graphql.Object
-	mutation *graphql.Object
-	inputs   map[string]*graphql.InputObject
-	types    map[string]graphql.Type
-	enums    map[string]*graphql.Enum
-	globals  map[string]*graphql.Scalar
+	schema       *proto.Schema
+	query        *graphql.Object
+	mutation     *graphql.Object
+	subscription *graphql.Object
+	inputs       map[string]*graphql.InputObject
+	types        map[string]graphql.Type
+	enums        map[string]*graphql.Enum
+	globals      map[string]*graphql.Scalar
 }
 
 // build returns a graphql.Schema that implements the given API.
 func (mk *graphqlSchemaBuilder) build(api *proto.Api, schema *proto.Schema) (*graphql.Schema, error) {
 	for _, actionName := range proto.GetActionNamesForApi(schema, api) {
 		action := schema.FindAction(actionName)
-		err := mk.addAction(action, schema)
+		err := mk.addAction(api, action, schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, event := range schema.GetEvents() {
+		if !lo.ContainsBy(api.GetApiModels(), func(m *proto.ApiModel) bool {
+			return m.GetModelName() == event.GetModelName()
+		}) {
+			continue
+		}
+
+		err := mk.addEvent(event)
 		if err != nil {
 			return nil, err
 		}
@@ -232,12 +292,14 @@ func (mk *graphqlSchemaBuilder) build(api *proto.Api, schema *proto.Schema) (*gr
 	}
 
 	mutation := lo.Ternary(len(mk.mutation.Fields()) > 0, mk.mutation, nil)
+	subscription := lo.Ternary(len(mk.subscription.Fields()) > 0, mk.subscription, nil)
 
 	gSchema, err := graphql.NewSchema(graphql.SchemaConfig{
 		Query: mk.query,
 		Types: types,
-		// graphql won't accept a mutation object that has zero fields.
-		Mutation: mutation,
+		// graphql won't accept a mutation or subscription object that has zero fields.
+		Mutation:     mutation,
+		Subscription: subscription,
 	})
 	if err != nil {
 		return nil, err
@@ -365,6 +427,12 @@ func (mk *graphqlSchemaBuilder) addModel(model *proto.Model) (*graphql.Object, e
 					return nil, err
 				}
 
+				// The row a belongs-to relationship refers to is returned even if it has been soft deleted, as the
+				// foreign key still refers to it
+				if !field.IsBelongsTo() {
+					query.ExcludeSoftDeleted()
+				}
+
 				scope := actions.NewModelScope(ctx, relatedModel, mk.schema)
 
 				switch {
@@ -456,6 +524,7 @@ func (mk *graphqlSchemaBuilder) addModel(model *proto.Model) (*graphql.Object, e
 
 // addOperation generates the graphql field object to represent the given proto.Action.
 func (mk *graphqlSchemaBuilder) addAction(
+	api *proto.Api,
 	action *proto.Action,
 	schema *proto.Schema) error {
 	model := schema.FindModel(action.GetModelName())
@@ -504,18 +573,37 @@ func (mk *graphqlSchemaBuilder) addAction(
 		field.Type = modelType
 		mk.query.AddFieldConfig(action.GetName(), field)
 	case proto.ActionType_ACTION_TYPE_CREATE,
-		proto.ActionType_ACTION_TYPE_UPDATE:
+		proto.ActionType_ACTION_TYPE_UPDATE,
+		proto.ActionType_ACTION_TYPE_RESTORE,
+		proto.ActionType_ACTION_TYPE_UPSERT:
 		field.Type = graphql.NewNonNull(modelType)
 		mk.mutation.AddFieldConfig(action.GetName(), field)
 	case proto.ActionType_ACTION_TYPE_DELETE:
 		field.Type = deleteResponseType
 		mk.mutation.AddFieldConfig(action.GetName(), field)
+	case proto.ActionType_ACTION_TYPE_CREATE_MANY,
+		proto.ActionType_ACTION_TYPE_UPDATE_MANY:
+		field.Type = graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(modelType)))
+		mk.mutation.AddFieldConfig(action.GetName(), field)
+	case proto.ActionType_ACTION_TYPE_DELETE_MANY:
+		field.Type = graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))
+		mk.mutation.AddFieldConfig(action.GetName(), field)
 	case proto.ActionType_ACTION_TYPE_LIST:
 		// for list types we need to wrap the output type in the
 		// connection type which allows for pagination
 		resultInfo := mk.makeResultInfoType(action)
 		field.Type = mk.makeConnectionType(modelType, resultInfo)
 		mk.query.AddFieldConfig(action.GetName(), field)
+	case proto.ActionType_ACTION_TYPE_AGGREGATE:
+		responseMessage := schema.FindMessage(action.GetResponseMessageName())
+		if responseMessage == nil {
+			return fmt.Errorf("response message does not exist: %s", action.GetResponseMessageName())
+		}
+		field.Type, err = mk.addMessage(responseMessage)
+		if err != nil {
+			return err
+		}
+		mk.query.AddFieldConfig(action.GetName(), field)
 	case proto.ActionType_ACTION_TYPE_READ:
 		responseMessage := schema.FindMessage(action.GetResponseMessageName())
 		if responseMessage == nil {
@@ -546,7 +634,7 @@ func (mk *graphqlSchemaBuilder) addAction(
 		return fmt.Errorf("addAction() does not yet support this action type: %v", action.GetType())
 	}
 
-	field.Resolve = ActionFunc(schema, action)
+	field.Resolve = ActionFunc(schema, api, action)
 
 	return nil
 }
@@ -55,6 +55,9 @@ type IntrospectionQueryResult struct {
 		MutationType struct {
 			Name string `json:"name"`
 		} `json:"mutationType"`
+		SubscriptionType struct {
+			Name string `json:"name"`
+		} `json:"subscriptionType"`
 		QueryType struct {
 			Name string `json:"name"`
 		} `json:"queryType"`
@@ -107,9 +110,9 @@ func ToGraphQLSchemaLanguage(response common.Response) string {
 		aType := r.Schema.Types[a]
 		bType := r.Schema.Types[b]
 
-		// Make sure Query and Mutation come at the top of the generated
-		// schema with Query first and Mutation second
-		typeNameOrder := []string{"Mutation", "Query"}
+		// Make sure Query, Mutation and Subscription come at the top of the
+		// generated schema with Query first, Mutation second and Subscription third
+		typeNameOrder := []string{"Subscription", "Mutation", "Query"}
 		aIndex := lo.IndexOf(typeNameOrder, aType.Name)
 		bIndex := lo.IndexOf(typeNameOrder, bType.Name)
 		if aIndex != -1 || bIndex != -1 {
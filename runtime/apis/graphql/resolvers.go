@@ -11,12 +11,24 @@ import (
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/actions"
 	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/ratelimit"
 )
 
-func ActionFunc(schema *proto.Schema, action *proto.Action) func(p graphql.ResolveParams) (interface{}, error) {
+func ActionFunc(schema *proto.Schema, api *proto.Api, action *proto.Action) func(p graphql.ResolveParams) (interface{}, error) {
 	return func(p graphql.ResolveParams) (interface{}, error) {
 		span := trace.SpanFromContext(p.Context)
 
+		rootValue := p.Info.RootValue.(map[string]interface{})
+		headersValue := rootValue["headers"].(map[string][]string)
+
+		err := ratelimit.Check(p.Context, api, action)
+		if err != nil {
+			for k, v := range common.RetryAfterHeaders(err) {
+				headersValue[k] = v
+			}
+			return nil, err
+		}
+
 		scope := actions.NewScope(p.Context, action, schema)
 		input := p.Args["input"]
 
@@ -39,9 +51,6 @@ func ActionFunc(schema *proto.Schema, action *proto.Action) func(p graphql.Resol
 			return nil, err
 		}
 
-		rootValue := p.Info.RootValue.(map[string]interface{})
-		headersValue := rootValue["headers"].(map[string][]string)
-
 		if meta != nil {
 			for k, v := range meta.Headers {
 				headersValue[k] = v
@@ -0,0 +1,167 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/sirupsen/logrus"
+	"github.com/teamkeel/graphql"
+	"github.com/teamkeel/keel/events"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+)
+
+// addEvent generates the subscription field to represent the given proto.Event.
+func (mk *graphqlSchemaBuilder) addEvent(event *proto.Event) error {
+	model := mk.schema.FindModel(event.GetModelName())
+	if model == nil {
+		return fmt.Errorf("model does not exist for event: %s", event.GetName())
+	}
+
+	eventType, err := mk.makeEventType(model)
+	if err != nil {
+		return err
+	}
+
+	mk.subscription.AddFieldConfig(subscriptionFieldName(event), &graphql.Field{
+		Type:      graphql.NewNonNull(eventType),
+		Subscribe: EventSubscribeFunc(mk.schema, event),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source, nil
+		},
+	})
+
+	return nil
+}
+
+// makeEventType generates the graphql type for the events of the given model, which
+// is shared between its created, updated and deleted subscription fields.
+func (mk *graphqlSchemaBuilder) makeEventType(model *proto.Model) (*graphql.Object, error) {
+	if out, ok := mk.types[fmt.Sprintf("event-%s", model.GetName())]; ok {
+		return out.(*graphql.Object), nil
+	}
+
+	modelType, err := mk.addModel(model)
+	if err != nil {
+		return nil, err
+	}
+
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: model.GetName() + "Event",
+		Fields: graphql.Fields{
+			"eventName": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "The name of the event, e.g. order.created.",
+			},
+			"occurredAt": &graphql.Field{
+				Type:        graphql.NewNonNull(timestampType),
+				Description: "The time at which the event occurred.",
+			},
+			"identityId": &graphql.Field{
+				Type:        graphql.ID,
+				Description: "The identity that resulted in the event, if any.",
+			},
+			"data": &graphql.Field{
+				Type:        graphql.NewNonNull(modelType),
+				Description: "The record at the time of the event.",
+			},
+			"previousData": &graphql.Field{
+				Type:        modelType,
+				Description: "The record before the event, if it existed.",
+			},
+		},
+	})
+
+	mk.types[fmt.Sprintf("event-%s", model.GetName())] = eventType
+
+	return eventType, nil
+}
+
+// subscriptionFieldName generates the subscription field name for an event,
+// e.g. order_item.created becomes orderItemCreated.
+func subscriptionFieldName(event *proto.Event) string {
+	return strcase.ToLowerCamel(strings.ReplaceAll(event.GetName(), ".", "_"))
+}
+
+// EventSubscribeFunc subscribes to the event broker in the context and emits each occurrence
+// of the given event which the subscriber is authorised to get. Since row-based permission
+// rules are evaluated against the database, deleted records will only be emitted if the
+// model's get permissions can be resolved without a database lookup (e.g. by role).
+func EventSubscribeFunc(schema *proto.Schema, event *proto.Event) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		broker, err := events.GetBroker(p.Context)
+		if err != nil {
+			return nil, errors.New("subscriptions are not supported in this environment")
+		}
+
+		model := schema.FindModel(event.GetModelName())
+		listener, unsubscribe := broker.Subscribe()
+		results := make(chan interface{})
+
+		go func() {
+			defer close(results)
+			defer unsubscribe()
+
+			for {
+				select {
+				case <-p.Context.Done():
+					return
+				case e, ok := <-listener:
+					if !ok {
+						return
+					}
+
+					if e.EventName != event.GetName() || e.Target == nil {
+						continue
+					}
+
+					authorised, err := authoriseEvent(p.Context, schema, model, e)
+					if err != nil {
+						logrus.WithError(err).Error("error authorising subscription event")
+						continue
+					}
+
+					if !authorised {
+						continue
+					}
+
+					select {
+					case results <- eventResponse(e):
+					case <-p.Context.Done():
+						return
+					}
+				}
+			}
+		}()
+
+		return results, nil
+	}
+}
+
+// authoriseEvent returns true if the subscriber is authorised to get the event's record.
+func authoriseEvent(ctx context.Context, schema *proto.Schema, model *proto.Model, e *events.Event) (bool, error) {
+	scope := actions.NewModelScope(ctx, model, schema)
+	return actions.AuthoriseForActionType(scope, proto.ActionType_ACTION_TYPE_GET, []map[string]any{e.Target.Data})
+}
+
+// eventResponse maps an event to the shape expected by the event's graphql type.
+func eventResponse(e *events.Event) map[string]any {
+	resp := map[string]any{
+		"eventName":  e.EventName,
+		"occurredAt": e.OccurredAt,
+		"data":       e.Target.Data,
+	}
+
+	if e.IdentityId != "" {
+		resp["identityId"] = e.IdentityId
+	}
+
+	if e.Target.PreviousData != nil {
+		resp["previousData"] = e.Target.PreviousData
+	}
+
+	return resp
+}
@@ -461,9 +461,13 @@ func sourceToTime(source interface{}) (*time.Time, error) {
 		return &v, nil
 	case string:
 		t, err := time.Parse(time.RFC3339, v)
-
 		if err != nil {
-			return nil, err
+			// Dates read from the audit table (e.g. in subscription events) have no time part
+			var dateErr error
+			t, dateErr = time.Parse(time.DateOnly, v)
+			if dateErr != nil {
+				return nil, err
+			}
 		}
 
 		return &t, nil
@@ -0,0 +1,346 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/teamkeel/graphql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/teamkeel/keel/events"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+)
+
+// WebSocketSubprotocol is the graphql-ws protocol used for subscriptions.
+// See https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md
+const WebSocketSubprotocol = "graphql-transport-ws"
+
+// graphql-ws message types.
+const (
+	messageConnectionInit = "connection_init"
+	messageConnectionAck  = "connection_ack"
+	messagePing           = "ping"
+	messagePong           = "pong"
+	messageSubscribe      = "subscribe"
+	messageNext           = "next"
+	messageError          = "error"
+	messageComplete       = "complete"
+)
+
+// graphql-ws close codes.
+const (
+	closeInvalidMessage         websocket.StatusCode = 4400
+	closeUnauthorized           websocket.StatusCode = 4401
+	closeForbidden              websocket.StatusCode = 4403
+	closeInitialisationTimeout  websocket.StatusCode = 4408
+	closeSubscriberExists       websocket.StatusCode = 4409
+	closeTooManyInitialisations websocket.StatusCode = 4429
+)
+
+// connectionInitTimeout is how long a client has to send connection_init after connecting.
+const connectionInitTimeout = 10 * time.Second
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// IsWebSocketUpgrade returns true if the request is asking to upgrade to a WebSocket connection.
+func IsWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// NewSubscriptionHandler upgrades the request to a WebSocket connection and serves GraphQL
+// subscriptions using the graphql-ws protocol. The connection is served on its own goroutine,
+// so the handler returns as soon as the upgrade has completed.
+func NewSubscriptionHandler(s *proto.Schema, api *proto.Api) http.HandlerFunc {
+	var schema *graphql.Schema
+	var mutex sync.Mutex
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), "GraphQL Subscription")
+		defer span.End()
+
+		// Events are published to subscribers via an in-process broker, which is
+		// only available when the runtime is a long-lived server.
+		if !events.HasBroker(ctx) {
+			w.WriteHeader(http.StatusNotImplemented)
+			_, _ = w.Write([]byte("subscriptions are not supported in this environment"))
+			return
+		}
+
+		mutex.Lock()
+		if schema == nil {
+			var err error
+			schema, err = NewGraphQLSchema(s, api)
+			if err != nil {
+				mutex.Unlock()
+				span.RecordError(err, trace.WithStackTrace(true))
+				span.SetStatus(codes.Error, err.Error())
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(fmt.Sprintf("error initialising GraphQL: %s", err.Error())))
+				return
+			}
+		}
+		mutex.Unlock()
+
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+			Subprotocols: []string{WebSocketSubprotocol},
+			// Authentication is done with a bearer token rather than cookies,
+			// so there is no need to restrict the origin.
+			OriginPatterns: []string{"*"},
+		})
+		if err != nil {
+			// Accept has already written an error response.
+			span.RecordError(err, trace.WithStackTrace(true))
+			span.SetStatus(codes.Error, err.Error())
+			return
+		}
+
+		if conn.Subprotocol() != WebSocketSubprotocol {
+			_ = conn.Close(websocket.StatusProtocolError, fmt.Sprintf("client must use the %s subprotocol", WebSocketSubprotocol))
+			return
+		}
+
+		span.SetAttributes(attribute.String("api.protocol", "GraphQL WebSocket"))
+
+		sc := &subscriptionConn{
+			conn:       conn,
+			schema:     s,
+			gqlSchema:  schema,
+			headers:    r.Header.Clone(),
+			operations: map[string]context.CancelFunc{},
+		}
+
+		// The request context is cancelled once this handler returns.
+		go sc.serve(context.WithoutCancel(ctx))
+	}
+}
+
+// subscriptionConn is a single graphql-ws connection, which can have many subscriptions.
+type subscriptionConn struct {
+	conn        *websocket.Conn
+	schema      *proto.Schema
+	gqlSchema   *graphql.Schema
+	headers     http.Header
+	mutex       sync.Mutex
+	initialised bool
+	operations  map[string]context.CancelFunc
+}
+
+func (sc *subscriptionConn) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer func() { _ = sc.conn.CloseNow() }()
+
+	initTimer := time.AfterFunc(connectionInitTimeout, func() {
+		sc.mutex.Lock()
+		defer sc.mutex.Unlock()
+		if !sc.initialised {
+			_ = sc.conn.Close(closeInitialisationTimeout, "Connection initialisation timeout")
+		}
+	})
+	defer initTimer.Stop()
+
+	for {
+		_, data, err := sc.conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			_ = sc.conn.Close(closeInvalidMessage, "Invalid message received")
+			return
+		}
+
+		switch msg.Type {
+		case messageConnectionInit:
+			sc.mutex.Lock()
+			alreadyInitialised := sc.initialised
+			sc.mutex.Unlock()
+
+			if alreadyInitialised {
+				_ = sc.conn.Close(closeTooManyInitialisations, "Too many initialisation requests")
+				return
+			}
+
+			ctx, err = sc.authenticate(ctx, msg.Payload)
+			if err != nil {
+				_ = sc.conn.Close(closeForbidden, "Forbidden")
+				return
+			}
+
+			sc.mutex.Lock()
+			sc.initialised = true
+			sc.mutex.Unlock()
+
+			sc.write(ctx, wsMessage{Type: messageConnectionAck})
+		case messagePing:
+			sc.write(ctx, wsMessage{Type: messagePong})
+		case messagePong:
+			// Nothing to do
+		case messageSubscribe:
+			sc.mutex.Lock()
+			initialised := sc.initialised
+			_, exists := sc.operations[msg.ID]
+			sc.mutex.Unlock()
+
+			if !initialised {
+				_ = sc.conn.Close(closeUnauthorized, "Unauthorized")
+				return
+			}
+
+			if msg.ID == "" {
+				_ = sc.conn.Close(closeInvalidMessage, "Invalid message received")
+				return
+			}
+
+			if exists {
+				_ = sc.conn.Close(closeSubscriberExists, fmt.Sprintf("Subscriber for %s already exists", msg.ID))
+				return
+			}
+
+			var params GraphQLRequest
+			if err := json.Unmarshal(msg.Payload, &params); err != nil {
+				_ = sc.conn.Close(closeInvalidMessage, "Invalid message received")
+				return
+			}
+
+			opCtx, opCancel := context.WithCancel(ctx)
+
+			sc.mutex.Lock()
+			sc.operations[msg.ID] = opCancel
+			sc.mutex.Unlock()
+
+			go sc.subscribe(opCtx, msg.ID, params)
+		case messageComplete:
+			sc.mutex.Lock()
+			if opCancel, ok := sc.operations[msg.ID]; ok {
+				opCancel()
+				delete(sc.operations, msg.ID)
+			}
+			sc.mutex.Unlock()
+		default:
+			_ = sc.conn.Close(closeInvalidMessage, "Invalid message received")
+			return
+		}
+	}
+}
+
+// authenticate resolves the identity or service account using the Authorization header from either the
+// upgrade request or the connection_init payload, as browsers cannot set headers on
+// WebSocket requests.
+func (sc *subscriptionConn) authenticate(ctx context.Context, payload json.RawMessage) (context.Context, error) {
+	headers, err := connectionHeaders(sc.headers, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return actions.HandleAuthorization(ctx, sc.schema, headers)
+}
+
+// connectionHeaders merges the string values of the connection_init payload into the headers.
+func connectionHeaders(headers http.Header, payload json.RawMessage) (http.Header, error) {
+	headers = headers.Clone()
+
+	if len(payload) > 0 {
+		var params map[string]any
+		if err := json.Unmarshal(payload, &params); err != nil {
+			return nil, err
+		}
+
+		for k, v := range params {
+			if value, ok := v.(string); ok {
+				headers.Set(k, value)
+			}
+		}
+	}
+
+	return headers, nil
+}
+
+// subscribe executes the subscription operation and writes each result to the connection
+// until either the client completes the operation or the connection is closed.
+func (sc *subscriptionConn) subscribe(ctx context.Context, id string, params GraphQLRequest) {
+	ctx, span := tracer.Start(ctx, "Subscribe")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("params.query", params.Query),
+		attribute.String("params.operationName", params.OperationName),
+	)
+
+	results := graphql.Subscribe(graphql.Params{
+		Schema:         *sc.gqlSchema,
+		Context:        ctx,
+		RequestString:  params.Query,
+		VariableValues: params.Variables,
+		OperationName:  params.OperationName,
+	})
+
+	first := true
+	for result := range results {
+		// Errors before the subscription has started (e.g. validation errors) end the operation.
+		if first && result.HasErrors() && result.Data == nil {
+			span.SetStatus(codes.Error, result.Errors[0].Message)
+			payload, _ := json.Marshal(result.Errors)
+			sc.write(ctx, wsMessage{ID: id, Type: messageError, Payload: payload})
+			sc.remove(id)
+			return
+		}
+		first = false
+
+		payload, err := json.Marshal(result)
+		if err != nil {
+			logrus.WithError(err).Error("error marshalling subscription result")
+			continue
+		}
+
+		sc.write(ctx, wsMessage{ID: id, Type: messageNext, Payload: payload})
+	}
+
+	// Only notify the client if the subscription ended on the server's side.
+	if sc.remove(id) {
+		sc.write(context.WithoutCancel(ctx), wsMessage{ID: id, Type: messageComplete})
+	}
+}
+
+// remove forgets the operation and returns true if it was still active.
+func (sc *subscriptionConn) remove(id string) bool {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	opCancel, ok := sc.operations[id]
+	if ok {
+		opCancel()
+		delete(sc.operations, id)
+	}
+
+	return ok
+}
+
+func (sc *subscriptionConn) write(ctx context.Context, msg wsMessage) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		logrus.WithError(err).Error("error marshalling subscription message")
+		return
+	}
+
+	err = sc.conn.Write(ctx, websocket.MessageText, b)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logrus.WithError(err).Debug("error writing subscription message")
+	}
+}
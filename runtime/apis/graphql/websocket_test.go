@@ -0,0 +1,188 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/events"
+	"github.com/teamkeel/keel/runtime"
+	"github.com/teamkeel/keel/runtime/apis/graphql"
+	"github.com/teamkeel/keel/schema"
+	"github.com/teamkeel/keel/schema/reader"
+)
+
+const subscriptionsSchema = `
+model Order {
+	fields {
+		reference Text
+	}
+
+	actions {
+		get getOrder(id)
+	}
+
+	@on([create], orderCreated)
+	@permission(expression: true, actions: [get])
+}
+
+api Test {
+	models {
+		Order
+	}
+}`
+
+func newSubscriptionsServer(t *testing.T, broker *events.Broker) *httptest.Server {
+	builder := schema.Builder{}
+	protoSchema, err := builder.MakeFromInputs(&reader.Inputs{
+		SchemaFiles: []*reader.SchemaFile{
+			{
+				Contents: subscriptionsSchema,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	handler := runtime.NewHttpHandler(protoSchema)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(events.WithBroker(r.Context(), broker)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func dialSubscriptions(t *testing.T, ctx context.Context, server *httptest.Server) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/test/graphql"
+	conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{
+		Subprotocols: []string{graphql.WebSocketSubprotocol},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.CloseNow() })
+
+	return conn
+}
+
+func writeMessage(t *testing.T, ctx context.Context, conn *websocket.Conn, msg map[string]any) {
+	b, err := json.Marshal(msg)
+	require.NoError(t, err)
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, b))
+}
+
+func readMessage(t *testing.T, ctx context.Context, conn *websocket.Conn) map[string]any {
+	_, b, err := conn.Read(ctx)
+	require.NoError(t, err)
+
+	var msg map[string]any
+	require.NoError(t, json.Unmarshal(b, &msg))
+	return msg
+}
+
+func TestSubscriptionReceivesEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	broker := events.NewBroker()
+	server := newSubscriptionsServer(t, broker)
+	conn := dialSubscriptions(t, ctx, server)
+
+	writeMessage(t, ctx, conn, map[string]any{"type": "connection_init"})
+	require.Equal(t, "connection_ack", readMessage(t, ctx, conn)["type"])
+
+	writeMessage(t, ctx, conn, map[string]any{
+		"id":   "1",
+		"type": "subscribe",
+		"payload": map[string]any{
+			"query": "subscription { orderCreated { eventName data { id reference } } }",
+		},
+	})
+
+	// The subscription is registered asynchronously, so keep publishing until it is received.
+	go func() {
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				broker.Publish(&events.Event{
+					EventName:  "order.created",
+					OccurredAt: time.Now(),
+					Target: &events.EventTarget{
+						Id:   "123",
+						Type: "Order",
+						Data: map[string]any{"id": "123", "reference": "ABC"},
+					},
+				})
+			}
+		}
+	}()
+
+	msg := readMessage(t, ctx, conn)
+	require.Equal(t, "next", msg["type"])
+	require.Equal(t, "1", msg["id"])
+	require.Equal(t, map[string]any{
+		"data": map[string]any{
+			"orderCreated": map[string]any{
+				"eventName": "order.created",
+				"data": map[string]any{
+					"id":        "123",
+					"reference": "ABC",
+				},
+			},
+		},
+	}, msg["payload"])
+
+	writeMessage(t, ctx, conn, map[string]any{"id": "1", "type": "complete"})
+	require.NoError(t, conn.Close(websocket.StatusNormalClosure, ""))
+}
+
+func TestSubscriptionRequiresConnectionInit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := newSubscriptionsServer(t, events.NewBroker())
+	conn := dialSubscriptions(t, ctx, server)
+
+	writeMessage(t, ctx, conn, map[string]any{
+		"id":   "1",
+		"type": "subscribe",
+		"payload": map[string]any{
+			"query": "subscription { orderCreated { eventName } }",
+		},
+	})
+
+	_, _, err := conn.Read(ctx)
+	require.Equal(t, websocket.StatusCode(4401), websocket.CloseStatus(err))
+}
+
+func TestSubscriptionInvalidQuery(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := newSubscriptionsServer(t, events.NewBroker())
+	conn := dialSubscriptions(t, ctx, server)
+
+	writeMessage(t, ctx, conn, map[string]any{"type": "connection_init"})
+	require.Equal(t, "connection_ack", readMessage(t, ctx, conn)["type"])
+
+	writeMessage(t, ctx, conn, map[string]any{
+		"id":   "1",
+		"type": "subscribe",
+		"payload": map[string]any{
+			"query": "subscription { orderDeleted { eventName } }",
+		},
+	})
+
+	msg := readMessage(t, ctx, conn)
+	require.Equal(t, "error", msg["type"])
+	require.Equal(t, "1", msg["id"])
+}
@@ -9,11 +9,11 @@ import (
 
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/actions"
-	"github.com/teamkeel/keel/runtime/auth"
 	"github.com/teamkeel/keel/runtime/common"
 	"github.com/teamkeel/keel/runtime/jsonschema"
 	"github.com/teamkeel/keel/runtime/locale"
 	"github.com/teamkeel/keel/runtime/openapi"
+	"github.com/teamkeel/keel/runtime/ratelimit"
 	"github.com/teamkeel/keel/runtime/runtimectx"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -42,13 +42,10 @@ func NewHandler(p *proto.Schema, api *proto.Api) common.HandlerFunc {
 			attribute.String("api.protocol", "HTTP JSON"),
 		)
 
-		identity, err := actions.HandleAuthorizationHeader(ctx, p, r.Header)
+		ctx, err := actions.HandleAuthorization(ctx, p, r.Header)
 		if err != nil {
 			return NewErrorResponse(ctx, err, nil)
 		}
-		if identity != nil {
-			ctx = auth.WithIdentity(ctx, identity)
-		}
 
 		// handle any Time-Zone headers
 		location, err := locale.HandleTimezoneHeader(ctx, r.Header)
@@ -75,6 +72,11 @@ func NewHandler(p *proto.Schema, api *proto.Api) common.HandlerFunc {
 			return NewErrorResponse(ctx, common.NewMethodNotFoundError(), nil)
 		}
 
+		err = ratelimit.Check(ctx, api, action)
+		if err != nil {
+			return NewErrorResponse(ctx, err, nil)
+		}
+
 		validation, err := jsonschema.ValidateRequest(ctx, p, action, inputs)
 		if err != nil {
 			// Validation cannot complete due to an invalid JSON schema
@@ -153,6 +155,10 @@ func NewErrorResponse(ctx context.Context, err error, data map[string]any) commo
 			httpCode = http.StatusMethodNotAllowed
 		case common.ErrInputMalformed:
 			httpCode = http.StatusBadRequest
+		case common.ErrRateLimited:
+			httpCode = http.StatusTooManyRequests
+		case common.ErrConflict:
+			httpCode = http.StatusConflict
 		}
 
 		span.SetAttributes(
@@ -168,5 +174,7 @@ func NewErrorResponse(ctx context.Context, err error, data map[string]any) commo
 		Code:    code,
 		Message: message,
 		Data:    data,
-	}, nil)
+	}, &common.ResponseMetadata{
+		Headers: common.RetryAfterHeaders(err),
+	})
 }
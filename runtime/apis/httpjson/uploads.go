@@ -0,0 +1,121 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/storage"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// UploadRequest is the body of a request for a URL to upload a file to.
+type UploadRequest struct {
+	// The action and File input the upload will be used for.
+	Action string `json:"action"`
+	Field  string `json:"field"`
+
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+}
+
+// NewUploadHandler handles requests for URLs which files can be uploaded to directly, rather than
+// sending them to an action as data URLs. The key of the uploaded file is then given as the value
+// of the File input when calling the action, and is only accepted from the same caller for the same
+// action and input. Uploads which are never used are deleted after
+// storage.UploadExpiryDuration.
+func NewUploadHandler(p *proto.Schema, api *proto.Api) common.HandlerFunc {
+	actionNames := proto.GetActionNamesForApi(p, api)
+
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Upload URL")
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("api.protocol", "HTTP JSON"),
+		)
+
+		if r.Method != http.MethodPost {
+			return NewErrorResponse(ctx, common.NewHttpMethodNotAllowedError("only HTTP POST accepted"), nil)
+		}
+
+		// Invalid tokens are rejected, even though the upload itself is not authorised until it's used. The
+		// caller is signed into the key of the upload so that nobody else can use the uploaded file.
+		ctx, err := actions.HandleAuthorization(ctx, p, r.Header)
+		if err != nil {
+			return NewErrorResponse(ctx, err, nil)
+		}
+
+		var req UploadRequest
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return NewErrorResponse(ctx, common.NewInputMalformedError("error parsing POST body"), nil)
+		}
+
+		if req.ContentType == "" {
+			return NewErrorResponse(ctx, common.NewValidationError("contentType is required"), nil)
+		}
+
+		action := p.FindAction(req.Action)
+		if action == nil || !lo.Contains(actionNames, action.GetName()) {
+			return NewErrorResponse(ctx, common.NewMethodNotFoundError(), nil)
+		}
+
+		if !hasFileInput(p, action, req.Field) {
+			return NewErrorResponse(ctx, common.NewValidationError(fmt.Sprintf("%s is not a File input of %s", req.Field, action.GetName())), nil)
+		}
+
+		span.SetAttributes(
+			attribute.String("action", action.GetName()),
+			attribute.String("field", req.Field),
+		)
+
+		store, err := runtimectx.GetStorage(ctx)
+		if err != nil {
+			return NewErrorResponse(ctx, err, nil)
+		}
+
+		upload, err := store.GenerateUploadURL(ctx, req.Filename, req.ContentType)
+		if err != nil {
+			return NewErrorResponse(ctx, err, nil)
+		}
+
+		upload.Key, err = oauth.GenerateUploadToken(ctx, upload.Key, action.GetName(), req.Field, storage.UploadExpiryDuration)
+		if err != nil {
+			return NewErrorResponse(ctx, err, nil)
+		}
+
+		return common.NewJsonResponse(http.StatusOK, upload, nil)
+	}
+}
+
+// hasFileInput returns true if the action has a File input with the given name, in the same way
+// that inputs are found when the action stores its files. Built-in create, update and upsert actions,
+// and their bulk actions, store uploads in their values inputs, and functions are given the uploaded
+// files of their inputs.
+func hasFileInput(p *proto.Schema, action *proto.Action, name string) bool {
+	var message *proto.Message
+	switch action.GetImplementation() {
+	case proto.ActionImplementation_ACTION_IMPLEMENTATION_CUSTOM:
+		message = p.FindMessage(action.GetInputMessageName())
+	case proto.ActionImplementation_ACTION_IMPLEMENTATION_AUTO:
+		if action.GetType() != proto.ActionType_ACTION_TYPE_CREATE && action.GetType() != proto.ActionType_ACTION_TYPE_UPDATE && action.GetType() != proto.ActionType_ACTION_TYPE_UPSERT &&
+			action.GetType() != proto.ActionType_ACTION_TYPE_CREATE_MANY && action.GetType() != proto.ActionType_ACTION_TYPE_UPDATE_MANY {
+			return false
+		}
+		message = proto.FindValuesInputMessage(p, action.GetName())
+	}
+
+	if message == nil {
+		return false
+	}
+
+	field := message.FindField(name)
+	return field != nil && field.GetType().GetType() == proto.Type_TYPE_FILE
+}
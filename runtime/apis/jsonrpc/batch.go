@@ -0,0 +1,214 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/events"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BatchTransactionHeader can be set to "true" to execute all the requests in a batch
+// in a single database transaction, which is rolled back if any request fails.
+const BatchTransactionHeader = "Batch-Transaction"
+
+// JsonRpcTransactionRolledBack is the error code given to the requests in a batch
+// which were not committed because another request in the transaction failed.
+const JsonRpcTransactionRolledBack = -32010 // Not part of the official spec
+
+// MaxBatchSize is the most requests which can be made in a single batch.
+const MaxBatchSize = 100
+
+var errBatchFailed = errors.New("batch request failed")
+
+type batchResult struct {
+	request  *JsonRpcRequest
+	response any
+	meta     *common.ResponseMetadata
+	err      error
+	executed bool
+}
+
+// isBatch returns true if the request body is a JSON array.
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// handleBatch executes a JSON-RPC 2.0 batch request. Requests are executed in order and an
+// error in one request does not affect the others, unless inTransaction is set in which case
+// all requests are executed in a single database transaction and the first error aborts the batch. Requests for
+// functions cannot be made in a transaction, as their writes would not be rolled back.
+func handleBatch(ctx context.Context, schema *proto.Schema, api *proto.Api, body []byte, inTransaction bool) common.Response {
+	ctx, span := tracer.Start(ctx, "Batch")
+	defer span.End()
+
+	var messages []json.RawMessage
+	if err := json.Unmarshal(body, &messages); err != nil {
+		err = common.NewInputMalformedError(fmt.Sprintf("error parsing JSON: %s", err.Error()))
+		return NewErrorResponse(ctx, nil, err)
+	}
+
+	if len(messages) == 0 {
+		err := common.NewInputMalformedError("invalid JSON-RPC 2.0 request")
+		return NewErrorResponse(ctx, nil, err)
+	}
+
+	if len(messages) > MaxBatchSize {
+		err := common.NewInputMalformedError(fmt.Sprintf("a batch cannot contain more than %d requests", MaxBatchSize))
+		return NewErrorResponse(ctx, nil, err)
+	}
+
+	span.SetAttributes(
+		attribute.Int("batch.size", len(messages)),
+		attribute.Bool("batch.transaction", inTransaction),
+	)
+
+	results := make([]*batchResult, len(messages))
+	for i, message := range messages {
+		req, err := parseJsonRpcRequest(message)
+		switch {
+		case err != nil:
+			results[i] = &batchResult{err: common.NewInputMalformedError(fmt.Sprintf("error parsing JSON: %s", err.Error()))}
+		case !req.Valid():
+			results[i] = &batchResult{request: req, err: common.NewInputMalformedError("invalid JSON-RPC 2.0 request")}
+		case inTransaction && isFunction(schema, req):
+			// Functions run in the functions runtime with their own database connections, so their writes
+			// would be committed even if the transaction were rolled back
+			results[i] = &batchResult{request: req, err: common.NewInputMalformedError("functions cannot be executed in a batch transaction")}
+		default:
+			results[i] = &batchResult{request: req}
+		}
+	}
+
+	if !inTransaction {
+		for _, result := range results {
+			if result.err != nil {
+				continue
+			}
+			result.response, result.meta, result.err = execute(ctx, schema, api, result.request)
+			result.executed = true
+		}
+
+		return newBatchResponse(ctx, results, false)
+	}
+
+	// Nothing is executed if any request in the transaction is invalid
+	if lo.SomeBy(results, func(result *batchResult) bool { return result.err != nil }) {
+		return newBatchResponse(ctx, results, true)
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return common.InternalServerErrorResponse(ctx, err)
+	}
+
+	err = database.Transaction(ctx, func(txCtx context.Context) error {
+		// Events must not be sent until the transaction has been committed.
+		txCtx = events.WithDeferredEvents(txCtx)
+
+		for _, result := range results {
+			result.response, result.meta, result.err = execute(txCtx, schema, api, result.request)
+			result.executed = true
+			if result.err != nil {
+				return errBatchFailed
+			}
+		}
+
+		return nil
+	})
+
+	rolledBack := err != nil
+	if rolledBack && !errors.Is(err, errBatchFailed) {
+		return common.InternalServerErrorResponse(ctx, err)
+	}
+
+	if !rolledBack {
+		eventsErr := events.SendEvents(ctx, schema)
+		if eventsErr != nil {
+			span.RecordError(eventsErr)
+			span.SetStatus(codes.Error, eventsErr.Error())
+		}
+	}
+
+	return newBatchResponse(ctx, results, rolledBack)
+}
+
+// isFunction returns true if the request is for an action implemented by a custom function.
+func isFunction(schema *proto.Schema, req *JsonRpcRequest) bool {
+	action := schema.FindAction(req.Method)
+	return action != nil && action.IsFunction()
+}
+
+// newBatchResponse builds the array of responses for the batch, omitting notifications.
+// If the batch was rolled back then every request without its own error is given
+// a rolled back error.
+func newBatchResponse(ctx context.Context, results []*batchResult, rolledBack bool) common.Response {
+	span := trace.SpanFromContext(ctx)
+
+	responses := []any{}
+	headers := http.Header{}
+
+	for _, result := range results {
+		if result.request != nil && result.request.IsNotification() && result.request.Valid() {
+			continue
+		}
+
+		var id *string
+		if result.request != nil {
+			id = result.request.ID
+		}
+
+		switch {
+		case result.err != nil:
+			responses = append(responses, newErrorResponseBody(ctx, id, result.err))
+		case rolledBack:
+			responses = append(responses, JsonRpcErrorResponse{
+				JsonRpc: "2.0",
+				ID:      id,
+				Error: JsonRpcError{
+					Code:    JsonRpcTransactionRolledBack,
+					Message: lo.Ternary(result.executed, "transaction was rolled back", "not executed as transaction was rolled back"),
+				},
+			})
+		default:
+			if result.meta != nil {
+				for k, v := range result.meta.Headers {
+					headers[k] = v
+				}
+			}
+
+			responses = append(responses, JsonRpcSuccessResponse{
+				JsonRpc: "2.0",
+				ID:      *id,
+				Result:  result.response,
+			})
+		}
+	}
+
+	if rolledBack {
+		span.SetStatus(codes.Error, "batch transaction was rolled back")
+	}
+
+	// The client does not expect any response if every request was a notification.
+	if len(responses) == 0 {
+		return common.Response{
+			Status:  http.StatusNoContent,
+			Headers: headers,
+		}
+	}
+
+	return common.NewJsonResponse(http.StatusOK, responses, &common.ResponseMetadata{
+		Headers: headers,
+	})
+}
@@ -10,9 +10,9 @@ import (
 
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/actions"
-	"github.com/teamkeel/keel/runtime/auth"
 	"github.com/teamkeel/keel/runtime/common"
 	"github.com/teamkeel/keel/runtime/locale"
+	"github.com/teamkeel/keel/runtime/ratelimit"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -30,6 +30,8 @@ const (
 	JsonRpcInternalErrorCode  = -32603
 	JsonRpcUnauthorized       = -32001 // Not part of the official spec
 	JsonRpcForbidden          = -32003 // Not part of the official spec
+	JsonRpcConflict           = -32009 // Not part of the official spec
+	JsonRpcRateLimited        = -32029 // Not part of the official spec
 )
 
 func NewHandler(schema *proto.Schema, api *proto.Api) common.HandlerFunc {
@@ -42,13 +44,10 @@ func NewHandler(schema *proto.Schema, api *proto.Api) common.HandlerFunc {
 			return NewErrorResponse(ctx, nil, err)
 		}
 
-		identity, err := actions.HandleAuthorizationHeader(ctx, schema, r.Header)
+		ctx, err := actions.HandleAuthorization(ctx, schema, r.Header)
 		if err != nil {
 			return NewErrorResponse(ctx, nil, err)
 		}
-		if identity != nil {
-			ctx = auth.WithIdentity(ctx, identity)
-		}
 
 		// handle any Time-Zone headers
 		location, err := locale.HandleTimezoneHeader(ctx, r.Header)
@@ -57,50 +56,87 @@ func NewHandler(schema *proto.Schema, api *proto.Api) common.HandlerFunc {
 		}
 		ctx = locale.WithTimeLocation(ctx, location)
 
-		req, err := parseJsonRpcRequest(r.Body)
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			err = common.NewInputMalformedError(fmt.Sprintf("error reading body: %s", err.Error()))
+			return NewErrorResponse(ctx, nil, err)
+		}
+
+		span.SetAttributes(
+			attribute.String("api.protocol", "RPC"),
+		)
+
+		if isBatch(body) {
+			return handleBatch(ctx, schema, api, body, r.Header.Get(BatchTransactionHeader) == "true")
+		}
+
+		req, err := parseJsonRpcRequest(body)
 		if err != nil {
 			err = common.NewInputMalformedError(fmt.Sprintf("error parsing JSON: %s", err.Error()))
-			return NewErrorResponse(ctx, &req.ID, err)
+			return NewErrorResponse(ctx, req.ID, err)
 		}
 
 		if !req.Valid() {
 			err = common.NewInputMalformedError("invalid JSON-RPC 2.0 request")
-			return NewErrorResponse(ctx, &req.ID, err)
+			return NewErrorResponse(ctx, req.ID, err)
 		}
 
-		inputs := req.Params
+		if !req.IsNotification() {
+			span.SetAttributes(
+				attribute.String("request.id", *req.ID),
+			)
+		}
 
-		span.SetAttributes(
-			attribute.String("request.id", req.ID),
-			attribute.String("api.protocol", "RPC"),
-		)
+		response, meta, err := execute(ctx, schema, api, req)
 
-		action := schema.FindAction(req.Method)
-		if action == nil {
-			err = common.NewMethodNotFoundError()
-			return NewErrorResponse(ctx, &req.ID, err)
+		// The client does not expect any response for notifications.
+		if req.IsNotification() {
+			return common.Response{
+				Status:  http.StatusNoContent,
+				Headers: map[string][]string{},
+			}
 		}
 
-		scope := actions.NewScope(ctx, action, schema)
-
-		response, meta, err := actions.Execute(scope, inputs)
 		if err != nil {
-			return NewErrorResponse(ctx, &req.ID, err)
+			return NewErrorResponse(ctx, req.ID, err)
 		}
 
-		return NewSuccessResponse(ctx, req.ID, response, meta)
+		return NewSuccessResponse(ctx, *req.ID, response, meta)
+	}
+}
+
+// execute runs the action for the given request.
+func execute(ctx context.Context, schema *proto.Schema, api *proto.Api, req *JsonRpcRequest) (any, *common.ResponseMetadata, error) {
+	action := schema.FindAction(req.Method)
+	if action == nil {
+		return nil, nil, common.NewMethodNotFoundError()
+	}
+
+	err := ratelimit.Check(ctx, api, action)
+	if err != nil {
+		return nil, nil, err
 	}
+
+	scope := actions.NewScope(ctx, action, schema)
+
+	return actions.Execute(scope, req.Params)
 }
 
 type JsonRpcRequest struct {
 	JsonRpc string         `json:"jsonrpc"`
-	ID      string         `json:"id"`
+	ID      *string        `json:"id"`
 	Method  string         `json:"method"`
 	Params  map[string]any `json:"params"`
 }
 
 func (r JsonRpcRequest) Valid() bool {
-	return r.Method != "" && r.ID != "" && r.JsonRpc == "2.0"
+	return r.Method != "" && (r.ID == nil || *r.ID != "") && r.JsonRpc == "2.0"
+}
+
+// IsNotification is true when the request has no id, in which case the client
+// does not expect a response.
+func (r JsonRpcRequest) IsNotification() bool {
+	return r.ID == nil
 }
 
 type JsonRpcSuccessResponse struct {
@@ -130,6 +166,17 @@ func NewSuccessResponse(ctx context.Context, requestId string, response any, met
 }
 
 func NewErrorResponse(ctx context.Context, requestId *string, err error) common.Response {
+	var rateLimitErr common.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return common.NewJsonResponse(http.StatusTooManyRequests, newErrorResponseBody(ctx, requestId, err), &common.ResponseMetadata{
+			Headers: common.RetryAfterHeaders(err),
+		})
+	}
+
+	return common.NewJsonResponse(http.StatusOK, newErrorResponseBody(ctx, requestId, err), nil)
+}
+
+func newErrorResponseBody(ctx context.Context, requestId *string, err error) JsonRpcErrorResponse {
 	span := trace.SpanFromContext(ctx)
 
 	var response JsonRpcError
@@ -156,19 +203,47 @@ func NewErrorResponse(ctx context.Context, requestId *string, err error) common.
 	span.RecordError(err, trace.WithStackTrace(true))
 	span.SetStatus(codes.Error, err.Error())
 
-	return common.NewJsonResponse(http.StatusOK, JsonRpcErrorResponse{
+	return JsonRpcErrorResponse{
 		JsonRpc: "2.0",
 		ID:      requestId,
 		Error:   response,
-	}, nil)
+	}
 }
 
-func parseJsonRpcRequest(b io.ReadCloser) (req *JsonRpcRequest, err error) {
-	body, err := io.ReadAll(b)
-	if err != nil {
-		return nil, err
+// IsReadOnlyRequest returns true if the request, or every request in a batch, calls an action which only reads data.
+func IsReadOnlyRequest(schema *proto.Schema, body []byte) bool {
+	requests := []*JsonRpcRequest{}
+	if isBatch(body) {
+		if err := json.Unmarshal(body, &requests); err != nil {
+			return false
+		}
+	} else {
+		req, err := parseJsonRpcRequest(body)
+		if err != nil {
+			return false
+		}
+		requests = append(requests, req)
+	}
+
+	if len(requests) == 0 {
+		return false
 	}
 
+	for _, req := range requests {
+		if req == nil {
+			return false
+		}
+
+		action := schema.FindAction(req.Method)
+		if action == nil || !action.IsReadAction() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseJsonRpcRequest(body []byte) (req *JsonRpcRequest, err error) {
 	req = &JsonRpcRequest{}
 	err = json.Unmarshal(body, req)
 	return req, err
@@ -186,6 +261,10 @@ func runtimeErrorCodeToJsonRpcErrorCode(code string) int {
 		return JsonRpcMethodNotFoundCode
 	case common.ErrInputMalformed:
 		return JsonRpcInvalidRequestCode
+	case common.ErrRateLimited:
+		return JsonRpcRateLimited
+	case common.ErrConflict:
+		return JsonRpcConflict
 	default:
 		return JsonRpcInternalErrorCode
 	}
@@ -0,0 +1,256 @@
+package jsonrpc_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/apis/jsonrpc"
+	"github.com/teamkeel/keel/schema"
+	"github.com/teamkeel/keel/schema/reader"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+const testSchema = `
+model Post {
+	fields {
+		title Text
+	}
+
+	actions {
+		get getPost(id)
+		create createPost() with (title)
+		update updatePost(id) with (title)
+		write writePost(Any) returns (Any)
+	}
+
+	@permission(expression: true, actions: [get, create, update])
+}
+
+api Test {
+	models {
+		Post
+	}
+}`
+
+func newRequest(body string, headers http.Header) *http.Request {
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	return &http.Request{
+		URL: &url.URL{
+			Path: "/test/rpc",
+		},
+		Method: http.MethodPost,
+		Header: headers,
+		Body:   io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func newHandler(t *testing.T) func(r *http.Request) (int, []byte) {
+	builder := schema.Builder{}
+	protoSchema, err := builder.MakeFromInputs(&reader.Inputs{
+		SchemaFiles: []*reader.SchemaFile{
+			{
+				Contents: testSchema,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	handler := jsonrpc.NewHandler(protoSchema, proto.FindApi(protoSchema, "Test"))
+
+	return func(r *http.Request) (int, []byte) {
+		response := handler(r)
+		return response.Status, response.Body
+	}
+}
+
+func TestBatchRequest(t *testing.T) {
+	handler := newHandler(t)
+
+	status, body := handler(newRequest(`[
+		{"jsonrpc": "2.0", "id": "1", "method": "listPosts", "params": {}},
+		{"jsonrpc": "1.0", "id": "2", "method": "getPost", "params": {}},
+		{"jsonrpc": "2.0", "method": "listPosts", "params": {}},
+		"not a request"
+	]`, nil))
+
+	require.Equal(t, http.StatusOK, status)
+
+	var responses []map[string]any
+	require.NoError(t, json.Unmarshal(body, &responses))
+	require.Len(t, responses, 3)
+
+	require.Equal(t, "1", responses[0]["id"])
+	require.Equal(t, float64(jsonrpc.JsonRpcMethodNotFoundCode), responses[0]["error"].(map[string]any)["code"])
+
+	require.Equal(t, "2", responses[1]["id"])
+	require.Equal(t, float64(jsonrpc.JsonRpcInvalidRequestCode), responses[1]["error"].(map[string]any)["code"])
+
+	require.Nil(t, responses[2]["id"])
+	require.Equal(t, float64(jsonrpc.JsonRpcInvalidRequestCode), responses[2]["error"].(map[string]any)["code"])
+}
+
+func TestEmptyBatchRequest(t *testing.T) {
+	handler := newHandler(t)
+
+	status, body := handler(newRequest(`[]`, nil))
+	require.Equal(t, http.StatusOK, status)
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(body, &response))
+	require.Nil(t, response["id"])
+	require.Equal(t, float64(jsonrpc.JsonRpcInvalidRequestCode), response["error"].(map[string]any)["code"])
+}
+
+func TestBatchOfNotifications(t *testing.T) {
+	handler := newHandler(t)
+
+	status, body := handler(newRequest(`[
+		{"jsonrpc": "2.0", "method": "listPosts", "params": {}},
+		{"jsonrpc": "2.0", "method": "listPosts", "params": {}}
+	]`, nil))
+
+	require.Equal(t, http.StatusNoContent, status)
+	require.Empty(t, body)
+}
+
+func TestNotification(t *testing.T) {
+	handler := newHandler(t)
+
+	status, body := handler(newRequest(`{"jsonrpc": "2.0", "method": "listPosts", "params": {}}`, nil))
+
+	require.Equal(t, http.StatusNoContent, status)
+	require.Empty(t, body)
+}
+
+func TestRequestWithEmptyId(t *testing.T) {
+	handler := newHandler(t)
+
+	status, body := handler(newRequest(`{"jsonrpc": "2.0", "id": "", "method": "getPost", "params": {}}`, nil))
+	require.Equal(t, http.StatusOK, status)
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(body, &response))
+	require.Equal(t, float64(jsonrpc.JsonRpcInvalidRequestCode), response["error"].(map[string]any)["code"])
+}
+
+func TestBatchTransactionRequiresDatabase(t *testing.T) {
+	handler := newHandler(t)
+
+	headers := http.Header{}
+	headers.Set(jsonrpc.BatchTransactionHeader, "true")
+
+	status, _ := handler(newRequest(`[
+		{"jsonrpc": "2.0", "id": "1", "method": "getPost", "params": {"id": "123"}}
+	]`, headers))
+
+	require.Equal(t, http.StatusInternalServerError, status)
+}
+
+func TestBatchTooLarge(t *testing.T) {
+	handler := newHandler(t)
+
+	requests := make([]string, jsonrpc.MaxBatchSize+1)
+	for i := range requests {
+		requests[i] = fmt.Sprintf(`{"jsonrpc": "2.0", "id": "%d", "method": "getPost", "params": {"id": "123"}}`, i)
+	}
+
+	status, body := handler(newRequest("["+strings.Join(requests, ",")+"]", nil))
+	require.Equal(t, http.StatusOK, status)
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(body, &response))
+	require.Nil(t, response["id"])
+	require.Equal(t, float64(jsonrpc.JsonRpcInvalidRequestCode), response["error"].(map[string]any)["code"])
+	require.Equal(t, "a batch cannot contain more than 100 requests", response["error"].(map[string]any)["message"])
+}
+
+func TestBatchTransactionRejectsFunctions(t *testing.T) {
+	handler := newHandler(t)
+
+	headers := http.Header{}
+	headers.Set(jsonrpc.BatchTransactionHeader, "true")
+
+	status, body := handler(newRequest(`[
+		{"jsonrpc": "2.0", "id": "1", "method": "createPost", "params": {"title": "a"}},
+		{"jsonrpc": "2.0", "id": "2", "method": "writePost", "params": {}}
+	]`, headers))
+	require.Equal(t, http.StatusOK, status)
+
+	var responses []map[string]any
+	require.NoError(t, json.Unmarshal(body, &responses))
+	require.Len(t, responses, 2)
+
+	require.Equal(t, float64(jsonrpc.JsonRpcTransactionRolledBack), responses[0]["error"].(map[string]any)["code"])
+	require.Equal(t, "not executed as transaction was rolled back", responses[0]["error"].(map[string]any)["message"])
+
+	require.Equal(t, float64(jsonrpc.JsonRpcInvalidRequestCode), responses[1]["error"].(map[string]any)["code"])
+	require.Equal(t, "functions cannot be executed in a batch transaction", responses[1]["error"].(map[string]any)["message"])
+}
+
+func TestBatchTransactionRolledBack(t *testing.T) {
+	ctx, database, protoSchema := keeltesting.MakeContext(t, t.Context(), testSchema, true)
+	defer database.Close()
+
+	handler := jsonrpc.NewHandler(protoSchema, proto.FindApi(protoSchema, "Test"))
+
+	headers := http.Header{}
+	headers.Set(jsonrpc.BatchTransactionHeader, "true")
+
+	response := handler(newRequest(`[
+		{"jsonrpc": "2.0", "id": "1", "method": "createPost", "params": {"title": "a"}},
+		{"jsonrpc": "2.0", "id": "2", "method": "createPost", "params": {"title": "b"}},
+		{"jsonrpc": "2.0", "id": "3", "method": "updatePost", "params": {"where": {"id": "unknown"}, "values": {"title": "c"}}},
+		{"jsonrpc": "2.0", "id": "4", "method": "createPost", "params": {"title": "d"}}
+	]`, headers).WithContext(ctx))
+	require.Equal(t, http.StatusOK, response.Status)
+
+	var responses []map[string]any
+	require.NoError(t, json.Unmarshal(response.Body, &responses))
+	require.Len(t, responses, 4)
+
+	require.Equal(t, "transaction was rolled back", responses[0]["error"].(map[string]any)["message"])
+	require.Equal(t, "transaction was rolled back", responses[1]["error"].(map[string]any)["message"])
+	require.Equal(t, float64(jsonrpc.JsonRpcInvalidParams), responses[2]["error"].(map[string]any)["code"])
+	require.Equal(t, "not executed as transaction was rolled back", responses[3]["error"].(map[string]any)["message"])
+
+	// The posts created before the failed request were rolled back
+	var count int
+	require.NoError(t, database.GetDB().Raw("SELECT COUNT(*) FROM post").Scan(&count).Error)
+	require.Equal(t, 0, count)
+}
+
+func TestBatchWithoutTransactionNotRolledBack(t *testing.T) {
+	ctx, database, protoSchema := keeltesting.MakeContext(t, t.Context(), testSchema, true)
+	defer database.Close()
+
+	handler := jsonrpc.NewHandler(protoSchema, proto.FindApi(protoSchema, "Test"))
+
+	response := handler(newRequest(`[
+		{"jsonrpc": "2.0", "id": "1", "method": "createPost", "params": {"title": "a"}},
+		{"jsonrpc": "2.0", "id": "2", "method": "updatePost", "params": {"where": {"id": "unknown"}, "values": {"title": "c"}}},
+		{"jsonrpc": "2.0", "id": "3", "method": "createPost", "params": {"title": "b"}}
+	]`, nil).WithContext(ctx))
+	require.Equal(t, http.StatusOK, response.Status)
+
+	var responses []map[string]any
+	require.NoError(t, json.Unmarshal(response.Body, &responses))
+	require.Len(t, responses, 3)
+
+	require.Equal(t, "a", responses[0]["result"].(map[string]any)["title"])
+	require.Equal(t, float64(jsonrpc.JsonRpcInvalidParams), responses[1]["error"].(map[string]any)["code"])
+	require.Equal(t, "b", responses[2]["result"].(map[string]any)["title"])
+
+	var count int
+	require.NoError(t, database.GetDB().Raw("SELECT COUNT(*) FROM post").Scan(&count).Error)
+	require.Equal(t, 2, count)
+}
@@ -36,7 +36,7 @@ func TestUnassignTask_Success(t *testing.T) {
 	identityID := identity["id"].(string)
 
 	// Generate access token
-	accessToken, _, err := oauth.GenerateAccessToken(ctx, identityID)
+	accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, identityID, "")
 	require.NoError(t, err)
 
 	// Get the task definition from schema
@@ -75,7 +75,7 @@ func TestUnassignTask_CannotUnassignCompletedTask(t *testing.T) {
 	identityID := identity["id"].(string)
 
 	// Generate access token
-	accessToken, _, err := oauth.GenerateAccessToken(ctx, identityID)
+	accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, identityID, "")
 	require.NoError(t, err)
 
 	// Get the task definition from schema
@@ -109,7 +109,7 @@ func TestUnassignTask_CannotUnassignCancelledTask(t *testing.T) {
 	identityID := identity["id"].(string)
 
 	// Generate access token
-	accessToken, _, err := oauth.GenerateAccessToken(ctx, identityID)
+	accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, identityID, "")
 	require.NoError(t, err)
 
 	// Get the task definition from schema
@@ -143,7 +143,7 @@ func TestUnassignTask_TaskNotFound(t *testing.T) {
 	identityID := identity["id"].(string)
 
 	// Generate access token
-	accessToken, _, err := oauth.GenerateAccessToken(ctx, identityID)
+	accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, identityID, "")
 	require.NoError(t, err)
 
 	// Try to unassign a non-existent task
@@ -163,7 +163,7 @@ func TestUnassignTask_UnassignNewTask(t *testing.T) {
 	identityID := identity["id"].(string)
 
 	// Generate access token
-	accessToken, _, err := oauth.GenerateAccessToken(ctx, identityID)
+	accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, identityID, "")
 	require.NoError(t, err)
 
 	// Get the task definition from schema
@@ -193,7 +193,7 @@ func TestUnassignTask_HttpMethodNotAllowed(t *testing.T) {
 	identityID := identity["id"].(string)
 
 	// Generate access token
-	accessToken, _, err := oauth.GenerateAccessToken(ctx, identityID)
+	accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, identityID, "")
 	require.NoError(t, err)
 
 	// Make GET request instead of PUT
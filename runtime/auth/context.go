@@ -8,7 +8,8 @@ import (
 type contextKey string
 
 const (
-	identityContextKey contextKey = "identityId"
+	identityContextKey       contextKey = "identityId"
+	serviceAccountContextKey contextKey = "serviceAccount"
 )
 
 type Identity map[string]any
@@ -32,3 +33,41 @@ func GetIdentity(ctx context.Context) (Identity, error) {
 func IsAuthenticated(ctx context.Context) bool {
 	return ctx.Value(identityContextKey) != nil
 }
+
+// ServiceAccount is a non-human client, such as a backend integration, which has authenticated
+// with the client_credentials grant rather than as an identity.
+type ServiceAccount struct {
+	Id     string   `json:"id"`
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+func WithServiceAccount(ctx context.Context, account *ServiceAccount) context.Context {
+	if account != nil {
+		ctx = context.WithValue(ctx, serviceAccountContextKey, account)
+	}
+
+	return ctx
+}
+
+func GetServiceAccount(ctx context.Context) (*ServiceAccount, error) {
+	v, ok := ctx.Value(serviceAccountContextKey).(*ServiceAccount)
+	if !ok {
+		return nil, fmt.Errorf("context does not have a key or is not ServiceAccount: %s", serviceAccountContextKey)
+	}
+	return v, nil
+}
+
+func IsServiceAccount(ctx context.Context) bool {
+	return ctx.Value(serviceAccountContextKey) != nil
+}
+
+// GetScopes returns the scopes granted to the service account making the request, or
+// an empty slice if the request was not made by a service account.
+func GetScopes(ctx context.Context) []string {
+	account, err := GetServiceAccount(ctx)
+	if err != nil {
+		return []string{}
+	}
+	return account.Scopes
+}
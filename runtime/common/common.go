@@ -3,12 +3,16 @@ package common
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/samber/lo"
 	"github.com/teamkeel/graphql/gqlerrors"
@@ -99,6 +103,10 @@ const (
 	ErrHttpMethodNotAllowed = "ERR_HTTP_METHOD_NOT_ALLOWED"
 	// An unexpected error happened from user code.
 	ErrUnknown = "ERR_UNKNOWN"
+	// Too many requests have been made and a rate limit has been exceeded.
+	ErrRateLimited = "ERR_RATE_LIMITED"
+	// The request conflicts with the current state of the data, for example deleting a record which is still referenced.
+	ErrConflict = "ERR_CONFLICT"
 )
 
 type PermissionStatus string
@@ -220,6 +228,17 @@ func NewForeignKeyConstraintError(column string) RuntimeError {
 	}
 }
 
+func NewRestrictedDeleteError(table string) RuntimeError {
+	// Parses from the database casing back to the schema casing.
+	// Important since these error messages are delivered to the user.
+	entity := casing.ToCamel(table)
+
+	return RuntimeError{
+		Code:    ErrConflict,
+		Message: fmt.Sprintf("the record cannot be deleted as it is still referenced by %s", entity),
+	}
+}
+
 func NewPermissionError() RuntimeError {
 	return RuntimeError{
 		Code:    ErrPermissionDenied,
@@ -241,6 +260,45 @@ func NewAuthenticationFailedMessageErr(message string) RuntimeError {
 	}
 }
 
+// RateLimitError is returned when a request exceeds a rate limit, and
+// carries how long the client should wait before trying again.
+type RateLimitError struct {
+	RuntimeError
+	RetryAfter time.Duration
+}
+
+func (e RateLimitError) Unwrap() error {
+	return e.RuntimeError
+}
+
+func NewRateLimitError(retryAfter time.Duration) RateLimitError {
+	return RateLimitError{
+		RuntimeError: RuntimeError{
+			Code:    ErrRateLimited,
+			Message: "too many requests",
+		},
+		RetryAfter: retryAfter,
+	}
+}
+
+// RetryAfterHeaders returns the Retry-After header to send with the response
+// if the error is a rate limit error.
+func RetryAfterHeaders(err error) map[string][]string {
+	var rateLimitErr RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		return map[string][]string{}
+	}
+
+	seconds := int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return map[string][]string{
+		"Retry-After": {strconv.Itoa(seconds)},
+	}
+}
+
 // ParseQueryParams will parse the parmeters in the request query string.
 func ParseQueryParams(r *http.Request) map[string]any {
 	q := r.URL.Query()
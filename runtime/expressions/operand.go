@@ -53,6 +53,22 @@ func IsContextIsAuthenticatedField(fragments []string) bool {
 	return false
 }
 
+func IsContextIsServiceAccountField(fragments []string) bool {
+	if IsContext(fragments) && len(fragments) == 2 {
+		return fragments[1] == "isServiceAccount"
+	}
+
+	return false
+}
+
+func IsContextScopesField(fragments []string) bool {
+	if IsContext(fragments) && len(fragments) == 2 {
+		return fragments[1] == "scopes"
+	}
+
+	return false
+}
+
 func IsContextField(fragments []string) bool {
 	return IsContext(fragments) && !IsContextDbColumn(fragments)
 }
@@ -0,0 +1,276 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/auth"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/schema/parser"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var tracer = otel.Tracer("github.com/teamkeel/keel/runtime/idempotency")
+
+const (
+	// HeaderName is the request header which a client sets to a unique value, such as a UUID,
+	// to make retries of the same request safe.
+	HeaderName = "Idempotency-Key"
+	// ReplayedHeaderName is set on responses which have been replayed from an earlier request.
+	ReplayedHeaderName = "Idempotent-Replayed"
+	// TableName is the keel-managed table in which keys and their responses are stored.
+	TableName = "keel_idempotency_key"
+)
+
+const (
+	// A request with the same key is still being executed.
+	ErrRequestInProgress = "ERR_IDEMPOTENCY_KEY_IN_PROGRESS"
+	// The key has already been used for a request with a different payload.
+	ErrRequestMismatch = "ERR_IDEMPOTENCY_KEY_MISMATCH"
+)
+
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// sweepInterval is how often each instance of the runtime clears out keys which have outlived the window.
+const sweepInterval = time.Minute
+
+// lastSweep is when this instance of the runtime last cleared out expired keys, in Unix nanoseconds.
+var lastSweep atomic.Int64
+
+// Handler wraps an API handler so that POST requests carrying an Idempotency-Key header are only
+// executed once. The key is stored along with a hash of the request and the response, and a repeat
+// of the request within the configured window is given the stored response rather than being executed
+// again. Reusing a key for a different request is rejected, as is repeating a request whilst the first
+// is still being executed.
+//
+// Keys are scoped to the identity or service account making the request, so one caller can never be
+// given the response to another's request. Requests for which readOnly returns true only read data and
+// so are safe to repeat without a key.
+//
+// Responses with a server error or rate limited status are not stored, so that those requests can be retried.
+func Handler(schema *proto.Schema, next common.HandlerFunc, readOnly func(r *http.Request, body []byte) bool) common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		key := r.Header.Get(HeaderName)
+		if key == "" || r.Method != http.MethodPost {
+			return next(r)
+		}
+
+		ctx, span := tracer.Start(r.Context(), "Idempotency Key")
+		defer span.End()
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if readOnly != nil && readOnly(r, body) {
+			return next(r)
+		}
+
+		// Authentication errors are left for the API handler to respond with
+		authCtx, err := actions.HandleAuthorization(ctx, schema, r.Header)
+		if err != nil {
+			return next(r)
+		}
+
+		key, err = scopedKey(authCtx, key)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		hash := requestHash(r, body)
+
+		database, err := db.GetDatabase(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		config, err := runtimectx.GetIdempotencyConfig(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		now := runtimectx.GetNow()
+
+		err = sweep(ctx, database, now, config.IdempotencyWindow())
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		// A key which has outlived the window is reclaimed, as though it had been swept already
+		claimed, err := database.ExecuteStatement(ctx, fmt.Sprintf(`
+			INSERT INTO %s (key, request_hash, created_at) VALUES (?, ?, ?)
+			ON CONFLICT (key) DO UPDATE SET request_hash = EXCLUDED.request_hash, response_status = NULL, response_headers = NULL, response_body = NULL, created_at = EXCLUDED.created_at
+			WHERE %[1]s.created_at < ?`, TableName), key, hash, now, now.Add(-config.IdempotencyWindow()))
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		if claimed.RowsAffected == 1 {
+			span.SetAttributes(attribute.Bool("idempotency.replayed", false))
+
+			response := execute(ctx, database, key, next, r.WithContext(ctx))
+
+			err = store(ctx, database, key, response)
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			return response
+		}
+
+		result, err := database.ExecuteQuery(ctx, fmt.Sprintf("SELECT request_hash, response_status, response_headers, response_body FROM %s WHERE key = ?", TableName), key)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		// The key was removed between trying to claim it and reading it back, which only happens if the
+		// request which had claimed it failed with a server error or was rate limited, so it is safe to execute
+		// this one instead
+		if len(result.Rows) == 0 {
+			return next(r.WithContext(ctx))
+		}
+
+		row := result.Rows[0]
+
+		if row["request_hash"] != hash {
+			return common.NewJsonResponse(http.StatusUnprocessableEntity, errorResponse{
+				Code:    ErrRequestMismatch,
+				Message: fmt.Sprintf("the %s header has already been used for a different request", HeaderName),
+			}, nil)
+		}
+
+		if row["response_status"] == nil {
+			return common.NewJsonResponse(http.StatusConflict, errorResponse{
+				Code:    ErrRequestInProgress,
+				Message: fmt.Sprintf("a request with this %s is still being processed", HeaderName),
+			}, nil)
+		}
+
+		span.SetAttributes(attribute.Bool("idempotency.replayed", true))
+
+		return replay(row)
+	}
+}
+
+// execute runs the request for the claimed key. If the handler panics then the key is released before the
+// panic continues, otherwise it would be left claimed and every retry rejected as in progress.
+func execute(ctx context.Context, database db.Database, key string, next common.HandlerFunc, r *http.Request) common.Response {
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = database.ExecuteStatement(context.WithoutCancel(ctx), fmt.Sprintf("DELETE FROM %s WHERE key = ?", TableName), key)
+			panic(p)
+		}
+	}()
+
+	return next(r)
+}
+
+// scopedKey prefixes the key with the identity or service account making the request.
+func scopedKey(ctx context.Context, key string) (string, error) {
+	if auth.IsAuthenticated(ctx) {
+		identity, err := auth.GetIdentity(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("identity:%s:%s", identity[parser.FieldNameId], key), nil
+	}
+
+	if auth.IsServiceAccount(ctx) {
+		account, err := auth.GetServiceAccount(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("service_account:%s:%s", account.Id, key), nil
+	}
+
+	return fmt.Sprintf("anonymous:%s", key), nil
+}
+
+// sweep deletes the keys which have outlived the window. It runs at most once every sweepInterval on each
+// instance of the runtime, rather than on every request, so that requests are not all contending to delete
+// from the same table.
+func sweep(ctx context.Context, database db.Database, now time.Time, window time.Duration) error {
+	last := lastSweep.Load()
+	if now.UnixNano()-last < int64(sweepInterval) || !lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return nil
+	}
+
+	_, err := database.ExecuteStatement(ctx, fmt.Sprintf("DELETE FROM %s WHERE created_at < ?", TableName), now.Add(-window))
+	return err
+}
+
+// store saves the response for the claimed key, or releases the key if the request failed with a server error
+// or was rate limited, as those requests can succeed when retried.
+func store(ctx context.Context, database db.Database, key string, response common.Response) error {
+	if response.Status >= http.StatusInternalServerError || response.Status == http.StatusTooManyRequests {
+		_, err := database.ExecuteStatement(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = ?", TableName), key)
+		return err
+	}
+
+	headers, err := json.Marshal(response.Headers)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.ExecuteStatement(ctx, fmt.Sprintf("UPDATE %s SET response_status = ?, response_headers = ?, response_body = ? WHERE key = ?", TableName), response.Status, string(headers), string(response.Body), key)
+	return err
+}
+
+// replay builds the response stored for a key.
+func replay(row map[string]any) common.Response {
+	response := common.Response{
+		Headers: map[string][]string{},
+	}
+
+	switch status := row["response_status"].(type) {
+	case int32:
+		response.Status = int(status)
+	case int64:
+		response.Status = int(status)
+	}
+
+	if headers, ok := row["response_headers"].(string); ok && headers != "" {
+		_ = json.Unmarshal([]byte(headers), &response.Headers)
+		if response.Headers == nil {
+			response.Headers = map[string][]string{}
+		}
+	}
+
+	if body, ok := row["response_body"].(string); ok {
+		response.Body = []byte(body)
+	}
+
+	response.Headers[ReplayedHeaderName] = []string{"true"}
+
+	return response
+}
+
+// requestHash identifies the request which a key was first used for, so that it can
+// be compared against any repeats.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,166 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/schema"
+	"github.com/teamkeel/keel/schema/reader"
+	"github.com/teamkeel/keel/testhelpers"
+)
+
+var testSchema = `
+model Order {
+	fields {
+		reference Text
+	}
+	actions {
+		create createOrder() with (reference)
+	}
+	@permission(expression: true, actions: [create])
+}`
+
+// makeContext sets up a database for the test. The testing package cannot be used as it depends on this one.
+func makeContext(t *testing.T) (context.Context, db.Database, *proto.Schema) {
+	builder := &schema.Builder{Config: &config.ProjectConfig{}}
+	s, err := builder.MakeFromInputs(&reader.Inputs{
+		SchemaFiles: []*reader.SchemaFile{{Contents: testSchema, FileName: "schema.keel"}},
+	})
+	require.NoError(t, err)
+
+	ctx := t.Context()
+
+	dbConnInfo := &db.ConnectionInfo{
+		Host:     "localhost",
+		Port:     "8001",
+		Username: "postgres",
+		Password: "postgres",
+		Database: "keel",
+	}
+
+	database, err := testhelpers.SetupDatabaseForTestCase(ctx, dbConnInfo, s, strings.ToLower("keel_test_"+t.Name()), true)
+	require.NoError(t, err)
+
+	return db.WithDatabase(ctx, database), database, s
+}
+
+func newRequest(ctx context.Context, key string) *http.Request {
+	request := &http.Request{
+		URL:    &url.URL{Path: "/api/json/createOrder"},
+		Method: http.MethodPost,
+		Body:   io.NopCloser(strings.NewReader(`{"reference": "123"}`)),
+		Header: http.Header{},
+	}
+	request.Header.Set(HeaderName, key)
+	return request.WithContext(ctx)
+}
+
+func countKeys(t *testing.T, database db.Database) int64 {
+	var count int64
+	require.NoError(t, database.GetDB().Raw(fmt.Sprintf("SELECT count(*) FROM %s", TableName)).Scan(&count).Error)
+	return count
+}
+
+func TestRequestInProgress(t *testing.T) {
+	ctx, database, schema := makeContext(t)
+	defer database.Close()
+
+	var handler common.HandlerFunc
+	var inProgress common.Response
+	handler = Handler(schema, func(r *http.Request) common.Response {
+		// The same request is repeated whilst the first is still being executed
+		inProgress = handler(newRequest(ctx, "abc"))
+		return common.Response{Status: http.StatusOK}
+	}, nil)
+
+	response := handler(newRequest(ctx, "abc"))
+	require.Equal(t, http.StatusOK, response.Status)
+
+	require.Equal(t, http.StatusConflict, inProgress.Status)
+	require.Contains(t, string(inProgress.Body), ErrRequestInProgress)
+	require.Empty(t, inProgress.Headers[ReplayedHeaderName])
+}
+
+func TestKeyReleasedAfterPanic(t *testing.T) {
+	ctx, database, schema := makeContext(t)
+	defer database.Close()
+
+	require.Panics(t, func() {
+		Handler(schema, func(r *http.Request) common.Response {
+			panic("boom")
+		}, nil)(newRequest(ctx, "abc"))
+	})
+
+	require.Equal(t, int64(0), countKeys(t, database))
+
+	// The retry is executed rather than being rejected as in progress
+	executed := 0
+	response := Handler(schema, func(r *http.Request) common.Response {
+		executed++
+		return common.Response{Status: http.StatusOK}
+	}, nil)(newRequest(ctx, "abc"))
+
+	require.Equal(t, http.StatusOK, response.Status)
+	require.Empty(t, response.Headers[ReplayedHeaderName])
+	require.Equal(t, 1, executed)
+}
+
+func TestSkipsReadOnlyRequests(t *testing.T) {
+	ctx, database, schema := makeContext(t)
+	defer database.Close()
+
+	executed := 0
+	handler := Handler(schema, func(r *http.Request) common.Response {
+		executed++
+		return common.Response{Status: http.StatusOK}
+	}, func(r *http.Request, body []byte) bool {
+		return true
+	})
+
+	handler(newRequest(ctx, "abc"))
+	handler(newRequest(ctx, "abc"))
+
+	require.Equal(t, 2, executed)
+	require.Equal(t, int64(0), countKeys(t, database))
+}
+
+func TestSweep(t *testing.T) {
+	ctx, database, _ := makeContext(t)
+	defer database.Close()
+
+	insert := func(key string, createdAt time.Time) {
+		_, err := database.ExecuteStatement(ctx, fmt.Sprintf("INSERT INTO %s (key, request_hash, created_at) VALUES (?, ?, ?)", TableName), key, "hash", createdAt)
+		require.NoError(t, err)
+	}
+
+	now := time.Now().UTC()
+	lastSweep.Store(0)
+
+	insert("anonymous:expired", now.Add(-2*time.Hour))
+	insert("anonymous:current", now.Add(-time.Minute))
+
+	require.NoError(t, sweep(ctx, database, now, time.Hour))
+
+	var keys []string
+	require.NoError(t, database.GetDB().Raw(fmt.Sprintf("SELECT key FROM %s ORDER BY key", TableName)).Scan(&keys).Error)
+	require.Equal(t, []string{"anonymous:current"}, keys)
+
+	// Sweeps are not repeated within the interval
+	insert("anonymous:expired", now.Add(-2*time.Hour))
+	require.NoError(t, sweep(ctx, database, now.Add(sweepInterval/2), time.Hour))
+	require.Equal(t, int64(2), countKeys(t, database))
+
+	require.NoError(t, sweep(ctx, database, now.Add(sweepInterval), time.Hour))
+	require.Equal(t, int64(1), countKeys(t, database))
+}
@@ -71,7 +71,8 @@ type JSONSchema struct {
 	Default               string                `json:"default,omitempty"`
 
 	// For arrays
-	Items *JSONSchema `json:"items,omitempty"`
+	Items    *JSONSchema `json:"items,omitempty"`
+	MaxItems *int        `json:"maxItems,omitempty"`
 
 	// Used to link to a type defined in the root $defs
 	Ref string `json:"$ref,omitempty"`
@@ -110,7 +111,17 @@ func ValidateResponse(ctx context.Context, schema *proto.Schema, action *proto.A
 
 func JSONSchemaForActionInput(ctx context.Context, schema *proto.Schema, action *proto.Action) JSONSchema {
 	inputMessage := schema.FindMessage(action.GetInputMessageName())
-	return JSONSchemaForMessage(ctx, schema, action, inputMessage, true)
+	inputSchema := JSONSchemaForMessage(ctx, schema, action, inputMessage, true)
+
+	// The number of items a bulk action can write in one request is limited
+	if action.IsBulk() {
+		if items, ok := inputSchema.Properties["items"]; ok {
+			items.MaxItems = lo.ToPtr(parser.MaxBulkItems)
+			inputSchema.Properties["items"] = items
+		}
+	}
+
+	return inputSchema
 }
 
 func JSONSchemaForActionResponse(ctx context.Context, schema *proto.Schema, action *proto.Action) JSONSchema {
@@ -122,7 +133,7 @@ func JSONSchemaForActionResponse(ctx context.Context, schema *proto.Schema, acti
 
 	// If we've reached this point then we know that we are dealing with built-in actions
 	switch action.GetType() {
-	case proto.ActionType_ACTION_TYPE_CREATE, proto.ActionType_ACTION_TYPE_GET, proto.ActionType_ACTION_TYPE_UPDATE:
+	case proto.ActionType_ACTION_TYPE_CREATE, proto.ActionType_ACTION_TYPE_GET, proto.ActionType_ACTION_TYPE_UPDATE, proto.ActionType_ACTION_TYPE_RESTORE, proto.ActionType_ACTION_TYPE_UPSERT:
 		// these action types return the serialized model
 
 		model := schema.FindModel(action.GetModelName())
@@ -173,6 +184,21 @@ func JSONSchemaForActionResponse(ctx context.Context, schema *proto.Schema, acti
 		return JSONSchema{
 			Type: "string",
 		}
+	case proto.ActionType_ACTION_TYPE_CREATE_MANY, proto.ActionType_ACTION_TYPE_UPDATE_MANY:
+		// array of the serialized models, in the order of the items
+
+		model := schema.FindModel(action.GetModelName())
+
+		return jsonSchemaForModel(ctx, schema, model, true)
+	case proto.ActionType_ACTION_TYPE_DELETE_MANY:
+		// string ids of deleted records, in the order of the items
+
+		return JSONSchema{
+			Type: "array",
+			Items: &JSONSchema{
+				Type: "string",
+			},
+		}
 	default:
 		return JSONSchema{}
 	}
@@ -589,6 +615,9 @@ func jsonSchemaForField(ctx context.Context, schema *proto.Schema, action *proto
 	case proto.Type_TYPE_DECIMAL:
 		prop.Type = "number"
 		prop.Format = "float"
+	case proto.Type_TYPE_VECTOR:
+		prop.Type = "array"
+		prop.Items = &JSONSchema{Type: "number"}
 	case proto.Type_TYPE_ENTITY:
 		model := schema.FindModel(t.GetEntityName().GetValue())
 
@@ -645,7 +674,7 @@ func jsonSchemaForField(ctx context.Context, schema *proto.Schema, action *proto
 		desc := "desc"
 		prop.Enum = []*string{&asc, &desc}
 	case proto.Type_TYPE_FILE:
-		// if the field is used as an input, so the type will be a data-url
+		// if the field is used as an input, so the type will be a data-url (or the key of an uploaded file)
 		if isInput {
 			prop.Type = "string"
 			prop.Format = "data-url"
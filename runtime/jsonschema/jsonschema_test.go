@@ -91,6 +91,36 @@ func TestValidateRequest(t *testing.T) {
 	}
 
 	fixtures := []fixtureGroup{
+		{
+			name: "createMany action",
+			schema: `
+				model Person {
+					fields {
+						name Text
+					}
+					actions {
+						createMany createPeople() with (name)
+					}
+				}
+			`,
+			cases: []fixture{
+				{
+					name:    "valid",
+					request: `{"items": [{"name": "Keelson"}, {"name": "Weaveton"}]}`,
+					opName:  "createPeople",
+				},
+
+				// errors
+				{
+					name:    "too many items",
+					request: `{"items": [` + strings.Repeat(`{"name": "Keelson"},`, 1000) + `{"name": "Weaveton"}]}`,
+					opName:  "createPeople",
+					errors: map[string][]string{
+						"items": {"Array must have at most 1000 items"},
+					},
+				},
+			},
+		},
 		{
 			name: "get action",
 			schema: `
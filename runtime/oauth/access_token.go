@@ -8,27 +8,42 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/samber/lo"
+	"github.com/teamkeel/keel/runtime/auth"
 	"github.com/teamkeel/keel/runtime/common"
 	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/schema/parser"
 )
 
 const (
-	KeelIssuer                          = "https://keel.so"
-	resetPasswordAudClaim               = "password-reset"
-	ResetTokenExpiry      time.Duration = time.Minute * 15
+	KeelIssuer                                 = "https://keel.so"
+	resetPasswordAudClaim                      = "password-reset"
+	ResetTokenExpiry             time.Duration = time.Minute * 15
+	emailVerificationAudClaim                  = "email-verification"
+	EmailVerificationTokenExpiry time.Duration = time.Hour * 24
+	mfaAudClaim                                = "mfa"
+	MfaTokenExpiry               time.Duration = time.Minute * 5
+	uploadAudClaim                             = "file-upload"
 )
 
 var (
-	ErrInvalidToken     = common.NewAuthenticationFailedMessageErr("cannot be parsed or verified as a valid JWT")
-	ErrTokenExpired     = common.NewAuthenticationFailedMessageErr("token has expired")
-	ErrIdentityNotFound = common.NewAuthenticationFailedMessageErr("identity not found")
+	ErrInvalidUploadToken = common.NewInputMalformedError("the file upload key is invalid, has expired or was not issued for this input")
+	ErrInvalidToken       = common.NewAuthenticationFailedMessageErr("cannot be parsed or verified as a valid JWT")
+	ErrTokenExpired       = common.NewAuthenticationFailedMessageErr("token has expired")
+	ErrIdentityNotFound   = common.NewAuthenticationFailedMessageErr("identity not found")
 )
 
 type AccessTokenClaims struct {
 	jwt.RegisteredClaims // https://pkg.go.dev/github.com/golang-jwt/jwt/v4#RegisteredClaims
+	// Space-separated scopes granted to a service account, https://datatracker.ietf.org/doc/html/rfc8693#section-4.2
+	Scope string `json:"scope,omitempty"`
+	// The session in which the token was issued to an identity, so that it is rejected once the session is revoked.
+	SessionId string `json:"sid,omitempty"`
+	// The email an email verification token was sent to, so that it is rejected once the identity's email has changed.
+	Email string `json:"email,omitempty"`
 }
 
-func GenerateAccessToken(ctx context.Context, identityId string) (string, time.Duration, error) {
+// GenerateSessionAccessToken generates an access token for the identity which is only valid for as long as the session is not revoked.
+func GenerateSessionAccessToken(ctx context.Context, identityId string, sessionId string) (string, time.Duration, error) {
 	if identityId == "" {
 		return "", 0, errors.New("cannot generate access token with an empty identityId intended for the sub claim")
 	}
@@ -40,7 +55,10 @@ func GenerateAccessToken(ctx context.Context, identityId string) (string, time.D
 
 	expiry := config.AccessTokenExpiry()
 
-	token, err := generateToken(ctx, identityId, []string{}, expiry)
+	claims := newClaims(identityId, []string{}, expiry)
+	claims.SessionId = sessionId
+
+	token, err := signToken(ctx, claims)
 	if err != nil {
 		return "", 0, err
 	}
@@ -49,7 +67,32 @@ func GenerateAccessToken(ctx context.Context, identityId string) (string, time.D
 }
 
 func ValidateAccessToken(ctx context.Context, tokenString string) (string, error) {
-	return validateToken(ctx, tokenString, "")
+	identityId, _, err := ValidateSessionAccessToken(ctx, tokenString)
+	return identityId, err
+}
+
+// ValidateSessionAccessToken validates the access token and returns the identity and the session in which it was issued,
+// if any. A token issued in a session which has been revoked is rejected, otherwise the session is recorded as used.
+func ValidateSessionAccessToken(ctx context.Context, tokenString string) (identityId string, sessionId string, err error) {
+	claims, err := validateClaims(ctx, tokenString, "")
+	if err != nil {
+		return "", "", err
+	}
+
+	// Access tokens are issued without an audience, whereas the tokens sent for password
+	// resets, email verification and MFA challenges are issued for that purpose alone.
+	if len(claims.Audience) > 0 {
+		return "", "", ErrInvalidToken
+	}
+
+	if claims.SessionId != "" {
+		err = useSession(ctx, claims.SessionId)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return claims.Subject, claims.SessionId, nil
 }
 
 func GenerateResetToken(ctx context.Context, identityId string) (string, error) {
@@ -66,9 +109,126 @@ func ValidateResetToken(ctx context.Context, tokenString string) (string, error)
 	return subject, err
 }
 
+// GenerateEmailVerificationToken generates the token sent to the email of an identity to verify it.
+func GenerateEmailVerificationToken(ctx context.Context, identityId string, email string) (string, error) {
+	if identityId == "" {
+		return "", errors.New("cannot generate email verification token with an empty identityId intended for the sub claim")
+	}
+
+	if email == "" {
+		return "", errors.New("cannot generate email verification token with an empty email")
+	}
+
+	claims := newClaims(identityId, []string{emailVerificationAudClaim}, EmailVerificationTokenExpiry)
+	claims.Email = email
+
+	return signToken(ctx, claims)
+}
+
+// ValidateEmailVerificationToken validates the email verification token and returns the identity and the email it was sent to.
+func ValidateEmailVerificationToken(ctx context.Context, tokenString string) (identityId string, email string, err error) {
+	claims, err := validateClaims(ctx, tokenString, emailVerificationAudClaim)
+	if err != nil {
+		return "", "", err
+	}
+
+	if claims.Email == "" {
+		return "", "", ErrInvalidToken
+	}
+
+	return claims.Subject, claims.Email, nil
+}
+
+func GenerateMfaToken(ctx context.Context, identityId string) (string, error) {
+	if identityId == "" {
+		return "", errors.New("cannot generate mfa token with an empty identityId intended for the sub claim")
+	}
+
+	return generateToken(ctx, identityId, []string{mfaAudClaim}, MfaTokenExpiry)
+}
+
+func ValidateMfaToken(ctx context.Context, tokenString string) (string, error) {
+	return validateToken(ctx, tokenString, mfaAudClaim)
+}
+
+// UploadTokenClaims binds a file uploaded directly to storage to the caller who requested the upload
+// and the action input it was requested for.
+type UploadTokenClaims struct {
+	AccessTokenClaims
+	Principal string `json:"principal,omitempty"`
+	Action    string `json:"action"`
+	Field     string `json:"field"`
+}
+
+// GenerateUploadToken generates the key given to the client for a file uploaded directly to storage. The storage
+// key is signed along with the caller in the context, and the action and File input the upload is for.
+func GenerateUploadToken(ctx context.Context, storageKey string, actionName string, fieldName string, expiresIn time.Duration) (string, error) {
+	if storageKey == "" {
+		return "", errors.New("cannot generate upload token with an empty storage key intended for the sub claim")
+	}
+
+	return signToken(ctx, UploadTokenClaims{
+		AccessTokenClaims: newClaims(storageKey, []string{uploadAudClaim}, expiresIn),
+		Principal:         uploadPrincipal(ctx),
+		Action:            actionName,
+		Field:             fieldName,
+	})
+}
+
+// ValidateUploadToken returns the storage key of the upload if the token was issued to the caller in the context
+// for the given action and File input, otherwise ErrInvalidUploadToken is returned.
+func ValidateUploadToken(ctx context.Context, tokenString string, actionName string, fieldName string) (string, error) {
+	privateKey, err := runtimectx.GetPrivateKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if privateKey == nil {
+		return "", errors.New("no private key set")
+	}
+
+	claims := &UploadTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return &privateKey.PublicKey, nil
+	})
+	if err != nil || !token.Valid {
+		return "", ErrInvalidUploadToken
+	}
+
+	if claims.Subject == "" ||
+		claims.Issuer != KeelIssuer ||
+		!lo.Contains(claims.Audience, uploadAudClaim) ||
+		claims.Principal != uploadPrincipal(ctx) ||
+		claims.Action != actionName ||
+		claims.Field != fieldName {
+		return "", ErrInvalidUploadToken
+	}
+
+	return claims.Subject, nil
+}
+
+// uploadPrincipal identifies the identity or service account making the request, or is empty if the request is unauthenticated.
+func uploadPrincipal(ctx context.Context) string {
+	if identity, err := auth.GetIdentity(ctx); err == nil {
+		if id, ok := identity[parser.FieldNameId].(string); ok {
+			return "identity:" + id
+		}
+	}
+
+	if account, err := auth.GetServiceAccount(ctx); err == nil {
+		return "service_account:" + account.Id
+	}
+
+	return ""
+}
+
 func generateToken(ctx context.Context, sub string, aud []string, expiresIn time.Duration) (string, error) {
+	return signToken(ctx, newClaims(sub, aud, expiresIn))
+}
+
+func newClaims(sub string, aud []string, expiresIn time.Duration) AccessTokenClaims {
 	now := time.Now().UTC()
-	claims := AccessTokenClaims{
+	return AccessTokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   sub,
 			Audience:  aud,
@@ -77,7 +237,9 @@ func generateToken(ctx context.Context, sub string, aud []string, expiresIn time
 			Issuer:    KeelIssuer,
 		},
 	}
+}
 
+func signToken(ctx context.Context, claims jwt.Claims) (string, error) {
 	privateKey, err := runtimectx.GetPrivateKey(ctx)
 	if err != nil {
 		return "", err
@@ -96,16 +258,25 @@ func generateToken(ctx context.Context, sub string, aud []string, expiresIn time
 }
 
 func validateToken(ctx context.Context, tokenString string, audienceClaim string) (string, error) {
+	claims, err := validateClaims(ctx, tokenString, audienceClaim)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.Subject, nil
+}
+
+func validateClaims(ctx context.Context, tokenString string, audienceClaim string) (*AccessTokenClaims, error) {
 	ctx, span := tracer.Start(ctx, "Validate access token")
 	defer span.End()
 
 	privateKey, err := runtimectx.GetPrivateKey(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if privateKey == nil {
-		return "", errors.New("no private key set")
+		return nil, errors.New("no private key set")
 	}
 
 	var token *jwt.Token
@@ -117,34 +288,34 @@ func validateToken(ctx context.Context, tokenString string, audienceClaim string
 
 	var validationErr *jwt.ValidationError
 	if errors.As(err, &validationErr) && validationErr.Errors == jwt.ValidationErrorExpired {
-		return "", ErrTokenExpired
+		return nil, ErrTokenExpired
 	}
 
 	if err != nil {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	if !claims.VerifyExpiresAt(time.Now().UTC(), true) {
-		return "", ErrTokenExpired
+		return nil, ErrTokenExpired
 	}
 
 	if audienceClaim != "" {
 		if !lo.Contains(claims.Audience, audienceClaim) {
-			return "", ErrInvalidToken
+			return nil, ErrInvalidToken
 		}
 	}
 
 	if !token.Valid {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	if claims.Subject == "" {
-		return "", errors.New("subject claim cannot be empty")
+		return nil, errors.New("subject claim cannot be empty")
 	}
 
 	if claims.Issuer != KeelIssuer {
-		return "", errors.New("invalid issuer")
+		return nil, errors.New("invalid issuer")
 	}
 
-	return claims.Subject, nil
+	return claims, nil
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/runtime/auth"
 	"github.com/teamkeel/keel/runtime/oauth"
 	"github.com/teamkeel/keel/runtime/runtimectx"
 	"github.com/teamkeel/keel/testhelpers"
@@ -28,7 +29,7 @@ func TestAccessTokenGeneration(t *testing.T) {
 	ctx := newContextWithPK(t.Context())
 	identityId := ksuid.New()
 
-	bearerJwt, _, err := oauth.GenerateAccessToken(ctx, identityId.String())
+	bearerJwt, _, err := oauth.GenerateSessionAccessToken(ctx, identityId.String(), "")
 	require.NoError(t, err)
 	require.NotEmpty(t, bearerJwt)
 
@@ -41,7 +42,7 @@ func TestAccessTokenValidationNoPrivateKey(t *testing.T) {
 	ctx := newContextWithPK(t.Context())
 	identityId := ksuid.New()
 
-	bearerJwt, _, err := oauth.GenerateAccessToken(ctx, identityId.String())
+	bearerJwt, _, err := oauth.GenerateSessionAccessToken(ctx, identityId.String(), "")
 	require.NoError(t, err)
 	require.NotEmpty(t, bearerJwt)
 
@@ -56,7 +57,7 @@ func TestAccessTokenGenerationAndParsingWithSamePrivateKey(t *testing.T) {
 	ctx := newContextWithPK(t.Context())
 	identityId := ksuid.New()
 
-	bearerJwt, _, err := oauth.GenerateAccessToken(ctx, identityId.String())
+	bearerJwt, _, err := oauth.GenerateSessionAccessToken(ctx, identityId.String(), "")
 	require.NoError(t, err)
 	require.NotEmpty(t, bearerJwt)
 
@@ -74,7 +75,7 @@ func TestAccessTokenValidationDifferentPrivateKey(t *testing.T) {
 	ctx = runtimectx.WithPrivateKey(ctx, privateKey)
 	require.NoError(t, err)
 
-	bearerJwt, _, err := oauth.GenerateAccessToken(ctx, identityId.String())
+	bearerJwt, _, err := oauth.GenerateSessionAccessToken(ctx, identityId.String(), "")
 	require.NoError(t, err)
 	require.NotEmpty(t, bearerJwt)
 
@@ -93,7 +94,7 @@ func TestAccessTokenIsRSAMethodWithPrivateKey(t *testing.T) {
 	ctx = runtimectx.WithPrivateKey(ctx, privateKey)
 	require.NoError(t, err)
 
-	jwtToken, _, err := oauth.GenerateAccessToken(ctx, identityId.String())
+	jwtToken, _, err := oauth.GenerateSessionAccessToken(ctx, identityId.String(), "")
 	require.NoError(t, err)
 	require.NotEmpty(t, jwtToken)
 
@@ -123,7 +124,7 @@ func TestAccessTokenClaims(t *testing.T) {
 	ctx = runtimectx.WithPrivateKey(ctx, privateKey)
 	require.NoError(t, err)
 
-	jwtToken, lifespan, err := oauth.GenerateAccessToken(ctx, identityId.String())
+	jwtToken, lifespan, err := oauth.GenerateSessionAccessToken(ctx, identityId.String(), "")
 	require.NoError(t, err)
 	require.NotEmpty(t, jwtToken)
 
@@ -157,7 +158,7 @@ func TestShortExpiredAccessTokenIsInvalid(t *testing.T) {
 		},
 	})
 
-	bearerJwt, _, err := oauth.GenerateAccessToken(ctx, identityId.String())
+	bearerJwt, _, err := oauth.GenerateSessionAccessToken(ctx, identityId.String(), "")
 	require.NoError(t, err)
 	require.NotEmpty(t, bearerJwt)
 
@@ -355,3 +356,167 @@ func TestResetTokenMissingAudIsInvalid(t *testing.T) {
 	require.ErrorIs(t, oauth.ErrInvalidToken, err)
 	require.Empty(t, parsedId)
 }
+
+func TestEmailVerificationTokenGenerationAndParsingWithSamePrivateKey(t *testing.T) {
+	ctx := newContextWithPK(t.Context())
+	identityId := ksuid.New()
+
+	token, err := oauth.GenerateEmailVerificationToken(ctx, identityId.String(), "keelson@keel.so")
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	parsedId, email, err := oauth.ValidateEmailVerificationToken(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, identityId.String(), parsedId)
+	require.Equal(t, "keelson@keel.so", email)
+}
+
+func TestEmailVerificationTokenIsNotResetToken(t *testing.T) {
+	ctx := newContextWithPK(t.Context())
+	identityId := ksuid.New()
+
+	token, err := oauth.GenerateEmailVerificationToken(ctx, identityId.String(), "keelson@keel.so")
+	require.NoError(t, err)
+
+	parsedId, err := oauth.ValidateResetToken(ctx, token)
+	require.ErrorIs(t, oauth.ErrInvalidToken, err)
+	require.Empty(t, parsedId)
+
+	resetToken, err := oauth.GenerateResetToken(ctx, identityId.String())
+	require.NoError(t, err)
+
+	parsedId, email, err := oauth.ValidateEmailVerificationToken(ctx, resetToken)
+	require.ErrorIs(t, oauth.ErrInvalidToken, err)
+	require.Empty(t, parsedId)
+	require.Empty(t, email)
+}
+
+func TestEmailedTokensAreNotAccessTokens(t *testing.T) {
+	ctx := newContextWithPK(t.Context())
+	identityId := ksuid.New()
+
+	verificationToken, err := oauth.GenerateEmailVerificationToken(ctx, identityId.String(), "keelson@keel.so")
+	require.NoError(t, err)
+
+	parsedId, err := oauth.ValidateAccessToken(ctx, verificationToken)
+	require.ErrorIs(t, oauth.ErrInvalidToken, err)
+	require.Empty(t, parsedId)
+
+	resetToken, err := oauth.GenerateResetToken(ctx, identityId.String())
+	require.NoError(t, err)
+
+	parsedId, err = oauth.ValidateAccessToken(ctx, resetToken)
+	require.ErrorIs(t, oauth.ErrInvalidToken, err)
+	require.Empty(t, parsedId)
+}
+
+func TestMfaTokenGenerationAndParsing(t *testing.T) {
+	ctx := newContextWithPK(t.Context())
+	identityId := ksuid.New()
+
+	token, err := oauth.GenerateMfaToken(ctx, identityId.String())
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	parsedId, err := oauth.ValidateMfaToken(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, identityId.String(), parsedId)
+}
+
+func TestMfaTokenIsNotAccessToken(t *testing.T) {
+	ctx := newContextWithPK(t.Context())
+	identityId := ksuid.New()
+
+	mfaToken, err := oauth.GenerateMfaToken(ctx, identityId.String())
+	require.NoError(t, err)
+
+	parsedId, err := oauth.ValidateAccessToken(ctx, mfaToken)
+	require.ErrorIs(t, oauth.ErrInvalidToken, err)
+	require.Empty(t, parsedId)
+
+	accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, identityId.String(), "")
+	require.NoError(t, err)
+
+	parsedId, err = oauth.ValidateMfaToken(ctx, accessToken)
+	require.ErrorIs(t, oauth.ErrInvalidToken, err)
+	require.Empty(t, parsedId)
+}
+
+func TestSessionAccessTokenHasSessionClaim(t *testing.T) {
+	ctx := newContextWithPK(t.Context())
+	identityId := ksuid.New()
+	sessionId := ksuid.New()
+
+	token, _, err := oauth.GenerateSessionAccessToken(ctx, identityId.String(), sessionId.String())
+	require.NoError(t, err)
+
+	claims := &oauth.AccessTokenClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(token, claims)
+	require.NoError(t, err)
+	require.Equal(t, identityId.String(), claims.Subject)
+	require.Equal(t, sessionId.String(), claims.SessionId)
+	require.Empty(t, claims.Audience)
+
+	// Tokens issued without a session have no session claim
+	token, _, err = oauth.GenerateSessionAccessToken(ctx, identityId.String(), "")
+	require.NoError(t, err)
+
+	claims = &oauth.AccessTokenClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(token, claims)
+	require.NoError(t, err)
+	require.Empty(t, claims.SessionId)
+}
+
+func TestUploadTokenGenerationAndParsing(t *testing.T) {
+	ctx := newContextWithPK(t.Context())
+	ctx = auth.WithIdentity(ctx, auth.Identity{"id": "identity_1"})
+
+	token, err := oauth.GenerateUploadToken(ctx, "upload_key", "createPost", "image", time.Hour)
+	require.NoError(t, err)
+
+	key, err := oauth.ValidateUploadToken(ctx, token, "createPost", "image")
+	require.NoError(t, err)
+	require.Equal(t, "upload_key", key)
+}
+
+func TestUploadTokenRejectedForOtherCaller(t *testing.T) {
+	ctx := newContextWithPK(t.Context())
+
+	token, err := oauth.GenerateUploadToken(auth.WithIdentity(ctx, auth.Identity{"id": "identity_1"}), "upload_key", "createPost", "image", time.Hour)
+	require.NoError(t, err)
+
+	_, err = oauth.ValidateUploadToken(auth.WithIdentity(ctx, auth.Identity{"id": "identity_2"}), token, "createPost", "image")
+	require.ErrorIs(t, err, oauth.ErrInvalidUploadToken)
+
+	_, err = oauth.ValidateUploadToken(ctx, token, "createPost", "image")
+	require.ErrorIs(t, err, oauth.ErrInvalidUploadToken)
+
+	_, err = oauth.ValidateUploadToken(auth.WithServiceAccount(ctx, &auth.ServiceAccount{Id: "identity_1"}), token, "createPost", "image")
+	require.ErrorIs(t, err, oauth.ErrInvalidUploadToken)
+}
+
+func TestUploadTokenRejectedForOtherInput(t *testing.T) {
+	ctx := newContextWithPK(t.Context())
+
+	token, err := oauth.GenerateUploadToken(ctx, "upload_key", "createPost", "image", time.Hour)
+	require.NoError(t, err)
+
+	_, err = oauth.ValidateUploadToken(ctx, token, "updatePost", "image")
+	require.ErrorIs(t, err, oauth.ErrInvalidUploadToken)
+
+	_, err = oauth.ValidateUploadToken(ctx, token, "createPost", "thumbnail")
+	require.ErrorIs(t, err, oauth.ErrInvalidUploadToken)
+
+	_, err = oauth.ValidateUploadToken(ctx, "upload_key", "createPost", "image")
+	require.ErrorIs(t, err, oauth.ErrInvalidUploadToken)
+}
+
+func TestUploadTokenIsNotAccessToken(t *testing.T) {
+	ctx := newContextWithPK(t.Context())
+
+	token, err := oauth.GenerateUploadToken(ctx, "upload_key", "createPost", "image", time.Hour)
+	require.NoError(t, err)
+
+	_, err = oauth.ValidateAccessToken(ctx, token)
+	require.ErrorIs(t, err, oauth.ErrInvalidToken)
+}
@@ -0,0 +1,377 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/karlseguin/typed"
+	"github.com/teamkeel/keel/auditing"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// Failed attempts to authenticate with the password grant.
+	LockoutActionPassword = "password"
+	// Requests to reset a password, each of which is counted as an attempt.
+	LockoutActionPasswordReset = "password_reset"
+	// Failed attempts at a multi-factor authentication code.
+	LockoutActionMfa = "mfa"
+)
+
+const (
+	// Attempts counted against the email address of an identity.
+	LockoutKindIdentity = "identity"
+	// Attempts counted against the IP address of the client.
+	LockoutKindIp = "ip"
+)
+
+// The table name used for lockout entries in the audit table.
+const lockoutAuditTableName = "keel_lockout"
+
+// Lockout is a counter of failed attempts for an email address or IP address, as stored in the keel_lockout table.
+type Lockout struct {
+	Action      string
+	Kind        string
+	Subject     string
+	Failures    int
+	LockedUntil *time.Time
+	UpdatedAt   time.Time
+}
+
+// CheckLockout returns the time until which the email address or IP address is locked out of the action,
+// or nil if neither is locked out.
+func CheckLockout(ctx context.Context, action string, email string, ipAddress string) (*time.Time, error) {
+	ctx, span := tracer.Start(ctx, "Check Lockout")
+	defer span.End()
+
+	config, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.LockoutEnabled() {
+		return nil, nil
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := `
+		SELECT
+			MAX(locked_until) AS locked_until
+		FROM
+			keel_lockout
+		WHERE
+			action = ? AND
+			((kind = ? AND subject = ?) OR (kind = ? AND subject = ?)) AND
+			locked_until > now()`
+
+	rows := []map[string]any{}
+	err = database.GetDB().Raw(sql, action, LockoutKindIdentity, NormaliseEmail(email), LockoutKindIp, ipAddress).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 1 {
+		if t, ok := rows[0]["locked_until"].(time.Time); ok {
+			return &t, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// errLockedOut is returned within the transaction of ReserveAttempt when a subject is locked out, so that the attempts
+// already counted against any other subject are rolled back.
+var errLockedOut = errors.New("locked out")
+
+// ReserveAttempt counts an attempt at the action against the email address and IP address before the attempt is made,
+// so that concurrent attempts cannot all be made before any of them has been counted. The attempt is counted against
+// the email address as failed until ResetFailedAttempts is called after it succeeds, while every attempt counts against
+// the IP address. Once either the email address or IP address has reached its
+// maximum number of attempts, it is locked out for the configured duration, which doubles with each further failed
+// attempt up to the maximum duration, although the attempt which reached the maximum may still be made.
+//
+// Returns whether the attempt may be made and the time until which the email address or IP address is locked out,
+// either by an existing lockout or by this attempt, or nil.
+func ReserveAttempt(ctx context.Context, action string, email string, ipAddress string) (bool, *time.Time, error) {
+	ctx, span := tracer.Start(ctx, "Reserve Attempt")
+	defer span.End()
+
+	config, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if !config.LockoutEnabled() {
+		return true, nil, nil
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+
+	subjects := []struct {
+		kind        string
+		subject     string
+		maxAttempts int
+	}{
+		{LockoutKindIdentity, NormaliseEmail(email), config.LockoutMaxAttempts()},
+		{LockoutKindIp, ipAddress, config.LockoutMaxAttemptsPerIp()},
+	}
+
+	var lockedUntil *time.Time
+	err = database.Transaction(ctx, func(ctx context.Context) error {
+		for _, s := range subjects {
+			if s.subject == "" {
+				continue
+			}
+
+			// The row is locked by the upsert, so concurrent attempts are counted one after the other. Nothing is
+			// counted while the subject is locked out. Failures are counted afresh once no attempt has been made
+			// for longer than the maximum lockout duration.
+			sql := `
+				INSERT INTO
+					keel_lockout (action, kind, subject, failures, updated_at)
+				VALUES
+					(?, ?, ?, 1, now())
+				ON CONFLICT (action, kind, subject) DO UPDATE SET
+					failures = CASE WHEN keel_lockout.updated_at < now() - make_interval(secs => ?) THEN 1 ELSE keel_lockout.failures + 1 END,
+					updated_at = now()
+				WHERE
+					keel_lockout.locked_until IS NULL OR keel_lockout.locked_until <= now()
+				RETURNING
+					failures`
+
+			result, err := database.ExecuteQuery(ctx, sql, action, s.kind, s.subject, config.LockoutMaxDuration().Seconds())
+			if err != nil {
+				return err
+			}
+
+			if len(result.Rows) == 0 {
+				return errLockedOut
+			}
+
+			failures := typed.New(result.Rows[0]).Int("failures")
+			if failures < s.maxAttempts {
+				continue
+			}
+
+			until := time.Now().UTC().Add(lockoutDuration(config.LockoutDuration(), config.LockoutMaxDuration(), failures-s.maxAttempts))
+
+			_, err = database.ExecuteStatement(ctx, "UPDATE keel_lockout SET locked_until = ? WHERE action = ? AND kind = ? AND subject = ?", until, action, s.kind, s.subject)
+			if err != nil {
+				return err
+			}
+
+			err = auditLockout(ctx, auditing.Update, map[string]any{
+				"id":           s.subject,
+				"action":       action,
+				"kind":         s.kind,
+				"failures":     failures,
+				"locked_until": until,
+			})
+			if err != nil {
+				return err
+			}
+
+			if lockedUntil == nil || until.After(*lockedUntil) {
+				lockedUntil = &until
+			}
+		}
+
+		return nil
+	})
+	if errors.Is(err, errLockedOut) {
+		lockedUntil, err = CheckLockout(ctx, action, email, ipAddress)
+		if err != nil {
+			return false, nil, err
+		}
+
+		// The lockout may have ended since the attempt was counted
+		if lockedUntil == nil {
+			return ReserveAttempt(ctx, action, email, ipAddress)
+		}
+
+		return false, lockedUntil, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	return true, lockedUntil, nil
+}
+
+// ResetFailedAttempts clears the attempts at the action counted against the email address, after an attempt reserved
+// with ReserveAttempt has succeeded. The attempt is still counted against the IP address, so that a client cannot make
+// more attempts than the IP address is allowed by also signing into an account of its own.
+func ResetFailedAttempts(ctx context.Context, action string, email string) error {
+	ctx, span := tracer.Start(ctx, "Reset Failed Attempts")
+	defer span.End()
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The successful attempt was reserved before any lockout of the email address, so it is lifted too
+	sql := `
+		DELETE FROM
+			keel_lockout
+		WHERE
+			action = ? AND
+			kind = ? AND
+			subject = ?`
+
+	return database.GetDB().Exec(sql, action, LockoutKindIdentity, NormaliseEmail(email)).Error
+}
+
+// ListLockouts returns the email addresses and IP addresses which are currently locked out, latest lockout first.
+func ListLockouts(ctx context.Context) ([]*Lockout, error) {
+	ctx, span := tracer.Start(ctx, "List Lockouts")
+	defer span.End()
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := `
+		SELECT
+			action, kind, subject, failures, locked_until, updated_at
+		FROM
+			keel_lockout
+		WHERE
+			locked_until > now()
+		ORDER BY
+			locked_until DESC`
+
+	rows := []map[string]any{}
+	err = database.GetDB().Raw(sql).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lockouts := []*Lockout{}
+	for _, row := range rows {
+		r := typed.New(row)
+		lockout := &Lockout{
+			Action:   r.String("action"),
+			Kind:     r.String("kind"),
+			Subject:  r.String("subject"),
+			Failures: r.Int("failures"),
+		}
+
+		if t, ok := row["locked_until"].(time.Time); ok {
+			lockout.LockedUntil = &t
+		}
+		if t, ok := row["updated_at"].(time.Time); ok {
+			lockout.UpdatedAt = t
+		}
+
+		lockouts = append(lockouts, lockout)
+	}
+
+	return lockouts, nil
+}
+
+// Unlock lifts any lockout of the email address or IP address and clears its failed attempts for all actions.
+// Returns the number of lockouts which were lifted.
+func Unlock(ctx context.Context, subject string) (int64, error) {
+	ctx, span := tracer.Start(ctx, "Unlock")
+	defer span.End()
+
+	if subject == "" {
+		return 0, errors.New("the email address or IP address to unlock cannot be empty")
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var unlocked int64
+	err = database.Transaction(ctx, func(ctx context.Context) error {
+		sql := `
+			DELETE FROM
+				keel_lockout
+			WHERE
+				(kind = ? AND subject = ?) OR (kind = ? AND subject = ?)
+			RETURNING
+				action, kind, subject, locked_until`
+
+		result, err := database.ExecuteQuery(ctx, sql, LockoutKindIdentity, NormaliseEmail(subject), LockoutKindIp, subject)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range result.Rows {
+			lockedUntil, ok := row["locked_until"].(time.Time)
+			if !ok || !lockedUntil.After(time.Now()) {
+				continue
+			}
+
+			unlocked++
+
+			err = auditLockout(ctx, auditing.Delete, map[string]any{
+				"id":     row["subject"],
+				"action": row["action"],
+				"kind":   row["kind"],
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return unlocked, nil
+}
+
+// lockoutDuration doubles the lockout duration for each failed attempt beyond the maximum, up to the maximum duration.
+func lockoutDuration(duration time.Duration, maxDuration time.Duration, excessFailures int) time.Duration {
+	for range excessFailures {
+		if duration >= maxDuration {
+			break
+		}
+		duration *= 2
+	}
+
+	return min(duration, maxDuration)
+}
+
+// auditLockout records a lockout or unlock in the audit table, so that it appears in the audit trail with the trace of the request.
+func auditLockout(ctx context.Context, op string, data map[string]any) error {
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	j, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var traceId any
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		traceId = spanContext.TraceID().String()
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s) VALUES (?, ?, ?::jsonb, ?)",
+		auditing.TableName, auditing.ColumnTableName, auditing.ColumnOp, auditing.ColumnData, auditing.ColumnTraceId)
+
+	_, err = database.ExecuteStatement(ctx, sql, lockoutAuditTableName, op, string(j), traceId)
+	return err
+}
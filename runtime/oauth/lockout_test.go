@@ -0,0 +1,284 @@
+package oauth_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/runtime/oauth"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+func TestReserveAttempt_LocksOutEmail(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttempts := 3
+	duration := 60
+	maxDuration := 150
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{
+			MaxAttempts: &maxAttempts,
+			Duration:    &duration,
+			MaxDuration: &maxDuration,
+		},
+	})
+
+	for range 2 {
+		allowed, lockedUntil, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "203.0.113.1")
+		require.NoError(t, err)
+		require.True(t, allowed)
+		require.Nil(t, lockedUntil)
+	}
+
+	lockedUntil, err := oauth.CheckLockout(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.Nil(t, lockedUntil)
+
+	// The third attempt may be made, but locks out the email for the configured duration
+	allowed, lockedUntil, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "Keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.NotNil(t, lockedUntil)
+	require.WithinDuration(t, time.Now().Add(60*time.Second), *lockedUntil, 5*time.Second)
+
+	lockedUntil, err = oauth.CheckLockout(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "")
+	require.NoError(t, err)
+	require.NotNil(t, lockedUntil)
+
+	// No further attempts are allowed or counted during the lockout
+	allowed, lockedUntil, err = oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.WithinDuration(t, time.Now().Add(60*time.Second), *lockedUntil, 5*time.Second)
+
+	lockouts, err := oauth.ListLockouts(ctx)
+	require.NoError(t, err)
+	require.Len(t, lockouts, 1)
+	require.Equal(t, 3, lockouts[0].Failures)
+
+	// Each attempt after the lockout has ended doubles the lockout, up to the maximum duration
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_lockout SET locked_until = now()")
+	require.NoError(t, err)
+
+	allowed, lockedUntil, err = oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.WithinDuration(t, time.Now().Add(120*time.Second), *lockedUntil, 5*time.Second)
+
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_lockout SET locked_until = now()")
+	require.NoError(t, err)
+
+	allowed, lockedUntil, err = oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.WithinDuration(t, time.Now().Add(150*time.Second), *lockedUntil, 5*time.Second)
+
+	// Other emails from the same IP address, and other actions, are not locked out
+	lockedUntil, err = oauth.CheckLockout(ctx, oauth.LockoutActionPassword, "weaveton@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.Nil(t, lockedUntil)
+
+	lockedUntil, err = oauth.CheckLockout(ctx, oauth.LockoutActionPasswordReset, "keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.Nil(t, lockedUntil)
+}
+
+func TestReserveAttempt_Concurrent(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttempts := 3
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{
+			MaxAttempts: &maxAttempts,
+		},
+	})
+
+	var wg sync.WaitGroup
+	var allowedCount atomic.Int32
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			allowed, _, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "")
+			assert.NoError(t, err)
+			if allowed {
+				allowedCount.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(maxAttempts), allowedCount.Load())
+}
+
+func TestReserveAttempt_LocksOutIpAddress(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttemptsPerIp := 2
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{
+			MaxAttemptsPerIp: &maxAttemptsPerIp,
+		},
+	})
+
+	allowed, lockedUntil, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Nil(t, lockedUntil)
+
+	allowed, lockedUntil, err = oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "weaveton@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.NotNil(t, lockedUntil)
+
+	lockedUntil, err = oauth.CheckLockout(ctx, oauth.LockoutActionPassword, "dave@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.NotNil(t, lockedUntil)
+
+	lockedUntil, err = oauth.CheckLockout(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "198.51.100.1")
+	require.NoError(t, err)
+	require.Nil(t, lockedUntil)
+}
+
+func TestReserveAttempt_Disabled(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	disabled := false
+	maxAttempts := 1
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{
+			Enabled:     &disabled,
+			MaxAttempts: &maxAttempts,
+		},
+	})
+
+	allowed, lockedUntil, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Nil(t, lockedUntil)
+
+	lockedUntil, err = oauth.CheckLockout(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.Nil(t, lockedUntil)
+}
+
+func TestResetFailedAttempts(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttempts := 2
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{
+			MaxAttempts: &maxAttempts,
+		},
+	})
+
+	allowed, lockedUntil, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Nil(t, lockedUntil)
+
+	err = oauth.ResetFailedAttempts(ctx, oauth.LockoutActionPassword, "keelson@keel.so")
+	require.NoError(t, err)
+
+	// The counter starts again
+	allowed, lockedUntil, err = oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Nil(t, lockedUntil)
+}
+
+func TestResetFailedAttempts_KeepsIpAddressAttempts(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttemptsPerIp := 4
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{
+			MaxAttemptsPerIp: &maxAttemptsPerIp,
+		},
+	})
+
+	// Signing into an account of its own between guesses at another does not give the IP address more attempts
+	for range 2 {
+		allowed, lockedUntil, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "victim@keel.so", "203.0.113.1")
+		require.NoError(t, err)
+		require.True(t, allowed)
+		require.Nil(t, lockedUntil)
+
+		allowed, _, err = oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "attacker@keel.so", "203.0.113.1")
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		err = oauth.ResetFailedAttempts(ctx, oauth.LockoutActionPassword, "attacker@keel.so")
+		require.NoError(t, err)
+	}
+
+	lockedUntil, err := oauth.CheckLockout(ctx, oauth.LockoutActionPassword, "victim@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.NotNil(t, lockedUntil)
+
+	allowed, _, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "victim@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.False(t, allowed)
+}
+
+func TestUnlock(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	maxAttempts := 1
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{
+			MaxAttempts: &maxAttempts,
+		},
+	})
+
+	allowed, lockedUntil, err := oauth.ReserveAttempt(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.NotNil(t, lockedUntil)
+
+	lockouts, err := oauth.ListLockouts(ctx)
+	require.NoError(t, err)
+	require.Len(t, lockouts, 1)
+	require.Equal(t, oauth.LockoutActionPassword, lockouts[0].Action)
+	require.Equal(t, oauth.LockoutKindIdentity, lockouts[0].Kind)
+	require.Equal(t, "keelson@keel.so", lockouts[0].Subject)
+	require.Equal(t, 1, lockouts[0].Failures)
+
+	unlocked, err := oauth.Unlock(ctx, "Keelson@keel.so")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), unlocked)
+
+	lockedUntil, err = oauth.CheckLockout(ctx, oauth.LockoutActionPassword, "keelson@keel.so", "203.0.113.1")
+	require.NoError(t, err)
+	require.Nil(t, lockedUntil)
+
+	lockouts, err = oauth.ListLockouts(ctx)
+	require.NoError(t, err)
+	require.Empty(t, lockouts)
+
+	// The lockout and the unlock are in the audit trail
+	result, err := database.ExecuteQuery(ctx, "SELECT op FROM keel_audit WHERE table_name = 'keel_lockout' AND data->>'id' = ? ORDER BY created_at", "keelson@keel.so")
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 2)
+	require.Equal(t, "update", result.Rows[0]["op"])
+	require.Equal(t, "delete", result.Rows[1]["op"])
+}
+
+func TestUnlock_ErrorOnEmptySubject(t *testing.T) {
+	ctx := t.Context()
+
+	_, err := oauth.Unlock(ctx, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,362 @@
+package oauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // SHA-1 is the algorithm authenticator apps support for TOTP, https://datatracker.ietf.org/doc/html/rfc6238
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+)
+
+const (
+	// Byte length of TOTP secrets, as recommended in https://datatracker.ietf.org/doc/html/rfc4226#section-4
+	totpSecretLength = 20
+	// Digit length of TOTP codes.
+	totpDigits = 6
+	// Period for which each TOTP code is valid.
+	totpPeriod = 30 * time.Second
+	// Number of periods either side of the current one for which a code is accepted, to allow for clock drift.
+	totpSkew = 1
+	// Number of recovery codes issued on enrolment.
+	recoveryCodeCount = 10
+	// Character length of each half of a recovery code.
+	recoveryCodeLength = 5
+)
+
+var (
+	ErrMfaAlreadyEnabled = errors.New("multi-factor authentication is already enabled for this identity")
+)
+
+var recoveryCodeChars = []byte("abcdefghijkmnpqrstuvwxyz23456789")
+
+// MfaEnrolment is the TOTP secret and recovery codes issued to an identity when enrolling in multi-factor authentication.
+type MfaEnrolment struct {
+	Secret        string
+	Uri           string
+	RecoveryCodes []string
+}
+
+// EnrolMfa issues a new TOTP secret and recovery codes to the identity, replacing any enrolment which is yet to be
+// confirmed. The enrolment only takes effect once confirmed with a code from the authenticator app.
+func EnrolMfa(ctx context.Context, identityId string, accountName string) (*MfaEnrolment, error) {
+	ctx, span := tracer.Start(ctx, "Enrol MFA")
+	defer span.End()
+
+	if identityId == "" {
+		return nil, errors.New("identityId cannot be empty when enrolling in mfa")
+	}
+
+	secret := make([]byte, totpSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := encryptSecret(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolment := &MfaEnrolment{
+		Secret: base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret),
+	}
+	enrolment.Uri = totpUri(config.MfaIssuer(), accountName, enrolment.Secret)
+
+	err = database.Transaction(ctx, func(ctx context.Context) error {
+		sql := `
+			INSERT INTO
+				keel_mfa (identity_id, secret, enabled, last_used_step, created_at)
+			VALUES
+				(?, ?, false, 0, now())
+			ON CONFLICT (identity_id) DO UPDATE SET
+				secret = EXCLUDED.secret,
+				created_at = EXCLUDED.created_at
+			WHERE
+				keel_mfa.enabled = false`
+
+		result, err := database.ExecuteStatement(ctx, sql, identityId, encrypted)
+		if err != nil {
+			return err
+		}
+
+		if result.RowsAffected != 1 {
+			return ErrMfaAlreadyEnabled
+		}
+
+		_, err = database.ExecuteStatement(ctx, "DELETE FROM keel_mfa_recovery_code WHERE identity_id = ?", identityId)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < recoveryCodeCount; i++ {
+			code := uniuri.NewLenChars(recoveryCodeLength, recoveryCodeChars) + "-" + uniuri.NewLenChars(recoveryCodeLength, recoveryCodeChars)
+
+			hash, err := hashToken(code)
+			if err != nil {
+				return err
+			}
+
+			_, err = database.ExecuteStatement(ctx, "INSERT INTO keel_mfa_recovery_code (identity_id, code) VALUES (?, ?)", identityId, hash)
+			if err != nil {
+				return err
+			}
+
+			enrolment.RecoveryCodes = append(enrolment.RecoveryCodes, code)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return enrolment, nil
+}
+
+// ConfirmMfa enables multi-factor authentication for the identity if the code is correct for the enrolment
+// which is yet to be confirmed.
+func ConfirmMfa(ctx context.Context, identityId string, code string) (isValid bool, err error) {
+	ctx, span := tracer.Start(ctx, "Confirm MFA")
+	defer span.End()
+
+	secret, enabled, _, err := getMfaSecret(ctx, identityId)
+	if err != nil {
+		return false, err
+	}
+
+	if secret == nil || enabled {
+		return false, nil
+	}
+
+	if _, ok := validateTotp(secret, code, time.Now()); !ok {
+		return false, nil
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	result := database.GetDB().Exec("UPDATE keel_mfa SET enabled = true WHERE identity_id = ? AND enabled = false", identityId)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// MfaEnabled returns true if the identity has confirmed its enrolment in multi-factor authentication.
+func MfaEnabled(ctx context.Context, identityId string) (bool, error) {
+	_, enabled, _, err := getMfaSecret(ctx, identityId)
+	return enabled, err
+}
+
+// VerifyMfa checks the code against the identity's authenticator app or its unused recovery codes. A TOTP code
+// cannot be used more than once, and a recovery code is consumed when used.
+func VerifyMfa(ctx context.Context, identityId string, code string) (isValid bool, err error) {
+	ctx, span := tracer.Start(ctx, "Verify MFA")
+	defer span.End()
+
+	secret, enabled, lastUsedStep, err := getMfaSecret(ctx, identityId)
+	if err != nil {
+		return false, err
+	}
+
+	if !enabled {
+		return false, nil
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if step, ok := validateTotp(secret, code, time.Now()); ok {
+		if step <= lastUsedStep {
+			return false, nil
+		}
+
+		// Only one request can succeed with the same code
+		result := database.GetDB().Exec("UPDATE keel_mfa SET last_used_step = ? WHERE identity_id = ? AND last_used_step < ?", step, identityId, step)
+		if result.Error != nil {
+			return false, result.Error
+		}
+
+		return result.RowsAffected == 1, nil
+	}
+
+	hash, err := hashToken(strings.ToLower(strings.TrimSpace(code)))
+	if err != nil {
+		return false, err
+	}
+
+	result := database.GetDB().Exec("DELETE FROM keel_mfa_recovery_code WHERE identity_id = ? AND code = ?", identityId, hash)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// DisableMfa removes the identity's enrolment in multi-factor authentication along with its recovery codes.
+func DisableMfa(ctx context.Context, identityId string) error {
+	ctx, span := tracer.Start(ctx, "Disable MFA")
+	defer span.End()
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(ctx, func(ctx context.Context) error {
+		_, err := database.ExecuteStatement(ctx, "DELETE FROM keel_mfa WHERE identity_id = ?", identityId)
+		if err != nil {
+			return err
+		}
+
+		_, err = database.ExecuteStatement(ctx, "DELETE FROM keel_mfa_recovery_code WHERE identity_id = ?", identityId)
+		return err
+	})
+}
+
+func getMfaSecret(ctx context.Context, identityId string) (secret []byte, enabled bool, lastUsedStep int64, err error) {
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, false, 0, err
+	}
+
+	rows := []map[string]any{}
+	err = database.GetDB().Raw("SELECT secret, enabled, last_used_step FROM keel_mfa WHERE identity_id = ?", identityId).Scan(&rows).Error
+	if err != nil {
+		return nil, false, 0, err
+	}
+
+	if len(rows) == 0 {
+		return nil, false, 0, nil
+	}
+
+	secret, err = decryptSecret(ctx, rows[0]["secret"].(string))
+	if err != nil {
+		return nil, false, 0, err
+	}
+
+	return secret, rows[0]["enabled"].(bool), rows[0]["last_used_step"].(int64), nil
+}
+
+// totpUri generates the key URI which authenticator apps read from a QR code,
+// https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func totpUri(issuer string, accountName string, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: q.Encode(),
+	}
+
+	return uri.String()
+}
+
+// validateTotp checks the code against the secret for the period at the given time, and those either side of it,
+// and returns the time step for which the code is valid.
+func validateTotp(secret []byte, code string, at time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := at.Unix() / int64(totpPeriod.Seconds())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if hmac.Equal([]byte(TotpCode(secret, step)), []byte(code)) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// TotpCode generates the code for the secret at the time step, https://datatracker.ietf.org/doc/html/rfc6238
+func TotpCode(secret []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, https://datatracker.ietf.org/doc/html/rfc4226#section-5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000)
+}
+
+// TotpStep returns the time step for the given time.
+func TotpStep(at time.Time) int64 {
+	return at.Unix() / int64(totpPeriod.Seconds())
+}
+
+// encryptSecret encrypts the TOTP secret with the runtime's key, as unlike other credentials it cannot be stored as a hash.
+func encryptSecret(ctx context.Context, secret []byte) (string, error) {
+	privateKey, err := runtimectx.GetPrivateKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if privateKey == nil {
+		return "", errors.New("no private key set")
+	}
+
+	encrypted, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, &privateKey.PublicKey, secret, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func decryptSecret(ctx context.Context, encrypted string) ([]byte, error) {
+	privateKey, err := runtimectx.GetPrivateKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if privateKey == nil {
+		return nil, errors.New("no private key set")
+	}
+
+	b, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, b, nil)
+}
@@ -0,0 +1,28 @@
+package oauth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/runtime/oauth"
+)
+
+// Test vectors from https://datatracker.ietf.org/doc/html/rfc6238#appendix-B, truncated to 6 digits.
+func TestTotpCode(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range cases {
+		step := oauth.TotpStep(time.Unix(unix, 0))
+		require.Equal(t, expected, oauth.TotpCode(secret, step), "at %d", unix)
+	}
+}
@@ -0,0 +1,190 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+)
+
+const (
+	// Character length of crypo-generated magic link codes.
+	magicLinkCodeLength = 32
+	// Digit length of one-time codes.
+	oneTimeCodeLength = 6
+	// PasswordlessCodeInterval is how long after issuing a code to an email address another can be issued.
+	PasswordlessCodeInterval = time.Minute
+)
+
+// ErrPasswordlessCodeTooSoon is returned when a code is requested for an email address within
+// PasswordlessCodeInterval of the last code issued to it.
+var ErrPasswordlessCodeTooSoon = errors.New("a passwordless code was issued to the email address too recently")
+
+// NewMagicLinkCode generates a new code to be sent in a magic link to the email address. Any code previously
+// issued to the email address is replaced, but its attempts still count towards the new code until it would have
+// expired.
+func NewMagicLinkCode(ctx context.Context, email string) (string, error) {
+	return newPasswordlessCode(ctx, email, uniuri.NewLen(magicLinkCodeLength))
+}
+
+// NewOneTimeCode generates a new 6-digit code to be sent to the email address. Any code previously
+// issued to the email address is replaced, but its attempts still count towards the new code until it would have
+// expired.
+func NewOneTimeCode(ctx context.Context, email string) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+
+	return newPasswordlessCode(ctx, email, fmt.Sprintf("%0*d", oneTimeCodeLength, n.Int64()))
+}
+
+func newPasswordlessCode(ctx context.Context, email string, code string) (string, error) {
+	ctx, span := tracer.Start(ctx, "New Passwordless Code")
+	defer span.End()
+
+	if email == "" {
+		return "", errors.New("email cannot be empty when generating new passwordless code")
+	}
+
+	hash, err := hashToken(code)
+	if err != nil {
+		return "", err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	config, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(config.PasswordlessCodeExpiry())
+
+	// A code is only replaced once the interval has passed since it was issued, and its attempts are only
+	// forgotten once it has expired, so that issuing new codes cannot be used to get more attempts.
+	sql := `
+		INSERT INTO
+			keel_passwordless_code (email, code, attempts, expires_at, created_at)
+		VALUES
+			(?, ?, 0, ?, ?)
+		ON CONFLICT (email) DO UPDATE SET
+			code = EXCLUDED.code,
+			attempts = CASE WHEN keel_passwordless_code.expires_at < EXCLUDED.created_at THEN 0 ELSE keel_passwordless_code.attempts END,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at
+		WHERE
+			keel_passwordless_code.created_at <= ?`
+
+	db := database.GetDB().Exec(sql, NormaliseEmail(email), hash, expiresAt, now, now.Add(-PasswordlessCodeInterval))
+	if db.Error != nil {
+		return "", db.Error
+	}
+
+	if db.RowsAffected != 1 {
+		return "", ErrPasswordlessCodeTooSoon
+	}
+
+	return code, nil
+}
+
+// ConsumePasswordlessCode checks that the code is the one last issued to the email address, that it has not expired
+// and that it has not been attempted too many times, and then consumes it (making it unusable again). Every attempt,
+// correct or not, is counted before the code is compared, so that concurrent attempts cannot exceed the configured
+// number of attempts.
+func ConsumePasswordlessCode(ctx context.Context, email string, code string) (isValid bool, err error) {
+	ctx, span := tracer.Start(ctx, "Consume Passwordless Code")
+	defer span.End()
+
+	codeHash, err := hashToken(code)
+	if err != nil {
+		return false, err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	config, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	email = NormaliseEmail(email)
+
+	sql := `
+		UPDATE
+			keel_passwordless_code
+		SET
+			attempts = attempts + 1
+		WHERE
+			email = ? AND
+			attempts < ? AND
+			expires_at >= now()
+		RETURNING
+			code`
+
+	rows := []map[string]any{}
+	err = database.GetDB().Raw(sql, email, config.PasswordlessMaxAttempts()).Scan(&rows).Error
+	if err != nil {
+		return false, err
+	}
+
+	if len(rows) == 1 {
+		issued, _ := rows[0]["code"].(string)
+		if subtle.ConstantTimeCompare([]byte(issued), []byte(codeHash)) == 1 {
+			// Only one of any concurrent attempts with the correct code can consume it
+			sql = `
+				DELETE FROM
+					keel_passwordless_code
+				WHERE
+					email = ? AND
+					code = ?
+				RETURNING
+					email`
+
+			rows = []map[string]any{}
+			err = database.GetDB().Raw(sql, email, codeHash).Scan(&rows).Error
+			if err != nil {
+				return false, err
+			}
+
+			return len(rows) == 1, nil
+		}
+	}
+
+	// Remove the issued code if it has expired. A code which has been attempted too many times is kept until
+	// it expires, so that its attempts still count if another code is issued in the meantime.
+	sql = `
+		DELETE FROM
+			keel_passwordless_code
+		WHERE
+			email = ? AND
+			expires_at < now()`
+
+	db := database.GetDB().Exec(sql, email)
+	if db.Error != nil {
+		return false, db.Error
+	}
+
+	return false, nil
+}
+
+// NormaliseEmail lower-cases an email address, so that the same address in a different case is one and the same
+// identity, passwordless code and lockout.
+func NormaliseEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -17,9 +17,9 @@ const (
 	refreshTokenLength = 64
 )
 
-// NewRefreshToken generates a new refresh token for the identity using the
-// configured or default expiry time.
-func NewRefreshToken(ctx context.Context, identityId string) (string, error) {
+// NewRefreshToken generates a new refresh token for the identity in the given session
+// using the configured or default expiry time.
+func NewRefreshToken(ctx context.Context, identityId string, sessionId string) (string, error) {
 	ctx, span := tracer.Start(ctx, "New Refresh Token")
 	defer span.End()
 
@@ -46,13 +46,19 @@ func NewRefreshToken(ctx context.Context, identityId string) (string, error) {
 	now := time.Now().UTC()
 	expiresAt := now.Add(config.RefreshTokenExpiry())
 
+	// Refresh tokens are stored without a session if none is given
+	var session any
+	if sessionId != "" {
+		session = sessionId
+	}
+
 	sql := `
 		INSERT INTO 
-			keel_refresh_token (token, identity_id, expires_at, created_at) 
+			keel_refresh_token (token, identity_id, session_id, expires_at, created_at) 
 		VALUES 
-			(?, ?, ?, ?)`
+			(?, ?, ?, ?, ?)`
 
-	db := database.GetDB().Exec(sql, hash, identityId, expiresAt, now)
+	db := database.GetDB().Exec(sql, hash, identityId, session, expiresAt, now)
 	if db.Error != nil {
 		return "", db.Error
 	}
@@ -65,31 +71,31 @@ func NewRefreshToken(ctx context.Context, identityId string) (string, error) {
 }
 
 // RotateRefreshToken validates that the provided refresh token has not expired,
-// and then rotates it for a new refresh token with the exact same expiry time and
-// identity. The original refresh token is then revoked from future use.
-func RotateRefreshToken(ctx context.Context, refreshTokenRaw string) (isValid bool, refreshToken string, identityId string, err error) {
+// and then rotates it for a new refresh token with the exact same expiry time, identity
+// and session. The original refresh token is then revoked from future use.
+func RotateRefreshToken(ctx context.Context, refreshTokenRaw string) (isValid bool, refreshToken string, identityId string, sessionId string, err error) {
 	ctx, span := tracer.Start(ctx, "Rotate Refresh Token")
 	defer span.End()
 
 	tokenHash, err := hashToken(refreshTokenRaw)
 	if err != nil {
-		return false, "", "", err
+		return false, "", "", "", err
 	}
 
 	newRefreshToken := uniuri.NewLen(refreshTokenLength)
 	newTokenHash, err := hashToken(newRefreshToken)
 	if err != nil {
-		return false, "", "", err
+		return false, "", "", "", err
 	}
 
 	database, err := db.GetDatabase(ctx)
 	if err != nil {
-		return false, "", "", err
+		return false, "", "", "", err
 	}
 
 	// This query has the following (important) characteristics:
 	//  - find and delete the refresh token
-	//  - create a new refresh token with the identity_id and expire_at of the original token
+	//  - create a new refresh token with the identity_id, session_id and expire_at of the original token
 	//  - only creates the new token if the original token had not expired
 	sql := `
 		WITH revoked_token AS (
@@ -99,9 +105,9 @@ func RotateRefreshToken(ctx context.Context, refreshTokenRaw string) (isValid bo
 				token = ?
 			RETURNING *)
 		INSERT INTO 
-			keel_refresh_token (token, identity_id, expires_at, created_at) 
+			keel_refresh_token (token, identity_id, session_id, expires_at, created_at) 
 		SELECT
-			?, identity_id, expires_at, now()
+			?, identity_id, session_id, expires_at, now()
 		FROM 
 			revoked_token
 		WHERE
@@ -111,41 +117,44 @@ func RotateRefreshToken(ctx context.Context, refreshTokenRaw string) (isValid bo
 	rows := []map[string]any{}
 	err = database.GetDB().Raw(sql, tokenHash, newTokenHash).Scan(&rows).Error
 	if err != nil {
-		return false, "", "", err
+		return false, "", "", "", err
 	}
 
 	// There was no refresh token found, and thus nothing to rotate.
 	if len(rows) != 1 {
-		return false, "", "", nil
+		return false, "", "", "", nil
 	}
 
 	identityId, ok := rows[0]["identity_id"].(string)
 	if !ok {
-		return false, "", "", errors.New("could not parse identity_id from database result")
+		return false, "", "", "", errors.New("could not parse identity_id from database result")
 	}
 
-	return true, newRefreshToken, identityId, nil
+	// Refresh tokens issued before sessions were recorded have no session
+	sessionId, _ = rows[0]["session_id"].(string)
+
+	return true, newRefreshToken, identityId, sessionId, nil
 }
 
 // ValidateRefreshToken validates that the provided refresh token has no expired,
-// and also returns the identity it is associated with. The refresh token is not revoked.
-func ValidateRefreshToken(ctx context.Context, refreshTokenRaw string) (isValid bool, identityId string, err error) {
+// and also returns the identity and session it is associated with. The refresh token is not revoked.
+func ValidateRefreshToken(ctx context.Context, refreshTokenRaw string) (isValid bool, identityId string, sessionId string, err error) {
 	ctx, span := tracer.Start(ctx, "Validate Refresh Token")
 	defer span.End()
 
 	tokenHash, err := hashToken(refreshTokenRaw)
 	if err != nil {
-		return false, "", err
+		return false, "", "", err
 	}
 
 	database, err := db.GetDatabase(ctx)
 	if err != nil {
-		return false, "", err
+		return false, "", "", err
 	}
 
 	sql := `
 		SELECT
-			token, identity_id, expires_at, now()
+			token, identity_id, session_id, expires_at, now()
 		FROM 
 			keel_refresh_token
 		WHERE 
@@ -155,24 +164,28 @@ func ValidateRefreshToken(ctx context.Context, refreshTokenRaw string) (isValid
 	rows := []map[string]any{}
 	err = database.GetDB().Raw(sql, tokenHash).Scan(&rows).Error
 	if err != nil {
-		return false, "", err
+		return false, "", "", err
 	}
 
 	// There was no refresh token found, and thus it is not valid
 	if len(rows) != 1 {
-		return false, "", nil
+		return false, "", "", nil
 	}
 
 	identityId, ok := rows[0]["identity_id"].(string)
 	if !ok {
-		return false, "", errors.New("could not parse identity_id from database result")
+		return false, "", "", errors.New("could not parse identity_id from database result")
 	}
 
-	return true, identityId, nil
+	// Refresh tokens issued before sessions were recorded have no session
+	sessionId, _ = rows[0]["session_id"].(string)
+
+	return true, identityId, sessionId, nil
 }
 
 // RevokeRefreshToken will delete (revoke) the provided refresh token,
-// which will prevent it from being used again.
+// which will prevent it from being used again. The session it belongs to
+// is also revoked, as signing out ends the session.
 func RevokeRefreshToken(ctx context.Context, refreshTokenRaw string) error {
 	ctx, span := tracer.Start(ctx, "Revoke Refresh Token")
 	defer span.End()
@@ -188,10 +201,19 @@ func RevokeRefreshToken(ctx context.Context, refreshTokenRaw string) error {
 	}
 
 	sql := `
-		DELETE FROM 
-			keel_refresh_token
-		WHERE 
-			token = ?`
+		WITH revoked_token AS (
+			DELETE FROM 
+				keel_refresh_token
+			WHERE 
+				token = ?
+			RETURNING session_id)
+		UPDATE
+			keel_session
+		SET
+			revoked_at = now()
+		WHERE
+			id IN (SELECT session_id FROM revoked_token) AND
+			revoked_at IS NULL`
 
 	err = database.GetDB().Exec(sql, tokenHash).Error
 	if err != nil {
@@ -17,7 +17,7 @@ func TestNewRefreshToken_NotEmpty(t *testing.T) {
 	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
 	defer database.Close()
 
-	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id")
+	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id", "")
 	require.NoError(t, err)
 	require.NotEmpty(t, refreshToken)
 }
@@ -25,7 +25,7 @@ func TestNewRefreshToken_NotEmpty(t *testing.T) {
 func TestNewRefreshToken_ErrorOnEmptyIdentityId(t *testing.T) {
 	ctx := t.Context()
 
-	_, err := oauth.NewRefreshToken(ctx, "")
+	_, err := oauth.NewRefreshToken(ctx, "", "")
 	require.Error(t, err)
 }
 
@@ -33,16 +33,16 @@ func TestRotateRefreshToken_Valid(t *testing.T) {
 	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
 	defer database.Close()
 
-	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id")
+	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id", "")
 	require.NoError(t, err)
 
-	isValid1, newRefreshToken1, identityId1, err := oauth.RotateRefreshToken(ctx, refreshToken)
+	isValid1, newRefreshToken1, identityId1, _, err := oauth.RotateRefreshToken(ctx, refreshToken)
 	require.NoError(t, err)
 	require.True(t, isValid1)
 	require.Equal(t, "identity_id", identityId1)
 	require.NotEmpty(t, newRefreshToken1)
 
-	isValid2, newRefreshToken2, identityId2, err := oauth.RotateRefreshToken(ctx, newRefreshToken1)
+	isValid2, newRefreshToken2, identityId2, _, err := oauth.RotateRefreshToken(ctx, newRefreshToken1)
 	require.NoError(t, err)
 	require.True(t, isValid2)
 	require.Equal(t, "identity_id", identityId2)
@@ -63,12 +63,12 @@ func TestRotateRefreshToken_Expired(t *testing.T) {
 	}
 	ctx = runtimectx.WithOAuthConfig(ctx, &config)
 
-	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id")
+	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id", "")
 	require.NoError(t, err)
 
 	time.Sleep(1100 * time.Millisecond)
 
-	isValid, newRefreshToken, identityId, err := oauth.RotateRefreshToken(ctx, refreshToken)
+	isValid, newRefreshToken, identityId, _, err := oauth.RotateRefreshToken(ctx, refreshToken)
 	require.NoError(t, err)
 	require.False(t, isValid)
 	require.Empty(t, identityId)
@@ -79,16 +79,16 @@ func TestRotateRefreshToken_ReuseRefreshTokenNotValid(t *testing.T) {
 	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
 	defer database.Close()
 
-	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id")
+	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id", "")
 	require.NoError(t, err)
 
-	isValid, newRefreshToken, identityId, err := oauth.RotateRefreshToken(ctx, refreshToken)
+	isValid, newRefreshToken, identityId, _, err := oauth.RotateRefreshToken(ctx, refreshToken)
 	require.NoError(t, err)
 	require.True(t, isValid)
 	require.Equal(t, "identity_id", identityId)
 	require.NotEmpty(t, newRefreshToken)
 
-	isValid2, newRefreshToken2, identityId2, err := oauth.RotateRefreshToken(ctx, refreshToken)
+	isValid2, newRefreshToken2, identityId2, _, err := oauth.RotateRefreshToken(ctx, refreshToken)
 	require.NoError(t, err)
 	require.False(t, isValid2)
 	require.Empty(t, identityId2)
@@ -99,10 +99,10 @@ func TestValidateRefreshToken_Valid(t *testing.T) {
 	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
 	defer database.Close()
 
-	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id")
+	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id", "")
 	require.NoError(t, err)
 
-	isValid, identityId, err := oauth.ValidateRefreshToken(ctx, refreshToken)
+	isValid, identityId, _, err := oauth.ValidateRefreshToken(ctx, refreshToken)
 	require.NoError(t, err)
 	require.True(t, isValid)
 	require.Equal(t, "identity_id", identityId)
@@ -121,12 +121,12 @@ func TestValidateRefreshToken_Expired(t *testing.T) {
 	}
 	ctx = runtimectx.WithOAuthConfig(ctx, &config)
 
-	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id")
+	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id", "")
 	require.NoError(t, err)
 
 	time.Sleep(1100 * time.Millisecond)
 
-	isValid, identityId, err := oauth.ValidateRefreshToken(ctx, refreshToken)
+	isValid, identityId, _, err := oauth.ValidateRefreshToken(ctx, refreshToken)
 	require.NoError(t, err)
 	require.False(t, isValid)
 	require.Empty(t, identityId)
@@ -136,13 +136,13 @@ func TestRevokeRefreshToken_Unauthorised(t *testing.T) {
 	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
 	defer database.Close()
 
-	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id")
+	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id", "")
 	require.NoError(t, err)
 
 	err = oauth.RevokeRefreshToken(ctx, refreshToken)
 	require.NoError(t, err)
 
-	isValid, _, _, err := oauth.RotateRefreshToken(ctx, refreshToken)
+	isValid, _, _, _, err := oauth.RotateRefreshToken(ctx, refreshToken)
 	require.NoError(t, err)
 	require.False(t, isValid)
 }
@@ -151,20 +151,20 @@ func TestRevokeRefreshToken_MultipleForIdentity(t *testing.T) {
 	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
 	defer database.Close()
 
-	refreshToken1, err := oauth.NewRefreshToken(ctx, "identity_id")
+	refreshToken1, err := oauth.NewRefreshToken(ctx, "identity_id", "")
 	require.NoError(t, err)
 
-	refreshToken2, err := oauth.NewRefreshToken(ctx, "identity_id")
+	refreshToken2, err := oauth.NewRefreshToken(ctx, "identity_id", "")
 	require.NoError(t, err)
 
 	err = oauth.RevokeRefreshToken(ctx, refreshToken1)
 	require.NoError(t, err)
 
-	isValid1, _, _, err := oauth.RotateRefreshToken(ctx, refreshToken1)
+	isValid1, _, _, _, err := oauth.RotateRefreshToken(ctx, refreshToken1)
 	require.NoError(t, err)
 	require.False(t, isValid1)
 
-	isValid2, _, _, err := oauth.RotateRefreshToken(ctx, refreshToken2)
+	isValid2, _, _, _, err := oauth.RotateRefreshToken(ctx, refreshToken2)
 	require.NoError(t, err)
 	require.True(t, isValid2)
 }
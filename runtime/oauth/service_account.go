@@ -0,0 +1,357 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/samber/lo"
+	"github.com/segmentio/ksuid"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/runtime/auth"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+)
+
+const (
+	serviceAccountAudClaim = "service-account"
+	// Prefix of the generated client ID, which tells them apart from the names of service accounts defined in config.
+	serviceAccountClientIdPrefix = "sa_"
+	// Character length of crypo-generated client IDs and secrets.
+	serviceAccountClientIdLength     = 24
+	serviceAccountClientSecretLength = 64
+	// How out of date the last_used_at of a service account can be before it is updated.
+	serviceAccountLastUsedInterval = time.Minute
+)
+
+var (
+	ErrServiceAccountRevoked = common.NewAuthenticationFailedMessageErr("service account has been revoked")
+	ErrServiceAccountExists  = errors.New("a service account with this name already exists")
+)
+
+// ServiceAccountRecord is a service account as stored in the keel_service_account table.
+type ServiceAccountRecord struct {
+	Id         string
+	Name       string
+	ClientId   string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// CreateServiceAccount creates a service account with a newly generated client ID and secret. Only a hash of
+// the secret is stored, so the secret which is returned cannot be retrieved again.
+func CreateServiceAccount(ctx context.Context, name string, scopes []string) (*ServiceAccountRecord, string, error) {
+	ctx, span := tracer.Start(ctx, "Create Service Account")
+	defer span.End()
+
+	if name == "" {
+		return nil, "", errors.New("name cannot be empty when creating a service account")
+	}
+
+	secret := uniuri.NewLen(serviceAccountClientSecretLength)
+	hash, err := hashToken(secret)
+	if err != nil {
+		return nil, "", err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	account := &ServiceAccountRecord{
+		Id:        ksuid.New().String(),
+		Name:      name,
+		ClientId:  serviceAccountClientIdPrefix + strings.ToLower(uniuri.NewLen(serviceAccountClientIdLength)),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	sql := `
+		INSERT INTO
+			keel_service_account (id, name, client_id, secret_hash, scopes, created_at)
+		VALUES
+			(?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO NOTHING`
+
+	result := database.GetDB().Exec(sql, account.Id, account.Name, account.ClientId, hash, strings.Join(scopes, " "), account.CreatedAt)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+
+	if result.RowsAffected != 1 {
+		return nil, "", ErrServiceAccountExists
+	}
+
+	return account, secret, nil
+}
+
+// ListServiceAccounts returns all service accounts, including those defined in config which have authenticated
+// at least once and those which have been revoked.
+func ListServiceAccounts(ctx context.Context) ([]*ServiceAccountRecord, error) {
+	ctx, span := tracer.Start(ctx, "List Service Accounts")
+	defer span.End()
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := `
+		SELECT
+			id, name, client_id, scopes, created_at, last_used_at, revoked_at
+		FROM
+			keel_service_account
+		ORDER BY
+			name`
+
+	rows := []map[string]any{}
+	err = database.GetDB().Raw(sql).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := []*ServiceAccountRecord{}
+	for _, row := range rows {
+		account := &ServiceAccountRecord{
+			Id:       row["id"].(string),
+			Name:     row["name"].(string),
+			ClientId: row["client_id"].(string),
+			Scopes:   strings.Fields(row["scopes"].(string)),
+		}
+
+		if t, ok := row["created_at"].(time.Time); ok {
+			account.CreatedAt = t
+		}
+		if t, ok := row["last_used_at"].(time.Time); ok {
+			account.LastUsedAt = &t
+		}
+		if t, ok := row["revoked_at"].(time.Time); ok {
+			account.RevokedAt = &t
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// RevokeServiceAccount revokes the service account with the given name, after which it cannot be issued
+// access tokens and any access tokens it has already been issued are rejected. Returns false if there is
+// no service account with this name which has not already been revoked.
+func RevokeServiceAccount(ctx context.Context, name string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "Revoke Service Account")
+	defer span.End()
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	sql := `
+		UPDATE
+			keel_service_account
+		SET
+			revoked_at = now()
+		WHERE
+			name = ? AND
+			revoked_at IS NULL`
+
+	result := database.GetDB().Exec(sql, name)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// AuthenticateServiceAccount checks the client credentials of a service account, either one created with
+// CreateServiceAccount or one defined in config, whose client ID is its name and whose client secret is kept
+// in a project secret. Returns nil if the credentials are incorrect or the service account has been revoked.
+func AuthenticateServiceAccount(ctx context.Context, clientId string, clientSecret string) (*auth.ServiceAccount, error) {
+	ctx, span := tracer.Start(ctx, "Authenticate Service Account")
+	defer span.End()
+
+	if clientId == "" || clientSecret == "" {
+		return nil, nil
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []map[string]any{}
+
+	if configured := config.GetServiceAccount(clientId); configured != nil {
+		secret, err := runtimectx.GetSecret(ctx, configured.GetClientSecretName())
+		if err != nil || subtle.ConstantTimeCompare([]byte(secret), []byte(clientSecret)) != 1 {
+			return nil, nil
+		}
+
+		// Service accounts defined in config are stored when they first authenticate so that
+		// they can be revoked and their usage tracked like any other. Their client ID is their name,
+		// so a conflict on the name is only the same account if the client ID matches too.
+		sql := `
+			INSERT INTO
+				keel_service_account (id, name, client_id, scopes, created_at)
+			VALUES
+				(?, ?, ?, ?, now())
+			ON CONFLICT (name) DO UPDATE SET
+				scopes = EXCLUDED.scopes
+			WHERE
+				keel_service_account.client_id = EXCLUDED.client_id
+			RETURNING
+				id, name, scopes, revoked_at`
+
+		err = database.GetDB().Raw(sql, ksuid.New().String(), configured.Name, configured.Name, strings.Join(configured.Scopes, " ")).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("%w: %s is defined in config but has also been created with a generated client ID", ErrServiceAccountExists, configured.Name)
+		}
+	} else {
+		hash, err := hashToken(clientSecret)
+		if err != nil {
+			return nil, err
+		}
+
+		sql := `
+			SELECT
+				id, name, scopes, revoked_at
+			FROM
+				keel_service_account
+			WHERE
+				client_id = ? AND
+				secret_hash = ?`
+
+		err = database.GetDB().Raw(sql, clientId, hash).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(rows) != 1 || rows[0]["revoked_at"] != nil {
+		return nil, nil
+	}
+
+	return &auth.ServiceAccount{
+		Id:     rows[0]["id"].(string),
+		Name:   rows[0]["name"].(string),
+		Scopes: strings.Fields(rows[0]["scopes"].(string)),
+	}, nil
+}
+
+// GenerateServiceAccountToken generates an access token for the service account which grants its scopes.
+func GenerateServiceAccountToken(ctx context.Context, account *auth.ServiceAccount) (string, time.Duration, error) {
+	if account == nil || account.Id == "" {
+		return "", 0, errors.New("cannot generate access token without a service account intended for the sub claim")
+	}
+
+	config, err := runtimectx.GetOAuthConfig(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+
+	expiry := config.AccessTokenExpiry()
+
+	claims := newClaims(account.Id, []string{serviceAccountAudClaim}, expiry)
+	claims.Scope = strings.Join(account.Scopes, " ")
+
+	token, err := signToken(ctx, claims)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return token, expiry, nil
+}
+
+// IsServiceAccountToken returns true if the token claims to have been issued to a service account. The token is
+// not verified, so it must still be validated with ValidateServiceAccountToken.
+func IsServiceAccountToken(tokenString string) bool {
+	claims := &AccessTokenClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(tokenString, claims)
+	if err != nil {
+		return false
+	}
+
+	return lo.Contains(claims.Audience, serviceAccountAudClaim)
+}
+
+// ValidateServiceAccountToken validates an access token issued to a service account and records that the service
+// account has been used, at most once every serviceAccountLastUsedInterval. The scopes granted by the token are limited to those
+// the service account still has.
+func ValidateServiceAccountToken(ctx context.Context, tokenString string) (*auth.ServiceAccount, error) {
+	claims, err := validateClaims(ctx, tokenString, serviceAccountAudClaim)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account is read on every request but only written to when last_used_at is out of date,
+	// so that busy service accounts are not all contending to update the same row.
+	sql := `
+		WITH account AS (
+			SELECT
+				id, name, scopes, last_used_at
+			FROM
+				keel_service_account
+			WHERE
+				id = ? AND
+				revoked_at IS NULL
+		), used AS (
+			UPDATE
+				keel_service_account
+			SET
+				last_used_at = now()
+			FROM
+				account
+			WHERE
+				keel_service_account.id = account.id AND
+				(account.last_used_at IS NULL OR account.last_used_at < now() - make_interval(secs => ?))
+		)
+		SELECT
+			id, name, scopes
+		FROM
+			account`
+
+	rows := []map[string]any{}
+	err = database.GetDB().Raw(sql, claims.Subject, serviceAccountLastUsedInterval.Seconds()).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update service account: %w", err)
+	}
+
+	if len(rows) != 1 {
+		return nil, ErrServiceAccountRevoked
+	}
+
+	current := strings.Fields(rows[0]["scopes"].(string))
+	scopes := lo.Filter(strings.Fields(claims.Scope), func(s string, _ int) bool {
+		return slices.Contains(current, s)
+	})
+
+	return &auth.ServiceAccount{
+		Id:     rows[0]["id"].(string),
+		Name:   rows[0]["name"].(string),
+		Scopes: scopes,
+	}, nil
+}
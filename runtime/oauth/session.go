@@ -0,0 +1,222 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/runtime/common"
+)
+
+var (
+	ErrSessionRevoked = common.NewAuthenticationFailedMessageErr("session has been revoked")
+)
+
+// Session is a signed in device, as stored in the keel_session table. Each refresh token issued to the
+// identity belongs to a session, which is carried through refresh token rotation.
+type Session struct {
+	Id         string
+	IdentityId string
+	UserAgent  string
+	IpAddress  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+// NewSession records a new session for the identity on the device with the given user agent and IP address.
+func NewSession(ctx context.Context, identityId string, userAgent string, ipAddress string) (string, error) {
+	ctx, span := tracer.Start(ctx, "New Session")
+	defer span.End()
+
+	if identityId == "" {
+		return "", errors.New("identity ID cannot be empty when creating a new session")
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	sessionId := ksuid.New().String()
+
+	sql := `
+		INSERT INTO
+			keel_session (id, identity_id, user_agent, ip_address, created_at, last_used_at)
+		VALUES
+			(?, ?, ?, ?, now(), now())`
+
+	result := database.GetDB().Exec(sql, sessionId, identityId, userAgent, ipAddress)
+	if result.Error != nil {
+		return "", result.Error
+	}
+
+	if result.RowsAffected != 1 {
+		return "", errors.New("failed to insert session into database")
+	}
+
+	return sessionId, nil
+}
+
+// ListSessions returns the identity's sessions which have neither been revoked nor outlived their refresh tokens,
+// most recently used first.
+func ListSessions(ctx context.Context, identityId string) ([]*Session, error) {
+	ctx, span := tracer.Start(ctx, "List Sessions")
+	defer span.End()
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := `
+		SELECT
+			s.id, s.identity_id, s.user_agent, s.ip_address, s.created_at, s.last_used_at
+		FROM
+			keel_session s
+		WHERE
+			s.identity_id = ? AND
+			s.revoked_at IS NULL AND
+			EXISTS (SELECT 1 FROM keel_refresh_token t WHERE t.session_id = s.id AND t.expires_at >= now())
+		ORDER BY
+			s.last_used_at DESC`
+
+	rows := []map[string]any{}
+	err = database.GetDB().Raw(sql, identityId).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []*Session{}
+	for _, row := range rows {
+		session := &Session{
+			Id:         row["id"].(string),
+			IdentityId: row["identity_id"].(string),
+		}
+
+		if s, ok := row["user_agent"].(string); ok {
+			session.UserAgent = s
+		}
+		if s, ok := row["ip_address"].(string); ok {
+			session.IpAddress = s
+		}
+		if t, ok := row["created_at"].(time.Time); ok {
+			session.CreatedAt = t
+		}
+		if t, ok := row["last_used_at"].(time.Time); ok {
+			session.LastUsedAt = t
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the identity's sessions along with its refresh tokens, and returns false if the
+// identity has no such session which is yet to be revoked. Access tokens issued in the session are rejected from then on.
+func RevokeSession(ctx context.Context, identityId string, sessionId string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "Revoke Session")
+	defer span.End()
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	revoked := false
+	err = database.Transaction(ctx, func(ctx context.Context) error {
+		result, err := database.ExecuteStatement(ctx, "UPDATE keel_session SET revoked_at = now() WHERE id = ? AND identity_id = ? AND revoked_at IS NULL", sessionId, identityId)
+		if err != nil {
+			return err
+		}
+
+		revoked = result.RowsAffected == 1
+
+		_, err = database.ExecuteStatement(ctx, "DELETE FROM keel_refresh_token WHERE session_id = ? AND identity_id = ?", sessionId, identityId)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
+
+// RevokeAllSessions revokes every session of the identity, signing it out on all devices, and returns the
+// number of sessions which were revoked. Refresh tokens issued before sessions were recorded are also revoked.
+func RevokeAllSessions(ctx context.Context, identityId string) (int64, error) {
+	ctx, span := tracer.Start(ctx, "Revoke All Sessions")
+	defer span.End()
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var revoked int64
+	err = database.Transaction(ctx, func(ctx context.Context) error {
+		result, err := database.ExecuteStatement(ctx, "UPDATE keel_session SET revoked_at = now() WHERE identity_id = ? AND revoked_at IS NULL", identityId)
+		if err != nil {
+			return err
+		}
+
+		revoked = result.RowsAffected
+
+		_, err = database.ExecuteStatement(ctx, "DELETE FROM keel_refresh_token WHERE identity_id = ?", identityId)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
+
+// sessionUseInterval is how often the time a session was last used is updated, so that it is not written on every request.
+const sessionUseInterval = time.Minute
+
+// useSession returns ErrSessionRevoked if the session has been revoked, and otherwise records that it has been used if
+// it was last used longer ago than sessionUseInterval.
+func useSession(ctx context.Context, sessionId string) error {
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	sql := `
+		SELECT
+			revoked_at IS NOT NULL AS revoked,
+			last_used_at < now() - make_interval(secs => ?) AS stale
+		FROM
+			keel_session
+		WHERE
+			id = ?`
+
+	rows := []map[string]any{}
+	err = database.GetDB().Raw(sql, sessionUseInterval.Seconds(), sessionId).Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	if len(rows) != 1 || rows[0]["revoked"] == true {
+		return ErrSessionRevoked
+	}
+
+	if rows[0]["stale"] != true {
+		return nil
+	}
+
+	sql = `
+		UPDATE
+			keel_session
+		SET
+			last_used_at = now()
+		WHERE
+			id = ? AND
+			revoked_at IS NULL AND
+			last_used_at < now() - make_interval(secs => ?)`
+
+	return database.GetDB().Exec(sql, sessionId, sessionUseInterval.Seconds()).Error
+}
@@ -0,0 +1,225 @@
+package oauth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/runtime/oauth"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+func TestNewSession_Listed(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	sessionId, err := oauth.NewSession(ctx, "identity_id", "Mozilla/5.0", "203.0.113.1")
+	require.NoError(t, err)
+	require.NotEmpty(t, sessionId)
+
+	// A session is only listed once it has a refresh token
+	sessions, err := oauth.ListSessions(ctx, "identity_id")
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	_, err = oauth.NewRefreshToken(ctx, "identity_id", sessionId)
+	require.NoError(t, err)
+
+	sessions, err = oauth.ListSessions(ctx, "identity_id")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, sessionId, sessions[0].Id)
+	require.Equal(t, "identity_id", sessions[0].IdentityId)
+	require.Equal(t, "Mozilla/5.0", sessions[0].UserAgent)
+	require.Equal(t, "203.0.113.1", sessions[0].IpAddress)
+	require.False(t, sessions[0].CreatedAt.IsZero())
+	require.False(t, sessions[0].LastUsedAt.IsZero())
+
+	sessions, err = oauth.ListSessions(ctx, "other_identity_id")
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestNewSession_ErrorOnEmptyIdentityId(t *testing.T) {
+	ctx := t.Context()
+
+	_, err := oauth.NewSession(ctx, "", "", "")
+	require.Error(t, err)
+}
+
+func TestRotateRefreshToken_KeepsSession(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	sessionId, err := oauth.NewSession(ctx, "identity_id", "", "")
+	require.NoError(t, err)
+
+	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id", sessionId)
+	require.NoError(t, err)
+
+	isValid, newRefreshToken, identityId, rotatedSessionId, err := oauth.RotateRefreshToken(ctx, refreshToken)
+	require.NoError(t, err)
+	require.True(t, isValid)
+	require.Equal(t, "identity_id", identityId)
+	require.Equal(t, sessionId, rotatedSessionId)
+
+	isValid, identityId, validatedSessionId, err := oauth.ValidateRefreshToken(ctx, newRefreshToken)
+	require.NoError(t, err)
+	require.True(t, isValid)
+	require.Equal(t, "identity_id", identityId)
+	require.Equal(t, sessionId, validatedSessionId)
+}
+
+func TestRevokeSession_RevokesTokens(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	sessionId1, err := oauth.NewSession(ctx, "identity_id", "", "")
+	require.NoError(t, err)
+	refreshToken1, err := oauth.NewRefreshToken(ctx, "identity_id", sessionId1)
+	require.NoError(t, err)
+	accessToken1, _, err := oauth.GenerateSessionAccessToken(ctx, "identity_id", sessionId1)
+	require.NoError(t, err)
+
+	sessionId2, err := oauth.NewSession(ctx, "identity_id", "", "")
+	require.NoError(t, err)
+	refreshToken2, err := oauth.NewRefreshToken(ctx, "identity_id", sessionId2)
+	require.NoError(t, err)
+	accessToken2, _, err := oauth.GenerateSessionAccessToken(ctx, "identity_id", sessionId2)
+	require.NoError(t, err)
+
+	// Another identity cannot revoke the session
+	revoked, err := oauth.RevokeSession(ctx, "other_identity_id", sessionId1)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	revoked, err = oauth.RevokeSession(ctx, "identity_id", sessionId1)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = oauth.RevokeSession(ctx, "identity_id", sessionId1)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	isValid, _, _, err := oauth.ValidateRefreshToken(ctx, refreshToken1)
+	require.NoError(t, err)
+	require.False(t, isValid)
+
+	_, err = oauth.ValidateAccessToken(ctx, accessToken1)
+	require.ErrorIs(t, err, oauth.ErrSessionRevoked)
+
+	// The other session is unaffected
+	isValid, _, _, err = oauth.ValidateRefreshToken(ctx, refreshToken2)
+	require.NoError(t, err)
+	require.True(t, isValid)
+
+	identityId, sessionId, err := oauth.ValidateSessionAccessToken(ctx, accessToken2)
+	require.NoError(t, err)
+	require.Equal(t, "identity_id", identityId)
+	require.Equal(t, sessionId2, sessionId)
+
+	sessions, err := oauth.ListSessions(ctx, "identity_id")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, sessionId2, sessions[0].Id)
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	accessTokens := []string{}
+	for i := 0; i < 2; i++ {
+		sessionId, err := oauth.NewSession(ctx, "identity_id", "", "")
+		require.NoError(t, err)
+		_, err = oauth.NewRefreshToken(ctx, "identity_id", sessionId)
+		require.NoError(t, err)
+		accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, "identity_id", sessionId)
+		require.NoError(t, err)
+		accessTokens = append(accessTokens, accessToken)
+	}
+
+	// Refresh tokens issued before sessions were recorded are also revoked
+	legacyRefreshToken, err := oauth.NewRefreshToken(ctx, "identity_id", "")
+	require.NoError(t, err)
+
+	otherSessionId, err := oauth.NewSession(ctx, "other_identity_id", "", "")
+	require.NoError(t, err)
+	otherRefreshToken, err := oauth.NewRefreshToken(ctx, "other_identity_id", otherSessionId)
+	require.NoError(t, err)
+
+	revoked, err := oauth.RevokeAllSessions(ctx, "identity_id")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), revoked)
+
+	for _, accessToken := range accessTokens {
+		_, err = oauth.ValidateAccessToken(ctx, accessToken)
+		require.ErrorIs(t, err, oauth.ErrSessionRevoked)
+	}
+
+	isValid, _, _, err := oauth.ValidateRefreshToken(ctx, legacyRefreshToken)
+	require.NoError(t, err)
+	require.False(t, isValid)
+
+	sessions, err := oauth.ListSessions(ctx, "identity_id")
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	isValid, _, _, err = oauth.ValidateRefreshToken(ctx, otherRefreshToken)
+	require.NoError(t, err)
+	require.True(t, isValid)
+}
+
+func TestRevokeRefreshToken_RevokesSession(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	sessionId, err := oauth.NewSession(ctx, "identity_id", "", "")
+	require.NoError(t, err)
+	refreshToken, err := oauth.NewRefreshToken(ctx, "identity_id", sessionId)
+	require.NoError(t, err)
+	accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, "identity_id", sessionId)
+	require.NoError(t, err)
+
+	err = oauth.RevokeRefreshToken(ctx, refreshToken)
+	require.NoError(t, err)
+
+	_, err = oauth.ValidateAccessToken(ctx, accessToken)
+	require.ErrorIs(t, err, oauth.ErrSessionRevoked)
+}
+
+func TestValidateSessionAccessToken_RecordsLastUsed(t *testing.T) {
+	ctx, database, _ := keeltesting.MakeContext(t, t.Context(), authTestSchema, true)
+	defer database.Close()
+
+	sessionId, err := oauth.NewSession(ctx, "identity_id", "", "")
+	require.NoError(t, err)
+	_, err = oauth.NewRefreshToken(ctx, "identity_id", sessionId)
+	require.NoError(t, err)
+	accessToken, _, err := oauth.GenerateSessionAccessToken(ctx, "identity_id", sessionId)
+	require.NoError(t, err)
+
+	// Not recorded again when the session was used within the last minute
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_session SET last_used_at = now() - INTERVAL '30 seconds' WHERE id = ?", sessionId)
+	require.NoError(t, err)
+
+	_, err = oauth.ValidateAccessToken(ctx, accessToken)
+	require.NoError(t, err)
+
+	sessions, err := oauth.ListSessions(ctx, "identity_id")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.WithinDuration(t, time.Now().Add(-30*time.Second), sessions[0].LastUsedAt, 5*time.Second)
+
+	// Recorded when it was last used longer ago
+	_, err = database.ExecuteStatement(ctx, "UPDATE keel_session SET last_used_at = now() - INTERVAL '2 minutes' WHERE id = ?", sessionId)
+	require.NoError(t, err)
+
+	_, err = oauth.ValidateAccessToken(ctx, accessToken)
+	require.NoError(t, err)
+
+	sessions, err = oauth.ListSessions(ctx, "identity_id")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.WithinDuration(t, time.Now(), sessions[0].LastUsedAt, 5*time.Second)
+}
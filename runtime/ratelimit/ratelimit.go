@@ -0,0 +1,268 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/db"
+	"github.com/teamkeel/keel/proto"
+	"github.com/teamkeel/keel/runtime/auth"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/schema/parser"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var tracer = otel.Tracer("github.com/teamkeel/keel/runtime/ratelimit")
+
+// TableName is the keel-managed table in which the token buckets are stored,
+// so that limits are shared by every instance of the runtime.
+const TableName = "keel_rate_limit"
+
+// refillSql refills the bucket for the time elapsed since it was last updated, without taking a token, and locks it
+// until the end of the transaction so that concurrent requests cannot take the same token. A new bucket starts full.
+// The bucket is marked as not allowing the request until its token is taken. The arguments are the key, capacity,
+// refill rate per second and the tokens in a new bucket.
+var refillSql = fmt.Sprintf(`
+	INSERT INTO %[1]s AS b (key, capacity, rate, tokens, allowed, updated_at)
+	VALUES (?, ?, ?, ?, false, now())
+	ON CONFLICT (key) DO UPDATE SET
+		tokens = LEAST(EXCLUDED.capacity, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * EXCLUDED.rate),
+		allowed = false,
+		updated_at = now()
+	RETURNING tokens`,
+	TableName,
+)
+
+// takeTokenSql takes a token from a bucket which has just been refilled.
+var takeTokenSql = fmt.Sprintf(`UPDATE %s SET tokens = tokens - 1, allowed = true WHERE key = ?`, TableName)
+
+// bucket is a token bucket from which a request takes a token.
+type bucket struct {
+	key      string
+	capacity float64
+	// Tokens added per second
+	rate float64
+}
+
+// sweepInterval is how often each instance of the runtime clears out buckets which have refilled.
+const sweepInterval = time.Minute
+
+// lastSweep is when this instance of the runtime last cleared out refilled buckets, in Unix nanoseconds.
+var lastSweep atomic.Int64
+
+// sweepSql deletes the buckets which have not been used for as long as it takes to refill an empty one, as they
+// are full again and so are no different to a new bucket.
+var sweepSql = fmt.Sprintf(`
+	DELETE FROM %s
+	WHERE updated_at + make_interval(secs => capacity / rate) < now()`,
+	TableName,
+)
+
+// Check takes a token from the bucket of every rate limit rule which applies to the action in the API for
+// the identity or IP address making the request. If any bucket is empty then no tokens are taken and a
+// common.RateLimitError is returned with the time until every bucket will have a token.
+func Check(ctx context.Context, api *proto.Api, action *proto.Action) error {
+	rules, err := runtimectx.GetRateLimits(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	ctx, span := tracer.Start(ctx, "Rate Limit")
+	defer span.End()
+
+	buckets := []bucket{}
+	for i, rule := range rules {
+		if !rule.Matches(api.GetName(), action.GetName()) {
+			continue
+		}
+
+		subject, err := subject(ctx, &rule)
+		if err != nil {
+			return err
+		}
+
+		// Without an identity or IP address there is nothing to count requests against
+		if subject == "" {
+			continue
+		}
+
+		// The rule's index is part of the key so that rules for the same API and action have their own buckets
+		capacity := float64(rule.Limit)
+		buckets = append(buckets, bucket{
+			key:      fmt.Sprintf("%d/%s/%s/%s", i, rule.Api, rule.Action, subject),
+			capacity: capacity,
+			rate:     capacity / float64(rule.Period),
+		})
+	}
+
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = sweep(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	retryAfter, err := takeTokens(ctx, database, buckets)
+	if err != nil {
+		return err
+	}
+
+	if retryAfter > 0 {
+		span.SetAttributes(attribute.Bool("rate_limited", true))
+		return common.NewRateLimitError(retryAfter)
+	}
+
+	return nil
+}
+
+// Take takes a token from the bucket with the key, which holds limit tokens and is refilled over the period. This
+// limits something other than the requests to an action, such as the emails sent to an address. If the bucket is
+// empty then a common.RateLimitError is returned with the time until a token will be available.
+func Take(ctx context.Context, key string, limit int, period time.Duration) error {
+	database, err := db.GetDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = sweep(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	capacity := float64(limit)
+	wait, err := takeTokens(ctx, database, []bucket{{key: key, capacity: capacity, rate: capacity / period.Seconds()}})
+	if err != nil {
+		return err
+	}
+
+	if wait > 0 {
+		return common.NewRateLimitError(wait)
+	}
+
+	return nil
+}
+
+// takeTokens takes a token from every bucket, but only if each of them has one, so that a request which is refused
+// does not use up the tokens in the buckets which would have allowed it. If any bucket is empty then it returns how
+// long until every bucket will have a token, or zero if the tokens were taken. The buckets are locked in the order
+// of their keys so that concurrent requests for the same buckets cannot deadlock.
+func takeTokens(ctx context.Context, database db.Database, buckets []bucket) (time.Duration, error) {
+	slices.SortFunc(buckets, func(a, b bucket) int {
+		return strings.Compare(a.key, b.key)
+	})
+
+	var wait time.Duration
+	err := database.Transaction(ctx, func(ctx context.Context) error {
+		for _, b := range buckets {
+			result, err := database.ExecuteQuery(ctx, refillSql, b.key, b.capacity, b.rate, b.capacity)
+			if err != nil {
+				return err
+			}
+
+			tokens, _ := result.Rows[0]["tokens"].(float64)
+			if tokens < 1 {
+				wait = max(wait, time.Duration((1-tokens)/b.rate*float64(time.Second)))
+			}
+		}
+
+		if wait > 0 {
+			return nil
+		}
+
+		for _, b := range buckets {
+			_, err := database.ExecuteStatement(ctx, takeTokenSql, b.key)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return wait, nil
+}
+
+// subject is who requests are counted against for the rule.
+func subject(ctx context.Context, rule *config.RateLimitConfig) (string, error) {
+	if !rule.ByIP() && auth.IsAuthenticated(ctx) {
+		identity, err := auth.GetIdentity(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("identity:%s", identity[parser.FieldNameId]), nil
+	}
+
+	if !rule.ByIP() && auth.IsServiceAccount(ctx) {
+		account, err := auth.GetServiceAccount(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("service-account:%s", account.Id), nil
+	}
+
+	ip := runtimectx.GetClientIP(ctx)
+	if ip == "" {
+		return "", nil
+	}
+
+	return fmt.Sprintf("ip:%s", ip), nil
+}
+
+// ClientIP returns the IP address of the client which made the request. Behind a load balancer
+// or API gateway this is the last address in the X-Forwarded-For header, which is the one appended
+// by the load balancer itself. Any earlier addresses are set by the client and cannot be trusted.
+func ClientIP(r *http.Request) string {
+	if forwarded := strings.Join(r.Header.Values("X-Forwarded-For"), ","); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			if ip := strings.TrimSpace(hops[i]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// sweep deletes the buckets which have refilled. It runs at most once every sweepInterval on each instance
+// of the runtime, rather than on every request.
+func sweep(ctx context.Context, database db.Database) error {
+	now := time.Now().UnixNano()
+	last := lastSweep.Load()
+	if now-last < int64(sweepInterval) || !lastSweep.CompareAndSwap(last, now) {
+		return nil
+	}
+
+	_, err := database.ExecuteStatement(ctx, sweepSql)
+	return err
+}
@@ -0,0 +1,24 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/runtime/ratelimit"
+)
+
+func TestClientIP(t *testing.T) {
+	t.Parallel()
+
+	r := &http.Request{RemoteAddr: "10.0.0.1:52311", Header: http.Header{}}
+	require.Equal(t, "10.0.0.1", ratelimit.ClientIP(r))
+
+	// Only the last address is added by the load balancer, the others can be set by the client
+	r.Header.Set("X-Forwarded-For", "203.0.113.7, 198.51.100.4")
+	require.Equal(t, "198.51.100.4", ratelimit.ClientIP(r))
+
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+	r.Header.Add("X-Forwarded-For", "198.51.100.9")
+	require.Equal(t, "198.51.100.9", ratelimit.ClientIP(r))
+}
@@ -2,10 +2,14 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/iancoleman/strcase"
@@ -22,7 +26,10 @@ import (
 	"github.com/teamkeel/keel/runtime/apis/jsonrpc"
 	"github.com/teamkeel/keel/runtime/apis/tasksapi"
 	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/idempotency"
+	"github.com/teamkeel/keel/runtime/ratelimit"
 	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/storage"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -47,9 +54,12 @@ func NewHttpHandler(currSchema *proto.Schema) http.Handler {
 	var flowsHandler common.HandlerFunc
 	var tasksHandler common.HandlerFunc
 	var authHandler func(http.ResponseWriter, *http.Request) common.Response
+	var subscriptionsHandler http.HandlerFunc
 	var router *httprouter.Router
+	filesHandler := NewFilesHandler()
 	if currSchema != nil {
 		apiHandler = NewApiHandler(currSchema)
+		subscriptionsHandler = NewSubscriptionsHandler(currSchema)
 		flowsHandler = NewFlowsHandler(currSchema)
 		authHandler = NewAuthHandler(currSchema)
 		tasksHandler = NewTasksHandler(currSchema)
@@ -64,7 +74,7 @@ func NewHttpHandler(currSchema *proto.Schema) http.Handler {
 			attribute.String("runtime_version", Version),
 		)
 
-		if apiHandler == nil || authHandler == nil || flowsHandler == nil || tasksHandler == nil {
+		if apiHandler == nil || authHandler == nil || flowsHandler == nil || tasksHandler == nil || subscriptionsHandler == nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			_, _ = w.Write([]byte("cannot serve requests when handlers are not set up"))
 			return
@@ -72,6 +82,13 @@ func NewHttpHandler(currSchema *proto.Schema) http.Handler {
 
 		r = r.WithContext(ctx)
 
+		// WebSocket connections take over the response writer, so they are
+		// handled separately to the request/response APIs.
+		if graphql.IsWebSocketUpgrade(r) {
+			subscriptionsHandler(w, r)
+			return
+		}
+
 		var response common.Response
 		path := r.URL.Path
 		switch {
@@ -81,6 +98,8 @@ func NewHttpHandler(currSchema *proto.Schema) http.Handler {
 			response = flowsHandler(r)
 		case strings.HasPrefix(path, "/auth"):
 			response = authHandler(w, r)
+		case strings.HasPrefix(path, storage.FilesystemFilesPath) && servesFiles(ctx):
+			response = filesHandler(r)
 		default:
 			response = apiHandler(r)
 		}
@@ -119,6 +138,14 @@ func NewAuthHandler(schema *proto.Schema) func(http.ResponseWriter, *http.Reques
 	handleProviders := authapi.ProvidersHandler(schema)
 	handleToken := authapi.TokenEndpointHandler(schema)
 	handleRevoke := authapi.RevokeHandler(schema)
+	handlePasswordless := authapi.PasswordlessHandler(schema)
+	handleVerifyEmail := authapi.VerifyEmailHandler(schema)
+	handleResendVerification := authapi.ResendVerificationHandler(schema)
+	handleMfaEnrol := authapi.MfaEnrolHandler(schema)
+	handleMfaConfirm := authapi.MfaConfirmHandler(schema)
+	handleMfaDisable := authapi.MfaDisableHandler(schema)
+	handleSessions := authapi.SessionsHandler(schema)
+	handleRevokeSessions := authapi.RevokeSessionsHandler(schema)
 	handleAuthorize := authapi.AuthorizeHandler(schema)
 	handleCallback := authapi.CallbackHandler(schema)
 	handleOpenApiRequest := authapi.OAuthOpenApiSchema()
@@ -130,7 +157,9 @@ func NewAuthHandler(schema *proto.Schema) func(http.ResponseWriter, *http.Reques
 		for k := range r.Header {
 			headers[k] = r.Header.Values(k)
 		}
-		r = r.WithContext(runtimectx.WithRequestHeaders(r.Context(), headers))
+		ctx := runtimectx.WithRequestHeaders(r.Context(), headers)
+		ctx = runtimectx.WithClientIP(ctx, ratelimit.ClientIP(r))
+		r = r.WithContext(ctx)
 
 		switch {
 		case r.URL.Path == "/auth/providers":
@@ -139,6 +168,22 @@ func NewAuthHandler(schema *proto.Schema) func(http.ResponseWriter, *http.Reques
 			return handleToken(r)
 		case r.URL.Path == "/auth/revoke":
 			return handleRevoke(r)
+		case r.URL.Path == "/auth/passwordless":
+			return handlePasswordless(r)
+		case r.URL.Path == "/auth/verify":
+			return handleVerifyEmail(r)
+		case r.URL.Path == "/auth/verify/resend":
+			return handleResendVerification(r)
+		case r.URL.Path == "/auth/mfa/enrol":
+			return handleMfaEnrol(r)
+		case r.URL.Path == "/auth/mfa/confirm":
+			return handleMfaConfirm(r)
+		case r.URL.Path == "/auth/mfa/disable":
+			return handleMfaDisable(r)
+		case r.URL.Path == "/auth/sessions":
+			return handleSessions(r)
+		case r.URL.Path == "/auth/sessions/revoke":
+			return handleRevokeSessions(r)
 		case strings.HasPrefix(r.URL.Path, "/auth/authorize"):
 			return handleAuthorize(r)
 		case strings.HasPrefix(r.URL.Path, "/auth/callback"):
@@ -160,15 +205,26 @@ func NewApiHandler(s *proto.Schema) common.HandlerFunc {
 	for _, api := range s.GetApis() {
 		root := "/" + strings.ToLower(api.GetName())
 
-		handlers[root+"/graphql"] = graphql.NewHandler(s, api)
-		handlers[root+"/rpc"] = jsonrpc.NewHandler(s, api)
+		handlers[root+"/graphql"] = idempotency.Handler(s, graphql.NewHandler(s, api), func(r *http.Request, body []byte) bool {
+			return graphql.IsReadOnlyRequest(body)
+		})
+		handlers[root+"/rpc"] = idempotency.Handler(s, jsonrpc.NewHandler(s, api), func(r *http.Request, body []byte) bool {
+			return jsonrpc.IsReadOnlyRequest(s, body)
+		})
 
 		httpJson := httpjson.NewHandler(s, api)
+		idempotentHttpJson := idempotency.Handler(s, httpJson, nil)
 
 		for _, name := range proto.GetActionNamesForApi(s, api) {
-			handlers[root+"/json/"+strings.ToLower(name)] = httpJson
+			// Actions which only read data are safe to repeat so do not need an idempotency key
+			if action := s.FindAction(name); action != nil && action.IsReadAction() {
+				handlers[root+"/json/"+strings.ToLower(name)] = httpJson
+			} else {
+				handlers[root+"/json/"+strings.ToLower(name)] = idempotentHttpJson
+			}
 		}
 		handlers[root+"/json/openapi.json"] = httpJson
+		handlers[root+"/uploads"] = httpjson.NewUploadHandler(s, api)
 	}
 
 	return withRequestResponseLogging(func(r *http.Request) common.Response {
@@ -189,12 +245,42 @@ func NewApiHandler(s *proto.Schema) common.HandlerFunc {
 			headers[k] = r.Header.Values(k)
 		}
 		ctx = runtimectx.WithRequestHeaders(ctx, headers)
+		ctx = runtimectx.WithClientIP(ctx, ratelimit.ClientIP(r))
 		r = r.WithContext(ctx)
 
 		return handler(r)
 	})
 }
 
+// NewSubscriptionsHandler handles WebSocket requests for GraphQL subscriptions to the customer APIs.
+func NewSubscriptionsHandler(s *proto.Schema) http.HandlerFunc {
+	handlers := map[string]http.HandlerFunc{}
+
+	for _, api := range s.GetApis() {
+		root := "/" + strings.ToLower(api.GetName())
+		handlers[root+"/graphql"] = graphql.NewSubscriptionHandler(s, api)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := handlers[strings.ToLower(r.URL.Path)]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("Not found"))
+			return
+		}
+
+		// Collect request headers and add to runtime context
+		// These are exposed in custom functions and in expressions
+		headers := map[string][]string{}
+		for k := range r.Header {
+			headers[k] = r.Header.Values(k)
+		}
+		ctx := runtimectx.WithRequestHeaders(r.Context(), headers)
+
+		handler(w, r.WithContext(ctx))
+	}
+}
+
 // NewFlowsHandler handles requests to the customer flows.
 func NewFlowsHandler(s *proto.Schema) common.HandlerFunc {
 	defaultFlowHandler := flowsapi.FlowHandler(s)
@@ -258,6 +344,149 @@ func NewTasksHandler(s *proto.Schema) common.HandlerFunc {
 	})
 }
 
+// NewFilesHandler handles requests to download and upload files which are stored on the local filesystem.
+// Each request must carry a valid signature, as generated by the store when hydrating a file or
+// when generating an upload URL.
+func NewFilesHandler() common.HandlerFunc {
+	return func(r *http.Request) common.Response {
+		ctx, span := tracer.Start(r.Context(), "Serve File")
+		defer span.End()
+
+		store, err := runtimectx.GetStorage(ctx)
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		fsStore, ok := store.(*storage.FilesystemStore)
+		if !ok {
+			return common.Response{
+				Status: http.StatusNotFound,
+			}
+		}
+
+		query := r.URL.Query()
+
+		// Files uploaded directly to storage, using a URL from the upload endpoint
+		if uploadKey, ok := strings.CutPrefix(r.URL.Path, storage.FilesystemFilesPath+storage.UploadObjectPrefix); ok {
+			if r.Method != http.MethodPut {
+				return common.Response{
+					Status: http.StatusMethodNotAllowed,
+				}
+			}
+
+			err = fsStore.VerifyUploadSignature(uploadKey, query.Get("expires"), query.Get("signature"))
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+				return common.Response{
+					Status: http.StatusForbidden,
+					Body:   []byte(err.Error()),
+				}
+			}
+
+			err = fsStore.WriteUpload(ctx, uploadKey, r.Body)
+			if errors.Is(err, storage.ErrFileNotFound) {
+				return common.Response{
+					Status: http.StatusNotFound,
+					Body:   []byte(err.Error()),
+				}
+			}
+			if err != nil {
+				return common.InternalServerErrorResponse(ctx, err)
+			}
+
+			return common.Response{
+				Status: http.StatusOK,
+			}
+		}
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			return common.Response{
+				Status: http.StatusMethodNotAllowed,
+			}
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, storage.FilesystemFilesPath)
+
+		err = fsStore.VerifySignature(key, query.Get("expires"), query.Get("signature"))
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return common.Response{
+				Status: http.StatusForbidden,
+				Body:   []byte(err.Error()),
+			}
+		}
+
+		data, fi, err := fsStore.GetFileData(ctx, key)
+		if errors.Is(err, storage.ErrFileNotFound) {
+			return common.Response{
+				Status: http.StatusNotFound,
+				Body:   []byte(err.Error()),
+			}
+		}
+		if err != nil {
+			return common.InternalServerErrorResponse(ctx, err)
+		}
+
+		// The content type is provided by the uploader, so only types which browsers cannot execute
+		// are displayed inline and everything else is downloaded.
+		disposition := "attachment"
+		if isInlineContentType(fi.ContentType) {
+			disposition = "inline"
+		}
+
+		headers := http.Header{}
+		headers.Set("Content-Type", fi.ContentType)
+		headers.Set("Content-Length", strconv.Itoa(len(data)))
+		headers.Set("X-Content-Type-Options", "nosniff")
+		if fi.Filename != "" {
+			headers.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": fi.Filename}))
+		} else {
+			headers.Set("Content-Disposition", disposition)
+		}
+
+		if r.Method == http.MethodHead {
+			data = nil
+		}
+
+		return common.Response{
+			Status:  http.StatusOK,
+			Body:    data,
+			Headers: headers,
+		}
+	}
+}
+
+// inlineContentTypes are the content types of stored files which are safe to display in the browser.
+var inlineContentTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"image/avif",
+	"application/pdf",
+}
+
+// isInlineContentType returns true if a stored file with the given content type can be displayed inline.
+func isInlineContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(inlineContentTypes, mediaType)
+}
+
+// servesFiles returns true if the runtime is responsible for serving stored files,
+// which is only the case when they are stored on the local filesystem.
+func servesFiles(ctx context.Context) bool {
+	store, err := runtimectx.GetStorage(ctx)
+	if err != nil {
+		return false
+	}
+
+	_, ok := store.(*storage.FilesystemStore)
+	return ok
+}
+
 type JobHandler struct {
 	schema *proto.Schema
 }
@@ -0,0 +1,48 @@
+package runtime_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/runtime"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	"github.com/teamkeel/keel/storage"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestFilesHandlerContentDisposition(t *testing.T) {
+	store, err := storage.NewFilesystemStore(t.TempDir(), "http://localhost:8000/", []byte("secret"), noop.NewTracerProvider().Tracer("test"))
+	require.NoError(t, err)
+
+	ctx := runtimectx.WithStorage(t.Context(), store)
+	handler := runtime.NewFilesHandler()
+
+	cases := []struct {
+		dataURL     string
+		disposition string
+	}{
+		{"data:image/png;name=pixel.png;base64,aGVsbG8=", `inline; filename=pixel.png`},
+		{"data:application/pdf;name=doc.pdf;base64,aGVsbG8=", `inline; filename=doc.pdf`},
+		{"data:text/html;name=page.html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==", `attachment; filename=page.html`},
+		{"data:image/svg+xml;name=image.svg;base64,PHN2Zz48L3N2Zz4=", `attachment; filename=image.svg`},
+		{"data:text/plain;base64,aGVsbG8=", `attachment`},
+	}
+
+	for _, c := range cases {
+		fi, err := store.Store(ctx, c.dataURL)
+		require.NoError(t, err)
+
+		file, err := store.GenerateFileResponse(ctx, &fi)
+		require.NoError(t, err)
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, file.URL, nil)
+		require.NoError(t, err)
+
+		response := handler(request)
+		require.Equal(t, http.StatusOK, response.Status, c.dataURL)
+		require.Equal(t, fi.ContentType, http.Header(response.Headers).Get("Content-Type"), c.dataURL)
+		require.Equal(t, "nosniff", http.Header(response.Headers).Get("X-Content-Type-Options"), c.dataURL)
+		require.Equal(t, c.disposition, http.Header(response.Headers).Get("Content-Disposition"), c.dataURL)
+	}
+}
@@ -396,6 +396,74 @@ const date_timestamp_parsing = `
 	}
 `
 
+const softDeleteRelationships = `
+	model Author {
+		fields {
+			name Text
+			publisher Publisher
+		}
+		actions {
+			get getAuthor(id)
+			list listAuthors(publisher.organisation)
+		}
+		@permission(
+			expression: true,
+			actions: [get, list]
+		)
+	}
+
+	model Publisher {
+		fields {
+			organisation Text
+		}
+		@softDelete
+		@permission(
+			expression: true,
+			actions: [get]
+		)
+	}
+
+	api Test {
+		models {
+			Author
+			Publisher
+		}
+	}
+`
+
+// softDeleteSetup adds two authors with publishers of the same name, one of which has been soft deleted.
+func softDeleteSetup(t *testing.T, db *gorm.DB) {
+	rows := []map[string]any{
+		initRow(map[string]any{
+			"id":           "publisher_1",
+			"organisation": "Keelson Publishers",
+		}),
+		initRow(map[string]any{
+			"id":           "publisher_2",
+			"organisation": "Keelson Publishers",
+			"deletedAt":    time.Now(),
+		}),
+	}
+	for _, row := range rows {
+		require.NoError(t, db.Table("publisher").Create(row).Error)
+	}
+	rows = []map[string]any{
+		initRow(map[string]any{
+			"id":          "author_1",
+			"name":        "Keelson",
+			"publisherId": "publisher_1",
+		}),
+		initRow(map[string]any{
+			"id":          "author_2",
+			"name":        "Weaveton",
+			"publisherId": "publisher_2",
+		}),
+	}
+	for _, row := range rows {
+		require.NoError(t, db.Table("author").Create(row).Error)
+	}
+}
+
 // testCases is a list of testCase that is good for the top level test suite to
 // iterate over.
 var testCases = []testCase{
@@ -2777,4 +2845,56 @@ var testCases = []testCase{
 				map[string]any{"iso8601": "2024-01-01T00:00:00.30Z"}})
 		},
 	},
+	{
+		name:          "get_action_relationship_belongs_to_soft_deleted",
+		keelSchema:    softDeleteRelationships,
+		databaseSetup: softDeleteSetup,
+		gqlOperation: `
+			query GetAuthor($authorId: ID!) {
+				getAuthor(input: { id: $authorId }) {
+					id
+					publisher {
+						id
+						organisation
+					}
+				}
+		 	}`,
+		variables: map[string]any{
+			"authorId": "author_2",
+		},
+		assertData: func(t *testing.T, data map[string]any) {
+			// The author still belongs to the publisher which has been soft deleted
+			rtt.AssertValueAtPath(t, data, "getAuthor.id", "author_2")
+			rtt.AssertValueAtPath(t, data, "getAuthor.publisher.id", "publisher_2")
+			rtt.AssertValueAtPath(t, data, "getAuthor.publisher.organisation", "Keelson Publishers")
+		},
+	},
+	{
+		name:          "list_action_relationship_filter_soft_deleted",
+		keelSchema:    softDeleteRelationships,
+		databaseSetup: softDeleteSetup,
+		gqlOperation: `
+			query ListAuthors($organisation: String!) {
+				listAuthors(input: { where: { publisher: { organisation: { equals: $organisation } } } }) {
+					edges {
+						node {
+							id
+						}
+					}
+					pageInfo {
+						totalCount
+					}
+				}
+		 	}`,
+		variables: map[string]any{
+			"organisation": "Keelson Publishers",
+		},
+		assertData: func(t *testing.T, data map[string]any) {
+			// The publisher which has been soft deleted does not match the filter
+			authors := rtt.GetValueAtPath(t, data, "listAuthors.edges").([]any)
+			require.Len(t, authors, 1)
+			rtt.AssertValueAtPath(t, authors[0].(map[string]any), "node.id", "author_1")
+			rtt.AssertValueAtPath(t, data, "listAuthors.pageInfo.totalCount", float64(1))
+		},
+	},
 }
@@ -0,0 +1,151 @@
+package runtime_test
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/runtime"
+	"github.com/teamkeel/keel/runtime/actions"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/idempotency"
+	"github.com/teamkeel/keel/runtime/oauth"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+var idempotencySchema = `
+model Order {
+	fields {
+		reference Text
+	}
+	actions {
+		create createOrder() with (reference)
+		list listOrders()
+	}
+	@permission(expression: true, actions: [create, list])
+}`
+
+func TestIdempotencyKeyReplaysResponse(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), idempotencySchema, true)
+	defer database.Close()
+
+	handler := runtime.NewApiHandler(schema)
+
+	send := func(path string, key string, body string) common.Response {
+		request := &http.Request{
+			URL:    &url.URL{Path: path},
+			Method: http.MethodPost,
+			Body:   io.NopCloser(strings.NewReader(body)),
+			Header: http.Header{},
+		}
+		if key != "" {
+			request.Header.Set(idempotency.HeaderName, key)
+		}
+		return handler(request.WithContext(ctx))
+	}
+
+	first := send("/api/json/createOrder", "abc", `{"reference": "123"}`)
+	require.Equal(t, http.StatusOK, first.Status)
+	require.Empty(t, first.Headers[idempotency.ReplayedHeaderName])
+
+	second := send("/api/json/createOrder", "abc", `{"reference": "123"}`)
+	require.Equal(t, http.StatusOK, second.Status)
+	require.Equal(t, string(first.Body), string(second.Body))
+	require.Equal(t, []string{"true"}, second.Headers[idempotency.ReplayedHeaderName])
+
+	var count int64
+	require.NoError(t, database.GetDB().Raw("SELECT count(*) FROM \"order\"").Scan(&count).Error)
+	require.Equal(t, int64(1), count)
+
+	mismatch := send("/api/json/createOrder", "abc", `{"reference": "456"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, mismatch.Status)
+	require.Contains(t, string(mismatch.Body), idempotency.ErrRequestMismatch)
+
+	rpc := send("/api/rpc", "def", `{"jsonrpc": "2.0", "id": "1", "method": "createOrder", "params": {"reference": "789"}}`)
+	require.Equal(t, http.StatusOK, rpc.Status)
+	rpcRetry := send("/api/rpc", "def", `{"jsonrpc": "2.0", "id": "1", "method": "createOrder", "params": {"reference": "789"}}`)
+	require.Equal(t, string(rpc.Body), string(rpcRetry.Body))
+
+	send("/api/json/createOrder", "", `{"reference": "123"}`)
+	send("/api/json/createOrder", "", `{"reference": "123"}`)
+
+	require.NoError(t, database.GetDB().Raw("SELECT count(*) FROM \"order\"").Scan(&count).Error)
+	require.Equal(t, int64(4), count)
+
+	// Actions which only read data are not made idempotent
+	send("/api/json/listOrders", "ghi", `{}`)
+	send("/api/rpc", "jkl", `{"jsonrpc": "2.0", "id": "1", "method": "listOrders", "params": {}}`)
+	send("/api/graphql", "mno", `{"query": "{ listOrders { edges { node { id } } } }"}`)
+
+	var keys []string
+	require.NoError(t, database.GetDB().Raw("SELECT key FROM keel_idempotency_key ORDER BY key").Scan(&keys).Error)
+	require.Equal(t, []string{"anonymous:abc", "anonymous:def"}, keys)
+}
+
+func TestIdempotencyKeyScopedToCaller(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), idempotencySchema, true)
+	defer database.Close()
+
+	handler := runtime.NewApiHandler(schema)
+
+	accessToken := func(email string) string {
+		identity, err := actions.CreateIdentity(ctx, schema, email, "1234", oauth.KeelIssuer)
+		require.NoError(t, err)
+		token, _, err := oauth.GenerateSessionAccessToken(ctx, identity["id"].(string), "")
+		require.NoError(t, err)
+		return token
+	}
+
+	send := func(token string, key string, body string) common.Response {
+		request := &http.Request{
+			URL:    &url.URL{Path: "/api/json/createOrder"},
+			Method: http.MethodPost,
+			Body:   io.NopCloser(strings.NewReader(body)),
+			Header: http.Header{},
+		}
+		request.Header.Set(idempotency.HeaderName, key)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		return handler(request.WithContext(ctx))
+	}
+
+	alice := accessToken("alice@keel.xyz")
+	bob := accessToken("bob@keel.xyz")
+
+	first := send(alice, "abc", `{"reference": "alice"}`)
+	require.Equal(t, http.StatusOK, first.Status)
+	require.Contains(t, string(first.Body), "alice")
+
+	// Another identity reusing the same key has their own request executed rather than being given alice's
+	// response or being rejected for a mismatched request
+	second := send(bob, "abc", `{"reference": "bob"}`)
+	require.Equal(t, http.StatusOK, second.Status)
+	require.Contains(t, string(second.Body), "bob")
+	require.Empty(t, second.Headers[idempotency.ReplayedHeaderName])
+
+	// As does an anonymous caller
+	third := send("", "abc", `{"reference": "anonymous"}`)
+	require.Equal(t, http.StatusOK, third.Status)
+	require.Contains(t, string(third.Body), "anonymous")
+	require.Empty(t, third.Headers[idempotency.ReplayedHeaderName])
+
+	// Each caller's retry is replayed from their own request
+	retry := send(bob, "abc", `{"reference": "bob"}`)
+	require.Equal(t, string(second.Body), string(retry.Body))
+	require.Equal(t, []string{"true"}, retry.Headers[idempotency.ReplayedHeaderName])
+
+	retry = send(alice, "abc", `{"reference": "alice"}`)
+	require.Equal(t, string(first.Body), string(retry.Body))
+	require.Equal(t, []string{"true"}, retry.Headers[idempotency.ReplayedHeaderName])
+
+	var count int64
+	require.NoError(t, database.GetDB().Raw("SELECT count(*) FROM \"order\"").Scan(&count).Error)
+	require.Equal(t, int64(3), count)
+
+	require.NoError(t, database.GetDB().Raw("SELECT count(*) FROM keel_idempotency_key").Scan(&count).Error)
+	require.Equal(t, int64(3), count)
+}
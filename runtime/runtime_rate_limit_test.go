@@ -0,0 +1,145 @@
+package runtime_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/config"
+	"github.com/teamkeel/keel/runtime"
+	"github.com/teamkeel/keel/runtime/common"
+	"github.com/teamkeel/keel/runtime/runtimectx"
+	keeltesting "github.com/teamkeel/keel/testing"
+)
+
+var rateLimitSchema = `
+model Order {
+	fields {
+		reference Text
+	}
+	actions {
+		create createOrder() with (reference)
+		list listOrders()
+	}
+	@permission(expression: true, actions: [create, list])
+}`
+
+func TestRateLimitExceeded(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), rateLimitSchema, true)
+	defer database.Close()
+
+	ctx = runtimectx.WithRateLimits(ctx, []config.RateLimitConfig{
+		{Action: "createOrder", By: config.RateLimitByIP, Limit: 2, Period: 3600},
+	})
+
+	handler := runtime.NewApiHandler(schema)
+
+	send := func(path string, body string) common.Response {
+		request := &http.Request{
+			URL:        &url.URL{Path: path},
+			Method:     http.MethodPost,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{},
+			RemoteAddr: "203.0.113.7:41234",
+		}
+		return handler(request.WithContext(ctx))
+	}
+
+	require.Equal(t, http.StatusOK, send("/api/json/createOrder", `{"reference": "1"}`).Status)
+	require.Equal(t, http.StatusOK, send("/api/json/createOrder", `{"reference": "2"}`).Status)
+
+	limited := send("/api/json/createOrder", `{"reference": "3"}`)
+	require.Equal(t, http.StatusTooManyRequests, limited.Status)
+	require.NotEmpty(t, limited.Headers["Retry-After"])
+
+	var res map[string]any
+	require.NoError(t, json.Unmarshal(limited.Body, &res))
+	require.Equal(t, common.ErrRateLimited, res["code"])
+
+	rpc := send("/api/rpc", `{"jsonrpc": "2.0", "id": "1", "method": "createOrder", "params": {"reference": "4"}}`)
+	require.Equal(t, http.StatusTooManyRequests, rpc.Status)
+	require.NotEmpty(t, rpc.Headers["Retry-After"])
+
+	gql := send("/api/graphql", `{"query": "mutation { createOrder(input: {reference: \"5\"}) { id } }"}`)
+	require.Equal(t, http.StatusTooManyRequests, gql.Status)
+	require.NotEmpty(t, gql.Headers["Retry-After"])
+
+	// Other actions are not limited by the rule
+	require.Equal(t, http.StatusOK, send("/api/json/listOrders", `{}`).Status)
+}
+
+func TestRateLimitRefusedRequestsDoNotUseOtherBuckets(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), rateLimitSchema, true)
+	defer database.Close()
+
+	ctx = runtimectx.WithRateLimits(ctx, []config.RateLimitConfig{
+		{By: config.RateLimitByIP, Limit: 3, Period: 3600},
+		{Action: "createOrder", By: config.RateLimitByIP, Limit: 1, Period: 3600},
+	})
+
+	handler := runtime.NewApiHandler(schema)
+
+	send := func(path string, body string) common.Response {
+		request := &http.Request{
+			URL:        &url.URL{Path: path},
+			Method:     http.MethodPost,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{},
+			RemoteAddr: "203.0.113.7:41234",
+		}
+		return handler(request.WithContext(ctx))
+	}
+
+	require.Equal(t, http.StatusOK, send("/api/json/createOrder", `{"reference": "1"}`).Status)
+
+	// Refused by the createOrder rule, which must not take tokens from the rule for every action
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusTooManyRequests, send("/api/json/createOrder", `{"reference": "2"}`).Status)
+	}
+
+	require.Equal(t, http.StatusOK, send("/api/json/listOrders", `{}`).Status)
+	require.Equal(t, http.StatusOK, send("/api/json/listOrders", `{}`).Status)
+	require.Equal(t, http.StatusTooManyRequests, send("/api/json/listOrders", `{}`).Status)
+}
+
+func TestRequestPasswordResetLockedOut(t *testing.T) {
+	ctx, database, schema := keeltesting.MakeContext(t, t.Context(), rateLimitSchema, true)
+	defer database.Close()
+
+	maxAttempts := 2
+	ctx = runtimectx.WithOAuthConfig(ctx, &config.AuthConfig{
+		Lockout: config.LockoutConfig{MaxAttempts: &maxAttempts},
+	})
+
+	handler := runtime.NewApiHandler(schema)
+
+	send := func(email string) common.Response {
+		request := &http.Request{
+			URL:        &url.URL{Path: "/api/json/requestPasswordReset"},
+			Method:     http.MethodPost,
+			Body:       io.NopCloser(strings.NewReader(`{"email": "` + email + `", "redirectUrl": "https://keel.so/reset"}`)),
+			Header:     http.Header{},
+			RemoteAddr: "203.0.113.7:41234",
+		}
+		return handler(request.WithContext(ctx))
+	}
+
+	// Every request counts as an attempt, whether or not the identity exists
+	require.Equal(t, http.StatusOK, send("keelson@keel.so").Status)
+	require.Equal(t, http.StatusOK, send("keelson@keel.so").Status)
+
+	locked := send("keelson@keel.so")
+	require.Equal(t, http.StatusTooManyRequests, locked.Status)
+	require.NotEmpty(t, locked.Headers["Retry-After"])
+
+	var res map[string]any
+	require.NoError(t, json.Unmarshal(locked.Body, &res))
+	require.Equal(t, common.ErrRateLimited, res["code"])
+
+	// Other emails are not locked out
+	require.Equal(t, http.StatusOK, send("weaveton@keel.so").Status)
+}
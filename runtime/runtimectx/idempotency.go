@@ -0,0 +1,30 @@
+package runtimectx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/teamkeel/keel/config"
+)
+
+const (
+	idempotencyContextKey contextKey = "idempotencyConfig"
+)
+
+func WithIdempotencyConfig(ctx context.Context, config *config.IdempotencyConfig) context.Context {
+	ctx = context.WithValue(ctx, idempotencyContextKey, config)
+	return ctx
+}
+
+func GetIdempotencyConfig(ctx context.Context) (*config.IdempotencyConfig, error) {
+	v := ctx.Value(idempotencyContextKey)
+	if v == nil {
+		return &config.IdempotencyConfig{}, nil
+	}
+
+	config, ok := v.(*config.IdempotencyConfig)
+	if !ok {
+		return nil, errors.New("idempotency config in the context has wrong value type")
+	}
+	return config, nil
+}
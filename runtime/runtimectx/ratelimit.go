@@ -0,0 +1,41 @@
+package runtimectx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/teamkeel/keel/config"
+)
+
+const (
+	rateLimitsContextKey contextKey = "rateLimits"
+	clientIPContextKey   contextKey = "clientIP"
+)
+
+func WithRateLimits(ctx context.Context, rules []config.RateLimitConfig) context.Context {
+	return context.WithValue(ctx, rateLimitsContextKey, rules)
+}
+
+func GetRateLimits(ctx context.Context) ([]config.RateLimitConfig, error) {
+	v := ctx.Value(rateLimitsContextKey)
+	if v == nil {
+		return nil, nil
+	}
+
+	rules, ok := v.([]config.RateLimitConfig)
+	if !ok {
+		return nil, errors.New("rate limits in the context has wrong value type")
+	}
+	return rules, nil
+}
+
+// WithClientIP sets the IP address of the client which made the request.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey, ip)
+}
+
+// GetClientIP returns the IP address of the client which made the request, if known.
+func GetClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPContextKey).(string)
+	return ip
+}
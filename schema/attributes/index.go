@@ -0,0 +1,81 @@
+package attributes
+
+import (
+	"encoding/hex"
+
+	"github.com/iancoleman/strcase"
+	"github.com/teamkeel/keel/expressions"
+	"github.com/teamkeel/keel/expressions/options"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+var indexes = make(map[string]*expressions.Parser)
+
+// defaultIndex will cache the base CEL environment for a schema. Index expressions are evaluated by
+// the database for each row, so only operators on the row's own values are available.
+func defaultIndex(schema []*parser.AST) (*expressions.Parser, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var contents string
+	for _, s := range schema {
+		contents += s.Raw + "\n"
+	}
+	key := hex.EncodeToString([]byte(contents))
+
+	if parser, exists := indexes[key]; exists {
+		return parser, nil
+	}
+
+	opts := []expressions.Option{
+		options.WithSchemaTypes(schema),
+		options.WithComparisonOperators(),
+		options.WithLogicalOperators(),
+		options.WithArithmeticOperators(),
+	}
+
+	parser, err := expressions.NewParser(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes[key] = parser
+
+	return parser, nil
+}
+
+// ValidateIndexWhereExpression validates the where argument of @index, which determines the rows included in the index.
+func ValidateIndexWhereExpression(schema []*parser.AST, model *parser.ModelNode, expression *parser.Expression) ([]*errorhandling.ValidationError, error) {
+	base, err := defaultIndex(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []expressions.Option{
+		options.WithVariable(strcase.ToLowerCamel(model.Name.Value), model.Name.Value, false),
+		options.WithReturnTypeAssertion(parser.FieldTypeBoolean, false),
+	}
+
+	p, err := base.Extend(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Validate(expression)
+}
+
+// ValidateIndexExpression validates the expression argument of @index, the value of which is indexed.
+func ValidateIndexExpression(schema []*parser.AST, model *parser.ModelNode, expression *parser.Expression) ([]*errorhandling.ValidationError, error) {
+	base, err := defaultIndex(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := base.Extend(options.WithVariable(strcase.ToLowerCamel(model.Name.Value), model.Name.Value, false))
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Validate(expression)
+}
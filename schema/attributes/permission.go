@@ -115,6 +115,12 @@ func ValidatePermissionActions(expression *parser.Expression) ([]*errorhandling.
 		options.WithConstant(parser.ActionTypeUpdate, "_ActionType"),
 		options.WithConstant(parser.ActionTypeList, "_ActionType"),
 		options.WithConstant(parser.ActionTypeDelete, "_ActionType"),
+		options.WithConstant(parser.ActionTypeRestore, "_ActionType"),
+		options.WithConstant(parser.ActionTypeUpsert, "_ActionType"),
+		options.WithConstant(parser.ActionTypeCreateMany, "_ActionType"),
+		options.WithConstant(parser.ActionTypeUpdateMany, "_ActionType"),
+		options.WithConstant(parser.ActionTypeDeleteMany, "_ActionType"),
+		options.WithConstant(parser.ActionTypeAggregate, "_ActionType"),
 		options.WithReturnTypeAssertion("_ActionType", true),
 	}
 
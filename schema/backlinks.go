@@ -2,7 +2,6 @@ package schema
 
 import (
 	"github.com/teamkeel/keel/casing"
-	"github.com/teamkeel/keel/expressions/resolve"
 	"github.com/teamkeel/keel/schema/parser"
 	"github.com/teamkeel/keel/schema/query"
 	"github.com/teamkeel/keel/schema/validation/errorhandling"
@@ -62,8 +61,9 @@ func (scm *Builder) insertBackLinkField(
 	backlinkName := casing.ToLowerCamel(parentModel.Name.Value)
 	relation := query.FieldGetAttribute(forwardRelnField, parser.AttributeRelation)
 	if relation != nil {
-		relationValue, _ := resolve.AsIdent(relation.Arguments[0].Expression)
-		backlinkName = relationValue.String()
+		if relationValue, ok := query.RelationAttributeValue(relation); ok {
+			backlinkName = relationValue
+		}
 	}
 
 	// If the field already exists don't add another one as this will just create a
@@ -67,7 +67,7 @@ func Completions(schemaFiles []*reader.SchemaFile, pos *node.Position, cfg *conf
 	// switch on nearest (previous) keyword
 	switch enclosingBlock {
 	case parser.KeywordModel:
-		attributes := getAttributeCompletions(tokenAtPos, []string{parser.AttributePermission, parser.AttributeUnique, parser.AttributeOn})
+		attributes := getAttributeCompletions(tokenAtPos, []string{parser.AttributePermission, parser.AttributeUnique, parser.AttributeOn, parser.AttributeRenamedFrom, parser.AttributeSoftDelete, parser.AttributeSearchable, parser.AttributeIndex})
 		return append(attributes, modelBlockKeywords...)
 	case parser.KeywordRole:
 		return roleBlockKeywords
@@ -256,6 +256,8 @@ func getBlockCompletions(asts []*parser.AST, tokenAtPos *TokensAtPosition, keywo
 			parser.AttributeRelation,
 			parser.AttributeComputed,
 			parser.AttributeSequence,
+			parser.AttributeRenamedFrom,
+			parser.AttributeVectorIndex,
 		})
 	}
 
@@ -327,6 +329,8 @@ func getBlockCompletions(asts []*parser.AST, tokenAtPos *TokensAtPosition, keywo
 				parser.AttributeRelation,
 				parser.AttributeComputed,
 				parser.AttributeSequence,
+				parser.AttributeRenamedFrom,
+				parser.AttributeVectorIndex,
 			})
 		}
 
@@ -372,11 +376,14 @@ func getActionCompletions(asts []*parser.AST, tokenAtPos *TokensAtPosition) []*C
 
 	prev, _ = tokenAtPos.FindPrevMultipleOnLine(
 		parser.ActionTypeDelete,
+		parser.ActionTypeDeleteMany,
+		parser.ActionTypeRestore,
 		parser.ActionTypeGet,
 		parser.ActionTypeList,
+		parser.ActionTypeAggregate,
 		parser.KeywordWith,
 	)
-	// if we're delete, list or get action type and have completed our parenthesis, or there is already a `with`
+	// if we're delete, restore, list or get action type and have completed our parenthesis, or there is already a `with`
 	// clause on this line then there are no further completions that are valid. Return empty list.
 	if tokenAtPos.Prev().EndOfParen() != nil && prev != "" {
 		return []*CompletionItem{}
@@ -398,6 +405,8 @@ func getActionCompletions(asts []*parser.AST, tokenAtPos *TokensAtPosition) []*C
 			parser.AttributeFunction,
 			parser.AttributeEmbed,
 			parser.AttributeFacet,
+			parser.AttributeGroupBy,
+			parser.AttributeAggregate,
 		})
 	}
 
@@ -474,6 +483,30 @@ var actionBlockKeywords = []*CompletionItem{
 		Label: parser.ActionTypeDelete,
 		Kind:  KindKeyword,
 	},
+	{
+		Label: parser.ActionTypeRestore,
+		Kind:  KindKeyword,
+	},
+	{
+		Label: parser.ActionTypeUpsert,
+		Kind:  KindKeyword,
+	},
+	{
+		Label: parser.ActionTypeCreateMany,
+		Kind:  KindKeyword,
+	},
+	{
+		Label: parser.ActionTypeUpdateMany,
+		Kind:  KindKeyword,
+	},
+	{
+		Label: parser.ActionTypeDeleteMany,
+		Kind:  KindKeyword,
+	},
+	{
+		Label: parser.ActionTypeAggregate,
+		Kind:  KindKeyword,
+	},
 	{
 		Label: parser.KeywordWith,
 		Kind:  KindKeyword,
@@ -663,6 +696,24 @@ func getAttributeArgCompletions(asts []*parser.AST, t *TokensAtPosition, cfg *co
 		return getScheduleArgCompletions(asts)
 	case parser.AttributeOn:
 		return getOnArgCompletions(asts, t)
+	case parser.AttributeIndex:
+		return getIndexArgCompletions(asts, t, cfg)
+	case parser.AttributeSearchable:
+		model := query.Model(asts, getParentModelName(t))
+		if model == nil {
+			return []*CompletionItem{}
+		}
+
+		fields := query.ModelFields(model, func(f *parser.FieldNode) bool {
+			return !f.Repeated && (f.Type.Value == parser.FieldTypeText || f.Type.Value == parser.FieldTypeMarkdown)
+		})
+
+		return lo.Map(fields, func(f *parser.FieldNode, _ int) *CompletionItem {
+			return &CompletionItem{
+				Label: f.Name.Value,
+				Kind:  KindField,
+			}
+		})
 	case parser.AttributeUnique:
 		// composite
 		if enclosingBlock == parser.KeywordModel {
@@ -917,6 +968,49 @@ func getPermissionArgCompletions(asts []*parser.AST, t *TokensAtPosition, cfg *c
 	}
 }
 
+func getIndexArgCompletions(asts []*parser.AST, t *TokensAtPosition, cfg *config.ProjectConfig) []*CompletionItem {
+	labels := lo.Map([]string{"expression", "descending", "where", "unique"}, func(label string, _ int) *CompletionItem {
+		return &CompletionItem{Label: label, Kind: KindLabel}
+	})
+
+	// If the first argument, which is either the array of fields or a label
+	if t.Prev().Value() == parser.AttributeIndex {
+		return append([]*CompletionItem{{Label: "[", Kind: KindPunctuation}}, labels...)
+	}
+
+	// If within an array of fields
+	if t.StartOfGroup("[", "]") != nil {
+		model := query.Model(asts, getParentModelName(t))
+		if model == nil {
+			return []*CompletionItem{}
+		}
+
+		fields := query.ModelFields(model, func(f *parser.FieldNode) bool {
+			return !f.Repeated && (f.IsScalar() || query.Enum(asts, f.Type.Value) != nil) && f.Type.Value != parser.FieldTypeVector
+		})
+
+		return lo.Map(fields, func(f *parser.FieldNode, _ int) *CompletionItem {
+			return &CompletionItem{
+				Label: f.Name.Value,
+				Kind:  KindField,
+			}
+		})
+	}
+
+	comma := t.FindPrev(",")
+	if comma.Is(t, t.Prev()) {
+		return labels
+	}
+
+	colon := t.FindPrev(":")
+	switch colon.Prev().Value() {
+	case "expression", "where":
+		return getExpressionCompletions(asts, t, cfg)
+	default:
+		return []*CompletionItem{}
+	}
+}
+
 func getOnArgCompletions(asts []*parser.AST, t *TokensAtPosition) []*CompletionItem {
 	// If the first argument and no array bracket has been opened
 	if t.Prev().Value() == parser.AttributeOn {
@@ -996,6 +1090,16 @@ func getExpressionCompletions(asts []*parser.AST, t *TokensAtPosition, cfg *conf
 					Description: "Authentication Indicator",
 					Kind:        KindField,
 				},
+				{
+					Label:       "isServiceAccount",
+					Description: "Service Account Indicator",
+					Kind:        KindField,
+				},
+				{
+					Label:       "scopes",
+					Description: "Service Account Scopes",
+					Kind:        KindField,
+				},
 				{
 					Label:       "headers",
 					Description: "Request Headers",
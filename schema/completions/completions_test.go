@@ -111,7 +111,7 @@ func TestModelCompletions(t *testing.T) {
 			model A {
 			  <Cursor>
 			}`,
-			expected: []string{"@permission", "@unique", "@on", "fields", "actions"},
+			expected: []string{"@permission", "@unique", "@on", "@renamedFrom", "@softDelete", "@searchable", "@index", "fields", "actions"},
 		},
 		// attributes tests
 		{
@@ -120,7 +120,7 @@ func TestModelCompletions(t *testing.T) {
 			model A {
               @<Cursor>
             }`,
-			expected: []string{"@permission", "@unique", "@on", "fields", "actions"},
+			expected: []string{"@permission", "@unique", "@on", "@renamedFrom", "@softDelete", "@searchable", "@index", "fields", "actions"},
 		},
 	}
 
@@ -434,7 +434,7 @@ func TestFieldCompletions(t *testing.T) {
 					}
 				}
 			}`,
-			expected: []string{"@unique", "@default", "@relation", "@sequence", "@computed"},
+			expected: []string{"@unique", "@default", "@relation", "@sequence", "@computed", "@renamedFrom", "@vectorIndex"},
 		},
 		{
 			name: "field-attributes-bare-at",
@@ -443,7 +443,7 @@ func TestFieldCompletions(t *testing.T) {
 					name Text @<Cursor>
 				}
 			}`,
-			expected: []string{"@unique", "@default", "@relation", "@sequence", "@computed"},
+			expected: []string{"@unique", "@default", "@relation", "@sequence", "@computed", "@renamedFrom", "@vectorIndex"},
 		},
 		{
 			name: "field-attributes-whitespace",
@@ -453,7 +453,7 @@ func TestFieldCompletions(t *testing.T) {
 					name Text <Cursor>
 				}
 			}`,
-			expected: []string{"@unique", "@default", "@relation", "@sequence", "@computed"},
+			expected: []string{"@unique", "@default", "@relation", "@sequence", "@computed", "@renamedFrom", "@vectorIndex"},
 		},
 	}
 
@@ -656,7 +656,7 @@ func TestActionCompletions(t *testing.T) {
 				}
 			  }
 		    }`,
-			expected: []string{"@embed", "@facet", "@function", "@orderBy", "@permission", "@set", "@sortable", "@validate", "@where"},
+			expected: []string{"@aggregate", "@embed", "@facet", "@function", "@groupBy", "@orderBy", "@permission", "@set", "@sortable", "@validate", "@where"},
 		},
 		{
 			name: "action-attributes-whitespace",
@@ -671,7 +671,7 @@ func TestActionCompletions(t *testing.T) {
 				}
 			  }
 		    }`,
-			expected: []string{"@embed", "@facet", "@function", "@orderBy", "@permission", "@set", "@sortable", "@validate", "@where"},
+			expected: []string{"@aggregate", "@embed", "@facet", "@function", "@groupBy", "@orderBy", "@permission", "@set", "@sortable", "@validate", "@where"},
 		},
 	}
 
@@ -758,7 +758,7 @@ func TestWhereAttributeCompletions(t *testing.T) {
 					}
 				}
 			}`,
-			expected: []string{"env", "headers", "identity", "isAuthenticated", "now", "secrets"},
+			expected: []string{"env", "headers", "identity", "isAuthenticated", "isServiceAccount", "now", "scopes", "secrets"},
 		},
 		{
 			name: "where-attribute-ctx-identity",
@@ -1019,7 +1019,7 @@ func TestSetAttributeCompletions(t *testing.T) {
 					}
 				}
 			}`,
-			expected: []string{"env", "headers", "identity", "isAuthenticated", "now", "secrets"},
+			expected: []string{"env", "headers", "identity", "isAuthenticated", "isServiceAccount", "now", "scopes", "secrets"},
 		},
 		{
 			name: "set-attribute-ctx-identity",
@@ -1327,7 +1327,7 @@ func TestPermissionCompletions(t *testing.T) {
 				)
 			}
 			`,
-			expected: []string{"env", "headers", "identity", "isAuthenticated", "now", "secrets"},
+			expected: []string{"env", "headers", "identity", "isAuthenticated", "isServiceAccount", "now", "scopes", "secrets"},
 		},
 		{
 			name: "permission-attribute-actions",
@@ -1509,6 +1509,83 @@ func TestSortableCompletions(t *testing.T) {
 	runTestsCases(t, cases)
 }
 
+func TestSearchableCompletions(t *testing.T) {
+	cases := []testCase{
+		{
+			name: "searchable-attribute-values",
+			schema: `
+			model Post {
+				fields {
+					title Text
+					body Markdown
+					tags Text[]
+					views Number
+				}
+				@searchable(<Cursor>
+		    }`,
+			expected: []string{"body", "title"},
+		},
+		{
+			name: "searchable-attribute-second-arg",
+			schema: `
+			model Post {
+				fields {
+					title Text
+					body Markdown
+				}
+				@searchable(title, <Cursor>
+		    }`,
+			expected: []string{"body", "title"},
+		},
+	}
+
+	runTestsCases(t, cases)
+}
+
+func TestIndexCompletions(t *testing.T) {
+	cases := []testCase{
+		{
+			name: "index-attribute-first-arg",
+			schema: `
+			model Post {
+				fields {
+					title Text
+				}
+				@index(<Cursor>
+		    }`,
+			expected: []string{"[", "descending", "expression", "unique", "where"},
+		},
+		{
+			name: "index-attribute-fields",
+			schema: `
+			model Post {
+				fields {
+					title Text
+					published Boolean
+					tags Text[]
+					author Author
+				}
+				@index([title, <Cursor>
+		    }
+			model Author {}`,
+			expected: []string{"createdAt", "id", "published", "title", "updatedAt"},
+		},
+		{
+			name: "index-attribute-labels",
+			schema: `
+			model Post {
+				fields {
+					title Text
+				}
+				@index([title], <Cursor>
+		    }`,
+			expected: []string{"descending", "expression", "unique", "where"},
+		},
+	}
+
+	runTestsCases(t, cases)
+}
+
 func TestEmbedCompletions(t *testing.T) {
 	cases := []testCase{
 		{
@@ -860,6 +860,35 @@ func makeDurationArrayQueryInputMessage(name string) *proto.Message {
 	}}
 }
 
+// Vectors are queried by their distance from another vector, and the results are ordered nearest first.
+func makeVectorQueryInputMessage(name string) *proto.Message {
+	return &proto.Message{Name: name, Fields: []*proto.MessageField{
+		{
+			MessageName: name,
+			Name:        "nearest",
+			Type: &proto.TypeInfo{
+				Type: proto.Type_TYPE_VECTOR,
+			},
+		},
+		{
+			MessageName: name,
+			Name:        "metric",
+			Optional:    true,
+			Type: &proto.TypeInfo{
+				Type: proto.Type_TYPE_STRING,
+			},
+		},
+		{
+			MessageName: name,
+			Name:        "maxDistance",
+			Optional:    true,
+			Type: &proto.TypeInfo{
+				Type: proto.Type_TYPE_DECIMAL,
+			},
+		},
+	}}
+}
+
 func makeEnumQueryInputMessage(name string, enumName string) *proto.Message {
 	return &proto.Message{Name: name, Fields: []*proto.MessageField{
 		{
@@ -937,6 +966,8 @@ func (scm *Builder) makeListQueryInputMessage(typeInfo *proto.TypeInfo) (*proto.
 		prefix = "Timestamp"
 	case proto.Type_TYPE_DURATION:
 		prefix = "Duration"
+	case proto.Type_TYPE_VECTOR:
+		prefix = "Vector"
 	case proto.Type_TYPE_ENUM:
 		prefix = typeInfo.GetEnumName().GetValue()
 	}
@@ -1052,6 +1083,8 @@ func (scm *Builder) makeListQueryInputMessage(typeInfo *proto.TypeInfo) (*proto.
 		return makeTimestampQueryInputMessage(msgName), nil
 	case proto.Type_TYPE_DURATION:
 		return makeDurationQueryInputMessage(msgName), nil
+	case proto.Type_TYPE_VECTOR:
+		return makeVectorQueryInputMessage(msgName), nil
 	case proto.Type_TYPE_ENUM:
 		return makeEnumQueryInputMessage(msgName, typeInfo.GetEnumName().GetValue()), nil
 	default:
@@ -1115,6 +1148,9 @@ func (scm *Builder) makeMessageHierarchyFromImplicitInput(rootMessage *proto.Mes
 	currMessage := rootMessage
 	currModel := model.Name.Value
 
+	// List and aggregate actions filter records with query inputs
+	isQuery := action.Type.Value == parser.ActionTypeList || action.Type.Value == parser.ActionTypeAggregate
+
 	for currIndex, fragment := range target {
 		if currIndex < len(target)-1 {
 			// If this is not the last target fragment, then we know the current fragment is referring to a related model field.
@@ -1143,14 +1179,14 @@ func (scm *Builder) makeMessageHierarchyFromImplicitInput(rootMessage *proto.Mes
 					Type: &proto.TypeInfo{
 						Type: proto.Type_TYPE_MESSAGE,
 						// Repeated with be true in a 1:M relationship for create only.
-						Repeated: action.Type.Value != parser.ActionTypeList && field.Repeated,
+						Repeated: !isQuery && field.Repeated,
 						MessageName: &wrapperspb.StringValue{
 							Value: relatedModelMessageName,
 						},
 					},
 					Optional: input.Optional,
 					// List op implicit inputs are not nullable, because they will have a query type.
-					Nullable:    action.Type.Value != parser.ActionTypeList && field.Optional,
+					Nullable:    !isQuery && field.Optional,
 					MessageName: currMessage.GetName(),
 				})
 
@@ -1171,7 +1207,7 @@ func (scm *Builder) makeMessageHierarchyFromImplicitInput(rootMessage *proto.Mes
 		} else {
 			typeInfo, target, targetsOptionalField := scm.inferParserInputType(model, input)
 
-			if action.Type.Value == parser.ActionTypeList {
+			if isQuery {
 				queryMessage, err := scm.makeListQueryInputMessage(typeInfo)
 				if err != nil {
 					panic(err.Error())
@@ -1206,9 +1242,51 @@ func (scm *Builder) makeMessageHierarchyFromImplicitInput(rootMessage *proto.Mes
 	}
 }
 
+// Adds the input messages of a bulk action, which has a root message with a repeated "items" field. The message of
+// each item is the input message that the single record action would have, renamed from XInput to XItem.
+func (scm *Builder) makeBulkInputMessage(model *parser.ModelNode, action *parser.ActionNode) string {
+	itemMessageName := makeItemMessageName(action.Name.Value)
+
+	messageName := scm.makeActionInputMessages(model, action)
+	if messageName == "" {
+		scm.proto.Messages = append(scm.proto.Messages, &proto.Message{
+			Name:   itemMessageName,
+			Fields: []*proto.MessageField{},
+		})
+	} else {
+		for _, m := range scm.proto.GetMessages() {
+			if m.GetName() != messageName {
+				continue
+			}
+
+			m.Name = itemMessageName
+			for _, f := range m.GetFields() {
+				f.MessageName = itemMessageName
+			}
+		}
+	}
+
+	scm.proto.Messages = append(scm.proto.Messages, &proto.Message{
+		Name: makeInputMessageName(action.Name.Value),
+		Fields: []*proto.MessageField{
+			{
+				Name: "items",
+				Type: &proto.TypeInfo{
+					Type:        proto.Type_TYPE_MESSAGE,
+					MessageName: wrapperspb.String(itemMessageName),
+					Repeated:    true,
+				},
+				MessageName: makeInputMessageName(action.Name.Value),
+			},
+		},
+	})
+
+	return makeInputMessageName(action.Name.Value)
+}
+
 // Adds a set of proto.Messages to top level Messages registry for all inputs of an Action.
 func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *parser.ActionNode) string {
-	switch action.Type.Value {
+	switch action.BaseType() {
 	case parser.ActionTypeCreate:
 		if len(action.With) > 0 {
 			rootMessage := &proto.Message{
@@ -1237,7 +1315,7 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 
 			return rootMessage.GetName()
 		}
-	case parser.ActionTypeGet, parser.ActionTypeDelete, parser.ActionTypeRead, parser.ActionTypeWrite:
+	case parser.ActionTypeGet, parser.ActionTypeDelete, parser.ActionTypeRestore, parser.ActionTypeRead, parser.ActionTypeWrite:
 		if len(action.Inputs) > 0 {
 			// Create message and add it to the proto schema
 			messageName := makeInputMessageName(action.Name.Value)
@@ -1246,7 +1324,7 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 
 			return messageName
 		}
-	case parser.ActionTypeUpdate:
+	case parser.ActionTypeUpdate, parser.ActionTypeUpsert:
 		fields := []*proto.MessageField{}
 
 		if len(action.Inputs) > 0 {
@@ -1319,26 +1397,7 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 			return makeInputMessageName(action.Name.Value)
 		}
 	case parser.ActionTypeList:
-
-		whereMessage := &proto.Message{
-			Name:   makeWhereMessageName(action.Name.Value),
-			Fields: []*proto.MessageField{},
-		}
-
-		for _, input := range action.Inputs {
-			if input.Label == nil {
-				scm.makeMessageHierarchyFromImplicitInput(whereMessage, input, model, action)
-			} else {
-				typeInfo := scm.explicitInputToTypeInfo(input)
-
-				whereMessage.Fields = append(whereMessage.Fields, &proto.MessageField{
-					Name:        input.Name(),
-					Type:        typeInfo,
-					Optional:    input.Optional,
-					MessageName: makeWhereMessageName(action.Name.Value),
-				})
-			}
-		}
+		whereMessage := scm.makeQueryWhereMessage(model, action)
 
 		sortableFields, err := query.ActionSortableFieldNames(action)
 		if err != nil {
@@ -1353,18 +1412,7 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 		// Only add where field if there are inputs
 		if len(action.Inputs) > 0 {
 			scm.proto.Messages = append(scm.proto.Messages, whereMessage)
-
-			inputMessage.Fields = append(inputMessage.Fields, &proto.MessageField{
-				Name: "where",
-				Optional: lo.EveryBy(whereMessage.GetFields(), func(f *proto.MessageField) bool {
-					return f.GetOptional()
-				}),
-				MessageName: makeInputMessageName(action.Name.Value),
-				Type: &proto.TypeInfo{
-					Type:        proto.Type_TYPE_MESSAGE,
-					MessageName: wrapperspb.String(whereMessage.GetName()),
-				},
-			})
+			inputMessage.Fields = append(inputMessage.Fields, makeWhereMessageField(inputMessage, whereMessage))
 		}
 
 		// Include pagination fields
@@ -1412,6 +1460,20 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 			},
 		})
 
+		// Searchable models can be searched by the list actions of the runtime
+		if !action.IsFunction() && lo.ContainsBy(query.ModelAttributes(model), func(a *parser.AttributeNode) bool {
+			return a.Name.Value == parser.AttributeSearchable
+		}) {
+			inputMessage.Fields = append(inputMessage.Fields, &proto.MessageField{
+				Name:        "search",
+				MessageName: makeInputMessageName(action.Name.Value),
+				Optional:    true,
+				Type: &proto.TypeInfo{
+					Type: proto.Type_TYPE_STRING,
+				},
+			})
+		}
+
 		orderByMessages := makeListOrderByMessages(action.Name.Value, sortableFields)
 		if len(orderByMessages) > 0 {
 			orderByMessageField := &proto.MessageField{
@@ -1431,6 +1493,37 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 
 		scm.proto.Messages = append(scm.proto.Messages, inputMessage)
 
+		return inputMessage.GetName()
+	case parser.ActionTypeAggregate:
+		whereMessage := scm.makeQueryWhereMessage(model, action)
+
+		inputMessage := &proto.Message{
+			Name:   makeInputMessageName(action.Name.Value),
+			Fields: []*proto.MessageField{},
+		}
+
+		// Only add where field if there are inputs
+		if len(action.Inputs) > 0 {
+			scm.proto.Messages = append(scm.proto.Messages, whereMessage)
+			inputMessage.Fields = append(inputMessage.Fields, makeWhereMessageField(inputMessage, whereMessage))
+		}
+
+		// Date and timestamp fields are grouped into buckets of an interval
+		if lo.SomeBy(query.ActionGroupByFields(model, action), func(f *parser.FieldNode) bool {
+			return f.Type.Value == parser.FieldTypeDate || f.Type.Value == parser.FieldTypeTimestamp
+		}) {
+			inputMessage.Fields = append(inputMessage.Fields, &proto.MessageField{
+				Name:        "interval",
+				MessageName: inputMessage.GetName(),
+				Optional:    true,
+				Type: &proto.TypeInfo{
+					Type: proto.Type_TYPE_STRING,
+				},
+			})
+		}
+
+		scm.proto.Messages = append(scm.proto.Messages, inputMessage)
+
 		return inputMessage.GetName()
 	default:
 		panic("unhandled action type when creating input message types")
@@ -1439,6 +1532,138 @@ func (scm *Builder) makeActionInputMessages(model *parser.ModelNode, action *par
 	return ""
 }
 
+// Creates the where message of a list or aggregate action, in which the implicit inputs are query messages.
+func (scm *Builder) makeQueryWhereMessage(model *parser.ModelNode, action *parser.ActionNode) *proto.Message {
+	whereMessage := &proto.Message{
+		Name:   makeWhereMessageName(action.Name.Value),
+		Fields: []*proto.MessageField{},
+	}
+
+	for _, input := range action.Inputs {
+		if input.Label == nil {
+			scm.makeMessageHierarchyFromImplicitInput(whereMessage, input, model, action)
+		} else {
+			typeInfo := scm.explicitInputToTypeInfo(input)
+
+			whereMessage.Fields = append(whereMessage.Fields, &proto.MessageField{
+				Name:        input.Name(),
+				Type:        typeInfo,
+				Optional:    input.Optional,
+				MessageName: makeWhereMessageName(action.Name.Value),
+			})
+		}
+	}
+
+	return whereMessage
+}
+
+func makeWhereMessageField(inputMessage *proto.Message, whereMessage *proto.Message) *proto.MessageField {
+	return &proto.MessageField{
+		Name: "where",
+		Optional: lo.EveryBy(whereMessage.GetFields(), func(f *proto.MessageField) bool {
+			return f.GetOptional()
+		}),
+		MessageName: inputMessage.GetName(),
+		Type: &proto.TypeInfo{
+			Type:        proto.Type_TYPE_MESSAGE,
+			MessageName: wrapperspb.String(whereMessage.GetName()),
+		},
+	}
+}
+
+// Adds the response messages of an aggregate action and returns the name of the root message. The response has
+// a result for each group, with the group's values of the @groupBy fields, the number of records in the group and
+// a nested message for each function of @aggregate. For example:
+//
+//	OrderTotalsResponse { results: OrderTotalsResult[] }
+//	OrderTotalsResult { status: Status, count: Number, sum: OrderTotalsSum }
+//	OrderTotalsSum { total: Decimal }
+func (scm *Builder) makeAggregateResponseMessage(model *parser.ModelNode, action *parser.ActionNode) string {
+	resultMessage := &proto.Message{
+		Name:   makeAggregateMessageName(action.Name.Value, "Result"),
+		Fields: []*proto.MessageField{},
+	}
+
+	for _, field := range query.ActionGroupByFields(model, action) {
+		resultMessage.Fields = append(resultMessage.Fields, &proto.MessageField{
+			Name:        field.Name.Value,
+			MessageName: resultMessage.GetName(),
+			Type:        scm.parserFieldToProtoTypeInfo(field),
+			Nullable:    true,
+		})
+	}
+
+	resultMessage.Fields = append(resultMessage.Fields, &proto.MessageField{
+		Name:        "count",
+		MessageName: resultMessage.GetName(),
+		Type: &proto.TypeInfo{
+			Type: proto.Type_TYPE_INT,
+		},
+	})
+
+	for _, function := range []string{parser.AggregateSum, parser.AggregateAvg, parser.AggregateMin, parser.AggregateMax} {
+		fields := query.ActionAggregateFields(model, action, function)
+		if len(fields) == 0 {
+			continue
+		}
+
+		functionMessage := &proto.Message{
+			Name:   makeAggregateMessageName(action.Name.Value, casing.ToCamel(function)),
+			Fields: []*proto.MessageField{},
+		}
+
+		for _, field := range fields {
+			typeInfo := scm.parserFieldToProtoTypeInfo(field)
+
+			switch {
+			case function == parser.AggregateAvg:
+				// The average of integers is not always an integer
+				typeInfo = &proto.TypeInfo{Type: proto.Type_TYPE_DECIMAL}
+			case function == parser.AggregateSum && typeInfo.GetType() == proto.Type_TYPE_INT:
+				// Postgres sums integers as bigints and so they are still integers
+				typeInfo = &proto.TypeInfo{Type: proto.Type_TYPE_INT}
+			}
+
+			functionMessage.Fields = append(functionMessage.Fields, &proto.MessageField{
+				Name:        field.Name.Value,
+				MessageName: functionMessage.GetName(),
+				Type:        typeInfo,
+				Nullable:    true,
+			})
+		}
+
+		scm.proto.Messages = append(scm.proto.Messages, functionMessage)
+
+		resultMessage.Fields = append(resultMessage.Fields, &proto.MessageField{
+			Name:        function,
+			MessageName: resultMessage.GetName(),
+			Type: &proto.TypeInfo{
+				Type:        proto.Type_TYPE_MESSAGE,
+				MessageName: wrapperspb.String(functionMessage.GetName()),
+			},
+		})
+	}
+
+	responseMessage := &proto.Message{
+		Name: makeAggregateMessageName(action.Name.Value, "Response"),
+		Fields: []*proto.MessageField{
+			{
+				Name:        "results",
+				MessageName: makeAggregateMessageName(action.Name.Value, "Response"),
+				Type: &proto.TypeInfo{
+					Type:        proto.Type_TYPE_MESSAGE,
+					MessageName: wrapperspb.String(resultMessage.GetName()),
+					Repeated:    true,
+				},
+			},
+		},
+	}
+
+	scm.proto.Messages = append(scm.proto.Messages, resultMessage, responseMessage)
+
+	return responseMessage.GetName()
+}
+
 func (scm *Builder) makeModel(decl *parser.DeclarationNode) {
 	protoModel := &proto.Model{
 		Name: decl.Model.Name.Value,
@@ -1799,6 +2024,14 @@ func (scm *Builder) makeField(parserField *parser.FieldNode, entityName string)
 		protoField.ForeignKeyInfo = &proto.ForeignKeyInfo{
 			RelatedEntityName:  entityField.Type.Value,
 			RelatedEntityField: parser.FieldNameId,
+			OnDelete:           relationOnDelete(entityField),
+		}
+
+		// When the relationship field is renamed then so is its foreign key column
+		renamedFrom := query.FieldGetAttribute(entityField, parser.AttributeRenamedFrom)
+		if renamedFrom != nil && len(renamedFrom.Arguments) == 1 {
+			name, _, _ := resolve.ToValue[string](renamedFrom.Arguments[0].Expression)
+			protoField.RenamedFrom = wrapperspb.String(fmt.Sprintf("%sId", name))
 		}
 	}
 
@@ -1836,9 +2069,10 @@ func (scm *Builder) setInverseFieldName(thisParserField *parser.FieldNode, thisP
 	// Use the field name in @relation(fieldName) if this attribute exists
 	relationAttr := query.FieldGetAttribute(thisParserField, parser.AttributeRelation)
 	if relationAttr != nil {
-		inverseFieldName := attributeFirstArgAsIdentifier(relationAttr)
-		thisProtoField.InverseFieldName = wrapperspb.String(inverseFieldName)
-		return
+		if inverseFieldName, ok := query.RelationAttributeValue(relationAttr); ok {
+			thisProtoField.InverseFieldName = wrapperspb.String(inverseFieldName)
+			return
+		}
 	}
 
 	// If no @relation attribute exists, then look for a match in the related entity fields' @relation attributes
@@ -1848,7 +2082,7 @@ func (scm *Builder) setInverseFieldName(thisParserField *parser.FieldNode, thisP
 		}
 		relationAttr := query.FieldGetAttribute(remoteField, parser.AttributeRelation)
 		if relationAttr != nil {
-			inverseFieldName := attributeFirstArgAsIdentifier(relationAttr)
+			inverseFieldName, _ := query.RelationAttributeValue(relationAttr)
 			if inverseFieldName == thisProtoField.GetName() {
 				thisProtoField.InverseFieldName = wrapperspb.String(remoteField.Name.Value)
 				return
@@ -1874,13 +2108,35 @@ func (scm *Builder) setInverseFieldName(thisParserField *parser.FieldNode, thisP
 	}
 }
 
-// attributeFirstArgAsIdentifier fishes out the identifier being held
-// by the first argument of the given attribute. It must only be called when
-// you know that it is well formed for that.
-func attributeFirstArgAsIdentifier(attr *parser.AttributeNode) string {
-	expr := attr.Arguments[0].Expression
-	theString, _ := resolve.AsIdent(expr)
-	return theString.String()
+// relationOnDelete returns the referential action defined by @relation(onDelete: ...) on a relationship field.
+func relationOnDelete(field *parser.FieldNode) proto.OnDelete {
+	relation := query.FieldGetAttribute(field, parser.AttributeRelation)
+	if relation == nil {
+		return proto.OnDelete_ON_DELETE_UNKNOWN
+	}
+
+	arg := query.RelationOnDeleteArgument(relation)
+	if arg == nil {
+		return proto.OnDelete_ON_DELETE_UNKNOWN
+	}
+
+	ident, err := resolve.AsIdent(arg.Expression)
+	if err != nil {
+		return proto.OnDelete_ON_DELETE_UNKNOWN
+	}
+
+	switch ident.String() {
+	case parser.OnDeleteCascade:
+		return proto.OnDelete_ON_DELETE_CASCADE
+	case parser.OnDeleteSetNull:
+		return proto.OnDelete_ON_DELETE_SET_NULL
+	case parser.OnDeleteRestrict:
+		return proto.OnDelete_ON_DELETE_RESTRICT
+	case parser.OnDeleteNoAction:
+		return proto.OnDelete_ON_DELETE_NO_ACTION
+	default:
+		return proto.OnDelete_ON_DELETE_UNKNOWN
+	}
 }
 
 func (scm *Builder) makeActions(actions []*parser.ActionNode, modelName string, builtIn bool) []*proto.Action {
@@ -1941,6 +2197,13 @@ func (scm *Builder) makeAction(action *parser.ActionNode, modelName string, buil
 		}
 
 		protoAction.ResponseMessageName = action.Returns[0].Type.ToString()
+	} else if action.Type.Value == parser.ActionTypeAggregate {
+		// aggregate actions have a response message of the grouped aggregates
+		protoAction.InputMessageName = scm.makeActionInputMessages(model, action)
+		protoAction.ResponseMessageName = scm.makeAggregateResponseMessage(model, action)
+	} else if action.IsBulk() {
+		// bulk actions take many items, each of which is the input of the single record action
+		protoAction.InputMessageName = scm.makeBulkInputMessage(model, action)
 	} else {
 		// we need to generate the messages representing the inputs to the scm.Messages
 		protoAction.InputMessageName = scm.makeActionInputMessages(model, action)
@@ -2118,6 +2381,17 @@ func (scm *Builder) applyModelAttribute(parserModel *parser.ModelNode, protoMode
 		perm := scm.permissionAttributeToProtoPermission(attribute)
 		perm.EntityName = protoModel.GetName()
 		protoModel.Permissions = append(protoModel.Permissions, perm)
+	case parser.AttributeRenamedFrom:
+		renamedFrom, _, _ := resolve.ToValue[string](attribute.Arguments[0].Expression)
+		protoModel.RenamedFrom = wrapperspb.String(renamedFrom)
+	case parser.AttributeSoftDelete:
+		protoModel.SoftDelete = true
+	case parser.AttributeSearchable:
+		for _, arg := range attribute.Arguments {
+			protoModel.SearchableFields = append(protoModel.SearchableFields, arg.Expression.String())
+		}
+	case parser.AttributeIndex:
+		protoModel.Indexes = append(protoModel.Indexes, indexAttributeToProtoIndex(attribute))
 	case parser.AttributeOn:
 		subscriberName, _ := resolve.AsIdent(attribute.Arguments[1].Expression)
 
@@ -2313,8 +2587,97 @@ func (scm *Builder) applyActionAttributes(action *parser.ActionNode, protoAction
 				expr := arg.Expression.String()
 				protoAction.Facets = append(protoAction.Facets, expr)
 			}
+		case parser.AttributeGroupBy:
+			for _, arg := range attribute.Arguments {
+				expr := arg.Expression.String()
+				protoAction.GroupBy = append(protoAction.GroupBy, expr)
+			}
+		case parser.AttributeAggregate:
+			for _, arg := range attribute.Arguments {
+				if arg.Label == nil {
+					continue
+				}
+
+				function := mapToAggregateFunction(arg.Label.Value)
+				idents, _ := resolve.AsIdentArray(arg.Expression)
+				for _, ident := range idents {
+					protoAction.Aggregates = append(protoAction.Aggregates, &proto.Aggregate{
+						Function:  function,
+						FieldName: ident.String(),
+					})
+				}
+			}
+		}
+	}
+}
+
+func indexAttributeToProtoIndex(attribute *parser.AttributeNode) *proto.Index {
+	index := &proto.Index{}
+
+	for _, arg := range attribute.Arguments {
+		label := ""
+		if arg.Label != nil {
+			label = arg.Label.Value
+		}
+
+		switch label {
+		case "":
+			idents, _ := resolve.AsIdentArray(arg.Expression)
+			for _, ident := range idents {
+				index.FieldNames = append(index.FieldNames, ident.String())
+			}
+		case "descending":
+			idents, _ := resolve.AsIdentArray(arg.Expression)
+			for _, ident := range idents {
+				index.DescendingFieldNames = append(index.DescendingFieldNames, ident.String())
+			}
+		case "expression":
+			index.Expression = &proto.Expression{Source: arg.Expression.String()}
+		case "where":
+			index.Where = &proto.Expression{Source: arg.Expression.String()}
+		case "unique":
+			index.Unique, _, _ = resolve.ToValue[bool](arg.Expression)
+		}
+	}
+
+	return index
+}
+
+func vectorIndexAttributeToProtoVectorIndex(attribute *parser.AttributeNode) *proto.VectorIndex {
+	index := &proto.VectorIndex{
+		Metric: proto.VectorDistanceMetric_VECTOR_DISTANCE_METRIC_COSINE,
+	}
+
+	for _, arg := range attribute.Arguments {
+		label := ""
+		if arg.Label != nil {
+			label = arg.Label.Value
+		}
+
+		switch label {
+		case "":
+			switch arg.Expression.String() {
+			case parser.VectorIndexHnsw:
+				index.Method = proto.VectorIndexMethod_VECTOR_INDEX_METHOD_HNSW
+			case parser.VectorIndexIvfflat:
+				index.Method = proto.VectorIndexMethod_VECTOR_INDEX_METHOD_IVFFLAT
+			}
+		case "dimensions":
+			dimensions, _, _ := resolve.ToValue[int64](arg.Expression)
+			index.Dimensions = uint32(dimensions)
+		case "metric":
+			switch arg.Expression.String() {
+			case parser.VectorMetricCosine:
+				index.Metric = proto.VectorDistanceMetric_VECTOR_DISTANCE_METRIC_COSINE
+			case parser.VectorMetricL2:
+				index.Metric = proto.VectorDistanceMetric_VECTOR_DISTANCE_METRIC_L2
+			case parser.VectorMetricInnerProduct:
+				index.Metric = proto.VectorDistanceMetric_VECTOR_DISTANCE_METRIC_INNER_PRODUCT
+			}
 		}
 	}
+
+	return index
 }
 
 func (scm *Builder) applyFieldAttributes(parserField *parser.FieldNode, protoField *proto.Field) {
@@ -2333,6 +2696,9 @@ func (scm *Builder) applyFieldAttributes(parserField *parser.FieldNode, protoFie
 				startsAt, _, _ := resolve.ToValue[int64](fieldAttribute.Arguments[1].Expression)
 				protoField.Sequence.StartsAt = uint32(startsAt)
 			}
+		case parser.AttributeRenamedFrom:
+			renamedFrom, _, _ := resolve.ToValue[string](fieldAttribute.Arguments[0].Expression)
+			protoField.RenamedFrom = wrapperspb.String(renamedFrom)
 		case parser.AttributePrimaryKey:
 			protoField.PrimaryKey = true
 			protoField.Unique = true
@@ -2350,6 +2716,8 @@ func (scm *Builder) applyFieldAttributes(parserField *parser.FieldNode, protoFie
 			protoField.ComputedExpression = &proto.Expression{
 				Source: fieldAttribute.Arguments[0].Expression.String(),
 			}
+		case parser.AttributeVectorIndex:
+			protoField.VectorIndex = vectorIndexAttributeToProtoVectorIndex(fieldAttribute)
 		case parser.AttributeRelation:
 			// We cannot process this field attribute here. But here is an explanation
 			// of why that is so - for future readers.
@@ -2406,6 +2774,18 @@ func (scm *Builder) mapToActionType(actionType string) proto.ActionType {
 		return proto.ActionType_ACTION_TYPE_LIST
 	case parser.ActionTypeDelete:
 		return proto.ActionType_ACTION_TYPE_DELETE
+	case parser.ActionTypeRestore:
+		return proto.ActionType_ACTION_TYPE_RESTORE
+	case parser.ActionTypeUpsert:
+		return proto.ActionType_ACTION_TYPE_UPSERT
+	case parser.ActionTypeCreateMany:
+		return proto.ActionType_ACTION_TYPE_CREATE_MANY
+	case parser.ActionTypeUpdateMany:
+		return proto.ActionType_ACTION_TYPE_UPDATE_MANY
+	case parser.ActionTypeDeleteMany:
+		return proto.ActionType_ACTION_TYPE_DELETE_MANY
+	case parser.ActionTypeAggregate:
+		return proto.ActionType_ACTION_TYPE_AGGREGATE
 	case parser.ActionTypeRead:
 		return proto.ActionType_ACTION_TYPE_READ
 	case parser.ActionTypeWrite:
@@ -2415,6 +2795,21 @@ func (scm *Builder) mapToActionType(actionType string) proto.ActionType {
 	}
 }
 
+func mapToAggregateFunction(function string) proto.AggregateFunction {
+	switch function {
+	case parser.AggregateSum:
+		return proto.AggregateFunction_AGGREGATE_FUNCTION_SUM
+	case parser.AggregateAvg:
+		return proto.AggregateFunction_AGGREGATE_FUNCTION_AVG
+	case parser.AggregateMin:
+		return proto.AggregateFunction_AGGREGATE_FUNCTION_MIN
+	case parser.AggregateMax:
+		return proto.AggregateFunction_AGGREGATE_FUNCTION_MAX
+	default:
+		return proto.AggregateFunction_AGGREGATE_FUNCTION_UNKNOWN
+	}
+}
+
 func mapToEventType(actionType proto.ActionType) string {
 	switch actionType {
 	case proto.ActionType_ACTION_TYPE_CREATE:
@@ -2444,16 +2839,29 @@ func (scm *Builder) applyJobAttribute(protoJob *proto.Job, attribute *parser.Att
 	case parser.AttributePermission:
 		protoJob.Permissions = append(protoJob.Permissions, scm.permissionAttributeToProtoPermission(attribute))
 	case parser.AttributeSchedule:
-		val, _, _ := resolve.ToValue[string](attribute.Arguments[0].Expression)
-		src := strings.TrimPrefix(val, `"`)
-		src = strings.TrimSuffix(src, `"`)
+		protoJob.Schedule = scheduleAttributeToProtoSchedule(attribute)
+	}
+}
+
+// scheduleAttributeToProtoSchedule converts the cron expression of a @schedule attribute to crontab
+// syntax, along with the time zone from its optional timezone argument.
+func scheduleAttributeToProtoSchedule(attribute *parser.AttributeNode) *proto.Schedule {
+	schedule := &proto.Schedule{}
 
-		c, _ := cron.Parse(src)
+	for _, arg := range attribute.Arguments {
+		val, _, _ := resolve.ToValue[string](arg.Expression)
+		src := strings.Trim(val, `"`)
 
-		protoJob.Schedule = &proto.Schedule{
-			Expression: c.String(),
+		switch {
+		case arg.Label == nil:
+			c, _ := cron.Parse(src)
+			schedule.Expression = c.String()
+		case arg.Label.Value == "timezone":
+			schedule.Timezone = src
 		}
 	}
+
+	return schedule
 }
 
 func (scm *Builder) applyJobInputs(protoMessage *proto.Message, inputs []*parser.JobInputNode) {
@@ -2481,14 +2889,7 @@ func (scm *Builder) applyFlowAttribute(protoFlow *proto.Flow, attribute *parser.
 	case parser.AttributePermission:
 		protoFlow.Permissions = append(protoFlow.Permissions, scm.permissionAttributeToProtoPermission(attribute))
 	case parser.AttributeSchedule:
-		val, _, _ := resolve.ToValue[string](attribute.Arguments[0].Expression)
-		src := strings.Trim(val, `"`)
-
-		c, _ := cron.Parse(src)
-
-		protoFlow.Schedule = &proto.Schedule{
-			Expression: c.String(),
-		}
+		protoFlow.Schedule = scheduleAttributeToProtoSchedule(attribute)
 	}
 }
 
@@ -2544,6 +2945,14 @@ func makeValuesMessageName(opName string) string {
 	return fmt.Sprintf("%sValues", casing.ToCamel(opName))
 }
 
+func makeItemMessageName(opName string) string {
+	return fmt.Sprintf("%sItem", casing.ToCamel(opName))
+}
+
+func makeAggregateMessageName(opName string, suffix string) string {
+	return fmt.Sprintf("%s%s", casing.ToCamel(opName), suffix)
+}
+
 func makeMessageName(opName string) string {
 	return fmt.Sprintf("%sMessage", casing.ToCamel(opName))
 }
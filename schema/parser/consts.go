@@ -77,6 +77,20 @@ const (
 	ActionTypeList   = "list"
 	ActionTypeDelete = "delete"
 
+	// Restores a record from a model with @softDelete.
+	ActionTypeRestore = "restore"
+
+	// Creates a record or updates the existing record with the same unique fields.
+	ActionTypeUpsert = "upsert"
+
+	// Bulk action types, which perform their single record action for each of many items.
+	ActionTypeCreateMany = "createMany"
+	ActionTypeUpdateMany = "updateMany"
+	ActionTypeDeleteMany = "deleteMany"
+
+	// Counts and aggregates the fields of records, grouped by some fields.
+	ActionTypeAggregate = "aggregate"
+
 	// Arbitrary function action types.
 	ActionTypeRead  = "read"
 	ActionTypeWrite = "write"
@@ -86,18 +100,38 @@ var ActionTypes = []string{
 	ActionTypeCreate,
 	ActionTypeGet,
 	ActionTypeDelete,
+	ActionTypeRestore,
 	ActionTypeList,
 	ActionTypeUpdate,
+	ActionTypeUpsert,
+	ActionTypeCreateMany,
+	ActionTypeUpdateMany,
+	ActionTypeDeleteMany,
+	ActionTypeAggregate,
 	ActionTypeRead,
 	ActionTypeWrite,
 }
 
+// MaxBulkItems is the most items that a bulk action can write in one request. Larger imports must be split
+// across several requests.
+const MaxBulkItems = 1000
+
+// BulkActionTypes maps each bulk action type to the action type it performs for each item.
+var BulkActionTypes = map[string]string{
+	ActionTypeCreateMany: ActionTypeCreate,
+	ActionTypeUpdateMany: ActionTypeUpdate,
+	ActionTypeDeleteMany: ActionTypeDelete,
+}
+
 // All models get a field named "id" implicitly. This set of constants provides
 // the set of this, and other similar implicit fields.
 const (
 	FieldNameId        = "id"
 	FieldNameCreatedAt = "createdAt"
 	FieldNameUpdatedAt = "updatedAt"
+
+	// Only added to models with @softDelete.
+	FieldNameDeletedAt = "deletedAt"
 )
 
 var (
@@ -131,25 +165,61 @@ const (
 )
 
 const (
-	AttributeUnique     = "unique"
-	AttributePermission = "permission"
-	AttributeWhere      = "where"
-	AttributeSet        = "set"
-	AttributePrimaryKey = "primaryKey"
-	AttributeDefault    = "default"
-	AttributeValidate   = "validate"
-	AttributeRelation   = "relation"
-	AttributeOrderBy    = "orderBy"
-	AttributeSortable   = "sortable"
-	AttributeSchedule   = "schedule"
-	AttributeFunction   = "function"
-	AttributeOn         = "on"
-	AttributeEmbed      = "embed"
-	AttributeComputed   = "computed"
-	AttributeFacet      = "facet"
-	AttributeSequence   = "sequence"
+	AttributeUnique      = "unique"
+	AttributePermission  = "permission"
+	AttributeWhere       = "where"
+	AttributeSet         = "set"
+	AttributePrimaryKey  = "primaryKey"
+	AttributeDefault     = "default"
+	AttributeValidate    = "validate"
+	AttributeRelation    = "relation"
+	AttributeOrderBy     = "orderBy"
+	AttributeSortable    = "sortable"
+	AttributeSchedule    = "schedule"
+	AttributeFunction    = "function"
+	AttributeOn          = "on"
+	AttributeEmbed       = "embed"
+	AttributeComputed    = "computed"
+	AttributeFacet       = "facet"
+	AttributeSequence    = "sequence"
+	AttributeRenamedFrom = "renamedFrom"
+	AttributeSoftDelete  = "softDelete"
+	AttributeSearchable  = "searchable"
+	AttributeVectorIndex = "vectorIndex"
+	AttributeIndex       = "index"
+	AttributeGroupBy     = "groupBy"
+	AttributeAggregate   = "aggregate"
+)
+
+const (
+	VectorIndexHnsw    = "hnsw"
+	VectorIndexIvfflat = "ivfflat"
 )
 
+const (
+	OnDeleteRestrict = "restrict"
+	OnDeleteCascade  = "cascade"
+	OnDeleteSetNull  = "setNull"
+	OnDeleteNoAction = "noAction"
+)
+
+const (
+	VectorMetricCosine       = "cosine"
+	VectorMetricL2           = "l2"
+	VectorMetricInnerProduct = "innerProduct"
+)
+
+// The aggregate functions of @aggregate.
+const (
+	AggregateSum = "sum"
+	AggregateAvg = "avg"
+	AggregateMin = "min"
+	AggregateMax = "max"
+)
+
+// The intervals which the Date and Timestamp fields of @groupBy can be bucketed by.
+var AggregateIntervals = []string{"day", "week", "month", "quarter", "year"}
+
 const (
 	OrderByAscending  = "asc"
 	OrderByDescending = "desc"
@@ -286,6 +286,21 @@ type ActionNode struct {
 	BuiltIn bool
 }
 
+// IsBulk returns true if the action performs its single record action for each of many items.
+func (a *ActionNode) IsBulk() bool {
+	_, ok := BulkActionTypes[a.Type.Value]
+	return ok
+}
+
+// BaseType returns the action type performed for each record, which for bulk actions is
+// the single record action type, e.g. create for createMany.
+func (a *ActionNode) BaseType() string {
+	if t, ok := BulkActionTypes[a.Type.Value]; ok {
+		return t
+	}
+	return a.Type.Value
+}
+
 func (a *ActionNode) IsArbitraryFunction() bool {
 	return a.IsFunction() && (a.Type.Value == ActionTypeRead || a.Type.Value == ActionTypeWrite)
 }
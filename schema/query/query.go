@@ -282,7 +282,7 @@ func ModelCreateActions(model *parser.ModelNode, filters ...ModelActionFilter) (
 	allFilters := []ModelActionFilter{}
 	allFilters = append(allFilters, filters...)
 	allFilters = append(allFilters, func(a *parser.ActionNode) bool {
-		return a.Type.Value == parser.ActionTypeCreate
+		return a.BaseType() == parser.ActionTypeCreate
 	})
 	return ModelActions(model, allFilters...)
 }
@@ -401,6 +401,33 @@ func FieldIsComputed(field *parser.FieldNode) bool {
 	return FieldHasAttribute(field, parser.AttributeComputed)
 }
 
+// UpsertConstraintFields returns the fields of the unique constraint on the model which is made up of exactly
+// the given fields, either a single unique field or those of a composite @unique. Returns nil if there is none.
+func UpsertConstraintFields(model *parser.ModelNode, fieldNames []string) []string {
+	if len(fieldNames) == 1 {
+		field := model.Field(fieldNames[0])
+		if field != nil && FieldIsUnique(field) {
+			return fieldNames
+		}
+	}
+
+	for _, attribute := range ModelAttributes(model) {
+		if attribute.Name.Value != parser.AttributeUnique {
+			continue
+		}
+
+		uniqueFields := lo.Map(CompositeUniqueFields(model, attribute), func(f *parser.FieldNode, _ int) string {
+			return f.Name.Value
+		})
+
+		if len(uniqueFields) == len(fieldNames) && lo.Every(uniqueFields, fieldNames) {
+			return uniqueFields
+		}
+	}
+
+	return nil
+}
+
 // CompositeUniqueFields returns the model's fields that make up a composite unique attribute.
 func CompositeUniqueFields(model *parser.ModelNode, attribute *parser.AttributeNode) []*parser.FieldNode {
 	if attribute.Name.Value != parser.AttributeUnique {
@@ -466,6 +493,62 @@ func ActionSortableFieldNames(action *parser.ActionNode) ([]string, error) {
 	return fields, nil
 }
 
+// ActionGroupByFields returns the model fields of the @groupBy attribute of an aggregate action,
+// skipping any arguments which are not fields of the model.
+func ActionGroupByFields(model *parser.ModelNode, action *parser.ActionNode) []*parser.FieldNode {
+	fields := []*parser.FieldNode{}
+
+	for _, attr := range action.Attributes {
+		if attr.Name.Value != parser.AttributeGroupBy {
+			continue
+		}
+
+		for _, arg := range attr.Arguments {
+			ident, err := resolve.AsIdent(arg.Expression)
+			if err != nil {
+				continue
+			}
+
+			if field := model.Field(ident.String()); field != nil {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	return fields
+}
+
+// ActionAggregateFields returns the model fields which the given function (e.g. sum) is applied to by the
+// @aggregate attribute of an aggregate action, skipping any which are not fields of the model.
+func ActionAggregateFields(model *parser.ModelNode, action *parser.ActionNode, function string) []*parser.FieldNode {
+	fields := []*parser.FieldNode{}
+
+	for _, attr := range action.Attributes {
+		if attr.Name.Value != parser.AttributeAggregate {
+			continue
+		}
+
+		for _, arg := range attr.Arguments {
+			if arg.Label == nil || arg.Label.Value != function {
+				continue
+			}
+
+			idents, err := resolve.AsIdentArray(arg.Expression)
+			if err != nil {
+				continue
+			}
+
+			for _, ident := range idents {
+				if field := model.Field(ident.String()); field != nil {
+					fields = append(fields, field)
+				}
+			}
+		}
+	}
+
+	return fields
+}
+
 func ModelFieldNames(model *parser.ModelNode) []string {
 	names := []string{}
 	for _, field := range ModelFields(model, ExcludeBuiltInFields) {
@@ -723,7 +806,7 @@ func GetRelationshipCandidates(asts []*parser.AST, entity parser.Entity, field *
 					continue
 				}
 
-				attr := FieldGetAttribute(f, parser.AttributeRelation)
+				attr := relationAttribute(f)
 				if attr != nil {
 					if relation, ok := RelationAttributeValue(attr); ok {
 						if relation == otherField.Name.Value {
@@ -738,7 +821,7 @@ func GetRelationshipCandidates(asts []*parser.AST, entity parser.Entity, field *
 				candidates = append(candidates, &Relationship{Entity: otherEntity, Field: otherField})
 			}
 
-			if FieldHasAttribute(field, parser.AttributeRelation) || FieldHasAttribute(otherField, parser.AttributeRelation) {
+			if relationAttribute(field) != nil || relationAttribute(otherField) != nil {
 				relationAttributeExists = true
 			}
 		}
@@ -749,7 +832,7 @@ func GetRelationshipCandidates(asts []*parser.AST, entity parser.Entity, field *
 		relationOnlyCandidates := []*Relationship{}
 
 		for _, relationship := range candidates {
-			if FieldHasAttribute(field, parser.AttributeRelation) || FieldHasAttribute(relationship.Field, parser.AttributeRelation) {
+			if relationAttribute(field) != nil || relationAttribute(relationship.Field) != nil {
 				relationOnlyCandidates = append(relationOnlyCandidates, relationship)
 			}
 		}
@@ -800,7 +883,7 @@ func ValidOneToHasMany(belongsTo *parser.FieldNode, hasMany *parser.FieldNode) b
 	}
 
 	// If belongsTo has @relation, check the field name matches hasMany
-	belongsToAttribute := FieldGetAttribute(belongsTo, parser.AttributeRelation)
+	belongsToAttribute := relationAttribute(belongsTo)
 	if belongsToAttribute != nil {
 		if relation, ok := RelationAttributeValue(belongsToAttribute); ok {
 			if relation != hasMany.Name.Value {
@@ -812,7 +895,7 @@ func ValidOneToHasMany(belongsTo *parser.FieldNode, hasMany *parser.FieldNode) b
 	}
 
 	// If hasMany has @relation, then this is not a candidate
-	hasManyAttribute := FieldGetAttribute(hasMany, parser.AttributeRelation)
+	hasManyAttribute := relationAttribute(hasMany)
 
 	return hasManyAttribute == nil
 }
@@ -830,13 +913,13 @@ func ValidUniqueOneToHasOne(hasOne *parser.FieldNode, belongsTo *parser.FieldNod
 		return false
 	}
 
-	otherFieldAttribute := FieldGetAttribute(belongsTo, parser.AttributeRelation)
+	otherFieldAttribute := relationAttribute(belongsTo)
 	if otherFieldAttribute != nil {
 		return false
 	}
 
 	// If hasOne has @relation, check the field name matches belongsTo
-	hasOneAttribute := FieldGetAttribute(hasOne, parser.AttributeRelation)
+	hasOneAttribute := relationAttribute(hasOne)
 	if hasOneAttribute != nil {
 		if relation, ok := RelationAttributeValue(hasOneAttribute); ok {
 			if relation != belongsTo.Name.Value {
@@ -848,18 +931,20 @@ func ValidUniqueOneToHasOne(hasOne *parser.FieldNode, belongsTo *parser.FieldNod
 	}
 
 	// If belongsTo has @relation, then this is not a candidate
-	belongsToAttribute := FieldGetAttribute(belongsTo, parser.AttributeRelation)
+	belongsToAttribute := relationAttribute(belongsTo)
 
 	return belongsToAttribute == nil
 }
 
-// RelationAttributeValue attempts to retrieve the value of the @relation attribute.
+// RelationAttributeValue attempts to retrieve the value of the @relation attribute, which is
+// the name of the field on the related model.
 func RelationAttributeValue(attr *parser.AttributeNode) (field string, ok bool) {
-	if len(attr.Arguments) != 1 {
+	arg := relationFieldArgument(attr)
+	if arg == nil {
 		return "", false
 	}
 
-	operand, err := resolve.AsIdent(attr.Arguments[0].Expression)
+	operand, err := resolve.AsIdent(arg.Expression)
 	if err != nil {
 		return "", false
 	}
@@ -870,3 +955,34 @@ func RelationAttributeValue(attr *parser.AttributeNode) (field string, ok bool)
 
 	return operand.Fragments[0], true
 }
+
+// RelationOnDeleteArgument returns the onDelete argument of the @relation attribute, if it has one.
+func RelationOnDeleteArgument(attr *parser.AttributeNode) *parser.AttributeArgumentNode {
+	for _, arg := range attr.Arguments {
+		if arg.Label != nil && arg.Label.Value == "onDelete" {
+			return arg
+		}
+	}
+	return nil
+}
+
+// relationFieldArgument returns the unlabelled argument of the @relation attribute, which names the
+// field on the related model.
+func relationFieldArgument(attr *parser.AttributeNode) *parser.AttributeArgumentNode {
+	for _, arg := range attr.Arguments {
+		if arg.Label == nil {
+			return arg
+		}
+	}
+	return nil
+}
+
+// relationAttribute returns the @relation attribute of the field only when it names a field on the
+// related model, as @relation(onDelete: ...) alone does not determine the other side of the relationship.
+func relationAttribute(field *parser.FieldNode) *parser.AttributeNode {
+	attr := FieldGetAttribute(field, parser.AttributeRelation)
+	if attr == nil || relationFieldArgument(attr) == nil {
+		return nil
+	}
+	return attr
+}
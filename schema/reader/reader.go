@@ -11,6 +11,8 @@ import (
 type Inputs struct {
 	Directory   string
 	SchemaFiles []*SchemaFile
+	// The up migrations in the project's migrations directory
+	MigrationFiles []*MigrationFile
 }
 
 type SchemaFile struct {
@@ -18,10 +20,16 @@ type SchemaFile struct {
 	Contents string
 }
 
+type MigrationFile struct {
+	FileName string
+	Contents string
+}
+
 // FromDir constructs an Inputs instance by selecting relevant
 // files from the given directory.
 //
-// So far it only looks for *.keel files and puts those in the SchemaFiles field.
+// It looks for *.keel files and puts those in the SchemaFiles field, and the up migrations
+// in the migrations directory which are put in the MigrationFiles field.
 func FromDir(dirName string) (*Inputs, error) {
 	inputs := &Inputs{
 		Directory:   dirName,
@@ -51,9 +59,37 @@ func FromDir(dirName string) (*Inputs, error) {
 		}
 	}
 
+	migrationFiles, err := MigrationsFromDir(dirName)
+	if err != nil {
+		return nil, err
+	}
+	inputs.MigrationFiles = migrationFiles
+
 	return inputs, nil
 }
 
+// MigrationsFromDir reads the up migrations in the migrations directory of the given project directory.
+func MigrationsFromDir(dirName string) ([]*MigrationFile, error) {
+	fileNames, err := filepath.Glob(filepath.Join(dirName, "migrations", "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := []*MigrationFile{}
+	for _, fName := range fileNames {
+		fileBytes, err := os.ReadFile(fName)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &MigrationFile{
+			FileName: fName,
+			Contents: string(fileBytes),
+		})
+	}
+
+	return files, nil
+}
+
 func FromFile(filename string) (*Inputs, error) {
 	fileBytes, err := os.ReadFile(filename)
 	if err != nil {
@@ -176,7 +176,7 @@ func (scm *Builder) makeFromInputs(allInputFiles *reader.Inputs) (*proto.Schema,
 		return nil, &parseErrors
 	}
 
-	v := validation.NewValidator(asts)
+	v := validation.NewValidator(asts).WithMigrationFiles(allInputFiles.MigrationFiles)
 	validationErrors := v.RunAllValidators(false)
 	if validationErrors != nil {
 		return nil, validationErrors
@@ -212,7 +212,7 @@ func (scm *Builder) ValidateFromInputs(inputs *reader.Inputs, includeWarnings bo
 		return &parseErrors
 	}
 
-	v := validation.NewValidator(asts)
+	v := validation.NewValidator(asts).WithMigrationFiles(inputs.MigrationFiles)
 	return v.RunAllValidators(includeWarnings)
 }
 
@@ -278,6 +278,21 @@ func (scm *Builder) insertBuiltInFields(declarations *parser.AST) {
 
 		switch {
 		case decl.Model != nil:
+			if lo.ContainsBy(decl.Model.Sections, func(s *parser.ModelSectionNode) bool {
+				return s.Attribute != nil && s.Attribute.Name.Value == parser.AttributeSoftDelete
+			}) {
+				fields = append(fields, &parser.FieldNode{
+					BuiltIn:  true,
+					Optional: true,
+					Name: parser.NameNode{
+						Value: parser.FieldNameDeletedAt,
+					},
+					Type: parser.NameNode{
+						Value: parser.FieldTypeTimestamp,
+					},
+				})
+			}
+
 			var modelFieldsSection *parser.ModelSectionNode
 			for _, section := range decl.Model.Sections {
 				if len(section.Fields) > 0 {
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/teamkeel/keel/config"
 	"github.com/teamkeel/keel/schema"
+	"github.com/teamkeel/keel/schema/reader"
 	"github.com/teamkeel/keel/schema/validation/errorhandling"
 	"google.golang.org/protobuf/encoding/protojson"
 )
@@ -147,6 +148,52 @@ func TestValidation(t *testing.T) {
 	}
 }
 
+func TestStaleRenamedFromWarnings(t *testing.T) {
+	t.Parallel()
+
+	schemaFile := &reader.SchemaFile{
+		FileName: "schema.keel",
+		Contents: `
+model Author {
+	@renamedFrom("Writer")
+	fields {
+		fullName Text @renamedFrom("name")
+		nickname Text @renamedFrom("alias")
+	}
+}
+
+model Post {
+	fields {
+		author Author @renamedFrom("writer")
+	}
+}`,
+	}
+
+	builder := &schema.Builder{}
+	err := builder.ValidateFromInputs(&reader.Inputs{
+		SchemaFiles: []*reader.SchemaFile{schemaFile},
+		MigrationFiles: []*reader.MigrationFile{
+			{
+				FileName: "migrations/0002_rename_writer.up.sql",
+				Contents: "ALTER TABLE \"writer\" RENAME TO \"author\";\nALTER TABLE \"author\" RENAME COLUMN \"name\" TO \"full_name\";\nALTER TABLE \"post\" RENAME COLUMN \"writer_id\" TO \"author_id\";\n",
+			},
+		},
+	}, true)
+
+	verrs := &errorhandling.ValidationErrors{}
+	require.ErrorAs(t, err, &verrs)
+	require.Empty(t, verrs.Errors)
+
+	messages := lo.Map(verrs.Warnings, func(w *errorhandling.ValidationError, _ int) string {
+		return w.Message
+	})
+	require.ElementsMatch(t, []string{
+		"Author has already been renamed in a migration, so @renamedFrom no longer has any effect",
+		"Author.fullName has already been renamed in a migration, so @renamedFrom no longer has any effect",
+		"Post.author has already been renamed in a migration, so @renamedFrom no longer has any effect",
+	}, messages)
+}
+
 func BenchmarkValidation(t *testing.B) {
 	testCaseDir := "{{your schema directory}}"
 	builder := &schema.Builder{}
@@ -0,0 +1,281 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/expressions/resolve"
+	"github.com/teamkeel/keel/formatting"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+// The field types each function of @aggregate can be applied to.
+var aggregateFieldTypes = map[string][]string{
+	parser.AggregateSum: {parser.FieldTypeNumber, parser.FieldTypeDecimal},
+	parser.AggregateAvg: {parser.FieldTypeNumber, parser.FieldTypeDecimal},
+	parser.AggregateMin: {parser.FieldTypeNumber, parser.FieldTypeDecimal, parser.FieldTypeDate, parser.FieldTypeTimestamp},
+	parser.AggregateMax: {parser.FieldTypeNumber, parser.FieldTypeDecimal, parser.FieldTypeDate, parser.FieldTypeTimestamp},
+}
+
+// AggregateAttributeRules validates that @groupBy and @aggregate are only used on aggregate actions, that
+// @groupBy is given the scalar fields of the model and that each function of @aggregate is given fields
+// of a type it can be applied to.
+func AggregateAttributeRules(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var currentModel *parser.ModelNode
+	var currentAction *parser.ActionNode
+	var groupByDefined bool
+	var aggregateDefined bool
+
+	return Visitor{
+		EnterModel: func(model *parser.ModelNode) {
+			currentModel = model
+		},
+		LeaveModel: func(_ *parser.ModelNode) {
+			currentModel = nil
+		},
+		EnterAction: func(action *parser.ActionNode) {
+			currentAction = action
+			groupByDefined = false
+			aggregateDefined = false
+		},
+		LeaveAction: func(_ *parser.ActionNode) {
+			currentAction = nil
+		},
+		EnterAttribute: func(attribute *parser.AttributeNode) {
+			if currentModel == nil || currentAction == nil {
+				return
+			}
+
+			if attribute.Name.Value != parser.AttributeGroupBy && attribute.Name.Value != parser.AttributeAggregate {
+				return
+			}
+
+			if currentAction.Type.Value != parser.ActionTypeAggregate {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeNotAllowedError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("@%s can only be used on aggregate actions", attribute.Name.Value),
+					},
+					attribute.Name,
+				))
+				return
+			}
+
+			switch attribute.Name.Value {
+			case parser.AttributeGroupBy:
+				if groupByDefined {
+					errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+						errorhandling.AttributeNotAllowedError,
+						errorhandling.ErrorDetails{
+							Message: "@groupBy can only be defined once per action",
+						},
+						attribute.Name,
+					))
+				}
+				groupByDefined = true
+
+				validateGroupByArguments(asts, currentModel, attribute, errs)
+			case parser.AttributeAggregate:
+				if aggregateDefined {
+					errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+						errorhandling.AttributeNotAllowedError,
+						errorhandling.ErrorDetails{
+							Message: "@aggregate can only be defined once per action",
+						},
+						attribute.Name,
+					))
+				}
+				aggregateDefined = true
+
+				validateAggregateArguments(currentModel, attribute, errs)
+			}
+		},
+	}
+}
+
+func validateGroupByArguments(asts []*parser.AST, model *parser.ModelNode, attribute *parser.AttributeNode, errs *errorhandling.ValidationErrors) {
+	if len(attribute.Arguments) == 0 {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeArgumentError,
+			errorhandling.ErrorDetails{
+				Message: "@groupBy requires at least one argument",
+				Hint:    "For example, use @groupBy(status, createdAt)",
+			},
+			attribute,
+		))
+		return
+	}
+
+	fieldNames := []string{}
+	for _, arg := range attribute.Arguments {
+		if arg.Label != nil {
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: "@groupBy arguments should not be labelled",
+					Hint:    "For example, use @groupBy(status, createdAt)",
+				},
+				arg,
+			))
+			continue
+		}
+
+		ident, err := resolve.AsIdent(arg.Expression)
+		if err != nil || len(ident.Fragments) != 1 {
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: "@groupBy argument is not correctly formatted",
+					Hint:    "For example, use @groupBy(status, createdAt)",
+				},
+				arg,
+			))
+			continue
+		}
+
+		name := ident.String()
+		field := model.Field(name)
+
+		switch {
+		case field == nil:
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("'%s' is not a field on %s", name, model.Name.Value),
+				},
+				ident,
+			))
+		case query.Model(asts, field.Type.Value) != nil:
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("'%s' is a relationship and cannot be grouped by", name),
+					Hint:    fmt.Sprintf("Group by the foreign key field instead, for e.g. %sId", name),
+				},
+				ident,
+			))
+		case lo.Contains([]string{parser.FieldTypeVector, parser.FieldTypeFile, parser.FieldTypeSecret, parser.FieldTypePassword}, field.Type.Value):
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("%s fields cannot be grouped by", field.Type.Value),
+				},
+				ident,
+			))
+		case lo.Contains(fieldNames, name):
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("'%s' has already been specified", name),
+				},
+				ident,
+			))
+		}
+
+		fieldNames = append(fieldNames, name)
+	}
+}
+
+func validateAggregateArguments(model *parser.ModelNode, attribute *parser.AttributeNode, errs *errorhandling.ValidationErrors) {
+	if len(attribute.Arguments) == 0 {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeArgumentError,
+			errorhandling.ErrorDetails{
+				Message: "@aggregate requires at least one argument",
+				Hint:    "For example, use @aggregate(sum: [total], avg: [total])",
+			},
+			attribute,
+		))
+		return
+	}
+
+	functions := []string{}
+	for _, arg := range attribute.Arguments {
+		if arg.Label == nil {
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: "@aggregate arguments must be labelled with sum, avg, min or max",
+					Hint:    "For example, use @aggregate(sum: [total], avg: [total])",
+				},
+				arg,
+			))
+			continue
+		}
+
+		function := arg.Label.Value
+		types, ok := aggregateFieldTypes[function]
+		if !ok {
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("'%s' is not an aggregate function. Valid functions are sum, avg, min or max", function),
+				},
+				arg.Label,
+			))
+			continue
+		}
+
+		if lo.Contains(functions, function) {
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("'%s' has already been specified", function),
+				},
+				arg.Label,
+			))
+			continue
+		}
+		functions = append(functions, function)
+
+		idents, err := resolve.AsIdentArray(arg.Expression)
+		if err != nil || len(idents) == 0 {
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("%s must be an array of field names", function),
+					Hint:    fmt.Sprintf("For example, use @aggregate(%s: [total])", function),
+				},
+				arg.Expression,
+			))
+			continue
+		}
+
+		fieldNames := []string{}
+		for _, ident := range idents {
+			name := ident.String()
+			field := model.Field(name)
+
+			switch {
+			case field == nil:
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("'%s' is not a field on %s", name, model.Name.Value),
+					},
+					ident,
+				))
+			case field.Repeated || !lo.Contains(types, field.Type.Value):
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("%s cannot be applied to '%s'. It can only be applied to fields of type %s", function, name, formatting.HumanizeList(types, formatting.DelimiterOr)),
+					},
+					ident,
+				))
+			case lo.Contains(fieldNames, name):
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("'%s' has already been specified", name),
+					},
+					ident,
+				))
+			}
+
+			fieldNames = append(fieldNames, name)
+		}
+	}
+}
@@ -65,6 +65,11 @@ func AttributeArgumentsRules(asts []*parser.AST, errs *errorhandling.ValidationE
 				template = map[string]bool{}
 
 				hint = "the @function attribute does not accept any arguments"
+			case parser.AttributeSoftDelete:
+				// No arguments
+				template = map[string]bool{}
+
+				hint = "the @softDelete attribute does not accept any arguments"
 			case parser.AttributeUnique:
 				if field == nil {
 					// A composite unique requires a single argument
@@ -88,12 +93,13 @@ func AttributeArgumentsRules(asts []*parser.AST, errs *errorhandling.ValidationE
 
 				hint = "the @where attribute accepts an expression as an argument, for e.g. @where(order.status == Status.Complete)"
 			case parser.AttributeRelation:
-				// A single required argument without a label
+				// An argument without a label, which can only be omitted when a referential action is given
 				template = map[string]bool{
-					"": true,
+					"":         len(attribute.Arguments) == 0,
+					"onDelete": false,
 				}
 
-				hint = "the @relation attribute accepts the field name on the related model, for e.g. @relation(author)"
+				hint = "the @relation attribute accepts the field name on the related model and what happens when the related record is deleted, for e.g. @relation(author, onDelete: restrict)"
 			case parser.AttributeSet:
 				// A single required argument without a label
 				template = map[string]bool{
@@ -102,12 +108,40 @@ func AttributeArgumentsRules(asts []*parser.AST, errs *errorhandling.ValidationE
 
 				hint = "the @set attribute sets a field on this model to some literal, for e.g. @set(order.status = Status.New)"
 			case parser.AttributeSchedule:
+				// A required argument without a label and an optional time zone
+				template = map[string]bool{
+					"":         true,
+					"timezone": false,
+				}
+
+				hint = `the @schedule attribute accepts cron syntax as a string and an optional time zone, for e.g. @schedule("every weekday at 9am", timezone: "Europe/London")`
+			case parser.AttributeVectorIndex:
+				// A required index method without a label, the number of dimensions and an optional distance metric
+				template = map[string]bool{
+					"":           true,
+					"dimensions": true,
+					"metric":     false,
+				}
+
+				hint = "the @vectorIndex attribute accepts the index method, the number of dimensions and an optional distance metric, for e.g. @vectorIndex(hnsw, dimensions: 1536, metric: cosine)"
+			case parser.AttributeIndex:
+				// Either the fields or the expression to index, along with optional settings
+				template = map[string]bool{
+					"":           false,
+					"expression": false,
+					"descending": false,
+					"where":      false,
+					"unique":     false,
+				}
+
+				hint = "the @index attribute accepts the fields to index and optionally a where expression, whether the index is unique and the fields in descending order, for e.g. @index([status, createdAt], where: post.published == true, descending: [createdAt])"
+			case parser.AttributeRenamedFrom:
 				// A single required argument without a label
 				template = map[string]bool{
 					"": true,
 				}
 
-				hint = `the @schedule attribute accepts cron syntax as a string, for e.g. @schedule("every weekday at 9am")`
+				hint = `the @renamedFrom attribute accepts the previous name as a string, for e.g. @renamedFrom("oldName")`
 			case parser.AttributePermission:
 				if task != nil {
 					template = map[string]bool{
@@ -24,7 +24,7 @@ func CreateNestedInputIsMany(asts []*parser.AST, errs *errorhandling.ValidationE
 				return
 			}
 
-			if n.Type.Value == parser.ActionTypeCreate {
+			if n.BaseType() == parser.ActionTypeCreate {
 				isCreateAction = true
 			}
 		},
@@ -0,0 +1,241 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/iancoleman/strcase"
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/expressions/resolve"
+	"github.com/teamkeel/keel/schema/attributes"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+// IndexAttributeRules validates that @index is given either the fields or an expression to index, and
+// that its fields and expressions only use the scalar fields of the model.
+func IndexAttributeRules(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var currentModel *parser.ModelNode
+
+	return Visitor{
+		EnterModel: func(m *parser.ModelNode) {
+			currentModel = m
+		},
+		LeaveModel: func(_ *parser.ModelNode) {
+			currentModel = nil
+		},
+		EnterAttribute: func(attr *parser.AttributeNode) {
+			if currentModel == nil || attr.Name.Value != parser.AttributeIndex {
+				return
+			}
+
+			var fields *parser.AttributeArgumentNode
+			var expression *parser.AttributeArgumentNode
+			var descending *parser.AttributeArgumentNode
+			fieldNames := []string{}
+
+			for _, arg := range attr.Arguments {
+				label := ""
+				if arg.Label != nil {
+					label = arg.Label.Value
+				}
+
+				switch label {
+				case "":
+					fields = arg
+					fieldNames = validateIndexFields(asts, currentModel, arg, errs)
+				case "expression":
+					expression = arg
+					validateIndexExpression(asts, currentModel, arg, false, errs)
+				case "where":
+					validateIndexExpression(asts, currentModel, arg, true, errs)
+				case "descending":
+					descending = arg
+				case "unique":
+					if _, isNull, err := resolve.ToValue[bool](arg.Expression); err != nil || isNull {
+						errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+							errorhandling.AttributeArgumentError,
+							errorhandling.ErrorDetails{
+								Message: "unique must be either true or false",
+							},
+							arg.Expression,
+						))
+					}
+				}
+			}
+
+			switch {
+			case fields == nil && expression == nil:
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: "@index requires either the fields or an expression to index",
+						Hint:    "For example, use @index([status, createdAt])",
+					},
+					attr.Name,
+				))
+			case fields != nil && expression != nil:
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: "@index cannot index both fields and an expression",
+					},
+					expression.Label,
+				))
+			}
+
+			if descending == nil {
+				return
+			}
+
+			if fields == nil {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: "descending can only be used when indexing fields",
+					},
+					descending.Label,
+				))
+				return
+			}
+
+			idents, err := resolve.AsIdentArray(descending.Expression)
+			if err != nil {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: "descending must be an array of the indexed fields",
+						Hint:    "For example, use @index([status, createdAt], descending: [createdAt])",
+					},
+					descending.Expression,
+				))
+				return
+			}
+
+			for _, ident := range idents {
+				if !lo.Contains(fieldNames, ident.String()) {
+					errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+						errorhandling.AttributeArgumentError,
+						errorhandling.ErrorDetails{
+							Message: fmt.Sprintf("'%s' is not one of the indexed fields", ident.String()),
+						},
+						ident,
+					))
+				}
+			}
+		},
+	}
+}
+
+// validateIndexFields validates the fields given to @index and returns their names.
+func validateIndexFields(asts []*parser.AST, model *parser.ModelNode, arg *parser.AttributeArgumentNode, errs *errorhandling.ValidationErrors) []string {
+	idents, err := resolve.AsIdentArray(arg.Expression)
+	if err != nil || len(idents) == 0 {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeArgumentError,
+			errorhandling.ErrorDetails{
+				Message: "@index fields must be an array of field names",
+				Hint:    "For example, use @index([status, createdAt])",
+			},
+			arg.Expression,
+		))
+		return nil
+	}
+
+	fieldNames := []string{}
+	for _, ident := range idents {
+		name := ident.String()
+		field := model.Field(name)
+
+		switch {
+		case field == nil:
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("'%s' is not a field on %s", name, model.Name.Value),
+				},
+				ident,
+			))
+		case query.Model(asts, field.Type.Value) != nil:
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("'%s' is a relationship and cannot be indexed", name),
+					Hint:    fmt.Sprintf("Index the foreign key field instead, for e.g. %sId", name),
+				},
+				ident,
+			))
+		case field.Type.Value == parser.FieldTypeVector || field.Type.Value == parser.FieldTypeFile:
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("%s fields cannot be indexed with @index", field.Type.Value),
+				},
+				ident,
+			))
+		case lo.Contains(fieldNames, name):
+			errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("'%s' has already been specified", name),
+				},
+				ident,
+			))
+		}
+
+		fieldNames = append(fieldNames, name)
+	}
+
+	return fieldNames
+}
+
+// validateIndexExpression validates the expression or where arguments of @index. Indexes are built from the
+// values of each row alone, so the expression can only refer to the fields of the model.
+func validateIndexExpression(asts []*parser.AST, model *parser.ModelNode, arg *parser.AttributeArgumentNode, isWhere bool, errs *errorhandling.ValidationErrors) {
+	validate := attributes.ValidateIndexExpression
+	if isWhere {
+		validate = attributes.ValidateIndexWhereExpression
+	}
+
+	issues, err := validate(asts, model, arg.Expression)
+	if err != nil {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeExpressionError,
+			errorhandling.ErrorDetails{
+				Message: "expression could not be parsed",
+			},
+			arg.Expression))
+		return
+	}
+
+	if len(issues) > 0 {
+		for _, issue := range issues {
+			errs.AppendError(issue)
+		}
+		return
+	}
+
+	operands, err := resolve.IdentOperands(arg.Expression)
+	if err != nil {
+		return
+	}
+
+	for _, operand := range operands {
+		if operand.Fragments[0] != strcase.ToLowerCamel(model.Name.Value) {
+			continue
+		}
+
+		field := model.Field(operand.Fragments[len(operand.Fragments)-1])
+		if len(operand.Fragments) == 2 && field != nil && query.Model(asts, field.Type.Value) == nil {
+			continue
+		}
+
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeExpressionError,
+			errorhandling.ErrorDetails{
+				Message: fmt.Sprintf("@index expressions can only use the fields of %s and not its relationships", model.Name.Value),
+			},
+			operand,
+		))
+	}
+}
@@ -24,6 +24,13 @@ func NameClashesRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) V
 
 			err := checkName(m.Name.Value, m.Name.Node)
 
+			if err != nil {
+				errs.AppendError(err)
+				return
+			}
+
+			err = checkEventTypeName(asts, m.Name.Value, m.Name.Node)
+
 			if err != nil {
 				errs.AppendError(err)
 			}
@@ -31,6 +38,13 @@ func NameClashesRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) V
 		EnterTask: func(t *parser.TaskNode) {
 			err := checkName(t.Name.Value, t.Name.Node)
 
+			if err != nil {
+				errs.AppendError(err)
+				return
+			}
+
+			err = checkEventTypeName(asts, t.Name.Value, t.Name.Node)
+
 			if err != nil {
 				errs.AppendError(err)
 			}
@@ -38,6 +52,17 @@ func NameClashesRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) V
 		EnterMessage: func(m *parser.MessageNode) {
 			err := checkMessageName(asts, m)
 
+			if err != nil {
+				errs.AppendError(err)
+				return
+			}
+
+			if m.BuiltIn {
+				return
+			}
+
+			err = checkEventTypeName(asts, m.Name.Value, m.Name.Node)
+
 			if err != nil {
 				errs.AppendError(err)
 			}
@@ -45,6 +70,13 @@ func NameClashesRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) V
 		EnterEnum: func(e *parser.EnumNode) {
 			err := checkName(e.Name.Value, e.Name.Node)
 
+			if err != nil {
+				errs.AppendError(err)
+				return
+			}
+
+			err = checkEventTypeName(asts, e.Name.Value, e.Name.Node)
+
 			if err != nil {
 				errs.AppendError(err)
 			}
@@ -82,6 +114,35 @@ func checkName(name string, node node.Node) *errorhandling.ValidationError {
 	return nil
 }
 
+// checkEventTypeName checks that the name does not clash with the <Model>Event type which is
+// generated for the GraphQL subscriptions of a model with events.
+func checkEventTypeName(asts []*parser.AST, name string, node node.Node) *errorhandling.ValidationError {
+	modelName, ok := strings.CutSuffix(name, "Event")
+	if !ok {
+		return nil
+	}
+
+	model := query.Model(asts, modelName)
+	if model == nil {
+		return nil
+	}
+
+	for _, attribute := range query.ModelAttributes(model) {
+		if attribute.Name.Value == parser.AttributeOn {
+			return errorhandling.NewValidationErrorWithDetails(
+				errorhandling.NamingError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("Reserved name '%s'", name),
+					Hint:    fmt.Sprintf("'%s' is used for the subscription events of the %s model", name, modelName),
+				},
+				node,
+			)
+		}
+	}
+
+	return nil
+}
+
 func getReservedSuffixes() []string {
 	return []string{"Input", "Connection", "Edge", "Values", "Where", "Request", "Response"}
 }
@@ -26,7 +26,7 @@ func NotMutableInputs(_ []*parser.AST, errs *errorhandling.ValidationErrors) Vis
 				return
 			}
 
-			if action.Type.Value != parser.ActionTypeCreate && action.Type.Value != parser.ActionTypeUpdate {
+			if !lo.Contains(ValidActionTypes, action.Type.Value) {
 				return
 			}
 
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/expressions/resolve"
 	"github.com/teamkeel/keel/schema/node"
 	"github.com/teamkeel/keel/schema/parser"
 	"github.com/teamkeel/keel/schema/query"
@@ -14,6 +16,8 @@ const (
 	learnMore = "To learn more about relationships, visit https://docs.keel.so/models#relationships"
 )
 
+var onDeleteActions = []string{parser.OnDeleteRestrict, parser.OnDeleteCascade, parser.OnDeleteSetNull, parser.OnDeleteNoAction}
+
 func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
 	candidates := map[*parser.FieldNode][]*query.Relationship{}
 	alreadyErrored := map[*parser.FieldNode]bool{}
@@ -178,10 +182,17 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 			}
 
 			var relation string
+			var onDelete *parser.AttributeArgumentNode
 			if relationAttr != nil {
 				var ok bool
 				relation, ok = query.RelationAttributeValue(relationAttr)
-				if !ok {
+				onDelete = query.RelationOnDeleteArgument(relationAttr)
+
+				// The field name can only be omitted when just the referential action is being defined
+				hasFieldArgument := lo.ContainsBy(relationAttr.Arguments, func(arg *parser.AttributeArgumentNode) bool {
+					return arg.Label == nil
+				})
+				if !ok && (hasFieldArgument || onDelete == nil) {
 					errs.AppendError(makeRelationshipError(
 						fmt.Sprintf("The @relation argument must refer to a field on %s", otherEntity.GetName()),
 						fmt.Sprintf("For example, @relation(fieldName). %s", learnMore),
@@ -201,6 +212,13 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 					))
 					return
 				}
+			}
+
+			if onDelete != nil && !validateOnDelete(currentField, onDelete, errs) {
+				return
+			}
+
+			if relation != "" {
 
 				// @relation field does not exist
 				otherField := otherEntity.Field(relation)
@@ -261,6 +279,21 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 				candidates[currentField] = fieldCandidates
 			}
 
+			// The referential action is applied to the foreign key, so it can only be defined on the side
+			// of the relationship which has one
+			if onDelete != nil && len(fieldCandidates) == 1 {
+				candidate := fieldCandidates[0]
+				if candidate.Field != nil &&
+					!query.ValidOneToHasMany(currentField, candidate.Field) &&
+					!query.ValidUniqueOneToHasOne(currentField, candidate.Field) {
+					errs.AppendError(makeRelationshipError(
+						"onDelete can only be defined on the side of the relationship which holds the foreign key",
+						fmt.Sprintf("Define onDelete on the '%s' field of %s instead. %s", candidate.Field.Name.Value, candidate.Entity.GetName(), learnMore),
+						onDelete.Label,
+					))
+				}
+			}
+
 			if len(fieldCandidates) == 0 && currentField.Repeated {
 				errs.AppendError(makeRelationshipError(
 					fmt.Sprintf("The field '%s' does not have an associated field on %s", currentField.Name.Value, currentField.Type.Value),
@@ -272,6 +305,30 @@ func RelationshipsRules(asts []*parser.AST, errs *errorhandling.ValidationErrors
 	}
 }
 
+// validateOnDelete validates the referential action given to @relation(onDelete: ...).
+func validateOnDelete(field *parser.FieldNode, arg *parser.AttributeArgumentNode, errs *errorhandling.ValidationErrors) bool {
+	ident, err := resolve.AsIdent(arg.Expression)
+	if err != nil || !lo.Contains(onDeleteActions, ident.String()) {
+		errs.AppendError(makeRelationshipError(
+			fmt.Sprintf("%s is not a valid onDelete action", arg.Expression.String()),
+			"The onDelete action must be one of restrict, cascade, setNull or noAction",
+			arg.Expression,
+		))
+		return false
+	}
+
+	if ident.String() == parser.OnDeleteSetNull && !field.Optional {
+		errs.AppendError(makeRelationshipError(
+			fmt.Sprintf("onDelete cannot be setNull as the '%s' field is not optional", field.Name.Value),
+			fmt.Sprintf("Either make '%s' optional or use another onDelete action. %s", field.Name.Value, learnMore),
+			arg.Expression,
+		))
+		return false
+	}
+
+	return true
+}
+
 // Removed top-level enterEntity and leaveEntity; logic now lives inside RelationshipsRules.
 func makeRelationshipError(message string, hint string, node node.ParserNode) *errorhandling.ValidationError {
 	return errorhandling.NewValidationErrorWithDetails(
@@ -0,0 +1,256 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/casing"
+	"github.com/teamkeel/keel/expressions/resolve"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/reader"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+var renamedFromRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*$`)
+
+// RenamedFromAttributeRules validates @renamedFrom attributes on models and fields:
+// - the argument must be a valid name, different to the current name
+// - the previous name must not be used by another model or field, as the rename would be ambiguous
+// - two models, or two fields of the same model, cannot be renamed from the same name
+//
+// Once a rename has been migrated the attribute is stale and should be removed,
+// which is reported by StaleRenamedFromRule.
+func RenamedFromAttributeRules(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var currentEntity parser.Entity
+	var currentField *parser.FieldNode
+
+	return Visitor{
+		EnterModel: func(m *parser.ModelNode) {
+			currentEntity = m
+		},
+		LeaveModel: func(m *parser.ModelNode) {
+			currentEntity = nil
+		},
+		EnterTask: func(t *parser.TaskNode) {
+			currentEntity = t
+		},
+		LeaveTask: func(t *parser.TaskNode) {
+			currentEntity = nil
+		},
+		EnterField: func(f *parser.FieldNode) {
+			currentField = f
+		},
+		LeaveField: func(f *parser.FieldNode) {
+			currentField = nil
+		},
+		EnterAttribute: func(attr *parser.AttributeNode) {
+			if attr.Name.Value != parser.AttributeRenamedFrom || currentEntity == nil || len(attr.Arguments) != 1 {
+				return
+			}
+
+			expr := attr.Arguments[0].Expression
+			previous, isNull, err := resolve.ToValue[string](expr)
+			if err != nil || isNull || !renamedFromRegex.MatchString(previous) {
+				errs.AppendError(
+					errorhandling.NewValidationErrorWithDetails(
+						errorhandling.AttributeArgumentError,
+						errorhandling.ErrorDetails{
+							Message: "the argument to @renamedFrom must be the previous name as a string",
+							Hint:    `For example, @renamedFrom("oldName")`,
+						},
+						expr,
+					),
+				)
+				return
+			}
+
+			if currentField == nil {
+				errs.Concat(validateModelRename(asts, currentEntity, attr, previous))
+			} else {
+				errs.Concat(validateFieldRename(currentEntity, currentField, attr, previous))
+			}
+		},
+	}
+}
+
+func validateModelRename(asts []*parser.AST, entity parser.Entity, attr *parser.AttributeNode, previous string) (errs errorhandling.ValidationErrors) {
+	expr := attr.Arguments[0].Expression
+
+	if previous == entity.GetName() {
+		errs.AppendError(
+			errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("%s cannot be renamed from its own name", entity.GetName()),
+				},
+				expr,
+			),
+		)
+		return
+	}
+
+	if query.Entity(asts, previous) != nil {
+		errs.AppendError(
+			errorhandling.NewValidationErrorWithDetails(
+				errorhandling.NamingError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("%s cannot be renamed from '%s' as there is still a %s with that name", entity.GetName(), previous, query.Entity(asts, previous).EntityType()),
+				},
+				expr,
+			),
+		)
+		return
+	}
+
+	for _, other := range query.Models(asts) {
+		if other == entity {
+			break
+		}
+
+		for _, a := range other.GetAttributes() {
+			if renamedFromValue(a) == previous {
+				errs.AppendError(
+					errorhandling.NewValidationErrorWithDetails(
+						errorhandling.NamingError,
+						errorhandling.ErrorDetails{
+							Message: fmt.Sprintf("%s has already been renamed from '%s'", other.GetName(), previous),
+						},
+						expr,
+					),
+				)
+				return
+			}
+		}
+	}
+
+	return
+}
+
+func validateFieldRename(entity parser.Entity, field *parser.FieldNode, attr *parser.AttributeNode, previous string) (errs errorhandling.ValidationErrors) {
+	expr := attr.Arguments[0].Expression
+
+	if previous == field.Name.Value {
+		errs.AppendError(
+			errorhandling.NewValidationErrorWithDetails(
+				errorhandling.AttributeArgumentError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("%s cannot be renamed from its own name", field.Name.Value),
+				},
+				expr,
+			),
+		)
+		return
+	}
+
+	if entity.Field(previous) != nil {
+		errs.AppendError(
+			errorhandling.NewValidationErrorWithDetails(
+				errorhandling.NamingError,
+				errorhandling.ErrorDetails{
+					Message: fmt.Sprintf("%s cannot be renamed from '%s' as %s still has a field with that name", field.Name.Value, previous, entity.GetName()),
+				},
+				expr,
+			),
+		)
+		return
+	}
+
+	for _, other := range entity.Fields() {
+		if other == field {
+			break
+		}
+
+		for _, a := range other.Attributes {
+			if renamedFromValue(a) == previous {
+				errs.AppendError(
+					errorhandling.NewValidationErrorWithDetails(
+						errorhandling.NamingError,
+						errorhandling.ErrorDetails{
+							Message: fmt.Sprintf("%s has already been renamed from '%s'", other.Name.Value, previous),
+						},
+						expr,
+					),
+				)
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// StaleRenamedFromRule warns about @renamedFrom attributes for which the rename is already in one of the
+// project's migration files, as the attribute no longer has any effect and should be removed.
+func StaleRenamedFromRule(asts []*parser.AST, migrationFiles []*reader.MigrationFile, errs *errorhandling.ValidationErrors) {
+	if len(migrationFiles) == 0 {
+		return
+	}
+
+	migrated := func(stmt string) bool {
+		return lo.ContainsBy(migrationFiles, func(f *reader.MigrationFile) bool {
+			return strings.Contains(f.Contents, stmt)
+		})
+	}
+
+	for _, entity := range query.Entities(asts) {
+		table := quoteIdentifier(entity.GetName())
+
+		for _, attr := range entity.GetAttributes() {
+			previous := renamedFromValue(attr)
+			if previous == "" {
+				continue
+			}
+
+			if migrated(fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", quoteIdentifier(previous), table)) {
+				errs.AppendWarning(staleRenamedFromWarning(entity.GetName(), attr))
+			}
+		}
+
+		for _, field := range entity.Fields() {
+			for _, attr := range field.Attributes {
+				previous := renamedFromValue(attr)
+				if previous == "" {
+					continue
+				}
+
+				// Relationship fields have no column, it's their foreign key field which is renamed
+				column, previousColumn := field.Name.Value, previous
+				if query.Model(asts, field.Type.Value) != nil {
+					column, previousColumn = column+"Id", previousColumn+"Id"
+				}
+
+				if migrated(fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", table, quoteIdentifier(previousColumn), quoteIdentifier(column))) {
+					errs.AppendWarning(staleRenamedFromWarning(fmt.Sprintf("%s.%s", entity.GetName(), field.Name.Value), attr))
+				}
+			}
+		}
+	}
+}
+
+func staleRenamedFromWarning(name string, attr *parser.AttributeNode) *errorhandling.ValidationError {
+	return errorhandling.NewValidationErrorWithDetails(
+		errorhandling.AttributeArgumentError,
+		errorhandling.ErrorDetails{
+			Message: fmt.Sprintf("%s has already been renamed in a migration, so @renamedFrom no longer has any effect", name),
+			Hint:    "Remove the @renamedFrom attribute",
+		},
+		attr,
+	)
+}
+
+// quoteIdentifier quotes the table or column name in the same way as generated migrations.
+func quoteIdentifier(name string) string {
+	return `"` + casing.ToSnake(name) + `"`
+}
+
+func renamedFromValue(attr *parser.AttributeNode) string {
+	if attr.Name.Value != parser.AttributeRenamedFrom || len(attr.Arguments) != 1 {
+		return ""
+	}
+
+	v, _, _ := resolve.ToValue[string](attr.Arguments[0].Expression)
+	return v
+}
@@ -17,7 +17,16 @@ var (
 		parser.ActionTypeUpdate,
 		parser.ActionTypeList,
 		parser.ActionTypeDelete,
+		parser.ActionTypeRestore,
+		parser.ActionTypeUpsert,
+		parser.ActionTypeCreateMany,
+		parser.ActionTypeUpdateMany,
+		parser.ActionTypeDeleteMany,
+		parser.ActionTypeAggregate,
 	}
+
+	// Restore, upsert, bulk and aggregate actions are only implemented by the runtime
+	validFunctionTypes = lo.Without(validActionTypes, parser.ActionTypeRestore, parser.ActionTypeUpsert, parser.ActionTypeCreateMany, parser.ActionTypeUpdateMany, parser.ActionTypeDeleteMany, parser.ActionTypeAggregate)
 )
 
 // validate only read+write can be used with returns
@@ -28,7 +37,7 @@ func ActionTypesRule(asts []*parser.AST) (errs errorhandling.ValidationErrors) {
 			return a.IsFunction()
 		}) {
 			hasReturns := len(function.Returns) > 0
-			validFunctionActionTypes := validActionTypes
+			validFunctionActionTypes := validFunctionTypes
 
 			if hasReturns {
 				validFunctionActionTypes = []string{parser.ActionTypeRead, parser.ActionTypeWrite}
@@ -211,7 +220,7 @@ func ActionModelInputsRule(asts []*parser.AST) (errs errorhandling.ValidationErr
 func CreateOperationNoReadInputsRule(asts []*parser.AST) (errs errorhandling.ValidationErrors) {
 	for _, model := range query.Models(asts) {
 		for _, action := range query.ModelActions(model) {
-			if action.Type.Value != parser.ActionTypeCreate {
+			if action.BaseType() != parser.ActionTypeCreate {
 				continue
 			}
 
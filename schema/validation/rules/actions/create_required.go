@@ -2,6 +2,7 @@ package actions
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/samber/lo"
@@ -14,14 +15,17 @@ import (
 
 // CreateOperationRequiredFieldsRule makes sure that all create operation are specified in such a way
 // that all the fields that must be populated during a create, are covered by either
-// inputs or set expressions.
+// inputs or set expressions. Upsert operations are included as they create the record
+// when it does not exist.
 // This includes (recursively) the fields in nested models where appropriate.
 func CreateOperationRequiredFieldsRule(
 	asts []*parser.AST) (errs errorhandling.ValidationErrors) {
 	for _, model := range query.Models(asts) {
 		rootModelName := casing.ToLowerCamel(model.Name.Value)
 
-		for _, op := range query.ModelCreateActions(model, func(a *parser.ActionNode) bool { return !a.IsFunction() }) {
+		for _, op := range query.ModelActions(model, func(a *parser.ActionNode) bool {
+			return !a.IsFunction() && (a.BaseType() == parser.ActionTypeCreate || a.Type.Value == parser.ActionTypeUpsert)
+		}) {
 			dotDelimPath := ""
 			for _, field := range query.ModelFields(model) {
 				if field.Type.Value == model.Name.Value && !field.Optional {
@@ -247,8 +251,14 @@ func setExpressions(action *parser.ActionNode) []*parser.Expression {
 
 // requiredFieldInWithInputs returns true if the given requiredField is
 // present the given action's "With" inputs and the input is required.
+// The unique lookup inputs of an upsert are also written when creating the record.
 func requiredFieldInWithInputs(requiredField string, action *parser.ActionNode) bool {
-	for _, input := range action.With {
+	inputs := action.With
+	if action.Type.Value == parser.ActionTypeUpsert {
+		inputs = append(slices.Clone(action.Inputs), action.With...)
+	}
+
+	for _, input := range inputs {
 		if input.Label == nil && input.Type.ToString() == requiredField && !input.Optional {
 			return true
 		}
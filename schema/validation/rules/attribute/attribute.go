@@ -79,6 +79,10 @@ var attributeLocations = map[string][]string{
 		parser.AttributePermission,
 		parser.AttributeUnique,
 		parser.AttributeOn,
+		parser.AttributeRenamedFrom,
+		parser.AttributeSoftDelete,
+		parser.AttributeSearchable,
+		parser.AttributeIndex,
 	},
 	parser.KeywordTask: {
 		parser.AttributePermission,
@@ -92,6 +96,8 @@ var attributeLocations = map[string][]string{
 		parser.AttributeRelation,
 		parser.AttributeComputed,
 		parser.AttributeSequence,
+		parser.AttributeRenamedFrom,
+		parser.AttributeVectorIndex,
 	},
 	parser.KeywordActions: {
 		parser.AttributeSet,
@@ -103,6 +109,8 @@ var attributeLocations = map[string][]string{
 		parser.AttributeFunction,
 		parser.AttributeEmbed,
 		parser.AttributeFacet,
+		parser.AttributeGroupBy,
+		parser.AttributeAggregate,
 	},
 	parser.KeywordJob: {
 		parser.AttributePermission,
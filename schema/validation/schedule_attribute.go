@@ -1,7 +1,12 @@
 package validation
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
+	"time"
+	// Embeds the time zone database so that time zones are validated the same on every platform
+	_ "time/tzdata"
 
 	"github.com/teamkeel/keel/cron"
 	"github.com/teamkeel/keel/expressions/resolve"
@@ -9,6 +14,9 @@ import (
 	"github.com/teamkeel/keel/schema/validation/errorhandling"
 )
 
+// ianaTimezoneRegex matches UTC and IANA time zone names in the Area/Location form, e.g. "Europe/London".
+var ianaTimezoneRegex = regexp.MustCompile(`^(UTC|[A-Z][A-Za-z_]+(/[A-Z][A-Za-z0-9_+\-]+)+)$`)
+
 func ScheduleAttributeRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
 	return Visitor{
 		EnterAttribute: func(attribute *parser.AttributeNode) {
@@ -33,7 +41,13 @@ func ScheduleAttributeRule(asts []*parser.AST, errs *errorhandling.ValidationErr
 				return
 			}
 
-			value, _, err := resolve.ToValue[string](attribute.Arguments[0].Expression)
+			for _, a := range attribute.Arguments[1:] {
+				if a.Label != nil && a.Label.Value == "timezone" {
+					validateTimezone(a, errs)
+				}
+			}
+
+			value, _, err := resolve.ToValue[string](arg.Expression)
 			if err != nil {
 				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
 					errorhandling.AttributeArgumentError,
@@ -89,3 +103,33 @@ func ScheduleAttributeRule(asts []*parser.AST, errs *errorhandling.ValidationErr
 		},
 	}
 }
+
+func validateTimezone(arg *parser.AttributeArgumentNode, errs *errorhandling.ValidationErrors) {
+	value, _, err := resolve.ToValue[string](arg.Expression)
+	if err != nil {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeArgumentError,
+			errorhandling.ErrorDetails{
+				Message: "timezone must be a string",
+				Hint:    "e.g. @schedule(\"every weekday at 9am\", timezone: \"Europe/London\")",
+			},
+			arg.Expression,
+		))
+		return
+	}
+
+	tz := strings.Trim(value, `"`)
+
+	// time.LoadLocation also accepts "Local", which is the time zone of whichever machine runs the schedule, and
+	// other files in the time zone database which are not IANA time zone names, so the name is checked first
+	if _, err := time.LoadLocation(tz); !ianaTimezoneRegex.MatchString(tz) || err != nil {
+		errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+			errorhandling.AttributeArgumentError,
+			errorhandling.ErrorDetails{
+				Message: fmt.Sprintf("'%s' is not a valid timezone", tz),
+				Hint:    "use an IANA time zone name, e.g. \"Europe/London\" or \"America/New_York\"",
+			},
+			arg.Expression,
+		))
+	}
+}
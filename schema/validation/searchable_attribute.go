@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/expressions/resolve"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+// SearchableAttributeRules validates that @searchable is only defined once on a model and
+// that its arguments are the text fields of the model.
+func SearchableAttributeRules(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var currentModel *parser.ModelNode
+	var currentAttribute *parser.AttributeNode
+	var searchable *parser.AttributeNode
+	var arguments []string
+
+	return Visitor{
+		EnterModel: func(m *parser.ModelNode) {
+			currentModel = m
+			searchable = nil
+		},
+		LeaveModel: func(_ *parser.ModelNode) {
+			currentModel = nil
+		},
+		EnterAttribute: func(attr *parser.AttributeNode) {
+			currentAttribute = attr
+			arguments = []string{}
+
+			if currentModel == nil || attr.Name.Value != parser.AttributeSearchable {
+				return
+			}
+
+			if searchable != nil {
+				errs.AppendError(
+					errorhandling.NewValidationErrorWithDetails(
+						errorhandling.AttributeNotAllowedError,
+						errorhandling.ErrorDetails{
+							Message: "@searchable can only be defined once per model",
+						},
+						attr.Name,
+					),
+				)
+			}
+
+			searchable = attr
+
+			if len(attr.Arguments) == 0 {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: "@searchable requires at least one argument",
+						Hint:    "For example, use @searchable(title, body)",
+					},
+					attr,
+				))
+			}
+		},
+		LeaveAttribute: func(_ *parser.AttributeNode) {
+			currentAttribute = nil
+			arguments = []string{}
+		},
+		EnterAttributeArgument: func(arg *parser.AttributeArgumentNode) {
+			if currentModel == nil || currentAttribute == nil || currentAttribute.Name.Value != parser.AttributeSearchable {
+				return
+			}
+
+			if arg.Label != nil {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: "@searchable arguments should not be labelled",
+						Hint:    "For example, use @searchable(title, body)",
+					},
+					arg,
+				))
+				return
+			}
+
+			ident, err := resolve.AsIdent(arg.Expression)
+			if err != nil || len(ident.Fragments) != 1 {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: "@searchable argument is not correctly formatted",
+						Hint:    "For example, use @searchable(title, body)",
+					},
+					arg,
+				))
+				return
+			}
+
+			argumentValue := ident.String()
+			modelField := currentModel.Field(argumentValue)
+
+			if modelField == nil {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("@searchable argument '%s' must correspond to a field on this model", argumentValue),
+					},
+					ident,
+				))
+				return
+			}
+
+			if modelField.Repeated || (modelField.Type.Value != parser.FieldTypeText && modelField.Type.Value != parser.FieldTypeMarkdown) {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("@searchable argument '%s' must be a Text or Markdown field", argumentValue),
+					},
+					ident,
+				))
+				return
+			}
+
+			if lo.Contains(arguments, argumentValue) {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeArgumentError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("@searchable argument name '%s' already defined", argumentValue),
+					},
+					ident,
+				))
+				return
+			}
+
+			arguments = append(arguments, argumentValue)
+		},
+	}
+}
@@ -40,6 +40,10 @@ func SequenceAttributeRules(asts []*parser.AST, errs *errorhandling.ValidationEr
 			if field == nil {
 				return
 			}
+			if attr.Name.Value == parser.AttributeRenamedFrom {
+				return
+			}
+
 			if attr.Name.Value != parser.AttributeSequence {
 				otherAttrs = append(otherAttrs, attr)
 				return
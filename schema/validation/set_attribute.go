@@ -18,6 +18,7 @@ var (
 	fieldsNotMutable = []string{
 		parser.FieldNameCreatedAt,
 		parser.FieldNameUpdatedAt,
+		parser.FieldNameDeletedAt,
 	}
 )
 
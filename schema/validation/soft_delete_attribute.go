@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+// SoftDeleteAttributeRules validates that @softDelete is only defined once on a model
+// and that restore actions are only defined on models with @softDelete.
+func SoftDeleteAttributeRules(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var currentModel *parser.ModelNode
+	var softDelete *parser.AttributeNode
+
+	return Visitor{
+		EnterModel: func(m *parser.ModelNode) {
+			currentModel = m
+			softDelete = nil
+		},
+		LeaveModel: func(_ *parser.ModelNode) {
+			currentModel = nil
+		},
+		EnterAttribute: func(attr *parser.AttributeNode) {
+			if currentModel == nil || attr.Name.Value != parser.AttributeSoftDelete {
+				return
+			}
+
+			if softDelete != nil {
+				errs.AppendError(
+					errorhandling.NewValidationErrorWithDetails(
+						errorhandling.AttributeNotAllowedError,
+						errorhandling.ErrorDetails{
+							Message: "@softDelete can only be defined once per model",
+						},
+						attr.Name,
+					),
+				)
+			}
+
+			softDelete = attr
+		},
+		EnterAction: func(action *parser.ActionNode) {
+			if currentModel == nil || action.Type.Value != parser.ActionTypeRestore {
+				return
+			}
+
+			if lo.ContainsBy(query.ModelAttributes(currentModel), func(a *parser.AttributeNode) bool {
+				return a.Name.Value == parser.AttributeSoftDelete
+			}) {
+				return
+			}
+
+			errs.AppendError(
+				errorhandling.NewValidationErrorWithDetails(
+					errorhandling.TypeError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("restore actions can only be defined on models with @softDelete, which %s does not have", currentModel.Name.Value),
+						Hint:    fmt.Sprintf("Add @softDelete to %s so that deleted records can be restored", currentModel.Name.Value),
+					},
+					action.Type,
+				),
+			)
+		},
+	}
+}
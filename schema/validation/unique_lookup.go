@@ -16,11 +16,14 @@ var (
 		parser.ActionTypeGet,
 		parser.ActionTypeUpdate,
 		parser.ActionTypeDelete,
+		parser.ActionTypeRestore,
+		parser.ActionTypeUpdateMany,
+		parser.ActionTypeDeleteMany,
 	}
 )
 
 // UniqueLookup checks that the filters will guarantee that one or zero record returned
-// for get, update and delete actions.
+// for get, update, delete and restore actions.
 func UniqueLookup(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
 	var model *parser.ModelNode
 	var action *parser.ActionNode
@@ -78,7 +81,7 @@ func UniqueLookup(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visi
 				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
 					errorhandling.ActionInputError,
 					errorhandling.ErrorDetails{
-						Message: fmt.Sprintf("The action '%s' can only %s a single record and therefore must be filtered by unique fields", action.Name.Value, action.Type.Value),
+						Message: fmt.Sprintf("The action '%s' can only %s a single record and therefore must be filtered by unique fields", action.Name.Value, action.BaseType()),
 						Hint:    "Did you mean to filter by 'id' or some other unique fields in the action's inputs or @where attributes?",
 					},
 					action.Name,
@@ -4,13 +4,14 @@ import (
 	"fmt"
 
 	"github.com/samber/lo"
+	"github.com/teamkeel/keel/casing"
 	"github.com/teamkeel/keel/schema/parser"
 	"github.com/teamkeel/keel/schema/query"
 	"github.com/teamkeel/keel/schema/validation/errorhandling"
 )
 
-// UpdateActionNestedInputsRule checks that the action inputs for update aren't referencing any relationship fields
-// apart from the foreign key.
+// UpdateActionNestedInputsRule checks that the action inputs for update and upsert aren't referencing any
+// relationship fields apart from the foreign key.
 func UpdateActionNestedInputsRule(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
 	var action *parser.ActionNode
 	var currentModel *parser.ModelNode
@@ -26,7 +27,7 @@ func UpdateActionNestedInputsRule(asts []*parser.AST, errs *errorhandling.Valida
 			if currentModel == nil {
 				return
 			}
-			if n.Type.Value == parser.ActionTypeUpdate {
+			if n.BaseType() == parser.ActionTypeUpdate || n.Type.Value == parser.ActionTypeUpsert {
 				action = n
 			}
 		},
@@ -58,8 +59,8 @@ func UpdateActionNestedInputsRule(asts []*parser.AST, errs *errorhandling.Valida
 					errs.AppendError(errorhandling.NewValidationErrorWithDetails(
 						errorhandling.ActionInputError,
 						errorhandling.ErrorDetails{
-							Message: "Update actions cannot perform field updates on nested models.",
-							Hint:    fmt.Sprintf("A %s's fields cannot be updated directly from a %s's update action.", model.Name.Value, currentModel.Name.Value),
+							Message: fmt.Sprintf("%s actions cannot perform field updates on nested models.", casing.ToCamel(action.Type.Value)),
+							Hint:    fmt.Sprintf("A %s's fields cannot be updated directly from a %s's %s action.", model.Name.Value, currentModel.Name.Value, action.Type.Value),
 						},
 						input,
 					))
@@ -0,0 +1,132 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+// UpsertActionRules validates that the inputs of upsert actions are exactly the fields of a unique constraint
+// on the model, which is used to find the record to update when it already exists.
+func UpsertActionRules(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var currentModel *parser.ModelNode
+
+	return Visitor{
+		EnterModel: func(m *parser.ModelNode) {
+			currentModel = m
+		},
+		LeaveModel: func(_ *parser.ModelNode) {
+			currentModel = nil
+		},
+		EnterAction: func(action *parser.ActionNode) {
+			if currentModel == nil || action.Type.Value != parser.ActionTypeUpsert || action.IsFunction() {
+				return
+			}
+
+			for _, attr := range action.Attributes {
+				if attr.Name.Value == parser.AttributeWhere {
+					errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+						errorhandling.AttributeNotAllowedError,
+						errorhandling.ErrorDetails{
+							Message: "@where cannot be used on upsert actions",
+							Hint:    "An upsert finds the existing record using the unique fields in its inputs",
+						},
+						attr.Name,
+					))
+				}
+			}
+
+			fieldNames := []string{}
+			valid := true
+			for _, input := range action.Inputs {
+				field := upsertInputField(asts, currentModel, input)
+				if field == nil {
+					errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+						errorhandling.ActionInputError,
+						errorhandling.ErrorDetails{
+							Message: fmt.Sprintf("'%s' cannot be used as an input to an upsert action", input.Name()),
+							Hint:    fmt.Sprintf("The inputs of an upsert action must be the unique fields of %s", currentModel.Name.Value),
+						},
+						input,
+					))
+					valid = false
+					continue
+				}
+
+				if input.Optional {
+					errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+						errorhandling.ActionInputError,
+						errorhandling.ErrorDetails{
+							Message: fmt.Sprintf("'%s' cannot be optional as it is used to find the existing record", input.Name()),
+						},
+						input,
+					))
+					valid = false
+					continue
+				}
+
+				// Null values are never equal to each other, so would always create a new record
+				if field.Optional {
+					errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+						errorhandling.ActionInputError,
+						errorhandling.ErrorDetails{
+							Message: fmt.Sprintf("'%s' cannot be used to find the existing record as it is an optional field", input.Name()),
+						},
+						input,
+					))
+					valid = false
+					continue
+				}
+
+				fieldNames = append(fieldNames, field.Name.Value)
+			}
+
+			if !valid {
+				return
+			}
+
+			if len(query.UpsertConstraintFields(currentModel, fieldNames)) == 0 {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.ActionInputError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("The upsert action '%s' must have inputs for exactly the fields of a unique constraint on %s", action.Name.Value, currentModel.Name.Value),
+						Hint:    "For example, upsert upsertProduct(sku) with (name, price) where sku is @unique",
+					},
+					action.Name,
+				))
+			}
+		},
+	}
+}
+
+// upsertInputField resolves an input of an upsert action to the field of the model it writes to. Relationships
+// can be used by their id, such as author.id, in which case the relationship field is returned.
+func upsertInputField(asts []*parser.AST, model *parser.ModelNode, input *parser.ActionInputNode) *parser.FieldNode {
+	if input.Label != nil {
+		return nil
+	}
+
+	fragments := lo.Map(input.Type.Fragments, func(f *parser.IdentFragment, _ int) string {
+		return f.Fragment
+	})
+
+	field := model.Field(fragments[0])
+	if field == nil {
+		return nil
+	}
+
+	switch {
+	case len(fragments) == 1 && query.IsForeignKey(asts, model, field):
+		return model.Field(strings.TrimSuffix(field.Name.Value, "Id"))
+	case len(fragments) == 1 && query.Entity(asts, field.Type.Value) == nil:
+		return field
+	case len(fragments) == 2 && fragments[1] == parser.FieldNameId && !field.Repeated && query.Entity(asts, field.Type.Value) != nil:
+		return field
+	default:
+		return nil
+	}
+}
@@ -2,6 +2,7 @@ package validation
 
 import (
 	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/reader"
 	"github.com/teamkeel/keel/schema/validation/errorhandling"
 	"github.com/teamkeel/keel/schema/validation/rules/actions"
 	"github.com/teamkeel/keel/schema/validation/rules/api"
@@ -12,7 +13,8 @@ import (
 )
 
 type Validator struct {
-	asts []*parser.AST
+	asts           []*parser.AST
+	migrationFiles []*reader.MigrationFile
 }
 
 func NewValidator(asts []*parser.AST) *Validator {
@@ -21,6 +23,13 @@ func NewValidator(asts []*parser.AST) *Validator {
 	}
 }
 
+// WithMigrationFiles sets the project's migration files, which are used to warn about
+// @renamedFrom attributes which have already been migrated.
+func (v *Validator) WithMigrationFiles(files []*reader.MigrationFile) *Validator {
+	v.migrationFiles = files
+	return v
+}
+
 // A Validator knows how to validate a parsed Keel schema.
 //
 // Conceptually we are validating a single schema.
@@ -76,6 +85,11 @@ var visitorFuncs = []VisitorFunc{
 	ComputedAttributeRules,
 	ComputedNullableFieldRules,
 	SequenceAttributeRules,
+	RenamedFromAttributeRules,
+	SoftDeleteAttributeRules,
+	SearchableAttributeRules,
+	VectorIndexAttributeRules,
+	IndexAttributeRules,
 	Jobs,
 	MessagesRule,
 	ScheduleAttributeRule,
@@ -89,7 +103,9 @@ var visitorFuncs = []VisitorFunc{
 	ApiDuplicateModelNamesRule,
 	StudioFeatures,
 	FacetAttributeRules,
+	AggregateAttributeRules,
 	UpdateActionNestedInputsRule,
+	UpsertActionRules,
 	RouteFunctions,
 	Flows,
 	//StudioFeatures, disabled temporarily as it's causing noise on non-studio builds
@@ -111,6 +127,8 @@ func (v *Validator) RunAllValidators(withWarnings bool) (errs *errorhandling.Val
 
 	runVisitors(v.asts, visitors)
 
+	StaleRenamedFromRule(v.asts, v.migrationFiles, errs)
+
 	// if we've got any warnings and they should be included, just return, no need to check for actual errors
 	if withWarnings && len(errs.Warnings) > 0 {
 		return errs
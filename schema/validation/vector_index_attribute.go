@@ -0,0 +1,126 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/teamkeel/keel/expressions/resolve"
+	"github.com/teamkeel/keel/schema/parser"
+	"github.com/teamkeel/keel/schema/query"
+	"github.com/teamkeel/keel/schema/validation/errorhandling"
+)
+
+var (
+	vectorIndexMethods = []string{parser.VectorIndexHnsw, parser.VectorIndexIvfflat}
+	vectorMetrics      = []string{parser.VectorMetricCosine, parser.VectorMetricL2, parser.VectorMetricInnerProduct}
+)
+
+// The most dimensions pgvector supports in an index
+const maxVectorIndexDimensions = 2000
+
+// VectorIndexAttributeRules validates @vectorIndex on vector fields, and that vector fields are only
+// used as list inputs when they are on the model being listed.
+func VectorIndexAttributeRules(asts []*parser.AST, errs *errorhandling.ValidationErrors) Visitor {
+	var currentModel *parser.ModelNode
+	var field *parser.FieldNode
+
+	return Visitor{
+		EnterModel: func(m *parser.ModelNode) {
+			currentModel = m
+		},
+		LeaveModel: func(_ *parser.ModelNode) {
+			currentModel = nil
+		},
+		EnterField: func(f *parser.FieldNode) {
+			field = f
+		},
+		LeaveField: func(_ *parser.FieldNode) {
+			field = nil
+		},
+		EnterAction: func(action *parser.ActionNode) {
+			if currentModel == nil || action.Type.Value != parser.ActionTypeList {
+				return
+			}
+
+			for _, input := range action.Inputs {
+				if input.Label != nil || len(input.Type.Fragments) < 2 {
+					continue
+				}
+
+				inputField := query.ResolveInputField(asts, input, currentModel)
+				if inputField == nil || inputField.Type.Value != parser.FieldTypeVector {
+					continue
+				}
+
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.ActionInputError,
+					errorhandling.ErrorDetails{
+						Message: fmt.Sprintf("'%s' cannot be used as an input as only vector fields on %s can be searched", input.Type.ToString(), currentModel.Name.Value),
+					},
+					input.Type,
+				))
+			}
+		},
+		EnterAttribute: func(attr *parser.AttributeNode) {
+			if field == nil || attr.Name.Value != parser.AttributeVectorIndex {
+				return
+			}
+
+			if field.Type.Value != parser.FieldTypeVector || field.Repeated {
+				errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+					errorhandling.AttributeNotAllowedError,
+					errorhandling.ErrorDetails{
+						Message: "@vectorIndex can only be used on Vector fields",
+					},
+					attr.Name,
+				))
+				return
+			}
+
+			for _, arg := range attr.Arguments {
+				label := ""
+				if arg.Label != nil {
+					label = arg.Label.Value
+				}
+
+				switch label {
+				case "":
+					ident, err := resolve.AsIdent(arg.Expression)
+					if err != nil || !lo.Contains(vectorIndexMethods, ident.String()) {
+						errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+							errorhandling.AttributeArgumentError,
+							errorhandling.ErrorDetails{
+								Message: fmt.Sprintf("%s is not a valid index method", arg.Expression.String()),
+								Hint:    "The index method must be either hnsw or ivfflat",
+							},
+							arg.Expression,
+						))
+					}
+				case "dimensions":
+					dimensions, isNull, err := resolve.ToValue[int64](arg.Expression)
+					if err != nil || isNull || dimensions < 1 || dimensions > maxVectorIndexDimensions {
+						errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+							errorhandling.AttributeArgumentError,
+							errorhandling.ErrorDetails{
+								Message: fmt.Sprintf("dimensions must be a number between 1 and %d", maxVectorIndexDimensions),
+							},
+							arg.Expression,
+						))
+					}
+				case "metric":
+					ident, err := resolve.AsIdent(arg.Expression)
+					if err != nil || !lo.Contains(vectorMetrics, ident.String()) {
+						errs.AppendError(errorhandling.NewValidationErrorWithDetails(
+							errorhandling.AttributeArgumentError,
+							errorhandling.ErrorDetails{
+								Message: fmt.Sprintf("%s is not a valid distance metric", arg.Expression.String()),
+								Hint:    "The distance metric must be one of cosine, l2 or innerProduct",
+							},
+							arg.Expression,
+						))
+					}
+				}
+			}
+		},
+	}
+}
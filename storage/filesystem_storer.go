@@ -0,0 +1,387 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/vincent-petithory/dataurl"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// FilesystemFilesPath is the path on the runtime from which files stored on the filesystem are served. It is
+// namespaced under /_keel as API names cannot begin with an underscore, so it never shadows an API.
+const FilesystemFilesPath = "/_keel/files/"
+
+var _ Storer = &FilesystemStore{}
+
+// FilesystemStore stores files in a local directory and generates signed URLs which are
+// served by the runtime itself. It is intended for local development and testing.
+type FilesystemStore struct {
+	tracer     trace.Tracer
+	Directory  string
+	BaseURL    string
+	SigningKey []byte
+}
+
+// fileMetadata is stored alongside each file as the filesystem has nowhere else to keep it.
+type fileMetadata struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+}
+
+// NewFilesystemStore creates a store which writes files to the given directory, creating it if
+// necessary. Download URLs are generated relative to baseURL and signed with signingKey.
+func NewFilesystemStore(directory string, baseURL string, signingKey []byte, tracer trace.Tracer) (*FilesystemStore, error) {
+	if len(signingKey) == 0 {
+		return nil, errors.New("signing key cannot be empty")
+	}
+
+	for _, prefix := range []string{FileObjectPrefix, UploadObjectPrefix} {
+		err := os.MkdirAll(filepath.Join(directory, prefix), 0o755)
+		if err != nil {
+			return nil, fmt.Errorf("creating files directory: %w", err)
+		}
+	}
+
+	return &FilesystemStore{
+		Directory:  directory,
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		SigningKey: signingKey,
+		tracer:     tracer,
+	}, nil
+}
+
+func (s FilesystemStore) GetFileInfo(ctx context.Context, key string) (FileInfo, error) {
+	filePath, err := s.filePath(FileObjectPrefix, key)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	stat, err := os.Stat(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return FileInfo{}, ErrFileNotFound
+	}
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	b, err := os.ReadFile(filePath + ".json")
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("reading file metadata: %w", err)
+	}
+
+	var metadata fileMetadata
+	err = json.Unmarshal(b, &metadata)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("parsing file metadata: %w", err)
+	}
+
+	return FileInfo{
+		Key:         key,
+		Filename:    metadata.Filename,
+		ContentType: metadata.ContentType,
+		Size:        int(stat.Size()),
+	}, nil
+}
+
+func (s FilesystemStore) Store(ctx context.Context, dataURL string) (FileInfo, error) {
+	var span trace.Span
+	ctx, span = s.tracer.Start(ctx, "Store File")
+	defer span.End()
+
+	durl, err := dataurl.DecodeString(dataURL)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("decoding dataurl: %w", err)
+	}
+
+	key := ksuid.New().String()
+	filePath, err := s.filePath(FileObjectPrefix, key)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	// The metadata is written first so the file is never visible without it.
+	err = writeFileMetadata(filePath, durl.Params["name"], durl.ContentType())
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	err = os.WriteFile(filePath, durl.Data, 0o644)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("storing file: %w", err)
+	}
+
+	return s.GetFileInfo(ctx, key)
+}
+
+func (s FilesystemStore) GenerateFileResponse(ctx context.Context, fi *FileInfo) (FileResponse, error) {
+	var span trace.Span
+	_, span = s.tracer.Start(ctx, "Hydrate File")
+	defer span.End()
+
+	return FileResponse{
+		Key:         fi.Key,
+		Filename:    fi.Filename,
+		ContentType: fi.ContentType,
+		Size:        fi.Size,
+		URL:         s.signedURL(FileObjectPrefix, fi.Key, time.Now().Add(FileObjectExpiryDuration)),
+	}, nil
+}
+
+func (s FilesystemStore) GenerateUploadURL(ctx context.Context, filename string, contentType string) (UploadResponse, error) {
+	var span trace.Span
+	_, span = s.tracer.Start(ctx, "Generate Upload URL")
+	defer span.End()
+
+	key := ksuid.New().String()
+	uploadPath, err := s.filePath(UploadObjectPrefix, key)
+	if err != nil {
+		return UploadResponse{}, err
+	}
+
+	// The filename and content type are recorded now as the upload itself is just the file's contents.
+	err = writeFileMetadata(uploadPath, filename, contentType)
+	if err != nil {
+		return UploadResponse{}, err
+	}
+
+	expiresAt := time.Now().Add(UploadURLExpiryDuration)
+
+	return UploadResponse{
+		Key:    key,
+		URL:    s.signedURL(UploadObjectPrefix, key, expiresAt),
+		Method: http.MethodPut,
+		Headers: map[string]string{
+			"Content-Type": contentType,
+		},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// WriteUpload saves the contents of a file uploaded to a URL from GenerateUploadURL.
+func (s FilesystemStore) WriteUpload(ctx context.Context, key string, contents io.Reader) error {
+	uploadPath, err := s.filePath(UploadObjectPrefix, key)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stat(uploadPath + ".json")
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrFileNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(uploadPath)
+	if err != nil {
+		return fmt.Errorf("storing upload: %w", err)
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, contents)
+	if err != nil {
+		return fmt.Errorf("storing upload: %w", err)
+	}
+
+	return f.Close()
+}
+
+func (s FilesystemStore) CompleteUpload(ctx context.Context, key string) (FileInfo, error) {
+	var span trace.Span
+	ctx, span = s.tracer.Start(ctx, "Complete Upload")
+	defer span.End()
+
+	uploadPath, err := s.filePath(UploadObjectPrefix, key)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	filePath, err := s.filePath(FileObjectPrefix, key)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	_, err = os.Stat(uploadPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return FileInfo{}, ErrFileNotFound
+	}
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	// As with storing, the metadata is moved first so the file is never visible without it.
+	err = os.Rename(uploadPath+".json", filePath+".json")
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("completing upload: %w", err)
+	}
+
+	err = os.Rename(uploadPath, filePath)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("completing upload: %w", err)
+	}
+
+	return s.GetFileInfo(ctx, key)
+}
+
+func (s FilesystemStore) DeleteExpiredUploads(ctx context.Context) (int, error) {
+	var span trace.Span
+	_, span = s.tracer.Start(ctx, "Delete Expired Uploads")
+	defer span.End()
+
+	dir := filepath.Join(s.Directory, UploadObjectPrefix)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, entry := range entries {
+		// Every upload has a metadata file, which is created along with its upload URL.
+		key, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return deleted, err
+		}
+
+		if time.Since(info.ModTime()) < UploadExpiryDuration {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(dir, key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return deleted, err
+		}
+
+		err = os.Remove(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+func (s FilesystemStore) GetFileData(ctx context.Context, key string) ([]byte, FileInfo, error) {
+	fi, err := s.GetFileInfo(ctx, key)
+	if err != nil {
+		return nil, FileInfo{}, err
+	}
+
+	filePath, err := s.filePath(FileObjectPrefix, key)
+	if err != nil {
+		return nil, FileInfo{}, err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, FileInfo{}, err
+	}
+
+	return data, fi, nil
+}
+
+// VerifySignature checks that the expires and signature query parameters of a download URL
+// were generated by this store for the given key, and that the URL has not expired.
+func (s FilesystemStore) VerifySignature(key string, expires string, signature string) error {
+	return s.verify(FileObjectPrefix, key, expires, signature)
+}
+
+// VerifyUploadSignature checks the query parameters of an upload URL in the same way
+// that VerifySignature does for download URLs.
+func (s FilesystemStore) VerifyUploadSignature(key string, expires string, signature string) error {
+	return s.verify(UploadObjectPrefix, key, expires, signature)
+}
+
+func (s FilesystemStore) verify(prefix string, key string, expires string, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if time.Now().Unix() > expiresAt {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(prefix, key, expiresAt))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// signedURL returns the URL on the runtime for the file, or upload, with the given key.
+func (s FilesystemStore) signedURL(prefix string, key string, expiresAt time.Time) string {
+	expires := expiresAt.Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.sign(prefix, key, expires))
+
+	path := FilesystemFilesPath
+	if prefix == UploadObjectPrefix {
+		path += UploadObjectPrefix
+	}
+
+	return fmt.Sprintf("%s%s%s?%s", s.BaseURL, path, url.PathEscape(key), query.Encode())
+}
+
+// sign generates the signature for a URL. Uploads are signed with their prefix so that
+// a download URL cannot be used to upload a file.
+func (s FilesystemStore) sign(prefix string, key string, expires int64) string {
+	message := fmt.Sprintf("%s:%d", key, expires)
+	if prefix == UploadObjectPrefix {
+		message = prefix + message
+	}
+
+	mac := hmac.New(sha256.New, s.SigningKey)
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// filePath returns the location of the file with the given key, making sure the key
+// cannot be used to reach outside of the files directory or to read a metadata file.
+func (s FilesystemStore) filePath(prefix string, key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || strings.Contains(key, ".") {
+		return "", ErrFileNotFound
+	}
+
+	return filepath.Join(s.Directory, prefix, key), nil
+}
+
+func writeFileMetadata(filePath string, filename string, contentType string) error {
+	metadata, err := json.Marshal(fileMetadata{
+		Filename:    filename,
+		ContentType: contentType,
+	})
+	if err != nil {
+		return fmt.Errorf("marshalling file metadata: %w", err)
+	}
+
+	err = os.WriteFile(filePath+".json", metadata, 0o644)
+	if err != nil {
+		return fmt.Errorf("storing file metadata: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package storage_test
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teamkeel/keel/storage"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func newFilesystemStore(t *testing.T) *storage.FilesystemStore {
+	store, err := storage.NewFilesystemStore(t.TempDir(), "http://localhost:8000/", []byte("secret"), noop.NewTracerProvider().Tracer("test"))
+	require.NoError(t, err)
+	return store
+}
+
+func TestFilesystemStoreStore(t *testing.T) {
+	ctx := context.Background()
+	store := newFilesystemStore(t)
+
+	fi, err := store.Store(ctx, "data:text/plain;name=hello.txt;base64,aGVsbG8gd29ybGQ=")
+	require.NoError(t, err)
+	require.NotEmpty(t, fi.Key)
+	require.Equal(t, "hello.txt", fi.Filename)
+	require.Equal(t, "text/plain", fi.ContentType)
+	require.Equal(t, 11, fi.Size)
+
+	info, err := store.GetFileInfo(ctx, fi.Key)
+	require.NoError(t, err)
+	require.Equal(t, fi, info)
+
+	data, info, err := store.GetFileData(ctx, fi.Key)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(data))
+	require.Equal(t, fi, info)
+}
+
+func TestFilesystemStoreFileNotFound(t *testing.T) {
+	ctx := context.Background()
+	store := newFilesystemStore(t)
+
+	for _, key := range []string{"2fjU8OAMFx4YYGdvpqvbeuOuTT4", "../secrets", "", ".", "2fjU8OAMFx4YYGdvpqvbeuOuTT4.json"} {
+		_, err := store.GetFileInfo(ctx, key)
+		require.ErrorIs(t, err, storage.ErrFileNotFound, key)
+	}
+}
+
+func TestFilesystemStoreSignedURL(t *testing.T) {
+	ctx := context.Background()
+	store := newFilesystemStore(t)
+
+	fi, err := store.Store(ctx, "data:text/plain;name=hello.txt;base64,aGVsbG8gd29ybGQ=")
+	require.NoError(t, err)
+
+	response, err := store.GenerateFileResponse(ctx, &fi)
+	require.NoError(t, err)
+
+	u, err := url.Parse(response.URL)
+	require.NoError(t, err)
+	require.Equal(t, "localhost:8000", u.Host)
+	require.Equal(t, storage.FilesystemFilesPath+fi.Key, u.Path)
+
+	expires := u.Query().Get("expires")
+	signature := u.Query().Get("signature")
+
+	require.NoError(t, store.VerifySignature(fi.Key, expires, signature))
+
+	// Signature is for a different key
+	require.ErrorIs(t, store.VerifySignature("2fjU8OAMFx4YYGdvpqvbeuOuTT4", expires, signature), storage.ErrInvalidSignature)
+
+	// Expiry has been tampered with
+	later := strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10)
+	require.ErrorIs(t, store.VerifySignature(fi.Key, later, signature), storage.ErrInvalidSignature)
+
+	// Signed by a different store
+	other := newFilesystemStore(t)
+	other.SigningKey = []byte("other")
+	require.ErrorIs(t, other.VerifySignature(fi.Key, expires, signature), storage.ErrInvalidSignature)
+}
+
+func TestFilesystemStoreExpiredSignature(t *testing.T) {
+	store := newFilesystemStore(t)
+
+	expired := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	require.ErrorIs(t, store.VerifySignature("2fjU8OAMFx4YYGdvpqvbeuOuTT4", expired, "abc"), storage.ErrInvalidSignature)
+}
+
+func TestFilesystemStoreUpload(t *testing.T) {
+	ctx := context.Background()
+	store := newFilesystemStore(t)
+
+	upload, err := store.GenerateUploadURL(ctx, "hello.txt", "text/plain")
+	require.NoError(t, err)
+	require.Equal(t, "PUT", upload.Method)
+	require.Equal(t, map[string]string{"Content-Type": "text/plain"}, upload.Headers)
+
+	u, err := url.Parse(upload.URL)
+	require.NoError(t, err)
+	require.Equal(t, storage.FilesystemFilesPath+"uploads/"+upload.Key, u.Path)
+
+	expires := u.Query().Get("expires")
+	signature := u.Query().Get("signature")
+	require.NoError(t, store.VerifyUploadSignature(upload.Key, expires, signature))
+
+	// An upload URL cannot be used to download a file
+	require.ErrorIs(t, store.VerifySignature(upload.Key, expires, signature), storage.ErrInvalidSignature)
+
+	// Nothing has been uploaded yet
+	_, err = store.CompleteUpload(ctx, upload.Key)
+	require.ErrorIs(t, err, storage.ErrFileNotFound)
+
+	require.NoError(t, store.WriteUpload(ctx, upload.Key, strings.NewReader("hello world")))
+
+	// The file is not available until the upload is completed
+	_, err = store.GetFileInfo(ctx, upload.Key)
+	require.ErrorIs(t, err, storage.ErrFileNotFound)
+
+	fi, err := store.CompleteUpload(ctx, upload.Key)
+	require.NoError(t, err)
+	require.Equal(t, storage.FileInfo{
+		Key:         upload.Key,
+		Filename:    "hello.txt",
+		ContentType: "text/plain",
+		Size:        11,
+	}, fi)
+
+	data, _, err := store.GetFileData(ctx, upload.Key)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(data))
+
+	// An upload can only be completed once
+	_, err = store.CompleteUpload(ctx, upload.Key)
+	require.ErrorIs(t, err, storage.ErrFileNotFound)
+}
+
+func TestFilesystemStoreWriteUploadRequiresUploadURL(t *testing.T) {
+	store := newFilesystemStore(t)
+
+	err := store.WriteUpload(context.Background(), "2fjU8OAMFx4YYGdvpqvbeuOuTT4", strings.NewReader("hello world"))
+	require.ErrorIs(t, err, storage.ErrFileNotFound)
+}
+
+func TestFilesystemStoreDeleteExpiredUploads(t *testing.T) {
+	ctx := context.Background()
+	store := newFilesystemStore(t)
+
+	expired, err := store.GenerateUploadURL(ctx, "old.txt", "text/plain")
+	require.NoError(t, err)
+	require.NoError(t, store.WriteUpload(ctx, expired.Key, strings.NewReader("old")))
+
+	recent, err := store.GenerateUploadURL(ctx, "new.txt", "text/plain")
+	require.NoError(t, err)
+	require.NoError(t, store.WriteUpload(ctx, recent.Key, strings.NewReader("new")))
+
+	old := time.Now().Add(-storage.UploadExpiryDuration - time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(store.Directory, storage.UploadObjectPrefix, expired.Key+".json"), old, old))
+
+	deleted, err := store.DeleteExpiredUploads(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	_, err = store.CompleteUpload(ctx, expired.Key)
+	require.ErrorIs(t, err, storage.ErrFileNotFound)
+
+	_, err = store.CompleteUpload(ctx, recent.Key)
+	require.NoError(t, err)
+}
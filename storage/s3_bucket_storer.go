@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/segmentio/ksuid"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 const (
@@ -108,6 +111,146 @@ func (s S3BucketStore) GenerateFileResponse(ctx context.Context, fi *FileInfo) (
 	return hydrated, nil
 }
 
+func (s S3BucketStore) GenerateUploadURL(ctx context.Context, filename string, contentType string) (UploadResponse, error) {
+	var span trace.Span
+	ctx, span = s.tracer.Start(ctx, "Generate Upload URL")
+	defer span.End()
+
+	if s.BucketName == "" {
+		return UploadResponse{}, errors.New("S3 bucket name cannot be empty")
+	}
+
+	key := ksuid.New().String()
+	pathedKey := path.Join(UploadObjectPrefix, key)
+
+	presignClient := s3.NewPresignClient(s.Client)
+
+	// The filename and content type are signed into the URL, so the upload must include them as headers.
+	request, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &s.BucketName,
+		Key:         &pathedKey,
+		ContentType: &contentType,
+		Metadata:    map[string]string{"filename": filename},
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = UploadURLExpiryDuration
+	})
+	if err != nil {
+		return UploadResponse{}, fmt.Errorf("couldn't get a presigned upload url for %s:%s. %w", s.BucketName, pathedKey, err)
+	}
+
+	headers := map[string]string{}
+	for k, v := range request.SignedHeader {
+		// The host header is set by the client making the request
+		if strings.EqualFold(k, "Host") {
+			continue
+		}
+		headers[k] = strings.Join(v, ",")
+	}
+
+	return UploadResponse{
+		Key:       key,
+		URL:       request.URL,
+		Method:    request.Method,
+		Headers:   headers,
+		ExpiresAt: time.Now().Add(UploadURLExpiryDuration),
+	}, nil
+}
+
+func (s S3BucketStore) CompleteUpload(ctx context.Context, key string) (FileInfo, error) {
+	var span trace.Span
+	ctx, span = s.tracer.Start(ctx, "Complete Upload")
+	defer span.End()
+
+	if s.BucketName == "" {
+		return FileInfo{}, errors.New("S3 bucket name cannot be empty")
+	}
+
+	// Keys are always generated by us, so anything else cannot have been uploaded.
+	if _, err := ksuid.Parse(key); err != nil {
+		return FileInfo{}, ErrFileNotFound
+	}
+
+	uploadKey := path.Join(UploadObjectPrefix, key)
+	pathedKey := path.Join(FileObjectPrefix, key)
+
+	object, err := s.Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: &s.BucketName,
+		Key:    &uploadKey,
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return FileInfo{}, ErrFileNotFound
+	}
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("completing upload: %w", err)
+	}
+
+	_, err = s.Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     &s.BucketName,
+		CopySource: aws.String(url.PathEscape(s.BucketName) + "/" + uploadKey),
+		Key:        &pathedKey,
+	})
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("completing upload: %w", err)
+	}
+
+	_, err = s.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &s.BucketName,
+		Key:    &uploadKey,
+	})
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("completing upload: %w", err)
+	}
+
+	return FileInfo{
+		Key:         key,
+		Filename:    object.Metadata["filename"],
+		ContentType: aws.ToString(object.ContentType),
+		Size:        int(aws.ToInt64(object.ContentLength)),
+	}, nil
+}
+
+func (s S3BucketStore) DeleteExpiredUploads(ctx context.Context) (int, error) {
+	var span trace.Span
+	ctx, span = s.tracer.Start(ctx, "Delete Expired Uploads")
+	defer span.End()
+
+	if s.BucketName == "" {
+		return 0, errors.New("S3 bucket name cannot be empty")
+	}
+
+	deleted := 0
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: &s.BucketName,
+		Prefix: aws.String(UploadObjectPrefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("listing uploads: %w", err)
+		}
+
+		for _, object := range page.Contents {
+			if object.LastModified == nil || time.Since(*object.LastModified) < UploadExpiryDuration {
+				continue
+			}
+
+			_, err = s.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+				Bucket: &s.BucketName,
+				Key:    object.Key,
+			})
+			if err != nil {
+				return deleted, fmt.Errorf("deleting upload: %w", err)
+			}
+
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
 func (s S3BucketStore) GetFileData(ctx context.Context, key string) ([]byte, FileInfo, error) {
 	if s.BucketName == "" {
 		return nil, FileInfo{}, errors.New("S3 bucket name cannot be empty")
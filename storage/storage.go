@@ -4,7 +4,23 @@ import (
 	"context"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
+)
+
+const (
+	// UploadObjectPrefix is where files uploaded directly to storage are kept until they are used in an action.
+	UploadObjectPrefix = "uploads/"
+	// UploadURLExpiryDuration is how long an upload URL can be used for.
+	UploadURLExpiryDuration = 15 * time.Minute
+	// UploadExpiryDuration is how long an uploaded file is kept for if it is not used in an action.
+	UploadExpiryDuration = 24 * time.Hour
+)
+
+var (
+	ErrFileNotFound     = errors.New("file not found")
+	ErrInvalidSignature = errors.New("invalid or expired file signature")
 )
 
 // Storer represents the interface for a file storing service that is used by the Keel runtime
@@ -24,6 +40,18 @@ type Storer interface {
 	//
 	// The use of this function is to generate any signed URLs for file downloads.
 	GenerateFileResponse(ctx context.Context, fi *FileInfo) (FileResponse, error)
+
+	// GenerateUploadURL will create a new file key and a URL which the file's contents can be uploaded
+	// to directly, rather than being sent to an action as a data URL.
+	GenerateUploadURL(ctx context.Context, filename string, contentType string) (UploadResponse, error)
+
+	// CompleteUpload will store a file which was uploaded using a URL from GenerateUploadURL so that
+	// it can be saved against a model. ErrFileNotFound is returned if nothing was uploaded for the key.
+	CompleteUpload(ctx context.Context, key string) (FileInfo, error)
+
+	// DeleteExpiredUploads will delete any uploaded files which were not completed
+	// within UploadExpiryDuration and return how many were deleted.
+	DeleteExpiredUploads(ctx context.Context) (int, error)
 }
 
 // FileInfo contains important data for the File type as stored in the database.
@@ -52,6 +80,17 @@ type FileResponse struct {
 	URL         string `json:"url"`
 }
 
+// UploadResponse is returned when requesting a URL to upload a file to.
+type UploadResponse struct {
+	// Key is given as the input to a File field once the file has been uploaded.
+	Key string `json:"key"`
+	URL string `json:"url"`
+	// Method and Headers must be used when uploading to the URL.
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
 func (fi *FileInfo) ToJSON() (string, error) {
 	json, err := json.Marshal(fi)
 	if err != nil {
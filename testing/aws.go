@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -41,6 +42,7 @@ func (h *AWSAPIHandler) HandleHTTP(r *http.Request, w http.ResponseWriter) {
 	s3Prefixes := []string{
 		h.PathPrefix + "files/",
 		h.PathPrefix + "jobs/",
+		h.PathPrefix + "uploads/",
 	}
 	isS3 := func() bool {
 		for _, prefix := range s3Prefixes {
@@ -63,12 +65,18 @@ func (h *AWSAPIHandler) HandleHTTP(r *http.Request, w http.ResponseWriter) {
 		return
 	case r.URL.Path == fmt.Sprintf("/aws/2015-03-31/functions/%s/invocations", h.FunctionsARN):
 		h.lambdaInvoke(r, w)
+	case r.Method == http.MethodPut && isS3() && r.Header.Get("X-Amz-Copy-Source") != "":
+		h.s3CopyObject(r, w)
+		return
 	case r.Method == http.MethodPut && isS3():
 		h.s3PutObject(r, w)
 		return
-	case r.Method == http.MethodGet && isS3():
+	case (r.Method == http.MethodGet || r.Method == http.MethodHead) && isS3():
 		h.s3GetObject(r, w)
 		return
+	case r.Method == http.MethodDelete && isS3():
+		h.s3DeleteObject(r, w)
+		return
 	default:
 		fmt.Println("Unhandled AWS request", r.Method, r.URL.Path, r.Header)
 		w.WriteHeader(http.StatusNotFound)
@@ -181,9 +189,48 @@ func (h *AWSAPIHandler) s3GetObject(r *http.Request, w http.ResponseWriter) {
 		}
 	}
 
+	// Set explicitly so that it's also returned for HeadObject requests
+	w.Header().Set("Content-Length", strconv.Itoa(len(f.Data)))
+
 	_, _ = w.Write(f.Data)
 }
 
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_CopyObject.html
+func (h *AWSAPIHandler) s3CopyObject(r *http.Request, w http.ResponseWriter) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	// The copy source is the bucket name followed by the key
+	source := r.Header.Get("X-Amz-Copy-Source")
+	_, sourceKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+
+	f, ok := h.S3Bucket[sourceKey]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(""))
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, h.PathPrefix)
+	h.S3Bucket[key] = &S3Object{
+		Headers: f.Headers.Clone(),
+		Data:    f.Data,
+	}
+
+	_, _ = w.Write([]byte("<CopyObjectResult></CopyObjectResult>"))
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteObject.html
+func (h *AWSAPIHandler) s3DeleteObject(r *http.Request, w http.ResponseWriter) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, h.PathPrefix)
+	delete(h.S3Bucket, key)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_SendMessage.html
 func (h *AWSAPIHandler) sqsSendMessage(r *http.Request, w http.ResponseWriter) {
 	requestBody, err := io.ReadAll(r.Body)
@@ -3,9 +3,11 @@ package testing
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/x509"
 	_ "embed"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
 	"errors"
@@ -32,6 +34,7 @@ import (
 	"github.com/teamkeel/keel/node"
 	"github.com/teamkeel/keel/proto"
 	"github.com/teamkeel/keel/runtime/apis/httpjson"
+	"github.com/teamkeel/keel/storage"
 	"github.com/teamkeel/keel/testhelpers"
 	"github.com/teamkeel/keel/util"
 )
@@ -58,6 +61,8 @@ type RunnerOpts struct {
 	TestGroupName string
 	// Generates a Keel client (keelClient.ts) for this test
 	GenerateClient bool
+	// Stores files on the filesystem, and serves them from the runtime, rather than in the mocked bucket
+	FilesystemStorage bool
 }
 
 var tracer = otel.Tracer("github.com/teamkeel/keel/testing")
@@ -153,6 +158,27 @@ func Run(ctx context.Context, opts *RunnerOpts) error {
 	)
 	pkBase64 := base64.StdEncoding.EncodeToString(pkPem)
 
+	var filesStore *storage.FilesystemStore
+	if opts.FilesystemStorage {
+		filesDir, err := os.MkdirTemp("", "keel-test-files")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(filesDir)
+
+		// Signed URLs only need to be valid for as long as the tests are running
+		signingKey := make([]byte, 32)
+		_, err = rand.Read(signingKey)
+		if err != nil {
+			return err
+		}
+
+		filesStore, err = storage.NewFilesystemStore(filesDir, serverURL, signingKey, tracer)
+		if err != nil {
+			return err
+		}
+	}
+
 	var functionsServer *node.DevelopmentServer
 
 	if node.HasFunctions(schema, config) {
@@ -177,6 +203,12 @@ func Run(ctx context.Context, opts *RunnerOpts) error {
 			"KEEL_API_URL": serverURL,
 		}
 
+		if filesStore != nil {
+			functionEnvVars["KEEL_FILES_DIRECTORY"] = filesStore.Directory
+			functionEnvVars["KEEL_FILES_URL"] = filesStore.BaseURL
+			functionEnvVars["KEEL_FILES_SIGNING_KEY"] = hex.EncodeToString(filesStore.SigningKey)
+		}
+
 		maps.Copy(functionEnvVars, envVars)
 
 		functionsServer, err = node.StartDevelopmentServer(ctx, opts.Dir, &node.ServerOpts{
@@ -329,7 +361,7 @@ func Run(ctx context.Context, opts *RunnerOpts) error {
 	os.Setenv("AWS_SESSION_TOKEN", "test")
 	os.Setenv("AWS_REGION", "test")
 
-	lambdaHandler, err = runtime.New(ctx, &runtime.HandlerArgs{
+	handlerArgs := &runtime.HandlerArgs{
 		LogLevel:       "warn",
 		SchemaPath:     path.Join(opts.Dir, ".build/runtime/schema.json"),
 		ConfigPath:     path.Join(opts.Dir, ".build/runtime/config.json"),
@@ -344,7 +376,12 @@ func Run(ctx context.Context, opts *RunnerOpts) error {
 
 		// Send all AWS API calls to our test server
 		AWSEndpoint: fmt.Sprintf("%s/aws", serverURL),
-	})
+	}
+	if filesStore != nil {
+		handlerArgs.FilesStorage = filesStore
+	}
+
+	lambdaHandler, err = runtime.New(ctx, handlerArgs)
 	if err != nil {
 		fmt.Println("error creating lambda runtime handler:", err)
 		return err
@@ -389,6 +426,13 @@ func Run(ctx context.Context, opts *RunnerOpts) error {
 		fmt.Sprintf("TEST_AWS_ENDPOINT=%s/aws", serverURL),
 		fmt.Sprintf("KEEL_FILES_BUCKET_NAME=%s", bucketName),
 	}...)
+	if filesStore != nil {
+		cmd.Env = append(cmd.Env,
+			fmt.Sprintf("KEEL_FILES_DIRECTORY=%s", filesStore.Directory),
+			fmt.Sprintf("KEEL_FILES_URL=%s", filesStore.BaseURL),
+			fmt.Sprintf("KEEL_FILES_SIGNING_KEY=%s", hex.EncodeToString(filesStore.SigningKey)),
+		)
+	}
 
 	return cmd.Run()
 }
@@ -491,8 +491,8 @@ func (g *Generator) generateGetEntryActionLinks() {
 		if idResponseFieldPath == "" {
 			continue
 		}
-		// get entry action for tools that operate on a model instance/s (create/update/list).
-		if tool.Action.IsList() || tool.Action.IsUpdate() || tool.Action.GetType() == proto.ActionType_ACTION_TYPE_CREATE {
+		// get entry action for tools that operate on a model instance/s (create/update/upsert/list).
+		if tool.Action.IsList() || tool.Action.IsUpdate() || tool.Action.IsUpsert() || tool.Action.GetType() == proto.ActionType_ACTION_TYPE_CREATE {
 			if getToolID := g.findGetByIDTool(tool.Model.GetName()); getToolID != "" {
 				tool.ActionConfig.GetEntryAction = &toolsproto.ToolLink{
 					ToolId: getToolID,
@@ -697,12 +697,16 @@ func (g *Generator) generateResponses() error {
 		}
 
 		// delete actions do not have a response
-		if tool.Action.IsDelete() {
+		if tool.Action.IsDelete() || tool.Action.GetType() == proto.ActionType_ACTION_TYPE_DELETE_MANY {
 			continue
 		}
 
 		// we don't have a response message, therefore the response will be the model...
 		pathPrefix := ""
+		// ...or for bulk actions, an array of the models
+		if tool.Action.IsBulk() {
+			pathPrefix = "[*]"
+		}
 		// if the action is a list action, we also need to include the pageInfo responses, resultInfo responses and prefix the results
 		if tool.Action.IsList() {
 			pathPrefix = ".results[*]"
@@ -804,8 +808,8 @@ func (g *Generator) makeInputsForMessage(
 			// create the GetEntry tool link to retrieve the entry for this related model. At this point, not all tools'
 			// inputs and responses have been generated ; this is a placeholder that will have it's data populated later
 			// in the generation process
-			// We do not add a GetEntryAction for the 'id' (or any unique lookup) input on a 'get', 'create' or 'update' action of a model, however do we add it for related models
-			if !((actionType == proto.ActionType_ACTION_TYPE_GET || actionType == proto.ActionType_ACTION_TYPE_CREATE || actionType == proto.ActionType_ACTION_TYPE_UPDATE) && len(f.GetTarget()) == 1) {
+			// We do not add a GetEntryAction for the 'id' (or any unique lookup) input on a 'get', 'create', 'update' or 'upsert' action of a model, however do we add it for related models
+			if !((actionType == proto.ActionType_ACTION_TYPE_GET || actionType == proto.ActionType_ACTION_TYPE_CREATE || actionType == proto.ActionType_ACTION_TYPE_UPDATE || actionType == proto.ActionType_ACTION_TYPE_UPSERT) && len(f.GetTarget()) == 1) {
 				config.GetEntryAction = &toolsproto.ToolLink{
 					ToolId: f.GetType().GetEntityName().GetValue(), // TODO: this is a bit of a hack placeholder because we do not know the underlying model which the field is pointing to during post-processing
 				}
@@ -1361,6 +1365,10 @@ func inferInputType(actionType proto.ActionType, fieldName string) toolsproto.Re
 		case "first", "after", "last", "before", "limit", "offset":
 			return toolsproto.RequestFieldConfig_PAGINATION
 		}
+	case proto.ActionType_ACTION_TYPE_AGGREGATE:
+		if fieldName == "where" {
+			return toolsproto.RequestFieldConfig_FILTERS
+		}
 	case proto.ActionType_ACTION_TYPE_UPDATE:
 		switch fieldName {
 		case "where":